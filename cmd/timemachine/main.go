@@ -3,10 +3,13 @@ package main
 import (
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/deepakkumarnarayana/timemachine-cli/internal/commands"
 	"github.com/deepakkumarnarayana/timemachine-cli/internal/core"
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/ui"
 )
 
 const Version = "1.0.0"
@@ -24,6 +27,7 @@ your codebase and creates snapshots without affecting your main Git workflow.
 🔧 QUICK START:
   timemachine init     # Initialize in your Git repository
   timemachine start    # Start watching for changes
+  timemachine start -d # Start watching in the background
   timemachine list     # View recent snapshots
   timemachine restore  # Rollback when needed
 
@@ -52,43 +56,146 @@ your codebase and creates snapshots without affecting your main Git workflow.
 		// Show enhanced help with current status
 		state, err := core.NewAppState()
 		if err != nil {
-			fmt.Printf("⚠️  Warning: %v\n", err)
-			fmt.Println("   Some commands may not work outside of a Git repository.\n")
+			fmt.Println(ui.T(ui.MsgWarning, err))
+			fmt.Println("   " + ui.T(ui.MsgOutsideRepository))
+			fmt.Println()
 		} else {
-			fmt.Printf("📂 Git Repository: %s\n", state.ProjectRoot)
+			fmt.Println(ui.T(ui.MsgGitRepository, state.ProjectRoot))
 			if state.IsInitialized {
-				fmt.Println("✅ Time Machine: Initialized and ready")
+				fmt.Println(ui.T(ui.MsgStatusInitialized))
 			} else {
-				fmt.Println("❌ Time Machine: Not initialized")
-				fmt.Println("   Run 'timemachine init' to get started")
+				fmt.Println(ui.T(ui.MsgStatusNotInitialized))
+				fmt.Println("   " + ui.T(ui.MsgRunInit))
 			}
 			fmt.Println()
 		}
 		
-		fmt.Println("Use 'timemachine --help' for detailed command information")
-		fmt.Println("Use 'timemachine <command> --help' for specific command help")
+		fmt.Println(ui.T(ui.MsgUseHelp))
+		fmt.Println(ui.T(ui.MsgUseCommandHelp))
 	},
 }
 
 func init() {
 	// Add version flag
 	rootCmd.Flags().BoolP("version", "v", false, "Show version information")
+
+	// Messages come from the ui catalog in the language of ui.language;
+	// --no-emoji drops their emoji for terminals and logs that can't show them
+	rootCmd.PersistentFlags().Bool("no-emoji", false, "Print messages without emoji")
+	// Confirmations are answered yes by --yes or TIMEMACHINE_ASSUME_YES; with
+	// --no-input, or without a terminal, they fail rather than wait on stdin
+	rootCmd.PersistentFlags().BoolP("yes", "y", false, "Answer yes to confirmations (or set TIMEMACHINE_ASSUME_YES=1)")
+	rootCmd.PersistentFlags().Bool("no-input", false, "Never read answers from stdin; fail when a confirmation is needed")
+	rootCmd.MarkFlagsMutuallyExclusive("yes", "no-input")
+	rootCmd.PersistentPreRun = func(cmd *cobra.Command, args []string) {
+		if noEmoji, _ := cmd.Flags().GetBool("no-emoji"); noEmoji {
+			ui.DisableEmoji()
+		}
+		if yes, _ := cmd.Flags().GetBool("yes"); yes {
+			ui.SetAssumeYes()
+		}
+		if noInput, _ := cmd.Flags().GetBool("no-input"); noInput {
+			ui.SetNoInput()
+		}
+		// Arguments and flags parsed; later errors aren't usage mistakes
+		cmd.SilenceUsage = true
+	}
+
+	// main prints errors once, and exits with the code of their kind (see
+	// core.ExitCode); bad flags and arguments are validation errors
+	rootCmd.SilenceErrors = true
+	rootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return &core.ValidationError{Err: err}
+	})
+	
+	// The completion command below replaces cobra's default one
+	rootCmd.CompletionOptions.DisableDefaultCmd = true
 	
 	// Add commands in logical order
 	rootCmd.AddCommand(commands.InitCmd())      // Setup
 	rootCmd.AddCommand(commands.ConfigCmd())    // Configuration  
 	rootCmd.AddCommand(commands.StartCmd())     // Core functionality
+	rootCmd.AddCommand(commands.StopCmd())      // Core functionality
+	rootCmd.AddCommand(commands.DaemonCmd())    // Core functionality
+	rootCmd.AddCommand(commands.ServiceCmd())   // Core functionality
+	rootCmd.AddCommand(commands.ReposCmd())     // Core functionality
 	rootCmd.AddCommand(commands.ListCmd())      // Inspection
 	rootCmd.AddCommand(commands.ShowCmd())      // Inspection
 	rootCmd.AddCommand(commands.InspectCmd())   // Inspection
+	rootCmd.AddCommand(commands.DiffCmd())      // Inspection
+	rootCmd.AddCommand(commands.HistoryCmd())   // Inspection
+	rootCmd.AddCommand(commands.ChangelogCmd()) // Inspection
+	rootCmd.AddCommand(commands.SummarizeCmd()) // Inspection
+	rootCmd.AddCommand(commands.SessionCmd())   // Inspection
+	rootCmd.AddCommand(commands.BranchCmd())    // Inspection
+	rootCmd.AddCommand(commands.MountCmd())     // Inspection
+	rootCmd.AddCommand(commands.UnmountCmd())   // Inspection
+	rootCmd.AddCommand(commands.AnnotateCmd())  // Inspection
+	rootCmd.AddCommand(commands.IgnoreCmd())    // Inspection
 	rootCmd.AddCommand(commands.RestoreCmd())   // Recovery
 	rootCmd.AddCommand(commands.StatusCmd())    // Status
+	rootCmd.AddCommand(commands.DoctorCmd())    // Status
+	rootCmd.AddCommand(commands.PromptCmd())    // Status
+	rootCmd.AddCommand(commands.StatsCmd())     // Status
+	rootCmd.AddCommand(commands.MCPServeCmd())  // Integration
+	rootCmd.AddCommand(commands.RecordCommitCmd()) // Integration
+	rootCmd.AddCommand(commands.PluginCmd())    // Integration
 	rootCmd.AddCommand(commands.CleanCmd())     // Maintenance
+	rootCmd.AddCommand(commands.CompactCmd())   // Maintenance
+	rootCmd.AddCommand(commands.ExportCmd())    // Maintenance
+	rootCmd.AddCommand(commands.ImportCmd())    // Maintenance
+	rootCmd.AddCommand(commands.RemoteCmd())    // Maintenance
+	rootCmd.AddCommand(commands.PushCmd())      // Maintenance
+	rootCmd.AddCommand(commands.PullCmd())      // Maintenance
+	rootCmd.AddCommand(commands.BackupCmd())    // Maintenance
+	rootCmd.AddCommand(commands.RestoreFromBackupCmd()) // Maintenance
+	rootCmd.AddCommand(commands.VerifyCmd())    // Maintenance
+	rootCmd.AddCommand(commands.SizeCmd())      // Maintenance
+	rootCmd.AddCommand(commands.AnalyzeCmd())   // Maintenance
+	rootCmd.AddCommand(commands.CompletionCmd()) // Setup
+	rootCmd.AddCommand(commands.GenrepoCmd())   // Development
+}
+
+// validateArgs makes the argument checks of cmd and its subcommands return
+// validation errors
+func validateArgs(cmd *cobra.Command) {
+	if check := cmd.Args; check != nil {
+		cmd.Args = func(cmd *cobra.Command, args []string) error {
+			if err := check(cmd, args); err != nil {
+				return &core.ValidationError{Err: err}
+			}
+			return nil
+		}
+	}
+	for _, sub := range cmd.Commands() {
+		validateArgs(sub)
+	}
+}
+
+// execute runs the root command. cobra rejects an unknown command while
+// finding the command to run, before any argument check, so its error is
+// made a validation error here.
+func execute() (*cobra.Command, error) {
+	cmd, err := rootCmd.ExecuteC()
+	if err != nil && strings.HasPrefix(err.Error(), "unknown command ") {
+		message := strings.TrimRight(err.Error(), "\n") // cobra's suggestions end in a newline
+		err = &core.ValidationError{Err: fmt.Errorf("%s\nRun '%s --help' for usage.", message, rootCmd.CommandPath())}
+	}
+	return cmd, err
 }
 
 func main() {
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+	// timemachine-<name> executables run as 'timemachine <name>'
+	commands.AddPluginCommands(rootCmd, os.Args[1:])
+	validateArgs(rootCmd)
+	started := time.Now()
+	cmd, err := execute()
+	// Only when telemetry.enabled is set for the repository
+	core.RecordCommand(cmd.CommandPath(), time.Since(started), err)
+	if err != nil {
+		if !core.IsReported(err) {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+		}
+		os.Exit(core.ExitCode(err))
 	}
 }
\ No newline at end of file
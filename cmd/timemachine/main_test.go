@@ -0,0 +1,40 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/core"
+)
+
+func TestUnknownCommand(t *testing.T) {
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	rootCmd.SetErr(&out)
+	defer rootCmd.SetArgs(nil)
+
+	tests := []struct {
+		args    []string
+		message string
+	}{
+		{[]string{"bogus"}, `unknown command "bogus" for "timemachine"`},
+		{[]string{"bogus", "--help"}, `unknown command "bogus" for "timemachine"`},
+		{[]string{"snapshot", "-m", "x"}, `unknown command "snapshot" for "timemachine"`},
+		{[]string{"lst"}, "Did you mean this?\n\tlist\nRun 'timemachine --help' for usage."},
+	}
+	for _, tt := range tests {
+		out.Reset()
+		rootCmd.SetArgs(tt.args)
+		_, err := execute()
+		if code := core.ExitCode(err); code != core.ExitValidation {
+			t.Errorf("%v: expected exit code %d, got %d (%v)", tt.args, core.ExitValidation, code, err)
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.message) {
+			t.Errorf("%v: expected an error containing %q, got %v", tt.args, tt.message, err)
+		}
+		if out.Len() > 0 {
+			t.Errorf("%v: expected no help or usage, got %q", tt.args, out.String())
+		}
+	}
+}
@@ -0,0 +1,277 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/core"
+)
+
+// snapshot is a snapshot as listed by the API
+type snapshot struct {
+	Hash    string    `json:"hash"`
+	Message string    `json:"message"`
+	Time    time.Time `json:"time"`
+	Note    string    `json:"note,omitempty"`
+}
+
+// conflict is an uncommitted Git change a restore would overwrite
+type conflict struct {
+	Path     string `json:"path"`
+	Staged   bool   `json:"staged"`
+	Unstaged bool   `json:"unstaged"`
+}
+
+// badRequest marks errors caused by the request rather than the server
+type badRequest struct{ error }
+
+// fail answers with the status matching err
+func fail(rw http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	if _, ok := err.(badRequest); ok {
+		status = http.StatusBadRequest
+	}
+	writeError(rw, status, err)
+}
+
+// decodeBody unmarshals a JSON request body; an empty body leaves v alone
+func decodeBody(r *http.Request, v interface{}) error {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil && !errors.Is(err, io.EOF) {
+		return badRequest{fmt.Errorf("invalid request body: %w", err)}
+	}
+	return nil
+}
+
+// resolve resolves a snapshot named by the request
+func (s *Server) resolve(ref string) (string, error) {
+	if ref == "" {
+		return "", badRequest{fmt.Errorf("snapshot hash is required")}
+	}
+	commit, err := s.git.ResolveSnapshot(ref)
+	if err != nil {
+		return "", badRequest{err}
+	}
+	return commit, nil
+}
+
+// pathspecs matches paths, relative to the project root, against a snapshot
+func (s *Server) pathspecs(commit string, paths []string) ([]string, error) {
+	resolved := make([]string, len(paths))
+	for i, path := range paths {
+		if filepath.IsAbs(path) {
+			resolved[i] = path
+		} else {
+			resolved[i] = filepath.Join(s.git.State.ProjectRoot, filepath.FromSlash(path))
+		}
+	}
+	matches, err := s.git.MatchSnapshotPaths(commit, resolved)
+	if err != nil {
+		return nil, badRequest{err}
+	}
+	return core.MatchedPathspecs(matches), nil
+}
+
+// status describes the server and the project
+func (s *Server) status(rw http.ResponseWriter, r *http.Request) {
+	latest, _ := s.git.RunCommand("rev-parse", "--verify", "--quiet", "HEAD")
+	writeJSON(rw, http.StatusOK, map[string]string{
+		"version":         s.version,
+		"project_root":    s.git.State.ProjectRoot,
+		"latest_snapshot": latest,
+	})
+}
+
+// listSnapshots lists snapshots, newest first. Query parameters: limit
+// (default 50, 0 for all), offset, file, since, until and grep.
+func (s *Server) listSnapshots(rw http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := core.SnapshotFilter{Limit: 50, Grep: query.Get("grep")}
+	for name, field := range map[string]*int{"limit": &filter.Limit, "offset": &filter.Offset} {
+		if value := query.Get(name); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				fail(rw, badRequest{fmt.Errorf("invalid %s %q", name, value)})
+				return
+			}
+			*field = n
+		}
+	}
+	if file := query.Get("file"); file != "" {
+		filter.File = filepath.Join(s.git.State.ProjectRoot, filepath.FromSlash(file))
+	}
+	for name, field := range map[string]*time.Time{"since": &filter.Since, "until": &filter.Until} {
+		if value := query.Get(name); value != "" {
+			bound, err := core.ParseTimeBound(value, name == "until")
+			if err != nil {
+				fail(rw, badRequest{fmt.Errorf("invalid %s: %w", name, err)})
+				return
+			}
+			*field = bound
+		}
+	}
+
+	snapshots, err := s.git.FilterSnapshots(filter)
+	if err != nil {
+		fail(rw, badRequest{err})
+		return
+	}
+	notes, _ := s.git.Notes()
+	entries := make([]snapshot, 0, len(snapshots))
+	for _, snap := range snapshots {
+		entries = append(entries, snapshot{
+			Hash:    snap.Hash,
+			Message: snap.Message,
+			Time:    snap.Timestamp.UTC(),
+			Note:    notes[snap.Hash],
+		})
+	}
+	writeJSON(rw, http.StatusOK, map[string]interface{}{"snapshots": entries})
+}
+
+// createSnapshot snapshots the working tree. Body: {"message", "note"}, both
+// optional. Answers 201 with the new snapshot, or 200 with the latest one
+// when nothing changed.
+func (s *Server) createSnapshot(rw http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Message string `json:"message"`
+		Note    string `json:"note"`
+	}
+	if err := decodeBody(r, &body); err != nil {
+		fail(rw, err)
+		return
+	}
+
+	before, _ := s.git.RunCommand("rev-parse", "--verify", "--quiet", "HEAD")
+	if err := s.git.CreateSnapshot(body.Message); err != nil {
+		fail(rw, err)
+		return
+	}
+	head, err := s.git.RunCommand("rev-parse", "HEAD")
+	if err != nil {
+		fail(rw, badRequest{fmt.Errorf("no snapshot was created: the project has no files to snapshot")})
+		return
+	}
+	created := head != before
+	if body.Note != "" {
+		if _, err := s.git.AddNote(head, body.Note, !created); err != nil {
+			fail(rw, err)
+			return
+		}
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	writeJSON(rw, status, map[string]interface{}{"hash": head, "created": created})
+}
+
+// diff diffs a snapshot to another or to the working tree. Query
+// parameters: from (required), to, path (repeatable) and stat.
+func (s *Server) diff(rw http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	from, err := s.resolve(query.Get("from"))
+	if err != nil {
+		fail(rw, err)
+		return
+	}
+	to := ""
+	if query.Get("to") != "" {
+		if to, err = s.resolve(query.Get("to")); err != nil {
+			fail(rw, err)
+			return
+		}
+	}
+	var pathspecs []string
+	if paths := query["path"]; len(paths) > 0 {
+		if pathspecs, err = s.pathspecs(from, paths); err != nil {
+			fail(rw, err)
+			return
+		}
+	}
+	stat, _ := strconv.ParseBool(query.Get("stat"))
+
+	diff, err := s.git.DiffSnapshots(from, to, core.DiffOptions{Stat: stat, Pathspecs: pathspecs})
+	if err != nil {
+		fail(rw, err)
+		return
+	}
+	writeJSON(rw, http.StatusOK, map[string]string{"from": from, "to": to, "diff": diff})
+}
+
+// restore restores files from a snapshot. Body: {"hash", "paths", "force"};
+// paths are relative to the project root, ["."] restores everything.
+// Answers 409 listing the conflicts when uncommitted Git changes would be
+// overwritten and force is not set.
+func (s *Server) restore(rw http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Hash  string   `json:"hash"`
+		Paths []string `json:"paths"`
+		Force bool     `json:"force"`
+	}
+	if err := decodeBody(r, &body); err != nil {
+		fail(rw, err)
+		return
+	}
+	if len(body.Paths) == 0 {
+		fail(rw, badRequest{fmt.Errorf("paths is required; use [\".\"] to restore everything")})
+		return
+	}
+	commit, err := s.resolve(body.Hash)
+	if err != nil {
+		fail(rw, err)
+		return
+	}
+	pathspecs, err := s.pathspecs(commit, body.Paths)
+	if err != nil {
+		fail(rw, err)
+		return
+	}
+
+	changes, err := s.git.RestoreConflicts(commit, pathspecs)
+	if err != nil {
+		fail(rw, err)
+		return
+	}
+	if len(changes) > 0 && !body.Force {
+		conflicts := make([]conflict, len(changes))
+		for i, change := range changes {
+			conflicts[i] = conflict{Path: change.Path, Staged: change.Staged, Unstaged: change.Unstaged}
+		}
+		writeJSON(rw, http.StatusConflict, apiError{
+			Error:     "restore would overwrite uncommitted Git changes; commit them first or set force",
+			Conflicts: conflicts,
+		})
+		return
+	}
+
+	journal, err := s.git.JournaledRestore(context.Background(), commit, pathspecs, core.DefaultRestoreBatchSize, nil)
+	if err != nil {
+		fail(rw, err)
+		return
+	}
+	files := journal.Files
+	if files == nil {
+		files = []string{}
+	}
+	result := map[string]interface{}{"source": commit, "backup": journal.Backup, "files": files}
+	if journal.BackupPatch != "" {
+		result["backup_patch"] = journal.BackupPatch
+	}
+	if len(files) > 0 {
+		hc := core.HookContext{Hash: journal.Source, BackupHash: journal.Backup, ChangedFiles: journal.Files}
+		if err := s.git.RunHooks(core.HookPostRestore, hc); err != nil {
+			result["warning"] = err.Error()
+		}
+	}
+	writeJSON(rw, http.StatusOK, result)
+}
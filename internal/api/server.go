@@ -0,0 +1,201 @@
+// Package api serves a project's snapshots over a local HTTP API, so editor
+// extensions can list, diff, create and restore snapshots without running
+// the CLI and parsing its output.
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/core"
+)
+
+// EndpointFileName is where a running API publishes its address and token,
+// in the shadow repository. Only the user can read it.
+const EndpointFileName = "api.json"
+
+// maxBodySize bounds request bodies
+const maxBodySize = 1024 * 1024
+
+// Endpoint tells clients how to reach a running API: send the token as
+// "Authorization: Bearer <token>" with every request
+type Endpoint struct {
+	URL     string `json:"url"`
+	Token   string `json:"token"`
+	PID     int    `json:"pid"`
+	Version string `json:"version"`
+}
+
+// EndpointFile returns the path of the endpoint file of a project
+func EndpointFile(state *core.AppState) string {
+	return filepath.Join(state.ShadowRepoDir, EndpointFileName)
+}
+
+// ReadEndpoint loads the endpoint of the project's running API
+func ReadEndpoint(state *core.AppState) (*Endpoint, error) {
+	content, err := os.ReadFile(EndpointFile(state))
+	if err != nil {
+		return nil, err
+	}
+	var endpoint Endpoint
+	if err := json.Unmarshal(content, &endpoint); err != nil {
+		return nil, fmt.Errorf("invalid API endpoint file: %w", err)
+	}
+	return &endpoint, nil
+}
+
+// Server answers API requests about one project's snapshots
+type Server struct {
+	git     *core.GitManager
+	version string
+	token   string
+	http    *http.Server
+}
+
+// NewServer creates a server for the project gitManager manages, with a new
+// random token; version is reported to clients as the server's
+func NewServer(gitManager *core.GitManager, version string) (*Server, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("failed to generate API token: %w", err)
+	}
+	return &Server{git: gitManager, version: version, token: hex.EncodeToString(secret)}, nil
+}
+
+// Handler returns the API's routes, behind token authentication
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/status", s.status)
+	mux.HandleFunc("GET /v1/snapshots", s.listSnapshots)
+	mux.HandleFunc("POST /v1/snapshots", s.createSnapshot)
+	mux.HandleFunc("GET /v1/diff", s.diff)
+	mux.HandleFunc("POST /v1/restore", s.restore)
+	return s.authenticate(mux)
+}
+
+// authenticate rejects requests without the token, and requests addressed
+// to another host name, which a web page could send via DNS rebinding
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if !loopbackHost(r.Host) {
+			writeError(rw, http.StatusForbidden, fmt.Errorf("requests must be addressed to a loopback host"))
+			return
+		}
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+			writeError(rw, http.StatusUnauthorized, fmt.Errorf("missing or invalid token; read it from %s", EndpointFileName))
+			return
+		}
+		r.Body = http.MaxBytesReader(rw, r.Body, maxBodySize)
+		next.ServeHTTP(rw, r)
+	})
+}
+
+// loopbackHost reports whether a Host header names this machine
+func loopbackHost(host string) bool {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(strings.Trim(host, "[]"))
+	return ip != nil && ip.IsLoopback()
+}
+
+// Start listens on addr, publishes the endpoint file and serves until Stop.
+// Listening happens before it returns, so a busy port is reported right away.
+func (s *Server) Start(addr string) (*Endpoint, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for the API on %s: %w", addr, err)
+	}
+
+	endpoint := &Endpoint{
+		URL:     "http://" + listener.Addr().String(),
+		Token:   s.token,
+		PID:     os.Getpid(),
+		Version: s.version,
+	}
+	if err := writeEndpoint(s.git.State, endpoint); err != nil {
+		listener.Close()
+		return nil, err
+	}
+
+	s.http = &http.Server{Handler: s.Handler(), ReadHeaderTimeout: 5 * time.Second}
+	go func() {
+		if err := s.http.Serve(listener); err != nil && err != http.ErrServerClosed {
+			fmt.Printf("Warning: API stopped: %v\n", err)
+		}
+	}()
+	return endpoint, nil
+}
+
+// Stop shuts the server down, waiting briefly for requests in flight, and
+// withdraws the endpoint file unless another server replaced it
+func (s *Server) Stop() {
+	if s.http == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	s.http.Shutdown(ctx)
+
+	if endpoint, err := ReadEndpoint(s.git.State); err == nil && endpoint.Token == s.token {
+		os.Remove(EndpointFile(s.git.State))
+	}
+}
+
+// writeEndpoint atomically replaces the endpoint file, readable by the user
+// alone since the token grants restoring files
+func writeEndpoint(state *core.AppState, endpoint *Endpoint) error {
+	content, err := json.MarshalIndent(endpoint, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode API endpoint: %w", err)
+	}
+	path := EndpointFile(state)
+	tmp, err := os.CreateTemp(filepath.Dir(path), EndpointFileName+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write API endpoint: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(content, '\n')); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write API endpoint: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write API endpoint: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write API endpoint: %w", err)
+	}
+	return nil
+}
+
+// apiError is the body of every error response
+type apiError struct {
+	Error     string     `json:"error"`
+	Conflicts []conflict `json:"conflicts,omitempty"`
+}
+
+func writeJSON(rw http.ResponseWriter, status int, v interface{}) {
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(status)
+	encoder := json.NewEncoder(rw)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(v)
+}
+
+func writeError(rw http.ResponseWriter, status int, err error) {
+	writeJSON(rw, status, apiError{Error: err.Error()})
+}
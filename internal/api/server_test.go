@@ -0,0 +1,206 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/core"
+)
+
+func setupTestServer(t *testing.T) (string, *Server) {
+	tempDir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", tempDir},
+		{"-C", tempDir, "config", "user.name", "Test User"},
+		{"-C", tempDir, "config", "user.email", "test@example.com"},
+	} {
+		if err := exec.Command("git", args...).Run(); err != nil {
+			t.Fatalf("git %v failed: %v", args, err)
+		}
+	}
+
+	gitDir := filepath.Join(tempDir, ".git")
+	gitManager := core.NewGitManager(&core.AppState{
+		ProjectRoot:   tempDir,
+		GitDir:        gitDir,
+		ShadowRepoDir: filepath.Join(gitDir, "timemachine_snapshots"),
+	})
+	if err := gitManager.InitializeShadowRepo(); err != nil {
+		t.Fatalf("Failed to initialize shadow repo: %v", err)
+	}
+	server, err := NewServer(gitManager, "test")
+	if err != nil {
+		t.Fatalf("NewServer failed: %v", err)
+	}
+	return tempDir, server
+}
+
+// call sends an authenticated request and decodes the JSON response
+func call(t *testing.T, server *Server, method, target string, body interface{}) (int, map[string]interface{}) {
+	var data []byte
+	if body != nil {
+		data, _ = json.Marshal(body)
+	}
+	req := httptest.NewRequest(method, "http://127.0.0.1"+target, bytes.NewReader(data))
+	req.Header.Set("Authorization", "Bearer "+server.token)
+	rec := httptest.NewRecorder()
+	server.Handler().ServeHTTP(rec, req)
+
+	var result map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+		t.Fatalf("%s %s: invalid response %q: %v", method, target, rec.Body.String(), err)
+	}
+	return rec.Code, result
+}
+
+func TestAPIAuthentication(t *testing.T) {
+	_, server := setupTestServer(t)
+
+	for _, tc := range []struct {
+		host, auth string
+		want       int
+	}{
+		{"127.0.0.1:8080", "", http.StatusUnauthorized},
+		{"127.0.0.1:8080", "Bearer wrong", http.StatusUnauthorized},
+		{"evil.example.com", "Bearer " + server.token, http.StatusForbidden},
+		{"localhost:8080", "Bearer " + server.token, http.StatusOK},
+		{"[::1]:8080", "Bearer " + server.token, http.StatusOK},
+	} {
+		req := httptest.NewRequest("GET", "/v1/status", nil)
+		req.Host = tc.host
+		if tc.auth != "" {
+			req.Header.Set("Authorization", tc.auth)
+		}
+		rec := httptest.NewRecorder()
+		server.Handler().ServeHTTP(rec, req)
+		if rec.Code != tc.want {
+			t.Errorf("Host %s, Authorization %q: expected %d, got %d", tc.host, tc.auth, tc.want, rec.Code)
+		}
+	}
+}
+
+func TestAPISnapshotDiffAndRestore(t *testing.T) {
+	tempDir, server := setupTestServer(t)
+	file := filepath.Join(tempDir, "main.go")
+	if err := os.WriteFile(file, []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	code, created := call(t, server, "POST", "/v1/snapshots", map[string]string{"note": "before refactoring"})
+	if code != http.StatusCreated || created["created"] != true {
+		t.Fatalf("Expected a snapshot to be created, got %d %v", code, created)
+	}
+	hash := created["hash"].(string)
+	if code, again := call(t, server, "POST", "/v1/snapshots", nil); code != http.StatusOK || again["hash"] != hash {
+		t.Errorf("Expected the latest snapshot when nothing changed, got %d %v", code, again)
+	}
+
+	_, listed := call(t, server, "GET", "/v1/snapshots?limit=5", nil)
+	snapshots := listed["snapshots"].([]interface{})
+	if len(snapshots) != 1 || snapshots[0].(map[string]interface{})["note"] != "before refactoring" {
+		t.Errorf("Expected the snapshot and its note to be listed, got %v", listed)
+	}
+
+	if err := os.WriteFile(file, []byte("package broken\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, diff := call(t, server, "GET", "/v1/diff?from="+hash+"&path=main.go", nil)
+	if !strings.Contains(diff["diff"].(string), "+package broken") {
+		t.Errorf("Expected a diff against the working tree, got %v", diff)
+	}
+
+	code, restored := call(t, server, "POST", "/v1/restore", map[string]interface{}{"hash": hash, "paths": []string{"main.go"}})
+	if code != http.StatusOK || len(restored["files"].([]interface{})) != 1 || restored["backup"] == "" {
+		t.Errorf("Expected main.go to be restored, got %d %v", code, restored)
+	}
+	if content, _ := os.ReadFile(file); string(content) != "package main\n" {
+		t.Errorf("Expected main.go to be restored, got %q", content)
+	}
+
+	for _, tc := range []struct {
+		method, target string
+		body           interface{}
+	}{
+		{"POST", "/v1/restore", map[string]interface{}{"hash": "nope", "paths": []string{"."}}},
+		{"POST", "/v1/restore", map[string]interface{}{"hash": hash}},
+		{"POST", "/v1/snapshots", map[string]interface{}{"unknown": true}},
+		{"GET", "/v1/snapshots?limit=-1", nil},
+		{"GET", "/v1/diff", nil},
+	} {
+		if code, result := call(t, server, tc.method, tc.target, tc.body); code != http.StatusBadRequest || result["error"] == "" {
+			t.Errorf("%s %s %v: expected a bad request, got %d %v", tc.method, tc.target, tc.body, code, result)
+		}
+	}
+}
+
+func TestAPIRestoreConflicts(t *testing.T) {
+	tempDir, server := setupTestServer(t)
+	file := filepath.Join(tempDir, "main.go")
+	if err := os.WriteFile(file, []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{{"add", "main.go"}, {"commit", "-m", "initial"}} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = tempDir
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+	}
+	_, created := call(t, server, "POST", "/v1/snapshots", nil)
+	hash := created["hash"].(string)
+
+	if err := os.WriteFile(file, []byte("package edited\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	code, result := call(t, server, "POST", "/v1/restore", map[string]interface{}{"hash": hash, "paths": []string{"."}})
+	if code != http.StatusConflict || len(result["conflicts"].([]interface{})) != 1 {
+		t.Fatalf("Expected the uncommitted change to conflict, got %d %v", code, result)
+	}
+	if content, _ := os.ReadFile(file); string(content) != "package edited\n" {
+		t.Errorf("Expected main.go to be left alone, got %q", content)
+	}
+
+	code, _ = call(t, server, "POST", "/v1/restore", map[string]interface{}{"hash": hash, "paths": []string{"."}, "force": true})
+	if content, _ := os.ReadFile(file); code != http.StatusOK || string(content) != "package main\n" {
+		t.Errorf("Expected force to restore main.go, got %d %q", code, content)
+	}
+}
+
+func TestAPIStartPublishesEndpoint(t *testing.T) {
+	_, server := setupTestServer(t)
+	endpoint, err := server.Start("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	published, err := ReadEndpoint(server.git.State)
+	if err != nil || published.URL != endpoint.URL || published.Token != server.token {
+		t.Fatalf("Expected the endpoint to be published, got %+v, %v", published, err)
+	}
+	if info, err := os.Stat(EndpointFile(server.git.State)); err == nil && info.Mode().Perm()&0077 != 0 {
+		t.Errorf("Expected the endpoint file to be private, got %v", info.Mode())
+	}
+
+	req, _ := http.NewRequest("GET", endpoint.URL+"/v1/status", nil)
+	req.Header.Set("Authorization", "Bearer "+endpoint.Token)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected 200, got %d", resp.StatusCode)
+	}
+
+	server.Stop()
+	if _, err := os.Stat(EndpointFile(server.git.State)); !os.IsNotExist(err) {
+		t.Errorf("Expected Stop to withdraw the endpoint file, got %v", err)
+	}
+}
@@ -0,0 +1,118 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/core"
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/ui"
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+// analyzeListPaths is how many paths a finding lists before summing up the rest
+const analyzeListPaths = 3
+
+// AnalyzeCmd creates the analyze command
+func AnalyzeCmd() *cobra.Command {
+	var format string
+
+	cmd := &cobra.Command{
+		Use:   "analyze",
+		Short: "Suggest ignore patterns that would save snapshot storage",
+		Long: `Scan every snapshot for storage that is probably wasted and suggest what to
+do about it:
+
+  • generated files (build output, dependencies, caches, logs, archives),
+    grouped by the .timemachine-ignore pattern that would exclude them
+  • files changed in more than 80% of snapshots, which are often logs or
+    state files rather than your work
+  • large contents snapshotted at several paths, such as copied assets
+
+Paths your ignore rules exclude already are left out. Savings are on disk,
+after compression, and estimate what the same amount of history would cost
+again; ignoring a path doesn't remove it from existing snapshots.
+
+Examples:
+  timemachine analyze
+  timemachine analyze --format json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runAnalyze(os.Stdout, format)
+		},
+	}
+
+	cmd.Flags().StringVar(&format, "format", "text", "Output format (text, json)")
+
+	return cmd
+}
+
+func runAnalyze(out io.Writer, format string) error {
+	if format != "text" && format != "json" {
+		return fmt.Errorf("unsupported format: %s (use 'text' or 'json')", format)
+	}
+
+	state, err := loadInitializedState()
+	if err != nil || state == nil {
+		return err
+	}
+
+	gitManager := core.NewGitManager(state)
+	analysis, err := gitManager.Analyze(gitManager.IgnoredByProject())
+	if err != nil {
+		return err
+	}
+
+	if format == "json" {
+		if analysis.Findings == nil {
+			analysis.Findings = []core.AnalysisFinding{}
+		}
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(analysis)
+	}
+
+	heading := ui.Color(ui.RoleHeading)
+	heading.Fprintln(out, "🔎 Snapshot analysis")
+	fmt.Fprintf(out, "   File contents: %s in %d version(s) across %d snapshot(s)\n",
+		utils.FormatBytes(analysis.DiskBytes), analysis.Blobs, analysis.Snapshots)
+	fmt.Fprintln(out)
+	if len(analysis.Findings) == 0 {
+		fmt.Fprintln(out, ui.Sprint(ui.RoleSuccess, "✅ Nothing to suggest: no generated, churny or duplicated files found."))
+		return nil
+	}
+
+	for _, finding := range analysis.Findings {
+		switch finding.Kind {
+		case core.FindingIgnore:
+			fmt.Fprintf(out, "💡 Add %s to .timemachine-ignore to save ~%s\n",
+				finding.Pattern, utils.FormatBytes(finding.Savings))
+			fmt.Fprintf(out, "   %d file(s), %d version(s): %s\n",
+				len(finding.Paths), finding.Versions, listPaths(finding.Paths))
+		case core.FindingChurn:
+			fmt.Fprintf(out, "🔁 %s changed in %.0f%% of snapshots (%d versions, %s)\n",
+				finding.Paths[0], finding.Changed*100, finding.Versions, utils.FormatBytes(finding.DiskBytes))
+			fmt.Fprintf(out, "   If it is generated, add %s to .timemachine-ignore to save ~%s\n",
+				finding.Pattern, utils.FormatBytes(finding.Savings))
+		case core.FindingDuplicate:
+			fmt.Fprintf(out, "👯 The same %s is snapshotted at %d paths (stored once)\n",
+				utils.FormatBytes(finding.DiskBytes), len(finding.Paths))
+			fmt.Fprintf(out, "   %s\n", listPaths(finding.Paths))
+		}
+		fmt.Fprintln(out)
+	}
+
+	fmt.Fprintln(out, ui.Sprint(ui.RoleMuted, "Ignored files stay in existing snapshots until they are cleaned or compacted away."))
+	return nil
+}
+
+// listPaths joins the first analyzeListPaths paths and counts the rest
+func listPaths(paths []string) string {
+	if len(paths) <= analyzeListPaths {
+		return strings.Join(paths, ", ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(paths[:analyzeListPaths], ", "), len(paths)-analyzeListPaths)
+}
@@ -0,0 +1,90 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/core"
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// AnnotateCmd creates the annotate command
+func AnnotateCmd() *cobra.Command {
+	var (
+		message    string
+		appendNote bool
+		remove     bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "annotate <hash>",
+		Short: "Attach a note to a snapshot",
+		Long: `Attach a note to a snapshot, so the right one to restore is easy to find
+days later: 'timemachine list' shows the first line of each note and
+'timemachine inspect' the whole note. Without -m the snapshot's note is
+printed.
+
+Notes are stored with git notes in the shadow repository
+(refs/notes/timemachine) and follow their snapshots when clean, compact or a
+secret purge rewrites history.
+
+Examples:
+  timemachine annotate abc12345 -m "auth flow broken from here"
+  timemachine annotate HEAD~2 --append -m "tests still pass"
+  timemachine annotate abc12345             # Show the note
+  timemachine annotate abc12345 --remove`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeSnapshots(1, false),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if appendNote && !cmd.Flags().Changed("message") {
+				return fmt.Errorf("--append needs a message (-m)")
+			}
+			state, err := loadInitializedState()
+			if err != nil || state == nil {
+				return err
+			}
+			gitManager := core.NewGitManager(state)
+
+			switch {
+			case remove:
+				commit, err := gitManager.RemoveNote(args[0])
+				if err != nil {
+					return err
+				}
+				ui.Success("✅ Removed the note of %s", ui.Sprint(ui.RoleHash, core.ShortHash(commit)))
+			case cmd.Flags().Changed("message"):
+				commit, err := gitManager.AddNote(args[0], message, appendNote)
+				if err != nil {
+					return err
+				}
+				ui.Success("✅ Annotated %s", ui.Sprint(ui.RoleHash, core.ShortHash(commit)))
+			default:
+				note, err := gitManager.Note(args[0])
+				if err != nil {
+					return err
+				}
+				if note == "" {
+					fmt.Printf("📝 %s has no note. Add one with: timemachine annotate %s -m \"...\"\n", args[0], args[0])
+					return nil
+				}
+				fmt.Println(note)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&message, "message", "m", "", "Note to attach, replacing the snapshot's note")
+	cmd.Flags().BoolVar(&appendNote, "append", false, "Add the message to the snapshot's note instead of replacing it")
+	cmd.Flags().BoolVar(&remove, "remove", false, "Delete the snapshot's note")
+	cmd.MarkFlagsMutuallyExclusive("message", "remove")
+
+	return cmd
+}
+
+// printNote prints a note indented below a heading line
+func printNote(note string) {
+	for _, line := range strings.Split(note, "\n") {
+		fmt.Printf("  %s\n", line)
+	}
+}
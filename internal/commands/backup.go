@@ -0,0 +1,165 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/core"
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/daemon"
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/ui"
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+// BackupCmd creates the backup command
+func BackupCmd() *cobra.Command {
+	var list bool
+
+	cmd := &cobra.Command{
+		Use:   "backup",
+		Short: "Upload new snapshots to object storage (backup.url)",
+		Long: `Upload the snapshots taken since the last backup to backup.url as a git
+bundle: s3://bucket/prefix, gs://bucket/prefix, or a directory such as a
+mounted network share. Each machine writes numbered bundles under
+<machine>/, the first holding every snapshot and the rest only what is new.
+
+S3 and GCS go through the aws and gcloud CLIs and use their credentials:
+environment variables, profiles, SSO or instance roles. Set backup.interval
+to upload from the watcher on a schedule.
+
+Examples:
+  timemachine backup               # Upload new snapshots now
+  timemachine backup --list        # List the bundles of every machine`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if list {
+				return runBackupList()
+			}
+			return runBackup()
+		},
+	}
+
+	cmd.Flags().BoolVar(&list, "list", false, "List the uploaded bundles instead of uploading")
+
+	return cmd
+}
+
+// RestoreFromBackupCmd creates the restore-from-backup command
+func RestoreFromBackupCmd() *cobra.Command {
+	var machine string
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:   "restore-from-backup",
+		Short: "Rebuild snapshot history from the bundles at backup.url",
+		Long: `Download every bundle a machine uploaded to backup.url and add its
+snapshots to this project, for when the machine or its disk is lost. Run
+'timemachine init' in a fresh checkout first, with the same backup.url.
+Working tree files are not changed; use 'timemachine restore' afterwards to
+bring back a snapshot.
+
+Branches missing here are created and those behind are brought up to date.
+Branches with snapshots the backup doesn't have are left alone unless
+--force is given: they are then replaced but stay reachable under
+refs/timemachine/backup/.
+
+Examples:
+  timemachine restore-from-backup                       # This machine's backups
+  timemachine restore-from-backup --machine old-laptop
+  timemachine restore-from-backup --force`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runRestoreFromBackup(machine, force)
+		},
+	}
+
+	cmd.Flags().StringVar(&machine, "machine", "", "Machine whose backups to restore (default remote.machine, or the hostname)")
+	cmd.Flags().BoolVar(&force, "force", false, "Replace local branches that diverged from the backup")
+
+	return cmd
+}
+
+func runBackup() error {
+	state, err := loadInitializedState()
+	if err != nil || state == nil {
+		return err
+	}
+	upload, err := core.NewGitManager(state).UploadBackup()
+	if err != nil {
+		return err
+	}
+	if upload.Key == "" {
+		fmt.Printf("Everything is already backed up to %s.\n", upload.Store)
+		return nil
+	}
+	ui.Success("☁️  Backed up %d snapshot(s) to %s", upload.Snapshots, upload.Store)
+	fmt.Printf("   %s (%s)\n", upload.Key, utils.FormatBytes(upload.Bytes))
+	return nil
+}
+
+func runBackupList() error {
+	state, err := loadInitializedState()
+	if err != nil || state == nil {
+		return err
+	}
+	bundles, err := core.NewGitManager(state).ListBackupBundles("")
+	if err != nil {
+		return err
+	}
+	if len(bundles) == 0 {
+		fmt.Printf("No backups at %s yet. Run 'timemachine backup' to upload one.\n", state.Config.Backup.URL)
+		return nil
+	}
+	for _, bundle := range bundles {
+		fmt.Println(bundle.Key)
+	}
+	return nil
+}
+
+func runRestoreFromBackup(machine string, force bool) error {
+	state, err := loadInitializedState()
+	if err != nil || state == nil {
+		return err
+	}
+
+	// The watcher would race the restore for the snapshot branch
+	status, err := daemon.NewManager(state).Status()
+	if err != nil {
+		return fmt.Errorf("failed to check daemon status: %w", err)
+	}
+	if status.Running {
+		ui.Warning("⚠️  Time Machine is running in the background (PID %d)", status.PID)
+		fmt.Println("   Run 'timemachine stop' before restoring from a backup.")
+		return nil
+	}
+
+	results, err := core.NewGitManager(state).RestoreFromBackup(machine, force)
+	if err != nil {
+		return err
+	}
+	restored, diverged := 0, 0
+	for _, result := range results {
+		switch result.Status {
+		case core.BackupBranchUpToDate:
+			fmt.Printf("  = %-30s %s\n", result.Branch, ui.Sprint(ui.RoleMuted, "up to date"))
+		case core.BackupBranchDiverged:
+			ui.Line(ui.RoleDeleted, "  ! %-30s has local snapshots the backup doesn't", result.Branch)
+			diverged++
+		case core.BackupBranchReplaced:
+			ui.Line(ui.RoleModified, "  ~ %-30s %d snapshot(s), previous kept under %s", result.Branch, result.Snapshots, result.BackupRef)
+			restored += result.Snapshots
+		default:
+			ui.Line(ui.RoleAdded, "  + %-30s %d snapshot(s)", result.Branch, result.Snapshots)
+			restored += result.Snapshots
+		}
+	}
+	if diverged > 0 {
+		fmt.Println("   Re-run with --force to replace diverged branches; they will be kept under refs/timemachine/backup/.")
+	}
+	if restored == 0 {
+		ui.Info("📸 Already up to date with the backup")
+		return nil
+	}
+	ui.Success("📥 Restored %d snapshot(s) from the backup", restored)
+	fmt.Println("   Use 'timemachine list' to browse them.")
+	return nil
+}
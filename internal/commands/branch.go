@@ -0,0 +1,154 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/core"
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// BranchCmd creates the branch command with subcommands
+func BranchCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "branch",
+		Short: "List and prune the branches of the shadow repository",
+		Long: `Snapshots are kept on branches of the shadow repository. Branches whose
+project branch was deleted keep their snapshots until pruned.
+
+Examples:
+  timemachine branch list                 # Snapshot counts per shadow branch
+  timemachine branch prune feature/login  # Delete a branch and its snapshots
+  timemachine branch prune --stale        # Every branch gone from the project
+  timemachine list --all-branches         # Snapshots of every branch`,
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List shadow branches with their snapshot counts",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runBranchList()
+		},
+	})
+
+	var force, stale bool
+	prune := &cobra.Command{
+		Use:   "prune [name...]",
+		Short: "Delete shadow branches and the snapshots only they contain",
+		Long: `Delete shadow branches and garbage-collect the snapshots only they contain.
+Branches that still exist in the project repository are kept unless --force
+is given; the current branch is always kept.`,
+		ValidArgsFunction: completeShadowBranches,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 && !stale {
+				return errors.New("name the branches to prune, or use --stale")
+			}
+			return runBranchPrune(args, stale, force)
+		},
+	}
+	prune.Flags().BoolVar(&stale, "stale", false, "Prune every branch deleted from the project repository")
+	prune.Flags().BoolVar(&force, "force", false, "Also prune branches that still exist in the project repository")
+	cmd.AddCommand(prune)
+
+	return cmd
+}
+
+func runBranchList() error {
+	gitManager, err := branchGitManager()
+	if err != nil || gitManager == nil {
+		return err
+	}
+
+	branches, err := gitManager.ShadowBranches()
+	if err != nil {
+		return err
+	}
+	if len(branches) == 0 {
+		fmt.Println("📸 No snapshots yet.")
+		return nil
+	}
+
+	fmt.Println("🌿 Shadow branches:")
+	fmt.Println()
+	stale := 0
+	for _, branch := range branches {
+		marker := " "
+		if branch.Current {
+			marker = "*"
+		}
+		note := ""
+		if !branch.InProject {
+			note = ui.Sprint(ui.RoleMuted, "  (deleted from the project)")
+			stale++
+		}
+		last := ""
+		if branch.LastTime != "" {
+			last = "last " + branch.LastTime
+		}
+		fmt.Printf("%s %-30s %6d snapshots  %s%s\n", marker, branch.Name, branch.Snapshots, last, note)
+	}
+
+	if stale > 0 {
+		fmt.Println()
+		fmt.Printf("Use 'timemachine branch prune --stale' to delete the %d branch(es) deleted from the project\n", stale)
+	}
+	return nil
+}
+
+func runBranchPrune(names []string, stale, force bool) error {
+	gitManager, err := branchGitManager()
+	if err != nil || gitManager == nil {
+		return err
+	}
+
+	if stale {
+		branches, err := gitManager.ShadowBranches()
+		if err != nil {
+			return err
+		}
+		for _, branch := range branches {
+			if !branch.InProject && !branch.Current {
+				names = append(names, branch.Name)
+			}
+		}
+		if len(names) == 0 {
+			fmt.Println("No stale shadow branches.")
+			return nil
+		}
+	}
+
+	deleted, err := gitManager.DeleteShadowBranches(names, force)
+	if err != nil && len(deleted) == 0 {
+		return err
+	}
+	total := 0
+	pruned := make([]string, len(deleted))
+	for i, branch := range deleted {
+		total += branch.Unique
+		pruned[i] = branch.Name
+	}
+	ui.Success("✅ Pruned %s (%d snapshots deleted)", strings.Join(pruned, ", "), total)
+	if err != nil {
+		ui.Warning("⚠️  %v", err)
+	}
+	return nil
+}
+
+// branchGitManager opens the shadow repository, returning
+// core.ErrNotInitialized after telling the user when it isn't initialized
+func branchGitManager() (*core.GitManager, error) {
+	state, err := core.NewAppState()
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize app state: %w", err)
+	}
+	if !state.IsInitialized {
+		ui.Error("%s", ui.T(ui.MsgNotInitialized))
+		fmt.Println(ui.T(ui.MsgRunInit))
+		return nil, core.Reported(core.ErrNotInitialized)
+	}
+	return core.NewGitManager(state), nil
+}
@@ -0,0 +1,227 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/core"
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// maxChangelogFiles caps the per-group diffstat listing
+const maxChangelogFiles = 15
+
+// ChangelogCmd creates the changelog command
+func ChangelogCmd() *cobra.Command {
+	var (
+		since      string
+		groupBy    string
+		format     string
+		sessionGap time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:   "changelog",
+		Short: "Summarize snapshots as a Markdown changelog",
+		Long: `Compose a summary of snapshots, grouped into work sessions or days, with
+diffstats for each group. Useful as a head start for writing the real commit
+message or PR description after a long AI-assisted work burst.
+
+A session is a run of snapshots with no more than --session-gap between them.
+
+Examples:
+  timemachine changelog                          # Everything, grouped by session
+  timemachine changelog --since 4h               # Last four hours
+  timemachine changelog --since abc1234          # Snapshots after abc1234
+  timemachine changelog --since 2024-05-01 --group-by day
+  timemachine changelog --format json > changes.json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runChangelog(os.Stdout, since, groupBy, format, sessionGap)
+		},
+	}
+
+	cmd.Flags().StringVar(&since, "since", "", "Start point: snapshot hash, age (e.g. 2h, 7d) or date (YYYY-MM-DD)")
+	cmd.Flags().StringVar(&groupBy, "group-by", "session", "Grouping: session or day")
+	cmd.Flags().StringVar(&format, "format", "markdown", "Output format (markdown, json)")
+	cmd.Flags().DurationVar(&sessionGap, "session-gap", core.DefaultSessionGap, "Idle time that starts a new session")
+
+	return cmd
+}
+
+// changelogGroup is one session or day in the changelog
+type changelogGroup struct {
+	Title      string              `json:"title"`
+	Start      time.Time           `json:"start"`
+	End        time.Time           `json:"end"`
+	Insertions int                 `json:"insertions"`
+	Deletions  int                 `json:"deletions"`
+	Snapshots  []changelogSnapshot `json:"snapshots"`
+	Files      []changelogFile     `json:"files"`
+}
+
+type changelogSnapshot struct {
+	Hash       string    `json:"hash"`
+	Time       time.Time `json:"time"`
+	Message    string    `json:"message"`
+	Files      int       `json:"files"`
+	Insertions int       `json:"insertions"`
+	Deletions  int       `json:"deletions"`
+}
+
+type changelogFile struct {
+	Path       string `json:"path"`
+	Insertions int    `json:"insertions"`
+	Deletions  int    `json:"deletions"`
+	Binary     bool   `json:"binary,omitempty"`
+}
+
+func runChangelog(out io.Writer, since, groupBy, format string, sessionGap time.Duration) error {
+	if groupBy != "session" && groupBy != "day" {
+		return fmt.Errorf("unsupported --group-by: %s (use 'session' or 'day')", groupBy)
+	}
+	if format != "markdown" && format != "json" {
+		return fmt.Errorf("unsupported format: %s (use 'markdown' or 'json')", format)
+	}
+
+	// Create application state
+	state, err := core.NewAppState()
+	if err != nil {
+		return fmt.Errorf("failed to initialize app state: %w", err)
+	}
+
+	// Check if initialized
+	if !state.IsInitialized {
+		ui.Error("%s", ui.T(ui.MsgNotInitialized))
+		fmt.Println(ui.T(ui.MsgRunInit))
+		return core.Reported(core.ErrNotInitialized)
+	}
+
+	gitManager := core.NewGitManager(state)
+
+	snapshots, err := gitManager.LogChanges(since)
+	if err != nil {
+		return err
+	}
+
+	var grouped [][]core.SnapshotChanges
+	if groupBy == "day" {
+		grouped = core.GroupByDay(snapshots)
+	} else {
+		grouped = core.GroupSessions(snapshots, sessionGap)
+	}
+
+	groups := make([]changelogGroup, 0, len(grouped))
+	for i, group := range grouped {
+		groups = append(groups, buildChangelogGroup(i+1, groupBy, group))
+	}
+
+	if format == "json" {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(groups)
+	}
+
+	writeChangelogMarkdown(out, groups, len(snapshots))
+	return nil
+}
+
+func buildChangelogGroup(index int, groupBy string, snapshots []core.SnapshotChanges) changelogGroup {
+	group := changelogGroup{
+		Start: snapshots[0].Time,
+		End:   snapshots[len(snapshots)-1].Time,
+	}
+
+	if groupBy == "day" {
+		group.Title = group.Start.Local().Format("Monday, 2 January 2006")
+	} else {
+		group.Title = fmt.Sprintf("Session %d", index)
+	}
+
+	for _, snapshot := range snapshots {
+		insertions, deletions := snapshot.Totals()
+		group.Insertions += insertions
+		group.Deletions += deletions
+		group.Snapshots = append(group.Snapshots, changelogSnapshot{
+			Hash:       snapshot.Hash,
+			Time:       snapshot.Time,
+			Message:    snapshot.Message,
+			Files:      len(snapshot.Files),
+			Insertions: insertions,
+			Deletions:  deletions,
+		})
+	}
+
+	for _, file := range core.AggregateFiles(snapshots) {
+		group.Files = append(group.Files, changelogFile{
+			Path:       file.Path,
+			Insertions: file.Insertions,
+			Deletions:  file.Deletions,
+			Binary:     file.Binary,
+		})
+	}
+
+	return group
+}
+
+func writeChangelogMarkdown(out io.Writer, groups []changelogGroup, total int) {
+	fmt.Fprintln(out, "# Time Machine changelog")
+	fmt.Fprintln(out)
+
+	if total == 0 {
+		fmt.Fprintln(out, "_No snapshots found._")
+		return
+	}
+
+	first := groups[0].Start.Local()
+	last := groups[len(groups)-1].End.Local()
+	fmt.Fprintf(out, "_%d snapshots in %d group(s), %s – %s_\n",
+		total, len(groups), first.Format("2006-01-02 15:04"), last.Format("2006-01-02 15:04"))
+
+	for _, group := range groups {
+		fmt.Fprintln(out)
+		fmt.Fprintf(out, "## %s (%s–%s)\n", group.Title,
+			group.Start.Local().Format("15:04"), group.End.Local().Format("15:04"))
+		fmt.Fprintln(out)
+		fmt.Fprintf(out, "%d snapshot(s), %d file(s) touched, +%d / -%d lines\n",
+			len(group.Snapshots), len(group.Files), group.Insertions, group.Deletions)
+		fmt.Fprintln(out)
+
+		fmt.Fprintln(out, "| Snapshot | Time | Message | Changes |")
+		fmt.Fprintln(out, "|----------|------|---------|---------|")
+		for _, snapshot := range group.Snapshots {
+			fmt.Fprintf(out, "| `%s` | %s | %s | %d file(s), +%d/-%d |\n",
+				snapshot.Hash[:8],
+				snapshot.Time.Local().Format("15:04:05"),
+				escapeMarkdownCell(snapshot.Message),
+				snapshot.Files, snapshot.Insertions, snapshot.Deletions)
+		}
+
+		if len(group.Files) > 0 {
+			fmt.Fprintln(out)
+			fmt.Fprintln(out, "**Diffstat**")
+			fmt.Fprintln(out)
+			for i, file := range group.Files {
+				if i >= maxChangelogFiles {
+					fmt.Fprintf(out, "- …and %d more file(s)\n", len(group.Files)-maxChangelogFiles)
+					break
+				}
+				if file.Binary {
+					fmt.Fprintf(out, "- `%s` (binary)\n", file.Path)
+				} else {
+					fmt.Fprintf(out, "- `%s` +%d -%d\n", file.Path, file.Insertions, file.Deletions)
+				}
+			}
+		}
+	}
+}
+
+// escapeMarkdownCell keeps messages from breaking the table layout
+func escapeMarkdownCell(s string) string {
+	return strings.ReplaceAll(s, "|", "\\|")
+}
@@ -0,0 +1,50 @@
+package commands
+
+import (
+	"testing"
+	"time"
+
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/core"
+)
+
+func TestFilterByAge(t *testing.T) {
+	now := time.Date(2024, 5, 20, 12, 0, 0, 0, time.UTC)
+	snapshots := []core.Snapshot{
+		{Hash: "e", Timestamp: now.Add(-30 * time.Minute)},
+		{Hash: "d", Timestamp: now.Add(-3 * time.Hour)},
+		{Hash: "c", Timestamp: now.Add(-2 * 24 * time.Hour)},
+		{Hash: "b", Timestamp: now.Add(-10 * 24 * time.Hour)},
+		// Same instant expressed in another timezone must compare equally
+		{Hash: "a", Timestamp: now.Add(-40 * 24 * time.Hour).In(time.FixedZone("UTC+9", 9*3600))},
+	}
+
+	testCases := []struct {
+		olderThan  string
+		wantRemove int
+		wantKeep   int
+	}{
+		{"1h", 4, 1},
+		{"1d", 3, 2},
+		{"1w", 2, 3},
+		{"1m", 1, 4},
+		{"1y", 0, 5},
+		{"10min", 5, 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.olderThan, func(t *testing.T) {
+			toRemove, keep, err := filterByAge(snapshots, tc.olderThan, now)
+			if err != nil {
+				t.Fatalf("filterByAge(%q) unexpected error: %v", tc.olderThan, err)
+			}
+			if len(toRemove) != tc.wantRemove || keep != tc.wantKeep {
+				t.Errorf("filterByAge(%q) = remove %d keep %d, want remove %d keep %d",
+					tc.olderThan, len(toRemove), keep, tc.wantRemove, tc.wantKeep)
+			}
+		})
+	}
+
+	if _, _, err := filterByAge(snapshots, "7x", now); err == nil {
+		t.Error("Expected error for invalid duration unit")
+	}
+}
@@ -0,0 +1,147 @@
+package commands
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/core"
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/ui"
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/utils"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// compactListGroups is how many groups a compaction lists before summing up
+// the rest
+const compactListGroups = 10
+
+// CompactCmd creates the compact command
+func CompactCmd() *cobra.Command {
+	var (
+		olderThan string
+		interval  string
+		dryRun    bool
+		auto      bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "compact",
+		Short: "Squash old snapshots into hourly or daily ones",
+		Long: `Squash old snapshots to reclaim space while keeping recent history granular.
+
+Snapshots older than --older-than are grouped by --interval, aligned to your
+local time (so 1d means calendar days), and each run of snapshots within one
+interval is replaced by a single snapshot: the newest one of the run, with
+its files and message. Nothing is lost that the newest snapshot doesn't
+contain, but the intermediate versions are gone. Newer snapshots are left
+alone, and compacting again with a larger interval keeps squashing.
+
+Supported units: min (minutes), h (hours), d (days), w (weeks), m (months)
+and y (years). Compacted snapshots and all later ones get new hashes.
+
+Examples:
+  timemachine compact                              # Hourly beyond a week
+  timemachine compact --older-than 30d --interval 1d
+  timemachine compact --dry-run                    # Show what would be squashed
+  timemachine compact --auto                       # No confirmation`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCompact(olderThan, interval, dryRun, auto)
+		},
+	}
+
+	cmd.Flags().StringVar(&olderThan, "older-than", "7d", "Only squash snapshots older than this (e.g., 12h, 7d, 2w)")
+	cmd.Flags().StringVar(&interval, "interval", "1h", "Keep one snapshot per interval (e.g., 1h, 1d, 1w)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would be squashed without changing anything")
+	cmd.Flags().BoolVar(&auto, "auto", false, "Skip confirmation prompt")
+
+	return cmd
+}
+
+func runCompact(olderThanFlag, intervalFlag string, dryRun, auto bool) error {
+	olderThan, err := core.ParseAge(olderThanFlag)
+	if err != nil {
+		return fmt.Errorf("invalid --older-than: %w", err)
+	}
+	interval, err := core.ParseAge(intervalFlag)
+	if err != nil {
+		return fmt.Errorf("invalid --interval: %w", err)
+	}
+
+	state, err := loadInitializedState()
+	if err != nil || state == nil {
+		return err
+	}
+	gitManager := core.NewGitManager(state)
+
+	plan, err := gitManager.PlanCompaction(olderThan, interval, time.Now())
+	if err != nil {
+		return err
+	}
+	if len(plan.Groups) == 0 {
+		fmt.Printf("📸 No two snapshots older than %s share an interval of %s. Nothing to compact.\n", olderThanFlag, intervalFlag)
+		return nil
+	}
+
+	squashed := plan.Squashed()
+	fmt.Println("🗜️  Time Machine Compaction")
+	fmt.Println()
+	fmt.Printf("Total snapshots: %d\n", plan.Total)
+	fmt.Printf("Will squash: %d snapshots into %d\n", squashed+len(plan.Groups), len(plan.Groups))
+	fmt.Printf("Will keep: %d snapshots\n", plan.Total-squashed)
+	fmt.Println()
+	for i, group := range plan.Groups {
+		if i == compactListGroups {
+			fmt.Println(ui.Sprint(ui.RoleMuted, fmt.Sprintf("  … and %d more intervals", len(plan.Groups)-i)))
+			break
+		}
+		newest := group.Snapshots[0]
+		fmt.Printf("  • %s  %3d → 1  %s  %s\n",
+			group.Start.Format("2006-01-02 15:04"),
+			len(group.Snapshots),
+			core.ShortHash(newest.Hash),
+			utils.TruncateString(newest.Message, 40))
+	}
+	fmt.Println()
+
+	if dryRun {
+		fmt.Println("Dry run: nothing was changed.")
+		return nil
+	}
+
+	if !auto {
+		confirmed, err := ui.Confirm("Do you want to continue?")
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			fmt.Println("Compaction cancelled.")
+			return nil
+		}
+		fmt.Println()
+	}
+
+	before, _ := core.NewStatsCollector(gitManager).DiskUsage()
+	fmt.Print("🗜️  Compacting snapshots... ")
+	_, err = gitManager.Compact(plan)
+	if core.IsLowDiskSpace(err) {
+		// History was compacted; only the space-hungry gc was skipped
+		color.Yellow("⚠️  %v", err)
+		fmt.Println("   Squashed snapshots stay on disk until garbage collection runs. Once space is")
+		fmt.Println("   freed, run: git --git-dir=.git/timemachine_snapshots gc --prune=now")
+		return nil
+	}
+	if err != nil {
+		color.Red("❌")
+		return fmt.Errorf("failed to compact snapshots: %w", err)
+	}
+	color.Green("✅")
+	fmt.Println()
+
+	color.Green("✨ Compacted %d snapshots into %d.", squashed+len(plan.Groups), len(plan.Groups))
+	if after, err := core.NewStatsCollector(gitManager).DiskUsage(); err == nil && before > 0 {
+		fmt.Printf("   Snapshot repository: %s → %s\n", utils.FormatBytes(before), utils.FormatBytes(after))
+	}
+	fmt.Println("   Note: compacted and later snapshots were rewritten and have new hashes.")
+	return nil
+}
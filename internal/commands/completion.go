@@ -0,0 +1,140 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/config"
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/core"
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+// completionSnapshots is how many snapshot hashes are suggested at most
+const completionSnapshots = 50
+
+// CompletionCmd creates the completion command
+func CompletionCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "completion bash|zsh|fish|powershell",
+		Short: "Generate shell completion scripts",
+		Long: `Generate a completion script for your shell. Besides commands and flags it
+completes snapshot hashes (with their messages) for restore, inspect, show and
+diff, and configuration keys for config get and set.
+
+Bash (requires bash-completion):
+  source <(timemachine completion bash)
+  # permanently, on Linux:
+  timemachine completion bash > /etc/bash_completion.d/timemachine
+
+Zsh:
+  timemachine completion zsh > "${fpath[1]}/_timemachine"
+  # then start a new shell; compinit must be enabled
+
+Fish:
+  timemachine completion fish > ~/.config/fish/completions/timemachine.fish
+
+PowerShell:
+  timemachine completion powershell | Out-String | Invoke-Expression
+  # permanently, add the line above to your $PROFILE`,
+		ValidArgs:             []string{"bash", "zsh", "fish", "powershell"},
+		Args:                  cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+		DisableFlagsInUseLine: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			root := cmd.Root()
+			switch args[0] {
+			case "bash":
+				return root.GenBashCompletionV2(os.Stdout, true)
+			case "zsh":
+				return root.GenZshCompletion(os.Stdout)
+			case "fish":
+				return root.GenFishCompletion(os.Stdout, true)
+			case "powershell":
+				return root.GenPowerShellCompletionWithDesc(os.Stdout)
+			}
+			return fmt.Errorf("unsupported shell %q", args[0])
+		},
+	}
+}
+
+// errEnoughCompletions stops the snapshot walk once enough hashes were found
+var errEnoughCompletions = errors.New("enough completions")
+
+// completeSnapshots suggests snapshot hashes for the first hashArgs
+// arguments, newest first, described by their message and age. Later
+// arguments complete as files when filesAfter is set.
+func completeSnapshots(hashArgs int, filesAfter bool) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) >= hashArgs {
+			if filesAfter {
+				return nil, cobra.ShellCompDirectiveDefault
+			}
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return snapshotCompletions(toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// snapshotCompletions returns "hash<TAB>description" pairs for snapshots
+// whose hash starts with prefix
+func snapshotCompletions(prefix string) []string {
+	state, err := core.NewAppState()
+	if err != nil || !state.IsInitialized {
+		return nil
+	}
+
+	prefix = strings.ToLower(prefix)
+	var completions []string
+	core.NewGitManager(state).WalkSnapshots(core.SnapshotFilter{}, func(snapshot core.Snapshot) error {
+		if !strings.HasPrefix(snapshot.Hash, prefix) {
+			return nil
+		}
+		hash := core.ShortHash(snapshot.Hash)
+		if len(prefix) > len(hash) {
+			hash = snapshot.Hash
+		}
+		completions = append(completions, fmt.Sprintf("%s\t%s (%s)", hash, utils.TruncateString(snapshot.Message, 50), snapshot.Time))
+		if len(completions) >= completionSnapshots {
+			return errEnoughCompletions
+		}
+		return nil
+	})
+	return completions
+}
+
+// completeShadowBranches suggests shadow branch names, described by their
+// snapshot counts
+func completeShadowBranches(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	state, err := core.NewAppState()
+	if err != nil || !state.IsInitialized {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	branches, err := core.NewGitManager(state).ShadowBranches()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var names []string
+	for _, branch := range branches {
+		if strings.HasPrefix(branch.Name, toComplete) {
+			names = append(names, fmt.Sprintf("%s\t%d snapshots", branch.Name, branch.Snapshots))
+		}
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeConfigKeys suggests configuration keys for the first argument
+func completeConfigKeys(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var keys []string
+	for _, key := range config.Keys() {
+		if strings.HasPrefix(key, toComplete) {
+			keys = append(keys, key)
+		}
+	}
+	return keys, cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,33 @@
+package commands
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestCompleteConfigKeys(t *testing.T) {
+	keys, directive := completeConfigKeys(nil, nil, "git.b")
+	if !reflect.DeepEqual(keys, []string{"git.backend"}) {
+		t.Errorf("Expected [git.backend], got %v", keys)
+	}
+	if directive != cobra.ShellCompDirectiveNoFileComp {
+		t.Errorf("Expected no file completion, got %v", directive)
+	}
+
+	// The value of config set is not completed
+	if keys, _ := completeConfigKeys(nil, []string{"git.backend"}, ""); len(keys) != 0 {
+		t.Errorf("Expected no completions for the value, got %v", keys)
+	}
+}
+
+func TestCompleteSnapshotsAfterHashes(t *testing.T) {
+	// Arguments after the hashes complete as files only where paths follow
+	if _, directive := completeSnapshots(1, true)(nil, []string{"abc"}, ""); directive != cobra.ShellCompDirectiveDefault {
+		t.Errorf("Expected file completion after the hash, got %v", directive)
+	}
+	if _, directive := completeSnapshots(2, false)(nil, []string{"abc", "def"}, ""); directive != cobra.ShellCompDirectiveNoFileComp {
+		t.Errorf("Expected no completion after both hashes, got %v", directive)
+	}
+}
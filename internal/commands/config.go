@@ -1,13 +1,20 @@
 package commands
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/config"
 	"github.com/deepakkumarnarayana/timemachine-cli/internal/core"
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/daemon"
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/presets"
 )
 
 // ConfigCmd creates the config command with subcommands
@@ -33,6 +40,9 @@ Configuration is loaded from multiple sources in order of precedence:
 	cmd.AddCommand(configGetCmd())
 	cmd.AddCommand(configSetCmd())
 	cmd.AddCommand(configValidateCmd())
+	cmd.AddCommand(configReloadCmd())
+	cmd.AddCommand(configProfilesCmd())
+	cmd.AddCommand(configSchemaCmd())
 
 	return cmd
 }
@@ -90,6 +100,7 @@ func configGetCmd() *cobra.Command {
 		Short: "Get a configuration value",
 		Long:  "Get a specific configuration value by key (e.g., 'log.level', 'watcher.debounce_delay')",
 		Args:  cobra.ExactArgs(1),
+		ValidArgsFunction: completeConfigKeys,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return getConfigValue(args[0])
 		},
@@ -105,6 +116,7 @@ func configSetCmd() *cobra.Command {
 		Short: "Set a configuration value",
 		Long:  "Set a configuration value in the configuration file",
 		Args:  cobra.ExactArgs(2),
+		ValidArgsFunction: completeConfigKeys,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return setConfigValue(args[0], args[1], global)
 		},
@@ -127,6 +139,80 @@ func configValidateCmd() *cobra.Command {
 	}
 }
 
+// configReloadCmd applies configuration changes to the running watcher
+func configReloadCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "reload",
+		Short: "Apply configuration changes to the running watcher",
+		Long: `Validate the configuration and have the running watcher, in the background or
+in the foreground, load it without restarting. The same happens when the
+watcher receives SIGHUP.
+
+The debounce delay, batching, ignore and cache settings, hooks, secrets,
+retention and message template apply right away. metrics.listen, git.backend
+and git.verify_* are reported as needing a restart.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return reloadConfig()
+		},
+	}
+}
+
+// configProfilesCmd groups the profile subcommands
+func configProfilesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "profiles",
+		Short: "Show the ecosystem profiles init can seed a project with",
+	}
+	cmd.AddCommand(&cobra.Command{
+		Use:   "list",
+		Short: "List the available profiles",
+		Long: `List the profiles 'timemachine init --profile <name>' can seed a project
+with: the watcher settings written to timemachine.yaml and the patterns added
+to .timemachine-ignore.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return listProfiles()
+		},
+	})
+	return cmd
+}
+
+// configSchemaCmd prints the JSON Schema of timemachine.yaml
+func configSchemaCmd() *cobra.Command {
+	var format string
+
+	cmd := &cobra.Command{
+		Use:   "schema",
+		Short: "Print the JSON Schema of timemachine.yaml",
+		Long: `Print a JSON Schema of timemachine.yaml, with every setting's type, allowed
+values, range, default and description, so that YAML language servers can
+validate and complete the file. It is generated from the configuration this
+binary reads, so it matches its version.
+
+Examples:
+  timemachine config schema > timemachine.schema.json
+  # then start timemachine.yaml with:
+  # yaml-language-server: $schema=./timemachine.schema.json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if format != "jsonschema" {
+				return fmt.Errorf("unsupported format %q (use jsonschema)", format)
+			}
+			schema, err := config.Schema()
+			if err != nil {
+				return fmt.Errorf("failed to generate schema: %w", err)
+			}
+			fmt.Println(string(schema))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&format, "format", "jsonschema", "Output format (jsonschema)")
+
+	return cmd
+}
+
 // Implementation functions
 
 func initProjectConfig(force bool) error {
@@ -218,10 +304,17 @@ func showConfig(format string) error {
 
 watcher:
   debounce_delay: %s
+  debounce_overrides: %s
   max_watched_files: %d
   ignore_patterns: %v
+  include_paths: %v
   batch_size: %d
+  batch_window: %s
+  interval_snapshot: %s
+  storm_threshold: %d
   enable_recursive: %t
+  respect_gitignore: %t
+  churn_filters: %s
 
 cache:
   max_entries: %d
@@ -232,21 +325,80 @@ cache:
 git:
   cleanup_threshold: %d
   auto_gc: %t
+  auto_heal: %t
   max_commits: %d
+  max_repo_size_mb: %d
   use_shallow_clone: %t
+  backend: %s
+  min_free_space_mb: %d
+  verify_interval: %s
+  verify_sample: %d
+  message_template: %q
+  signing_key: %q
+  provenance: %t
 
 ui:
   progress_indicators: %t
   color_output: %t
   pager: %s
   table_format: %s
+  list_stats: %s
+  theme: %s
+  syntax_theme: %s
+  language: %s
+  emoji: %t
+
+retention:
+  max_age: %q
+  max_total_size_mb: %d
+  keep_hourly: %d
+  keep_daily: %d
+  keep_weekly: %d
+
+hooks:
+  pre_snapshot: %s
+  post_snapshot: %s
+  post_restore: %s
+  timeout: %s
+
+metrics:
+  listen: %q
+
+secrets:
+  mode: %s
+  allow: %s
+  deny: %s
+
+telemetry:
+  enabled: %t
+  endpoint: %q
+
+remote:
+  name: %s
+  machine: %q
+  push_interval: %s
+
+backup:
+  url: %q
+  interval: %s
+
+detect:
+  agents: %t
 `,
 				state.Config.Log.Level, state.Config.Log.Format, state.Config.Log.File,
-				state.Config.Watcher.DebounceDelay, state.Config.Watcher.MaxWatchedFiles, state.Config.Watcher.IgnorePatterns,
-				state.Config.Watcher.BatchSize, state.Config.Watcher.EnableRecursive,
+				state.Config.Watcher.DebounceDelay, debounceOverrideList(state.Config.Watcher.DebounceOverrides, false), state.Config.Watcher.MaxWatchedFiles, state.Config.Watcher.IgnorePatterns, quotedList(state.Config.Watcher.IncludePaths),
+				state.Config.Watcher.BatchSize, state.Config.Watcher.BatchWindow, state.Config.Watcher.IntervalSnapshot, state.Config.Watcher.StormThreshold, state.Config.Watcher.EnableRecursive, state.Config.Watcher.RespectGitignore, churnFilterList(state.Config.Watcher.ChurnFilters, false),
 				state.Config.Cache.MaxEntries, state.Config.Cache.MaxMemoryMB, state.Config.Cache.TTL, state.Config.Cache.EnableLRU,
-				state.Config.Git.CleanupThreshold, state.Config.Git.AutoGC, state.Config.Git.MaxCommits, state.Config.Git.UseShallowClone,
-				state.Config.UI.ProgressIndicators, state.Config.UI.ColorOutput, state.Config.UI.Pager, state.Config.UI.TableFormat)
+				state.Config.Git.CleanupThreshold, state.Config.Git.AutoGC, state.Config.Git.AutoHeal, state.Config.Git.MaxCommits, state.Config.Git.MaxRepoSizeMB, state.Config.Git.UseShallowClone, state.Config.Git.Backend, state.Config.Git.MinFreeSpaceMB, state.Config.Git.VerifyInterval, state.Config.Git.VerifySample, state.Config.Git.MessageTemplate, state.Config.Git.SigningKey, state.Config.Git.Provenance,
+				state.Config.UI.ProgressIndicators, state.Config.UI.ColorOutput, state.Config.UI.Pager, state.Config.UI.TableFormat, state.Config.UI.ListStats, state.Config.UI.Theme, state.Config.UI.SyntaxTheme, state.Config.UI.Language, state.Config.UI.Emoji,
+				state.Config.Retention.MaxAge, state.Config.Retention.MaxTotalSizeMB, state.Config.Retention.KeepHourly, state.Config.Retention.KeepDaily, state.Config.Retention.KeepWeekly,
+				quotedList(state.Config.Hooks.PreSnapshot), quotedList(state.Config.Hooks.PostSnapshot), quotedList(state.Config.Hooks.PostRestore), state.Config.Hooks.Timeout,
+				state.Config.Metrics.Listen,
+				state.Config.Secrets.Mode, quotedList(state.Config.Secrets.Allow), quotedList(state.Config.Secrets.Deny),
+				state.Config.Telemetry.Enabled, state.Config.Telemetry.Endpoint,
+				state.Config.Remote.Name, state.Config.Remote.Machine, state.Config.Remote.PushInterval,
+				state.Config.Backup.URL, state.Config.Backup.Interval,
+				state.Config.Detect.Agents)
 	case "json":
 		// Convert to JSON (simplified version)
 		fmt.Printf(`{
@@ -257,10 +409,17 @@ ui:
   },
   "watcher": {
     "debounce_delay": "%s",
+    "debounce_overrides": %s,
     "max_watched_files": %d,
     "ignore_patterns": %v,
+    "include_paths": %v,
     "batch_size": %d,
-    "enable_recursive": %t
+    "batch_window": "%s",
+    "interval_snapshot": "%s",
+    "storm_threshold": %d,
+    "enable_recursive": %t,
+    "respect_gitignore": %t,
+    "churn_filters": %s
   },
   "cache": {
     "max_entries": %d,
@@ -271,22 +430,81 @@ ui:
   "git": {
     "cleanup_threshold": %d,
     "auto_gc": %t,
+    "auto_heal": %t,
     "max_commits": %d,
-    "use_shallow_clone": %t
+    "max_repo_size_mb": %d,
+    "use_shallow_clone": %t,
+    "backend": "%s",
+    "min_free_space_mb": %d,
+    "verify_interval": "%s",
+    "verify_sample": %d,
+    "message_template": %q,
+    "signing_key": %q,
+    "provenance": %t
   },
   "ui": {
     "progress_indicators": %t,
     "color_output": %t,
     "pager": "%s",
-    "table_format": "%s"
+    "table_format": "%s",
+    "list_stats": "%s",
+    "theme": "%s",
+    "syntax_theme": "%s",
+    "language": "%s",
+    "emoji": %t
+  },
+  "retention": {
+    "max_age": %q,
+    "max_total_size_mb": %d,
+    "keep_hourly": %d,
+    "keep_daily": %d,
+    "keep_weekly": %d
+  },
+  "hooks": {
+    "pre_snapshot": %s,
+    "post_snapshot": %s,
+    "post_restore": %s,
+    "timeout": "%s"
+  },
+  "metrics": {
+    "listen": %q
+  },
+  "secrets": {
+    "mode": "%s",
+    "allow": %s,
+    "deny": %s
+  },
+  "telemetry": {
+    "enabled": %t,
+    "endpoint": %q
+  },
+  "remote": {
+    "name": %q,
+    "machine": %q,
+    "push_interval": "%s"
+  },
+  "backup": {
+    "url": %q,
+    "interval": "%s"
+  },
+  "detect": {
+    "agents": %t
   }
 }`,
 			state.Config.Log.Level, state.Config.Log.Format, state.Config.Log.File,
-			state.Config.Watcher.DebounceDelay, state.Config.Watcher.MaxWatchedFiles, state.Config.Watcher.IgnorePatterns,
-			state.Config.Watcher.BatchSize, state.Config.Watcher.EnableRecursive,
+			state.Config.Watcher.DebounceDelay, debounceOverrideList(state.Config.Watcher.DebounceOverrides, true), state.Config.Watcher.MaxWatchedFiles, state.Config.Watcher.IgnorePatterns, quotedList(state.Config.Watcher.IncludePaths),
+			state.Config.Watcher.BatchSize, state.Config.Watcher.BatchWindow, state.Config.Watcher.IntervalSnapshot, state.Config.Watcher.StormThreshold, state.Config.Watcher.EnableRecursive, state.Config.Watcher.RespectGitignore, churnFilterList(state.Config.Watcher.ChurnFilters, true),
 			state.Config.Cache.MaxEntries, state.Config.Cache.MaxMemoryMB, state.Config.Cache.TTL, state.Config.Cache.EnableLRU,
-			state.Config.Git.CleanupThreshold, state.Config.Git.AutoGC, state.Config.Git.MaxCommits, state.Config.Git.UseShallowClone,
-			state.Config.UI.ProgressIndicators, state.Config.UI.ColorOutput, state.Config.UI.Pager, state.Config.UI.TableFormat)
+			state.Config.Git.CleanupThreshold, state.Config.Git.AutoGC, state.Config.Git.AutoHeal, state.Config.Git.MaxCommits, state.Config.Git.MaxRepoSizeMB, state.Config.Git.UseShallowClone, state.Config.Git.Backend, state.Config.Git.MinFreeSpaceMB, state.Config.Git.VerifyInterval, state.Config.Git.VerifySample, state.Config.Git.MessageTemplate, state.Config.Git.SigningKey, state.Config.Git.Provenance,
+			state.Config.UI.ProgressIndicators, state.Config.UI.ColorOutput, state.Config.UI.Pager, state.Config.UI.TableFormat, state.Config.UI.ListStats, state.Config.UI.Theme, state.Config.UI.SyntaxTheme, state.Config.UI.Language, state.Config.UI.Emoji,
+			state.Config.Retention.MaxAge, state.Config.Retention.MaxTotalSizeMB, state.Config.Retention.KeepHourly, state.Config.Retention.KeepDaily, state.Config.Retention.KeepWeekly,
+			quotedList(state.Config.Hooks.PreSnapshot), quotedList(state.Config.Hooks.PostSnapshot), quotedList(state.Config.Hooks.PostRestore), state.Config.Hooks.Timeout,
+			state.Config.Metrics.Listen,
+			state.Config.Secrets.Mode, quotedList(state.Config.Secrets.Allow), quotedList(state.Config.Secrets.Deny),
+			state.Config.Telemetry.Enabled, state.Config.Telemetry.Endpoint,
+			state.Config.Remote.Name, state.Config.Remote.Machine, state.Config.Remote.PushInterval,
+			state.Config.Backup.URL, state.Config.Backup.Interval,
+			state.Config.Detect.Agents)
 	default:
 		return fmt.Errorf("unsupported format: %s (use 'yaml' or 'json')", format)
 	}
@@ -304,7 +522,7 @@ func getConfigValue(key string) error {
 	// Get value from viper
 	value := state.ConfigManager.GetViper().Get(key)
 	if value == nil {
-		return fmt.Errorf("configuration key '%s' not found", key)
+		return core.NewNotFoundError("configuration key '%s' not found", key)
 	}
 
 	fmt.Printf("%v\n", value)
@@ -341,6 +559,73 @@ func setConfigValue(key, value string, global bool) error {
 	return nil
 }
 
+func listProfiles() error {
+	profiles, err := presets.All()
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Profiles (timemachine init --profile <name>):")
+	for _, profile := range profiles {
+		settings, err := profile.Settings()
+		if err != nil {
+			return err
+		}
+		keys := make([]string, 0, len(settings))
+		for key := range settings {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for i, key := range keys {
+			keys[i] = fmt.Sprintf("%s: %v", key, settings[key])
+		}
+
+		fmt.Println()
+		color.New(color.Bold).Printf("%s", profile.Name)
+		fmt.Printf(" - %s\n", profile.Description)
+		fmt.Printf("  Settings: %s\n", strings.Join(keys, ", "))
+		fmt.Printf("  Ignores:  %s\n", strings.Join(profile.Ignore, " "))
+	}
+	return nil
+}
+
+func reloadConfig() error {
+	state, err := core.NewLightAppState()
+	if err != nil {
+		return fmt.Errorf("failed to initialize app state: %w", err)
+	}
+
+	// Catch mistakes here rather than in the watcher's log
+	if err := config.NewManager().Load(state.ProjectRoot); err != nil {
+		return &core.ValidationError{Err: err}
+	}
+
+	pid, reload, err := daemon.NewManager(state).Reload()
+	if errors.Is(err, daemon.ErrNoWatcher) {
+		color.Yellow("Time Machine is not running; the configuration applies when it starts.")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if reload.Error != "" {
+		return fmt.Errorf("watcher (PID %d) kept its configuration: %s", pid, reload.Error)
+	}
+	if len(reload.Changed) == 0 {
+		color.Green("✅ Watcher (PID %d) reloaded its configuration; nothing changed", pid)
+		return nil
+	}
+	color.Green("✅ Watcher (PID %d) reloaded its configuration", pid)
+	for _, key := range reload.Changed {
+		fmt.Printf("• %s\n", key)
+	}
+	if len(reload.RestartRequired) > 0 {
+		color.Yellow("⚠️  Restart the watcher to apply: %s", strings.Join(reload.RestartRequired, ", "))
+	}
+	return nil
+}
+
 func validateConfig() error {
 	// Create application state
 	state, err := core.NewAppState()
@@ -348,8 +633,11 @@ func validateConfig() error {
 		return fmt.Errorf("failed to initialize app state: %w", err)
 	}
 
-	// Validation is performed during config loading
-	// If we reach here, configuration is valid
+	// NewAppState only warns about an invalid configuration and carries on
+	// with the defaults; here it is a failure
+	if err := config.NewManager().Load(state.ProjectRoot); err != nil {
+		return &core.ValidationError{Err: err}
+	}
 	color.Green("✅ Configuration is valid")
 
 	// Show configuration source information
@@ -360,14 +648,13 @@ func validateConfig() error {
 		fmt.Println("• No configuration file found (using defaults)")
 	}
 
-	// Show environment variable overrides
-	envVars := []string{
-		"TIMEMACHINE_LOG_LEVEL", "TIMEMACHINE_LOG_FORMAT", "TIMEMACHINE_LOG_FILE",
-		"TIMEMACHINE_WATCHER_DEBOUNCE", "TIMEMACHINE_WATCHER_MAX_FILES",
-		"TIMEMACHINE_CACHE_MAX_ENTRIES", "TIMEMACHINE_CACHE_MAX_MEMORY", "TIMEMACHINE_CACHE_TTL",
-		"TIMEMACHINE_GIT_CLEANUP_THRESHOLD", "TIMEMACHINE_GIT_AUTO_GC",
-		"TIMEMACHINE_UI_COLOR", "TIMEMACHINE_UI_PAGER",
+	// Show environment variable overrides, of every variable the
+	// configuration binds
+	envVars := make([]string, 0, len(config.EnvBindings()))
+	for envVar := range config.EnvBindings() {
+		envVars = append(envVars, envVar)
 	}
+	sort.Strings(envVars)
 
 	envOverrides := []string{}
 	for _, envVar := range envVars {
@@ -384,4 +671,41 @@ func validateConfig() error {
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+
+// quotedList formats a list of strings so it reads back as both YAML and JSON
+func quotedList(items []string) string {
+	quoted := make([]string, len(items))
+	for i, item := range items {
+		quoted[i] = strconv.Quote(item)
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
+
+// debounceOverrideList formats watcher.debounce_overrides as a YAML flow
+// sequence, or a JSON array
+func debounceOverrideList(overrides []config.DebounceOverride, asJSON bool) string {
+	items := make([]string, len(overrides))
+	for i, override := range overrides {
+		if asJSON {
+			items[i] = fmt.Sprintf("{\"pattern\": %q, \"delay\": \"%s\"}", override.Pattern, override.Delay)
+		} else {
+			items[i] = fmt.Sprintf("{pattern: %q, delay: %s}", override.Pattern, override.Delay)
+		}
+	}
+	return "[" + strings.Join(items, ", ") + "]"
+}
+
+// churnFilterList formats watcher.churn_filters as a YAML flow sequence, or
+// a JSON array
+func churnFilterList(filters []config.ChurnFilter, asJSON bool) string {
+	items := make([]string, len(filters))
+	for i, filter := range filters {
+		if asJSON {
+			items[i] = fmt.Sprintf("{\"pattern\": %q, \"normalize\": %s}", filter.Pattern, quotedList(filter.Normalize))
+		} else {
+			items[i] = fmt.Sprintf("{pattern: %q, normalize: %s}", filter.Pattern, quotedList(filter.Normalize))
+		}
+	}
+	return "[" + strings.Join(items, ", ") + "]"
+}
@@ -0,0 +1,116 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/core"
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/daemon"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// DaemonCmd creates the daemon command with subcommands
+func DaemonCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "daemon",
+		Short: "Manage the background watcher process",
+		Long: `Manage the Time Machine watcher running as a background process.
+
+Examples:
+  timemachine daemon start     # Same as 'timemachine start --daemon'
+  timemachine daemon status    # Show PID, uptime and log location
+  timemachine daemon stop      # Same as 'timemachine stop'`,
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "start",
+		Short: "Start the watcher in the background",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runStartDaemon()
+		},
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:   "stop",
+		Short: "Stop the background watcher",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runStop()
+		},
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:   "status",
+		Short: "Show background watcher status",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDaemonStatus()
+		},
+	})
+
+	return cmd
+}
+
+// StopCmd creates the stop command
+func StopCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "stop",
+		Short: "Stop the background watcher started with 'start --daemon'",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runStop()
+		},
+	}
+}
+
+func runStop() error {
+	// Create application state
+	state, err := core.NewAppState()
+	if err != nil {
+		return fmt.Errorf("failed to initialize app state: %w", err)
+	}
+
+	daemonManager := daemon.NewManager(state)
+
+	fmt.Print("🛑 Stopping Time Machine daemon... ")
+	pid, err := daemonManager.Stop()
+	if errors.Is(err, daemon.ErrNotRunning) {
+		color.Yellow("not running")
+		return nil
+	}
+	if err != nil {
+		color.Red("❌")
+		return err
+	}
+	color.Green("✅")
+	fmt.Printf("   Stopped process %d\n", pid)
+
+	return nil
+}
+
+func runDaemonStatus() error {
+	// Create application state
+	state, err := core.NewAppState()
+	if err != nil {
+		return fmt.Errorf("failed to initialize app state: %w", err)
+	}
+
+	status, err := daemon.NewManager(state).Status()
+	if err != nil {
+		return fmt.Errorf("failed to read daemon status: %w", err)
+	}
+
+	if status.Running {
+		color.Green("✅ Daemon: running")
+		fmt.Printf("   PID:     %d\n", status.PID)
+		if !status.StartedAt.IsZero() {
+			fmt.Printf("   Uptime:  %s\n", status.Uptime())
+		}
+		fmt.Printf("   Log:     %s\n", status.LogFile)
+		return nil
+	}
+
+	color.Yellow("⚠️  Daemon: not running")
+	if status.Stale {
+		fmt.Printf("   Removed stale PID file for process %d\n", status.PID)
+	}
+	fmt.Println("   Run 'timemachine start --daemon' to start it")
+
+	return nil
+}
@@ -0,0 +1,43 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// deprecationRemovalRelease is the release in which legacy spellings are
+// dropped. Renamed flags and commands keep working for two releases.
+const deprecationRemovalRelease = "v1.2.0"
+
+// aliasFlag registers oldName as a hidden, deprecated spelling of the existing
+// flag newName. Both names share one value, so callers only ever read newName.
+// Using the old name prints a warning to stderr, keeping stdout script-safe.
+func aliasFlag(cmd *cobra.Command, oldName, newName string) {
+	flags := cmd.Flags()
+	target := flags.Lookup(newName)
+	if target == nil {
+		panic(fmt.Sprintf("aliasFlag: command %q has no flag --%s", cmd.Name(), newName))
+	}
+
+	flags.AddFlag(&pflag.Flag{
+		Name:        oldName,
+		Usage:       target.Usage,
+		Value:       target.Value,
+		DefValue:    target.DefValue,
+		NoOptDefVal: target.NoOptDefVal,
+		Hidden:      true,
+		Deprecated:  fmt.Sprintf("use --%s instead (will be removed in %s)", newName, deprecationRemovalRelease),
+	})
+}
+
+// deprecateFlag marks the flag name, whose feature moved to another
+// command, as deprecated in favour of replacement. It keeps working, hidden
+// from help, and using it prints a warning to stderr.
+func deprecateFlag(cmd *cobra.Command, name, replacement string) {
+	message := fmt.Sprintf("use '%s' instead (will be removed in %s)", replacement, deprecationRemovalRelease)
+	if err := cmd.Flags().MarkDeprecated(name, message); err != nil {
+		panic(fmt.Sprintf("deprecateFlag: %v", err))
+	}
+}
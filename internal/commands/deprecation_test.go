@@ -0,0 +1,60 @@
+package commands
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestAliasFlag(t *testing.T) {
+	var files []string
+	cmd := &cobra.Command{Use: "restore", RunE: func(cmd *cobra.Command, args []string) error { return nil }}
+	cmd.Flags().StringSliceVarP(&files, "file", "f", []string{}, "Files to restore")
+	aliasFlag(cmd, "files", "file")
+
+	var stderr bytes.Buffer
+	cmd.SetErr(&stderr)
+	cmd.Flags().SetOutput(&stderr)
+	cmd.SetArgs([]string{"--files", "a.go,b.go"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(files, []string{"a.go", "b.go"}) {
+		t.Errorf("Expected legacy flag to populate --file, got %v", files)
+	}
+	if !strings.Contains(stderr.String(), "use --file instead") {
+		t.Errorf("Expected deprecation warning, got %q", stderr.String())
+	}
+	if !cmd.Flags().Lookup("files").Hidden {
+		t.Error("Expected legacy flag to be hidden from help")
+	}
+}
+
+func TestDeprecateFlag(t *testing.T) {
+	var searchAll bool
+	cmd := &cobra.Command{Use: "inspect", RunE: func(cmd *cobra.Command, args []string) error { return nil }}
+	cmd.Flags().BoolVarP(&searchAll, "search-all", "a", false, "Search all snapshots")
+	deprecateFlag(cmd, "search-all", "timemachine history <file>")
+
+	var stderr bytes.Buffer
+	cmd.SetErr(&stderr)
+	cmd.Flags().SetOutput(&stderr)
+	cmd.SetArgs([]string{"--search-all"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !searchAll {
+		t.Error("Expected the deprecated flag to keep working")
+	}
+	if !strings.Contains(stderr.String(), "use 'timemachine history <file>' instead") {
+		t.Errorf("Expected deprecation warning, got %q", stderr.String())
+	}
+	if flag := InspectCmd().Flags().Lookup("search-all"); flag == nil || flag.Deprecated == "" {
+		t.Error("Expected inspect --search-all to be deprecated in favour of history")
+	}
+}
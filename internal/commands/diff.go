@@ -0,0 +1,214 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/core"
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/render"
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/ui"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// DiffCmd creates the diff command
+func DiffCmd() *cobra.Command {
+	var (
+		stat       bool
+		files      []string
+		noPager    bool
+		sideBySide bool
+		ago        int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "diff <hashA> [hashB]",
+		Short: "Compare two snapshots, or a snapshot with the working tree",
+		Long: `Show the changes between two arbitrary snapshots. With a single hash, compare
+that snapshot with the current working tree, including files created since
+the last snapshot.
+
+--side-by-side shows the old and new version of each hunk in two columns
+with line numbers, fitted to the terminal width (COLUMNS, or 120 columns
+when the width is unknown). Long lines wrap within their column.
+
+Long output is shown through a pager according to the ui.pager setting
+(auto pages only on a terminal). The pager is taken from TIMEMACHINE_PAGER,
+then PAGER, and defaults to less.
+
+Examples:
+  timemachine diff abc1234 def5678            # What changed between two snapshots
+  timemachine diff abc1234                    # Snapshot vs. current files
+  timemachine diff abc1234 def5678 --stat     # Files changed with line counts
+  timemachine diff abc1234 --side-by-side     # Old and new side by side
+  timemachine diff abc1234 -f src/main.go     # Limit to one file
+  timemachine diff abc1234 --no-pager > changes.patch
+  timemachine diff latest~1 latest            # What the latest snapshot changed
+  timemachine diff -n 3                       # Three snapshots ago vs. current files
+
+Snapshots are full or short hashes, tags, 'latest', or revisions such as
+latest~3 or HEAD^; -n 3 names the first one as three before the latest.`,
+		Args:              cobra.RangeArgs(0, 2),
+		ValidArgsFunction: completeSnapshots(2, false),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			args, err := snapshotsAgoArgs(cmd, ago, args)
+			if err != nil {
+				return err
+			}
+			if len(args) == 0 || len(args) > 2 {
+				return core.NewValidationError("requires one or two snapshots, or -n and at most one")
+			}
+			to := ""
+			if len(args) == 2 {
+				to = args[1]
+			}
+			if stat && sideBySide {
+				return fmt.Errorf("--stat and --side-by-side cannot be used together")
+			}
+			return runDiff(args[0], to, files, stat, sideBySide, noPager)
+		},
+	}
+
+	cmd.Flags().BoolVar(&stat, "stat", false, "Show a diffstat instead of the full patch")
+	cmd.Flags().StringSliceVarP(&files, "file", "f", nil, "Limit the diff to specific files (comma-separated)")
+	cmd.Flags().BoolVar(&sideBySide, "side-by-side", false, "Show the old and new side of each change in two columns")
+	cmd.Flags().BoolVar(&noPager, "no-pager", false, "Write directly to stdout instead of a pager")
+	addSnapshotsAgoFlag(cmd, &ago)
+
+	return cmd
+}
+
+func runDiff(from, to string, files []string, stat, sideBySide, noPager bool) error {
+	pathspecs := make([]string, 0, len(files))
+	for _, file := range files {
+		sanitized, err := sanitizeFilePath(file)
+		if err != nil {
+			return fmt.Errorf("invalid file filter: %w", err)
+		}
+		pathspecs = append(pathspecs, sanitized)
+	}
+
+	state, err := loadInitializedState()
+	if err != nil || state == nil {
+		return err
+	}
+
+	// Short hashes, tags and relative revisions resolve to full hashes
+	gitManager := core.NewGitManager(state)
+	for _, hash := range []*string{&from, &to} {
+		if *hash == "" {
+			continue
+		}
+		if *hash, err = gitManager.ResolveSnapshot(*hash); err != nil {
+			return err
+		}
+	}
+	diff, err := gitManager.DiffSnapshots(from, to, core.DiffOptions{Stat: stat, Pathspecs: pathspecs})
+	if err != nil {
+		return err
+	}
+
+	target := "working tree"
+	if to != "" {
+		target = core.ShortHash(to)
+	}
+
+	if strings.TrimSpace(diff) == "" {
+		ui.Success("✅ No differences between %s and %s", core.ShortHash(from), target)
+		return nil
+	}
+
+	out, done := startPager(state, noPager)
+	defer done()
+
+	ui.Color(ui.RoleHeading).Fprintf(out, "🔍 Comparing %s → %s\n\n", core.ShortHash(from), target)
+	switch {
+	case stat:
+		writeDiffStat(out, diff)
+	case sideBySide:
+		render.SideBySide{Width: render.TerminalWidth(os.Stdout)}.Render(out, diff)
+	default:
+		writeColoredDiff(out, diff)
+	}
+
+	return nil
+}
+
+// writeColoredDiff prints a unified diff with git-like coloring, and the
+// code of each file highlighted by its language
+func writeColoredDiff(out io.Writer, diff string) {
+	header := color.New(color.Bold)
+	var highlighter *render.Highlighter
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git"):
+			highlighter = render.NewHighlighter(diffFileName(line))
+			ui.Color(ui.RoleDiffHeader).Fprintln(out, line)
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"),
+			strings.HasPrefix(line, "index "), strings.HasPrefix(line, "new file mode"),
+			strings.HasPrefix(line, "deleted file mode"), strings.HasPrefix(line, "similarity index"),
+			strings.HasPrefix(line, "rename from"), strings.HasPrefix(line, "rename to"):
+			header.Fprintln(out, line)
+		case strings.HasPrefix(line, "@@"):
+			ui.Color(ui.RoleDiffHunk).Fprintln(out, line)
+		case strings.HasPrefix(line, "+"):
+			fmt.Fprintln(out, highlightDiffLine(highlighter, ui.RoleAdded, line))
+		case strings.HasPrefix(line, "-"):
+			fmt.Fprintln(out, highlightDiffLine(highlighter, ui.RoleDeleted, line))
+		case strings.HasPrefix(line, " "):
+			fmt.Fprintln(out, " "+highlighter.Line(line[1:]))
+		default:
+			fmt.Fprintln(out, line)
+		}
+	}
+}
+
+// highlightDiffLine colors an added or removed line: the marker in the
+// role's color and the code by its language, or all of it in the role's
+// color without a highlighter
+func highlightDiffLine(highlighter *render.Highlighter, role ui.Role, line string) string {
+	if highlighter == nil {
+		return ui.Sprint(role, line)
+	}
+	return ui.Sprint(role, line[:1]) + highlighter.Line(line[1:])
+}
+
+// diffFileName returns the new path of a "diff --git a/old b/new" line
+func diffFileName(header string) string {
+	if i := strings.LastIndex(header, " b/"); i >= 0 {
+		return header[i+3:]
+	}
+	return ""
+}
+
+// writeDiffStat prints `git diff --stat` output with colored +/- bars
+func writeDiffStat(out io.Writer, stat string) {
+	green := ui.Color(ui.RoleAdded)
+	red := ui.Color(ui.RoleDeleted)
+
+	for _, line := range strings.Split(stat, "\n") {
+		bar := strings.LastIndex(line, "|")
+		if bar < 0 {
+			// Summary line ("N files changed, ...")
+			color.New(color.Bold).Fprintln(out, line)
+			continue
+		}
+
+		// RunCommand trims the output, so restore git's one-space indent
+		// on every file line rather than only the later ones
+		fmt.Fprint(out, " "+strings.TrimLeft(line[:bar+1], " "))
+		for _, r := range line[bar+1:] {
+			switch r {
+			case '+':
+				green.Fprint(out, string(r))
+			case '-':
+				red.Fprint(out, string(r))
+			default:
+				fmt.Fprint(out, string(r))
+			}
+		}
+		fmt.Fprintln(out)
+	}
+}
@@ -0,0 +1,118 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/core"
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// DoctorCmd creates the doctor command
+func DoctorCmd() *cobra.Command {
+	var fix bool
+
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Diagnose common problems and suggest fixes",
+		Long: `Check the environment and the project for common problems and print what to
+do about each, along with an overall health score:
+
+- git missing or too old for every feature
+- inotify watch limits too low for the project
+- an invalid configuration, misspelled TIMEMACHINE_* variables or variables
+  overriding the configuration file
+- a missing shadow repository, or one without user.name and user.email
+- Time Machine hooks Git skips because they aren't executable
+- a .timemachine-ignore over the size, line or pattern limits
+- configuration files other users can write to
+
+--fix applies the fixes that are safe to make unasked: copying the Git
+identity into the shadow repository, making hooks executable and restricting
+configuration file permissions. Exits with an error while problems remain.
+
+Examples:
+  timemachine doctor
+  timemachine doctor --fix`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Problems found are the answer, not a usage mistake
+			cmd.SilenceUsage = true
+			return runDoctor(fix)
+		},
+	}
+
+	cmd.Flags().BoolVar(&fix, "fix", false, "Apply the safe fixes")
+
+	return cmd
+}
+
+func runDoctor(fix bool) error {
+	state, err := core.NewLightAppState()
+	if err != nil {
+		return err
+	}
+
+	checks := core.Diagnose(state)
+	if fix {
+		fixed := 0
+		for _, check := range checks {
+			if check.Status == core.CheckOK || check.Apply == nil {
+				continue
+			}
+			if err := check.Apply(); err != nil {
+				ui.Error("❌ %s: %v", check.Name, err)
+				continue
+			}
+			ui.Success("🔧 %s: %s", check.Name, check.Fix)
+			fixed++
+		}
+		if fixed > 0 {
+			fmt.Println()
+			checks = core.Diagnose(state)
+		}
+	}
+
+	ui.Heading("🩺 Time Machine doctor")
+	fmt.Println()
+	failures, warnings, fixable := 0, 0, 0
+	for _, check := range checks {
+		switch check.Status {
+		case core.CheckOK:
+			ui.Success("✅ %-26s %s", check.Name, check.Detail)
+			continue
+		case core.CheckWarn:
+			warnings++
+			ui.Warning("⚠️  %-26s %s", check.Name, check.Detail)
+		default:
+			failures++
+			ui.Error("❌ %-26s %s", check.Name, check.Detail)
+		}
+		if check.Fix != "" {
+			fmt.Printf("   → %s\n", check.Fix)
+		}
+		if check.Apply != nil {
+			fixable++
+		}
+	}
+
+	fmt.Println()
+	score := core.HealthScore(checks)
+	summary := fmt.Sprintf("Health score: %d/100 (%d problem(s), %d warning(s))", score, failures, warnings)
+	switch {
+	case failures > 0:
+		ui.Error("%s", summary)
+	case warnings > 0:
+		ui.Warning("%s", summary)
+	default:
+		ui.Success("%s", summary)
+	}
+	if fixable > 0 && !fix {
+		fmt.Printf("Run 'timemachine doctor --fix' to apply %d safe fix(es)\n", fixable)
+	}
+
+	if failures > 0 {
+		return fmt.Errorf("doctor found %d problem(s)", failures)
+	}
+	return nil
+}
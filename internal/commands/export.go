@@ -0,0 +1,81 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/core"
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/ui"
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+// ExportCmd creates the export command
+func ExportCmd() *cobra.Command {
+	var (
+		output string
+		since  string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Package snapshots into a file for another machine",
+		Long: `Package the snapshot history into a single file that 'timemachine import'
+can restore in a clone of the project on another machine.
+
+The format follows the file extension: .tar.gz or .tgz writes an archive of
+the snapshot repository, anything else a git bundle. Bundles can be limited
+to recent snapshots with --since; such a bundle can only be imported where
+the earlier snapshots already exist, e.g. after importing a full export.
+
+Examples:
+  timemachine export --output backup.bundle             # All snapshots
+  timemachine export --output week.bundle --since 7d    # Last week only
+  timemachine export --output backup.tar.gz`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runExport(output, since)
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "", "File to write (.bundle, .tar.gz or .tgz)")
+	cmd.Flags().StringVar(&since, "since", "", "Only export snapshots newer than this age (e.g., 12h, 7d, 2w)")
+	cmd.MarkFlagRequired("output")
+
+	return cmd
+}
+
+func runExport(output, since string) error {
+	opts := core.ExportOptions{}
+	if since != "" {
+		age, err := core.ParseAge(since)
+		if err != nil {
+			return fmt.Errorf("invalid --since: %w", err)
+		}
+		opts.Since = age
+	}
+
+	state, err := loadInitializedState()
+	if err != nil || state == nil {
+		return err
+	}
+
+	gitManager := core.NewGitManager(state)
+	count, err := gitManager.ExportSnapshots(output, opts)
+	if err != nil {
+		return err
+	}
+
+	size := ""
+	if info, err := os.Stat(output); err == nil {
+		size = fmt.Sprintf(" (%s)", utils.FormatBytes(info.Size()))
+	}
+
+	ui.Success("📦 Exported %d snapshot(s) to %s%s", count, output, size)
+	if opts.Since > 0 {
+		fmt.Println("   This export only holds recent snapshots; import a full export first on a new machine.")
+	}
+	fmt.Printf("   Restore it elsewhere with: timemachine import %s\n", output)
+
+	return nil
+}
@@ -0,0 +1,188 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/core"
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/render"
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/ui"
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+// HistoryCmd creates the history command
+func HistoryCmd() *cobra.Command {
+	var (
+		show   int
+		limit  int
+		offset int
+		format string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "history <file>",
+		Short: "List every snapshot that changed a file",
+		Long: `List the snapshots in which a file changed, newest first, with added and
+removed line counts. Versions are numbered from 1 (the oldest), so a number
+keeps referring to the same version as new snapshots are taken.
+
+Use --show to print the file exactly as it was at a version; the output is
+the raw content, suitable for redirecting or piping.
+
+Examples:
+  timemachine history src/main.go                  # Latest 20 versions
+  timemachine history src/main.go --limit 0        # All versions
+  timemachine history src/main.go --offset 20      # Next page
+  timemachine history src/main.go --show 3         # Content at version 3
+  timemachine history src/main.go --show 3 > old.go
+  timemachine history src/main.go --format json`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if cmd.Flags().Changed("show") {
+				return runHistoryShow(os.Stdout, args[0], show)
+			}
+			return runHistory(os.Stdout, args[0], limit, offset, format)
+		},
+	}
+
+	cmd.Flags().IntVar(&show, "show", 0, "Print the file's content at version n")
+	cmd.Flags().IntVarP(&limit, "limit", "n", 20, "Number of versions to list (0 for all)")
+	cmd.Flags().IntVar(&offset, "offset", 0, "Skip this many of the newest versions")
+	cmd.Flags().StringVar(&format, "format", "text", "Output format (text, json)")
+
+	return cmd
+}
+
+// historyPage is the JSON output of the history command
+type historyPage struct {
+	Path     string             `json:"path"`
+	Total    int                `json:"total"`
+	Offset   int                `json:"offset"`
+	Versions []core.FileVersion `json:"versions"`
+}
+
+func runHistory(out io.Writer, file string, limit, offset int, format string) error {
+	if format != "text" && format != "json" {
+		return fmt.Errorf("unsupported format: %s (use 'text' or 'json')", format)
+	}
+	if limit < 0 || offset < 0 {
+		return fmt.Errorf("--limit and --offset must not be negative")
+	}
+
+	state, err := loadInitializedState()
+	if err != nil || state == nil {
+		return err
+	}
+
+	gitManager := core.NewGitManager(state)
+	versions, rel, err := gitManager.FileHistory(file)
+	if err != nil {
+		return err
+	}
+
+	page := pageVersions(versions, limit, offset)
+
+	if format == "json" {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(historyPage{Path: rel, Total: len(versions), Offset: offset, Versions: page})
+	}
+
+	if len(versions) == 0 {
+		ui.Warning("📝 No snapshots changed %s", rel)
+		return nil
+	}
+
+	ui.Color(ui.RoleHeading).Fprintf(out, "📜 History of %s (%d version(s))\n\n", rel, len(versions))
+	fmt.Fprintf(out, "%5s  %-8s  %-16s  %-12s  %s\n", "#", "SNAPSHOT", "WHEN", "CHANGES", "MESSAGE")
+
+	for _, version := range page {
+		fmt.Fprintf(out, "%5d  %s  %-16s  %s  %s\n",
+			version.Number,
+			ui.Sprint(ui.RoleHash, core.ShortHash(version.Hash)),
+			utils.TruncateString(version.RelativeTime, 16),
+			formatVersionChanges(version),
+			utils.TruncateString(version.Message, 50))
+	}
+
+	fmt.Fprintln(out)
+	if len(page) < len(versions) {
+		fmt.Fprintf(out, "Showing %d-%d of %d", offset+1, offset+len(page), len(versions))
+		if next := offset + len(page); next < len(versions) {
+			fmt.Fprintf(out, " (next page: --offset %d)", next)
+		}
+		fmt.Fprintln(out)
+	}
+	fmt.Fprintf(out, "Use 'timemachine history %s --show <#>' to print a version\n", file)
+
+	return nil
+}
+
+// pageVersions applies --offset and --limit (0 means no limit)
+func pageVersions(versions []core.FileVersion, limit, offset int) []core.FileVersion {
+	if offset >= len(versions) {
+		return []core.FileVersion{}
+	}
+	versions = versions[offset:]
+	if limit > 0 && limit < len(versions) {
+		versions = versions[:limit]
+	}
+	return versions
+}
+
+// formatVersionChanges renders the CHANGES column padded to a fixed width;
+// padding is applied before coloring so escape codes don't skew alignment
+func formatVersionChanges(version core.FileVersion) string {
+	const width = 12
+	switch {
+	case version.Deleted:
+		return ui.Sprint(ui.RoleDeleted, fmt.Sprintf("%-*s", width, "deleted"))
+	case version.Binary:
+		return ui.Sprint(ui.RoleModified, fmt.Sprintf("%-*s", width, "binary"))
+	}
+
+	added := fmt.Sprintf("+%d", version.Insertions)
+	removed := fmt.Sprintf("-%d", version.Deletions)
+	padding := width - len(added) - len(removed) - 1
+	if padding < 0 {
+		padding = 0
+	}
+	return ui.Sprint(ui.RoleAdded, added) + " " + ui.Sprint(ui.RoleDeleted, removed) + fmt.Sprintf("%*s", padding, "")
+}
+
+func runHistoryShow(out io.Writer, file string, number int) error {
+	state, err := loadInitializedState()
+	if err != nil || state == nil {
+		return err
+	}
+
+	gitManager := core.NewGitManager(state)
+	versions, rel, err := gitManager.FileHistory(file)
+	if err != nil {
+		return err
+	}
+
+	if number < 1 || number > len(versions) {
+		return fmt.Errorf("version %d does not exist: %s has %d version(s)", number, rel, len(versions))
+	}
+
+	version := versions[len(versions)-number]
+	if version.Deleted {
+		return fmt.Errorf("%s was deleted in version %d (%s); nothing to show", rel, number, core.ShortHash(version.Hash))
+	}
+
+	content, err := gitManager.FileContentAt(version.Hash, rel)
+	if err != nil {
+		return err
+	}
+
+	if highlighter := render.NewHighlighter(rel); highlighter != nil {
+		_, err = io.WriteString(out, highlighter.Text(string(content)))
+		return err
+	}
+	_, err = out.Write(content)
+	return err
+}
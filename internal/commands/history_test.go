@@ -0,0 +1,41 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/core"
+)
+
+func TestPageVersions(t *testing.T) {
+	versions := make([]core.FileVersion, 5)
+	for i := range versions {
+		versions[i].Number = 5 - i
+	}
+
+	tests := []struct {
+		name     string
+		limit    int
+		offset   int
+		expected []int
+	}{
+		{"first page", 2, 0, []int{5, 4}},
+		{"second page", 2, 2, []int{3, 2}},
+		{"partial last page", 2, 4, []int{1}},
+		{"no limit", 0, 1, []int{4, 3, 2, 1}},
+		{"offset past end", 2, 10, []int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page := pageVersions(versions, tt.limit, tt.offset)
+			if len(page) != len(tt.expected) {
+				t.Fatalf("Expected %d versions, got %d", len(tt.expected), len(page))
+			}
+			for i, number := range tt.expected {
+				if page[i].Number != number {
+					t.Errorf("Expected version %d at position %d, got %d", number, i, page[i].Number)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/core"
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// IgnoreCmd creates the ignore command
+func IgnoreCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "ignore",
+		Short: "Debug ignore patterns",
+	}
+
+	cmd.AddCommand(ignoreExplainCmd())
+
+	return cmd
+}
+
+// ignoreExplainCmd reports which pattern decides whether paths are ignored
+func ignoreExplainCmd() *cobra.Command {
+	var ignores []string
+
+	cmd := &cobra.Command{
+		Use:   "explain <path>...",
+		Short: "Show whether a path is ignored, and which pattern decided it",
+		Long: `Report whether each path is ignored by the watcher and, like
+'git check-ignore -v', the pattern that decided it: its source and line in
+.timemachine-ignore or a .gitignore, its position in watcher.ignore_patterns
+or TIMEMACHINE_WATCHER_IGNORE, or the built-in rule for .git. A path matched
+by a "!" pattern is reported along with the pattern that re-included it.
+
+Paths are relative to the current directory and need not exist. Use --ignore
+to see the effect of patterns given to 'timemachine start --ignore'.
+
+Examples:
+  timemachine ignore explain dist/app.js
+  timemachine ignore explain src/ package-lock.json --ignore '*.lock'`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runIgnoreExplain(args, ignores)
+		},
+	}
+
+	cmd.Flags().StringArrayVar(&ignores, "ignore", nil, "Also apply this start --ignore pattern (repeatable)")
+
+	return cmd
+}
+
+func runIgnoreExplain(paths, ignores []string) error {
+	state, err := core.NewAppState()
+	if err != nil {
+		return err
+	}
+	state.IgnoreFlags = ignores
+
+	manager, err := core.NewProjectIgnoreManager(state)
+	if err != nil {
+		ui.Warning("⚠️  %v", err)
+	}
+
+	for _, p := range paths {
+		abs, err := filepath.Abs(p)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(state.ProjectRoot, abs)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("%s is outside the project", p)
+		}
+		info, statErr := os.Stat(abs)
+		isDir := statErr == nil && info.IsDir()
+
+		match := manager.Explain(abs, isDir)
+		name := match.Path
+		if isDir {
+			name += "/"
+		}
+		switch {
+		case match.Ignored:
+			ui.Warning("🙈 %s is ignored", name)
+			fmt.Printf("   %s: %s\n", ignoreLocation(match), match.Pattern)
+		case match.Source != "":
+			ui.Success("✅ %s is watched", name)
+			fmt.Printf("   re-included by %s: %s\n", ignoreLocation(match), match.Pattern)
+		default:
+			ui.Success("✅ %s is watched: no pattern matches", name)
+		}
+		if !match.Ignored && !state.IsIncluded(match.Path) {
+			ui.Warning("   ⚠️  but it is outside watcher.include_paths (%s), so it isn't snapshotted", strings.Join(state.IncludePaths, ", "))
+		}
+	}
+	return nil
+}
+
+// ignoreLocation describes where a pattern comes from, as file:line for
+// ignore files
+func ignoreLocation(match core.IgnoreMatch) string {
+	switch {
+	case match.File != "":
+		return fmt.Sprintf("%s:%d", match.File, match.Line)
+	case match.Source == core.IgnoreSourceDefault:
+		return match.Source
+	default:
+		return fmt.Sprintf("%s entry %d", match.Source, match.Line)
+	}
+}
@@ -0,0 +1,84 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/core"
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/daemon"
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// ImportCmd creates the import command
+func ImportCmd() *cobra.Command {
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:   "import <file>",
+		Short: "Restore snapshots from a file created by export",
+		Long: `Add the snapshots from a file written by 'timemachine export' to this
+project's snapshot history. Working tree files are not changed; use
+'timemachine restore' afterwards to bring back a snapshot.
+
+The imported snapshots must continue the local history, or the project must
+have no snapshots yet. If snapshots were taken on both machines since the
+last import, the histories have diverged and --force is needed: the local
+snapshots are then replaced but stay reachable under refs/timemachine/backup/.
+
+Examples:
+  timemachine import backup.bundle
+  timemachine import backup.tar.gz
+  timemachine import backup.bundle --force   # Replace diverged local snapshots`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runImport(args[0], force)
+		},
+	}
+
+	cmd.Flags().BoolVar(&force, "force", false, "Replace local snapshots that diverged from the imported ones")
+
+	return cmd
+}
+
+func runImport(input string, force bool) error {
+	state, err := loadInitializedState()
+	if err != nil || state == nil {
+		return err
+	}
+
+	// The watcher would race the import for the snapshot branch
+	status, err := daemon.NewManager(state).Status()
+	if err != nil {
+		return fmt.Errorf("failed to check daemon status: %w", err)
+	}
+	if status.Running {
+		ui.Warning("⚠️  Time Machine is running in the background (PID %d)", status.PID)
+		fmt.Println("   Run 'timemachine stop' before importing.")
+		return nil
+	}
+
+	gitManager := core.NewGitManager(state)
+	result, err := gitManager.ImportSnapshots(input, force)
+	if errors.Is(err, core.ErrHistoryDiverged) {
+		ui.Error("❌ Local snapshots were taken that %s doesn't contain.", input)
+		fmt.Println("   Re-run with --force to replace them; they will be kept under refs/timemachine/backup/.")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if result.Snapshots == 0 {
+		ui.Info("📸 Already up to date: all snapshots in %s are present", input)
+		return nil
+	}
+
+	ui.Success("📥 Imported %d snapshot(s); latest is %s", result.Snapshots, ui.Sprint(ui.RoleHash, core.ShortHash(result.Head)))
+	if result.BackupRef != "" {
+		fmt.Printf("   Previous local snapshots are kept under %s\n", result.BackupRef)
+	}
+	fmt.Println("   Use 'timemachine list' to browse them.")
+
+	return nil
+}
@@ -2,6 +2,7 @@ package commands
 
 import (
 	"bufio"
+	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -9,12 +10,38 @@ import (
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/config"
 	"github.com/deepakkumarnarayana/timemachine-cli/internal/core"
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/presets"
 )
 
+// initOptions are the flags of the init command
+type initOptions struct {
+	noHook      bool
+	commitHooks bool
+	noGitignore bool
+	quiet       bool
+	configFrom  string
+	profile     string
+	check       bool
+}
+
+// commitHooks are the hooks --commit-hooks installs
+var commitHooks = []string{"post-commit", "post-merge"}
+
+// initStep is one thing init sets up
+type initStep struct {
+	progress string      // Shown while the step runs
+	missing  string      // Reported by --check while the step is pending
+	done     func() bool // Whether there is nothing left to do
+	run      func() error
+}
+
 // InitCmd creates the init command
 func InitCmd() *cobra.Command {
-	return &cobra.Command{
+	var opts initOptions
+
+	cmd := &cobra.Command{
 		Use:   "init",
 		Short: "Initialize Time Machine in the current Git repository",
 		Long: `Initialize Time Machine by creating a shadow repository for snapshots.
@@ -22,70 +49,125 @@ func InitCmd() *cobra.Command {
 This command:
 - Creates a shadow repository at .git/timemachine_snapshots/
 - Updates .gitignore to exclude the shadow repository
-- Installs a post-push hook for automatic cleanup
-- Creates an initial snapshot`,
-		RunE: runInit,
+- Installs a pre-push hook for automatic cleanup, replacing the post-push
+  hook earlier versions installed (Git never runs post-push hooks)
+- Creates an initial snapshot
+
+With --commit-hooks it also installs post-commit and post-merge hooks that
+record each commit in the next snapshot's message, so 'timemachine list
+--since-commit HEAD~3' shows the snapshots taken since that commit.
+
+Running it again completes whatever is missing and otherwise changes nothing,
+so it is safe in provisioning scripts and CI pipelines; it never prompts.
+--check only reports whether anything is missing, exiting non-zero if so.
+
+--profile seeds timemachine.yaml with watcher settings and .timemachine-ignore
+with patterns curated for an ecosystem; 'timemachine config profiles list'
+shows them.
+
+Examples:
+  timemachine init --profile node                    # Skip node_modules, wait out npm install
+  timemachine init --no-hook --no-gitignore --quiet  # Leave the project's Git files alone
+  timemachine init --config-from ci/timemachine.yaml # Start from shared settings
+  timemachine init --check || timemachine init`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// A failed check is an answer, not a usage mistake
+			cmd.SilenceUsage = opts.check
+			return runInit(opts)
+		},
 	}
+
+	cmd.Flags().BoolVar(&opts.noHook, "no-hook", false, "Don't install the pre-push cleanup hook")
+	cmd.Flags().BoolVar(&opts.commitHooks, "commit-hooks", false, "Install post-commit and post-merge hooks recording commits in snapshots")
+	cmd.Flags().BoolVar(&opts.noGitignore, "no-gitignore", false, "Don't add the shadow repository to .gitignore")
+	cmd.Flags().BoolVarP(&opts.quiet, "quiet", "q", false, "Print nothing but errors")
+	cmd.Flags().StringVar(&opts.configFrom, "config-from", "", "Install this configuration file as the project's timemachine.yaml")
+	cmd.Flags().StringVar(&opts.profile, "profile", "", "Seed settings and ignore patterns for an ecosystem: "+strings.Join(presets.Names(), ", "))
+	cmd.Flags().BoolVar(&opts.check, "check", false, "Change nothing; exit non-zero if initialization is needed")
+	cmd.MarkFlagsMutuallyExclusive("config-from", "profile")
+	cmd.RegisterFlagCompletionFunc("profile", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return presets.Names(), cobra.ShellCompDirectiveNoFileComp
+	})
+
+	return cmd
 }
 
-func runInit(cmd *cobra.Command, args []string) error {
+func runInit(opts initOptions) error {
 	// Create application state
 	state, err := core.NewAppState()
 	if err != nil {
 		return fmt.Errorf("failed to initialize app state: %w", err)
 	}
 
-	fmt.Println("🔧 Initializing Time Machine...")
-
-	// Check if already initialized
-	if state.IsInitialized {
-		color.Green("✅ Time Machine is already initialized!")
-		fmt.Printf("   Shadow repository exists at: %s\n", state.ShadowRepoDir)
-		return nil
+	// Refuse a broken configuration before changing anything
+	if opts.configFrom != "" {
+		if err := config.ValidateFile(opts.configFrom); err != nil {
+			return core.NewValidationError("invalid configuration in %s: %w", opts.configFrom, err)
+		}
 	}
-
-	// Create Git manager
-	gitManager := core.NewGitManager(state)
-
-	// Step 1: Create shadow repository
-	fmt.Print("  Creating shadow repository... ")
-	if err := gitManager.InitializeShadowRepo(); err != nil {
-		color.Red("❌")
-		return fmt.Errorf("failed to create shadow repository: %w", err)
+	var profile *presets.Preset
+	if opts.profile != "" {
+		if profile, err = presets.Get(opts.profile); err != nil {
+			return err
+		}
 	}
-	color.Green("✅")
 
-	// Step 2: Update .gitignore
-	fmt.Print("  Updating .gitignore... ")
-	if err := updateGitignore(state.ProjectRoot); err != nil {
-		color.Red("❌")
-		return fmt.Errorf("failed to update .gitignore: %w", err)
+	var pending []initStep
+	for _, step := range initSteps(state, opts, profile) {
+		if !step.done() {
+			pending = append(pending, step)
+		}
 	}
-	color.Green("✅")
 
-	// Step 3: Create default .timemachine-ignore file
-	fmt.Print("  Creating .timemachine-ignore... ")
-	if err := createDefaultTimemachineIgnore(state.ProjectRoot); err != nil {
-		color.Red("❌")
-		return fmt.Errorf("failed to create .timemachine-ignore: %w", err)
+	if opts.check {
+		if len(pending) == 0 {
+			if !opts.quiet {
+				color.Green("✅ Time Machine is initialized")
+			}
+			return nil
+		}
+		var missing []string
+		for _, step := range pending {
+			missing = append(missing, step.missing)
+		}
+		return fmt.Errorf("initialization needed: %s missing; run 'timemachine init'", strings.Join(missing, ", "))
 	}
-	color.Green("✅")
 
-	// Step 4: Install post-push hook
-	fmt.Print("  Installing auto-cleanup hook... ")
-	if err := installPostPushHook(state.GitDir); err != nil {
-		color.Red("❌")
-		return fmt.Errorf("failed to install post-push hook: %w", err)
+	// Check if already initialized
+	if len(pending) == 0 {
+		if !opts.quiet {
+			color.Green("✅ Time Machine is already initialized!")
+			fmt.Printf("   Shadow repository exists at: %s\n", state.ShadowRepoDir)
+		}
+		return nil
 	}
-	color.Green("✅")
 
-	// Step 5: Create initial snapshot
-	fmt.Print("  Creating initial snapshot... ")
-	if err := gitManager.CreateSnapshot("Initial Time Machine snapshot"); err != nil {
-		color.Red("❌")
-		return fmt.Errorf("failed to create initial snapshot: %w", err)
+	firstRun := !state.IsInitialized
+	if !opts.quiet {
+		if firstRun {
+			fmt.Println("🔧 Initializing Time Machine...")
+		} else {
+			fmt.Println("🔧 Completing Time Machine setup...")
+		}
+	}
+	for _, step := range pending {
+		if !opts.quiet {
+			fmt.Printf("  %s... ", step.progress)
+		}
+		if err := step.run(); err != nil {
+			if !opts.quiet {
+				color.Red("❌")
+			}
+			return err
+		}
+		if !opts.quiet {
+			color.Green("✅")
+		}
+	}
+	if opts.quiet || !firstRun {
+		return nil
 	}
-	color.Green("✅")
 
 	// Success message
 	fmt.Println()
@@ -99,6 +181,188 @@ func runInit(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// initSteps lists what init sets up, in order; profile is nil without
+// --profile
+func initSteps(state *core.AppState, opts initOptions, profile *presets.Preset) []initStep {
+	gitManager := core.NewGitManager(state)
+	hooksDir := core.CommonDir(state.GitDir)
+	configPath := filepath.Join(state.ProjectRoot, "timemachine.yaml")
+	ignorePath := filepath.Join(state.ProjectRoot, core.DefaultIgnoreFile)
+	initialized := func() bool { return state.IsInitialized }
+
+	var steps []initStep
+	if opts.configFrom != "" {
+		steps = append(steps, initStep{
+			progress: "Installing configuration from " + opts.configFrom,
+			missing:  "configuration from " + opts.configFrom,
+			done:     func() bool { return sameFileContent(opts.configFrom, configPath) },
+			run:      func() error { return installConfig(opts.configFrom, configPath) },
+		})
+	}
+	if profile != nil {
+		steps = append(steps, initStep{
+			progress: "Installing " + profile.Name + " profile settings",
+			missing:  profile.Name + " profile settings",
+			done:     func() bool { return hasContent(configPath, profile.Config) },
+			run: func() error {
+				return writeConfig(configPath, profile.Config, "the "+profile.Name+" profile")
+			},
+		})
+	}
+	steps = append(steps, initStep{
+		progress: "Creating shadow repository",
+		missing:  "shadow repository",
+		done:     initialized,
+		run: func() error {
+			if err := gitManager.InitializeShadowRepo(); err != nil {
+				return fmt.Errorf("failed to create shadow repository: %w", err)
+			}
+			return nil
+		},
+	})
+	if !opts.noGitignore {
+		steps = append(steps, initStep{
+			progress: "Updating .gitignore",
+			missing:  ".gitignore entry",
+			done:     func() bool { return fileContains(filepath.Join(state.ProjectRoot, ".gitignore"), "timemachine_snapshots") },
+			run: func() error {
+				if err := updateGitignore(state.ProjectRoot); err != nil {
+					return fmt.Errorf("failed to update .gitignore: %w", err)
+				}
+				return nil
+			},
+		})
+	}
+	steps = append(steps, initStep{
+		progress: "Creating .timemachine-ignore",
+		missing:  ".timemachine-ignore",
+		done: func() bool {
+			_, err := os.Stat(ignorePath)
+			return err == nil
+		},
+		run: func() error {
+			if err := createDefaultTimemachineIgnore(state.ProjectRoot); err != nil {
+				return fmt.Errorf("failed to create .timemachine-ignore: %w", err)
+			}
+			return nil
+		},
+	})
+	if profile != nil {
+		steps = append(steps, initStep{
+			progress: "Adding " + profile.Name + " profile ignore patterns",
+			missing:  profile.Name + " profile ignore patterns",
+			done:     func() bool { return fileContains(ignorePath, profile.IgnoreMarker()) },
+			run: func() error {
+				if err := appendFile(ignorePath, profile.IgnoreSection()); err != nil {
+					return fmt.Errorf("failed to update .timemachine-ignore: %w", err)
+				}
+				return nil
+			},
+		})
+	}
+	if !opts.noHook {
+		steps = append(steps, initStep{
+			progress: "Installing auto-cleanup hook",
+			missing:  "pre-push hook",
+			done: func() bool {
+				return fileContains(filepath.Join(hooksDir, "hooks", "pre-push"), "timemachine clean") && !hasLegacyPostPushHook(hooksDir)
+			},
+			run: func() error {
+				if err := installPrePushHook(hooksDir); err != nil {
+					return fmt.Errorf("failed to install pre-push hook: %w", err)
+				}
+				// Earlier versions used post-push, which Git never runs
+				return removeLegacyPostPushHook(hooksDir)
+			},
+		})
+	}
+	if opts.commitHooks {
+		steps = append(steps, initStep{
+			progress: "Installing commit correlation hooks",
+			missing:  "post-commit and post-merge hooks",
+			done: func() bool {
+				for _, name := range commitHooks {
+					if !fileContains(filepath.Join(hooksDir, "hooks", name), "timemachine record-commit") {
+						return false
+					}
+				}
+				return true
+			},
+			run: func() error {
+				if err := installCommitHooks(hooksDir); err != nil {
+					return fmt.Errorf("failed to install commit hooks: %w", err)
+				}
+				return nil
+			},
+		})
+	}
+	return append(steps, initStep{
+		progress: "Creating initial snapshot",
+		missing:  "initial snapshot",
+		done:     initialized,
+		run: func() error {
+			if err := gitManager.CreateSnapshot(core.InitialSnapshotMessage); err != nil {
+				return fmt.Errorf("failed to create initial snapshot: %w", err)
+			}
+			return nil
+		},
+	})
+}
+
+// fileContains reports whether a file exists and contains substr
+func fileContains(path, substr string) bool {
+	content, err := os.ReadFile(path)
+	return err == nil && strings.Contains(string(content), substr)
+}
+
+// sameFileContent reports whether two files exist and have the same content
+func sameFileContent(a, b string) bool {
+	content, err := os.ReadFile(a)
+	return err == nil && hasContent(b, content)
+}
+
+// hasContent reports whether a file exists with exactly this content
+func hasContent(path string, content []byte) bool {
+	existing, err := os.ReadFile(path)
+	return err == nil && bytes.Equal(existing, content)
+}
+
+// appendFile appends text to a file, which must exist
+func appendFile(path, text string) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		return err
+	}
+	if _, err := file.WriteString(text); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// installConfig copies a configuration file to the project's, refusing to
+// replace one with other settings
+func installConfig(source, target string) error {
+	content, err := os.ReadFile(source)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", source, err)
+	}
+	return writeConfig(target, content, source)
+}
+
+// writeConfig writes the project's configuration file, refusing to replace
+// one with other settings; source names where the content came from
+func writeConfig(target string, content []byte, source string) error {
+	if _, err := os.Stat(target); err == nil {
+		return fmt.Errorf("%s already exists with other settings; remove it to install %s", target, source)
+	}
+	// Owner-only like 'config init': the configuration may name hook commands
+	if err := os.WriteFile(target, content, 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", target, err)
+	}
+	return nil
+}
+
 // updateGitignore adds the timemachine_snapshots directory to .gitignore
 // MUST preserve existing content and only append if not already present
 func updateGitignore(projectRoot string) error {
@@ -233,10 +497,90 @@ func createDefaultTimemachineIgnore(projectRoot string) error {
 	return writer.Flush()
 }
 
-// installPostPushHook installs or updates the post-push hook for automatic cleanup
-// MUST preserve existing hook content and only append if not already present
-func installPostPushHook(gitDir string) error {
+// cleanupHookSection is the Time Machine section of the pre-push hook. It
+// keeps the exit status of whatever the hook ran before it, so an existing
+// check still blocks a push, while a failed cleanup never does.
+var cleanupHookSection = []string{
+	"",
+	"# Time Machine auto-cleanup",
+	"timemachine_status=$?",
+	"if command -v timemachine >/dev/null 2>&1; then",
+	"    timemachine clean --auto --quiet </dev/null || true",
+	"fi",
+	"exit $timemachine_status",
+}
+
+// legacyCleanupHookSection is the section earlier versions appended to a
+// post-push hook, which Git never runs
+var legacyCleanupHookSection = []string{
+	"",
+	"# Time Machine auto-cleanup",
+	"if command -v timemachine >/dev/null 2>&1; then",
+	"    timemachine clean --auto --quiet",
+	"fi",
+}
+
+// installPrePushHook installs or updates the pre-push hook for automatic cleanup
+func installPrePushHook(gitDir string) error {
+	return installHook(gitDir, "pre-push", "timemachine clean", cleanupHookSection)
+}
+
+// hasLegacyPostPushHook reports whether an earlier version installed its
+// cleanup in the post-push hook
+func hasLegacyPostPushHook(gitDir string) bool {
+	content, err := os.ReadFile(filepath.Join(gitDir, "hooks", "post-push"))
+	return err == nil && strings.Contains(string(content), strings.Join(legacyCleanupHookSection, "\n"))
+}
+
+// removeLegacyPostPushHook takes the cleanup section out of the post-push
+// hook earlier versions installed, deleting the hook if nothing else is left
+func removeLegacyPostPushHook(gitDir string) error {
 	hookPath := filepath.Join(gitDir, "hooks", "post-push")
+	content, err := os.ReadFile(hookPath)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to read post-push hook: %w", err)
+	}
+
+	remaining := strings.Replace(string(content), strings.Join(legacyCleanupHookSection, "\n")+"\n", "", 1)
+	if remaining == string(content) {
+		return nil
+	}
+	if strings.TrimSpace(remaining) == "#!/bin/sh" {
+		if err := os.Remove(hookPath); err != nil {
+			return fmt.Errorf("failed to remove post-push hook: %w", err)
+		}
+		return nil
+	}
+	if err := os.WriteFile(hookPath, []byte(remaining), 0755); err != nil {
+		return fmt.Errorf("failed to update post-push hook: %w", err)
+	}
+	return nil
+}
+
+// installCommitHooks installs or updates the post-commit and post-merge
+// hooks recording each project commit for the next snapshot's message
+func installCommitHooks(gitDir string) error {
+	for _, name := range commitHooks {
+		if err := installHook(gitDir, name, "timemachine record-commit", []string{
+			"",
+			"# Time Machine commit correlation",
+			"if command -v timemachine >/dev/null 2>&1; then",
+			"    timemachine record-commit",
+			"fi",
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// installHook appends a Time Machine section to a Git hook, unless a line
+// already contains marker
+// MUST preserve existing hook content and only append if not already present
+func installHook(gitDir, name, marker string, timemachineHook []string) error {
+	hookPath := filepath.Join(gitDir, "hooks", name)
 	
 	// Create hooks directory if it doesn't exist
 	hooksDir := filepath.Dir(hookPath)
@@ -257,7 +601,7 @@ func installPostPushHook(gitDir string) error {
 			existingContent = append(existingContent, line)
 			
 			// Check if already contains timemachine command
-			if strings.Contains(line, "timemachine clean") {
+			if strings.Contains(line, marker) {
 				timemachineFound = true
 			}
 		}
@@ -269,20 +613,11 @@ func installPostPushHook(gitDir string) error {
 		return fmt.Errorf("failed to open existing hook: %w", err)
 	}
 	
-	// If already contains timemachine cleanup, nothing to do
+	// If already contains the Time Machine section, nothing to do
 	if timemachineFound {
 		return nil
 	}
 	
-	// Time Machine hook content
-	timemachineHook := []string{
-		"",
-		"# Time Machine auto-cleanup",
-		"if command -v timemachine >/dev/null 2>&1; then",
-		"    timemachine clean --auto --quiet",
-		"fi",
-	}
-	
 	// Create or update the hook
 	file, err := os.Create(hookPath)
 	if err != nil {
@@ -104,7 +104,7 @@ func TestUpdateGitignore(t *testing.T) {
 	})
 }
 
-func TestInstallPostPushHook(t *testing.T) {
+func TestInstallPrePushHook(t *testing.T) {
 	// Create temporary git directory structure
 	tempDir, err := os.MkdirTemp("", "timemachine-hook-test")
 	if err != nil {
@@ -119,16 +119,16 @@ func TestInstallPostPushHook(t *testing.T) {
 	}
 
 	t.Run("CreateNewHook", func(t *testing.T) {
-		err := installPostPushHook(gitDir)
+		err := installPrePushHook(gitDir)
 		if err != nil {
-			t.Fatalf("installPostPushHook failed: %v", err)
+			t.Fatalf("installPrePushHook failed: %v", err)
 		}
 
 		// Check hook was created
-		hookPath := filepath.Join(gitDir, "hooks", "post-push")
+		hookPath := filepath.Join(gitDir, "hooks", "pre-push")
 		content, err := os.ReadFile(hookPath)
 		if err != nil {
-			t.Fatalf("Failed to read post-push hook: %v", err)
+			t.Fatalf("Failed to read pre-push hook: %v", err)
 		}
 
 		contentStr := string(content)
@@ -153,7 +153,7 @@ func TestInstallPostPushHook(t *testing.T) {
 	})
 
 	t.Run("PreserveExistingHook", func(t *testing.T) {
-		hookPath := filepath.Join(gitDir, "hooks", "post-push")
+		hookPath := filepath.Join(gitDir, "hooks", "pre-push")
 		
 		// Create existing hook with custom content
 		existingContent := "#!/bin/sh\necho 'Custom hook content'\n# Some existing functionality\n"
@@ -162,9 +162,9 @@ func TestInstallPostPushHook(t *testing.T) {
 			t.Fatalf("Failed to create existing hook: %v", err)
 		}
 
-		err = installPostPushHook(gitDir)
+		err = installPrePushHook(gitDir)
 		if err != nil {
-			t.Fatalf("installPostPushHook failed: %v", err)
+			t.Fatalf("installPrePushHook failed: %v", err)
 		}
 
 		// Check both existing and new content are present
@@ -183,7 +183,7 @@ func TestInstallPostPushHook(t *testing.T) {
 	})
 
 	t.Run("SkipIfAlreadyExists", func(t *testing.T) {
-		hookPath := filepath.Join(gitDir, "hooks", "post-push")
+		hookPath := filepath.Join(gitDir, "hooks", "pre-push")
 		
 		// Create hook that already contains timemachine cleanup
 		existingContent := "#!/bin/sh\necho 'Pre-existing hook'\ntimemachine clean --auto --quiet\n"
@@ -200,9 +200,9 @@ func TestInstallPostPushHook(t *testing.T) {
 
 		time.Sleep(10 * time.Millisecond) // Ensure different timestamp
 
-		err = installPostPushHook(gitDir)
+		err = installPrePushHook(gitDir)
 		if err != nil {
-			t.Fatalf("installPostPushHook failed: %v", err)
+			t.Fatalf("installPrePushHook failed: %v", err)
 		}
 
 		// Check file was not modified
@@ -239,12 +239,12 @@ func TestGitHookExecution(t *testing.T) {
 
 	t.Run("HookIsExecutableAndValid", func(t *testing.T) {
 		// Install the hook
-		err := installPostPushHook(gitDir)
+		err := installPrePushHook(gitDir)
 		if err != nil {
 			t.Fatalf("Failed to install hook: %v", err)
 		}
 
-		hookPath := filepath.Join(gitDir, "hooks", "post-push")
+		hookPath := filepath.Join(gitDir, "hooks", "pre-push")
 
 		// Check hook exists and is executable
 		stat, err := os.Stat(hookPath)
@@ -278,7 +278,7 @@ func TestGitHookExecution(t *testing.T) {
 
 	t.Run("HookExecutesDirectly", func(t *testing.T) {
 		// Install the hook
-		err := installPostPushHook(gitDir)
+		err := installPrePushHook(gitDir)
 		if err != nil {
 			t.Fatalf("Failed to install hook: %v", err)
 		}
@@ -295,7 +295,7 @@ exit 0
 		}
 
 		// Create a modified hook that uses our test script
-		hookPath := filepath.Join(gitDir, "hooks", "post-push")
+		hookPath := filepath.Join(gitDir, "hooks", "pre-push")
 		testHookContent := `#!/bin/sh
 
 # Time Machine auto-cleanup
@@ -332,12 +332,12 @@ fi
 
 	t.Run("HookHandlesMissingTimemachine", func(t *testing.T) {
 		// Install the hook
-		err := installPostPushHook(gitDir)
+		err := installPrePushHook(gitDir)
 		if err != nil {
 			t.Fatalf("Failed to install hook: %v", err)
 		}
 
-		hookPath := filepath.Join(gitDir, "hooks", "post-push")
+		hookPath := filepath.Join(gitDir, "hooks", "pre-push")
 
 		// Execute the hook directly (timemachine command won't be found)
 		// This should not fail - the hook should gracefully handle missing command
@@ -349,6 +349,62 @@ fi
 	})
 }
 
+func TestPrePushHookKeepsExitStatus(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available, skipping hook execution test")
+	}
+	gitDir := filepath.Join(t.TempDir(), ".git")
+	hookPath := filepath.Join(gitDir, "hooks", "pre-push")
+	os.MkdirAll(filepath.Dir(hookPath), 0755)
+
+	// An existing check that fails must still block the push
+	if err := os.WriteFile(hookPath, []byte("#!/bin/sh\nfalse\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := installPrePushHook(gitDir); err != nil {
+		t.Fatalf("installPrePushHook failed: %v", err)
+	}
+	if err := exec.Command("sh", hookPath).Run(); err == nil {
+		t.Errorf("Expected the hook to keep the failing exit status")
+	}
+
+	os.Remove(hookPath)
+	if err := installPrePushHook(gitDir); err != nil {
+		t.Fatalf("installPrePushHook failed: %v", err)
+	}
+	if err := exec.Command("sh", hookPath).Run(); err != nil {
+		t.Errorf("Expected the cleanup alone never to block a push, got %v", err)
+	}
+}
+
+func TestRemoveLegacyPostPushHook(t *testing.T) {
+	gitDir := filepath.Join(t.TempDir(), ".git")
+	hookPath := filepath.Join(gitDir, "hooks", "post-push")
+	os.MkdirAll(filepath.Dir(hookPath), 0755)
+	legacy := strings.Join(legacyCleanupHookSection, "\n") + "\n"
+
+	// A hook init created alone is removed
+	os.WriteFile(hookPath, []byte("#!/bin/sh\n"+legacy), 0755)
+	if !hasLegacyPostPushHook(gitDir) {
+		t.Fatalf("Expected the legacy hook to be detected")
+	}
+	if err := removeLegacyPostPushHook(gitDir); err != nil {
+		t.Fatalf("removeLegacyPostPushHook failed: %v", err)
+	}
+	if _, err := os.Stat(hookPath); !os.IsNotExist(err) {
+		t.Errorf("Expected the legacy hook to be removed, got %v", err)
+	}
+
+	// Other content is kept
+	os.WriteFile(hookPath, []byte("#!/bin/sh\necho custom\n"+legacy), 0755)
+	if err := removeLegacyPostPushHook(gitDir); err != nil {
+		t.Fatalf("removeLegacyPostPushHook failed: %v", err)
+	}
+	if content, _ := os.ReadFile(hookPath); string(content) != "#!/bin/sh\necho custom\n" || hasLegacyPostPushHook(gitDir) {
+		t.Errorf("Expected only the Time Machine section to be removed, got %q", content)
+	}
+}
+
 func TestInitCommand(t *testing.T) {
 	// Create temporary directory for testing
 	tempDir, err := os.MkdirTemp("", "timemachine-init-test")
@@ -407,14 +463,14 @@ func TestInitCommand(t *testing.T) {
 			t.Errorf(".gitignore was not updated with timemachine exclusion")
 		}
 
-		// Verify post-push hook was installed
-		hookPath := filepath.Join(tempDir, ".git", "hooks", "post-push")
+		// Verify pre-push hook was installed
+		hookPath := filepath.Join(tempDir, ".git", "hooks", "pre-push")
 		hookContent, err := os.ReadFile(hookPath)
 		if err != nil {
-			t.Fatalf("Failed to read post-push hook: %v", err)
+			t.Fatalf("Failed to read pre-push hook: %v", err)
 		}
 		if !strings.Contains(string(hookContent), "timemachine clean") {
-			t.Errorf("Post-push hook was not installed correctly")
+			t.Errorf("Pre-push hook was not installed correctly")
 		}
 
 		// Verify hook is executable
@@ -423,7 +479,7 @@ func TestInitCommand(t *testing.T) {
 			t.Fatalf("Failed to stat hook: %v", err)
 		}
 		if stat.Mode()&0111 == 0 {
-			t.Errorf("Post-push hook is not executable")
+			t.Errorf("Pre-push hook is not executable")
 		}
 
 		// Verify initial snapshot was created
@@ -454,4 +510,164 @@ func TestInitCommand(t *testing.T) {
 		}
 		// Should complete without error and show "already initialized" message
 	})
-}
\ No newline at end of file
+}
+func TestInitFlags(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("Git not available, skipping init command test")
+	}
+	tempDir := t.TempDir()
+	for _, args := range [][]string{
+		{"init"},
+		{"config", "user.name", "Test User"},
+		{"config", "user.email", "test@example.com"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = tempDir
+		if err := cmd.Run(); err != nil {
+			t.Fatalf("git %v failed: %v", args, err)
+		}
+	}
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+	os.Chdir(tempDir)
+
+	runInitWith := func(args ...string) error {
+		initCmd := InitCmd()
+		initCmd.SetArgs(args)
+		initCmd.SilenceUsage = true
+		initCmd.SilenceErrors = true
+		return initCmd.Execute()
+	}
+	gitignorePath := filepath.Join(tempDir, ".gitignore")
+	hookPath := filepath.Join(tempDir, ".git", "hooks", "pre-push")
+	configPath := filepath.Join(tempDir, "timemachine.yaml")
+
+	if err := runInitWith("--check", "--quiet"); err == nil || !strings.Contains(err.Error(), "shadow repository") {
+		t.Errorf("Expected --check to report the missing shadow repository, got %v", err)
+	}
+
+	invalid := filepath.Join(t.TempDir(), "invalid.yaml")
+	os.WriteFile(invalid, []byte("log:\n  level: loud\n"), 0644)
+	if err := runInitWith("--config-from", invalid, "--quiet"); err == nil {
+		t.Errorf("Expected an invalid configuration to be refused")
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, ".git", "timemachine_snapshots")); !os.IsNotExist(err) {
+		t.Errorf("Expected nothing to be set up with an invalid configuration")
+	}
+
+	shared := filepath.Join(t.TempDir(), "shared.yaml")
+	os.WriteFile(shared, []byte("log:\n  level: debug\n"), 0644)
+	if err := runInitWith("--no-hook", "--no-gitignore", "--quiet", "--config-from", shared); err != nil {
+		t.Fatalf("init failed: %v", err)
+	}
+	for _, path := range []string{gitignorePath, hookPath} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("Expected %s to be left alone", path)
+		}
+	}
+	if content, _ := os.ReadFile(configPath); string(content) != "log:\n  level: debug\n" {
+		t.Errorf("Expected the configuration to be installed, got %q", content)
+	}
+
+	// Initialized as asked, but not as the defaults would
+	if err := runInitWith("--check", "--no-hook", "--no-gitignore", "--config-from", shared); err != nil {
+		t.Errorf("Expected --check to pass, got %v", err)
+	}
+	err := runInitWith("--check")
+	if err == nil || !strings.Contains(err.Error(), ".gitignore entry") || !strings.Contains(err.Error(), "pre-push hook") {
+		t.Errorf("Expected --check to report the .gitignore entry and hook, got %v", err)
+	}
+
+	// A second run completes what is missing, moving an earlier version's
+	// post-push hook to pre-push
+	legacyPath := filepath.Join(tempDir, ".git", "hooks", "post-push")
+	os.MkdirAll(filepath.Dir(legacyPath), 0755)
+	os.WriteFile(legacyPath, []byte("#!/bin/sh\n"+strings.Join(legacyCleanupHookSection, "\n")+"\n"), 0755)
+	if err := runInitWith("--quiet"); err != nil {
+		t.Fatalf("Second init failed: %v", err)
+	}
+	if err := runInitWith("--check"); err != nil {
+		t.Errorf("Expected --check to pass after completing setup, got %v", err)
+	}
+	if _, err := os.Stat(legacyPath); !os.IsNotExist(err) {
+		t.Errorf("Expected the post-push hook to be removed, got %v", err)
+	}
+
+	if err := runInitWith("--check", "--commit-hooks"); err == nil || !strings.Contains(err.Error(), "post-commit and post-merge hooks") {
+		t.Errorf("Expected --check to report the missing commit hooks, got %v", err)
+	}
+	if err := runInitWith("--commit-hooks", "--quiet"); err != nil {
+		t.Fatalf("init --commit-hooks failed: %v", err)
+	}
+	for _, name := range []string{"post-commit", "post-merge"} {
+		content, _ := os.ReadFile(filepath.Join(tempDir, ".git", "hooks", name))
+		if !strings.HasPrefix(string(content), "#!/bin/sh\n") || strings.Count(string(content), "timemachine record-commit") != 1 {
+			t.Errorf("Expected the %s hook to record commits, got %q", name, content)
+		}
+	}
+
+	other := filepath.Join(t.TempDir(), "other.yaml")
+	os.WriteFile(other, []byte("log:\n  level: warn\n"), 0644)
+	if err := runInitWith("--config-from", other, "--quiet"); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("Expected a different existing configuration to be kept, got %v", err)
+	}
+}
+
+func TestInitProfile(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("Git not available, skipping init command test")
+	}
+	tempDir := t.TempDir()
+	for _, args := range [][]string{
+		{"init"},
+		{"config", "user.name", "Test User"},
+		{"config", "user.email", "test@example.com"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = tempDir
+		if err := cmd.Run(); err != nil {
+			t.Fatalf("git %v failed: %v", args, err)
+		}
+	}
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+	os.Chdir(tempDir)
+
+	runInitWith := func(args ...string) error {
+		initCmd := InitCmd()
+		initCmd.SetArgs(args)
+		initCmd.SilenceUsage = true
+		initCmd.SilenceErrors = true
+		return initCmd.Execute()
+	}
+
+	if err := runInitWith("--profile", "cobol", "--quiet"); err == nil || !strings.Contains(err.Error(), "unknown profile") {
+		t.Errorf("Expected an unknown profile to be refused, got %v", err)
+	}
+	if err := runInitWith("--profile", "node", "--quiet"); err != nil {
+		t.Fatalf("init --profile failed: %v", err)
+	}
+	config, _ := os.ReadFile(filepath.Join(tempDir, "timemachine.yaml"))
+	if !strings.Contains(string(config), "storm_threshold: 500") {
+		t.Errorf("Expected the node watcher settings, got %q", config)
+	}
+	ignore, _ := os.ReadFile(filepath.Join(tempDir, core.DefaultIgnoreFile))
+	if !strings.Contains(string(ignore), "*.log") || !strings.Contains(string(ignore), "# Profile: node\nnode_modules/\n") {
+		t.Errorf("Expected the default and node ignore patterns, got %q", ignore)
+	}
+
+	// Running it again changes nothing
+	if err := runInitWith("--check", "--profile", "node"); err != nil {
+		t.Errorf("Expected --check to pass, got %v", err)
+	}
+	if err := runInitWith("--profile", "node", "--quiet"); err != nil {
+		t.Fatalf("Second init --profile failed: %v", err)
+	}
+	if again, _ := os.ReadFile(filepath.Join(tempDir, core.DefaultIgnoreFile)); string(again) != string(ignore) {
+		t.Errorf("Expected .timemachine-ignore to be unchanged, got %q", again)
+	}
+
+	if err := runInitWith("--profile", "python", "--quiet"); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("Expected the node settings to be kept, got %v", err)
+	}
+}
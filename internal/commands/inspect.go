@@ -2,33 +2,20 @@ package commands
 
 import (
 	"fmt"
+	"os"
 	"os/exec"
 	"path/filepath"
-	"regexp"
 	"strconv"
 	"strings"
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 	"github.com/deepakkumarnarayana/timemachine-cli/internal/core"
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/render"
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/ui"
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/utils"
 )
 
-// validateGitHash ensures git hash is safe for use in commands
-func validateGitHash(hash string) error {
-	if hash == "" {
-		return fmt.Errorf("empty hash not allowed")
-	}
-	// Only allow alphanumeric characters and ensure reasonable length (4-40 chars for git hashes)
-	matched, err := regexp.MatchString("^[a-fA-F0-9]{4,40}$", hash)
-	if err != nil {
-		return fmt.Errorf("regex validation failed: %w", err)
-	}
-	if !matched {
-		return fmt.Errorf("invalid git hash format: must be 4-40 hexadecimal characters")
-	}
-	return nil
-}
-
 // sanitizeFilePath prevents path traversal attacks using defense-in-depth security approach
 func sanitizeFilePath(path string) (string, error) {
 	if path == "" {
@@ -38,29 +25,29 @@ func sanitizeFilePath(path string) (string, error) {
 	// First line of defense: explicit path traversal detection
 	// filepath.IsLocal allows some traversal patterns that resolve within the directory
 	if strings.Contains(path, "..") {
-		return "", fmt.Errorf("path traversal not allowed")
+		return "", core.NewValidationError("path traversal not allowed")
 	}
 	
 	// Second line of defense: Windows absolute path detection (critical for cross-platform security)
 	// filepath.IsLocal and filepath.IsAbs don't detect Windows paths on Unix systems
 	if len(path) >= 3 && path[1] == ':' && (path[2] == '\\' || path[2] == '/') {
-		return "", fmt.Errorf("absolute paths not allowed")
+		return "", core.NewValidationError("absolute paths not allowed")
 	}
 	
 	// Additional Windows path detection: UNC paths (\\server\share)
 	if strings.HasPrefix(path, "\\\\") || strings.HasPrefix(path, "//") {
-		return "", fmt.Errorf("UNC and network paths not allowed")
+		return "", core.NewValidationError("UNC and network paths not allowed")
 	}
 	
 	// Third line of defense: Unix absolute path detection
 	if filepath.IsAbs(path) {
-		return "", fmt.Errorf("absolute paths not allowed")
+		return "", core.NewValidationError("absolute paths not allowed")
 	}
 	
 	// Fourth line of defense: Use Go 1.20+ filepath.IsLocal for additional validation
 	// This catches edge cases we might have missed
 	if !filepath.IsLocal(path) {
-		return "", fmt.Errorf("path must be local and relative")
+		return "", core.NewValidationError("path must be local and relative")
 	}
 	
 	// Clean and normalize the path
@@ -68,7 +55,7 @@ func sanitizeFilePath(path string) (string, error) {
 	
 	// Final validation: ensure cleaning didn't create an absolute path
 	if strings.HasPrefix(cleaned, "/") || filepath.IsAbs(cleaned) {
-		return "", fmt.Errorf("path must be relative after normalization")
+		return "", core.NewValidationError("path must be relative after normalization")
 	}
 	
 	return cleaned, nil
@@ -79,9 +66,12 @@ func InspectCmd() *cobra.Command {
 	var (
 		showDiff   bool
 		showStats  bool
-		fileFilter string
+		files      []string
+		excludes   []string
 		verbose    bool
 		searchAll  bool
+		sideBySide bool
+		ago        int
 	)
 
 	cmd := &cobra.Command{
@@ -92,32 +82,54 @@ func InspectCmd() *cobra.Command {
 Examples:
   timemachine inspect                    # Show latest snapshot changes
   timemachine inspect abc123def         # Show specific snapshot by hash
+  timemachine inspect -n 3              # Show the snapshot three before the latest
   timemachine inspect --diff            # Show detailed line-by-line changes
+  timemachine inspect --side-by-side    # Show the changes in two columns
   timemachine inspect --stats           # Show repository statistics
   timemachine inspect --file=main.go    # Show changes only for specific file
+  timemachine inspect -f 'src/**' --exclude '**/*_test.go'  # Globs, minus tests
   timemachine inspect --verbose         # Show comprehensive analysis
-  timemachine inspect --search-all --file=main.go  # Search all snapshots for changes to main.go`,
+
+The snapshot is a full or short hash, a tag, 'latest', or a revision such
+as latest~3 or HEAD^.
+
+--file and --exclude may be repeated. Patterns with *, ? or [ are globs,
+where ** spans directories; others name a file or directory. Git pathspec
+magic is accepted: :(icase)readme.md, :!vendor/ or :/ for the project root.
+
+For a compact, scriptable per-file listing see 'timemachine history <file>'.`,
+		ValidArgsFunction: completeSnapshots(1, false),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runInspect(cmd, args, showDiff, showStats, fileFilter, verbose, searchAll)
+			if cmd.Flags().Changed("ago") && len(args) > 0 {
+				return core.NewValidationError("give a snapshot hash or -n, not both")
+			}
+			args, err := snapshotsAgoArgs(cmd, ago, args)
+			if err != nil {
+				return err
+			}
+			return runInspect(cmd, args, showDiff || sideBySide, showStats, files, excludes, verbose, searchAll, sideBySide)
 		},
 	}
 
 	cmd.Flags().BoolVarP(&showDiff, "diff", "d", false, "Show detailed line-by-line differences")
+	cmd.Flags().BoolVar(&sideBySide, "side-by-side", false, "Show the detailed changes in two columns (implies --diff)")
 	cmd.Flags().BoolVarP(&showStats, "stats", "s", false, "Show repository storage statistics")
-	cmd.Flags().StringVarP(&fileFilter, "file", "f", "", "Filter changes to specific file")
+	cmd.Flags().StringSliceVarP(&files, "file", "f", nil, "Filter changes to files, directories or glob patterns (repeatable)")
+	cmd.Flags().StringSliceVar(&excludes, "exclude", nil, "Leave out files, directories or glob patterns (repeatable)")
 	cmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Show comprehensive analysis")
 	cmd.Flags().BoolVarP(&searchAll, "search-all", "a", false, "Search all snapshots for file changes")
+	deprecateFlag(cmd, "search-all", "timemachine history <file>")
+	addSnapshotsAgoFlag(cmd, &ago)
 
 	return cmd
 }
 
-func runInspect(cmd *cobra.Command, args []string, showDiff, showStats bool, fileFilter string, verbose, searchAll bool) error {
+func runInspect(cmd *cobra.Command, args []string, showDiff, showStats bool, files, excludes []string, verbose, searchAll, sideBySide bool) error {
 	// Validate and sanitize file filter input
-	sanitizedFileFilter, err := sanitizeFilePath(fileFilter)
+	fileFilter, err := newPathFilter(files, excludes)
 	if err != nil {
 		return fmt.Errorf("invalid file filter: %w", err)
 	}
-	fileFilter = sanitizedFileFilter
 
 	// Create application state
 	state, err := core.NewAppState()
@@ -126,9 +138,9 @@ func runInspect(cmd *cobra.Command, args []string, showDiff, showStats bool, fil
 	}
 
 	if !state.IsInitialized {
-		color.Red("❌ Time Machine is not initialized")
-		fmt.Println("Run 'timemachine init' first to initialize the shadow repository.")
-		return nil
+		ui.Error("%s", ui.T(ui.MsgNotInitialized))
+		fmt.Println(ui.T(ui.MsgRunInit))
+		return core.Reported(core.ErrNotInitialized)
 	}
 
 	// Create Git manager
@@ -144,16 +156,15 @@ func runInspect(cmd *cobra.Command, args []string, showDiff, showStats bool, fil
 
 	// Handle search-all mode
 	if searchAll {
-		return runSearchAllSnapshots(state, fileFilter, showDiff, verbose)
+		return runSearchAllSnapshots(state, fileFilter, showDiff, verbose, sideBySide)
 	}
 
 	// Determine which snapshot to inspect
 	var targetHash string
 	if len(args) > 0 {
-		targetHash = args[0]
-		// Validate user-provided hash for security
-		if err := validateGitHash(targetHash); err != nil {
-			return fmt.Errorf("invalid snapshot hash: %w", err)
+		// Short hashes, tags and relative revisions resolve to full hashes
+		if targetHash, err = gitManager.ResolveSnapshot(args[0]); err != nil {
+			return err
 		}
 	} else {
 		// Get latest snapshot
@@ -162,19 +173,10 @@ func runInspect(cmd *cobra.Command, args []string, showDiff, showStats bool, fil
 			return fmt.Errorf("failed to get snapshots: %w", err)
 		}
 		if len(snapshots) == 0 {
-			color.Yellow("📝 No snapshots found")
+			ui.Warning("📝 No snapshots found")
 			return nil
 		}
 		targetHash = snapshots[0].Hash
-		// Internal hashes from ListSnapshots are trusted, but validate anyway for defense in depth
-		if err := validateGitHash(targetHash); err != nil {
-			return fmt.Errorf("internal hash validation failed: %w", err)
-		}
-	}
-
-	// Validate the hash exists
-	if !isValidHash(state, targetHash) {
-		return fmt.Errorf("snapshot hash '%s' not found", targetHash)
 	}
 
 	// Show snapshot overview
@@ -194,7 +196,7 @@ func runInspect(cmd *cobra.Command, args []string, showDiff, showStats bool, fil
 
 	// Show detailed diff if requested
 	if showDiff || verbose {
-		if err := showDetailedDiff(state, targetHash, fileFilter); err != nil {
+		if err := showDetailedDiff(state, targetHash, fileFilter, sideBySide); err != nil {
 			return fmt.Errorf("failed to show detailed diff: %w", err)
 		}
 	}
@@ -210,40 +212,31 @@ func runInspect(cmd *cobra.Command, args []string, showDiff, showStats bool, fil
 }
 
 func showRepositoryStats(state *core.AppState) error {
-	color.Cyan("🗄️  Repository Statistics")
-	color.Cyan("========================")
-
-	// Repository size
-	cmd := exec.Command("du", "-sh", state.ShadowRepoDir)
-	if sizeOutput, err := cmd.Output(); err == nil {
-		fmt.Printf("Repository size: %s", string(sizeOutput))
-	}
-
-	// Object count and storage details
-	cmd = exec.Command("git", "--git-dir="+state.ShadowRepoDir, "count-objects", "-v")
-	if objectOutput, err := cmd.Output(); err == nil {
-		lines := strings.Split(string(objectOutput), "\n")
-		for _, line := range lines {
-			line = strings.TrimSpace(line)
-			if line != "" {
-				fmt.Printf("  %s\n", line)
-			}
-		}
+	ui.Heading("🗄️  Repository Statistics")
+	ui.Heading("========================")
+
+	stats, err := core.NewStatsCollector(core.NewGitManager(state)).Collect()
+	if err != nil {
+		return err
 	}
 
-	// Total commits
-	cmd = exec.Command("git", "--git-dir="+state.ShadowRepoDir, "rev-list", "--count", "HEAD")
-	if countOutput, err := cmd.Output(); err == nil {
-		count := strings.TrimSpace(string(countOutput))
-		fmt.Printf("  total-snapshots: %s\n", count)
+	fmt.Printf("Repository size: %s (see 'timemachine size' for a breakdown)\n", utils.FormatBytes(stats.DiskBytes))
+	fmt.Printf("  snapshots:      %d\n", stats.Snapshots)
+	fmt.Printf("  loose objects:  %d (%s)\n", stats.LooseObjects, utils.FormatBytes(stats.LooseBytes))
+	fmt.Printf("  packed objects: %d in %d pack(s) (%s)\n", stats.PackedObjects, stats.Packs, utils.FormatBytes(stats.PackBytes))
+	if stats.PrunePackable > 0 {
+		fmt.Printf("  prune-packable: %d\n", stats.PrunePackable)
+	}
+	if stats.Garbage > 0 {
+		fmt.Printf("  garbage:        %d file(s) (%s)\n", stats.Garbage, utils.FormatBytes(stats.GarbageBytes))
 	}
 
 	return nil
 }
 
 func showSnapshotOverview(state *core.AppState, hash string) error {
-	color.Green("🔍 Snapshot Overview")
-	fmt.Printf("Hash: %s\n", hash)
+	ui.Heading("🔍 Snapshot Overview")
+	fmt.Printf("Hash: %s\n", ui.Sprint(ui.RoleHash, hash))
 
 	// Get commit info
 	cmd := exec.Command("git", "--git-dir="+state.ShadowRepoDir, "--work-tree="+state.ProjectRoot,
@@ -257,117 +250,92 @@ func showSnapshotOverview(state *core.AppState, hash string) error {
 			fmt.Printf("Message: %s\n", lines[2])
 		}
 	}
+	if note, err := core.NewGitManager(state).Note(hash); err == nil && note != "" {
+		ui.Info("📝 Note:")
+		printNote(note)
+	}
 	fmt.Println()
 
 	return nil
 }
 
-func showFileChanges(state *core.AppState, hash string, fileFilter string) error {
-	color.Blue("📝 File Changes")
-	color.Blue("===============")
-
-	// Build command args
-	args := []string{"--git-dir=" + state.ShadowRepoDir, "--work-tree=" + state.ProjectRoot,
-		"show", "--name-status", hash}
-	
-	if fileFilter != "" {
-		args = append(args, "--", fileFilter)
-	}
+func showFileChanges(state *core.AppState, hash string, fileFilter pathFilter) error {
+	ui.Heading("📝 File Changes")
+	ui.Heading("===============")
 
-	cmd := exec.Command("git", args...)
-	output, err := cmd.Output()
+	changes, err := snapshotChanges(state, hash, fileFilter)
 	if err != nil {
 		return fmt.Errorf("failed to get file changes: %w", err)
 	}
 
-	// Parse and display file changes
-	lines := strings.Split(string(output), "\n")
-	fileCount := 0
-	
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" || strings.HasPrefix(line, "commit ") || 
-		   strings.HasPrefix(line, "Author:") || strings.HasPrefix(line, "Date:") ||
-		   strings.Contains(line, "Snapshot at") {
-			continue
+	for _, change := range changes {
+		// Color-code the status
+		var statusColor *color.Color
+		var statusText string
+		switch change.Status {
+		case "A":
+			statusColor = ui.Color(ui.RoleAdded)
+			statusText = "Added"
+		case "M":
+			statusColor = ui.Color(ui.RoleModified)
+			statusText = "Modified"
+		case "D":
+			statusColor = ui.Color(ui.RoleDeleted)
+			statusText = "Deleted"
+		case "R":
+			statusColor = ui.Color(ui.RoleRenamed)
+			statusText = "Renamed"
+		case "C":
+			statusColor = ui.Color(ui.RoleRenamed)
+			statusText = "Copied"
+		default:
+			statusColor = ui.Color(ui.RoleMuted)
+			statusText = change.Status
 		}
 
-		// Parse status and filename
-		parts := strings.SplitN(line, "\t", 2)
-		if len(parts) == 2 {
-			status := parts[0]
-			filename := parts[1]
-			fileCount++
-
-			// Color-code the status
-			var statusColor *color.Color
-			var statusText string
-			switch status {
-			case "A":
-				statusColor = color.New(color.FgGreen)
-				statusText = "Added"
-			case "M":
-				statusColor = color.New(color.FgYellow) 
-				statusText = "Modified"
-			case "D":
-				statusColor = color.New(color.FgRed)
-				statusText = "Deleted"
-			case "R":
-				statusColor = color.New(color.FgBlue)
-				statusText = "Renamed"
-			default:
-				statusColor = color.New(color.FgWhite)
-				statusText = status
-			}
-
-			statusColor.Printf("  %s", statusText)
-			fmt.Printf("\t%s\n", filename)
+		name := change.Path
+		if change.OldPath != "" {
+			name = fmt.Sprintf("%s → %s (%d%%)", change.OldPath, change.Path, change.Similarity)
+		}
+		counts := ui.Sprint(ui.RoleAdded, fmt.Sprintf("+%d", change.Insertions)) + " " + ui.Sprint(ui.RoleDeleted, fmt.Sprintf("-%d", change.Deletions))
+		if change.Binary {
+			counts = ui.Sprint(ui.RoleMuted, "binary")
 		}
+
+		statusColor.Printf("  %s", statusText)
+		fmt.Printf("\t%s\t%s\n", name, counts)
 	}
 
-	if fileCount == 0 {
-		color.Yellow("  No file changes found")
-		if fileFilter != "" {
-			fmt.Printf("  (filtered for: %s)\n", fileFilter)
+	if len(changes) == 0 {
+		ui.Warning("  No file changes found")
+		if fileFilter.label != "" {
+			fmt.Printf("  (filtered for: %s)\n", fileFilter.label)
 		}
 	} else {
-		fmt.Printf("\nTotal files changed: %d\n", fileCount)
+		fmt.Printf("\nTotal files changed: %d\n", len(changes))
 	}
 	fmt.Println()
 
 	return nil
 }
 
-func showDeletedFiles(state *core.AppState, hash string, fileFilter string) error {
-	// Get list of deleted files
-	args := []string{"--git-dir=" + state.ShadowRepoDir, "--work-tree=" + state.ProjectRoot,
-		"show", "--name-status", hash}
-	
-	if fileFilter != "" {
-		args = append(args, "--", fileFilter)
-	}
+// snapshotChanges returns the files a snapshot changed, limited to
+// fileFilter when set
+func snapshotChanges(state *core.AppState, hash string, fileFilter pathFilter) ([]core.FileChange, error) {
+	return core.NewGitManager(state).SnapshotFileChanges(hash, fileFilter.pathspecs...)
+}
 
-	cmd := exec.Command("git", args...)
-	output, err := cmd.Output()
+func showDeletedFiles(state *core.AppState, hash string, fileFilter pathFilter) error {
+	changes, err := snapshotChanges(state, hash, fileFilter)
 	if err != nil {
 		return fmt.Errorf("failed to get file changes: %w", err)
 	}
 
 	// Find deleted files
 	deletedFiles := []string{}
-	lines := strings.Split(string(output), "\n")
-	
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" || strings.HasPrefix(line, "commit ") || 
-		   strings.HasPrefix(line, "Author:") || strings.HasPrefix(line, "Date:") ||
-		   strings.Contains(line, "Snapshot at") {
-			continue
-		}
-
-		parts := strings.SplitN(line, "\t", 2)
-		if len(parts) == 2 && parts[0] == "D" {
-			deletedFiles = append(deletedFiles, parts[1])
+	for _, change := range changes {
+		if change.Status == "D" {
+			deletedFiles = append(deletedFiles, change.Path)
 		}
 	}
 
@@ -375,8 +343,8 @@ func showDeletedFiles(state *core.AppState, hash string, fileFilter string) erro
 		return nil
 	}
 
-	color.Red("🗑️  Deleted File Contents")
-	color.Red("========================")
+	ui.Line(ui.RoleDeleted, "🗑️  Deleted File Contents")
+	ui.Line(ui.RoleDeleted, "========================")
 
 	for _, filename := range deletedFiles {
 		// Get the parent commit to show what the file contained before deletion
@@ -395,19 +363,20 @@ func showDeletedFiles(state *core.AppState, hash string, fileFilter string) erro
 		fileCmd := exec.Command("git", "--git-dir="+state.ShadowRepoDir, "show", parent+":"+filename)
 		fileContent, err := fileCmd.Output()
 		if err != nil {
-			color.Yellow(fmt.Sprintf("⚠️  Could not retrieve content of deleted file: %s", filename))
+			ui.Warning("⚠️  Could not retrieve content of deleted file: %s", filename)
 			continue
 		}
 
-		color.Cyan(fmt.Sprintf("📄 File: %s (before deletion)", filename))
-		color.Cyan(strings.Repeat("-", len(filename)+25))
+		ui.Info("📄 File: %s (before deletion)", filename)
+		ui.Info(strings.Repeat("-", len(filename)+25))
 		
 		// Show file contents with line numbers
+		highlighter := render.NewHighlighter(filename)
 		contentLines := strings.Split(string(fileContent), "\n")
 		for i, contentLine := range contentLines {
 			if i < len(contentLines)-1 || contentLine != "" { // Skip last empty line
-				color.New(color.FgYellow).Printf("%4d: ", i+1)
-				fmt.Println(contentLine)
+				ui.Color(ui.RoleMuted).Printf("%4d: ", i+1)
+				fmt.Println(highlighter.Line(contentLine))
 			}
 		}
 		fmt.Println()
@@ -416,16 +385,16 @@ func showDeletedFiles(state *core.AppState, hash string, fileFilter string) erro
 	return nil
 }
 
-func showDetailedDiff(state *core.AppState, hash string, fileFilter string) error {
-	color.Magenta("📋 Detailed Changes")
-	color.Magenta("===================")
+func showDetailedDiff(state *core.AppState, hash string, fileFilter pathFilter, sideBySide bool) error {
+	ui.Heading("📋 Detailed Changes")
+	ui.Heading("===================")
 
 	// Build command args
 	args := []string{"--git-dir=" + state.ShadowRepoDir, "--work-tree=" + state.ProjectRoot,
 		"show", hash}
 	
-	if fileFilter != "" {
-		args = append(args, "--", fileFilter)
+	if len(fileFilter.pathspecs) > 0 {
+		args = append(append(args, "--"), fileFilter.pathspecs...)
 	}
 
 	cmd := exec.Command("git", args...)
@@ -434,11 +403,17 @@ func showDetailedDiff(state *core.AppState, hash string, fileFilter string) erro
 		return fmt.Errorf("failed to get detailed diff: %w", err)
 	}
 
+	if sideBySide {
+		render.SideBySide{Width: render.TerminalWidth(os.Stdout)}.Render(os.Stdout, string(output))
+		return nil
+	}
+
 	// Format the diff output
 	lines := strings.Split(string(output), "\n")
 	inDiffSection := false
 	currentFile := ""
 	isDeletedFile := false
+	var highlighter *render.Highlighter
 	
 	for _, line := range lines {
 		if strings.HasPrefix(line, "diff --git") {
@@ -448,28 +423,31 @@ func showDetailedDiff(state *core.AppState, hash string, fileFilter string) erro
 			if len(parts) >= 4 {
 				currentFile = strings.TrimPrefix(parts[2], "a/")
 			}
-			color.Cyan(line)
+			highlighter = render.NewHighlighter(diffFileName(line))
+			ui.Line(ui.RoleDiffHeader, "%s", line)
 		} else if strings.HasPrefix(line, "deleted file mode") {
 			isDeletedFile = true
-			color.Red("🗑️  " + line + " - File was completely removed")
+			ui.Line(ui.RoleDeleted, "🗑️  %s - File was completely removed", line)
 		} else if strings.HasPrefix(line, "new file mode") {
 			isDeletedFile = false
-			color.Green("📄 " + line + " - New file was added")
+			ui.Line(ui.RoleAdded, "📄 %s - New file was added", line)
 		} else if strings.HasPrefix(line, "@@") {
 			if isDeletedFile && currentFile != "" {
-				color.Yellow(fmt.Sprintf("📖 Contents of deleted file '%s':", currentFile))
+				ui.Warning("📖 Contents of deleted file '%s':", currentFile)
 			}
-			color.Blue(line)
+			ui.Line(ui.RoleDiffHunk, "%s", line)
 		} else if strings.HasPrefix(line, "+") {
-			color.Green(line)
+			fmt.Println(highlightDiffLine(highlighter, ui.RoleAdded, line))
 		} else if strings.HasPrefix(line, "-") {
 			if isDeletedFile {
 				// Highlight deleted file content differently
-				color.New(color.FgRed, color.BgBlack).Print("- ")
-				color.New(color.FgWhite).Println(line[1:])
+				ui.Color(ui.RoleDeleted).Print("- ")
+				fmt.Println(highlighter.Line(line[1:]))
 			} else {
-				color.Red(line)
+				fmt.Println(highlightDiffLine(highlighter, ui.RoleDeleted, line))
 			}
+		} else if inDiffSection && strings.HasPrefix(line, " ") {
+			fmt.Println(" " + highlighter.Line(line[1:]))
 		} else if inDiffSection {
 			fmt.Println(line)
 		}
@@ -485,25 +463,17 @@ func showDetailedDiff(state *core.AppState, hash string, fileFilter string) erro
 
 func showComprehensiveAnalysis(state *core.AppState, hash string) error {
 	fmt.Println()
-	color.Cyan("📊 Comprehensive Analysis")
-	color.Cyan("=========================")
+	ui.Heading("📊 Comprehensive Analysis")
+	ui.Heading("=========================")
 
-	// Show diff stats
-	cmd := exec.Command("git", "--git-dir="+state.ShadowRepoDir, "--work-tree="+state.ProjectRoot,
-		"show", "--stat", hash)
-	
-	if output, err := cmd.Output(); err == nil {
-		fmt.Println("Statistics:")
-		lines := strings.Split(string(output), "\n")
-		for _, line := range lines {
-			if strings.Contains(line, "|") || strings.Contains(line, "changed") {
-				fmt.Printf("  %s\n", line)
-			}
-		}
+	// Sum the changes by directory and file type
+	if changes, err := snapshotChanges(state, hash, pathFilter{}); err == nil && len(changes) > 0 {
+		fmt.Print("Statistics:")
+		showChangeSummary(changes)
 	}
 
 	// Show parent commit (what it was based on)
-	cmd = exec.Command("git", "--git-dir="+state.ShadowRepoDir, "show", "--format=%P", "--no-patch", hash)
+	cmd := exec.Command("git", "--git-dir="+state.ShadowRepoDir, "show", "--format=%P", "--no-patch", hash)
 	if output, err := cmd.Output(); err == nil {
 		parent := strings.TrimSpace(string(output))
 		if parent != "" {
@@ -523,11 +493,6 @@ func showComprehensiveAnalysis(state *core.AppState, hash string) error {
 	return nil
 }
 
-func isValidHash(state *core.AppState, hash string) bool {
-	cmd := exec.Command("git", "--git-dir="+state.ShadowRepoDir, "cat-file", "-e", hash)
-	return cmd.Run() == nil
-}
-
 func formatBytes(bytes int64) string {
 	const unit = 1024
 	if bytes < unit {
@@ -541,25 +506,25 @@ func formatBytes(bytes int64) string {
 	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
 }
 
-func runSearchAllSnapshots(state *core.AppState, fileFilter string, showDiff, verbose bool) error {
-	// File filter is already validated in runInspect, but validate again for defense in depth
-	if _, err := sanitizeFilePath(fileFilter); err != nil {
-		return fmt.Errorf("invalid file filter in search-all: %w", err)
-	}
-	color.Green("🔍 Searching All Snapshots")
-	if fileFilter != "" {
-		color.Cyan(fmt.Sprintf("📁 File History: %s", fileFilter))
+func runSearchAllSnapshots(state *core.AppState, fileFilter pathFilter, showDiff, verbose, sideBySide bool) error {
+	ui.Heading("🔍 Searching All Snapshots")
+	if fileFilter.label != "" {
+		ui.Info("📁 File History: %s", fileFilter.label)
 	} else {
-		color.Cyan("📊 All Snapshots")
+		ui.Info("📊 All Snapshots")
 	}
 	fmt.Println()
 
 	// Use Git's native --follow command for efficient file history
 	var args []string
-	if fileFilter != "" {
+	if fileFilter.follow != "" {
 		// Use git log --follow for file-specific history (most efficient)
 		args = []string{"--git-dir=" + state.ShadowRepoDir, "--work-tree=" + state.ProjectRoot,
-			"log", "--follow", "--oneline", "--date=short", "--format=%H|%ad|%s", "--", fileFilter}
+			"log", "--follow", "--oneline", "--date=short", "--format=%H|%ad|%s", "--", fileFilter.follow}
+	} else if len(fileFilter.pathspecs) > 0 {
+		// Renames can only be followed for a single file
+		args = append([]string{"--git-dir=" + state.ShadowRepoDir, "--work-tree=" + state.ProjectRoot,
+			"log", "--oneline", "--date=short", "--format=%H|%ad|%s", "--"}, fileFilter.pathspecs...)
 	} else {
 		// Show all snapshots
 		args = []string{"--git-dir=" + state.ShadowRepoDir, "--work-tree=" + state.ProjectRoot,
@@ -574,14 +539,14 @@ func runSearchAllSnapshots(state *core.AppState, fileFilter string, showDiff, ve
 
 	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
 	if len(lines) == 1 && lines[0] == "" {
-		color.Yellow("📝 No snapshots found")
-		if fileFilter != "" {
-			fmt.Printf("   (no history found for: %s)\n", fileFilter)
+		ui.Warning("📝 No snapshots found")
+		if fileFilter.label != "" {
+			fmt.Printf("   (no history found for: %s)\n", fileFilter.label)
 		}
 		return nil
 	}
 
-	color.Green(fmt.Sprintf("📸 Found %d snapshot(s)\n", len(lines)))
+	ui.Success("📸 Found %d snapshot(s)\n", len(lines))
 
 	for i, line := range lines {
 		parts := strings.SplitN(line, "|", 3)
@@ -593,12 +558,12 @@ func runSearchAllSnapshots(state *core.AppState, fileFilter string, showDiff, ve
 		date := parts[1] 
 		message := parts[2]
 
-		color.Cyan(fmt.Sprintf("📸 Snapshot %d/%d - %s", i+1, len(lines), hash[:8]))
+		ui.Info("📸 Snapshot %d/%d - %s", i+1, len(lines), hash[:8])
 		fmt.Printf("📅 %s - %s\n", date, message)
 
 		// Show what files changed in this snapshot
 		if showDiff || verbose {
-			if err := showDetailedDiff(state, hash, fileFilter); err == nil {
+			if err := showDetailedDiff(state, hash, fileFilter, sideBySide); err == nil {
 				fmt.Println()
 			}
 		} else {
@@ -612,9 +577,9 @@ func runSearchAllSnapshots(state *core.AppState, fileFilter string, showDiff, ve
 	}
 
 	// Show additional file operations if specific file requested
-	if fileFilter != "" && (showDiff || verbose) {
-		if err := showFileOperationsHistory(state, fileFilter); err != nil {
-			color.Yellow(fmt.Sprintf("⚠️  Could not show operation history: %v", err))
+	if fileFilter.follow != "" && (showDiff || verbose) {
+		if err := showFileOperationsHistory(state, fileFilter.follow); err != nil {
+			ui.Warning("⚠️  Could not show operation history: %v", err)
 		}
 	}
 
@@ -623,8 +588,8 @@ func runSearchAllSnapshots(state *core.AppState, fileFilter string, showDiff, ve
 
 func showFileOperationsHistory(state *core.AppState, filename string) error {
 	fmt.Println()
-	color.Magenta("📋 File Operations History")
-	color.Magenta("==========================")
+	ui.Heading("📋 File Operations History")
+	ui.Heading("==========================")
 
 	// Show renames/moves using --follow --name-status
 	args := []string{"--git-dir=" + state.ShadowRepoDir, "--work-tree=" + state.ProjectRoot,
@@ -661,13 +626,13 @@ func showFileOperationsHistory(state *core.AppState, filename string) error {
 				
 				switch status {
 				case "A":
-					color.Green(fmt.Sprintf("  ✅ Added: %s", file))
+					ui.Line(ui.RoleAdded, "  ✅ Added: %s", file)
 				case "M":
-					color.Yellow(fmt.Sprintf("  ✏️  Modified: %s", file))
+					ui.Line(ui.RoleModified, "  ✏️  Modified: %s", file)
 				case "D":
-					color.Red(fmt.Sprintf("  🗑️  Deleted: %s", file))
+					ui.Line(ui.RoleDeleted, "  🗑️  Deleted: %s", file)
 				case "R100":
-					color.Blue(fmt.Sprintf("  📝 Renamed: %s", file))
+					ui.Line(ui.RoleRenamed, "  📝 Renamed: %s", file)
 				default:
 					fmt.Printf("  %s: %s\n", status, file)
 				}
@@ -1,19 +1,27 @@
 package commands
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"strings"
 
-	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 	"github.com/deepakkumarnarayana/timemachine-cli/internal/core"
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/ui"
 	"github.com/deepakkumarnarayana/timemachine-cli/internal/utils"
 )
 
 // ListCmd creates the list command
 func ListCmd() *cobra.Command {
 	var (
-		filePath string
-		limit    int
+		filter       core.SnapshotFilter
+		since, until string
+		sinceCommit  string
+		noPager      bool
+		fast         bool
+		files        []string
+		excludes     []string
 	)
 
 	cmd := &cobra.Command{
@@ -21,20 +29,119 @@ func ListCmd() *cobra.Command {
 		Short: "List recent snapshots",
 		Long: `List recent snapshots from the Time Machine shadow repository.
 
-You can filter snapshots by file and limit the number of results.`,
+Filters combine: only snapshots matching all of them are shown. --since and
+--until take an age (2h, 7d) or a date (2024-03-09, or 2024-03-09 14:00); a
+date alone given to --until includes that whole day. --grep is a
+case-insensitive regular expression matched against snapshot messages.
+--since-commit takes a commit of the project and shows the snapshots taken
+since it: those from the snapshot that recorded it on, with the hooks
+'timemachine init --commit-hooks' installs, or else since it was committed.
+--session takes the ID or name of a session started with 'timemachine
+session start' and shows the snapshots taken during it. --agent shows the
+snapshots taken while an AI coding agent (claude, cursor, aider, copilot,
+codex, gemini) was running in the project, as recorded with detect.agents.
+
+Snapshots are printed as they are read, through a pager according to the
+ui.pager setting, so --limit 0 lists even huge histories without delay. Use
+--limit and --offset to page through them instead.
+
+Each snapshot shows how many files it changed and its +/- line counts,
+read for 50 snapshots at a time. The ui.list_stats setting controls this:
+auto (the default) shows them unless listing everything with --limit 0,
+always, or never. --fast skips them for one listing.
+
+--file and --exclude may be repeated, and take files, directories or glob
+patterns, where ** spans directories; a snapshot is listed when it changed
+a file they select. Git pathspec magic such as :(icase) is accepted.
+
+Examples:
+  timemachine list --since 2h
+  timemachine list --since 2024-03-01 --until 2024-03-09
+  timemachine list --branch feature/login --grep "src/api"
+  timemachine list --all-branches --since 1d
+  timemachine list --since-commit HEAD~3    # the edits behind the last 3 commits
+  timemachine list --session s2
+  timemachine list --agent aider
+  timemachine list --file main.go -n 5
+  timemachine list --file 'src/**' --exclude '**/*_test.go'
+  timemachine list --limit 50 --offset 50   # the second page of 50
+  timemachine list --limit 0                # everything, through the pager
+  timemachine list --fast                   # without file statistics`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runList(filePath, limit)
+			var err error
+			if since != "" {
+				if filter.Since, err = core.ParseTimeBound(since, false); err != nil {
+					return fmt.Errorf("invalid --since: %w", err)
+				}
+			}
+			if until != "" {
+				if filter.Until, err = core.ParseTimeBound(until, true); err != nil {
+					return fmt.Errorf("invalid --until: %w", err)
+				}
+			}
+			fileLabel := strings.Join(files, ", ")
+			if len(files) == 1 && len(excludes) == 0 && !strings.HasPrefix(files[0], ":") && !strings.ContainsAny(files[0], "*?[") {
+				// A single path is passed as is: it may be absolute
+				filter.File = files[0]
+			} else if len(files) > 0 || len(excludes) > 0 {
+				pathFilter, err := newPathFilter(files, excludes)
+				if err != nil {
+					return fmt.Errorf("invalid file filter: %w", err)
+				}
+				filter.Pathspecs, fileLabel = pathFilter.pathspecs, pathFilter.label
+			}
+			return runList(filter, fileLabel, sinceCommit, noPager, fast)
 		},
 	}
 
 	// Add flags
-	cmd.Flags().StringVarP(&filePath, "file", "f", "", "Filter snapshots by file path")
-	cmd.Flags().IntVarP(&limit, "limit", "n", 20, "Limit number of snapshots to show")
+	cmd.Flags().StringSliceVarP(&files, "file", "f", nil, "Only snapshots changing these files, directories or glob patterns (repeatable)")
+	cmd.Flags().StringSliceVar(&excludes, "exclude", nil, "Leave out changes to these files, directories or glob patterns (repeatable)")
+	cmd.Flags().IntVarP(&filter.Limit, "limit", "n", 20, "Limit number of snapshots to show (0 for all)")
+	cmd.Flags().IntVar(&filter.Offset, "offset", 0, "Skip this many matching snapshots (for paging)")
+	cmd.Flags().StringVarP(&filter.Branch, "branch", "b", "", "List snapshots of this branch instead of the current one")
+	cmd.Flags().BoolVar(&filter.AllBranches, "all-branches", false, "List snapshots of every shadow branch, showing each one's branch")
+	cmd.MarkFlagsMutuallyExclusive("branch", "all-branches")
+	cmd.RegisterFlagCompletionFunc("branch", completeShadowBranches)
+	cmd.Flags().StringVar(&since, "since", "", "Only snapshots taken since this age or date")
+	cmd.Flags().StringVar(&sinceCommit, "since-commit", "", "Only snapshots taken since this commit of the project")
+	cmd.MarkFlagsMutuallyExclusive("since", "since-commit")
+	cmd.Flags().StringVar(&until, "until", "", "Only snapshots taken until this age or date")
+	cmd.Flags().StringVar(&filter.Grep, "grep", "", "Only snapshots whose message matches this regular expression")
+	cmd.Flags().StringVar(&filter.Session, "session", "", "Only snapshots taken during this named session")
+	cmd.Flags().StringVar(&filter.Agent, "agent", "", "Only snapshots taken while this AI agent was running")
+	cmd.Flags().BoolVar(&noPager, "no-pager", false, "Write directly to stdout instead of a pager")
+	cmd.Flags().BoolVar(&fast, "fast", false, "Skip the per-snapshot file statistics")
 
 	return cmd
 }
 
-func runList(filePath string, limit int) error {
+// errListOutputClosed stops the snapshot walk once output can't be written
+var errListOutputClosed = errors.New("output closed")
+
+// listStats decides whether list shows file statistics: never with --fast,
+// else by ui.list_stats, where auto skips them for unbounded listings
+func listStats(state *core.AppState, limit int, fast bool) bool {
+	if fast {
+		return false
+	}
+	mode := "auto"
+	if state.Config != nil && state.Config.UI.ListStats != "" {
+		mode = state.Config.UI.ListStats
+	}
+	switch mode {
+	case "always":
+		return true
+	case "never":
+		return false
+	default:
+		return limit > 0
+	}
+}
+
+// runList lists the snapshots matching filter; fileLabel describes its file
+// filter, if any, for messages
+func runList(filter core.SnapshotFilter, fileLabel, sinceCommit string, noPager, fast bool) error {
 	// Create application state
 	state, err := core.NewAppState()
 	if err != nil {
@@ -43,61 +150,173 @@ func runList(filePath string, limit int) error {
 
 	// Check if initialized
 	if !state.IsInitialized {
-		color.Red("❌ Time Machine is not initialized!")
-		fmt.Println("Run 'timemachine init' to get started.")
-		return nil
+		ui.Error("%s", ui.T(ui.MsgNotInitialized))
+		fmt.Println(ui.T(ui.MsgRunInit))
+		return core.Reported(core.ErrNotInitialized)
 	}
 
 	// Create Git manager
 	gitManager := core.NewGitManager(state)
+	if sinceCommit != "" {
+		if filter.Since, err = gitManager.SinceProjectCommit(sinceCommit); err != nil {
+			return fmt.Errorf("invalid --since-commit: %w", err)
+		}
+	}
+	if filter.Session != "" {
+		session, err := gitManager.FindNamedSession(filter.Session)
+		if err != nil {
+			return err
+		}
+		filter.Session = session.ID
+		if filter.Branch == "" && !filter.AllBranches {
+			filter.Branch = session.Branch
+		}
+	}
+	filtered := filter.Session != "" || filter.Agent != "" || filter.Branch != "" || filter.AllBranches || !filter.Since.IsZero() || !filter.Until.IsZero() || filter.Grep != ""
+
+	// Snapshots are printed as git log produces them, so the first page shows
+	// up immediately even on huge histories. With statistics they are
+	// printed a batch at a time, each batch's read in one git process.
+	var out io.Writer
+	done := func() {}
+	defer func() { done() }()
+	count := 0
+	notes, _ := gitManager.Notes()
+	withStats := listStats(state, filter.Limit, fast)
+	var batch []core.Snapshot
+	printBatch := func() error {
+		var stats map[string]core.SnapshotChanges
+		if withStats {
+			hashes := make([]string, len(batch))
+			for i, snapshot := range batch {
+				hashes[i] = snapshot.Hash
+			}
+			stats, _ = gitManager.SnapshotStats(hashes) // Without them the listing still works
+		}
+		for _, snapshot := range batch {
+			changes, ok := stats[snapshot.Hash]
+			if err := printListEntry(out, snapshot, filter.AllBranches, withStats, changes, ok, notes[snapshot.Hash]); err != nil {
+				return err
+			}
+		}
+		batch = batch[:0]
+		return nil
+	}
+	err = gitManager.WalkSnapshots(filter, func(snapshot core.Snapshot) error {
+		if count == 0 {
+			out, done = startPager(state, noPager)
+			fmt.Fprintln(out, ui.T(ui.MsgRecentSnapshots))
+			fmt.Fprintln(out)
+		}
+		count++
 
-	// Get snapshots
-	snapshots, err := gitManager.ListSnapshots(limit, filePath)
+		batch = append(batch, snapshot)
+		if !withStats || len(batch) == core.SnapshotStatsBatch {
+			return printBatch()
+		}
+		return nil
+	})
+	if err == nil && len(batch) > 0 {
+		err = printBatch()
+	}
+	if errors.Is(err, errListOutputClosed) {
+		// The pager was quit before everything was shown
+		return nil
+	}
 	if err != nil {
 		return fmt.Errorf("failed to list snapshots: %w", err)
 	}
 
 	// Handle empty results
-	if len(snapshots) == 0 {
-		fmt.Println("📸 No snapshots found.")
-		if filePath != "" {
-			fmt.Printf("   Try without the --file filter or check if '%s' exists.\n", filePath)
+	if count == 0 {
+		fmt.Println(ui.T(ui.MsgNoSnapshots))
+		if fileLabel != "" {
+			fmt.Println("   " + ui.T(ui.MsgTryWithoutFileFilter, fileLabel))
+		} else if filter.Offset > 0 {
+			fmt.Println("   " + ui.T(ui.MsgNoSnapshotsPastOffset, filter.Offset))
+		} else if filtered {
+			fmt.Println("   " + ui.T(ui.MsgTryWideningFilters))
 		} else {
-			fmt.Println("   Create your first snapshot by making changes to files.")
+			fmt.Println("   " + ui.T(ui.MsgCreateFirstSnapshot))
 		}
 		return nil
 	}
-
-	// Display header
-	fmt.Println("📸 Recent snapshots:")
-	fmt.Println()
-
-	// Simple table output without tablewriter for now
-	for _, snapshot := range snapshots {
-		// Truncate hash to 8 characters for display
-		shortHash := snapshot.Hash
-		if len(shortHash) > 8 {
-			shortHash = shortHash[:8]
-		}
-		
-		// Format with consistent spacing
-		fmt.Printf("%-10s  %-50s  %s\n", 
-			shortHash, 
-			utils.TruncateString(snapshot.Message, 50), 
-			snapshot.Time,
-		)
-	}
 	
 	// Display summary
-	fmt.Println()
-	if filePath != "" {
-		fmt.Printf("Total: %d snapshots for '%s'\n", len(snapshots), filePath)
+	fmt.Fprintln(out)
+	if fileLabel != "" {
+		fmt.Fprintln(out, ui.T(ui.MsgTotalSnapshotsForFile, count, fileLabel))
+	} else if filtered {
+		fmt.Fprintln(out, ui.T(ui.MsgTotalMatchingSnapshots, count))
 	} else {
-		fmt.Printf("Total: %d snapshots\n", len(snapshots))
+		fmt.Fprintln(out, ui.T(ui.MsgTotalSnapshots, count))
+	}
+	if filter.Limit > 0 && count == filter.Limit {
+		fmt.Fprintln(out, ui.T(ui.MsgMoreSnapshots, filter.Offset+count))
 	}
-	fmt.Println()
-	fmt.Println("Use 'timemachine show <hash>' to see details")
-	fmt.Println("Use 'timemachine restore <hash>' to restore a snapshot")
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, ui.T(ui.MsgUseShow))
+	fmt.Fprintln(out, ui.T(ui.MsgUseRestore))
 
 	return nil
-}
\ No newline at end of file
+}
+
+// printListEntry prints one snapshot of the list. The statistics column is
+// there when withStats is set, blank if they couldn't be read (hasChanges
+// unset).
+func printListEntry(out io.Writer, snapshot core.Snapshot, showBranch, withStats bool, changes core.SnapshotChanges, hasChanges bool, note string) error {
+	// Truncate hash to 8 characters for display
+	shortHash := snapshot.Hash
+	if len(shortHash) > 8 {
+		shortHash = shortHash[:8]
+	}
+
+	branch := ""
+	if showBranch {
+		branch = ui.Sprint(ui.RoleMuted, fmt.Sprintf("%-20s", utils.TruncateString(snapshot.Branch, 20))) + "  "
+	}
+
+	stats := ""
+	if withStats {
+		stats = formatListStats(changes, hasChanges) + "  "
+	}
+
+	// Format with consistent spacing
+	if _, err := fmt.Fprintf(out, "%s  %s%-50s  %s%s\n",
+		ui.Sprint(ui.RoleHash, fmt.Sprintf("%-10s", shortHash)),
+		branch,
+		utils.TruncateString(snapshot.Message, 50),
+		stats,
+		snapshot.Time,
+	); err != nil {
+		return errListOutputClosed
+	}
+	if note != "" {
+		fmt.Fprintf(out, "%10s  %s\n", "", ui.Sprint(ui.RoleInfo, "📝 "+utils.TruncateString(core.NoteSummary(note), 60)))
+	}
+	return nil
+}
+
+// formatListStats renders the file count and line counts of a snapshot
+// padded to a fixed width; padding is applied before coloring so escape
+// codes don't skew alignment
+func formatListStats(changes core.SnapshotChanges, ok bool) string {
+	const filesWidth, linesWidth = 9, 14
+	if !ok {
+		return fmt.Sprintf("%*s", filesWidth+1+linesWidth, "")
+	}
+
+	files := fmt.Sprintf("%d file", len(changes.Files))
+	if len(changes.Files) != 1 {
+		files += "s"
+	}
+	insertions, deletions := changes.Totals()
+	added := fmt.Sprintf("+%d", insertions)
+	removed := fmt.Sprintf("-%d", deletions)
+	padding := linesWidth - len(added) - len(removed) - 1
+	if padding < 0 {
+		padding = 0
+	}
+	return ui.Sprint(ui.RoleMuted, fmt.Sprintf("%*s", filesWidth, files)) + " " +
+		ui.Sprint(ui.RoleAdded, added) + " " + ui.Sprint(ui.RoleDeleted, removed) + fmt.Sprintf("%*s", padding, "")
+}
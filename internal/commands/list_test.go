@@ -0,0 +1,31 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/config"
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/core"
+)
+
+func TestListStats(t *testing.T) {
+	tests := []struct {
+		mode     string
+		limit    int
+		fast     bool
+		expected bool
+	}{
+		{"auto", 20, false, true},
+		{"auto", 0, false, false},
+		{"", 20, false, true},
+		{"always", 0, false, true},
+		{"always", 20, true, false},
+		{"never", 20, false, false},
+	}
+
+	for _, tt := range tests {
+		state := &core.AppState{Config: &config.Config{UI: config.UIConfig{ListStats: tt.mode}}}
+		if got := listStats(state, tt.limit, tt.fast); got != tt.expected {
+			t.Errorf("listStats(%q, limit %d, fast %v) = %v, want %v", tt.mode, tt.limit, tt.fast, got, tt.expected)
+		}
+	}
+}
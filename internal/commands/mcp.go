@@ -0,0 +1,49 @@
+package commands
+
+import (
+	"os"
+
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/core"
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/mcp"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// MCPServeCmd creates the mcp-serve command
+func MCPServeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "mcp-serve",
+		Short: "Serve snapshots to AI agents over the Model Context Protocol",
+		Long: `Run a Model Context Protocol (MCP) server on stdin and stdout, so AI coding
+agents can checkpoint and roll back their own edits. It offers the tools
+create_snapshot, list_snapshots, diff_snapshot and restore_files; paths are
+relative to the project root. Restores take a pre-restore snapshot and refuse
+to overwrite uncommitted Git changes unless the agent sets force.
+
+Agents start the server themselves. Register it in their MCP configuration,
+for example:
+
+  {"mcpServers": {"timemachine": {"command": "timemachine", "args": ["mcp-serve"]}}}
+
+with the project as working directory. Messages to the user go to stderr.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			state, err := loadInitializedState()
+			if err != nil || state == nil {
+				return err
+			}
+
+			// stdout carries the protocol alone: anything else printed,
+			// including by hooks and warnings, goes to stderr
+			protocol := os.Stdout
+			os.Stdout = os.Stderr
+			color.Output = os.Stderr
+			defer func() { os.Stdout = protocol }()
+
+			server := mcp.NewServer(core.NewGitManager(state), cmd.Root().Version)
+			return server.Serve(os.Stdin, protocol)
+		},
+	}
+
+	return cmd
+}
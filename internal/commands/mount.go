@@ -0,0 +1,190 @@
+package commands
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/core"
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/ui"
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+// MountCmd creates the mount command
+func MountCmd() *cobra.Command {
+	var (
+		list    bool
+		refresh bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "mount <hash> <dir> [paths...]",
+		Short: "Open a snapshot read-only in a separate directory",
+		Long: `Open a snapshot, or some of its paths, as a read-only directory tree next to
+your working directory, to browse, grep or run it side by side with what
+you have now. Mounts are remembered until 'timemachine unmount' removes them.
+
+FUSE is not used: the snapshot's files are extracted into the directory,
+cloned copy-on-write from your working directory where the filesystem
+supports it (APFS, Btrfs, XFS), so even large snapshots open quickly. A
+mount shows the snapshot it resolved to when mounted; mount a revision such
+as HEAD and use --refresh to bring it up to date.
+
+Examples:
+  timemachine mount abc12345 ../preview
+  timemachine mount HEAD~3 /tmp/before src/       # Only src/
+  timemachine mount --refresh ../latest           # Re-resolve its revision
+  timemachine mount --list
+  timemachine unmount ../preview`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			switch {
+			case list:
+				return cobra.NoArgs(cmd, args)
+			case refresh:
+				return cobra.MinimumNArgs(1)(cmd, args)
+			}
+			return cobra.MinimumNArgs(2)(cmd, args)
+		},
+		ValidArgsFunction: completeSnapshots(1, true),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if list && refresh {
+				return fmt.Errorf("--list and --refresh cannot be used together")
+			}
+			state, err := loadInitializedState()
+			if err != nil || state == nil {
+				return err
+			}
+			gitManager := core.NewGitManager(state)
+
+			switch {
+			case list:
+				return runMountList(gitManager)
+			case refresh:
+				return runMountRefresh(gitManager, args)
+			}
+			return runMount(gitManager, args[0], args[1], args[2:])
+		},
+	}
+
+	cmd.Flags().BoolVar(&list, "list", false, "List mounted snapshots")
+	cmd.Flags().BoolVar(&refresh, "refresh", false, "Re-extract mounts whose revision now points at another snapshot")
+
+	return cmd
+}
+
+// UnmountCmd creates the unmount command
+func UnmountCmd() *cobra.Command {
+	var all bool
+
+	cmd := &cobra.Command{
+		Use:   "unmount <dir>...",
+		Short: "Remove snapshots opened with mount",
+		Long: `Delete the directories of mounted snapshots and forget them. Only
+directories created by 'timemachine mount' are removed.
+
+Examples:
+  timemachine unmount ../preview
+  timemachine unmount --all`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if all {
+				return cobra.NoArgs(cmd, args)
+			}
+			return cobra.MinimumNArgs(1)(cmd, args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			state, err := loadInitializedState()
+			if err != nil || state == nil {
+				return err
+			}
+			gitManager := core.NewGitManager(state)
+
+			if all {
+				mounts, err := gitManager.Mounts()
+				if err != nil {
+					return err
+				}
+				if len(mounts) == 0 {
+					fmt.Println("📂 No snapshots are mounted.")
+					return nil
+				}
+				for _, mount := range mounts {
+					args = append(args, mount.Dir)
+				}
+			}
+			for _, dir := range args {
+				if err := gitManager.Unmount(dir); err != nil {
+					return err
+				}
+				ui.Success("✅ Unmounted %s", dir)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&all, "all", false, "Unmount every mounted snapshot")
+
+	return cmd
+}
+
+func runMount(gitManager *core.GitManager, hash, dir string, paths []string) error {
+	if len(paths) > 0 {
+		matches, err := gitManager.MatchSnapshotPaths(hash, paths)
+		if err != nil {
+			return err
+		}
+		paths = core.MatchedPathspecs(matches)
+	}
+	mount, result, err := gitManager.MountSnapshot(hash, dir, paths)
+	if err != nil {
+		return err
+	}
+
+	ui.Success("✅ Mounted %s read-only at %s (%d file(s), %s)",
+		ui.Sprint(ui.RoleHash, core.ShortHash(mount.Snapshot)), dir, result.Files, utils.FormatBytes(result.Bytes))
+	if result.Cloned > 0 {
+		ui.Info("⚡ %d unchanged file(s) cloned copy-on-write from your working directory", result.Cloned)
+	}
+	if rel, err := filepath.Rel(gitManager.State.ProjectRoot, mount.Dir); err == nil && !strings.HasPrefix(rel, "..") {
+		ui.Warning("⚠️  %s is inside the project, so the watcher will snapshot it too", dir)
+	}
+	fmt.Printf("   Remove it with: timemachine unmount %s\n", dir)
+	return nil
+}
+
+func runMountList(gitManager *core.GitManager) error {
+	mounts, err := gitManager.Mounts()
+	if err != nil {
+		return err
+	}
+	if len(mounts) == 0 {
+		fmt.Println("📂 No snapshots are mounted.")
+		return nil
+	}
+
+	for _, mount := range mounts {
+		ref := ""
+		if mount.Ref != mount.Snapshot && !strings.HasPrefix(mount.Snapshot, mount.Ref) {
+			ref = fmt.Sprintf(" (%s)", mount.Ref)
+		}
+		fmt.Printf("%s%s  %s  %d file(s), mounted %s\n",
+			ui.Sprint(ui.RoleHash, core.ShortHash(mount.Snapshot)), ref, mount.Dir, mount.Files,
+			mount.MountedAt.Local().Format("2006-01-02 15:04"))
+	}
+	return nil
+}
+
+func runMountRefresh(gitManager *core.GitManager, dirs []string) error {
+	for _, dir := range dirs {
+		mount, changed, err := gitManager.RefreshMount(dir)
+		if err != nil {
+			return err
+		}
+		if !changed {
+			fmt.Printf("📂 %s is up to date (%s)\n", dir, core.ShortHash(mount.Snapshot))
+			continue
+		}
+		ui.Success("✅ Refreshed %s to %s", dir, ui.Sprint(ui.RoleHash, core.ShortHash(mount.Snapshot)))
+	}
+	return nil
+}
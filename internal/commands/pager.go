@@ -0,0 +1,91 @@
+package commands
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/core"
+	"github.com/mattn/go-isatty"
+)
+
+// defaultPager is used when neither TIMEMACHINE_PAGER nor PAGER is set
+const defaultPager = "less"
+
+// pagerMode returns the ui.pager setting, defaulting to auto
+func pagerMode(state *core.AppState) string {
+	if state != nil && state.Config != nil && state.Config.UI.Pager != "" {
+		return state.Config.UI.Pager
+	}
+	return "auto"
+}
+
+// shouldPage decides whether output goes through a pager: never and always
+// are explicit, auto pages only when stdout is a terminal
+func shouldPage(mode string, terminal bool) bool {
+	switch mode {
+	case "never":
+		return false
+	case "always":
+		return true
+	default:
+		return terminal
+	}
+}
+
+// pagerCommand returns the pager command line from the environment. An
+// explicitly empty or "cat" pager disables paging.
+func pagerCommand() []string {
+	pager, ok := os.LookupEnv("TIMEMACHINE_PAGER")
+	if !ok {
+		pager, ok = os.LookupEnv("PAGER")
+	}
+	if !ok {
+		pager = defaultPager
+	}
+
+	fields := strings.Fields(pager)
+	if len(fields) == 0 || fields[0] == "cat" {
+		return nil
+	}
+	return fields
+}
+
+// startPager returns a writer for long output and a function that must be
+// called once writing is done. Without a pager it writes to stdout directly.
+func startPager(state *core.AppState, disabled bool) (io.Writer, func()) {
+	noop := func() {}
+
+	terminal := isatty.IsTerminal(os.Stdout.Fd()) || isatty.IsCygwinTerminal(os.Stdout.Fd())
+	if disabled || !shouldPage(pagerMode(state), terminal) {
+		return os.Stdout, noop
+	}
+
+	args := pagerCommand()
+	if args == nil {
+		return os.Stdout, noop
+	}
+
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	// Keep colors, quit on short output and don't clear the screen on exit
+	if _, ok := os.LookupEnv("LESS"); !ok {
+		cmd.Env = append(os.Environ(), "LESS=FRX")
+	}
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return os.Stdout, noop
+	}
+	if err := cmd.Start(); err != nil {
+		// Missing pager binary: fall back to plain output
+		return os.Stdout, noop
+	}
+
+	return stdin, func() {
+		stdin.Close()
+		cmd.Wait()
+	}
+}
@@ -0,0 +1,69 @@
+package commands
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestShouldPage(t *testing.T) {
+	tests := []struct {
+		mode     string
+		terminal bool
+		expected bool
+	}{
+		{"auto", true, true},
+		{"auto", false, false},
+		{"", true, true},
+		{"always", false, true},
+		{"never", true, false},
+	}
+
+	for _, tt := range tests {
+		if got := shouldPage(tt.mode, tt.terminal); got != tt.expected {
+			t.Errorf("shouldPage(%q, %v): expected %v, got %v", tt.mode, tt.terminal, tt.expected, got)
+		}
+	}
+}
+
+func TestPagerCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		tmPager  *string
+		pager    *string
+		expected []string
+	}{
+		{"default", nil, nil, []string{"less"}},
+		{"PAGER", nil, strPtr("more -s"), []string{"more", "-s"}},
+		{"TIMEMACHINE_PAGER wins", strPtr("bat"), strPtr("more"), []string{"bat"}},
+		{"empty disables", strPtr(""), nil, nil},
+		{"cat disables", nil, strPtr("cat"), nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setOrUnset(t, "TIMEMACHINE_PAGER", tt.tmPager)
+			setOrUnset(t, "PAGER", tt.pager)
+
+			if got := pagerCommand(); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func strPtr(s string) *string {
+	return &s
+}
+
+// setOrUnset sets an environment variable for the test, or unsets it when
+// value is nil
+func setOrUnset(t *testing.T, key string, value *string) {
+	t.Helper()
+	t.Setenv(key, "")
+	if value == nil {
+		os.Unsetenv(key)
+		return
+	}
+	os.Setenv(key, *value)
+}
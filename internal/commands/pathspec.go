@@ -0,0 +1,118 @@
+package commands
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/core"
+)
+
+// pathspecMagic is the pathspec magic a pattern may start with, in the long
+// form :(glob,icase)pattern. attr and the others that read more than the
+// path are left out.
+var pathspecMagic = map[string]bool{
+	"glob": true, "literal": true, "icase": true, "exclude": true, "top": true,
+}
+
+// pathFilter is the files --file and --exclude select
+type pathFilter struct {
+	pathspecs []string // Git pathspecs; none selects every file
+	label     string   // The patterns as given, for messages
+	follow    string   // The single plain path selected, whose renames git can follow
+}
+
+// newPathFilter turns --file and --exclude patterns into git pathspecs.
+// Patterns with *, ? or [ are globs, where ** spans directories; others are
+// files or directories, matched literally. Each is validated like a path.
+func newPathFilter(files, excludes []string) (pathFilter, error) {
+	var filter pathFilter
+	for _, file := range files {
+		pathspec, plain, err := parsePathspec(file, false)
+		if err != nil {
+			return pathFilter{}, err
+		}
+		filter.pathspecs = append(filter.pathspecs, pathspec)
+		if plain != "" && len(files) == 1 && len(excludes) == 0 {
+			filter.follow = plain
+		}
+	}
+	for _, exclude := range excludes {
+		pathspec, _, err := parsePathspec(exclude, true)
+		if err != nil {
+			return pathFilter{}, err
+		}
+		filter.pathspecs = append(filter.pathspecs, pathspec)
+	}
+
+	filter.label = strings.Join(files, ", ")
+	if len(excludes) > 0 {
+		if filter.label != "" {
+			filter.label += " "
+		}
+		filter.label += "excluding " + strings.Join(excludes, ", ")
+	}
+	return filter, nil
+}
+
+// parsePathspec converts one pattern, which may carry pathspec magic (:!
+// and :^ exclude, :/ is the project root), into a pathspec with its magic
+// spelled out. plain is the path when the pattern is a plain path.
+func parsePathspec(pattern string, exclude bool) (pathspec string, plain string, err error) {
+	magic := make(map[string]bool)
+	rest := pattern
+	switch {
+	case strings.HasPrefix(rest, ":("):
+		end := strings.IndexByte(rest, ')')
+		if end < 0 {
+			return "", "", core.NewValidationError("invalid pattern %q: unterminated pathspec magic", pattern)
+		}
+		for _, word := range strings.Split(rest[2:end], ",") {
+			if word = strings.TrimSpace(word); !pathspecMagic[word] {
+				return "", "", core.NewValidationError("invalid pattern %q: unsupported pathspec magic %q", pattern, word)
+			}
+			magic[word] = true
+		}
+		rest = rest[end+1:]
+	case strings.HasPrefix(rest, ":!"), strings.HasPrefix(rest, ":^"):
+		magic["exclude"] = true
+		rest = rest[2:]
+	case strings.HasPrefix(rest, ":/"):
+		magic["top"] = true
+		rest = rest[2:]
+	case strings.HasPrefix(rest, ":"):
+		return "", "", core.NewValidationError("invalid pattern %q: unsupported pathspec magic", pattern)
+	}
+	if exclude {
+		magic["exclude"] = true
+	}
+	if magic["glob"] && magic["literal"] {
+		return "", "", core.NewValidationError("invalid pattern %q: glob and literal magic contradict", pattern)
+	}
+
+	if rest == "" {
+		return "", "", core.NewValidationError("invalid pattern %q: no path", pattern)
+	}
+	cleaned, err := sanitizeFilePath(rest)
+	if err != nil {
+		return "", "", err
+	}
+	cleaned = filepath.ToSlash(cleaned)
+	if !magic["glob"] && !magic["literal"] {
+		if strings.ContainsAny(cleaned, "*?[") {
+			magic["glob"] = true
+		} else {
+			magic["literal"] = true
+		}
+	}
+	if magic["literal"] && len(magic) == 1 {
+		plain = cleaned
+	}
+
+	words := make([]string, 0, len(magic))
+	for word := range magic {
+		words = append(words, word)
+	}
+	sort.Strings(words)
+	return ":(" + strings.Join(words, ",") + ")" + cleaned, plain, nil
+}
@@ -0,0 +1,59 @@
+package commands
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParsePathspec(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		exclude  bool
+		pathspec string
+		plain    string
+	}{
+		{"main.go", false, ":(literal)main.go", "main.go"},
+		{"src/", false, ":(literal)src", "src"},
+		{"src/**", false, ":(glob)src/**", ""},
+		{"**/*_test.go", true, ":(exclude,glob)**/*_test.go", ""},
+		{":!vendor/", false, ":(exclude,literal)vendor", ""},
+		{":^*.log", false, ":(exclude,glob)*.log", ""},
+		{":/docs", false, ":(literal,top)docs", ""},
+		{":(icase)readme.md", false, ":(icase,literal)readme.md", ""},
+		{":(glob, icase)*.MD", false, ":(glob,icase)*.MD", ""},
+	}
+	for _, tt := range tests {
+		pathspec, plain, err := parsePathspec(tt.pattern, tt.exclude)
+		if err != nil || pathspec != tt.pathspec || plain != tt.plain {
+			t.Errorf("parsePathspec(%q) = %q, %q, %v, want %q, %q", tt.pattern, pathspec, plain, err, tt.pathspec, tt.plain)
+		}
+	}
+
+	for _, pattern := range []string{":(attr:x)a", ":(glob", ":(glob,literal)a", ":x", ":!", "../etc", ":/../etc", "/etc/passwd", ":(top)/etc"} {
+		if _, _, err := parsePathspec(pattern, false); err == nil {
+			t.Errorf("parsePathspec(%q) expected an error", pattern)
+		}
+	}
+}
+
+func TestNewPathFilter(t *testing.T) {
+	filter, err := newPathFilter([]string{"src/**", "go.mod"}, []string{"**/*_test.go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{":(glob)src/**", ":(literal)go.mod", ":(exclude,glob)**/*_test.go"}
+	if !reflect.DeepEqual(filter.pathspecs, expected) || filter.follow != "" {
+		t.Errorf("Unexpected filter %+v", filter)
+	}
+	if filter.label != "src/**, go.mod excluding **/*_test.go" {
+		t.Errorf("Unexpected label %q", filter.label)
+	}
+
+	// A single file's renames can be followed
+	if filter, _ := newPathFilter([]string{"main.go"}, nil); filter.follow != "main.go" {
+		t.Errorf("Expected main.go to be followed, got %+v", filter)
+	}
+	if filter, _ := newPathFilter(nil, nil); len(filter.pathspecs) != 0 || filter.label != "" {
+		t.Errorf("Expected an empty filter, got %+v", filter)
+	}
+}
@@ -0,0 +1,125 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/core"
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// PluginCmd creates the plugin command with subcommands
+func PluginCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "plugin",
+		Short: "Manage plugins adding commands to timemachine",
+		Long: `Plugins add commands, like Git's and kubectl's: an executable named
+timemachine-<name> runs as 'timemachine <name>', with the arguments after
+the command. They are looked up in the plugins directory of the user's
+configuration directory, then on PATH; built-in commands always win.
+
+A plugin is handed the project in environment variables:
+
+  TM_PROJECT_ROOT    The working tree
+  TM_GIT_DIR         The project's .git directory
+  TM_SHADOW_REPO     The shadow repository
+  TM_BRANCH          The shadow branch snapshots go to
+  TM_EXECUTABLE      The timemachine command, to call back
+  TM_VERSION         The version of timemachine
+  TM_PLUGIN_API      The plugin API version, 1
+  TM_PLUGIN_CONTEXT  A JSON file with all of the above and the arguments
+
+Run with --timemachine-plugin-info, a plugin may print a JSON description
+of itself, {"api_version": 1, "description": "...", "usage": "..."}, shown
+by 'timemachine plugin list'.
+
+Examples:
+  timemachine plugin list
+  timemachine plugin path`,
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List installed plugins",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPluginList(cmd.Root())
+		},
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:   "path",
+		Short: "Print the directory plugins are installed in",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			fmt.Println(core.PluginsDir())
+			return nil
+		},
+	})
+
+	return cmd
+}
+
+func runPluginList(root *cobra.Command) error {
+	plugins := core.DiscoverPlugins()
+	if len(plugins) == 0 {
+		fmt.Printf("No plugins. Install an executable named %s<name> in %s or on PATH.\n", core.PluginPrefix, core.PluginsDir())
+		return nil
+	}
+	for _, plugin := range plugins {
+		info, err := plugin.Describe()
+		description := info.Description
+		if description == "" {
+			description = ui.Sprint(ui.RoleMuted, "(no description)")
+		}
+		fmt.Printf("%-16s %s\n", plugin.Name, description)
+		fmt.Printf("%-16s %s\n", "", ui.Sprint(ui.RoleMuted, plugin.Path))
+		if builtin, _, err := root.Find([]string{plugin.Name}); err == nil && builtin != root {
+			ui.Line(ui.RoleWarning, "%-16s hidden by the built-in '%s' command", "", plugin.Name)
+		}
+		for _, shadowed := range plugin.Shadowed {
+			ui.Line(ui.RoleWarning, "%-16s hides %s", "", shadowed)
+		}
+		if err != nil && info.APIVersion > core.PluginAPIVersion {
+			ui.Line(ui.RoleWarning, "%-16s %v", "", err)
+		}
+	}
+	return nil
+}
+
+// AddPluginCommands adds the plugin args name to root as a command, when
+// args doesn't name a built-in one. Only that plugin is looked up, so
+// built-in commands don't pay for scanning PATH.
+func AddPluginCommands(root *cobra.Command, args []string) {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return
+	}
+	if cmd, _, err := root.Find(args[:1]); err == nil && cmd != root {
+		return
+	}
+	plugin, ok := core.FindPlugin(args[0])
+	if !ok {
+		return
+	}
+	root.AddCommand(pluginCommand(plugin))
+}
+
+// pluginCommand runs a plugin, passing every argument after its name on
+// untouched
+func pluginCommand(plugin core.Plugin) *cobra.Command {
+	return &cobra.Command{
+		Use:                plugin.Name,
+		Short:              "Plugin " + plugin.Path,
+		DisableFlagParsing: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			pc := core.NewPluginContext(cmd.Root().Version, plugin.Name, args)
+			err := plugin.Run(pc)
+			if _, ok := err.(*core.PluginExitError); ok {
+				// The plugin reported its own error
+				return core.Reported(err)
+			}
+			return err
+		},
+	}
+}
@@ -0,0 +1,96 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/core"
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/daemon"
+	"github.com/spf13/cobra"
+)
+
+const defaultPromptFormat = "⏰{count} {age}{warn}"
+
+// PromptCmd creates the prompt command
+func PromptCmd() *cobra.Command {
+	var format string
+
+	cmd := &cobra.Command{
+		Use:   "prompt",
+		Short: "Print a compact status segment for shell prompts",
+		Long: `Print a minimal Time Machine status string for embedding in shell prompts.
+
+Only the cached state written by the running watcher is read, so this is
+fast enough to run on every prompt. Nothing is printed outside of an
+initialized repository.
+
+Format placeholders:
+  {count}  snapshots taken since the main repository's HEAD commit
+  {age}    time since the last snapshot (e.g. 42s), or - if unknown
+  {warn}   ! when the watcher is not running, the last snapshot failed or
+           snapshot corruption was detected
+
+Examples:
+  # Bash
+  PS1='$(timemachine prompt) '"$PS1"
+
+  # Zsh
+  setopt PROMPT_SUBST
+  RPROMPT='$(timemachine prompt --format "tm:{count}{warn}")'`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPrompt(format)
+		},
+	}
+
+	cmd.Flags().StringVar(&format, "format", defaultPromptFormat, "Output format using {count}, {age} and {warn} placeholders")
+
+	return cmd
+}
+
+func runPrompt(format string) error {
+	// Skip configuration loading: this runs on every shell prompt
+	state, err := core.NewLightAppState()
+	if err != nil || !state.IsInitialized {
+		return nil
+	}
+
+	fmt.Println(renderPromptSegment(format, state, time.Now()))
+	return nil
+}
+
+// renderPromptSegment expands the prompt format from the cached watcher state
+func renderPromptSegment(format string, state *core.AppState, now time.Time) string {
+	count := "0"
+	age := "-"
+	warn := "!"
+
+	if watcherState, err := daemon.NewManager(state).ReadState(); err == nil {
+		count = strconv.Itoa(watcherState.SnapshotsSinceHead)
+
+		// A new main repo commit resets the counter until the watcher catches up
+		if _, head, err := core.ReadHead(state.GitDir); err == nil && head != watcherState.MainHead {
+			count = "0"
+		}
+
+		if !watcherState.LastSnapshotAt.IsZero() {
+			seconds := int(now.Sub(watcherState.LastSnapshotAt).Seconds())
+			if seconds < 0 {
+				seconds = 0
+			}
+			age = strconv.Itoa(seconds) + "s"
+		}
+
+		if watcherState.LastError == "" && watcherState.WatcherAlive() {
+			warn = ""
+		}
+	}
+	if report, err := core.NewGitManager(state).ReadIntegrityReport(); err == nil && report.Corrupt() {
+		warn = "!"
+	}
+
+	replacer := strings.NewReplacer("{count}", count, "{age}", age, "{warn}", warn)
+	return replacer.Replace(format)
+}
@@ -0,0 +1,32 @@
+package commands
+
+import (
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/core"
+	"github.com/spf13/cobra"
+)
+
+// RecordCommitCmd creates the record-commit command, run by the hooks
+// 'timemachine init --commit-hooks' installs
+func RecordCommitCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "record-commit",
+		Short: "Record the project's HEAD commit in the next snapshot",
+		Long: `Record the commit checked out in the project, so the next snapshot's
+message names it and 'timemachine list --since-commit' can find it.
+
+Run by the post-commit and post-merge hooks 'timemachine init --commit-hooks'
+installs. Nothing is printed, and nothing is done outside of an initialized
+repository.`,
+		Args:   cobra.NoArgs,
+		Hidden: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Skip configuration loading: this runs on every commit
+			state, err := core.NewLightAppState()
+			if err != nil || !state.IsInitialized {
+				return nil
+			}
+			_, err = core.NewGitManager(state).RecordProjectCommit()
+			return err
+		},
+	}
+}
@@ -0,0 +1,236 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/core"
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// RemoteCmd creates the remote command with subcommands
+func RemoteCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "remote",
+		Short: "Manage remote backups of the shadow repository",
+		Long: `Back snapshots up to a private Git remote, so losing a machine doesn't lose
+its session history. Each machine pushes its shadow branches to
+<machine>/<branch> on the remote and never overwrites another's.
+
+Use a private repository: snapshots hold every file of the project.
+
+Examples:
+  timemachine remote add git@github.com:me/project-snapshots.git
+  timemachine remote add backup /mnt/nas/project-snapshots.git
+  timemachine remote list
+  timemachine push                 # Push every shadow branch
+  timemachine pull                 # Fetch the snapshots of every machine`,
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "add [name] <url>",
+		Short: "Add a remote to push snapshots to (named remote.name, origin, by default)",
+		Args:  cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name, url := "", args[0]
+			if len(args) == 2 {
+				name, url = args[0], args[1]
+			}
+			return runRemoteAdd(name, url)
+		},
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List remotes",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runRemoteList()
+		},
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:     "remove [name]",
+		Aliases: []string{"rm"},
+		Short:   "Remove a remote and the branches pulled from it, leaving the remote itself untouched",
+		Args:    cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runRemoteRemove(firstArg(args))
+		},
+	})
+
+	return cmd
+}
+
+// PushCmd creates the push command
+func PushCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "push [remote]",
+		Short: "Push snapshots to a remote backup",
+		Long: `Push every shadow branch to <machine>/<branch> on the remote (remote.name,
+origin, by default). The machine is remote.machine, or the hostname.
+
+Pushes only ever append: nothing is forced, and when retention, compact or
+clean rewrote a branch since the last push, the new history is joined to
+the remote's with a merge so no snapshot on the remote is lost. Set
+remote.push_interval to push from the watcher on a schedule.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPush(firstArg(args))
+		},
+	}
+}
+
+// PullCmd creates the pull command
+func PullCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "pull [remote]",
+		Short: "Fetch the snapshots of every machine from a remote backup",
+		Long: `Fetch the shadow branches every machine pushed to the remote (remote.name,
+origin, by default). They are kept apart from the local branches, as
+<remote>/<machine>/<branch>, and never merged into them:
+
+  timemachine pull
+  timemachine list --branch origin/old-laptop/main
+  timemachine restore <hash>
+
+Branches deleted on the remote are kept.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPull(firstArg(args))
+		},
+	}
+}
+
+// firstArg returns the optional first argument, "" when absent
+func firstArg(args []string) string {
+	if len(args) == 0 {
+		return ""
+	}
+	return args[0]
+}
+
+// remoteName returns the remote a command works on: the one named, else
+// remote.name
+func remoteName(gitManager *core.GitManager, name string) string {
+	if name != "" {
+		return name
+	}
+	if name = gitManager.State.Config.Remote.Name; name != "" {
+		return name
+	}
+	return core.DefaultRemote
+}
+
+// remoteGitManager loads the repository for the remote commands
+func remoteGitManager() (*core.GitManager, error) {
+	state, err := loadInitializedState()
+	if err != nil || state == nil {
+		return nil, err
+	}
+	return core.NewGitManager(state), nil
+}
+
+func runRemoteAdd(name, url string) error {
+	gitManager, err := remoteGitManager()
+	if err != nil || gitManager == nil {
+		return err
+	}
+	name = remoteName(gitManager, name)
+	if err := gitManager.AddRemote(name, url); err != nil {
+		return err
+	}
+	ui.Success("✅ Added remote %s: %s", name, url)
+	fmt.Println("   Run 'timemachine push' to back up your snapshots")
+	return nil
+}
+
+func runRemoteList() error {
+	gitManager, err := remoteGitManager()
+	if err != nil || gitManager == nil {
+		return err
+	}
+	remotes, err := gitManager.Remotes()
+	if err != nil {
+		return err
+	}
+	if len(remotes) == 0 {
+		fmt.Println("No remotes. Add one with 'timemachine remote add <url>'.")
+		return nil
+	}
+	for _, remote := range remotes {
+		fmt.Printf("%-12s %s\n", remote.Name, remote.URL)
+	}
+	return nil
+}
+
+func runRemoteRemove(name string) error {
+	gitManager, err := remoteGitManager()
+	if err != nil || gitManager == nil {
+		return err
+	}
+	name = remoteName(gitManager, name)
+	if err := gitManager.RemoveRemote(name); err != nil {
+		return err
+	}
+	ui.Success("✅ Removed remote %s", name)
+	return nil
+}
+
+func runPush(remote string) error {
+	gitManager, err := remoteGitManager()
+	if err != nil || gitManager == nil {
+		return err
+	}
+	remote = remoteName(gitManager, remote)
+
+	results, err := gitManager.PushSnapshots(remote)
+	if err != nil {
+		return err
+	}
+	pushed := 0
+	for _, result := range results {
+		switch result.Status {
+		case core.PushUpToDate:
+			fmt.Printf("  = %-30s %s\n", result.Ref, ui.Sprint(ui.RoleMuted, "up to date"))
+			continue
+		case core.PushMerged:
+			ui.Line(ui.RoleModified, "  ~ %-30s merged with the remote's earlier snapshots", result.Ref)
+		case core.PushCreated:
+			ui.Line(ui.RoleAdded, "  + %-30s new", result.Ref)
+		default:
+			ui.Line(ui.RoleAdded, "  + %-30s updated", result.Ref)
+		}
+		pushed++
+	}
+	if pushed == 0 {
+		fmt.Printf("Everything is already on %s.\n", remote)
+		return nil
+	}
+	ui.Success("✅ Pushed %d branch(es) to %s", pushed, remote)
+	return nil
+}
+
+func runPull(remote string) error {
+	gitManager, err := remoteGitManager()
+	if err != nil || gitManager == nil {
+		return err
+	}
+	remote = remoteName(gitManager, remote)
+
+	branches, err := gitManager.PullSnapshots(remote)
+	if err != nil {
+		return err
+	}
+	if len(branches) == 0 {
+		fmt.Printf("%s has no snapshots yet.\n", remote)
+		return nil
+	}
+	ui.Heading("☁️  Snapshots on %s:", remote)
+	fmt.Println()
+	for _, branch := range branches {
+		fmt.Printf("  %-40s %6d snapshots  last %s\n", branch.Ref, branch.Snapshots, branch.LastSnapshot.Format("2006-01-02 15:04"))
+	}
+	fmt.Println()
+	fmt.Println("List one with 'timemachine list --branch <remote>/<machine>/<branch>'")
+	return nil
+}
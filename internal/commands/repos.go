@@ -0,0 +1,258 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/core"
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/daemon"
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/ui"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// ReposCmd creates the repos command with subcommands
+func ReposCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "repos",
+		Short: "Manage the watchers of several repositories together",
+		Long: `Keep a list of repositories and start or stop all their watchers at once.
+
+Each repository keeps its own shadow repository, configuration and
+background watcher; the list is stored in your user configuration
+directory.
+
+Examples:
+  timemachine repos add .                       # Register the current repository
+  timemachine repos add ~/src/api ~/src/web
+  timemachine repos list                        # Registered repositories and their watchers
+  timemachine repos start-all
+  timemachine repos stop-all`,
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "add <path>...",
+		Short: "Register repositories",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runReposAdd(args)
+		},
+	})
+
+	cmd.AddCommand(&cobra.Command{
+		Use:     "remove <path>...",
+		Aliases: []string{"rm"},
+		Short:   "Unregister repositories (their watchers keep running)",
+		Args:    cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runReposRemove(args)
+		},
+	})
+
+	cmd.AddCommand(&cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List registered repositories and whether they are watched",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runReposList()
+		},
+	})
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "start-all",
+		Short: "Start a background watcher in every registered repository",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runReposStartAll()
+		},
+	})
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "stop-all",
+		Short: "Stop the background watcher in every registered repository",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runReposStopAll()
+		},
+	})
+
+	return cmd
+}
+
+func runReposAdd(paths []string) error {
+	registry, err := daemon.LoadRegistry()
+	if err != nil {
+		return err
+	}
+
+	added := 0
+	for _, path := range paths {
+		state, err := core.NewLightAppStateAt(path)
+		if err != nil {
+			color.Red("❌ %s: %v", path, err)
+			continue
+		}
+		if !state.IsInitialized {
+			ui.Error("%s", ui.T(ui.MsgRepoNotInitialized, state.ProjectRoot))
+			fmt.Println("   " + ui.T(ui.MsgRunInitThere))
+			continue
+		}
+		if !registry.Add(state.ProjectRoot) {
+			color.Yellow("⚠️  %s: already registered", state.ProjectRoot)
+			continue
+		}
+		color.Green("✅ %s: registered", state.ProjectRoot)
+		added++
+	}
+
+	if added == 0 {
+		return nil
+	}
+	return registry.Save()
+}
+
+func runReposRemove(paths []string) error {
+	registry, err := daemon.LoadRegistry()
+	if err != nil {
+		return err
+	}
+
+	removed := 0
+	for _, path := range paths {
+		// A repository that was deleted can still be unregistered by path
+		root := path
+		if state, err := core.NewLightAppStateAt(path); err == nil {
+			root = state.ProjectRoot
+		}
+		if !registry.Remove(root) {
+			color.Yellow("⚠️  %s: not registered", root)
+			continue
+		}
+		color.Green("✅ %s: unregistered", root)
+		removed++
+	}
+
+	if removed == 0 {
+		return nil
+	}
+	return registry.Save()
+}
+
+// registeredRepos loads the registry, reporting when it is empty
+func registeredRepos() ([]daemon.RegisteredRepo, error) {
+	registry, err := daemon.LoadRegistry()
+	if err != nil {
+		return nil, err
+	}
+	if len(registry.Repos) == 0 {
+		color.Yellow("📭 No repositories registered")
+		fmt.Println("   Run 'timemachine repos add <path>' to register one.")
+	}
+	return registry.Repos, nil
+}
+
+// registeredState resolves a registered repository, printing why it can't
+// be watched
+func registeredState(repo daemon.RegisteredRepo) *core.AppState {
+	if _, err := os.Stat(repo.Path); err != nil {
+		color.Red("❌ %s: repository is missing", repo.Path)
+		fmt.Println("   Run 'timemachine repos remove' to unregister it.")
+		return nil
+	}
+	state, err := core.NewLightAppStateAt(repo.Path)
+	if err != nil {
+		color.Red("❌ %s: %v", repo.Path, err)
+		return nil
+	}
+	if !state.IsInitialized {
+		ui.Error("%s", ui.T(ui.MsgRepoNotInitialized, repo.Path))
+		return nil
+	}
+	return state
+}
+
+func runReposList() error {
+	repos, err := registeredRepos()
+	if err != nil || len(repos) == 0 {
+		return err
+	}
+
+	for _, repo := range repos {
+		state := registeredState(repo)
+		if state == nil {
+			continue
+		}
+
+		watcher := collectWatcherStatus(state)
+		if watcher.Running {
+			color.Green("✅ %s", repo.Path)
+			fmt.Printf("   Watcher: %s (PID %d)\n", watcher.Mode, watcher.PID)
+		} else {
+			color.Yellow("⚠️  %s", repo.Path)
+			fmt.Println("   Watcher: not running")
+		}
+
+		snapshots, err := core.NewGitManager(state).ListSnapshots(1, "")
+		if err == nil && len(snapshots) > 0 {
+			fmt.Printf("   Last snapshot: %s (%s)\n", snapshots[0].Time, snapshots[0].Timestamp.Local().Format("2006-01-02 15:04:05"))
+		} else {
+			fmt.Println("   Last snapshot: none")
+		}
+	}
+	return nil
+}
+
+func runReposStartAll() error {
+	repos, err := registeredRepos()
+	if err != nil || len(repos) == 0 {
+		return err
+	}
+
+	started := 0
+	for _, repo := range repos {
+		state := registeredState(repo)
+		if state == nil {
+			continue
+		}
+
+		if watcher := collectWatcherStatus(state); watcher.Running {
+			color.Yellow("⚠️  %s: already watched (%s, PID %d)", repo.Path, watcher.Mode, watcher.PID)
+			continue
+		}
+		pid, err := daemon.NewManager(state).Start()
+		if err != nil {
+			color.Red("❌ %s: %v", repo.Path, err)
+			continue
+		}
+		color.Green("✅ %s: started (PID %d)", repo.Path, pid)
+		started++
+	}
+
+	fmt.Printf("\n🚀 Started %d of %d watchers\n", started, len(repos))
+	return nil
+}
+
+func runReposStopAll() error {
+	repos, err := registeredRepos()
+	if err != nil || len(repos) == 0 {
+		return err
+	}
+
+	for _, repo := range repos {
+		state := registeredState(repo)
+		if state == nil {
+			continue
+		}
+
+		pid, err := daemon.NewManager(state).Stop()
+		if errors.Is(err, daemon.ErrNotRunning) {
+			fmt.Printf("   %s: not running\n", repo.Path)
+			continue
+		}
+		if err != nil {
+			color.Red("❌ %s: %v", repo.Path, err)
+			continue
+		}
+		color.Green("✅ %s: stopped (PID %d)", repo.Path, pid)
+	}
+	return nil
+}
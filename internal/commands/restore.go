@@ -1,47 +1,237 @@
 package commands
 
 import (
-	"bufio"
+	"errors"
 	"fmt"
-	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 	"github.com/deepakkumarnarayana/timemachine-cli/internal/core"
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/ui"
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/utils"
 )
 
 // RestoreCmd creates the restore command
 func RestoreCmd() *cobra.Command {
 	var (
-		files []string
-		force bool
+		files        []string
+		force        bool
+		interactive  bool
+		resume       bool
+		abort        bool
+		to           string
+		list         bool
+		stash        bool
+		patch        string
+		listBackups  bool
+		applyBackup  string
+		sessionStart string
+		ago          int
 	)
 
 	cmd := &cobra.Command{
-		Use:   "restore <hash>",
+		Use:   "restore [hash] [paths...]",
 		Short: "Restore files from a snapshot",
 		Long: `Restore files from a specific snapshot to the working directory.
 
-By default, this restores all files from the snapshot. You can specify
-specific files to restore using the --files flag.
+By default, this restores all files from the snapshot. You can limit the
+restore to files, directories or glob patterns, given after the hash or
+with --file. Patterns are matched against the snapshot's files: *, ? and
+[a-z] match within a path segment and ** spans directories, so quote them
+to keep your shell from expanding them:
+
+  timemachine restore abc123 'src/**/*.ts' docs/
+
+Use --list to show what the paths match without restoring anything.
+
+The snapshot is a full or short hash, a tag, 'latest', or a revision such
+as latest~3; -n 3 names the snapshot three before the latest, and every
+argument is then a path:
+
+  timemachine restore -n 2 src/
+
+Use --patch to restore parts of one file: each hunk of the difference
+between the snapshot and your working copy is shown, and you choose which to
+bring back, as with 'git checkout -p':
+
+  timemachine restore abc123 --patch src/server.go
+
+Use --interactive to browse snapshots, preview what each would change in
+your working directory, and tick the individual files to restore.
+
+Restores are journaled: a pre-restore snapshot is taken and files are
+restored in batches. If a restore is interrupted (Ctrl+C, crash), run
+'timemachine restore --resume' to finish it or '--abort' to roll back.
+
+Files with staged or unstaged changes in your Git repository are not
+overwritten, in full or by --patch, without --force. Use --stash to move those changes into a Git
+stash first ('git stash pop' brings them back).
+
+Every restore that overwrites files saves a reverse patch of what it replaced
+in .git/timemachine_snapshots/backups. --list-backups shows them, and
+--apply-backup <id> applies one to bring the replaced contents back:
+
+  timemachine restore --list-backups
+  timemachine restore --apply-backup restore-20250114-093012
+
+Use --session-start to roll back a whole session started with 'timemachine
+session start', restoring the state from before it began:
+
+  timemachine restore --session-start s3
+
+Use --to to write the snapshot into a separate, empty directory instead,
+leaving your working directory untouched. Files you haven't changed since
+the snapshot are cloned copy-on-write on filesystems that support it
+(APFS, Btrfs, XFS), which makes even large snapshots near-instant.
 
 IMPORTANT: This only affects the working directory, not the Git staging area.
 Your Git history and staged changes are preserved.`,
-		Args: cobra.ExactArgs(1),
+		Args: cobra.ArbitraryArgs,
+		ValidArgsFunction: completeSnapshots(1, true),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runRestore(args[0], files, force)
+			if sessionStart != "" && cmd.Flags().Changed("ago") {
+				return core.NewValidationError("--session-start and -n both name the snapshot to restore")
+			}
+			// Every argument is a path: -n names the snapshot
+			args, err := snapshotsAgoArgs(cmd, ago, args)
+			if err != nil {
+				return err
+			}
+			if sessionStart != "" {
+				// Every argument is a path: the session names the snapshot
+				if patch != "" || listBackups || applyBackup != "" || resume || abort || interactive {
+					return core.NewValidationError("--session-start can't be combined with --patch, --interactive, --resume, --abort or the backup flags")
+				}
+				hash, err := sessionStartSnapshot(sessionStart)
+				if err != nil || hash == "" {
+					return err
+				}
+				args = append([]string{hash}, args...)
+			}
+			if len(args) > 1 {
+				files = append(append([]string{}, args[1:]...), files...)
+				args = args[:1]
+			}
+			if stash && (list || resume || abort || interactive || to != "") {
+				return fmt.Errorf("--stash only applies to restoring into the working directory")
+			}
+			if list && (resume || abort || interactive || to != "") {
+				return fmt.Errorf("--list cannot be combined with --resume, --abort, --interactive or --to")
+			}
+			if listBackups || applyBackup != "" {
+				if listBackups && applyBackup != "" {
+					return core.NewValidationError("--list-backups and --apply-backup cannot be used together")
+				}
+				if len(args) > 0 || len(files) > 0 || force || list || interactive || stash || to != "" || resume || abort {
+					return core.NewValidationError("--list-backups and --apply-backup take no hash or other restore flags")
+				}
+				state, err := loadInitializedState()
+				if err != nil || state == nil {
+					return err
+				}
+				if listBackups {
+					return runListBackups(state)
+				}
+				return runApplyBackup(state, applyBackup)
+			}
+			if patch != "" {
+				if len(files) > 0 || list || resume || abort || interactive || to != "" {
+					return fmt.Errorf("--patch restores hunks of one file and takes only a hash, --force or --stash")
+				}
+				if len(args) == 0 {
+					return fmt.Errorf("requires a snapshot hash to restore hunks from")
+				}
+				return runRestorePatch(args[0], patch, force, stash)
+			}
+			if resume || abort {
+				if resume && abort {
+					return fmt.Errorf("--resume and --abort cannot be used together")
+				}
+				if len(args) > 0 || len(files) > 0 || interactive || to != "" {
+					return fmt.Errorf("--resume and --abort do not take a hash, --file, --interactive or --to")
+				}
+				state, err := loadInitializedState()
+				if err != nil || state == nil {
+					return err
+				}
+				return runRestoreJournal(state, resume)
+			}
+			if interactive {
+				if len(args) > 0 || len(files) > 0 || to != "" {
+					return fmt.Errorf("--interactive cannot be combined with a hash, --file or --to")
+				}
+				state, err := loadInitializedState()
+				if err != nil || state == nil {
+					return err
+				}
+				return runInteractiveRestore(state, force)
+			}
+			if len(args) == 0 {
+				return fmt.Errorf("requires a snapshot hash or -n (or use --interactive)")
+			}
+			if to != "" {
+				return runRestoreTo(args[0], files, to)
+			}
+			return runRestore(args[0], files, force, list, stash)
 		},
 	}
 
 	// Add flags
-	cmd.Flags().StringSliceVar(&files, "files", []string{}, "Specific files to restore (comma-separated)")
+	cmd.Flags().StringSliceVarP(&files, "file", "f", []string{}, "Files, directories or glob patterns to restore (comma-separated)")
 	cmd.Flags().BoolVar(&force, "force", false, "Skip confirmation prompt")
+	cmd.Flags().BoolVarP(&interactive, "interactive", "i", false, "Browse snapshots and pick files to restore")
+	cmd.Flags().BoolVar(&resume, "resume", false, "Finish an interrupted restore")
+	cmd.Flags().BoolVar(&abort, "abort", false, "Roll back an interrupted restore")
+	cmd.Flags().StringVar(&to, "to", "", "Write the snapshot into this empty directory instead of the working directory")
+	cmd.Flags().BoolVar(&list, "list", false, "Show the snapshot files the paths match without restoring")
+	cmd.Flags().StringVarP(&patch, "patch", "p", "", "Pick the hunks of this file to restore")
+	cmd.Flags().BoolVar(&stash, "stash", false, "Stash uncommitted Git changes to the restored files first")
+	cmd.Flags().BoolVar(&listBackups, "list-backups", false, "List the reverse patches saved by past restores")
+	cmd.Flags().StringVar(&applyBackup, "apply-backup", "", "Apply the reverse patch of a past restore, bringing back what it replaced")
+	cmd.Flags().StringVar(&sessionStart, "session-start", "", "Restore the state from before this named session began")
+	addSnapshotsAgoFlag(cmd, &ago)
+
+	// Legacy spellings
+	aliasFlag(cmd, "files", "file")
 
 	return cmd
 }
 
-func runRestore(hash string, files []string, force bool) error {
+// sessionStartSnapshot returns the snapshot taken when a named session
+// started
+func sessionStartSnapshot(id string) (string, error) {
+	state, err := loadInitializedState()
+	if err != nil || state == nil {
+		return "", err
+	}
+	session, err := core.NewGitManager(state).FindNamedSession(id)
+	if err != nil {
+		return "", err
+	}
+	if session.Active() {
+		ui.Warning("⚠️  Session %s is still running; end it with 'timemachine session end'", session.ID)
+	}
+	return session.StartSnapshot, nil
+}
+
+// loadInitializedState returns the app state, or core.ErrNotInitialized after
+// printing the usual hint when Time Machine is not initialized
+func loadInitializedState() (*core.AppState, error) {
+	state, err := core.NewAppState()
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize app state: %w", err)
+	}
+	if !state.IsInitialized {
+		ui.Error("%s", ui.T(ui.MsgNotInitialized))
+		fmt.Println(ui.T(ui.MsgRunInit))
+		return nil, core.Reported(core.ErrNotInitialized)
+	}
+	return state, nil
+}
+
+func runRestore(hash string, files []string, force, list, stash bool) error {
 	// Create application state
 	state, err := core.NewAppState()
 	if err != nil {
@@ -50,21 +240,26 @@ func runRestore(hash string, files []string, force bool) error {
 
 	// Check if initialized
 	if !state.IsInitialized {
-		color.Red("❌ Time Machine is not initialized!")
-		fmt.Println("Run 'timemachine init' to get started.")
-		return nil
+		ui.Error("%s", ui.T(ui.MsgNotInitialized))
+		fmt.Println(ui.T(ui.MsgRunInit))
+		return core.Reported(core.ErrNotInitialized)
 	}
 
 	// Create Git manager
 	gitManager := core.NewGitManager(state)
 
-	// Verify the hash exists
-	_, err = gitManager.RunCommand("rev-parse", "--verify", hash+"^{commit}")
+	// Resolve short hashes, tags and relative revisions
+	ref := hash
+	hash, err = gitManager.ResolveSnapshot(ref)
 	if err != nil {
-		color.Red("❌ Snapshot not found!")
-		fmt.Printf("   Hash '%s' does not exist.\n", hash)
-		fmt.Println("   Use 'timemachine list' to see available snapshots.")
-		return nil
+		var notFound *core.NotFoundError
+		if errors.As(err, &notFound) {
+			color.Red("❌ Snapshot not found!")
+			fmt.Printf("   '%s' does not name a snapshot.\n", ref)
+			fmt.Println("   Use 'timemachine list' to see available snapshots.")
+			return core.Reported(err)
+		}
+		return err
 	}
 
 	// Get snapshot details for confirmation
@@ -75,7 +270,7 @@ func runRestore(hash string, files []string, force bool) error {
 
 	var targetSnapshot *core.Snapshot
 	for _, snapshot := range snapshots {
-		if strings.HasPrefix(snapshot.Hash, hash) || snapshot.Hash == hash {
+		if snapshot.Hash == hash {
 			targetSnapshot = &snapshot
 			break
 		}
@@ -86,6 +281,23 @@ func runRestore(hash string, files []string, force bool) error {
 		return nil
 	}
 
+	var matches []core.PathMatch
+	if len(files) > 0 {
+		matches, err = gitManager.MatchSnapshotPaths(targetSnapshot.Hash, files)
+		if err != nil {
+			return err
+		}
+	}
+	if list {
+		showPathMatches(targetSnapshot.Hash, matches)
+		return nil
+	}
+	pathspecs := core.MatchedPathspecs(matches)
+	conflicts, err := gitManager.RestoreConflicts(targetSnapshot.Hash, pathspecs)
+	if err != nil {
+		return err
+	}
+
 	// Show what will be restored
 	fmt.Println("📸 Restore Snapshot")
 	fmt.Println()
@@ -99,12 +311,32 @@ func runRestore(hash string, files []string, force bool) error {
 		fmt.Println("   Any uncommitted changes in your working directory will be lost!")
 	} else {
 		color.Yellow("⚠️  This will restore the following files:")
-		for _, file := range files {
+		matched := core.MatchedFiles(matches)
+		for i, file := range matched {
+			if i == maxListedFiles {
+				fmt.Printf("   … and %d more (see --list)\n", len(matched)-i)
+				break
+			}
 			fmt.Printf("   • %s\n", file)
 		}
+		for _, match := range matches {
+			if !match.Glob && len(match.Files) == 0 {
+				fmt.Printf("   • %s (not in the snapshot: will be removed)\n", match.Pattern)
+			}
+		}
 		fmt.Println("   Any uncommitted changes to these files will be lost!")
 	}
 
+	if len(conflicts) > 0 {
+		fmt.Println()
+		showRestoreConflicts(conflicts)
+		if !force && !stash {
+			fmt.Println("   Commit them, rerun with --stash to stash them first, or use --force to")
+			fmt.Println("   overwrite them (the pre-restore snapshot keeps their working copies).")
+			return fmt.Errorf("restore would overwrite uncommitted changes")
+		}
+	}
+
 	fmt.Println()
 	color.Cyan("ℹ️  Note: This only affects your working directory.")
 	fmt.Println("   Your Git staging area and commit history remain unchanged.")
@@ -112,16 +344,11 @@ func runRestore(hash string, files []string, force bool) error {
 	// Ask for confirmation unless --force is used
 	if !force {
 		fmt.Println()
-		fmt.Print("Do you want to continue? (y/N): ")
-		
-		reader := bufio.NewReader(os.Stdin)
-		response, err := reader.ReadString('\n')
+		confirmed, err := ui.Confirm("Do you want to continue?")
 		if err != nil {
-			return fmt.Errorf("failed to read confirmation: %w", err)
+			return err
 		}
-		
-		response = strings.TrimSpace(strings.ToLower(response))
-		if response != "y" && response != "yes" {
+		if !confirmed {
 			fmt.Println("Restore cancelled.")
 			return nil
 		}
@@ -129,15 +356,26 @@ func runRestore(hash string, files []string, force bool) error {
 
 	// Perform the restore
 	fmt.Println()
+	if stash && len(conflicts) > 0 {
+		if err := gitManager.StashProjectChanges(conflicts, "timemachine: before restoring "+core.ShortHash(targetSnapshot.Hash)); err != nil {
+			return err
+		}
+		fmt.Printf("📦 Stashed uncommitted changes to %d file(s); 'git stash pop' brings them back\n", len(conflicts))
+	}
 	fmt.Print("🔄 Restoring files... ")
 	
-	err = gitManager.RestoreSnapshot(targetSnapshot.Hash, files)
+	journal, err := journaledRestore(gitManager, targetSnapshot.Hash, pathspecs)
 	if err != nil {
-		color.Red("❌")
-		return fmt.Errorf("failed to restore snapshot: %w", err)
+		return err
 	}
 	
 	color.Green("✅")
+	if len(journal.Files) == 0 {
+		color.Green("✨ Working directory already matches this snapshot")
+		return nil
+	}
+	fmt.Printf("💾 Pre-restore snapshot: %s (use it to undo this restore)\n", journal.Backup[:8])
+	printBackupPatch(journal.BackupPatch)
 	fmt.Println()
 	
 	if len(files) == 0 {
@@ -152,5 +390,78 @@ func runRestore(hash string, files []string, force bool) error {
 	fmt.Println("   • Use 'git add' and 'git commit' if you want to save these changes")
 	fmt.Println("   • Use 'git status' to see what changed")
 
+	runPostRestoreHooks(gitManager, journal)
 	return nil
-}
\ No newline at end of file
+}
+// runRestoreTo writes a snapshot into a separate directory; the working
+// directory is not touched, so no confirmation or journal is needed
+func runRestoreTo(hash string, files []string, dest string) error {
+	state, err := loadInitializedState()
+	if err != nil || state == nil {
+		return err
+	}
+
+	gitManager := core.NewGitManager(state)
+	if hash, err = gitManager.ResolveSnapshot(hash); err != nil {
+		return err
+	}
+	if len(files) > 0 {
+		matches, err := gitManager.MatchSnapshotPaths(hash, files)
+		if err != nil {
+			return err
+		}
+		files = core.MatchedPathspecs(matches)
+	}
+	result, err := gitManager.MaterializeSnapshot(hash, dest, files)
+	if err != nil {
+		return err
+	}
+
+	ui.Success("✅ Restored %d file(s) (%s) from %s to %s",
+		result.Files, utils.FormatBytes(result.Bytes), ui.Sprint(ui.RoleHash, core.ShortHash(hash)), dest)
+	if result.Cloned > 0 {
+		ui.Info("⚡ %d unchanged file(s) cloned copy-on-write from your working directory", result.Cloned)
+	}
+
+	if abs, err := filepath.Abs(dest); err == nil {
+		if rel, err := filepath.Rel(state.ProjectRoot, abs); err == nil && !strings.HasPrefix(rel, "..") {
+			ui.Warning("⚠️  %s is inside the project, so the watcher will snapshot it too", dest)
+		}
+	}
+
+	return nil
+}
+
+// showRestoreConflicts lists restored files with uncommitted Git changes
+func showRestoreConflicts(conflicts []core.ProjectChange) {
+	color.Yellow("⚠️  %d of these file(s) have uncommitted changes in your Git repository:", len(conflicts))
+	for i, conflict := range conflicts {
+		if i == maxListedFiles {
+			fmt.Printf("   … and %d more\n", len(conflicts)-i)
+			break
+		}
+		fmt.Printf("   • %s (%s)\n", conflict.Path, conflict.Describe())
+	}
+}
+
+// maxListedFiles caps the files listed in a restore confirmation
+const maxListedFiles = 20
+
+// showPathMatches prints the snapshot files each restore path matched
+func showPathMatches(hash string, matches []core.PathMatch) {
+	if len(matches) == 0 {
+		ui.Info("All files in %s would be restored", core.ShortHash(hash))
+		return
+	}
+	for _, match := range matches {
+		if len(match.Files) == 0 {
+			ui.Warning("%s: not in the snapshot, restoring removes it", match.Pattern)
+			continue
+		}
+		ui.Info("%s: %d file(s)", match.Pattern, len(match.Files))
+		for _, file := range match.Files {
+			fmt.Printf("   %s\n", file)
+		}
+	}
+	fmt.Printf("\n%d file(s) in %s match\n", len(core.MatchedFiles(matches)), core.ShortHash(hash))
+}
@@ -0,0 +1,52 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/core"
+	"github.com/fatih/color"
+)
+
+// printBackupPatch points to the reverse patch a restore saved
+func printBackupPatch(id string) {
+	if id != "" {
+		fmt.Printf("🩹 Reverse patch: %s (timemachine restore --apply-backup %s)\n", id, id)
+	}
+}
+
+// runListBackups handles --list-backups
+func runListBackups(state *core.AppState) error {
+	backups, err := core.NewGitManager(state).ListRestoreBackups()
+	if err != nil {
+		return err
+	}
+	if len(backups) == 0 {
+		color.Yellow("📭 No restore backups yet")
+		fmt.Println("   Every restore that overwrites files saves one.")
+		return nil
+	}
+
+	fmt.Printf("🩹 Restore backups (%d):\n\n", len(backups))
+	fmt.Printf("%-28s  %-19s  %-8s  %-8s  %s\n", "ID", "TIME", "RESTORED", "BEFORE", "FILES")
+	for _, backup := range backups {
+		fmt.Printf("%-28s  %-19s  %-8s  %-8s  %d\n", backup.ID, backup.Time.Local().Format("2006-01-02 15:04:05"),
+			core.ShortHash(backup.Source), core.ShortHash(backup.Backup), backup.Files)
+	}
+	fmt.Println()
+	fmt.Println("Apply one with 'timemachine restore --apply-backup <id>'; each is a patch")
+	fmt.Println("in .git/timemachine_snapshots/backups that 'git apply' also accepts.")
+	return nil
+}
+
+// runApplyBackup handles --apply-backup
+func runApplyBackup(state *core.AppState, id string) error {
+	gitManager := core.NewGitManager(state)
+	backup, snapshot, err := gitManager.ApplyRestoreBackup(id)
+	if err != nil {
+		return err
+	}
+
+	color.Green("✨ Applied %s: the %d file(s) replaced by restoring %s are back", backup.ID, backup.Files, core.ShortHash(backup.Source))
+	fmt.Printf("💾 Pre-restore snapshot: %s (use it to undo this)\n", core.ShortHash(snapshot))
+	return nil
+}
@@ -0,0 +1,348 @@
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/core"
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/ui"
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/utils"
+	"github.com/fatih/color"
+)
+
+// interactivePageSize is the number of snapshots shown per page
+const interactivePageSize = 10
+
+// interactiveRestore is a line-based snapshot browser: pick a snapshot,
+// preview what restoring it would change, tick files and confirm.
+type interactiveRestore struct {
+	gitManager *core.GitManager
+	reader     *bufio.Reader
+	snapshots  []core.Snapshot
+	page       int
+}
+
+func runInteractiveRestore(state *core.AppState, force bool) error {
+	if err := ui.RequireInput("interactive restore"); err != nil {
+		return err
+	}
+	gitManager := core.NewGitManager(state)
+
+	snapshots, err := gitManager.ListSnapshots(0, "")
+	if err != nil {
+		return fmt.Errorf("failed to list snapshots: %w", err)
+	}
+	if len(snapshots) == 0 {
+		color.Yellow("📭 No snapshots found")
+		return nil
+	}
+
+	browser := &interactiveRestore{
+		gitManager: gitManager,
+		reader:     bufio.NewReader(os.Stdin),
+		snapshots:  snapshots,
+	}
+
+	for {
+		snapshot, ok, err := browser.chooseSnapshot()
+		if err != nil || !ok {
+			return err
+		}
+
+		restored, back, err := browser.chooseFiles(snapshot, force)
+		if err != nil || restored || !back {
+			return err
+		}
+	}
+}
+
+// prompt prints a prompt and reads one trimmed line. EOF is treated as quit.
+func (r *interactiveRestore) prompt(text string) (string, bool, error) {
+	fmt.Print(text)
+	line, err := r.reader.ReadString('\n')
+	if err != nil && line == "" {
+		fmt.Println()
+		return "", false, nil
+	}
+	return strings.TrimSpace(line), true, nil
+}
+
+// chooseSnapshot shows the paged snapshot list until one is selected
+func (r *interactiveRestore) chooseSnapshot() (core.Snapshot, bool, error) {
+	pages := (len(r.snapshots) + interactivePageSize - 1) / interactivePageSize
+
+	for {
+		start := r.page * interactivePageSize
+		end := start + interactivePageSize
+		if end > len(r.snapshots) {
+			end = len(r.snapshots)
+		}
+
+		fmt.Println()
+		color.Cyan("📸 Snapshots (page %d/%d)", r.page+1, pages)
+		fmt.Println()
+		for i := start; i < end; i++ {
+			snapshot := r.snapshots[i]
+			fmt.Printf("  %3d  ", i+1)
+			color.New(color.FgYellow).Printf("%s", snapshot.Hash[:8])
+			fmt.Printf("  %-45s  ", utils.TruncateString(snapshot.Message, 45))
+			color.New(color.FgHiBlack).Println(snapshot.Time)
+		}
+		fmt.Println()
+		fmt.Println("  <n> select · d <n> preview diff · ] next page · [ previous page · q quit")
+
+		input, ok, err := r.prompt("> ")
+		if err != nil || !ok {
+			return core.Snapshot{}, false, err
+		}
+
+		switch {
+		case input == "":
+			continue
+		case input == "q":
+			fmt.Println("Restore cancelled.")
+			return core.Snapshot{}, false, nil
+		case input == "]":
+			if r.page < pages-1 {
+				r.page++
+			}
+		case input == "[":
+			if r.page > 0 {
+				r.page--
+			}
+		case strings.HasPrefix(input, "d"):
+			index, err := parseIndex(strings.TrimSpace(input[1:]), len(r.snapshots))
+			if err != nil {
+				color.Red("❌ %v", err)
+				continue
+			}
+			if err := r.previewDiff(r.snapshots[index].Hash, ""); err != nil {
+				return core.Snapshot{}, false, err
+			}
+		default:
+			index, err := parseIndex(input, len(r.snapshots))
+			if err != nil {
+				color.Red("❌ %v", err)
+				continue
+			}
+			return r.snapshots[index], true, nil
+		}
+	}
+}
+
+// chooseFiles lets the user tick files changed by restoring the snapshot.
+// It reports whether a restore happened or the user asked to go back.
+func (r *interactiveRestore) chooseFiles(snapshot core.Snapshot, force bool) (restored, back bool, err error) {
+	changes, err := r.gitManager.CompareWorktree(snapshot.Hash)
+	if err != nil {
+		return false, false, err
+	}
+	if len(changes) == 0 {
+		color.Green("✅ Working directory already matches snapshot %s", snapshot.Hash[:8])
+		return false, true, nil
+	}
+
+	selected := make([]bool, len(changes))
+	for i := range selected {
+		selected[i] = true
+	}
+
+	for {
+		fmt.Println()
+		color.Cyan("📸 %s  %s", snapshot.Hash[:8], snapshot.Message)
+		fmt.Println()
+		for i, change := range changes {
+			box := "[ ]"
+			if selected[i] {
+				box = "[x]"
+			}
+			fmt.Printf("  %3d  %s  ", i+1, box)
+			switch change.Status {
+			case "A":
+				color.New(color.FgGreen).Printf("%-8s", change.Describe())
+			case "D":
+				color.New(color.FgRed).Printf("%-8s", change.Describe())
+			default:
+				color.New(color.FgYellow).Printf("%-8s", change.Describe())
+			}
+			fmt.Printf("  %s\n", change.Path)
+		}
+		fmt.Println()
+		fmt.Println("  <n>[,<n>-<m>] toggle · a all · x none · d <n> preview · r restore · b back · q quit")
+
+		input, ok, err := r.prompt("> ")
+		if err != nil || !ok {
+			return false, false, err
+		}
+
+		switch {
+		case input == "":
+			continue
+		case input == "q":
+			fmt.Println("Restore cancelled.")
+			return false, false, nil
+		case input == "b":
+			return false, true, nil
+		case input == "a" || input == "x":
+			for i := range selected {
+				selected[i] = input == "a"
+			}
+		case input == "r":
+			var files []string
+			for i, change := range changes {
+				if selected[i] {
+					files = append(files, change.Path)
+				}
+			}
+			if len(files) == 0 {
+				color.Yellow("⚠️  No files selected")
+				continue
+			}
+			return r.restore(snapshot, files, force)
+		case strings.HasPrefix(input, "d"):
+			index, err := parseIndex(strings.TrimSpace(input[1:]), len(changes))
+			if err != nil {
+				color.Red("❌ %v", err)
+				continue
+			}
+			if err := r.previewDiff(snapshot.Hash, changes[index].Path); err != nil {
+				return false, false, err
+			}
+		default:
+			indexes, err := parseSelection(input, len(changes))
+			if err != nil {
+				color.Red("❌ %v", err)
+				continue
+			}
+			for _, index := range indexes {
+				selected[index] = !selected[index]
+			}
+		}
+	}
+}
+
+// restore confirms and restores the selected files
+func (r *interactiveRestore) restore(snapshot core.Snapshot, files []string, force bool) (bool, bool, error) {
+	fmt.Println()
+	color.Yellow("⚠️  This will restore %d file(s) from snapshot %s", len(files), snapshot.Hash[:8])
+	fmt.Println("   Any uncommitted changes to these files will be lost!")
+
+	// Paths from git diff are relative to the project root, not the cwd
+	pathspecs := make([]string, len(files))
+	for i, file := range files {
+		pathspecs[i] = ":(top,literal)" + file
+	}
+
+	conflicts, err := r.gitManager.RestoreConflicts(snapshot.Hash, pathspecs)
+	if err != nil {
+		return false, false, err
+	}
+	if len(conflicts) > 0 {
+		showRestoreConflicts(conflicts)
+		if !force {
+			fmt.Println("   Commit or stash them first, or rerun with --force to overwrite them.")
+			return false, true, nil
+		}
+	}
+
+	if !force && !ui.AssumeYes() {
+		input, ok, err := r.prompt("Do you want to continue? (y/N): ")
+		if err != nil || !ok {
+			return false, false, err
+		}
+		input = strings.ToLower(input)
+		if input != "y" && input != "yes" {
+			return false, true, nil
+		}
+	}
+
+	fmt.Print("🔄 Restoring files... ")
+	journal, err := journaledRestore(r.gitManager, snapshot.Hash, pathspecs)
+	if err != nil {
+		return false, false, err
+	}
+	color.Green("✅")
+	fmt.Println()
+	color.Green("✨ %d file(s) restored successfully!", len(files))
+	printBackupPatch(journal.BackupPatch)
+	if len(journal.Files) > 0 {
+		runPostRestoreHooks(r.gitManager, journal)
+	}
+
+	return true, false, nil
+}
+
+// previewDiff prints the changes restoring the snapshot would make
+func (r *interactiveRestore) previewDiff(hash, path string) error {
+	diff, err := r.gitManager.WorktreeDiff(hash, path)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println()
+	if strings.TrimSpace(diff) == "" {
+		color.Green("✅ No differences from the working directory")
+		return nil
+	}
+
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git"):
+			color.Cyan(line)
+		case strings.HasPrefix(line, "@@"):
+			color.Blue(line)
+		case strings.HasPrefix(line, "+") && !strings.HasPrefix(line, "+++"):
+			color.Green(line)
+		case strings.HasPrefix(line, "-") && !strings.HasPrefix(line, "---"):
+			color.Red(line)
+		default:
+			fmt.Println(line)
+		}
+	}
+
+	_, _, err = r.prompt("\nPress Enter to continue...")
+	return err
+}
+
+// parseIndex parses a 1-based index into a 0-based one
+func parseIndex(input string, count int) (int, error) {
+	n, err := strconv.Atoi(input)
+	if err != nil || n < 1 || n > count {
+		return 0, fmt.Errorf("enter a number between 1 and %d", count)
+	}
+	return n - 1, nil
+}
+
+// parseSelection parses "1,3 5-7" into 0-based indexes
+func parseSelection(input string, count int) ([]int, error) {
+	fields := strings.FieldsFunc(input, func(r rune) bool { return r == ',' || r == ' ' })
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("enter a number between 1 and %d", count)
+	}
+
+	var indexes []int
+	for _, field := range fields {
+		first, last, isRange := strings.Cut(field, "-")
+		start, err := parseIndex(first, count)
+		if err != nil {
+			return nil, err
+		}
+		end := start
+		if isRange {
+			if end, err = parseIndex(last, count); err != nil {
+				return nil, err
+			}
+		}
+		if end < start {
+			start, end = end, start
+		}
+		for i := start; i <= end; i++ {
+			indexes = append(indexes, i)
+		}
+	}
+
+	return indexes, nil
+}
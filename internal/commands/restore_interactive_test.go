@@ -0,0 +1,60 @@
+package commands
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/utils"
+)
+
+func TestParseSelection(t *testing.T) {
+	testCases := []struct {
+		name    string
+		input   string
+		want    []int
+		wantErr bool
+	}{
+		{name: "single index", input: "2", want: []int{1}},
+		{name: "comma and space separated", input: "1,3 5", want: []int{0, 2, 4}},
+		{name: "range", input: "2-4", want: []int{1, 2, 3}},
+		{name: "reversed range", input: "4-2", want: []int{1, 2, 3}},
+		{name: "out of bounds", input: "6", wantErr: true},
+		{name: "zero", input: "0", wantErr: true},
+		{name: "not a number", input: "abc", wantErr: true},
+		{name: "empty", input: "", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parseSelection(tc.input, 5)
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("parseSelection(%q) expected error, got %v", tc.input, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseSelection(%q) unexpected error: %v", tc.input, err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("parseSelection(%q) = %v, want %v", tc.input, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestTruncateMessage(t *testing.T) {
+	if got := utils.TruncateString("short", 10); got != "short" {
+		t.Errorf("Expected short string unchanged, got %q", got)
+	}
+	got := utils.TruncateString("snapshöt message über ünïcödé", 12)
+	if !utf8.ValidString(got) || got != "snapshöt ..." {
+		t.Errorf("Expected a valid truncated string, got %q", got)
+	}
+	// The browser's columns line up for non-ASCII messages
+	if line := fmt.Sprintf("%-45s|", utils.TruncateString("日本語のスナップショットのメッセージ", 45)); utf8.RuneCountInString(line) != 46 {
+		t.Errorf("Expected the message padded to 45 runes, got %q", line)
+	}
+}
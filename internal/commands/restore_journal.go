@@ -0,0 +1,113 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/signal"
+	"syscall"
+
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/core"
+	"github.com/fatih/color"
+)
+
+// journaledRestore restores through the restore journal. Ctrl+C stops the
+// restore between batches and leaves it resumable.
+func journaledRestore(gitManager *core.GitManager, hash string, pathspecs []string) (*core.RestoreJournal, error) {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	journal, err := gitManager.JournaledRestore(ctx, hash, pathspecs, core.DefaultRestoreBatchSize, printRestoreProgress)
+	return journal, explainRestoreError(journal, err)
+}
+
+// printRestoreProgress shows batch progress for restores spanning several batches
+func printRestoreProgress(done, total int) {
+	if total > core.DefaultRestoreBatchSize {
+		fmt.Printf("\r🔄 Restoring files... %d/%d ", done, total)
+	}
+}
+
+// explainRestoreError reports a failed restore, with recovery instructions
+// when it was interrupted or blocked. Callers can return the result as is.
+func explainRestoreError(journal *core.RestoreJournal, err error) error {
+	var inProgress *core.RestoreInProgressError
+	switch {
+	case err == nil:
+		return nil
+	case errors.As(err, &inProgress):
+		fmt.Println()
+		color.Red("❌ %v", err)
+		printResumeHint()
+		return fmt.Errorf("restore blocked by an interrupted restore")
+	case errors.Is(err, context.Canceled):
+		fmt.Println()
+		color.Yellow("⏸️  Restore interrupted after %d of %d files", journal.Done, len(journal.Files))
+		printResumeHint()
+		return fmt.Errorf("restore interrupted")
+	case journal != nil && journal.Done < len(journal.Files):
+		fmt.Println()
+		color.Red("❌ Restore failed after %d of %d files", journal.Done, len(journal.Files))
+		printResumeHint()
+		return fmt.Errorf("failed to restore snapshot: %w", err)
+	default:
+		color.Red("❌")
+		return fmt.Errorf("failed to restore snapshot: %w", err)
+	}
+}
+
+// runPostRestoreHooks runs the post_restore hooks once a restore finished;
+// a failing hook does not undo the restore
+func runPostRestoreHooks(gitManager *core.GitManager, journal *core.RestoreJournal) {
+	hc := core.HookContext{Hash: journal.Source, BackupHash: journal.Backup, ChangedFiles: journal.Files}
+	if err := gitManager.RunHooks(core.HookPostRestore, hc); err != nil {
+		color.Yellow("⚠️  %v", err)
+	}
+}
+
+func printResumeHint() {
+	fmt.Println("   Run 'timemachine restore --resume' to finish it")
+	fmt.Println("   or 'timemachine restore --abort' to roll back to the pre-restore snapshot.")
+}
+
+// runRestoreJournal handles --resume and --abort
+func runRestoreJournal(state *core.AppState, resume bool) error {
+	gitManager := core.NewGitManager(state)
+
+	journal, err := gitManager.ReadRestoreJournal()
+	if errors.Is(err, core.ErrNoRestoreInProgress) {
+		color.Yellow("📭 No interrupted restore found")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if !resume {
+		fmt.Printf("⏪ Rolling back %d file(s) to the pre-restore snapshot %s... ", len(journal.Files), journal.Backup[:8])
+		if _, err := gitManager.AbortRestore(); err != nil {
+			color.Red("❌")
+			return err
+		}
+		color.Green("✅")
+		fmt.Println()
+		color.Green("✨ Restore aborted, working directory rolled back")
+		return nil
+	}
+
+	fmt.Printf("🔄 Resuming restore of %s (%d/%d files done)... ", journal.Source[:8], journal.Done, len(journal.Files))
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	journal, err = gitManager.ResumeRestore(ctx, core.DefaultRestoreBatchSize, printRestoreProgress)
+	if err := explainRestoreError(journal, err); err != nil {
+		return err
+	}
+
+	color.Green("✅")
+	fmt.Println()
+	color.Green("✨ Restore of %s completed!", journal.Source[:8])
+	runPostRestoreHooks(gitManager, journal)
+	return nil
+}
@@ -0,0 +1,149 @@
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/core"
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/render"
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/ui"
+	"github.com/fatih/color"
+)
+
+// patchHelp explains the answers to the hunk prompt, as git add -p does
+const patchHelp = `y - restore this hunk
+n - keep the working copy of this hunk
+a - restore this hunk and all later ones
+d - keep this hunk and all later ones
+q - quit; restore only the hunks chosen so far
+? - print help`
+
+// runRestorePatch walks through the hunks restoring a file from a snapshot
+// would change and restores the ones picked. Like a full restore, it
+// refuses a file with uncommitted Git changes unless force or stash is set.
+func runRestorePatch(hash, file string, force, stash bool) error {
+	state, err := loadInitializedState()
+	if err != nil || state == nil {
+		return err
+	}
+
+	gitManager := core.NewGitManager(state)
+	if hash, err = gitManager.ResolveSnapshot(hash); err != nil {
+		return err
+	}
+	patch, err := gitManager.RestorePatch(hash, file)
+	if err != nil {
+		return err
+	}
+	if len(patch.Hunks) == 0 {
+		color.Green("✨ %s already matches snapshot %s", patch.Path, core.ShortHash(patch.Source))
+		return nil
+	}
+
+	conflicts, err := gitManager.RestoreConflicts(patch.Source, []string{":(top,literal)" + patch.Path})
+	if err != nil {
+		return err
+	}
+	if len(conflicts) > 0 {
+		showRestoreConflicts(conflicts)
+		if !force && !stash {
+			fmt.Println("   Commit them, rerun with --stash to stash them first, or use --force to")
+			fmt.Println("   restore hunks over them (the pre-restore snapshot keeps the working copy).")
+			return fmt.Errorf("restore would overwrite uncommitted changes")
+		}
+	}
+	if err := ui.RequireInput("restore --patch"); err != nil {
+		return err
+	}
+	if stash && len(conflicts) > 0 {
+		if err := gitManager.StashProjectChanges(conflicts, "timemachine: before restoring hunks from "+core.ShortHash(patch.Source)); err != nil {
+			return err
+		}
+		fmt.Printf("📦 Stashed uncommitted changes to %s; 'git stash pop' brings them back\n", patch.Path)
+		// The hunks are now against the committed version
+		if patch, err = gitManager.RestorePatch(patch.Source, file); err != nil {
+			return err
+		}
+		if len(patch.Hunks) == 0 {
+			color.Green("✨ %s already matches snapshot %s", patch.Path, core.ShortHash(patch.Source))
+			return nil
+		}
+	}
+
+	fmt.Printf("📸 Restoring hunks of %s from snapshot %s (%d hunk(s))\n", patch.Path, core.ShortHash(patch.Source), len(patch.Hunks))
+
+	selected := chooseHunks(bufio.NewReader(os.Stdin), patch)
+	if len(selected) == 0 {
+		fmt.Println("No hunks restored.")
+		return nil
+	}
+
+	backup, backupPatch, err := gitManager.ApplyRestorePatch(patch, selected)
+	if err != nil {
+		return err
+	}
+	fmt.Println()
+	color.Green("✨ Restored %d of %d hunk(s) of %s", len(selected), len(patch.Hunks), patch.Path)
+	fmt.Printf("💾 Pre-restore snapshot: %s (use it to undo this restore)\n", core.ShortHash(backup))
+	printBackupPatch(backupPatch)
+
+	hc := core.HookContext{Hash: patch.Source, BackupHash: backup, ChangedFiles: []string{patch.Path}}
+	if err := gitManager.RunHooks(core.HookPostRestore, hc); err != nil {
+		color.Yellow("⚠️  %v", err)
+	}
+	return nil
+}
+
+// chooseHunks shows each hunk and asks whether to restore it, returning the
+// indexes picked. End of input quits, as q does.
+func chooseHunks(reader *bufio.Reader, patch *core.RestorePatch) []int {
+	var selected []int
+	highlighter := render.NewHighlighter(patch.Path)
+	for i := 0; i < len(patch.Hunks); i++ {
+		fmt.Println()
+		printHunk(patch.Hunks[i], highlighter)
+
+		fmt.Printf("(%d/%d) Restore this hunk [y,n,a,d,q,?]? ", i+1, len(patch.Hunks))
+		line, err := reader.ReadString('\n')
+		if err != nil && line == "" {
+			fmt.Println()
+			return selected
+		}
+		switch strings.ToLower(strings.TrimSpace(line)) {
+		case "y", "yes":
+			selected = append(selected, i)
+		case "n", "no":
+		case "a":
+			for ; i < len(patch.Hunks); i++ {
+				selected = append(selected, i)
+			}
+		case "d", "q":
+			return selected
+		default:
+			color.Cyan(patchHelp)
+			i-- // Ask again
+		}
+	}
+	return selected
+}
+
+// printHunk prints a hunk the way restore --interactive previews diffs:
+// lines from the working copy in red, from the snapshot in green, with the
+// code highlighted by its language
+func printHunk(hunk core.Hunk, highlighter *render.Highlighter) {
+	color.Blue(hunk.Header)
+	for _, line := range hunk.Lines {
+		switch {
+		case strings.HasPrefix(line, `\`): // "\ No newline at end of file"
+			fmt.Println(line)
+		case line[0] == '+':
+			fmt.Println(highlightDiffLine(highlighter, ui.RoleAdded, line))
+		case line[0] == '-':
+			fmt.Println(highlightDiffLine(highlighter, ui.RoleDeleted, line))
+		default:
+			fmt.Println(line[:1] + highlighter.Line(line[1:]))
+		}
+	}
+}
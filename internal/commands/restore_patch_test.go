@@ -0,0 +1,87 @@
+package commands
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/core"
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/ui"
+)
+
+func TestChooseHunks(t *testing.T) {
+	patch := &core.RestorePatch{Path: "main.go"}
+	for i := 0; i < 4; i++ {
+		patch.Hunks = append(patch.Hunks, core.Hunk{Header: "@@ -1 +1 @@", Lines: []string{"-new", "+old"}})
+	}
+
+	tests := []struct {
+		input    string
+		expected []int
+	}{
+		{"y\nn\ny\nn\n", []int{0, 2}},
+		{"n\n?\na\n", []int{1, 2, 3}}, // Help asks again
+		{"y\nd\n", []int{0}},
+		{"n\ny\nq\n", []int{1}},
+		{"y", []int{0}}, // Input ends: quit
+		{"", nil},
+	}
+	for _, tt := range tests {
+		selected := chooseHunks(bufio.NewReader(strings.NewReader(tt.input)), patch)
+		if !reflect.DeepEqual(selected, tt.expected) {
+			t.Errorf("chooseHunks(%q) = %v, want %v", tt.input, selected, tt.expected)
+		}
+	}
+}
+
+func TestRestorePatchUncommittedChanges(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("Git not available")
+	}
+	tempDir := t.TempDir()
+	for _, args := range [][]string{
+		{"init"},
+		{"config", "user.name", "Test User"},
+		{"config", "user.email", "test@example.com"},
+	} {
+		if output, err := exec.Command("git", append([]string{"-C", tempDir}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+	}
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+	os.Chdir(tempDir)
+
+	if err := os.WriteFile("main.go", []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	exec.Command("git", "add", "main.go").Run()
+	exec.Command("git", "commit", "-m", "init").Run()
+	initCmd := InitCmd()
+	if err := initCmd.RunE(initCmd, []string{}); err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+
+	// Uncommitted work on the file
+	if err := os.WriteFile("main.go", []byte("package main // edited\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := runRestorePatch("latest", "main.go", false, false)
+	if err == nil || !strings.Contains(err.Error(), "uncommitted changes") {
+		t.Errorf("Expected --patch to refuse uncommitted changes, got %v", err)
+	}
+
+	// Nothing is stashed before it is known hunks can be chosen
+	ui.SetNoInput()
+	if err := runRestorePatch("latest", "main.go", false, true); !errors.Is(err, ui.ErrNoInput) {
+		t.Errorf("Expected ErrNoInput without a terminal, got %v", err)
+	}
+	if content, _ := os.ReadFile("main.go"); string(content) != "package main // edited\n" {
+		t.Errorf("Expected the working copy untouched, got %q", content)
+	}
+}
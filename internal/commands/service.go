@@ -0,0 +1,207 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/core"
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/daemon"
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/ui"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// ServiceCmd creates the service command with subcommands
+func ServiceCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "service",
+		Short: "Start the watcher automatically when you log in",
+		Long: `Register the watcher as a user-level service so snapshots resume
+automatically after a reboot: a systemd user unit on Linux, a LaunchAgent
+on macOS, or a logon task in Task Scheduler on Windows.
+
+Each repository gets its own service; install it for every repository
+that should be watched.
+
+Examples:
+  timemachine service install                   # The current repository
+  timemachine service install ~/src/api ~/src/web
+  timemachine service status                    # All installed services
+  timemachine service uninstall --all`,
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "install [path...]",
+		Short: "Install and start the service for repositories (default: current)",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runServiceInstall(args)
+		},
+	})
+
+	var all bool
+	uninstall := &cobra.Command{
+		Use:   "uninstall [path...]",
+		Short: "Stop and remove the service for repositories (default: current)",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if all && len(args) > 0 {
+				return fmt.Errorf("--all cannot be combined with paths")
+			}
+			return runServiceUninstall(args, all)
+		},
+	}
+	uninstall.Flags().BoolVar(&all, "all", false, "Remove every Time Machine service")
+	cmd.AddCommand(uninstall)
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "status",
+		Short: "List installed services and whether they are running",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runServiceStatus()
+		},
+	})
+
+	return cmd
+}
+
+// serviceRepos resolves the repositories named on the command line (the
+// current one by default) to their states
+func serviceRepos(paths []string) ([]*core.AppState, error) {
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+	states := make([]*core.AppState, 0, len(paths))
+	for _, path := range paths {
+		state, err := core.NewLightAppStateAt(path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		states = append(states, state)
+	}
+	return states, nil
+}
+
+func runServiceInstall(paths []string) error {
+	states, err := serviceRepos(paths)
+	if err != nil {
+		return err
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate the timemachine binary: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+
+	installed := 0
+	for _, state := range states {
+		if !state.IsInitialized {
+			ui.Error("%s", ui.T(ui.MsgRepoNotInitialized, state.ProjectRoot))
+			fmt.Println("   " + ui.T(ui.MsgRunInitThere))
+			continue
+		}
+
+		// The service's watcher would refuse to run next to a background one
+		daemonManager := daemon.NewManager(state)
+		stopped := false
+		if pid, err := daemonManager.Stop(); err == nil {
+			fmt.Printf("🛑 Stopped the background watcher (PID %d) in %s; the service takes over\n", pid, state.ProjectRoot)
+			stopped = true
+		} else if !errors.Is(err, daemon.ErrNotRunning) {
+			return err
+		}
+
+		svc, err := daemon.InstallService(state.ProjectRoot, exe, daemonManager.LogFile())
+		if err != nil {
+			color.Red("❌ %s: %v", state.ProjectRoot, err)
+			if stopped {
+				fmt.Println("   Run 'timemachine start --daemon' there to restart the background watcher.")
+			}
+			continue
+		}
+		color.Green("✅ %s: installed %s", state.ProjectRoot, svc.Name)
+		installed++
+	}
+
+	if installed > 0 {
+		fmt.Println()
+		fmt.Printf("ℹ️  %s\n", daemon.ServiceNote)
+	}
+	return nil
+}
+
+func runServiceUninstall(paths []string, all bool) error {
+	var services []daemon.Service
+	if all {
+		installed, err := daemon.ListServices()
+		if err != nil {
+			return err
+		}
+		services = installed
+	} else {
+		states, err := serviceRepos(paths)
+		if err != nil {
+			return err
+		}
+		for _, state := range states {
+			svc, err := daemon.FindService(state.ProjectRoot)
+			if err != nil {
+				return err
+			}
+			if svc == nil {
+				color.Yellow("⚠️  %s: no service installed", state.ProjectRoot)
+				continue
+			}
+			services = append(services, *svc)
+		}
+	}
+
+	if all && len(services) == 0 {
+		color.Yellow("📭 No services installed")
+		return nil
+	}
+	for _, svc := range services {
+		if err := daemon.UninstallService(svc); err != nil {
+			color.Yellow("⚠️  %s: %v", svc.Repo, err)
+			continue
+		}
+		color.Green("✅ %s: removed %s", svc.Repo, svc.Name)
+	}
+	return nil
+}
+
+func runServiceStatus() error {
+	services, err := daemon.ListServices()
+	if errors.Is(err, daemon.ErrServicesUnsupported) {
+		color.Yellow("⚠️  %v", err)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("🔧 Service manager: %s\n", daemon.ServiceManager)
+	if len(services) == 0 {
+		color.Yellow("📭 No services installed")
+		fmt.Println("   Run 'timemachine service install' in a repository to start its watcher at login.")
+		return nil
+	}
+
+	fmt.Println()
+	for _, svc := range services {
+		state := svc.State()
+		if state.Active {
+			color.Green("✅ %s", svc.Repo)
+		} else {
+			color.Yellow("⚠️  %s", svc.Repo)
+		}
+		fmt.Printf("   Service: %s (%s)\n", svc.Name, state.Detail)
+		if _, err := os.Stat(svc.Repo); err != nil {
+			color.Red("   Repository is missing; run 'timemachine service uninstall --all' or reinstall")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,381 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/core"
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/ui"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// exportOptions controls the size and content of a session export
+type exportOptions struct {
+	Format       string
+	MaxBytes     int
+	MaxDiffBytes int
+	NoRedact     bool
+}
+
+// SessionCmd creates the session command
+func SessionCmd() *cobra.Command {
+	var sessionGap time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "session",
+		Short: "Start, end, list and export work sessions",
+		Long: `Work with sessions: runs of snapshots with no more than --session-gap
+between them. A session is identified by the short hash of its first
+snapshot; 'latest' always refers to the most recent session.
+
+Mark a session explicitly, such as one AI agent task, with 'session start'
+and 'session end'. Every snapshot taken in between is tagged with the
+session's ID (s1, s2, ...), so it can be listed, or rolled back as a whole:
+
+  timemachine session start "claude: refactor auth"
+  timemachine session end
+  timemachine list --session s1
+  timemachine restore --session-start s1`,
+	}
+
+	cmd.PersistentFlags().DurationVar(&sessionGap, "session-gap", core.DefaultSessionGap, "Idle time that starts a new session")
+
+	cmd.AddCommand(sessionStartCmd())
+	cmd.AddCommand(sessionEndCmd())
+	cmd.AddCommand(sessionListCmd(&sessionGap))
+	cmd.AddCommand(sessionExportCmd(&sessionGap))
+
+	return cmd
+}
+
+func sessionStartCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "start <name>",
+		Short: "Start a named session, tagging every snapshot until it ends",
+		Long: `Snapshot the working tree and start a named session. Snapshots taken until
+'timemachine session end' carry its ID, whichever process takes them, and
+'timemachine restore --session-start <id>' brings back the state from
+before it began. One session runs at a time.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runSessionStart(args[0])
+		},
+	}
+}
+
+func sessionEndCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "end",
+		Short: "End the running named session",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runSessionEnd()
+		},
+	}
+}
+
+func runSessionStart(name string) error {
+	state, err := loadInitializedState()
+	if err != nil || state == nil {
+		return err
+	}
+	session, err := core.NewGitManager(state).StartNamedSession(name)
+	if err != nil {
+		return err
+	}
+	ui.Success("▶️  Started session %s: %s", session.ID, session.Name)
+	fmt.Printf("   Roll it back with 'timemachine restore --session-start %s'\n", session.ID)
+	return nil
+}
+
+func runSessionEnd() error {
+	state, err := loadInitializedState()
+	if err != nil || state == nil {
+		return err
+	}
+	gitManager := core.NewGitManager(state)
+	session, err := gitManager.EndNamedSession()
+	if err != nil {
+		return err
+	}
+	snapshots, err := gitManager.FilterSnapshots(core.SnapshotFilter{Session: session.ID})
+	if err != nil {
+		return err
+	}
+	ui.Success("⏹️  Ended session %s after %s with %d snapshot(s)", session.ID, session.End.Sub(session.Start).Round(time.Second), len(snapshots))
+	fmt.Printf("   List them with 'timemachine list --session %s'\n", session.ID)
+	return nil
+}
+
+func sessionListCmd(sessionGap *time.Duration) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List work sessions",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runSessionList(*sessionGap)
+		},
+	}
+}
+
+func sessionExportCmd(sessionGap *time.Duration) *cobra.Command {
+	var opts exportOptions
+
+	cmd := &cobra.Command{
+		Use:   "export <id>",
+		Short: "Export a session's snapshots and diffs for post-mortems",
+		Long: `Export every snapshot in a session, in order, with its metadata and unified
+diff. The output is sized for feeding back into an LLM ("analyze what went
+wrong in this session"): diffs are capped per snapshot and in total, and
+values that look like secrets are redacted.
+
+Each line of the jsonl output is one record: a "session" header, one
+"snapshot" record per snapshot and a closing "summary".
+
+Examples:
+  timemachine session export latest > session.jsonl
+  timemachine session export abc12345 --max-bytes 100000`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runSessionExport(os.Stdout, args[0], *sessionGap, opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Format, "format", "jsonl", "Output format (jsonl)")
+	cmd.Flags().IntVar(&opts.MaxBytes, "max-bytes", 256*1024, "Total size budget for diffs (0 = unlimited)")
+	cmd.Flags().IntVar(&opts.MaxDiffBytes, "max-diff-bytes", 32*1024, "Maximum diff size per snapshot (0 = unlimited)")
+	cmd.Flags().BoolVar(&opts.NoRedact, "no-redact", false, "Do not redact detected secrets")
+
+	return cmd
+}
+
+// loadSessions opens the repository and groups its snapshots into sessions
+func loadSessions(sessionGap time.Duration) (*core.GitManager, []core.Session, bool, error) {
+	// Create application state
+	state, err := core.NewAppState()
+	if err != nil {
+		return nil, nil, false, fmt.Errorf("failed to initialize app state: %w", err)
+	}
+
+	// Check if initialized
+	if !state.IsInitialized {
+		ui.Error("%s", ui.T(ui.MsgNotInitialized))
+		fmt.Println(ui.T(ui.MsgRunInit))
+		return nil, nil, false, core.Reported(core.ErrNotInitialized)
+	}
+
+	gitManager := core.NewGitManager(state)
+
+	sessions, err := gitManager.ListSessions(sessionGap)
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	return gitManager, sessions, true, nil
+}
+
+func runSessionList(sessionGap time.Duration) error {
+	gitManager, sessions, ok, err := loadSessions(sessionGap)
+	if err != nil || !ok {
+		return err
+	}
+
+	named, err := gitManager.NamedSessions()
+	if err != nil {
+		return err
+	}
+	if len(named) > 0 {
+		fmt.Printf("🏷️  %d named session(s):\n\n", len(named))
+		for i := len(named) - 1; i >= 0; i-- {
+			session := named[i]
+			color.New(color.FgYellow).Printf("%-8s", session.ID)
+			fmt.Printf("  %s  ", session.Start.Local().Format("2006-01-02 15:04"))
+			if session.Active() {
+				color.New(color.FgGreen).Printf("%-8s", "running")
+			} else {
+				fmt.Printf("%-8s", session.End.Sub(session.Start).Round(time.Minute))
+			}
+			fmt.Printf("  %s\n", session.Name)
+		}
+		fmt.Println()
+	}
+
+	if len(sessions) == 0 {
+		color.Yellow("📭 No sessions found")
+		return nil
+	}
+
+	fmt.Printf("🗂️  %d session(s):\n\n", len(sessions))
+
+	// Newest first, like 'list'
+	for i := len(sessions) - 1; i >= 0; i-- {
+		session := sessions[i]
+		insertions, deletions := 0, 0
+		for _, snapshot := range session.Snapshots {
+			ins, del := snapshot.Totals()
+			insertions += ins
+			deletions += del
+		}
+
+		color.New(color.FgYellow).Printf("%s", session.ID)
+		fmt.Printf("  %s  %-8s  %3d snapshot(s)  ",
+			session.Start.Local().Format("2006-01-02 15:04"),
+			session.End.Sub(session.Start).Round(time.Minute),
+			len(session.Snapshots))
+		color.New(color.FgGreen).Printf("+%d", insertions)
+		fmt.Print("/")
+		color.New(color.FgRed).Printf("-%d\n", deletions)
+	}
+
+	fmt.Println()
+	fmt.Println("💡 Export one with: timemachine session export <id>")
+
+	return nil
+}
+
+// Records written by session export
+type sessionRecord struct {
+	Type       string    `json:"type"`
+	ID         string    `json:"id"`
+	Start      time.Time `json:"start"`
+	End        time.Time `json:"end"`
+	Snapshots  int       `json:"snapshots"`
+	Insertions int       `json:"insertions"`
+	Deletions  int       `json:"deletions"`
+	Files      []string  `json:"files"`
+}
+
+type snapshotRecord struct {
+	Type          string          `json:"type"`
+	Index         int             `json:"index"`
+	Hash          string          `json:"hash"`
+	Time          time.Time       `json:"time"`
+	Message       string          `json:"message"`
+	Files         []changelogFile `json:"files"`
+	Diff          string          `json:"diff"`
+	DiffTruncated bool            `json:"diff_truncated,omitempty"`
+	DiffOmitted   bool            `json:"diff_omitted,omitempty"`
+	Redactions    []string        `json:"redactions,omitempty"`
+}
+
+type summaryRecord struct {
+	Type              string `json:"type"`
+	Snapshots         int    `json:"snapshots"`
+	DiffsTruncated    int    `json:"diffs_truncated"`
+	DiffsOmitted      int    `json:"diffs_omitted"`
+	RedactedSnapshots int    `json:"redacted_snapshots"`
+}
+
+func runSessionExport(out io.Writer, id string, sessionGap time.Duration, opts exportOptions) error {
+	if opts.Format != "jsonl" {
+		return fmt.Errorf("unsupported format: %s (use 'jsonl')", opts.Format)
+	}
+
+	gitManager, sessions, ok, err := loadSessions(sessionGap)
+	if err != nil || !ok {
+		return err
+	}
+
+	session, err := core.FindSession(sessions, id)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(out)
+
+	header := sessionRecord{
+		Type:      "session",
+		ID:        session.ID,
+		Start:     session.Start,
+		End:       session.End,
+		Snapshots: len(session.Snapshots),
+		Files:     []string{},
+	}
+	for _, snapshot := range session.Snapshots {
+		insertions, deletions := snapshot.Totals()
+		header.Insertions += insertions
+		header.Deletions += deletions
+	}
+	for _, file := range core.AggregateFiles(session.Snapshots) {
+		header.Files = append(header.Files, file.Path)
+	}
+	if err := encoder.Encode(header); err != nil {
+		return err
+	}
+
+	summary := summaryRecord{Type: "summary", Snapshots: len(session.Snapshots)}
+	budget := opts.MaxBytes
+
+	for i, snapshot := range session.Snapshots {
+		record := snapshotRecord{
+			Type:    "snapshot",
+			Index:   i + 1,
+			Hash:    snapshot.Hash,
+			Time:    snapshot.Time,
+			Message: snapshot.Message,
+			Files:   []changelogFile{},
+		}
+		for _, file := range snapshot.Files {
+			record.Files = append(record.Files, changelogFile{
+				Path:       file.Path,
+				Insertions: file.Insertions,
+				Deletions:  file.Deletions,
+				Binary:     file.Binary,
+			})
+		}
+
+		if opts.MaxBytes > 0 && budget <= 0 {
+			record.DiffOmitted = true
+			summary.DiffsOmitted++
+		} else {
+			diff, err := gitManager.SnapshotPatch(snapshot.Hash)
+			if err != nil {
+				return err
+			}
+
+			// Redact before truncating so a cut can never expose part of a secret
+			if !opts.NoRedact {
+				diff, record.Redactions = core.RedactSecrets(diff, core.BuiltinSecretRules)
+				if len(record.Redactions) > 0 {
+					summary.RedactedSnapshots++
+				}
+			}
+
+			limit := opts.MaxDiffBytes
+			if opts.MaxBytes > 0 && (limit <= 0 || budget < limit) {
+				limit = budget
+			}
+			record.Diff, record.DiffTruncated = truncateDiff(diff, limit)
+			if record.DiffTruncated {
+				summary.DiffsTruncated++
+			}
+			budget -= len(record.Diff)
+		}
+
+		if err := encoder.Encode(record); err != nil {
+			return err
+		}
+	}
+
+	return encoder.Encode(summary)
+}
+
+// truncateDiff cuts a diff to at most limit bytes at a line boundary.
+// A limit of 0 or less means unlimited.
+func truncateDiff(diff string, limit int) (string, bool) {
+	if limit <= 0 || len(diff) <= limit {
+		return diff, false
+	}
+
+	cut := strings.LastIndex(diff[:limit], "\n")
+	if cut < 0 {
+		cut = 0
+	}
+
+	return diff[:cut] + fmt.Sprintf("\n... [diff truncated, %d bytes omitted]", len(diff)-cut), true
+}
@@ -0,0 +1,28 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTruncateDiff(t *testing.T) {
+	diff := "line one\nline two\nline three\n"
+
+	if got, truncated := truncateDiff(diff, 0); truncated || got != diff {
+		t.Errorf("Expected unlimited diff to be unchanged, got %q", got)
+	}
+	if got, truncated := truncateDiff(diff, 100); truncated || got != diff {
+		t.Errorf("Expected small diff to be unchanged, got %q", got)
+	}
+
+	got, truncated := truncateDiff(diff, 12)
+	if !truncated {
+		t.Fatal("Expected diff to be truncated")
+	}
+	if !strings.HasPrefix(got, "line one\n...") {
+		t.Errorf("Expected cut at line boundary, got %q", got)
+	}
+	if !strings.Contains(got, "bytes omitted") {
+		t.Errorf("Expected truncation marker, got %q", got)
+	}
+}
@@ -1,29 +1,48 @@
 package commands
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 
-	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 	"github.com/deepakkumarnarayana/timemachine-cli/internal/core"
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/ui"
 )
 
 // ShowCmd creates the show command
 func ShowCmd() *cobra.Command {
-	return &cobra.Command{
+	var ago int
+
+	cmd := &cobra.Command{
 		Use:   "show <hash>",
 		Short: "Show detailed information about a snapshot",
 		Long: `Show detailed information about a specific snapshot including:
 - Full commit hash
 - Commit message  
 - Author and timestamp
-- Changed files`,
-		Args: cobra.ExactArgs(1),
+- Provenance: host, tool and session trailers and the signature, if any
+- Changed files, with renames and copies detected and +/- line counts
+- Changes summed by directory and file type
+
+The snapshot is a full or short hash, a tag, 'latest', or a revision such
+as latest~3 or HEAD^; -n 3 shows the snapshot three before the latest.`,
+		Args: cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeSnapshots(1, false),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			args, err := snapshotsAgoArgs(cmd, ago, args)
+			if err != nil {
+				return err
+			}
+			if len(args) != 1 {
+				return core.NewValidationError("requires a snapshot hash or -n")
+			}
 			return runShow(args[0])
 		},
 	}
+	addSnapshotsAgoFlag(cmd, &ago)
+
+	return cmd
 }
 
 func runShow(hash string) error {
@@ -35,38 +54,50 @@ func runShow(hash string) error {
 
 	// Check if initialized
 	if !state.IsInitialized {
-		color.Red("❌ Time Machine is not initialized!")
-		fmt.Println("Run 'timemachine init' to get started.")
-		return nil
+		ui.Error("%s", ui.T(ui.MsgNotInitialized))
+		fmt.Println(ui.T(ui.MsgRunInit))
+		return core.Reported(core.ErrNotInitialized)
 	}
 
 	// Create Git manager
 	gitManager := core.NewGitManager(state)
 
-	// Get detailed commit information
-	commitInfo, err := gitManager.RunCommand("show", "--pretty=fuller", "--name-status", hash)
+	// Resolve short hashes, tags and relative revisions
+	ref := hash
+	hash, err = gitManager.ResolveSnapshot(ref)
 	if err != nil {
-		if strings.Contains(err.Error(), "bad object") || strings.Contains(err.Error(), "bad revision") {
-			color.Red("❌ Snapshot not found!")
-			fmt.Printf("   Hash '%s' does not exist.\n", hash)
+		var notFound *core.NotFoundError
+		if errors.As(err, &notFound) {
+			ui.Error("❌ Snapshot not found!")
+			fmt.Printf("   '%s' does not name a snapshot.\n", ref)
 			fmt.Println("   Use 'timemachine list' to see available snapshots.")
-			return nil
+			return core.Reported(err)
 		}
+		return err
+	}
+
+	// Get detailed commit information
+	commitInfo, err := gitManager.RunCommand("show", "--pretty=fuller", "--no-patch", hash)
+	if err != nil {
 		return fmt.Errorf("failed to show snapshot details: %w", err)
 	}
+	changes, err := gitManager.SnapshotFileChanges(hash)
+	if err != nil {
+		return err
+	}
+	provenance, err := gitManager.SnapshotProvenance(hash)
+	if err != nil {
+		return err
+	}
 
 	// Display the information with nice formatting
 	fmt.Printf("📸 Snapshot Details\n")
 	fmt.Println()
 	
 	// Parse and format the git show output
-	lines := strings.Split(commitInfo, "\n")
-	inFileList := false
-	
-	for _, line := range lines {
-		// Handle commit info section
+	for _, line := range strings.Split(commitInfo, "\n") {
 		if strings.HasPrefix(line, "commit ") {
-			color.Yellow("Commit:    %s", strings.TrimPrefix(line, "commit "))
+			ui.Line(ui.RoleHash, "Commit:    %s", strings.TrimPrefix(line, "commit "))
 		} else if strings.HasPrefix(line, "Author: ") {
 			fmt.Printf("Author:    %s\n", strings.TrimPrefix(line, "Author: "))
 		} else if strings.HasPrefix(line, "AuthorDate: ") {
@@ -75,70 +106,126 @@ func runShow(hash string) error {
 			fmt.Printf("Committer: %s\n", strings.TrimPrefix(line, "Commit: "))
 		} else if strings.HasPrefix(line, "CommitDate: ") {
 			fmt.Printf("Committed: %s\n", strings.TrimPrefix(line, "CommitDate: "))
-		} else if line == "" && !inFileList {
+		} else if line == "" {
 			// Empty line before commit message
 			fmt.Println()
-		} else if !inFileList && !strings.HasPrefix(line, "commit ") && 
-				  !strings.HasPrefix(line, "Author") && 
-				  !strings.HasPrefix(line, "Commit") && 
-				  !strings.HasPrefix(line, "    ") && 
-				  line != "" {
-			// This is likely the start of file status
-			inFileList = true
-			fmt.Println()
-			color.Cyan("Changed Files:")
-			formatFileStatus(line)
-		} else if inFileList {
-			if line == "" {
-				continue
-			}
-			formatFileStatus(line)
 		} else if strings.HasPrefix(line, "    ") {
 			// Commit message (indented)
 			message := strings.TrimPrefix(line, "    ")
-			if message != "" {
-				color.Green("Message:   %s", message)
+			if message != "" && !core.IsProvenanceTrailer(message) {
+				ui.Success("Message:   %s", message)
 				fmt.Println()
 			}
 		}
 	}
+
+	showProvenance(provenance)
+
+	if len(changes) > 0 {
+		fmt.Println()
+		ui.Heading("Changed Files:")
+		for _, change := range changes {
+			formatFileChange(change)
+		}
+		showChangeSummary(changes)
+	}
 	
 	fmt.Println()
-	fmt.Printf("Use 'timemachine restore %s' to restore this snapshot\n", hash)
+	fmt.Printf("Use 'timemachine restore %s' to restore this snapshot\n", core.ShortHash(hash))
 
 	return nil
 }
 
-// formatFileStatus formats the file status output from git show --name-status
-func formatFileStatus(line string) {
-	if line == "" {
+// showProvenance prints where a snapshot was taken and its signature, when
+// git.provenance or git.signing_key recorded them
+func showProvenance(provenance *core.Provenance) {
+	if provenance.Host == "" && provenance.Tool == "" && provenance.Session == "" && provenance.Agents == "" && provenance.Signature == core.SignatureNone {
 		return
 	}
-	
-	parts := strings.Fields(line)
-	if len(parts) < 2 {
-		return
+	fmt.Println()
+	ui.Heading("Provenance:")
+	if provenance.Host != "" {
+		fmt.Printf("  Host:      %s\n", provenance.Host)
 	}
-	
-	status := parts[0]
-	filename := strings.Join(parts[1:], " ")
-	
-	switch status {
+	if provenance.Tool != "" {
+		fmt.Printf("  Tool:      %s\n", provenance.Tool)
+	}
+	if provenance.Session != "" {
+		fmt.Printf("  Session:   %s\n", provenance.Session)
+	}
+	if provenance.Agents != "" {
+		fmt.Printf("  Agents:    %s\n", provenance.Agents)
+	}
+
+	signature := provenance.Signature
+	if provenance.Signer != "" {
+		signature += " by " + provenance.Signer
+	}
+	if provenance.Key != "" {
+		signature += " (" + provenance.Key + ")"
+	}
+	switch provenance.Signature {
+	case core.SignatureGood:
+		ui.Success("  Signature: %s", signature)
+	case core.SignatureNone:
+		fmt.Printf("  Signature: %s\n", signature)
+	case core.SignatureBad:
+		ui.Error("  Signature: %s", signature)
+	default:
+		ui.Warning("  Signature: %s", signature)
+	}
+}
+
+// formatFileChange prints one changed file with its line counts
+func formatFileChange(change core.FileChange) {
+	counts := fmt.Sprintf("+%d -%d", change.Insertions, change.Deletions)
+	if change.Binary {
+		counts = "binary"
+	}
+
+	switch change.Status {
 	case "A":
-		color.Green("  + %s (added)", filename)
+		ui.Line(ui.RoleAdded, "  + %s (added, %s)", change.Path, counts)
 	case "M":
-		color.Yellow("  ~ %s (modified)", filename)
+		ui.Line(ui.RoleModified, "  ~ %s (modified, %s)", change.Path, counts)
 	case "D":
-		color.Red("  - %s (deleted)", filename)
+		ui.Line(ui.RoleDeleted, "  - %s (deleted, %s)", change.Path, counts)
 	case "R":
-		if len(parts) >= 3 {
-			color.Blue("  → %s → %s (renamed)", parts[1], parts[2])
-		}
+		ui.Line(ui.RoleRenamed, "  → %s → %s (renamed, %d%% similar, %s)", change.OldPath, change.Path, change.Similarity, counts)
 	case "C":
-		if len(parts) >= 3 {
-			color.Cyan("  ≈ %s → %s (copied)", parts[1], parts[2])
-		}
+		ui.Line(ui.RoleRenamed, "  ≈ %s → %s (copied, %d%% similar, %s)", change.OldPath, change.Path, change.Similarity, counts)
 	default:
-		fmt.Printf("  %s %s\n", status, filename)
+		fmt.Printf("  %s %s (%s)\n", change.Status, change.Path, counts)
+	}
+}
+
+// maxSummaryGroups caps the directories and file types summarized
+const maxSummaryGroups = 5
+
+// showChangeSummary totals changes and, across several files, sums them by
+// directory and file type
+func showChangeSummary(changes []core.FileChange) {
+	insertions, deletions := core.SnapshotChanges{Files: changes}.Totals()
+	fmt.Printf("\n%d file(s) changed, %s, %s\n", len(changes),
+		ui.Sprint(ui.RoleAdded, fmt.Sprintf("+%d", insertions)), ui.Sprint(ui.RoleDeleted, fmt.Sprintf("-%d", deletions)))
+	if len(changes) < 2 {
+		return
+	}
+
+	byDirectory, byType := core.GroupChanges(changes)
+	for _, section := range []struct {
+		title  string
+		groups []core.ChangeGroup
+	}{{"By directory:", byDirectory}, {"By file type:", byType}} {
+		fmt.Println()
+		ui.Heading(section.title)
+		for i, group := range section.groups {
+			if i == maxSummaryGroups {
+				fmt.Printf("  … and %d more\n", len(section.groups)-i)
+				break
+			}
+			fmt.Printf("  %-30s %3d file(s)  %s %s\n", group.Name, group.Files,
+				ui.Sprint(ui.RoleAdded, fmt.Sprintf("+%d", group.Insertions)), ui.Sprint(ui.RoleDeleted, fmt.Sprintf("-%d", group.Deletions)))
+		}
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,142 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/core"
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/ui"
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+// sizeGrowthDays is how many of the most recent days the growth table shows
+const sizeGrowthDays = 14
+
+// SizeCmd creates the size command
+func SizeCmd() *cobra.Command {
+	var (
+		top    int
+		format string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "size",
+		Short: "Show where snapshot storage goes",
+		Long: `Report the disk usage of the snapshot repository: the files whose versions
+take the most space, the snapshots that added the most, and growth per day.
+
+Every stored version of a file is charged to the first snapshot that
+stored it; later snapshots that reuse it cost nothing. Sizes are on disk,
+after compression, and leave out directory listings and commit records,
+so they are approximate.
+
+Examples:
+  timemachine size                # Top 10 files and snapshots
+  timemachine size --top 20
+  timemachine size --top 0        # Everything
+  timemachine size --format json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runSize(os.Stdout, top, format)
+		},
+	}
+
+	cmd.Flags().IntVar(&top, "top", 10, "Number of files and snapshots to list (0 for all)")
+	cmd.Flags().StringVar(&format, "format", "text", "Output format (text, json)")
+
+	return cmd
+}
+
+// sizeOutput is the JSON output of the size command
+type sizeOutput struct {
+	RepositorySizeBytes int64               `json:"repository_size_bytes"`
+	Snapshots           int                 `json:"snapshots"`
+	Blobs               int                 `json:"blobs"`
+	DiskBytes           int64               `json:"disk_bytes"`
+	LargestFiles        []core.FileSize     `json:"largest_files"`
+	LargestSnapshots    []core.SnapshotSize `json:"largest_snapshots"`
+	Growth              []core.SizeGrowth   `json:"growth"`
+}
+
+func runSize(out io.Writer, top int, format string) error {
+	if format != "text" && format != "json" {
+		return fmt.Errorf("unsupported format: %s (use 'text' or 'json')", format)
+	}
+	if top < 0 {
+		return fmt.Errorf("--top must not be negative")
+	}
+
+	state, err := loadInitializedState()
+	if err != nil || state == nil {
+		return err
+	}
+
+	gitManager := core.NewGitManager(state)
+	report, err := gitManager.SizeReport()
+	if err != nil {
+		return err
+	}
+	repoSize, err := core.NewStatsCollector(gitManager).DiskUsage()
+	if err != nil {
+		return fmt.Errorf("failed to measure snapshot repository: %w", err)
+	}
+
+	if format == "json" {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(sizeOutput{
+			RepositorySizeBytes: repoSize,
+			Snapshots:           len(report.Snapshots),
+			Blobs:               report.Blobs,
+			DiskBytes:           report.DiskBytes,
+			LargestFiles:        report.LargestFiles(top),
+			LargestSnapshots:    report.LargestSnapshots(top),
+			Growth:              report.Growth,
+		})
+	}
+
+	heading := ui.Color(ui.RoleHeading)
+	heading.Fprintln(out, "🗄️  Snapshot storage")
+	fmt.Fprintf(out, "   Repository on disk: %s\n", utils.FormatBytes(repoSize))
+	fmt.Fprintf(out, "   File contents:      %s in %d version(s) across %d snapshot(s)\n",
+		utils.FormatBytes(report.DiskBytes), report.Blobs, len(report.Snapshots))
+	if len(report.Snapshots) == 0 {
+		return nil
+	}
+
+	fmt.Fprintln(out)
+	heading.Fprintln(out, "📦 Largest files")
+	fmt.Fprintf(out, "   %10s  %8s  %10s  %s\n", "ON DISK", "VERSIONS", "LARGEST", "PATH")
+	for _, file := range report.LargestFiles(top) {
+		fmt.Fprintf(out, "   %10s  %8d  %10s  %s\n",
+			utils.FormatBytes(file.DiskBytes), file.Versions, utils.FormatBytes(file.Bytes), file.Path)
+	}
+
+	fmt.Fprintln(out)
+	heading.Fprintln(out, "📸 Snapshots that added the most")
+	fmt.Fprintf(out, "   %-8s  %-16s  %10s  %5s  %s\n", "SNAPSHOT", "WHEN", "ADDED", "FILES", "MESSAGE")
+	for _, snapshot := range report.LargestSnapshots(top) {
+		fmt.Fprintf(out, "   %s  %-16s  %10s  %5d  %s\n",
+			ui.Sprint(ui.RoleHash, snapshot.Hash[:8]),
+			snapshot.Time.Local().Format("2006-01-02 15:04"),
+			utils.FormatBytes(snapshot.DiskBytes), snapshot.NewBlobs,
+			utils.TruncateString(snapshot.Message, 50))
+	}
+
+	growth := report.Growth
+	if len(growth) > sizeGrowthDays {
+		growth = growth[len(growth)-sizeGrowthDays:]
+	}
+	fmt.Fprintln(out)
+	heading.Fprintln(out, "📈 Growth per day")
+	fmt.Fprintf(out, "   %-10s  %9s  %10s  %10s\n", "DAY", "SNAPSHOTS", "ADDED", "TOTAL")
+	for _, day := range growth {
+		fmt.Fprintf(out, "   %-10s  %9d  %10s  %10s\n", day.Day.Format("2006-01-02"), day.Snapshots,
+			utils.FormatBytes(day.DiskBytes), utils.FormatBytes(day.Total))
+	}
+
+	return nil
+}
@@ -0,0 +1,23 @@
+package commands
+
+import (
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/core"
+	"github.com/spf13/cobra"
+)
+
+// addSnapshotsAgoFlag adds -n, naming a snapshot by how many came after it
+func addSnapshotsAgoFlag(cmd *cobra.Command, ago *int) {
+	cmd.Flags().IntVarP(ago, "ago", "n", 0, "Use the snapshot N before the latest instead of a hash (0 is the latest)")
+}
+
+// snapshotsAgoArgs puts the snapshot -n names in front of args, when -n is
+// given, so every argument after it is read as usual
+func snapshotsAgoArgs(cmd *cobra.Command, ago int, args []string) ([]string, error) {
+	if !cmd.Flags().Changed("ago") {
+		return args, nil
+	}
+	if ago < 0 {
+		return nil, core.NewValidationError("-n must not be negative")
+	}
+	return append([]string{core.SnapshotsAgoRef(ago)}, args...), nil
+}
@@ -1,35 +1,151 @@
 package commands
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"sync"
 	"syscall"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/api"
 	"github.com/deepakkumarnarayana/timemachine-cli/internal/core"
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/ui"
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/daemon"
 )
 
+// stateRefreshInterval is how often a running watcher republishes its
+// activity between snapshots
+const stateRefreshInterval = 15 * time.Second
+
 // StartCmd creates the start command
 func StartCmd() *cobra.Command {
-	return &cobra.Command{
+	var (
+		background  bool
+		daemonChild bool
+		faultSpec   string
+		porcelain   bool
+		noCatchUp   bool
+		paths       []string
+		ignores     []string
+	)
+
+	cmd := &cobra.Command{
 		Use:   "start",
 		Short: "Start watching for file changes and creating automatic snapshots",
 		Long: `Start the Time Machine file watcher to automatically create snapshots
-when files change. This runs in the foreground and will continue until
-you press Ctrl+C.
+when files change. By default this runs in the foreground and will continue
+until you press Ctrl+C.
+
+Use --daemon to run the watcher as a background process instead. The PID is
+recorded in .git/timemachine_snapshots/daemon.pid and output is written to
+.git/timemachine_snapshots/daemon.log. Stop it with 'timemachine stop'.
+
+Set api.enabled to serve a local HTTP API for editor extensions while the
+watcher runs. Its address and access token are written to
+.git/timemachine_snapshots/api.json, readable only by you.
+
+Use --porcelain to stream the watcher's events to stdout, one JSON object per
+line, for editor plugins and scripts; everything else is printed to stderr.
+Each event has a "type" and a "time":
+
+  snapshot_created  hash, message, files
+  branch_changed    action (checkout, renamed, pruned), branch, from
+  files_ignored     files, count (changes to ignored paths, once a second)
+  error             source (snapshot, watcher, branches, integrity, heal), message
+
+The format is stable: fields and event types are only ever added.
+
+Use --path (repeatable) to watch and snapshot only some directories, such as
+one service of a monorepo; it overrides watcher.include_paths. Changes
+elsewhere are neither noticed nor recorded.
+
+Use --ignore (repeatable) to ignore more paths for this run, on top of
+.timemachine-ignore. Ignore patterns are applied in this order, a later
+source winning ("!pattern" re-includes): .gitignore (watcher.respect_gitignore),
+watcher.ignore_patterns, .timemachine-ignore, .timemachine-ignore files in
+subdirectories, TIMEMACHINE_WATCHER_IGNORE (comma-separated), then --ignore.
+'timemachine status --verbose' shows how many patterns each contributes.
+
+On start, the changes made since the last snapshot, while nothing was
+watching, are recorded right away in a "Catch-up snapshot". Use --no-catchup
+to leave them until they are edited again, or the periodic full rescan.
+
+Send the watcher SIGHUP, or run 'timemachine config reload', to apply
+configuration changes without restarting it.
 
 The watcher:
-- Monitors all files in the project recursively
+- Monitors all files in the project recursively, or under --path
 - Ignores common build/cache directories (node_modules, dist, .git, etc.)
 - Groups rapid changes together to prevent snapshot spam
-- Creates snapshots with 500ms debounce delay`,
-		RunE: runStart,
+- Creates snapshots after the configured debounce delay (default 2s)`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// A watcher already running is no usage mistake
+			cmd.SilenceUsage = true
+			if cmd.Flags().Changed("fault-inject") {
+				injector, err := core.EnableFaultInjection(faultSpec)
+				if err != nil {
+					return err
+				}
+				color.Magenta("🧪 Fault injection enabled: %s", injector)
+			}
+			if background {
+				if porcelain {
+					return fmt.Errorf("--porcelain streams events to the terminal and can't be used with --daemon")
+				}
+				var childArgs []string
+				if cmd.Flags().Changed("fault-inject") {
+					childArgs = append(childArgs, "--fault-inject="+faultSpec)
+				}
+				if noCatchUp {
+					childArgs = append(childArgs, "--no-catchup")
+				}
+				// The background process doesn't share our directory
+				for _, pattern := range ignores {
+					childArgs = append(childArgs, "--ignore="+pattern)
+				}
+				for _, p := range paths {
+					abs, err := filepath.Abs(p)
+					if err != nil {
+						return err
+					}
+					childArgs = append(childArgs, "--path="+abs)
+				}
+				return runStartDaemon(childArgs...)
+			}
+			return runStart(daemonChild, porcelain, noCatchUp, paths, ignores, cmd.Root().Version)
+		},
 	}
+
+	cmd.Flags().BoolVarP(&background, "daemon", "d", false, "Run the watcher in the background")
+	cmd.Flags().BoolVar(&porcelain, "porcelain", false, "Stream watcher events to stdout as JSON lines, for editor plugins and scripts")
+	cmd.Flags().BoolVar(&noCatchUp, "no-catchup", false, "Don't snapshot the changes made while the watcher was stopped right away")
+	cmd.Flags().StringArrayVar(&ignores, "ignore", nil, "Also ignore paths matching this pattern (repeatable)")
+	cmd.Flags().StringSliceVar(&paths, "path", nil, "Only watch and snapshot this directory (repeatable)")
+	cmd.Flags().BoolVar(&daemonChild, daemon.ChildFlag[2:], false, "Internal: run as the background watcher process")
+	cmd.Flags().MarkHidden(daemon.ChildFlag[2:])
+	cmd.Flags().StringVar(&faultSpec, "fault-inject", "", "Internal: inject random faults for robustness testing (requires "+core.FaultInjectionEnv+"=1)")
+	cmd.Flags().MarkHidden("fault-inject")
+
+	return cmd
 }
 
-func runStart(cmd *cobra.Command, args []string) error {
+func runStart(daemonChild, porcelain, noCatchUp bool, paths, ignores []string, version string) error {
+	// stdout carries the events alone: anything else printed, including by
+	// hooks and warnings, goes to stderr
+	events := os.Stdout
+	if porcelain {
+		os.Stdout = os.Stderr
+		color.Output = os.Stderr
+		defer func() { os.Stdout = events }()
+	}
+
 	// Create application state
 	state, err := core.NewAppState()
 	if err != nil {
@@ -38,9 +154,58 @@ func runStart(cmd *cobra.Command, args []string) error {
 
 	// Check if initialized
 	if !state.IsInitialized {
-		color.Red("❌ Time Machine is not initialized!")
-		fmt.Println("Run 'timemachine init' to get started.")
-		return nil
+		ui.Error("%s", ui.T(ui.MsgNotInitialized))
+		fmt.Println(ui.T(ui.MsgRunInit))
+		return core.Reported(core.ErrNotInitialized)
+	}
+
+	if len(paths) > 0 {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get current directory: %w", err)
+		}
+		includes, err := core.ResolveIncludePaths(state.ProjectRoot, cwd, paths)
+		if err != nil {
+			return fmt.Errorf("invalid --path: %w", err)
+		}
+		state.IncludePaths = includes
+	}
+	state.IgnoreFlags = ignores
+
+	// Refuse to run a second watcher alongside a background one
+	daemonManager := daemon.NewManager(state)
+	if !daemonChild {
+		status, err := daemonManager.Status()
+		if err != nil {
+			return fmt.Errorf("failed to check daemon status: %w", err)
+		}
+		if status.Running {
+			color.Yellow("⚠️  Time Machine is already running in the background (PID %d)", status.PID)
+			fmt.Println("   Run 'timemachine stop' to stop it first.")
+			return &daemon.LockedError{PID: status.PID}
+		}
+	}
+
+	// Only one watcher per repository: two would interleave snapshots and
+	// branch switches
+	lock, err := daemonManager.AcquireWatcherLock()
+	var locked *daemon.LockedError
+	if errors.As(err, &locked) {
+		if status, _ := daemonManager.Status(); status != nil && status.Running {
+			color.Yellow("⚠️  It runs in the background: see 'timemachine daemon status', or 'timemachine stop' it first.")
+		} else {
+			color.Yellow("⚠️  It runs in the foreground, in another terminal: stop it with Ctrl+C first.")
+		}
+		return locked
+	}
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
+	// Explain up front what works differently in this environment
+	for _, limitation := range state.Capabilities().Limitations() {
+		color.Yellow("⚠️  %s", limitation)
 	}
 
 	// Create Git manager
@@ -52,28 +217,155 @@ func runStart(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to create watcher: %w", err)
 	}
 
+	if porcelain {
+		watcher.SetEventHandler(porcelainEvents(events))
+	}
+	watcher.SetCatchUp(!noCatchUp)
+
+	// Keep the cached watcher state fresh for cheap readers (timemachine prompt)
+	watcher.SetSnapshotHandler(func(snapshotErr error) {
+		stats := watcher.Stats()
+		if err := daemonManager.RefreshState(gitManager, &stats, snapshotErr); err != nil {
+			fmt.Printf("Warning: failed to update watcher state: %v\n", err)
+		}
+	})
+
+	// Optional Prometheus endpoint, e.g. for shared dev VMs
+	if state.Config != nil && state.Config.Metrics.Listen != "" {
+		server, err := watcher.ServeMetrics(state.Config.Metrics.Listen)
+		if err != nil {
+			return err
+		}
+		defer core.StopMetrics(server)
+		fmt.Printf("📈 Serving metrics at http://%s/metrics\n", state.Config.Metrics.Listen)
+	}
+
+	// Optional local API for editor extensions
+	if state.Config != nil && state.Config.API.Enabled {
+		server, err := api.NewServer(gitManager, version)
+		if err != nil {
+			return err
+		}
+		endpoint, err := server.Start(state.Config.API.Listen)
+		if err != nil {
+			return err
+		}
+		defer server.Stop()
+		fmt.Printf("🔌 Serving the API at %s (token in %s)\n", endpoint.URL, api.EndpointFile(state))
+	}
+
 	// Setup signal handler for graceful shutdown
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	reloadChan := make(chan os.Signal, 1)
+	signal.Notify(reloadChan, syscall.SIGHUP)
 
 	// Start watcher in goroutine
 	errChan := make(chan error, 1)
+	startedChan := make(chan struct{})
 	go func() {
 		if err := watcher.Start(); err != nil {
 			errChan <- err
+			return
 		}
+		close(startedChan)
 	}()
 
+	// Refreshes watcher activity for 'timemachine status' once started
+	var refreshChan <-chan time.Time
+
 	// Wait for signal or error
-	select {
-	case sig := <-sigChan:
-		fmt.Printf("\n🛑 Received %v signal, stopping watcher...\n", sig)
-		watcher.Stop()
-		fmt.Println("✅ Time Machine stopped gracefully")
-		return nil
-		
-	case err := <-errChan:
-		watcher.Stop()
-		return fmt.Errorf("watcher error: %w", err)
-	}
-}
\ No newline at end of file
+	for {
+		select {
+		case <-startedChan:
+			startedChan = nil
+			stats := watcher.Stats()
+			if err := daemonManager.RefreshState(gitManager, &stats, nil); err != nil {
+				fmt.Printf("Warning: failed to update watcher state: %v\n", err)
+			}
+			ticker := time.NewTicker(stateRefreshInterval)
+			defer ticker.Stop()
+			refreshChan = ticker.C
+			if daemonChild {
+				// Publishing the PID file tells the parent we started successfully
+				if err := daemonManager.WritePIDFile(); err != nil {
+					watcher.Stop()
+					return err
+				}
+				defer daemonManager.RemovePIDFile()
+			}
+
+		case <-refreshChan:
+			stats := watcher.Stats()
+			if err := daemonManager.RefreshActivity(gitManager, &stats); err != nil {
+				fmt.Printf("Warning: failed to update watcher state: %v\n", err)
+			}
+
+		case <-reloadChan:
+			if startedChan != nil {
+				fmt.Println("Ignoring configuration reload: the watcher is still starting")
+				continue
+			}
+			watcher.ReloadConfig()
+			stats := watcher.Stats()
+			if err := daemonManager.RefreshActivity(gitManager, &stats); err != nil {
+				fmt.Printf("Warning: failed to update watcher state: %v\n", err)
+			}
+
+		case sig := <-sigChan:
+			fmt.Printf("\n🛑 Received %v signal, stopping watcher...\n", sig)
+			watcher.Stop()
+			fmt.Println("✅ Time Machine stopped gracefully")
+			return nil
+
+		case err := <-errChan:
+			watcher.Stop()
+			return fmt.Errorf("watcher error: %w", err)
+		}
+	}
+}
+
+// porcelainEvents writes watcher events to out as JSON lines. The watcher
+// emits them from several goroutines, so writes are serialized.
+func porcelainEvents(out io.Writer) func(core.WatcherEvent) {
+	var mu sync.Mutex
+	encoder := json.NewEncoder(out)
+	return func(event core.WatcherEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		encoder.Encode(event)
+	}
+}
+
+func runStartDaemon(childArgs ...string) error {
+	// Create application state
+	state, err := core.NewAppState()
+	if err != nil {
+		return fmt.Errorf("failed to initialize app state: %w", err)
+	}
+
+	// Check if initialized
+	if !state.IsInitialized {
+		ui.Error("%s", ui.T(ui.MsgNotInitialized))
+		fmt.Println(ui.T(ui.MsgRunInit))
+		return core.Reported(core.ErrNotInitialized)
+	}
+
+	daemonManager := daemon.NewManager(state)
+
+	fmt.Print("🚀 Starting Time Machine in the background... ")
+	pid, err := daemonManager.Start(childArgs...)
+	if err != nil {
+		color.Red("❌")
+		return fmt.Errorf("failed to start daemon: %w", err)
+	}
+	color.Green("✅")
+
+	fmt.Printf("   PID: %d\n", pid)
+	fmt.Printf("   Log: %s\n", daemonManager.LogFile())
+	fmt.Println()
+	fmt.Println("Use 'timemachine daemon status' to check on it")
+	fmt.Println("Use 'timemachine stop' to stop it")
+
+	return nil
+}
@@ -0,0 +1,176 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/core"
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/render"
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// StatsCmd creates the stats command
+func StatsCmd() *cobra.Command {
+	var (
+		format  string
+		reset   bool
+		send    bool
+		graph   bool
+		period  string
+		periods int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Show local usage statistics and storage growth",
+		Long: `Show the usage statistics recorded while telemetry.enabled is set: how often
+each command ran and failed, snapshot latencies and intervals, and errors by
+category. Use them to tune watcher.debounce_delay and retention.
+
+Statistics stay in .git/timemachine_snapshots. They hold counts, durations
+and error categories only: no paths, arguments or file contents. Nothing is
+sent anywhere unless telemetry.endpoint is set and you run 'stats --send'.
+
+With --graph, chart the snapshots taken and the storage they added per day
+or week instead, from the snapshot history; telemetry isn't needed. Sizes
+are on disk, charged to the first snapshot storing each file version, as
+in 'timemachine size'.
+
+Examples:
+  timemachine stats
+  timemachine stats --format json
+  timemachine stats --send        # Post the summary to telemetry.endpoint
+  timemachine stats --reset       # Delete the recorded statistics
+  timemachine stats --graph                    # The last 30 days
+  timemachine stats --graph --period week --periods 0
+  timemachine stats --graph --format csv > growth.csv`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if graph {
+				return runStatsGraph(os.Stdout, format, period, periods, render.TerminalWidth(os.Stdout))
+			}
+			if cmd.Flags().Changed("period") || cmd.Flags().Changed("periods") {
+				return core.NewValidationError("--period and --periods need --graph")
+			}
+			return runStats(os.Stdout, format, reset, send)
+		},
+	}
+
+	cmd.Flags().StringVar(&format, "format", "text", "Output format (text, json; csv with --graph)")
+	cmd.Flags().BoolVar(&reset, "reset", false, "Delete the recorded statistics")
+	cmd.Flags().BoolVar(&send, "send", false, "Post the summary to telemetry.endpoint")
+	cmd.Flags().BoolVar(&graph, "graph", false, "Chart snapshots and storage growth over time")
+	cmd.Flags().StringVar(&period, "period", core.GrowthPerDay, "Period of the chart (day, week)")
+	cmd.Flags().IntVar(&periods, "periods", 30, "Number of latest periods to chart (0 for all)")
+	cmd.MarkFlagsMutuallyExclusive("reset", "send", "graph")
+
+	return cmd
+}
+
+func runStats(out io.Writer, format string, reset, send bool) error {
+	if format != "text" && format != "json" {
+		return core.NewValidationError("unsupported format: %s (use 'text' or 'json')", format)
+	}
+
+	state, err := loadInitializedState()
+	if err != nil || state == nil {
+		return err
+	}
+
+	if reset {
+		if err := core.ResetTelemetry(state.ShadowRepoDir); err != nil {
+			return err
+		}
+		ui.Success("✅ Usage statistics deleted")
+		return nil
+	}
+
+	stats, err := core.ReadUsageStats(state.ShadowRepoDir)
+	if err != nil {
+		return err
+	}
+
+	if send {
+		if err := core.SendUsageStats(state.Config.Telemetry, stats); err != nil {
+			return err
+		}
+		ui.Success("✅ Sent usage statistics (%d events) to %s", stats.Events, state.Config.Telemetry.Endpoint)
+		return nil
+	}
+
+	if format == "json" {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(stats)
+	}
+
+	if !state.Config.Telemetry.Enabled {
+		ui.Warning("⚠️  Telemetry is off; set telemetry.enabled: true in timemachine.yaml to record usage")
+	}
+	if stats.Events == 0 {
+		fmt.Fprintln(out, "No usage statistics recorded yet.")
+		return nil
+	}
+	printUsageStats(out, stats, state.Config.Watcher.DebounceDelay)
+	return nil
+}
+
+// printUsageStats writes the text report of stats. debounce is the current
+// watcher.debounce_delay, shown next to the snapshot interval it drives.
+func printUsageStats(out io.Writer, stats *core.UsageStats, debounce time.Duration) {
+	heading := ui.Color(ui.RoleHeading)
+	heading.Fprintf(out, "📊 Usage since %s (%d events)\n", stats.Since.Format("2006-01-02 15:04"), stats.Events)
+
+	if len(stats.Commands) > 0 {
+		fmt.Fprintln(out)
+		heading.Fprintln(out, "⌨️  Commands")
+		fmt.Fprintf(out, "   %6s  %6s  %s\n", "RUNS", "FAILED", "COMMAND")
+		for _, command := range stats.Commands {
+			fmt.Fprintf(out, "   %6d  %6d  %s\n", command.Count, command.Failures, command.Name)
+		}
+	}
+
+	snapshots := stats.Snapshots
+	if snapshots.Count+snapshots.Failures > 0 {
+		fmt.Fprintln(out)
+		heading.Fprintln(out, "📸 Snapshots")
+		fmt.Fprintf(out, "   Created: %d, failed: %d (%.1f per day)\n", snapshots.Count, snapshots.Failures, snapshots.PerDay)
+		if snapshots.Count > 0 {
+			fmt.Fprintf(out, "   Latency: p50 %s, p95 %s, max %s\n",
+				milliseconds(snapshots.LatencyP50MS), milliseconds(snapshots.LatencyP95MS), milliseconds(snapshots.LatencyMaxMS))
+			fmt.Fprintf(out, "   Files per snapshot: %.1f on average\n", snapshots.AverageFiles)
+		}
+		if snapshots.IntervalMS > 0 {
+			fmt.Fprintf(out, "   Interval: %s median between snapshots (watcher.debounce_delay: %s)\n",
+				milliseconds(snapshots.IntervalMS), debounce)
+		}
+	}
+
+	if len(stats.Errors) > 0 {
+		fmt.Fprintln(out)
+		heading.Fprintln(out, "❌ Errors")
+		categories := make([]string, 0, len(stats.Errors))
+		for category := range stats.Errors {
+			categories = append(categories, category)
+		}
+		sort.Strings(categories)
+		for _, category := range categories {
+			fmt.Fprintf(out, "   %-20s %d\n", category, stats.Errors[category])
+		}
+	}
+}
+
+// milliseconds converts a duration in milliseconds for display, in tenths
+// of a second from one second up
+func milliseconds(ms int64) time.Duration {
+	d := time.Duration(ms) * time.Millisecond
+	if d < time.Second {
+		return d
+	}
+	return d.Round(100 * time.Millisecond)
+}
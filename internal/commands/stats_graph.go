@@ -0,0 +1,159 @@
+package commands
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/core"
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/ui"
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/utils"
+)
+
+// sparkLevels draw a value from the smallest nonzero one up to the largest;
+// zero is a space
+var sparkLevels = []rune("▁▂▃▄▅▆▇█")
+
+// barEighths draw the fraction of a bar's last cell
+var barEighths = []rune(" ▏▎▍▌▋▊▉")
+
+const (
+	graphLabelWidth = 50 // Columns of the table before the bars
+	graphMinBar     = 10
+	graphMaxBar     = 40
+)
+
+// sparkline draws values as one cell each, scaled to the largest
+func sparkline(values []int64) string {
+	var largest int64
+	for _, value := range values {
+		largest = max(largest, value)
+	}
+	var line strings.Builder
+	for _, value := range values {
+		if value <= 0 {
+			line.WriteRune(' ')
+			continue
+		}
+		level := int((value*int64(len(sparkLevels)) - 1) / largest)
+		line.WriteRune(sparkLevels[min(level, len(sparkLevels)-1)])
+	}
+	return line.String()
+}
+
+// bar draws value as a horizontal bar of up to width cells, in eighths of
+// a cell
+func bar(value, largest int64, width int) string {
+	if value <= 0 || largest <= 0 {
+		return ""
+	}
+	eighths := int(value * int64(width*8) / largest)
+	eighths = max(eighths, 1) // Something was added, however little
+	return strings.Repeat("█", eighths/8) + strings.TrimSpace(string(barEighths[eighths%8]))
+}
+
+// runStatsGraph charts snapshot counts and storage growth per day or week,
+// the last periods of them (every one for 0)
+func runStatsGraph(out io.Writer, format, period string, periods, width int) error {
+	if format != "text" && format != "json" && format != "csv" {
+		return core.NewValidationError("unsupported format: %s (use 'text', 'json' or 'csv')", format)
+	}
+	if periods < 0 {
+		return core.NewValidationError("--periods must not be negative")
+	}
+
+	state, err := loadInitializedState()
+	if err != nil || state == nil {
+		return err
+	}
+	report, err := core.NewGitManager(state).SizeReport()
+	if err != nil {
+		return err
+	}
+	series, err := report.GrowthSeries(period, time.Now())
+	if err != nil {
+		return err
+	}
+	if periods > 0 && len(series) > periods {
+		series = series[len(series)-periods:]
+	}
+
+	switch format {
+	case "json":
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		if series == nil {
+			series = []core.SizeGrowth{}
+		}
+		return encoder.Encode(series)
+	case "csv":
+		return writeGrowthCSV(out, period, series)
+	}
+
+	if len(series) == 0 {
+		fmt.Fprintln(out, "No snapshots yet.")
+		return nil
+	}
+	printGrowthGraph(out, period, series, width)
+	return nil
+}
+
+// writeGrowthCSV writes the series for spreadsheets, sizes in bytes
+func writeGrowthCSV(out io.Writer, period string, series []core.SizeGrowth) error {
+	writer := csv.NewWriter(out)
+	writer.Write([]string{period, "snapshots", "added_bytes", "total_bytes"})
+	for _, growth := range series {
+		writer.Write([]string{
+			growth.Day.Format("2006-01-02"),
+			strconv.Itoa(growth.Snapshots),
+			strconv.FormatInt(growth.DiskBytes, 10),
+			strconv.FormatInt(growth.Total, 10),
+		})
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// printGrowthGraph writes sparklines of the series, then a table with a
+// bar of the storage each period added
+func printGrowthGraph(out io.Writer, period string, series []core.SizeGrowth, width int) {
+	var snapshots, added, totals []int64
+	var snapshotSum, mostSnapshots, addedSum, mostAdded int64
+	for _, growth := range series {
+		snapshots = append(snapshots, int64(growth.Snapshots))
+		added = append(added, growth.DiskBytes)
+		totals = append(totals, growth.Total)
+		snapshotSum += int64(growth.Snapshots)
+		addedSum += growth.DiskBytes
+		mostSnapshots = max(mostSnapshots, int64(growth.Snapshots))
+		mostAdded = max(mostAdded, growth.DiskBytes)
+	}
+	// Sparklines longer than the terminal show the latest periods
+	if spark := width - 16; spark > 0 && len(series) > spark {
+		snapshots, added, totals = snapshots[len(snapshots)-spark:], added[len(added)-spark:], totals[len(totals)-spark:]
+	}
+
+	heading := ui.Color(ui.RoleHeading)
+	heading.Fprintf(out, "📈 Growth per %s, %s to %s\n", period,
+		series[0].Day.Format("2006-01-02"), series[len(series)-1].Day.Format("2006-01-02"))
+	accent := func(line string) string { return ui.Sprint(ui.RoleInfo, line) }
+	fmt.Fprintf(out, "   %-10s %s\n", "Snapshots", accent(sparkline(snapshots)))
+	fmt.Fprintf(out, "   %-10s %s\n", "Added", accent(sparkline(added)))
+	fmt.Fprintf(out, "   %-10s %s\n", "Total", accent(sparkline(totals)))
+	fmt.Fprintf(out, "   %d snapshot(s) adding %s; at most %d and %s a %s; %s in total\n",
+		snapshotSum, utils.FormatBytes(addedSum), mostSnapshots, utils.FormatBytes(mostAdded), period,
+		utils.FormatBytes(series[len(series)-1].Total))
+
+	barWidth := min(max(width-graphLabelWidth, graphMinBar), graphMaxBar)
+	fmt.Fprintln(out)
+	fmt.Fprintf(out, "   %-10s  %9s  %10s  %10s\n", strings.ToUpper(period), "SNAPSHOTS", "ADDED", "TOTAL")
+	for _, growth := range series {
+		fmt.Fprintf(out, "   %-10s  %9d  %10s  %10s  %s\n", growth.Day.Format("2006-01-02"), growth.Snapshots,
+			utils.FormatBytes(growth.DiskBytes), utils.FormatBytes(growth.Total),
+			ui.Sprint(ui.RoleAdded, bar(growth.DiskBytes, mostAdded, barWidth)))
+	}
+}
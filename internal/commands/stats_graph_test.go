@@ -0,0 +1,31 @@
+package commands
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/core"
+)
+
+func TestGrowthGraph(t *testing.T) {
+	if line := sparkline([]int64{0, 1, 4, 8}); line != " ▁▄█" {
+		t.Errorf("Unexpected sparkline %q", line)
+	}
+	if line := bar(3, 8, 4); line != "█▌" {
+		t.Errorf("Unexpected bar %q", line)
+	}
+	if line := bar(1, 1000, 4); line != "▏" {
+		t.Errorf("Expected the smallest bar for a little growth, got %q", line)
+	}
+
+	var out bytes.Buffer
+	day := time.Date(2025, 3, 3, 0, 0, 0, 0, time.Local)
+	series := []core.SizeGrowth{{Day: day, Snapshots: 2, DiskBytes: 150, Total: 150}}
+	if err := writeGrowthCSV(&out, core.GrowthPerDay, series); err != nil {
+		t.Fatal(err)
+	}
+	if expected := "day,snapshots,added_bytes,total_bytes\n2025-03-03,2,150,150\n"; out.String() != expected {
+		t.Errorf("Expected CSV %q, got %q", expected, out.String())
+	}
+}
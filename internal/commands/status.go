@@ -1,37 +1,56 @@
 package commands
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+	"time"
 
-	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 	"github.com/deepakkumarnarayana/timemachine-cli/internal/core"
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/daemon"
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/ui"
 	"github.com/deepakkumarnarayana/timemachine-cli/internal/utils"
 )
 
 // StatusCmd creates the status command
 func StatusCmd() *cobra.Command {
-	var verbose bool
+	var (
+		verbose bool
+		format  string
+	)
 
 	cmd := &cobra.Command{
 		Use:   "status",
 		Short: "Show Time Machine status and statistics",
 		Long: `Show the current status of Time Machine including:
 - Initialization status
-- Number of snapshots
+- Watcher health: PID, uptime, watched directories, pending changes and
+  ignore cache hit rate
+- Number of snapshots, per shadow branch, and when the last one was taken
 - Shadow repository size
 - Recent activity
 - Configuration details
 
-Use --verbose for detailed information including file counts and paths.`,
+Use --verbose for detailed information including file counts and paths.
+Use --format json for machine-readable output, e.g. for monitoring.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if format == "json" {
+				return runStatusJSON(os.Stdout)
+			}
+			if format != "text" {
+				return fmt.Errorf("unsupported format: %s (use 'text' or 'json')", format)
+			}
 			return runStatus(verbose)
 		},
 	}
 
 	cmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Show detailed information")
+	cmd.Flags().StringVar(&format, "format", "text", "Output format (text, json)")
 
 	return cmd
 }
@@ -40,10 +59,11 @@ func runStatus(verbose bool) error {
 	// Create application state
 	state, err := core.NewAppState()
 	if err != nil {
-		color.Red("❌ Error: %v", err)
+		ui.Error("❌ Error: %v", err)
 		fmt.Println()
 		showNotInGitRepo()
-		return nil
+		// Already shown; the exit code still tells scripts what went wrong
+		return core.Reported(err)
 	}
 
 	// Show header
@@ -56,34 +76,56 @@ func runStatus(verbose bool) error {
 		fmt.Printf("   Path: %s\n", state.ProjectRoot)
 		fmt.Printf("   Git: %s\n", state.GitDir)
 	}
+	if env := state.Environment(); env.Kind != "" || env.Poll || verbose {
+		fmt.Printf("🖥️  Environment: %s\n", env)
+		if env.Poll {
+			fmt.Println("   Changes aren't reported on this filesystem, so the watcher polls for them")
+		}
+	}
 
 	// Initialization status
 	if state.IsInitialized {
-		color.Green("✅ Status: Initialized and ready")
+		ui.Success("✅ Status: Initialized and ready")
 		if verbose {
 			fmt.Printf("   Shadow repository: %s\n", state.ShadowRepoDir)
 		}
 	} else {
-		color.Yellow("⚠️  Status: Not initialized")
+		ui.Warning("⚠️  Status: Not initialized")
 		fmt.Println("   Run 'timemachine init' to get started")
 		fmt.Println()
 		return nil
 	}
 
+	// Watcher health, as last published by the running watcher
+	fmt.Println()
+	watcher := collectWatcherStatus(state)
+	showWatcherStatus(watcher)
+	if verbose {
+		showIgnoreSources(watcher.IgnoreSources)
+	}
+
 	// Create Git manager for statistics
 	gitManager := core.NewGitManager(state)
 
 	// Get snapshot statistics
 	snapshots, err := gitManager.ListSnapshots(0, "")
 	if err != nil {
-		color.Red("❌ Error getting snapshots: %v", err)
+		ui.Error("❌ Error getting snapshots: %v", err)
 		return nil
 	}
 
 	fmt.Println()
 	fmt.Printf("📸 Snapshots: %d total\n", len(snapshots))
 
+	// Only worth a breakdown once there is more than one shadow branch
+	if counts, err := gitManager.BranchSnapshotCounts(); err == nil && (len(counts) > 1 || verbose) {
+		for _, branch := range sortedKeys(counts) {
+			fmt.Printf("   %s: %d\n", branch, counts[branch])
+		}
+	}
+
 	if len(snapshots) > 0 {
+		fmt.Printf("   Last snapshot: %s (%s)\n", snapshots[0].Time, snapshots[0].Timestamp.Local().Format("2006-01-02 15:04:05"))
 		// Show recent activity
 		recentSnapshots := snapshots
 		if len(snapshots) > 5 {
@@ -93,7 +135,7 @@ func runStatus(verbose bool) error {
 		fmt.Println("   Recent activity:")
 		for _, snapshot := range recentSnapshots {
 			fmt.Printf("   • %s  %s  %s\n", 
-				snapshot.Hash[:8], 
+				ui.Sprint(ui.RoleHash, snapshot.Hash[:8]),
 				utils.TruncateString(snapshot.Message, 35), 
 				snapshot.Time)
 		}
@@ -105,13 +147,20 @@ func runStatus(verbose bool) error {
 		fmt.Println("   No snapshots yet")
 	}
 
+	// Integrity alerts stay until a full check passes
+	fmt.Println()
+	showIntegrityStatus(gitManager)
+
 	// Shadow repository size
 	fmt.Println()
-	size, err := utils.CalculateDirectorySize(state.ShadowRepoDir)
+	stats, err := core.NewStatsCollector(gitManager).Collect()
 	if err != nil {
 		fmt.Printf("💾 Repository size: Unable to calculate (%v)\n", err)
 	} else {
-		fmt.Printf("💾 Repository size: %s\n", utils.FormatBytes(size))
+		fmt.Printf("💾 Repository size: %s (%d objects)\n", utils.FormatBytes(stats.DiskBytes), stats.Objects())
+	}
+	if usage, err := gitManager.QuotaUsage(); err == nil {
+		showQuota(usage)
 	}
 
 	// Configuration status
@@ -121,17 +170,20 @@ func runStatus(verbose bool) error {
 	// Check .gitignore
 	gitignorePath := filepath.Join(state.ProjectRoot, ".gitignore")
 	if hasTimeMachineInGitignore(gitignorePath) {
-		color.Green("   ✅ .gitignore updated")
+		ui.Success("   ✅ .gitignore updated")
 	} else {
-		color.Yellow("   ⚠️  .gitignore not updated")
+		ui.Warning("   ⚠️  .gitignore not updated")
 	}
 
-	// Check post-push hook
-	hookPath := filepath.Join(state.GitDir, "hooks", "post-push")
-	if hasTimeMachineHook(hookPath) {
-		color.Green("   ✅ Auto-cleanup hook installed")
+	// Check pre-push hook
+	hooksDir := core.CommonDir(state.GitDir)
+	if hasLegacyPostPushHook(hooksDir) {
+		ui.Warning("   ⚠️  Auto-cleanup hook is a post-push hook, which Git never runs")
+		fmt.Println("      Run 'timemachine init' to replace it with a pre-push hook")
+	} else if hasTimeMachineHook(filepath.Join(hooksDir, "hooks", "pre-push")) {
+		ui.Success("   ✅ Auto-cleanup hook installed")
 	} else {
-		color.Yellow("   ⚠️  Auto-cleanup hook not installed")
+		ui.Warning("   ⚠️  Auto-cleanup hook not installed")
 	}
 
 	// Show verbose details
@@ -151,6 +203,262 @@ func runStatus(verbose bool) error {
 	return nil
 }
 
+// statusReport is the JSON output of the status command
+type statusReport struct {
+	Project             string                `json:"project"`
+	Path                string                `json:"path"`
+	Initialized         bool                  `json:"initialized"`
+	Environment         core.Environment      `json:"environment"`
+	Watcher             watcherReport         `json:"watcher"`
+	Snapshots           snapshotReport        `json:"snapshots"`
+	RepositorySizeBytes int64                 `json:"repository_size_bytes"`
+	Storage             *core.RepositoryStats `json:"storage,omitempty"`
+	Quota               *core.QuotaUsage      `json:"quota,omitempty"`
+	Integrity           *core.IntegrityReport `json:"integrity,omitempty"`
+}
+
+// watcherReport describes the running watcher, if any
+type watcherReport struct {
+	Running              bool             `json:"running"`
+	Mode                 string           `json:"mode,omitempty"` // "daemon" or "foreground"
+	PID                  int              `json:"pid,omitempty"`
+	StartedAt            time.Time        `json:"started_at,omitempty"`
+	UptimeSeconds        int64            `json:"uptime_seconds"`
+	WatchedDirs          int              `json:"watched_dirs"`
+	WatchedFiles         int              `json:"watched_files"`
+	PolledDirs           int              `json:"polled_dirs"` // Polled instead of watched because of watch limits
+	PendingChanges       int              `json:"pending_changes"`
+	SnapshotPending      bool             `json:"snapshot_pending"`
+	IgnoreCacheHits      int64            `json:"ignore_cache_hits"`
+	IgnoreCacheMisses    int64            `json:"ignore_cache_misses"`
+	IgnoreCacheHitRate   float64          `json:"ignore_cache_hit_rate"`
+	IgnoreCacheEvictions int64            `json:"ignore_cache_evictions"`
+	LastGC               *core.GCRun      `json:"last_gc,omitempty"`
+	LastHeal             *core.ShadowHeal `json:"last_heal,omitempty"`  // Set once the shadow repository had to be re-created
+	UpdatedAt            time.Time        `json:"updated_at,omitempty"` // When the watcher last published these numbers
+	LastError            string           `json:"last_error,omitempty"`
+	IgnoreSources        []core.IgnoreSource `json:"ignore_sources,omitempty"` // In the order applied; later sources win
+}
+
+// snapshotReport summarizes the snapshots in the shadow repository
+type snapshotReport struct {
+	Total    int            `json:"total"`
+	ByBranch map[string]int `json:"by_branch"`
+	LastAt   time.Time      `json:"last_at,omitempty"`
+	LastHash string         `json:"last_hash,omitempty"`
+}
+
+// runStatusJSON prints the status as JSON
+func runStatusJSON(out io.Writer) error {
+	state, err := core.NewAppState()
+	if err != nil {
+		return err
+	}
+
+	report := statusReport{
+		Project:     filepath.Base(state.ProjectRoot),
+		Path:        state.ProjectRoot,
+		Initialized: state.IsInitialized,
+		Environment: state.Environment(),
+		Snapshots:   snapshotReport{ByBranch: map[string]int{}},
+	}
+
+	if state.IsInitialized {
+		report.Watcher = collectWatcherStatus(state)
+
+		gitManager := core.NewGitManager(state)
+		snapshots, err := gitManager.ListSnapshots(0, "")
+		if err != nil {
+			return fmt.Errorf("failed to list snapshots: %w", err)
+		}
+		report.Snapshots.Total = len(snapshots)
+		if len(snapshots) > 0 {
+			report.Snapshots.LastAt = snapshots[0].Timestamp.UTC()
+			report.Snapshots.LastHash = snapshots[0].Hash
+		}
+		if counts, err := gitManager.BranchSnapshotCounts(); err == nil {
+			report.Snapshots.ByBranch = counts
+		}
+		if stats, err := core.NewStatsCollector(gitManager).Collect(); err == nil {
+			report.RepositorySizeBytes = stats.DiskBytes
+			report.Storage = stats
+		}
+		if usage, err := gitManager.QuotaUsage(); err == nil {
+			report.Quota = &usage
+		}
+		if integrity, err := gitManager.ReadIntegrityReport(); err == nil {
+			report.Integrity = integrity
+		}
+	}
+
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
+
+// collectWatcherStatus finds the running watcher, in the background or in
+// the foreground, and reads the activity it last published
+func collectWatcherStatus(state *core.AppState) watcherReport {
+	var report watcherReport
+	manager := daemon.NewManager(state)
+
+	if status, err := manager.Status(); err == nil && status.Running {
+		report.Running, report.Mode, report.PID, report.StartedAt = true, "daemon", status.PID, status.StartedAt
+	}
+
+	published, err := manager.ReadState()
+	if err != nil {
+		return report
+	}
+	if !report.Running {
+		if !published.WatcherAlive() {
+			return report
+		}
+		report.Running, report.Mode, report.PID, report.StartedAt = true, "foreground", published.PID, published.StartedAt
+	} else if published.PID != report.PID {
+		return report // Left behind by an earlier watcher
+	}
+
+	report.WatchedDirs = published.WatchedDirs
+	report.WatchedFiles = published.WatchedFiles
+	report.PolledDirs = published.PolledDirs
+	report.PendingChanges = published.PendingChanges
+	report.SnapshotPending = published.SnapshotPending
+	report.IgnoreCacheHits = published.IgnoreCacheHits
+	report.IgnoreCacheMisses = published.IgnoreCacheMisses
+	report.IgnoreCacheHitRate = published.IgnoreCacheHitRate
+	report.IgnoreCacheEvictions = published.IgnoreCacheEvictions
+	report.LastGC = published.LastGC
+	report.LastHeal = published.LastHeal
+	report.UpdatedAt = published.UpdatedAt
+	report.LastError = published.LastError
+	report.IgnoreSources = published.IgnoreSources
+	if !report.StartedAt.IsZero() {
+		report.UptimeSeconds = int64(time.Since(report.StartedAt).Seconds())
+	}
+	return report
+}
+
+// showQuota prints how much of git.max_commits and git.max_repo_size_mb is
+// in use, warning from 90%
+func showQuota(usage core.QuotaUsage) {
+	var parts []string
+	high := false
+	if usage.MaxSnapshots > 0 {
+		percent := 100 * float64(usage.Snapshots) / float64(usage.MaxSnapshots)
+		high = high || percent >= 90
+		parts = append(parts, fmt.Sprintf("%d/%d snapshots (%.0f%%)", usage.Snapshots, usage.MaxSnapshots, percent))
+	}
+	if usage.MaxRepoBytes > 0 {
+		percent := 100 * float64(usage.RepoBytes) / float64(usage.MaxRepoBytes)
+		high = high || percent >= 90
+		parts = append(parts, fmt.Sprintf("%s/%s (%.0f%%)", utils.FormatBytes(usage.RepoBytes), utils.FormatBytes(usage.MaxRepoBytes), percent))
+	}
+	if len(parts) == 0 {
+		return
+	}
+	line := "   Quota: " + strings.Join(parts, ", ")
+	if high {
+		ui.Warning("%s; the oldest snapshots are pruned beyond it", line)
+	} else {
+		fmt.Println(line)
+	}
+}
+
+// showIgnoreSources prints where the running watcher's ignore patterns come
+// from, in the order they are applied
+func showIgnoreSources(sources []core.IgnoreSource) {
+	if len(sources) == 0 {
+		return
+	}
+	fmt.Println("   Ignore patterns (later sources win):")
+	for _, source := range sources {
+		fmt.Printf("   • %-36s %d\n", source.Name, source.Patterns)
+	}
+}
+
+// showWatcherStatus prints the watcher section of the status
+func showWatcherStatus(watcher watcherReport) {
+	if !watcher.Running {
+		ui.Warning("👀 Watcher: not running")
+		fmt.Println("   Run 'timemachine start' to begin taking snapshots")
+		return
+	}
+
+	where := "in the foreground"
+	if watcher.Mode == "daemon" {
+		where = "in the background"
+	}
+	uptime := time.Duration(watcher.UptimeSeconds) * time.Second
+	ui.Success("👀 Watcher: running %s (PID %d, up %s)", where, watcher.PID, uptime)
+	if watcher.UpdatedAt.IsZero() {
+		return
+	}
+
+	fmt.Printf("   Watching %d directories (%d files)", watcher.WatchedDirs, watcher.WatchedFiles)
+	if watcher.PendingChanges > 0 || watcher.SnapshotPending {
+		fmt.Printf(", %d change(s) waiting for the next snapshot", watcher.PendingChanges)
+	}
+	fmt.Println()
+	if watcher.PolledDirs > 0 {
+		ui.Warning("   ⚠️  Polling %d directories instead of watching them (watch limits, or a filesystem that doesn't report changes)", watcher.PolledDirs)
+	}
+	if checks := watcher.IgnoreCacheHits + watcher.IgnoreCacheMisses; checks > 0 {
+		fmt.Printf("   Ignore cache: %.1f%% hit rate over %d checks", watcher.IgnoreCacheHitRate, checks)
+		if watcher.IgnoreCacheEvictions > 0 {
+			fmt.Printf(", %d evicted", watcher.IgnoreCacheEvictions)
+		}
+		fmt.Println()
+	}
+	if gc := watcher.LastGC; gc != nil {
+		if gc.Error != "" {
+			ui.Warning("   ⚠️  Last garbage collection failed %s: %s", gc.At.Local().Format("2006-01-02 15:04"), gc.Error)
+		} else {
+			fmt.Printf("   Last garbage collection: %s (took %s)\n", gc.At.Local().Format("2006-01-02 15:04"), time.Duration(gc.DurationMs)*time.Millisecond)
+		}
+	}
+	if heal := watcher.LastHeal; heal != nil {
+		at := heal.At.Local().Format("2006-01-02 15:04")
+		if heal.Error != "" {
+			ui.Error("   🚨 The shadow repository is broken (%s) and re-creating it failed %s: %s", heal.Reason, at, heal.Error)
+		} else {
+			ui.Error("   🚨 The shadow repository was re-created %s: %s", at, heal.Reason)
+		}
+		if heal.Quarantine != "" {
+			fmt.Printf("      Earlier snapshots may be recoverable from %s\n", heal.Quarantine)
+		}
+	}
+	if watcher.LastError != "" {
+		ui.Warning("   ⚠️  Last snapshot failed: %s", watcher.LastError)
+	}
+}
+
+// sortedKeys returns the keys of counts in order
+func sortedKeys(counts map[string]int) []string {
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// showIntegrityStatus prints the result of the latest integrity check
+func showIntegrityStatus(gitManager *core.GitManager) {
+	report, err := gitManager.ReadIntegrityReport()
+	switch {
+	case err != nil:
+		fmt.Println("🛡️  Integrity: not checked yet (run 'timemachine verify')")
+	case report.Corrupt():
+		ui.Error("🚨 Integrity: CORRUPTION DETECTED %s (%d problem(s))",
+			report.DetectedAt.Local().Format("2006-01-02 15:04"), len(report.Problems))
+		fmt.Printf("   %s\n", report.Problems[0])
+		fmt.Println("   Run 'timemachine verify --full' for details")
+	default:
+		fmt.Printf("🛡️  Integrity: OK (checked %s)\n", report.CheckedAt.Local().Format("2006-01-02 15:04"))
+	}
+}
+
 func showNotInGitRepo() {
 	fmt.Println("Time Machine requires a Git repository to function.")
 	fmt.Println()
@@ -195,11 +503,41 @@ func showDetailedStatus(state *core.AppState, gitManager *core.GitManager) {
 	hasChanges, err := checkUncommittedChanges(state.ProjectRoot)
 	if err == nil {
 		if hasChanges {
-			color.Yellow("   ⚠️  Uncommitted changes detected in main repo")
+			ui.Warning("   ⚠️  Uncommitted changes detected in main repo")
 		} else {
-			color.Green("   ✅ Working directory clean")
+			ui.Success("   ✅ Working directory clean")
 		}
 	}
+
+	showCapabilities(state.Capabilities())
+}
+
+// showCapabilities prints what the environment supports and what is degraded
+func showCapabilities(caps *core.Capabilities) {
+	fmt.Println()
+	fmt.Println("🧩 Environment:")
+	if caps.Git {
+		fmt.Printf("   Git: %s\n", caps.GitVersion)
+	}
+	watching := caps.WatchBackend
+	if caps.WatchError != nil {
+		watching += " (unavailable)"
+	}
+	fmt.Printf("   File watching: %s\n", watching)
+	fmt.Printf("   Color output: %s\n", availability(caps.Color, "yes", "no"))
+	fmt.Printf("   Clipboard: %s\n", availability(caps.Clipboard != "", caps.Clipboard, "not found"))
+	fmt.Printf("   Notifications: %s\n", availability(caps.Notifications != "", caps.Notifications, "not found"))
+
+	for _, limitation := range caps.Limitations() {
+		ui.Warning("   ⚠️  %s", limitation)
+	}
+}
+
+func availability(ok bool, yes, no string) string {
+	if ok {
+		return yes
+	}
+	return no
 }
 
 func checkUncommittedChanges(projectRoot string) (bool, error) {
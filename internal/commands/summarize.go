@@ -0,0 +1,137 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/core"
+	"github.com/spf13/cobra"
+)
+
+// Caps on the markdown summary, so it stays readable in a PR description
+const (
+	maxSummaryFiles    = 25
+	maxSummaryMessages = 5
+)
+
+// SummarizeCmd creates the summarize command
+func SummarizeCmd() *cobra.Command {
+	var (
+		since      string
+		format     string
+		sessionGap time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:   "summarize",
+		Short: "Summarize what changed across snapshots, for a PR description",
+		Long: `Summarize everything snapshots recorded over a period: the files touched,
+how much was written along the way (churn) against what is left at the end
+(net), and a timeline of work sessions. Paste the markdown into a PR
+description so reviewers can see how the change came about, AI edits and
+all.
+
+--since takes a commit of the project, such as the branch the PR targets,
+an age or a date, or a snapshot hash. A commit means the snapshots taken
+since it, as for 'timemachine list --since-commit'.
+
+Examples:
+  timemachine summarize --since main
+  timemachine summarize --since 4h
+  timemachine summarize --since main | gh pr create --body-file -
+  timemachine summarize --since 2024-05-01 --format json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runSummarize(os.Stdout, since, format, sessionGap)
+		},
+	}
+
+	cmd.Flags().StringVar(&since, "since", "", "Start point: project commit, age (e.g. 2h, 7d), date (YYYY-MM-DD) or snapshot hash")
+	cmd.Flags().StringVar(&format, "format", "markdown", "Output format (markdown, json)")
+	cmd.Flags().DurationVar(&sessionGap, "session-gap", core.DefaultSessionGap, "Idle time that starts a new session")
+
+	return cmd
+}
+
+func runSummarize(out io.Writer, since, format string, sessionGap time.Duration) error {
+	if format != "markdown" && format != "json" {
+		return fmt.Errorf("unsupported format: %s (use 'markdown' or 'json')", format)
+	}
+
+	state, err := loadInitializedState()
+	if err != nil || state == nil {
+		return err
+	}
+
+	summary, err := core.NewGitManager(state).Summarize(since, sessionGap)
+	if err != nil {
+		return err
+	}
+
+	if format == "json" {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(summary)
+	}
+
+	writeSummaryMarkdown(out, summary)
+	return nil
+}
+
+func writeSummaryMarkdown(out io.Writer, summary *core.ChangeSummary) {
+	fmt.Fprintln(out, "## Change summary")
+	fmt.Fprintln(out)
+
+	if summary.Snapshots == 0 {
+		fmt.Fprintln(out, "_No snapshots found._")
+		return
+	}
+
+	netInsertions, netDeletions := 0, 0
+	for _, file := range summary.Files {
+		netInsertions += file.NetInsertions
+		netDeletions += file.NetDeletions
+	}
+	fmt.Fprintf(out, "%d snapshot(s) over %s (%s – %s) in %d session(s).\n",
+		summary.Snapshots, summary.End.Sub(summary.Start).Round(time.Minute),
+		summary.Start.Local().Format("2006-01-02 15:04"), summary.End.Local().Format("2006-01-02 15:04"),
+		len(summary.Sessions))
+	fmt.Fprintf(out, "%d file(s) touched: +%d / -%d lines written along the way, +%d / -%d net.\n",
+		len(summary.Files), summary.Insertions, summary.Deletions, netInsertions, netDeletions)
+
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "### Files")
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "| File | Status | Edits | Churn | Net |")
+	fmt.Fprintln(out, "|------|--------|------:|------:|----:|")
+	for i, file := range summary.Files {
+		if i >= maxSummaryFiles {
+			fmt.Fprintf(out, "\n…and %d more file(s)\n", len(summary.Files)-maxSummaryFiles)
+			break
+		}
+		churn, net := fmt.Sprintf("+%d / -%d", file.Insertions, file.Deletions), fmt.Sprintf("+%d / -%d", file.NetInsertions, file.NetDeletions)
+		if file.Binary {
+			churn, net = "binary", "binary"
+		}
+		fmt.Fprintf(out, "| `%s` | %s | %d | %s | %s |\n", escapeMarkdownCell(file.Path), file.Status, file.Edits, churn, net)
+	}
+
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "### Timeline")
+	fmt.Fprintln(out)
+	for _, session := range summary.Sessions {
+		fmt.Fprintf(out, "- **%s–%s** · %d snapshot(s) · %d file(s) · +%d / -%d\n",
+			session.Start.Local().Format("2006-01-02 15:04"), session.End.Local().Format("15:04"),
+			session.Snapshots, session.Files, session.Insertions, session.Deletions)
+		for i, message := range session.Messages {
+			if i >= maxSummaryMessages {
+				fmt.Fprintf(out, "  - …and %d more\n", len(session.Messages)-maxSummaryMessages)
+				break
+			}
+			fmt.Fprintf(out, "  - %s\n", message)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/core"
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// VerifyCmd creates the verify command
+func VerifyCmd() *cobra.Command {
+	var (
+		full   bool
+		sample int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Check snapshots for corruption",
+		Long: `Check the snapshot repository for corruption.
+
+The watcher runs a quick check every git.verify_interval (default 6h): it
+confirms every snapshot's objects exist, re-hashes objects added since the
+last clean check and a random sample of older ones. Problems it finds are
+shown by 'timemachine status' until a full check passes.
+
+Without flags this runs the same quick check. --full reads and verifies every
+object with git fsck and clears earlier alerts if the repository is sound.
+
+Exits with an error when corruption is found.
+
+Examples:
+  timemachine verify               # Quick check
+  timemachine verify --sample 5000 # Quick check covering more old objects
+  timemachine verify --full        # Check everything`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if sample < 0 {
+				return fmt.Errorf("--sample must not be negative")
+			}
+			return runVerify(full, sample)
+		},
+	}
+
+	cmd.Flags().BoolVar(&full, "full", false, "Verify every object with git fsck")
+	cmd.Flags().IntVar(&sample, "sample", core.DefaultVerifySample, "Older objects to re-hash in a quick check")
+
+	return cmd
+}
+
+func runVerify(full bool, sample int) error {
+	state, err := loadInitializedState()
+	if err != nil || state == nil {
+		return err
+	}
+
+	if full {
+		fmt.Println("🔍 Verifying every snapshot object, this may take a while...")
+	} else {
+		fmt.Println("🔍 Verifying snapshots...")
+	}
+
+	gitManager := core.NewGitManager(state)
+	report, err := gitManager.VerifyIntegrity(full, sample)
+	if err != nil {
+		return err
+	}
+
+	if !report.Corrupt() {
+		ui.Success("✅ No corruption found (%d objects checked)", report.ObjectsChecked)
+		return nil
+	}
+
+	ui.Error("🚨 Snapshot corruption detected (first found %s):", report.DetectedAt.Local().Format("2006-01-02 15:04:05"))
+	for _, problem := range report.Problems {
+		fmt.Printf("   • %s\n", problem)
+	}
+	fmt.Println()
+	if !full {
+		fmt.Println("Run 'timemachine verify --full' for a complete check; it clears this alert if")
+		fmt.Println("the repository turns out to be sound.")
+	} else {
+		fmt.Println("Restoring snapshots that reference damaged objects may fail.")
+	}
+
+	return fmt.Errorf("snapshot repository is corrupt")
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"sort"
 	"time"
 
 	"github.com/spf13/viper"
@@ -16,6 +18,15 @@ type Config struct {
 	Cache   CacheConfig   `mapstructure:"cache" yaml:"cache" validate:"dive"`
 	Git     GitConfig     `mapstructure:"git" yaml:"git" validate:"dive"`
 	UI      UIConfig      `mapstructure:"ui" yaml:"ui" validate:"dive"`
+	Retention RetentionConfig `mapstructure:"retention" yaml:"retention" validate:"dive"`
+	Hooks     HooksConfig     `mapstructure:"hooks" yaml:"hooks" validate:"dive"`
+	Metrics   MetricsConfig   `mapstructure:"metrics" yaml:"metrics" validate:"dive"`
+	Secrets   SecretsConfig   `mapstructure:"secrets" yaml:"secrets" validate:"dive"`
+	API       APIConfig       `mapstructure:"api" yaml:"api" validate:"dive"`
+	Telemetry TelemetryConfig `mapstructure:"telemetry" yaml:"telemetry" validate:"dive"`
+	Remote    RemoteConfig    `mapstructure:"remote" yaml:"remote" validate:"dive"`
+	Backup    BackupConfig    `mapstructure:"backup" yaml:"backup" validate:"dive"`
+	Detect    DetectConfig    `mapstructure:"detect" yaml:"detect" validate:"dive"`
 }
 
 // LogConfig controls logging behavior
@@ -27,13 +38,41 @@ type LogConfig struct {
 
 // WatcherConfig controls file watching behavior
 type WatcherConfig struct {
-	DebounceDelay    time.Duration `mapstructure:"debounce_delay" yaml:"debounce_delay" validate:"min=100ms,max=10s" default:"2s"`
-	MaxWatchedFiles  int           `mapstructure:"max_watched_files" yaml:"max_watched_files" validate:"min=1000,max=1000000" default:"100000"`
-	IgnorePatterns   []string      `mapstructure:"ignore_patterns" yaml:"ignore_patterns" default:"[]"`
-	BatchSize        int           `mapstructure:"batch_size" yaml:"batch_size" validate:"min=1,max=1000" default:"100"`
-	EnableRecursive  bool          `mapstructure:"enable_recursive" yaml:"enable_recursive" default:"true"`
+	DebounceDelay     time.Duration      `mapstructure:"debounce_delay" yaml:"debounce_delay" validate:"min=100ms,max=10s" default:"2s"`
+	DebounceOverrides []DebounceOverride `mapstructure:"debounce_overrides" yaml:"debounce_overrides" default:"[]"` // Debounce delays of their own for matching paths; the longest matching pattern wins
+	MaxWatchedFiles   int                `mapstructure:"max_watched_files" yaml:"max_watched_files" validate:"min=1000,max=1000000" default:"100000"`
+	IgnorePatterns    []string           `mapstructure:"ignore_patterns" yaml:"ignore_patterns" default:"[]"`
+	IncludePaths      []string           `mapstructure:"include_paths" yaml:"include_paths" default:"[]"` // Subtrees relative to the project root to snapshot; empty snapshots everything
+	BatchSize         int                `mapstructure:"batch_size" yaml:"batch_size" validate:"min=1,max=1000" default:"100"`
+	BatchWindow       time.Duration      `mapstructure:"batch_window" yaml:"batch_window" validate:"min=0,max=10m" default:"30s"`            // Longest a batch of changes waits for things to quiet down (0 = no limit)
+	IntervalSnapshot  time.Duration      `mapstructure:"interval_snapshot" yaml:"interval_snapshot" validate:"min=0,max=24h" default:"0"`    // Longest pending changes wait for a snapshot, even while they never quiet down (0 = no limit)
+	StormThreshold    int                `mapstructure:"storm_threshold" yaml:"storm_threshold" validate:"min=0,max=1000000" default:"1000"` // Changes in a second that defer snapshots until they subside (0 = never)
+	EnableRecursive   bool               `mapstructure:"enable_recursive" yaml:"enable_recursive" default:"true"`
+	RespectGitignore  bool               `mapstructure:"respect_gitignore" yaml:"respect_gitignore" default:"false"` // Also skip paths ignored by .gitignore files
+	ChurnFilters      []ChurnFilter      `mapstructure:"churn_filters" yaml:"churn_filters" default:"[]"`            // Matching paths aren't snapshotted while their changes normalize away
 }
 
+// DebounceOverride gives paths matching a pattern, with .gitignore syntax,
+// a debounce delay of their own
+type DebounceOverride struct {
+	Pattern string        `mapstructure:"pattern" yaml:"pattern"`
+	Delay   time.Duration `mapstructure:"delay" yaml:"delay"`
+}
+
+// ChurnFilter leaves changes to paths matching a pattern, with .gitignore
+// syntax, out of snapshots when the old and new contents are the same once
+// normalized, e.g. a lockfile regenerated with its keys in another order
+type ChurnFilter struct {
+	Pattern   string   `mapstructure:"pattern" yaml:"pattern"`
+	Normalize []string `mapstructure:"normalize" yaml:"normalize"` // Applied in the order of ChurnNormalizers, whatever order they are listed in
+}
+
+// ChurnNormalizers are the normalizers a churn filter can apply, in the
+// order they are applied: timestamps become a placeholder, JSON is
+// reformatted with sorted keys, lines are sorted, then runs of whitespace
+// are collapsed
+var ChurnNormalizers = []string{"timestamps", "json", "sort_lines", "whitespace"}
+
 // CacheConfig controls caching behavior
 type CacheConfig struct {
 	MaxEntries   int           `mapstructure:"max_entries" yaml:"max_entries" validate:"min=1000,max=100000" default:"10000"`
@@ -46,8 +85,17 @@ type CacheConfig struct {
 type GitConfig struct {
 	CleanupThreshold int  `mapstructure:"cleanup_threshold" yaml:"cleanup_threshold" validate:"min=10,max=10000" default:"100"`
 	AutoGC           bool `mapstructure:"auto_gc" yaml:"auto_gc" default:"true"`
+	AutoHeal         bool `mapstructure:"auto_heal" yaml:"auto_heal" default:"true"` // Re-initialize a shadow repository deleted or corrupted while watching
 	MaxCommits       int  `mapstructure:"max_commits" yaml:"max_commits" validate:"min=50,max=50000" default:"1000"`
+	MaxRepoSizeMB    int  `mapstructure:"max_repo_size_mb" yaml:"max_repo_size_mb" validate:"min=0" default:"0"` // Prune the oldest snapshots once the shadow repository is larger; 0 disables the limit
 	UseShallowClone  bool `mapstructure:"use_shallow_clone" yaml:"use_shallow_clone" default:"false"`
+	Backend          string `mapstructure:"backend" yaml:"backend" validate:"oneof=exec native" default:"exec"`
+	MinFreeSpaceMB   int    `mapstructure:"min_free_space_mb" yaml:"min_free_space_mb" validate:"min=0,max=1048576" default:"100"` // 0 disables the check
+	VerifyInterval   time.Duration `mapstructure:"verify_interval" yaml:"verify_interval" default:"6h"` // Background integrity checks; 0 disables them
+	VerifySample     int           `mapstructure:"verify_sample" yaml:"verify_sample" validate:"min=0,max=100000" default:"200"` // Older objects re-hashed per check
+	MessageTemplate  string        `mapstructure:"message_template" yaml:"message_template"` // text/template for snapshots without a message; empty uses "Snapshot at {{.Time}}"
+	SigningKey       string        `mapstructure:"signing_key" yaml:"signing_key"`                  // Sign snapshots with this SSH key (file or "ssh-..." public key) or GPG key ID; empty leaves them unsigned
+	Provenance       bool          `mapstructure:"provenance" yaml:"provenance" default:"false"`   // Record host, tool and session trailers in snapshot messages
 }
 
 // UIConfig controls user interface behavior
@@ -56,6 +104,79 @@ type UIConfig struct {
 	ColorOutput        bool   `mapstructure:"color_output" yaml:"color_output" default:"true"`
 	Pager              string `mapstructure:"pager" yaml:"pager" validate:"oneof=auto always never" default:"auto"`
 	TableFormat        string `mapstructure:"table_format" yaml:"table_format" validate:"oneof=table json yaml" default:"table"`
+	ListStats          string `mapstructure:"list_stats" yaml:"list_stats" validate:"oneof=auto always never" default:"auto"`
+	Theme              string            `mapstructure:"theme" yaml:"theme" validate:"oneof=default dark light monochrome custom" default:"default"`
+	CustomTheme        map[string]string `mapstructure:"custom_theme" yaml:"custom_theme,omitempty"`         // role -> color spec, used by theme: custom
+	SyntaxTheme        string            `mapstructure:"syntax_theme" yaml:"syntax_theme" default:"monokai"` // chroma style for file contents and diffs, or none
+	Language           string            `mapstructure:"language" yaml:"language" validate:"oneof=auto en ja zh" default:"auto"`
+	Emoji              bool              `mapstructure:"emoji" yaml:"emoji" default:"true"`
+}
+
+// RetentionConfig controls automatic snapshot pruning. Every rule is
+// disabled at its zero value, so snapshots are kept until cleaned by hand.
+type RetentionConfig struct {
+	MaxAge         string `mapstructure:"max_age" yaml:"max_age" default:""`                                    // e.g. 30d; empty keeps snapshots of any age
+	MaxTotalSizeMB int    `mapstructure:"max_total_size_mb" yaml:"max_total_size_mb" validate:"min=0" default:"0"` // Cap on stored snapshot data
+	KeepHourly     int    `mapstructure:"keep_hourly" yaml:"keep_hourly" validate:"min=0" default:"0"`             // Hours to keep one snapshot per hour for
+	KeepDaily      int    `mapstructure:"keep_daily" yaml:"keep_daily" validate:"min=0" default:"0"`               // Days to keep one snapshot per day for
+	KeepWeekly     int    `mapstructure:"keep_weekly" yaml:"keep_weekly" validate:"min=0" default:"0"`             // Weeks to keep one snapshot per week for
+}
+
+// HooksConfig lists shell commands run on snapshot lifecycle events. Hooks
+// run arbitrary commands, so they are only read from configuration files and
+// never from environment variables.
+type HooksConfig struct {
+	PreSnapshot  []string      `mapstructure:"pre_snapshot" yaml:"pre_snapshot" default:"[]"`             // Before the watcher snapshots; a failing command skips the snapshot
+	PostSnapshot []string      `mapstructure:"post_snapshot" yaml:"post_snapshot" default:"[]"`           // After the watcher saved a snapshot
+	PostRestore  []string      `mapstructure:"post_restore" yaml:"post_restore" default:"[]"`             // After files were restored
+	Timeout      time.Duration `mapstructure:"timeout" yaml:"timeout" validate:"min=0,max=1h" default:"1m"` // Per command; 0 uses the default of 1m, otherwise at least 1s
+}
+
+// MetricsConfig controls the watcher's Prometheus metrics endpoint
+type MetricsConfig struct {
+	Listen string `mapstructure:"listen" yaml:"listen" default:""` // host:port to serve /metrics on, e.g. 127.0.0.1:9190; empty disables it
+}
+
+// SecretsConfig controls the scan of changed lines for credentials before
+// they are snapshotted
+type SecretsConfig struct {
+	Mode  string   `mapstructure:"mode" yaml:"mode" validate:"oneof=warn block off" default:"warn"` // warn logs findings; block skips the snapshot
+	Allow []string `mapstructure:"allow" yaml:"allow" default:"[]"`                                // Regexes for matches that are not secrets, e.g. test fixtures
+	Deny  []string `mapstructure:"deny" yaml:"deny" default:"[]"`                                  // Extra regexes to treat as secrets
+}
+
+// APIConfig controls the watcher's local HTTP API for editor integrations
+type APIConfig struct {
+	Enabled bool   `mapstructure:"enabled" yaml:"enabled" default:"false"`
+	Listen  string `mapstructure:"listen" yaml:"listen" default:"127.0.0.1:0"` // Loopback host:port; port 0 picks a free one, published in the token file
+}
+
+// TelemetryConfig controls the local usage statistics shown by
+// timemachine stats
+type TelemetryConfig struct {
+	Enabled  bool   `mapstructure:"enabled" yaml:"enabled" default:"false"`
+	Endpoint string `mapstructure:"endpoint" yaml:"endpoint" default:""` // http(s) URL that stats --send posts the summary to; empty never sends
+}
+
+// RemoteConfig controls the remote backup of the shadow repository, see
+// timemachine push and pull
+type RemoteConfig struct {
+	Name         string        `mapstructure:"name" yaml:"name" default:"origin"`               // Remote that push and pull use by default, and the watcher pushes to
+	Machine      string        `mapstructure:"machine" yaml:"machine" default:""`               // Namespace of this machine's branches on the remote; empty uses the hostname
+	PushInterval time.Duration `mapstructure:"push_interval" yaml:"push_interval" default:"0"` // How often the watcher pushes; 0 disables
+}
+
+// BackupConfig controls the bundles uploaded to object storage, see
+// timemachine backup and restore-from-backup
+type BackupConfig struct {
+	URL      string        `mapstructure:"url" yaml:"url" default:""`                // s3://bucket/prefix, gs://bucket/prefix or a local directory; empty disables backups
+	Interval time.Duration `mapstructure:"interval" yaml:"interval" default:"0"`     // How often the watcher uploads new snapshots; 0 disables
+}
+
+// DetectConfig controls what snapshots learn about the tools changing the
+// project
+type DetectConfig struct {
+	Agents bool `mapstructure:"agents" yaml:"agents" default:"false"` // Record the AI coding agents running in the project on each snapshot
 }
 
 // Manager handles configuration loading and management
@@ -113,6 +234,24 @@ func (m *Manager) Load(projectRoot string) error {
 	return nil
 }
 
+// ValidateFile checks that a configuration file can be read and, merged
+// with the defaults, passes validation
+func ValidateFile(path string) error {
+	m := NewManager()
+	m.viper.SetConfigFile(path)
+	m.viper.SetConfigType("yaml")
+	if err := m.viper.ReadInConfig(); err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	if err := m.viper.Unmarshal(m.config); err != nil {
+		return fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+	if err := m.validator.Validate(m.config); err != nil {
+		return fmt.Errorf("configuration validation failed: %w", err)
+	}
+	return nil
+}
+
 // Get returns the current configuration
 func (m *Manager) Get() *Config {
 	return m.config
@@ -161,6 +300,101 @@ func (m *Manager) setupConfigPaths(projectRoot string) error {
 	return nil
 }
 
+// envBindings maps the only environment variables that override settings to
+// their keys
+var envBindings = map[string]string{
+	"TIMEMACHINE_LOG_LEVEL":               "log.level",
+	"TIMEMACHINE_LOG_FORMAT":              "log.format",
+	"TIMEMACHINE_LOG_FILE":                "log.file",
+	"TIMEMACHINE_WATCHER_DEBOUNCE":        "watcher.debounce_delay",
+	"TIMEMACHINE_WATCHER_MAX_FILES":       "watcher.max_watched_files",
+	"TIMEMACHINE_WATCHER_GITIGNORE":       "watcher.respect_gitignore",
+	"TIMEMACHINE_WATCHER_BATCH_WINDOW":    "watcher.batch_window",
+	"TIMEMACHINE_WATCHER_STORM_THRESHOLD": "watcher.storm_threshold",
+	"TIMEMACHINE_WATCHER_INTERVAL":        "watcher.interval_snapshot",
+	"TIMEMACHINE_CACHE_MAX_ENTRIES":       "cache.max_entries",
+	"TIMEMACHINE_CACHE_MAX_MEMORY":        "cache.max_memory_mb",
+	"TIMEMACHINE_CACHE_TTL":               "cache.ttl",
+	"TIMEMACHINE_GIT_CLEANUP_THRESHOLD":   "git.cleanup_threshold",
+	"TIMEMACHINE_GIT_AUTO_GC":             "git.auto_gc",
+	"TIMEMACHINE_GIT_AUTO_HEAL":           "git.auto_heal",
+	"TIMEMACHINE_GIT_BACKEND":             "git.backend",
+	"TIMEMACHINE_GIT_MIN_FREE_SPACE":      "git.min_free_space_mb",
+	"TIMEMACHINE_GIT_MAX_REPO_SIZE":       "git.max_repo_size_mb",
+	"TIMEMACHINE_GIT_VERIFY_INTERVAL":     "git.verify_interval",
+	"TIMEMACHINE_GIT_MESSAGE_TEMPLATE":    "git.message_template",
+	"TIMEMACHINE_GIT_SIGNING_KEY":         "git.signing_key",
+	"TIMEMACHINE_GIT_PROVENANCE":          "git.provenance",
+	"TIMEMACHINE_UI_COLOR":                "ui.color_output",
+	"TIMEMACHINE_UI_PAGER":                "ui.pager",
+	"TIMEMACHINE_UI_THEME":                "ui.theme",
+	"TIMEMACHINE_UI_LIST_STATS":           "ui.list_stats",
+	"TIMEMACHINE_UI_LANGUAGE":             "ui.language",
+	"TIMEMACHINE_UI_EMOJI":                "ui.emoji",
+	"TIMEMACHINE_UI_SYNTAX_THEME":         "ui.syntax_theme",
+	"TIMEMACHINE_RETENTION_MAX_AGE":       "retention.max_age",
+	"TIMEMACHINE_RETENTION_MAX_SIZE":      "retention.max_total_size_mb",
+	"TIMEMACHINE_HOOKS_TIMEOUT":           "hooks.timeout",
+	"TIMEMACHINE_METRICS_LISTEN":          "metrics.listen",
+	"TIMEMACHINE_SECRETS_MODE":            "secrets.mode",
+	"TIMEMACHINE_API_ENABLED":             "api.enabled",
+	"TIMEMACHINE_API_LISTEN":              "api.listen",
+	"TIMEMACHINE_TELEMETRY_ENABLED":       "telemetry.enabled",
+	"TIMEMACHINE_TELEMETRY_ENDPOINT":      "telemetry.endpoint",
+	"TIMEMACHINE_REMOTE_NAME":             "remote.name",
+	"TIMEMACHINE_REMOTE_MACHINE":          "remote.machine",
+	"TIMEMACHINE_REMOTE_PUSH_INTERVAL":    "remote.push_interval",
+	"TIMEMACHINE_BACKUP_URL":              "backup.url",
+	"TIMEMACHINE_BACKUP_INTERVAL":         "backup.interval",
+	"TIMEMACHINE_DETECT_AGENTS":           "detect.agents",
+}
+
+// EnvBindings returns the environment variables that override settings,
+// mapped to the keys they set
+func EnvBindings() map[string]string {
+	bindings := make(map[string]string, len(envBindings))
+	for env, key := range envBindings {
+		bindings[env] = key
+	}
+	return bindings
+}
+
+// EnvOverride is a setting an environment variable overrides
+type EnvOverride struct {
+	Env   string // e.g. TIMEMACHINE_LOG_LEVEL
+	Key   string // e.g. log.level
+	Value string // The environment variable's value
+	File  string // What the configuration file sets the key to; "" when it doesn't
+}
+
+// EnvOverrides lists, sorted by variable, the settings environment variables
+// override along with what the loaded configuration file set them to
+func (m *Manager) EnvOverrides() []EnvOverride {
+	var file *viper.Viper
+	if path := m.viper.ConfigFileUsed(); path != "" {
+		file = viper.New()
+		file.SetConfigFile(path)
+		if err := file.ReadInConfig(); err != nil {
+			file = nil
+		}
+	}
+
+	var overrides []EnvOverride
+	for env, key := range envBindings {
+		value, ok := os.LookupEnv(env)
+		if !ok {
+			continue
+		}
+		override := EnvOverride{Env: env, Key: key, Value: value}
+		if file != nil && file.IsSet(key) {
+			override.File = fmt.Sprint(file.Get(key))
+		}
+		overrides = append(overrides, override)
+	}
+	sort.Slice(overrides, func(i, j int) bool { return overrides[i].Env < overrides[j].Env })
+	return overrides
+}
+
 // setupEnvironmentVariables configures environment variable handling
 // SECURITY: Only explicitly defined environment variables are bound to prevent injection attacks
 func (m *Manager) setupEnvironmentVariables() {
@@ -168,25 +402,9 @@ func (m *Manager) setupEnvironmentVariables() {
 	// arbitrary environment variable injection. Now only explicitly defined
 	// variables are processed, ensuring all values go through validation.
 	
-	// Only these specific environment variables are allowed
-	allowedEnvVars := map[string]string{
-		"TIMEMACHINE_LOG_LEVEL":            "log.level",
-		"TIMEMACHINE_LOG_FORMAT":           "log.format", 
-		"TIMEMACHINE_LOG_FILE":             "log.file",
-		"TIMEMACHINE_WATCHER_DEBOUNCE":     "watcher.debounce_delay",
-		"TIMEMACHINE_WATCHER_MAX_FILES":    "watcher.max_watched_files",
-		"TIMEMACHINE_CACHE_MAX_ENTRIES":    "cache.max_entries",
-		"TIMEMACHINE_CACHE_MAX_MEMORY":     "cache.max_memory_mb",
-		"TIMEMACHINE_CACHE_TTL":            "cache.ttl",
-		"TIMEMACHINE_GIT_CLEANUP_THRESHOLD": "git.cleanup_threshold",
-		"TIMEMACHINE_GIT_AUTO_GC":          "git.auto_gc",
-		"TIMEMACHINE_UI_COLOR":             "ui.color_output",
-		"TIMEMACHINE_UI_PAGER":             "ui.pager",
-	}
-	
 	// Bind only explicitly defined environment variables
 	// This ensures all values go through the normal validation pipeline
-	for env, key := range allowedEnvVars {
+	for env, key := range envBindings {
 		m.viper.BindEnv(key, env)
 	}
 }
@@ -200,10 +418,17 @@ func setDefaults(v *viper.Viper) {
 	
 	// Watcher defaults
 	v.SetDefault("watcher.debounce_delay", "2s")
+	v.SetDefault("watcher.debounce_overrides", []DebounceOverride{})
 	v.SetDefault("watcher.max_watched_files", 100000)
 	v.SetDefault("watcher.ignore_patterns", []string{})
+	v.SetDefault("watcher.include_paths", []string{})
 	v.SetDefault("watcher.batch_size", 100)
+	v.SetDefault("watcher.batch_window", "30s")
+	v.SetDefault("watcher.interval_snapshot", "0")
+	v.SetDefault("watcher.storm_threshold", 1000)
 	v.SetDefault("watcher.enable_recursive", true)
+	v.SetDefault("watcher.respect_gitignore", false)
+	v.SetDefault("watcher.churn_filters", []ChurnFilter{})
 	
 	// Cache defaults
 	v.SetDefault("cache.max_entries", 10000)
@@ -214,27 +439,74 @@ func setDefaults(v *viper.Viper) {
 	// Git defaults
 	v.SetDefault("git.cleanup_threshold", 100)
 	v.SetDefault("git.auto_gc", true)
+	v.SetDefault("git.auto_heal", true)
 	v.SetDefault("git.max_commits", 1000)
+	v.SetDefault("git.max_repo_size_mb", 0)
 	v.SetDefault("git.use_shallow_clone", false)
+	v.SetDefault("git.backend", "exec")
+	v.SetDefault("git.min_free_space_mb", 100)
+	v.SetDefault("git.verify_interval", "6h")
+	v.SetDefault("git.verify_sample", 200)
+	v.SetDefault("git.message_template", "")
+	v.SetDefault("git.signing_key", "")
+	v.SetDefault("git.provenance", false)
 	
 	// UI defaults
 	v.SetDefault("ui.progress_indicators", true)
 	v.SetDefault("ui.color_output", true)
 	v.SetDefault("ui.pager", "auto")
 	v.SetDefault("ui.table_format", "table")
-}
-
-// CreateDefaultConfigFile creates a default configuration file in the project root
-func (m *Manager) CreateDefaultConfigFile(projectRoot string) error {
-	configPath := filepath.Join(projectRoot, "timemachine.yaml")
+	v.SetDefault("ui.list_stats", "auto")
+	v.SetDefault("ui.theme", "default")
+	v.SetDefault("ui.syntax_theme", "monokai")
+	v.SetDefault("ui.language", "auto")
+	v.SetDefault("ui.emoji", true)
 	
-	// Check if config file already exists
-	if _, err := os.Stat(configPath); err == nil {
-		return fmt.Errorf("configuration file already exists at %s", configPath)
-	}
+	// Retention defaults (all rules disabled)
+	v.SetDefault("retention.max_age", "")
+	v.SetDefault("retention.max_total_size_mb", 0)
+	v.SetDefault("retention.keep_hourly", 0)
+	v.SetDefault("retention.keep_daily", 0)
+	v.SetDefault("retention.keep_weekly", 0)
+	
+	// Hook defaults (no hooks)
+	v.SetDefault("hooks.pre_snapshot", []string{})
+	v.SetDefault("hooks.post_snapshot", []string{})
+	v.SetDefault("hooks.post_restore", []string{})
+	v.SetDefault("hooks.timeout", "1m")
+	
+	// Metrics defaults (endpoint disabled)
+	v.SetDefault("metrics.listen", "")
 	
-	// Create default configuration with comments
-	defaultConfig := `# TimeMachine CLI Configuration
+	// Secrets defaults (warn about built-in detections)
+	v.SetDefault("secrets.mode", "warn")
+	v.SetDefault("secrets.allow", []string{})
+	v.SetDefault("secrets.deny", []string{})
+	
+	// API defaults (disabled)
+	v.SetDefault("api.enabled", false)
+	v.SetDefault("api.listen", "127.0.0.1:0")
+	
+	// Telemetry defaults (off, nothing ever sent)
+	v.SetDefault("telemetry.enabled", false)
+	v.SetDefault("telemetry.endpoint", "")
+	
+	// Remote defaults (never pushed automatically)
+	v.SetDefault("remote.name", "origin")
+	v.SetDefault("remote.machine", "")
+	v.SetDefault("remote.push_interval", "0")
+	
+	// Backup defaults (nowhere to upload to)
+	v.SetDefault("backup.url", "")
+	v.SetDefault("backup.interval", "0")
+	
+	// Detection defaults (no process scanning)
+	v.SetDefault("detect.agents", false)
+}
+
+// defaultConfigFile is the commented configuration file written by
+// CreateDefaultConfigFile. Its comments also describe the settings in Schema.
+const defaultConfigFile = `# TimeMachine CLI Configuration
 # This file contains configuration options for TimeMachine CLI
 # All settings have sensible defaults and can be overridden via:
 #   - Command line flags (highest priority)
@@ -249,35 +521,159 @@ log:
 
 watcher:
   debounce_delay: 2s           # delay before creating snapshot after changes
+  debounce_overrides: []       # delays of their own for matching paths, e.g. [{pattern: "data/**", delay: 30s}]
   max_watched_files: 100000    # maximum number of files to watch
   ignore_patterns: []          # additional patterns to ignore
-  batch_size: 100             # number of files to process in batch
+  include_paths: []            # only watch and snapshot these subtrees, e.g. ["services/payments"] (empty = everything)
+  batch_size: 100             # snapshot as soon as this many files changed
+  batch_window: 30s           # snapshot at least this often during continuous changes (0 = wait for quiet)
+  interval_snapshot: 0        # snapshot pending changes at least this often, even during storms, e.g. 10m (0 = off)
+  storm_threshold: 1000       # changes in a second that defer snapshots until they subside (0 = never)
   enable_recursive: true      # recursively watch subdirectories
+  respect_gitignore: false    # also skip paths ignored by .gitignore files
+  churn_filters: []           # skip changes that normalize away, e.g. [{pattern: "package-lock.json", normalize: [json]}]; normalizers: timestamps, json, sort_lines, whitespace
 
 cache:
-  max_entries: 10000      # maximum cache entries
+  max_entries: 10000      # maximum cached ignore results
   max_memory_mb: 50       # maximum cache memory usage
   ttl: 1h                # cache entry time-to-live
-  enable_lru: true       # use LRU eviction policy
+  enable_lru: true       # evict least recently used first (else oldest first)
 
 git:
-  cleanup_threshold: 100      # number of snapshots before cleanup
-  auto_gc: true              # automatically run git gc
-  max_commits: 1000          # maximum snapshots to keep
+  cleanup_threshold: 100      # snapshots between automatic garbage collections
+  auto_gc: true              # run 'git gc --auto' while watching, between snapshots
+  auto_heal: true            # re-create the shadow repository if it is deleted or corrupted while watching
+  max_commits: 1000          # maximum snapshots to keep; the oldest are pruned beyond it
+  max_repo_size_mb: 0        # prune the oldest snapshots once the shadow repository is larger (0 disables)
   use_shallow_clone: false   # use shallow cloning for performance
+  backend: exec              # exec (git binary) or native (in-process go-git)
+  min_free_space_mb: 100     # pause snapshots below this much free disk space (0 disables)
+  verify_interval: 6h        # how often the watcher checks snapshots for corruption (0 disables)
+  verify_sample: 200         # older objects re-checked per verification
+  message_template: ""       # e.g. "{{.Time}} [{{.Branch}}] {{.FileCount}} files in {{join .Dirs \", \"}}"
+  signing_key: ""            # sign snapshots: an SSH key file, "ssh-..." public key or GPG key ID (empty leaves them unsigned)
+  provenance: false          # record Snapshot-Host, Snapshot-Tool and Snapshot-Session trailers
 
 ui:
   progress_indicators: true   # show progress bars and spinners
   color_output: true         # colorize output
   pager: auto               # auto, always, never
   table_format: table       # table, json, yaml
+  list_stats: auto          # file counts and +/- lines in 'list': auto (unless --limit 0), always, never
+  theme: default            # default, dark, light, monochrome, custom
+  syntax_theme: monokai     # highlighting of code in diffs and file views: a chroma style (github, dracula, ...) or none
+  language: auto            # language of messages: auto (from LANG), en, ja, zh
+  emoji: true               # emoji in messages (--no-emoji turns them off for one command)
+  # custom_theme:           # per-role colors used by theme: custom, such as
+  #   success: hi-green
+  #   error: bold red
+  #   hash: magenta
+
+retention:                # automatic pruning by the watcher and 'clean --policy' (0/empty disables a rule)
+  max_age: ""             # drop snapshots older than this, e.g. 30d
+  max_total_size_mb: 0    # drop the oldest snapshots while stored data exceeds this
+  keep_hourly: 0          # beyond the last hour, keep one snapshot per hour for this many hours
+  keep_daily: 0           # ... one per day for this many days
+  keep_weekly: 0          # ... one per week for this many weeks
+
+hooks:                    # shell commands run in the project root, with TM_* variables describing the event
+  pre_snapshot: []        # before the watcher snapshots; a failing command skips the snapshot
+  post_snapshot: []       # after the watcher saved a snapshot, e.g. ["npm run lint --silent"]
+  post_restore: []        # after files were restored
+  timeout: 1m             # per command; 0 uses the default of 1m, otherwise at least 1s
+
+metrics:
+  listen: ""              # serve Prometheus metrics from the watcher, e.g. 127.0.0.1:9190 (empty disables)
+
+secrets:                  # scan changed lines for credentials (AWS keys, private keys, tokens, .env values)
+  mode: warn              # warn, block (skip the snapshot), off
+  allow: []               # regexes for matches that are not secrets, e.g. ["EXAMPLE_TOKEN_[0-9]+"]
+  deny: []                # extra regexes to treat as secrets
+
+api:                      # local HTTP API served by the watcher, for editor extensions
+  enabled: false          # serve the API while watching
+  listen: 127.0.0.1:0     # loopback only; port 0 picks a free port, written with the token to .git/timemachine_snapshots/api.json
+
+telemetry:                # usage statistics kept in .git/timemachine_snapshots, see timemachine stats
+  enabled: false          # record command usage, snapshot latencies and error categories
+  endpoint: ""            # http(s) URL that 'timemachine stats --send' posts the summary to (empty never sends)
+
+remote:                   # private remote backing up snapshots, see timemachine remote, push and pull
+  name: origin            # remote that push and pull use by default
+  machine: ""             # namespace of this machine's branches on the remote (empty uses the hostname)
+  push_interval: 0        # how often the watcher pushes snapshots, e.g. 15m (0 disables)
+
+backup:                   # incremental bundles in object storage, see timemachine backup and restore-from-backup
+  url: ""                 # s3://bucket/prefix, gs://bucket/prefix or a directory (credentials come from the aws and gcloud CLIs)
+  interval: 0             # how often the watcher uploads new snapshots, e.g. 1h (0 disables)
+
+detect:                   # what snapshots record about the tools changing the project
+  agents: false           # tag snapshots with the AI coding agents (claude, cursor, aider, copilot, ...) running in the project
 `
+
+// CreateDefaultConfigFile creates a default configuration file in the project root
+func (m *Manager) CreateDefaultConfigFile(projectRoot string) error {
+	configPath := filepath.Join(projectRoot, "timemachine.yaml")
+	
+	// Check if config file already exists
+	if _, err := os.Stat(configPath); err == nil {
+		return fmt.Errorf("configuration file already exists at %s", configPath)
+	}
 	
 	// Write the default configuration with secure permissions (0600 = owner read/write only)
 	// SECURITY: Use restrictive permissions to prevent other users from reading configuration
-	if err := os.WriteFile(configPath, []byte(defaultConfig), 0600); err != nil {
+	if err := os.WriteFile(configPath, []byte(defaultConfigFile), 0600); err != nil {
 		return fmt.Errorf("failed to write default config file: %w", err)
 	}
 	
 	return nil
-}
\ No newline at end of file
+}
+// Keys returns every configuration key in dotted form (e.g. "log.level"),
+// sorted, as accepted by 'config get' and environment variable mappings
+func Keys() []string {
+	var keys []string
+	configType := reflect.TypeOf(Config{})
+	for i := 0; i < configType.NumField(); i++ {
+		section := configType.Field(i)
+		prefix := section.Tag.Get("mapstructure")
+		for j := 0; j < section.Type.NumField(); j++ {
+			if name := section.Type.Field(j).Tag.Get("mapstructure"); name != "" {
+				keys = append(keys, prefix+"."+name)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Changed returns the keys, in dotted form and sorted, whose values differ
+// between two configurations
+func Changed(old, new *Config) []string {
+	var keys []string
+	oldValue, newValue := reflect.ValueOf(*old), reflect.ValueOf(*new)
+	configType := oldValue.Type()
+	for i := 0; i < configType.NumField(); i++ {
+		section := configType.Field(i)
+		prefix := section.Tag.Get("mapstructure")
+		for j := 0; j < section.Type.NumField(); j++ {
+			name := section.Type.Field(j).Tag.Get("mapstructure")
+			if name == "" {
+				continue
+			}
+			if !sameValue(oldValue.Field(i).Field(j), newValue.Field(i).Field(j)) {
+				keys = append(keys, prefix+"."+name)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// sameValue compares two setting values, treating nil and empty lists or
+// maps as equal
+func sameValue(a, b reflect.Value) bool {
+	if kind := a.Kind(); (kind == reflect.Slice || kind == reflect.Map) && a.Len() == 0 && b.Len() == 0 {
+		return true
+	}
+	return reflect.DeepEqual(a.Interface(), b.Interface())
+}
@@ -3,6 +3,8 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"reflect"
+	"sort"
 	"testing"
 	"time"
 )
@@ -365,4 +367,42 @@ func TestGetViper(t *testing.T) {
 	if viper != manager.viper {
 		t.Error("GetViper() returned different instance")
 	}
-}
\ No newline at end of file
+}
+func TestKeys(t *testing.T) {
+	keys := Keys()
+
+	if !sort.StringsAreSorted(keys) {
+		t.Errorf("Expected sorted keys, got %v", keys)
+	}
+	for _, expected := range []string{"log.level", "git.message_template", "ui.custom_theme", "secrets.mode"} {
+		found := false
+		for _, key := range keys {
+			found = found || key == expected
+		}
+		if !found {
+			t.Errorf("Expected %s among the keys", expected)
+		}
+	}
+}
+
+func TestChanged(t *testing.T) {
+	old := &Config{
+		Log:     LogConfig{Level: "info"},
+		Watcher: WatcherConfig{DebounceDelay: 2 * time.Second},
+		Hooks:   HooksConfig{PreSnapshot: nil},
+	}
+	new := &Config{
+		Log:     LogConfig{Level: "debug"},
+		Watcher: WatcherConfig{DebounceDelay: 2 * time.Second, BatchSize: 10},
+		Hooks:   HooksConfig{PreSnapshot: []string{}},
+		UI:      UIConfig{CustomTheme: map[string]string{"error": "red"}},
+	}
+
+	expected := []string{"log.level", "ui.custom_theme", "watcher.batch_size"}
+	if changed := Changed(old, new); !reflect.DeepEqual(changed, expected) {
+		t.Errorf("Expected %v, got %v", expected, changed)
+	}
+	if changed := Changed(old, old); len(changed) != 0 {
+		t.Errorf("Expected no changes, got %v", changed)
+	}
+}
@@ -0,0 +1,56 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseAge parses human-friendly ages such as "90m", "2h", "7d", "2w",
+// "1mo" and "1y". For backwards compatibility with `clean --older-than`,
+// a bare "m" suffix means months; use "min" for minutes.
+func ParseAge(s string) (time.Duration, error) {
+	s = strings.TrimSpace(strings.ToLower(s))
+	if s == "" {
+		return 0, fmt.Errorf("empty duration")
+	}
+
+	// Split into numeric prefix and unit suffix
+	i := 0
+	for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		i++
+	}
+	if i == 0 {
+		return 0, fmt.Errorf("invalid duration %q: missing number", s)
+	}
+	if i == len(s) {
+		return 0, fmt.Errorf("invalid duration %q: missing unit (use min, h, d, w, m or y)", s)
+	}
+
+	num, err := strconv.Atoi(s[:i])
+	if err != nil {
+		return 0, fmt.Errorf("invalid number in duration %q", s)
+	}
+
+	const day = 24 * time.Hour
+	var unit time.Duration
+	switch s[i:] {
+	case "min":
+		unit = time.Minute
+	case "h":
+		unit = time.Hour
+	case "d":
+		unit = day
+	case "w":
+		unit = 7 * day
+	case "m", "mo":
+		unit = 30 * day // months (approximate)
+	case "y":
+		unit = 365 * day
+	default:
+		return 0, fmt.Errorf("unsupported unit %q in duration %q (use min, h, d, w, m or y)", s[i:], s)
+	}
+
+	return time.Duration(num) * unit, nil
+}
@@ -0,0 +1,195 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SchemaID identifies the JSON Schema of timemachine.yaml
+const SchemaID = "https://github.com/deepakkumarnarayana/timemachine-cli/timemachine.schema.json"
+
+// durationPattern matches the durations settings accept, e.g. "90s" or "1h30m"
+const durationPattern = `^([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$|^0$`
+
+// Schema returns a JSON Schema (draft-07) of timemachine.yaml, generated from
+// the Config struct's tags: types from the fields, allowed values and ranges
+// from validate, defaults from default and descriptions from the comments of
+// the default configuration file. YAML language servers use it to validate
+// and complete the file.
+func Schema() ([]byte, error) {
+	descriptions := settingDescriptions()
+	sections := make(map[string]interface{})
+	configType := reflect.TypeOf(Config{})
+	for i := 0; i < configType.NumField(); i++ {
+		section := configType.Field(i)
+		prefix := section.Tag.Get("mapstructure")
+		properties := make(map[string]interface{})
+		for j := 0; j < section.Type.NumField(); j++ {
+			field := section.Type.Field(j)
+			name := field.Tag.Get("mapstructure")
+			if name == "" {
+				continue
+			}
+			property, err := fieldSchema(field, descriptions[prefix+"."+name])
+			if err != nil {
+				return nil, fmt.Errorf("%s.%s: %w", prefix, name, err)
+			}
+			properties[name] = property
+		}
+		sectionSchema := map[string]interface{}{
+			"type":                 "object",
+			"properties":           properties,
+			"additionalProperties": false,
+		}
+		if description := descriptions[prefix]; description != "" {
+			sectionSchema["description"] = description
+		}
+		sections[prefix] = sectionSchema
+	}
+
+	return json.MarshalIndent(map[string]interface{}{
+		"$schema":              "http://json-schema.org/draft-07/schema#",
+		"$id":                  SchemaID,
+		"title":                "TimeMachine CLI configuration (timemachine.yaml)",
+		"type":                 "object",
+		"properties":           sections,
+		"additionalProperties": false,
+	}, "", "  ")
+}
+
+// fieldSchema describes one setting: its JSON type, and the values the
+// validate and default tags allow and start with
+func fieldSchema(field reflect.StructField, description string) (map[string]interface{}, error) {
+	schema := make(map[string]interface{})
+	isDuration := field.Type == reflect.TypeOf(time.Duration(0))
+	switch {
+	case isDuration:
+		// A bare 0 reads as a number in YAML
+		schema["type"] = []string{"string", "integer"}
+		schema["pattern"] = durationPattern
+	case field.Type.Kind() == reflect.Int:
+		schema["type"] = "integer"
+	case field.Type.Kind() == reflect.Bool:
+		schema["type"] = "boolean"
+	case field.Type.Kind() == reflect.String:
+		schema["type"] = "string"
+	case field.Type.Kind() == reflect.Slice && field.Type.Elem().Kind() == reflect.String:
+		schema["type"] = "array"
+		schema["items"] = map[string]interface{}{"type": "string"}
+	case field.Type.Kind() == reflect.Slice && field.Type.Elem().Kind() == reflect.Struct:
+		items, err := structSchema(field.Type.Elem())
+		if err != nil {
+			return nil, err
+		}
+		schema["type"] = "array"
+		schema["items"] = items
+	case field.Type.Kind() == reflect.Map && field.Type.Elem().Kind() == reflect.String:
+		schema["type"] = "object"
+		schema["additionalProperties"] = map[string]interface{}{"type": "string"}
+	default:
+		return nil, fmt.Errorf("unsupported type %s", field.Type)
+	}
+
+	var bounds []string
+	for _, rule := range strings.Split(field.Tag.Get("validate"), ",") {
+		key, value, _ := strings.Cut(rule, "=")
+		switch key {
+		case "oneof":
+			schema["enum"] = strings.Fields(value)
+		case "min", "max":
+			if isDuration {
+				// Ranges of duration strings can't be expressed in JSON Schema
+				bounds = append(bounds, key+" "+value)
+				continue
+			}
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s %q", key, value)
+			}
+			schema[map[string]string{"min": "minimum", "max": "maximum"}[key]] = n
+		}
+	}
+	if len(bounds) > 0 {
+		description = strings.TrimSpace(description + " (" + strings.Join(bounds, ", ") + ")")
+	}
+	if description != "" {
+		schema["description"] = description
+	}
+
+	if value, ok := field.Tag.Lookup("default"); ok {
+		switch schema["type"] {
+		case "integer":
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid default %q", value)
+			}
+			schema["default"] = n
+		case "boolean":
+			schema["default"] = value == "true"
+		case "array":
+			schema["default"] = []string{}
+		default:
+			schema["default"] = value
+		}
+	}
+	return schema, nil
+}
+
+// structSchema describes the entries of a list setting, such as
+// watcher.debounce_overrides: objects with every field required
+func structSchema(structType reflect.Type) (map[string]interface{}, error) {
+	properties := make(map[string]interface{})
+	var required []string
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		name := field.Tag.Get("mapstructure")
+		property, err := fieldSchema(field, "")
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		properties[name] = property
+		required = append(required, name)
+	}
+	return map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"required":             required,
+		"additionalProperties": false,
+	}, nil
+}
+
+// settingDescriptions reads the comments of the default configuration file,
+// keyed by section ("watcher") and setting ("watcher.batch_size"). Settings
+// commented out there, like ui.custom_theme, are described too.
+func settingDescriptions() map[string]string {
+	descriptions := make(map[string]string)
+	section := ""
+	for _, line := range strings.Split(defaultConfigFile, "\n") {
+		indented := strings.HasPrefix(line, "  ")
+		trimmed := strings.TrimSpace(line)
+		if indented && strings.HasPrefix(trimmed, "# ") {
+			trimmed = strings.TrimPrefix(trimmed, "# ")
+		}
+		name, rest, ok := strings.Cut(trimmed, ":")
+		if !ok || name == "" || strings.ContainsAny(name, " #") {
+			continue
+		}
+		if !indented {
+			section = name
+		}
+		_, comment, ok := strings.Cut(rest, " #")
+		if !ok {
+			continue
+		}
+		key := section
+		if indented {
+			key += "." + name
+		}
+		descriptions[key] = strings.TrimSpace(comment)
+	}
+	return descriptions
+}
@@ -0,0 +1,58 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSchema(t *testing.T) {
+	data, err := Schema()
+	if err != nil {
+		t.Fatalf("Schema failed: %v", err)
+	}
+	var schema struct {
+		ID         string `json:"$id"`
+		Properties map[string]struct {
+			Properties map[string]map[string]interface{} `json:"properties"`
+		} `json:"properties"`
+	}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatalf("Schema is not valid JSON: %v", err)
+	}
+	if schema.ID != SchemaID {
+		t.Errorf("Expected $id %s, got %s", SchemaID, schema.ID)
+	}
+
+	// Every setting is described, so a new one can't be left out
+	for _, key := range Keys() {
+		section, name, _ := strings.Cut(key, ".")
+		property, ok := schema.Properties[section].Properties[name]
+		if !ok {
+			t.Errorf("Schema is missing %s", key)
+			continue
+		}
+		if property["description"] == nil {
+			t.Errorf("Schema has no description for %s; comment it in the default configuration file", key)
+		}
+	}
+
+	level := schema.Properties["log"].Properties["level"]
+	if !reflect.DeepEqual(level["enum"], []interface{}{"debug", "info", "warn", "error"}) || level["default"] != "info" {
+		t.Errorf("Expected log.level to list its values and default, got %v", level)
+	}
+	batchSize := schema.Properties["watcher"].Properties["batch_size"]
+	if batchSize["minimum"] != 1.0 || batchSize["maximum"] != 1000.0 || batchSize["default"] != 100.0 {
+		t.Errorf("Expected watcher.batch_size to have its range and default, got %v", batchSize)
+	}
+	// 0 is accepted, as the validator does, and means the default
+	hookTimeout := schema.Properties["hooks"].Properties["timeout"]
+	if description, _ := hookTimeout["description"].(string); !strings.Contains(description, "(min 0, max 1h)") {
+		t.Errorf("Expected hooks.timeout to allow 0, got %v", hookTimeout)
+	}
+	debounce := schema.Properties["watcher"].Properties["debounce_delay"]
+	if !strings.Contains(debounce["description"].(string), "(min 100ms, max 10s)") || debounce["pattern"] == nil {
+		t.Errorf("Expected watcher.debounce_delay to describe its range, got %v", debounce)
+	}
+}
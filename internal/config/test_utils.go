@@ -89,10 +89,17 @@ log:
 
 watcher:
   debounce_delay: 2s
+  debounce_overrides: []
   max_watched_files: 100000
   ignore_patterns: ["*.log", "*.tmp"]
+  include_paths: []
   batch_size: 100
+  batch_window: 30s
+  interval_snapshot: 0
+  storm_threshold: 1000
   enable_recursive: true
+  respect_gitignore: false
+  churn_filters: []
 
 cache:
   max_entries: 10000
@@ -103,14 +110,69 @@ cache:
 git:
   cleanup_threshold: 100
   auto_gc: true
+  auto_heal: true
   max_commits: 1000
+  max_repo_size_mb: 0
   use_shallow_clone: false
+  backend: exec
+  min_free_space_mb: 100
+  verify_interval: 6h
+  verify_sample: 200
+  message_template: "{{.Time}} [{{.Branch}}] {{.FileCount}} files"
+  signing_key: ""
+  provenance: false
 
 ui:
   progress_indicators: true
   color_output: true
   pager: auto
   table_format: table
+  list_stats: auto
+  theme: default
+  syntax_theme: monokai
+  language: auto
+  emoji: true
+
+retention:
+  max_age: ""
+  max_total_size_mb: 0
+  keep_hourly: 0
+  keep_daily: 0
+  keep_weekly: 0
+
+hooks:
+  pre_snapshot: []
+  post_snapshot: []
+  post_restore: []
+  timeout: 1m
+
+metrics:
+  listen: ""
+
+secrets:
+  mode: warn
+  allow: []
+  deny: []
+
+api:
+  enabled: false
+  listen: 127.0.0.1:0
+
+telemetry:
+  enabled: false
+  endpoint: ""
+
+remote:
+  name: origin
+  machine: ""
+  push_interval: 0
+
+backup:
+  url: ""
+  interval: 0
+
+detect:
+  agents: false
 `
 }
 
@@ -2,11 +2,20 @@ package config
 
 import (
 	"fmt"
+	"net"
 	"net/url"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+	"text/template"
 	"time"
+
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/render"
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/ui"
 )
 
 // Validator provides configuration validation
@@ -48,6 +57,46 @@ func (v *Validator) Validate(config *Config) error {
 		errors = append(errors, fmt.Sprintf("ui config: %v", err))
 	}
 	
+	// Validate retention configuration
+	if err := v.validateRetentionConfig(&config.Retention); err != nil {
+		errors = append(errors, fmt.Sprintf("retention config: %v", err))
+	}
+	
+	// Validate hooks configuration
+	if err := v.validateHooksConfig(&config.Hooks); err != nil {
+		errors = append(errors, fmt.Sprintf("hooks config: %v", err))
+	}
+	
+	// Validate metrics configuration
+	if err := v.validateMetricsConfig(&config.Metrics); err != nil {
+		errors = append(errors, fmt.Sprintf("metrics config: %v", err))
+	}
+	
+	// Validate secrets configuration
+	if err := v.validateSecretsConfig(&config.Secrets); err != nil {
+		errors = append(errors, fmt.Sprintf("secrets config: %v", err))
+	}
+	
+	// Validate API configuration
+	if err := v.validateAPIConfig(&config.API); err != nil {
+		errors = append(errors, fmt.Sprintf("api config: %v", err))
+	}
+	
+	// Validate telemetry configuration
+	if err := v.validateTelemetryConfig(&config.Telemetry); err != nil {
+		errors = append(errors, fmt.Sprintf("telemetry config: %v", err))
+	}
+	
+	// Validate remote configuration
+	if err := v.validateRemoteConfig(&config.Remote); err != nil {
+		errors = append(errors, fmt.Sprintf("remote config: %v", err))
+	}
+	
+	// Validate backup configuration
+	if err := v.validateBackupConfig(&config.Backup); err != nil {
+		errors = append(errors, fmt.Sprintf("backup config: %v", err))
+	}
+	
 	if len(errors) > 0 {
 		return fmt.Errorf("validation errors: %s", strings.Join(errors, "; "))
 	}
@@ -99,6 +148,35 @@ func (v *Validator) validateWatcherConfig(config *WatcherConfig) error {
 		errors = append(errors, "debounce_delay must be at most 10s")
 	}
 	
+	// Validate debounce overrides
+	for i, override := range config.DebounceOverrides {
+		if strings.TrimSpace(override.Pattern) == "" || strings.Contains(override.Pattern, "..") {
+			errors = append(errors, fmt.Sprintf("debounce override %d needs a pattern without '..'", i))
+		} else if _, err := path.Match(override.Pattern, ""); err != nil {
+			errors = append(errors, fmt.Sprintf("debounce override %d has an invalid pattern %q", i, override.Pattern))
+		}
+		if override.Delay < 100*time.Millisecond || override.Delay > 10*time.Minute {
+			errors = append(errors, fmt.Sprintf("debounce override %d delay must be between 100ms and 10m", i))
+		}
+	}
+
+	// Validate churn filters
+	for i, filter := range config.ChurnFilters {
+		if strings.TrimSpace(filter.Pattern) == "" || strings.Contains(filter.Pattern, "..") {
+			errors = append(errors, fmt.Sprintf("churn filter %d needs a pattern without '..'", i))
+		} else if _, err := path.Match(filter.Pattern, ""); err != nil {
+			errors = append(errors, fmt.Sprintf("churn filter %d has an invalid pattern %q", i, filter.Pattern))
+		}
+		if len(filter.Normalize) == 0 {
+			errors = append(errors, fmt.Sprintf("churn filter %d needs at least one normalizer (%s)", i, strings.Join(ChurnNormalizers, ", ")))
+		}
+		for _, normalizer := range filter.Normalize {
+			if !slices.Contains(ChurnNormalizers, normalizer) {
+				errors = append(errors, fmt.Sprintf("churn filter %d has an unknown normalizer %q (use %s)", i, normalizer, strings.Join(ChurnNormalizers, ", ")))
+			}
+		}
+	}
+
 	// Validate max watched files
 	if config.MaxWatchedFiles < 1000 {
 		errors = append(errors, "max_watched_files must be at least 1000")
@@ -114,6 +192,33 @@ func (v *Validator) validateWatcherConfig(config *WatcherConfig) error {
 	if config.BatchSize > 1000 {
 		errors = append(errors, "batch_size must be at most 1000")
 	}
+
+	// Validate batch window
+	if config.BatchWindow < 0 {
+		errors = append(errors, "batch_window must not be negative")
+	}
+	if config.BatchWindow > 10*time.Minute {
+		errors = append(errors, "batch_window must be at most 10m")
+	}
+
+	// Validate interval snapshots
+	if config.IntervalSnapshot < 0 {
+		errors = append(errors, "interval_snapshot must not be negative")
+	}
+	if config.IntervalSnapshot > 0 && config.IntervalSnapshot < 10*time.Second {
+		errors = append(errors, "interval_snapshot must be at least 10s (or 0 to disable)")
+	}
+	if config.IntervalSnapshot > 24*time.Hour {
+		errors = append(errors, "interval_snapshot must be at most 24h")
+	}
+
+	// Validate change storm threshold
+	if config.StormThreshold < 0 {
+		errors = append(errors, "storm_threshold must not be negative")
+	}
+	if config.StormThreshold > 1000000 {
+		errors = append(errors, "storm_threshold must be at most 1000000")
+	}
 	
 	// Validate ignore patterns (basic syntax check)
 	for i, pattern := range config.IgnorePatterns {
@@ -122,6 +227,13 @@ func (v *Validator) validateWatcherConfig(config *WatcherConfig) error {
 		}
 	}
 	
+	// Validate include paths: subtrees of the project
+	for i, path := range config.IncludePaths {
+		if strings.Contains(path, "..") || filepath.IsAbs(path) || strings.HasPrefix(path, "/") {
+			errors = append(errors, fmt.Sprintf("include path %d must be relative to the project root without '..'", i))
+		}
+	}
+	
 	if len(errors) > 0 {
 		return fmt.Errorf("%s", strings.Join(errors, "; "))
 	}
@@ -189,6 +301,50 @@ func (v *Validator) validateGitConfig(config *GitConfig) error {
 		errors = append(errors, "cleanup_threshold must be less than max_commits")
 	}
 	
+	if config.MaxRepoSizeMB < 0 {
+		errors = append(errors, "max_repo_size_mb must not be negative")
+	}
+
+	// Validate free space threshold (0 disables the check)
+	if config.MinFreeSpaceMB < 0 {
+		errors = append(errors, "min_free_space_mb must not be negative")
+	}
+	if config.MinFreeSpaceMB > 1048576 {
+		errors = append(errors, "min_free_space_mb must be at most 1048576 (1 TB)")
+	}
+	
+	// Validate integrity checks (0 disables them)
+	if config.VerifyInterval < 0 || (config.VerifyInterval > 0 && config.VerifyInterval < time.Minute) {
+		errors = append(errors, "verify_interval must be 0 (disabled) or at least 1m")
+	}
+	if config.VerifyInterval > 7*24*time.Hour {
+		errors = append(errors, "verify_interval must be at most 168h")
+	}
+	if config.VerifySample < 0 || config.VerifySample > 100000 {
+		errors = append(errors, "verify_sample must be between 0 and 100000")
+	}
+	
+	// Validate the snapshot message template (empty uses the default)
+	if config.MessageTemplate != "" {
+		funcs := template.FuncMap{"join": strings.Join}
+		if _, err := template.New("message").Funcs(funcs).Parse(config.MessageTemplate); err != nil {
+			errors = append(errors, fmt.Sprintf("invalid message_template: %v", err))
+		}
+	}
+	
+	// Validate the signing key: a key file, a public key or a GPG key ID,
+	// never something git would read as an option
+	if config.SigningKey != "" && (strings.HasPrefix(config.SigningKey, "-") || strings.ContainsAny(config.SigningKey, "\n\r")) {
+		errors = append(errors, "signing_key must be an SSH key file, an \"ssh-...\" public key or a GPG key ID")
+	}
+	
+	// Validate backend (empty means the default exec backend)
+	validBackends := []string{"exec", "native"}
+	if config.Backend != "" && !v.stringInSlice(config.Backend, validBackends) {
+		errors = append(errors, fmt.Sprintf("invalid backend '%s', must be one of: %s", 
+			config.Backend, strings.Join(validBackends, ", ")))
+	}
+	
 	if len(errors) > 0 {
 		return fmt.Errorf("%s", strings.Join(errors, "; "))
 	}
@@ -214,6 +370,231 @@ func (v *Validator) validateUIConfig(config *UIConfig) error {
 			config.TableFormat, strings.Join(validTableFormats, ", ")))
 	}
 	
+	// Validate list stats (empty falls back to auto)
+	validListStats := []string{"auto", "always", "never"}
+	if config.ListStats != "" && !v.stringInSlice(config.ListStats, validListStats) {
+		errors = append(errors, fmt.Sprintf("invalid list_stats '%s', must be one of: %s",
+			config.ListStats, strings.Join(validListStats, ", ")))
+	}
+
+	// Validate theme (empty falls back to default)
+	if config.Theme != "" {
+		if _, err := ui.NewTheme(config.Theme, config.CustomTheme); err != nil {
+			errors = append(errors, fmt.Sprintf("invalid ui theme: %v", err))
+		}
+	}
+
+	// Validate language (empty falls back to auto)
+	if config.Language != "" && !v.stringInSlice(config.Language, ui.Languages) {
+		errors = append(errors, fmt.Sprintf("invalid language '%s', must be one of: %s",
+			config.Language, strings.Join(ui.Languages, ", ")))
+	}
+
+	// Validate syntax theme (empty falls back to the default)
+	if err := render.ValidateSyntaxTheme(config.SyntaxTheme); err != nil {
+		errors = append(errors, err.Error())
+	}
+	
+	if len(errors) > 0 {
+		return fmt.Errorf("%s", strings.Join(errors, "; "))
+	}
+	
+	return nil
+}
+
+// validateRetentionConfig validates retention configuration
+func (v *Validator) validateRetentionConfig(config *RetentionConfig) error {
+	var errors []string
+	
+	// Validate max age (empty disables it)
+	if config.MaxAge != "" {
+		if _, err := ParseAge(config.MaxAge); err != nil {
+			errors = append(errors, fmt.Sprintf("invalid max_age: %v", err))
+		}
+	}
+	
+	if config.MaxTotalSizeMB < 0 {
+		errors = append(errors, "max_total_size_mb must not be negative")
+	}
+	if config.KeepHourly < 0 || config.KeepDaily < 0 || config.KeepWeekly < 0 {
+		errors = append(errors, "keep_hourly, keep_daily and keep_weekly must not be negative")
+	}
+	
+	if len(errors) > 0 {
+		return fmt.Errorf("%s", strings.Join(errors, "; "))
+	}
+	
+	return nil
+}
+
+// validateHooksConfig validates hooks configuration
+func (v *Validator) validateHooksConfig(config *HooksConfig) error {
+	var errors []string
+	
+	hooks := map[string][]string{
+		"pre_snapshot":  config.PreSnapshot,
+		"post_snapshot": config.PostSnapshot,
+		"post_restore":  config.PostRestore,
+	}
+	for _, name := range []string{"pre_snapshot", "post_snapshot", "post_restore"} {
+		for _, command := range hooks[name] {
+			if strings.TrimSpace(command) == "" {
+				errors = append(errors, fmt.Sprintf("%s contains an empty command", name))
+				break
+			}
+		}
+	}
+	
+	// Validate timeout (0 uses the default)
+	if config.Timeout != 0 && (config.Timeout < time.Second || config.Timeout > time.Hour) {
+		errors = append(errors, "timeout must be between 1s and 1h")
+	}
+	
+	if len(errors) > 0 {
+		return fmt.Errorf("%s", strings.Join(errors, "; "))
+	}
+	
+	return nil
+}
+
+// validateMetricsConfig validates metrics configuration
+func (v *Validator) validateMetricsConfig(config *MetricsConfig) error {
+	// Empty disables the endpoint
+	if config.Listen == "" {
+		return nil
+	}
+	
+	_, port, err := net.SplitHostPort(config.Listen)
+	if err != nil {
+		return fmt.Errorf("invalid listen address %q: expected host:port", config.Listen)
+	}
+	if n, err := strconv.Atoi(port); err != nil || n < 0 || n > 65535 {
+		return fmt.Errorf("invalid listen port %q", port)
+	}
+	
+	return nil
+}
+
+// validateAPIConfig validates API configuration
+func (v *Validator) validateAPIConfig(config *APIConfig) error {
+	// Empty uses the default
+	if config.Listen == "" {
+		return nil
+	}
+	
+	host, port, err := net.SplitHostPort(config.Listen)
+	if err != nil {
+		return fmt.Errorf("invalid listen address %q: expected host:port", config.Listen)
+	}
+	if n, err := strconv.Atoi(port); err != nil || n < 0 || n > 65535 {
+		return fmt.Errorf("invalid listen port %q", port)
+	}
+	// The API restores files: never expose it beyond this machine
+	if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		return fmt.Errorf("listen address %q is not a loopback address", config.Listen)
+	}
+	
+	return nil
+}
+
+// validateTelemetryConfig validates telemetry configuration
+func (v *Validator) validateTelemetryConfig(config *TelemetryConfig) error {
+	// Empty never sends anything
+	if config.Endpoint == "" {
+		return nil
+	}
+	
+	endpoint, err := url.Parse(config.Endpoint)
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		return fmt.Errorf("invalid endpoint %q: expected an http or https URL", config.Endpoint)
+	}
+	
+	return nil
+}
+
+// validateRemoteConfig validates remote configuration
+func (v *Validator) validateRemoteConfig(config *RemoteConfig) error {
+	var errors []string
+	
+	// Both end up in ref names and git arguments
+	if config.Name != "" && !remoteNamePattern.MatchString(config.Name) {
+		errors = append(errors, fmt.Sprintf("invalid name %q: use letters, digits, '.', '_' and '-'", config.Name))
+	}
+	if config.Machine != "" && !remoteNamePattern.MatchString(config.Machine) {
+		errors = append(errors, fmt.Sprintf("invalid machine %q: use letters, digits, '.', '_' and '-'", config.Machine))
+	}
+	
+	// 0 disables pushing from the watcher
+	if config.PushInterval < 0 || (config.PushInterval > 0 && config.PushInterval < time.Minute) {
+		errors = append(errors, "push_interval must be 0 (disabled) or at least 1m")
+	}
+	if config.PushInterval > 24*time.Hour {
+		errors = append(errors, "push_interval must be at most 24h")
+	}
+	
+	if len(errors) > 0 {
+		return fmt.Errorf("%s", strings.Join(errors, "; "))
+	}
+	return nil
+}
+
+// validateBackupConfig validates backup configuration
+func (v *Validator) validateBackupConfig(config *BackupConfig) error {
+	var errors []string
+	
+	// Credentials stay with the aws and gcloud CLIs, never in the file
+	if config.URL != "" && !filepath.IsAbs(config.URL) {
+		if location, err := url.Parse(config.URL); err != nil {
+			errors = append(errors, fmt.Sprintf("invalid url %q: %v", config.URL, err))
+		} else if location.User != nil || location.RawQuery != "" {
+			errors = append(errors, "url must not contain credentials or query parameters; the aws and gcloud CLIs provide credentials")
+		} else if location.Scheme != "" && location.Scheme != "s3" && location.Scheme != "gs" && location.Scheme != "file" {
+			errors = append(errors, fmt.Sprintf("unsupported url scheme %q, must be one of: s3, gs, file", location.Scheme))
+		} else if (location.Scheme == "s3" || location.Scheme == "gs") && location.Host == "" {
+			errors = append(errors, fmt.Sprintf("url %q names no bucket", config.URL))
+		} else if location.Scheme == "" || (location.Scheme == "file" && !filepath.IsAbs(filepath.FromSlash(location.Path))) {
+			errors = append(errors, fmt.Sprintf("url %q must be s3://, gs:// or an absolute directory", config.URL))
+		}
+	}
+	
+	// 0 disables uploads from the watcher
+	if config.Interval < 0 || (config.Interval > 0 && config.Interval < time.Minute) {
+		errors = append(errors, "interval must be 0 (disabled) or at least 1m")
+	}
+	if config.Interval > 7*24*time.Hour {
+		errors = append(errors, "interval must be at most 168h")
+	}
+	if config.Interval > 0 && config.URL == "" {
+		errors = append(errors, "interval needs a url to upload to")
+	}
+	
+	if len(errors) > 0 {
+		return fmt.Errorf("%s", strings.Join(errors, "; "))
+	}
+	return nil
+}
+
+// remoteNamePattern matches remote and machine names that are safe in refs
+var remoteNamePattern = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9._-]*$`)
+
+// validateSecretsConfig validates secrets configuration
+func (v *Validator) validateSecretsConfig(config *SecretsConfig) error {
+	var errors []string
+	
+	// Empty uses the default (warn)
+	if config.Mode != "" && !v.stringInSlice(config.Mode, []string{"warn", "block", "off"}) {
+		errors = append(errors, fmt.Sprintf("invalid mode '%s', must be one of: warn, block, off", config.Mode))
+	}
+	
+	patterns := map[string][]string{"allow": config.Allow, "deny": config.Deny}
+	for _, name := range []string{"allow", "deny"} {
+		for _, pattern := range patterns[name] {
+			if _, err := regexp.Compile(pattern); err != nil {
+				errors = append(errors, fmt.Sprintf("invalid %s pattern %q: %v", name, pattern, err))
+			}
+		}
+	}
+	
 	if len(errors) > 0 {
 		return fmt.Errorf("%s", strings.Join(errors, "; "))
 	}
@@ -458,9 +839,16 @@ Log Configuration:
 
 Watcher Configuration:
   - debounce_delay: between 100ms and 10s
+  - debounce_overrides: patterns without '..', delays between 100ms and 10m
+  - churn_filters: patterns without '..', normalizers from timestamps, json,
+    sort_lines and whitespace
   - max_watched_files: between 1,000 and 1,000,000
   - batch_size: between 1 and 1,000
+  - batch_window: between 0 (no limit) and 10m
+  - interval_snapshot: 0 (off), or between 10s and 24h
+  - storm_threshold: between 0 (never) and 1,000,000
   - ignore_patterns: no '..' sequences allowed
+  - include_paths: relative to the project root, no '..' sequences allowed
 
 Cache Configuration:
   - max_entries: between 1,000 and 100,000
@@ -470,9 +858,59 @@ Cache Configuration:
 Git Configuration:
   - cleanup_threshold: between 10 and 10,000 (must be < max_commits)
   - max_commits: between 50 and 50,000
+  - max_repo_size_mb: not negative (0 disables the limit)
+  - backend: must be 'exec' or 'native'
+  - min_free_space_mb: between 0 (disabled) and 1,048,576
+  - verify_interval: 0 (disabled) or between 1m and 168h
+  - verify_sample: between 0 and 100,000
+  - message_template: Go template; .Time, .Date, .Timestamp, .Branch,
+    .FileCount, .Files, .Dirs and join, e.g. "{{.Time}} [{{.Branch}}]"
+  - signing_key: an SSH key file, an "ssh-..." public key or a GPG key ID
+
+Retention Configuration (0 or empty disables a rule):
+  - max_age: an age such as 12h, 30d, 2w or 6m
+  - max_total_size_mb, keep_hourly, keep_daily, keep_weekly: not negative
+
+Hooks Configuration:
+  - pre_snapshot, post_snapshot, post_restore: lists of non-empty commands
+  - timeout: 0 (default of 1m) or between 1s and 1h
+
+Metrics Configuration:
+  - listen: empty (disabled) or host:port, e.g. 127.0.0.1:9190
+
+Secrets Configuration:
+  - mode: must be 'warn', 'block', or 'off'
+  - allow, deny: lists of regular expressions (Go syntax)
+
+API Configuration:
+  - listen: loopback host:port, e.g. 127.0.0.1:0 (port 0 picks a free port)
+
+Telemetry Configuration:
+  - endpoint: empty (never sends) or an http(s) URL
+
+Remote Configuration:
+  - name, machine: letters, digits, '.', '_' and '-' (empty machine uses the hostname)
+  - push_interval: 0 (disabled) or between 1m and 24h
+
+Backup Configuration:
+  - url: s3://bucket/prefix, gs://bucket/prefix or a directory, without credentials
+  - interval: 0 (disabled) or between 1m and 168h; needs a url
+
+Detect Configuration:
+  - agents: true or false; scans processes on Linux, macOS and other systems with ps
 
 UI Configuration:
   - pager: must be 'auto', 'always', or 'never'
   - table_format: must be 'table', 'json', or 'yaml'
+  - list_stats: must be 'auto', 'always', or 'never'
+  - theme: must be 'default', 'dark', 'light', 'monochrome', or 'custom'
+  - custom_theme: role -> color, e.g. "error: bold red"; roles are success,
+    warning, error, info, heading, hash, muted, added, modified, deleted,
+    renamed, diff_header, diff_hunk; colors are black, red, green, yellow,
+    blue, magenta, cyan, white with optional hi-/bg- prefixes, plus bold,
+    faint, italic, underline
+  - language: must be 'auto', 'en', 'ja', or 'zh'
+  - syntax_theme: 'none' or a chroma style such as 'monokai' (default),
+    'github', 'dracula' or 'solarized-dark'
 `
 }
\ No newline at end of file
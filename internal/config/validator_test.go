@@ -157,6 +157,36 @@ func TestValidateWatcherConfig(t *testing.T) {
 			},
 			expectError: true,
 		},
+		{
+			name: "valid churn filters",
+			config: WatcherConfig{
+				DebounceDelay:   2 * time.Second,
+				MaxWatchedFiles: 100000,
+				BatchSize:       100,
+				ChurnFilters:    []ChurnFilter{{Pattern: "package-lock.json", Normalize: []string{"json", "whitespace"}}},
+			},
+			expectError: false,
+		},
+		{
+			name: "unknown churn normalizer",
+			config: WatcherConfig{
+				DebounceDelay:   2 * time.Second,
+				MaxWatchedFiles: 100000,
+				BatchSize:       100,
+				ChurnFilters:    []ChurnFilter{{Pattern: "*.lock", Normalize: []string{"yaml"}}},
+			},
+			expectError: true,
+		},
+		{
+			name: "churn filter without normalizers",
+			config: WatcherConfig{
+				DebounceDelay:   2 * time.Second,
+				MaxWatchedFiles: 100000,
+				BatchSize:       100,
+				ChurnFilters:    []ChurnFilter{{Pattern: "*.lock"}},
+			},
+			expectError: true,
+		},
 	}
 	
 	for _, tt := range tests {
@@ -375,6 +405,35 @@ func TestValidateUIConfig(t *testing.T) {
 			},
 			expectError: true,
 		},
+		{
+			name: "custom theme",
+			config: UIConfig{
+				Pager:       "auto",
+				TableFormat: "table",
+				Theme:       "custom",
+				CustomTheme: map[string]string{"error": "bold hi-red", "hash": "magenta"},
+			},
+			expectError: false,
+		},
+		{
+			name: "invalid theme",
+			config: UIConfig{
+				Pager:       "auto",
+				TableFormat: "table",
+				Theme:       "solarized",
+			},
+			expectError: true,
+		},
+		{
+			name: "invalid custom theme color",
+			config: UIConfig{
+				Pager:       "auto",
+				TableFormat: "table",
+				Theme:       "custom",
+				CustomTheme: map[string]string{"error": "crimson"},
+			},
+			expectError: true,
+		},
 	}
 	
 	for _, tt := range tests {
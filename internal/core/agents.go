@@ -0,0 +1,154 @@
+package core
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// agentTrailer names, in a snapshot message, the AI coding agents that were
+// running in the project when it was taken
+const agentTrailer = "Snapshot-Agent: "
+
+// agentExecutables maps the executables of well-known AI coding agents to
+// the names snapshots record
+var agentExecutables = map[string]string{
+	"aider":        "aider",
+	"claude":       "claude",
+	"codex":        "codex",
+	"copilot":      "copilot",
+	"cursor":       "cursor",
+	"cursor-agent": "cursor",
+	"gemini":       "gemini",
+}
+
+// agentPackages finds agents run through an interpreter by their package
+// in the script's path
+var agentPackages = map[string]string{
+	"@anthropic-ai/claude-code": "claude",
+	"@github/copilot":           "copilot",
+	"@google/gemini-cli":        "gemini",
+	"@openai/codex":             "codex",
+	"site-packages/aider":       "aider",
+}
+
+// agentInterpreters run agents shipped as scripts
+var agentInterpreters = map[string]bool{
+	"node": true, "bun": true, "deno": true, "npx": true,
+	"python": true, "python3": true, "pipx": true, "uv": true, "uvx": true,
+}
+
+// agentCacheTTL is how long a process scan is reused: snapshots come in
+// bursts, and agents run for minutes
+const agentCacheTTL = 10 * time.Second
+
+var agentCache struct {
+	sync.Mutex
+	root   string
+	at     time.Time
+	agents []string
+}
+
+// matchAgent returns the agent a process command line belongs to, if any
+func matchAgent(argv []string) string {
+	if len(argv) == 0 {
+		return ""
+	}
+	executable := executableName(argv[0])
+	if agent, ok := agentExecutables[executable]; ok {
+		return agent
+	}
+	if !agentInterpreters[strings.TrimRight(executable, "0123456789.")] {
+		return ""
+	}
+	args := argv[1:]
+	for _, arg := range args {
+		for pkg, agent := range agentPackages {
+			if strings.Contains(filepath.ToSlash(arg), pkg) {
+				return agent
+			}
+		}
+	}
+	// The script or module run: node .../bin/claude, python -m aider
+	for i, arg := range args {
+		if arg == "-m" && i+1 < len(args) {
+			return agentExecutables[args[i+1]]
+		}
+		if !strings.HasPrefix(arg, "-") {
+			return agentExecutables[executableName(arg)]
+		}
+	}
+	return ""
+}
+
+// executableName is a command's base name without a Windows extension
+func executableName(path string) string {
+	name := strings.ToLower(filepath.Base(filepath.FromSlash(path)))
+	return strings.TrimSuffix(name, ".exe")
+}
+
+// withinProject reports whether dir is the project root or below it
+func withinProject(root, dir string) bool {
+	rel, err := filepath.Rel(root, dir)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// DetectAgents returns the AI coding agents running in the project, by
+// name and sorted, scanning processes at most every agentCacheTTL
+func (g *GitManager) DetectAgents() []string {
+	agentCache.Lock()
+	defer agentCache.Unlock()
+	if agentCache.root == g.State.ProjectRoot && time.Since(agentCache.at) < agentCacheTTL {
+		return agentCache.agents
+	}
+
+	found := make(map[string]bool)
+	for _, process := range listProcesses() {
+		if agent := matchAgent(process.argv); agent != "" && withinProject(g.State.ProjectRoot, process.cwd) {
+			found[agent] = true
+		}
+	}
+	agents := make([]string, 0, len(found))
+	for agent := range found {
+		agents = append(agents, agent)
+	}
+	sort.Strings(agents)
+
+	agentCache.root, agentCache.at, agentCache.agents = g.State.ProjectRoot, time.Now(), agents
+	return agents
+}
+
+// agentsTrailer returns the Snapshot-Agent trailer of a new snapshot, empty
+// unless detect.agents is set and an agent is running
+func (g *GitManager) agentsTrailer() string {
+	if g.State.Config == nil || !g.State.Config.Detect.Agents {
+		return ""
+	}
+	agents := g.DetectAgents()
+	if len(agents) == 0 {
+		return ""
+	}
+	return agentTrailer + strings.Join(agents, ", ")
+}
+
+// hasAgentTrailer reports whether a snapshot message names agent
+func hasAgentTrailer(message, agent string) bool {
+	for _, line := range strings.Split(message, "\n") {
+		if names, ok := strings.CutPrefix(line, agentTrailer); ok {
+			for _, name := range strings.Split(names, ", ") {
+				if strings.EqualFold(name, agent) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// process is a running process as far as agent detection cares
+type process struct {
+	argv []string
+	cwd  string
+}
@@ -0,0 +1,40 @@
+//go:build linux
+
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// listProcesses reads the command line and working directory of every
+// process this user may inspect from /proc
+func listProcesses() []process {
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil
+	}
+
+	var processes []process
+	for _, entry := range entries {
+		if _, err := strconv.Atoi(entry.Name()); err != nil {
+			continue
+		}
+		cmdline, err := os.ReadFile(filepath.Join("/proc", entry.Name(), "cmdline"))
+		if err != nil || len(cmdline) == 0 {
+			continue // Exited, a kernel thread, or not ours to read
+		}
+		argv := strings.Split(strings.TrimRight(string(cmdline), "\x00"), "\x00")
+		if matchAgent(argv) == "" {
+			continue // Only agents' directories are worth a syscall
+		}
+		cwd, err := os.Readlink(filepath.Join("/proc", entry.Name(), "cwd"))
+		if err != nil {
+			continue
+		}
+		processes = append(processes, process{argv: argv, cwd: cwd})
+	}
+	return processes
+}
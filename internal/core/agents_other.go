@@ -0,0 +1,42 @@
+//go:build !linux
+
+package core
+
+import (
+	"os/exec"
+	"strings"
+)
+
+// listProcesses lists processes with ps and looks up the working directory
+// of the agents among them with lsof. Where neither exists, as on Windows,
+// nothing is detected.
+func listProcesses() []process {
+	output, err := exec.Command("ps", "-axo", "pid=,args=").Output()
+	if err != nil {
+		return nil
+	}
+
+	var processes []process
+	for _, line := range strings.Split(string(output), "\n") {
+		pid, args, ok := strings.Cut(strings.TrimSpace(line), " ")
+		if !ok {
+			continue
+		}
+		// ps joins arguments with spaces; paths with spaces can't be told
+		// apart, which costs at most a missed agent
+		argv := strings.Fields(args)
+		if matchAgent(argv) == "" {
+			continue
+		}
+		cwd, err := exec.Command("lsof", "-a", "-p", pid, "-d", "cwd", "-Fn").Output()
+		if err != nil {
+			continue
+		}
+		for _, field := range strings.Split(string(cwd), "\n") {
+			if dir, ok := strings.CutPrefix(field, "n"); ok {
+				processes = append(processes, process{argv: argv, cwd: dir})
+			}
+		}
+	}
+	return processes
+}
@@ -0,0 +1,87 @@
+package core
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/config"
+)
+
+func TestMatchAgent(t *testing.T) {
+	tests := []struct {
+		argv     []string
+		expected string
+	}{
+		{[]string{"claude"}, "claude"},
+		{[]string{"/usr/local/bin/claude", "--resume"}, "claude"},
+		{[]string{"node", "/usr/lib/node_modules/@anthropic-ai/claude-code/cli.js"}, "claude"},
+		{[]string{"node", "--no-warnings", "/home/me/.npm/bin/codex"}, "codex"},
+		{[]string{"/usr/bin/python3.11", "/home/me/.local/bin/aider", "--model", "sonnet"}, "aider"},
+		{[]string{"python3", "-m", "aider"}, "aider"},
+		{[]string{"Cursor-Agent.exe"}, "cursor"},
+		{[]string{"copilot"}, "copilot"},
+		{[]string{"node", "server.js", "claude"}, ""},
+		{[]string{"grep", "claude"}, ""},
+		{[]string{"vim", "aider.md"}, ""},
+		{nil, ""},
+	}
+
+	for _, tt := range tests {
+		if got := matchAgent(tt.argv); got != tt.expected {
+			t.Errorf("matchAgent(%q) = %q, want %q", tt.argv, got, tt.expected)
+		}
+	}
+}
+
+func TestWithinProject(t *testing.T) {
+	if !withinProject("/work/app", "/work/app") || !withinProject("/work/app", "/work/app/src") {
+		t.Error("Expected the project and its subdirectories to match")
+	}
+	if withinProject("/work/app", "/work") || withinProject("/work/app", "/work/app2") {
+		t.Error("Expected directories outside the project not to match")
+	}
+}
+
+func TestAgentTrailers(t *testing.T) {
+	tempDir, state, gitManager := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	// Pretend a scan just found aider and claude
+	agentCache.Lock()
+	agentCache.root, agentCache.at, agentCache.agents = state.ProjectRoot, time.Now().Add(time.Hour), []string{"aider", "claude"}
+	agentCache.Unlock()
+	defer func() {
+		agentCache.Lock()
+		agentCache.root, agentCache.agents = "", nil
+		agentCache.Unlock()
+	}()
+
+	snapshotFile(t, tempDir, gitManager, "untagged")
+	state.Config = &config.Config{Detect: config.DetectConfig{Agents: true}}
+	snapshotFile(t, tempDir, gitManager, "tagged")
+
+	message, _ := gitManager.RunCommand("log", "-1", "--format=%B")
+	if !strings.Contains(message, agentTrailer+"aider, claude") {
+		t.Errorf("Expected the agent trailer, got %q", message)
+	}
+	provenance, err := gitManager.SnapshotProvenance("HEAD")
+	if err != nil || provenance.Agents != "aider, claude" {
+		t.Errorf("Expected the agents in the provenance, got %+v, %v", provenance, err)
+	}
+
+	for _, backend := range []string{BackendExec, BackendNative} {
+		state.Config = &config.Config{Git: config.GitConfig{Backend: backend}}
+		manager := NewGitManager(state)
+		for agent, expected := range map[string]int{"claude": 1, "Aider": 1, "cursor": 0, "aid": 0} {
+			snapshots, err := manager.FilterSnapshots(SnapshotFilter{Agent: agent})
+			if err != nil {
+				t.Fatalf("%s: FilterSnapshots failed: %v", backend, err)
+			}
+			if len(snapshots) != expected {
+				t.Errorf("%s: expected %d snapshot(s) with %s, got %+v", backend, expected, agent, snapshots)
+			}
+		}
+	}
+}
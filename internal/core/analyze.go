@@ -0,0 +1,294 @@
+package core
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Kinds of analysis findings
+const (
+	FindingIgnore    = "ignore"    // Generated files worth ignoring
+	FindingChurn     = "churn"     // A file changed in nearly every snapshot
+	FindingDuplicate = "duplicate" // The same large content at several paths
+)
+
+const (
+	analyzeLargeBlob   = 1024 * 1024 // Smallest content reported as a duplicate
+	analyzeMinSavings  = 1024 * 1024 // Smallest saving worth an ignore suggestion
+	churnRatio         = 0.8         // Share of snapshots a churny file changes in
+	churnMinSnapshots  = 10          // Fewer snapshots say nothing about churn
+	analyzeChurnSample = 3           // Largest churny files reported
+)
+
+// generatedDirs are directory names that usually hold build output,
+// dependencies or caches rather than source
+var generatedDirs = map[string]bool{
+	"node_modules": true, "dist": true, "build": true, "out": true, "target": true,
+	"coverage": true, ".cache": true, ".next": true, ".nuxt": true, "__pycache__": true,
+	".pytest_cache": true, ".gradle": true, ".tox": true, ".venv": true, "venv": true, "tmp": true,
+}
+
+// generatedExts are file extensions of logs, compiled objects and archives
+var generatedExts = map[string]bool{
+	".log": true, ".tmp": true, ".pyc": true, ".class": true, ".o": true, ".obj": true,
+	".so": true, ".dll": true, ".exe": true, ".zip": true, ".tar": true, ".gz": true, ".jar": true,
+}
+
+// AnalysisFinding is one thing the analysis suggests acting on
+type AnalysisFinding struct {
+	Kind      string   `json:"kind"`
+	Pattern   string   `json:"pattern,omitempty"`       // Ignore pattern that would help
+	Paths     []string `json:"paths"`                   // Files concerned, largest first
+	Versions  int      `json:"versions"`                // File versions stored for them
+	DiskBytes int64    `json:"disk_bytes"`              // Their storage on disk
+	Savings   int64    `json:"savings_bytes"`           // Roughly what acting on it saves over as much history again
+	Changed   float64  `json:"changed_ratio,omitempty"` // Share of snapshots changing the file (churn)
+}
+
+// Analysis is the result of scanning the shadow repository for wasted space
+type Analysis struct {
+	Snapshots int               `json:"snapshots"`
+	Blobs     int               `json:"blobs"`
+	DiskBytes int64             `json:"disk_bytes"`
+	Findings  []AnalysisFinding `json:"findings"` // Largest savings first
+}
+
+// analyzedFile is what the history says about one path
+type analyzedFile struct {
+	path      string
+	changes   int   // Snapshots that changed it
+	versions  int   // File versions first stored under it
+	diskBytes int64 // Their size on disk
+}
+
+// Analyze scans the snapshots on every shadow branch for generated files
+// worth ignoring, files changed in nearly every snapshot and large
+// contents snapshotted at several paths. Paths the ignore rules exclude
+// already are left out of the suggestions.
+func (g *GitManager) Analyze(ignored func(path string) bool) (*Analysis, error) {
+	// Records start with \x01; -z keeps unusual paths unquoted
+	output, err := g.runCommandRaw("log", "--branches", "--raw", "-z", "--no-abbrev",
+		"--no-renames", "-r", "--root", "--format=%x01%H")
+	if err != nil {
+		if strings.Contains(err.Error(), "does not have any commits yet") {
+			return &Analysis{}, nil
+		}
+		return nil, fmt.Errorf("failed to read snapshot history: %w", err)
+	}
+
+	analysis := &Analysis{}
+	files := make(map[string]*analyzedFile)
+	firstPath := make(map[string]string)          // Blob to the path it is charged to
+	blobPaths := make(map[string]map[string]bool) // Blob to every path it appeared at
+	var order []string
+	tokens := strings.Split(string(output), "\x00")
+	for i := 0; i < len(tokens); i++ {
+		token := strings.TrimLeft(tokens[i], "\n")
+		switch {
+		case strings.HasPrefix(token, "\x01"):
+			analysis.Snapshots++
+		case strings.HasPrefix(token, ":") && i+1 < len(tokens):
+			// :<old mode> <new mode> <old object> <new object> <status>, then the path
+			fields := strings.Fields(token)
+			i++
+			if len(fields) != 5 {
+				continue
+			}
+			file := files[tokens[i]]
+			if file == nil {
+				file = &analyzedFile{path: tokens[i]}
+				files[tokens[i]] = file
+			}
+			file.changes++
+			if fields[4] == "D" || !strings.HasPrefix(fields[1], "10") {
+				continue // Deletions, symlinks and submodules store no file content
+			}
+			blob := fields[3]
+			if blobPaths[blob] == nil {
+				blobPaths[blob] = make(map[string]bool)
+				firstPath[blob] = tokens[i]
+				order = append(order, blob)
+			}
+			blobPaths[blob][tokens[i]] = true
+		}
+	}
+
+	sizes, err := g.blobSizes(order)
+	if err != nil {
+		return nil, err
+	}
+	for _, blob := range order {
+		size, ok := sizes[blob]
+		if !ok {
+			continue // Missing objects are reported by verify, not here
+		}
+		analysis.Blobs++
+		analysis.DiskBytes += size.disk
+		file := files[firstPath[blob]]
+		file.versions++
+		file.diskBytes += size.disk
+
+		if size.bytes >= analyzeLargeBlob && len(blobPaths[blob]) > 1 {
+			finding := AnalysisFinding{Kind: FindingDuplicate, Versions: 1, DiskBytes: size.disk}
+			for path := range blobPaths[blob] {
+				finding.Paths = append(finding.Paths, path)
+			}
+			sort.Strings(finding.Paths)
+			analysis.Findings = append(analysis.Findings, finding)
+		}
+	}
+
+	analysis.Findings = append(analysis.Findings, ignoreFindings(files, ignored)...)
+	analysis.Findings = append(analysis.Findings, churnFindings(files, analysis.Snapshots, ignored)...)
+	sort.SliceStable(analysis.Findings, func(i, j int) bool {
+		a, b := analysis.Findings[i], analysis.Findings[j]
+		if a.Savings != b.Savings {
+			return a.Savings > b.Savings
+		}
+		return a.DiskBytes > b.DiskBytes
+	})
+	return analysis, nil
+}
+
+// blobSize is the uncompressed and on-disk size of a blob
+type blobSize struct {
+	bytes int64
+	disk  int64
+}
+
+// blobSizes asks git for the sizes of blobs in one cat-file batch
+func (g *GitManager) blobSizes(blobs []string) (map[string]blobSize, error) {
+	sizes := make(map[string]blobSize, len(blobs))
+	if len(blobs) == 0 {
+		return sizes, nil
+	}
+	output, err := g.runCommandInput(strings.Join(blobs, "\n")+"\n",
+		"cat-file", "--batch-check=%(objectname) %(objectsize) %(objectsize:disk)")
+	if err != nil {
+		return nil, fmt.Errorf("failed to measure snapshot objects: %w", err)
+	}
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			continue
+		}
+		size, err1 := strconv.ParseInt(fields[1], 10, 64)
+		disk, err2 := strconv.ParseInt(fields[2], 10, 64)
+		if err1 != nil || err2 != nil {
+			return nil, fmt.Errorf("unexpected object size %q", line)
+		}
+		sizes[fields[0]] = blobSize{bytes: size, disk: disk}
+	}
+	return sizes, nil
+}
+
+// generatedPattern returns the ignore pattern covering a path that looks
+// generated (e.g. "dist/" or "*.log"), or "" if it doesn't
+func generatedPattern(file string) string {
+	dirs := strings.Split(file, "/")
+	for _, dir := range dirs[:len(dirs)-1] {
+		if generatedDirs[dir] {
+			return dir + "/"
+		}
+	}
+	if ext := strings.ToLower(path.Ext(file)); generatedExts[ext] {
+		return "*" + ext
+	}
+	return ""
+}
+
+// ignoreFindings groups the storage of generated-looking files by the
+// ignore pattern that would exclude them
+func ignoreFindings(files map[string]*analyzedFile, ignored func(string) bool) []AnalysisFinding {
+	byPattern := make(map[string]*AnalysisFinding)
+	var patterns []string
+	for _, file := range files {
+		pattern := generatedPattern(file.path)
+		if pattern == "" || file.versions == 0 || ignored(file.path) {
+			continue
+		}
+		finding := byPattern[pattern]
+		if finding == nil {
+			finding = &AnalysisFinding{Kind: FindingIgnore, Pattern: pattern}
+			byPattern[pattern] = finding
+			patterns = append(patterns, pattern)
+		}
+		finding.Paths = append(finding.Paths, file.path)
+		finding.Versions += file.versions
+		finding.DiskBytes += file.diskBytes
+	}
+
+	var findings []AnalysisFinding
+	for _, pattern := range patterns {
+		finding := byPattern[pattern]
+		if finding.DiskBytes < analyzeMinSavings {
+			continue
+		}
+		finding.Savings = finding.DiskBytes
+		sortBySize(finding.Paths, files)
+		findings = append(findings, *finding)
+	}
+	return findings
+}
+
+// churnFindings reports the largest files changed in more than churnRatio
+// of the snapshots, unless an ignore finding covers them already
+func churnFindings(files map[string]*analyzedFile, snapshots int, ignored func(string) bool) []AnalysisFinding {
+	if snapshots < churnMinSnapshots {
+		return nil
+	}
+	var churny []*analyzedFile
+	for _, file := range files {
+		if float64(file.changes) > churnRatio*float64(snapshots) && file.versions > 0 &&
+			generatedPattern(file.path) == "" && !ignored(file.path) {
+			churny = append(churny, file)
+		}
+	}
+	sort.Slice(churny, func(i, j int) bool {
+		if churny[i].diskBytes != churny[j].diskBytes {
+			return churny[i].diskBytes > churny[j].diskBytes
+		}
+		return churny[i].path < churny[j].path
+	})
+	if len(churny) > analyzeChurnSample {
+		churny = churny[:analyzeChurnSample]
+	}
+
+	findings := make([]AnalysisFinding, len(churny))
+	for i, file := range churny {
+		findings[i] = AnalysisFinding{
+			Kind:      FindingChurn,
+			Pattern:   "/" + file.path,
+			Paths:     []string{file.path},
+			Versions:  file.versions,
+			DiskBytes: file.diskBytes,
+			Savings:   file.diskBytes,
+			Changed:   float64(file.changes) / float64(snapshots),
+		}
+	}
+	return findings
+}
+
+// sortBySize orders paths by their storage, largest first
+func sortBySize(paths []string, files map[string]*analyzedFile) {
+	sort.Slice(paths, func(i, j int) bool {
+		a, b := files[paths[i]], files[paths[j]]
+		if a.diskBytes != b.diskBytes {
+			return a.diskBytes > b.diskBytes
+		}
+		return a.path < b.path
+	})
+}
+
+// IgnoredByProject returns a check of slash-separated project paths against
+// the project's current ignore rules, for Analyze
+func (g *GitManager) IgnoredByProject() func(string) bool {
+	manager, _ := NewProjectIgnoreManager(g.State) // Rules that failed to load just don't count
+	return func(path string) bool {
+		return manager.ShouldIgnoreFile(filepath.Join(g.State.ProjectRoot, filepath.FromSlash(path)))
+	}
+}
@@ -0,0 +1,90 @@
+package core
+
+import (
+	"crypto/rand"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAnalyze(t *testing.T) {
+	tempDir, _, gitManager := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	// Random content doesn't compress, so its size on disk is predictable
+	large := make([]byte, 2*analyzeLargeBlob)
+	if _, err := rand.Read(large); err != nil {
+		t.Fatalf("Failed to generate content: %v", err)
+	}
+	for _, name := range []string{"web/dist/bundle.js", "assets/logo.bin", "assets/copy/logo.bin"} {
+		path := filepath.Join(tempDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		content := large
+		if strings.Contains(name, "dist") {
+			content = append([]byte("bundle"), large...)
+		}
+		if err := os.WriteFile(path, content, 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	for i := 0; i < churnMinSnapshots; i++ {
+		if err := os.WriteFile(filepath.Join(tempDir, "app.log"), []byte(fmt.Sprintf("line %d\n", i)), 0644); err != nil {
+			t.Fatalf("Failed to write app.log: %v", err)
+		}
+		snapshotFile(t, tempDir, gitManager, fmt.Sprintf("version %d", i))
+	}
+
+	analysis, err := gitManager.Analyze(func(string) bool { return false })
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	if analysis.Snapshots != churnMinSnapshots {
+		t.Errorf("Expected %d snapshots, got %d", churnMinSnapshots, analysis.Snapshots)
+	}
+
+	findings := make(map[string]AnalysisFinding)
+	for _, finding := range analysis.Findings {
+		findings[finding.Kind+" "+finding.Pattern] = finding
+	}
+	dist, ok := findings[FindingIgnore+" dist/"]
+	if !ok {
+		t.Fatalf("Expected a dist/ suggestion, got %+v", analysis.Findings)
+	}
+	if dist.Savings < int64(len(large)) || len(dist.Paths) != 1 || dist.Paths[0] != "web/dist/bundle.js" {
+		t.Errorf("Expected dist/ to save at least %d bytes of web/dist/bundle.js, got %+v", len(large), dist)
+	}
+	if analysis.Findings[0].Pattern != "dist/" {
+		t.Errorf("Expected the largest saving first, got %+v", analysis.Findings[0])
+	}
+
+	churn, ok := findings[FindingChurn+" /file.txt"]
+	if !ok || churn.Changed != 1 || churn.Versions != churnMinSnapshots {
+		t.Errorf("Expected file.txt to churn in every snapshot, got %+v", analysis.Findings)
+	}
+	if _, ok := findings[FindingChurn+" /app.log"]; ok {
+		t.Error("Expected app.log to be covered by *.log rather than reported as churn")
+	}
+	if _, ok := findings[FindingIgnore+" *.log"]; ok {
+		t.Error("Expected no *.log suggestion for a few bytes of logs")
+	}
+
+	duplicate, ok := findings[FindingDuplicate+" "]
+	if !ok || strings.Join(duplicate.Paths, ",") != "assets/copy/logo.bin,assets/logo.bin" {
+		t.Errorf("Expected logo.bin to be reported as duplicated, got %+v", analysis.Findings)
+	}
+
+	// Paths ignored already get no suggestions
+	analysis, err = gitManager.Analyze(func(path string) bool { return strings.Contains(path, "dist/") })
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	for _, finding := range analysis.Findings {
+		if finding.Pattern == "dist/" {
+			t.Errorf("Expected no suggestion for ignored paths, got %+v", finding)
+		}
+	}
+}
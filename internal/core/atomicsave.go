@@ -0,0 +1,177 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// atomicSaveWindow is how long an editor's temporary file may exist before
+// it is taken for a file of its own: atomic saves rename or remove it within
+// milliseconds
+const atomicSaveWindow = 5 * time.Second
+
+// atomicSave is an editor's temporary file that was created and not yet
+// renamed or removed
+type atomicSave struct {
+	target  string // The file being saved, "" for temporary files of no file
+	created time.Time
+}
+
+// editorTempTarget reports whether name is a temporary file editors write
+// while saving, and the name of the file it saves:
+//
+//   - vim: "4913" (write test, no file), "name~" (backup)
+//   - VS Code and Node-based editors: "name.tmp", "name.<random>.tmp"
+//   - JetBrains safe write: "name___jb_tmp___", "name___jb_old___"
+//   - gedit: ".goutputstream-XXXXXX" (no file)
+func editorTempTarget(name string) (string, bool) {
+	switch {
+	case name == "4913", strings.HasPrefix(name, ".goutputstream-"):
+		return "", true
+	case strings.HasSuffix(name, "___jb_tmp___"):
+		return targetName(strings.TrimSuffix(name, "___jb_tmp___"))
+	case strings.HasSuffix(name, "___jb_old___"):
+		return targetName(strings.TrimSuffix(name, "___jb_old___"))
+	case strings.HasSuffix(name, "~"):
+		return targetName(strings.TrimSuffix(name, "~"))
+	case strings.HasSuffix(name, ".tmp"):
+		target := strings.TrimSuffix(name, ".tmp")
+		if ext := filepath.Ext(target); isRandomToken(ext) {
+			target = strings.TrimSuffix(target, ext)
+		}
+		return targetName(target)
+	}
+	return "", false
+}
+
+// targetName accepts what's left of a temporary file's name as the name of
+// the file saved
+func targetName(name string) (string, bool) {
+	return name, name != "" && name != "." && name != ".."
+}
+
+// isEditorScratch reports whether name is a file editors keep next to an
+// open file, such as vim's swap files, never worth a snapshot
+func isEditorScratch(name string) bool {
+	if !strings.HasPrefix(name, ".") {
+		return false
+	}
+	switch filepath.Ext(name) {
+	case ".swp", ".swo", ".swx":
+		return true
+	}
+	return strings.HasPrefix(name, ".#") // Emacs lock files
+}
+
+// isRandomToken reports whether ext looks like the random part of a
+// temporary file's name: ".3f9a1c", not ".config"
+func isRandomToken(ext string) bool {
+	if len(ext) < 7 {
+		return false
+	}
+	digits := false
+	for _, r := range ext[1:] {
+		switch {
+		case '0' <= r && r <= '9':
+			digits = true
+		case 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z':
+		default:
+			return false
+		}
+	}
+	return digits
+}
+
+// coalesceAtomicSave folds the events of an editor's atomic save (write a
+// temporary file, rename it over the file) into one change of the file
+// saved. It returns the event to process instead, and false when the event
+// is part of a save still in flight and must be dropped. Only called by the
+// event loop.
+func (w *Watcher) coalesceAtomicSave(event fsnotify.Event) (fsnotify.Event, bool) {
+	name := filepath.Base(event.Name)
+	if isEditorScratch(name) {
+		return event, false
+	}
+	target, ok := editorTempTarget(name)
+	if !ok {
+		return event, true
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_, pending := w.atomicSaves[event.Name]
+	switch {
+	case event.Op&fsnotify.Create == fsnotify.Create:
+		w.atomicSaves[event.Name] = atomicSave{target: target, created: time.Now()}
+		if w.atomicTimer == nil {
+			w.scheduleAtomicExpiry(atomicSaveWindow)
+		}
+		return event, false
+	case !pending:
+		// Not created just now: a file of its own that looks temporary
+		return event, true
+	case event.Op&(fsnotify.Remove|fsnotify.Rename) != 0:
+		delete(w.atomicSaves, event.Name)
+		if target == "" {
+			return event, false
+		}
+		return fsnotify.Event{Name: filepath.Join(filepath.Dir(event.Name), target), Op: fsnotify.Write}, true
+	}
+	return event, false // Written while in flight
+}
+
+// scheduleAtomicExpiry expires saves in flight after delay, under mu
+func (w *Watcher) scheduleAtomicExpiry(delay time.Duration) {
+	w.atomicTimer = time.AfterFunc(delay, func() {
+		select {
+		case w.atomicDue <- struct{}{}:
+		default: // Expiry is already due
+		}
+	})
+}
+
+// atomicSaveInFlight reports whether an editor is in the middle of a save:
+// the file saved may be missing or the temporary file present, so a
+// snapshot waits
+func (w *Watcher) atomicSaveInFlight() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, save := range w.atomicSaves {
+		if time.Since(save.created) < atomicSaveWindow {
+			return true
+		}
+	}
+	return false
+}
+
+// expireAtomicSaves gives up on temporary files that outlived
+// atomicSaveWindow: those still there are changes of their own. Only called
+// by the event loop.
+func (w *Watcher) expireAtomicSaves() {
+	w.mu.Lock()
+	var expired []string
+	next := time.Duration(0)
+	for path, save := range w.atomicSaves {
+		if age := time.Since(save.created); age >= atomicSaveWindow {
+			expired = append(expired, path)
+			delete(w.atomicSaves, path)
+		} else if left := atomicSaveWindow - age; next == 0 || left < next {
+			next = left
+		}
+	}
+	w.atomicTimer = nil
+	if next > 0 {
+		w.scheduleAtomicExpiry(next)
+	}
+	w.mu.Unlock()
+
+	for _, path := range expired {
+		if _, err := os.Lstat(path); err == nil {
+			w.handleEvent(fsnotify.Event{Name: path, Op: fsnotify.Write})
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+func TestEditorTempTarget(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		temp   bool
+	}{
+		{"main.go~", "main.go", true},
+		{"4913", "", true},
+		{"main.go___jb_tmp___", "main.go", true},
+		{"main.go___jb_old___", "main.go", true},
+		{"main.go.tmp", "main.go", true},
+		{"main.go.8f3a91c2.tmp", "main.go", true},
+		{"app.config.tmp", "app.config", true},
+		{".goutputstream-X1Y2Z3", "", true},
+		{"~", "", false},
+		{"main.go", "", false},
+		{"4914", "", false},
+	}
+	for _, tt := range tests {
+		target, temp := editorTempTarget(tt.name)
+		if temp != tt.temp || (temp && target != tt.target) {
+			t.Errorf("editorTempTarget(%q) = %q, %v, want %q, %v", tt.name, target, temp, tt.target, tt.temp)
+		}
+	}
+
+	for name, expected := range map[string]bool{".main.go.swp": true, ".main.go.swx": true, ".#main.go": true, "main.swp": false, "main.go": false} {
+		if isEditorScratch(name) != expected {
+			t.Errorf("isEditorScratch(%q) = %v", name, !expected)
+		}
+	}
+}
+
+func TestAtomicSaves(t *testing.T) {
+	tempDir, state, gitManager := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	watcher, err := NewWatcher(state, gitManager)
+	if err != nil {
+		t.Fatalf("NewWatcher failed: %v", err)
+	}
+	defer watcher.fsWatcher.Close()
+	path := func(name string) string { return filepath.Join(tempDir, name) }
+
+	// The events each editor's save produces on Linux, renames reported
+	// as a Rename of the old name and a Create of the new one
+	saves := map[string][]fsnotify.Event{
+		"vim": {
+			{Name: path("4913"), Op: fsnotify.Create},
+			{Name: path("4913"), Op: fsnotify.Remove},
+			{Name: path("main.go"), Op: fsnotify.Rename},
+			{Name: path("main.go~"), Op: fsnotify.Create},
+			{Name: path("main.go"), Op: fsnotify.Create},
+			{Name: path("main.go"), Op: fsnotify.Write},
+			{Name: path(".main.go.swp"), Op: fsnotify.Write},
+			{Name: path("main.go~"), Op: fsnotify.Remove},
+		},
+		"vscode": {
+			{Name: path("main.go.8f3a91c2.tmp"), Op: fsnotify.Create},
+			{Name: path("main.go.8f3a91c2.tmp"), Op: fsnotify.Write},
+			{Name: path("main.go.8f3a91c2.tmp"), Op: fsnotify.Rename},
+			{Name: path("main.go"), Op: fsnotify.Create},
+		},
+		"jetbrains": {
+			{Name: path("main.go___jb_tmp___"), Op: fsnotify.Create},
+			{Name: path("main.go___jb_tmp___"), Op: fsnotify.Write},
+			{Name: path("main.go"), Op: fsnotify.Rename},
+			{Name: path("main.go___jb_old___"), Op: fsnotify.Create},
+			{Name: path("main.go___jb_tmp___"), Op: fsnotify.Rename},
+			{Name: path("main.go"), Op: fsnotify.Create},
+			{Name: path("main.go___jb_old___"), Op: fsnotify.Remove},
+		},
+	}
+
+	for editor, events := range saves {
+		for i, event := range events {
+			watcher.handleEvent(event)
+			// Snapshots wait while the temporary file exists
+			if i == 0 && !watcher.atomicSaveInFlight() {
+				t.Errorf("%s: expected the save in flight after %v", editor, event)
+			}
+		}
+		watcher.debouncer.Cancel()
+		if watcher.atomicSaveInFlight() {
+			t.Errorf("%s: expected the save done", editor)
+		}
+
+		paths, _ := watcher.takeChangedPaths()
+		if !reflect.DeepEqual(paths, []string{"main.go"}) {
+			t.Errorf("%s: expected one change of main.go, got %v", editor, paths)
+		}
+	}
+
+	// A temporary-looking file that stays is a file of its own
+	notes := path("notes.tmp")
+	os.WriteFile(notes, []byte("keep"), 0644)
+	watcher.handleEvent(fsnotify.Event{Name: notes, Op: fsnotify.Create})
+	if paths, _ := watcher.takeChangedPaths(); len(paths) != 0 {
+		t.Fatalf("Expected the new file to wait, got %v", paths)
+	}
+	watcher.mu.Lock()
+	watcher.atomicSaves[notes] = atomicSave{target: "notes", created: time.Now().Add(-atomicSaveWindow)}
+	watcher.mu.Unlock()
+	watcher.expireAtomicSaves()
+	watcher.debouncer.Cancel()
+	if paths, _ := watcher.takeChangedPaths(); !reflect.DeepEqual(paths, []string{"notes.tmp"}) {
+		t.Errorf("Expected notes.tmp once it stayed, got %v", paths)
+	}
+	watcher.mu.Lock()
+	if watcher.atomicTimer != nil {
+		watcher.atomicTimer.Stop()
+	}
+	watcher.mu.Unlock()
+}
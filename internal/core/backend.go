@@ -0,0 +1,86 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Backend names accepted by the git.backend setting
+const (
+	BackendExec   = "exec"   // Shell out to the git binary
+	BackendNative = "native" // Run in-process with go-git
+)
+
+// GitBackend performs the hot-path snapshot operations on the shadow
+// repository. Everything else still goes through RunCommand.
+type GitBackend interface {
+	Name() string
+	CreateSnapshot(message string) error
+	WalkSnapshots(filter SnapshotFilter, fn func(Snapshot) error) error
+	RestoreSnapshot(hash string, files []string) error
+}
+
+// execBackend implements GitBackend with the git binary
+type execBackend struct {
+	*GitManager
+}
+
+// Name returns the backend name
+func (execBackend) Name() string {
+	return BackendExec
+}
+
+// newBackend returns the backend with the given name, falling back to exec
+// for unknown names
+func newBackend(g *GitManager, name string) GitBackend {
+	if name == BackendNative {
+		return &nativeBackend{git: g}
+	}
+	return execBackend{g}
+}
+
+// topPathspecPrefix marks a path as relative to the project root
+const topPathspecPrefix = ":(top,literal)"
+
+// rootRelativePath converts a path given on the command line (relative to
+// the current directory, absolute, or a :(top,literal) pathspec) into a
+// slash-separated path relative to the project root. The root itself is "".
+func (g *GitManager) rootRelativePath(path string) (string, error) {
+	if strings.HasPrefix(path, topPathspecPrefix) {
+		rel := filepath.ToSlash(filepath.Clean(strings.TrimPrefix(path, topPathspecPrefix)))
+		if rel == "." {
+			return "", nil
+		}
+		return rel, nil
+	}
+
+	// Windows paths pasted into WSL name the mounted drive
+	abs := g.State.Environment().LocalPath(path)
+	if !filepath.IsAbs(abs) {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return "", err
+		}
+		abs = filepath.Join(cwd, path)
+	}
+
+	root := g.State.ProjectRoot
+	if resolved, err := filepath.EvalSymlinks(root); err == nil {
+		root = resolved
+	}
+	if dir, err := filepath.EvalSymlinks(filepath.Dir(abs)); err == nil {
+		abs = filepath.Join(dir, filepath.Base(abs))
+	}
+
+	rel, err := filepath.Rel(root, abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("path %s is outside the repository", path)
+	}
+	if rel == "." {
+		return "", nil
+	}
+
+	return filepath.ToSlash(rel), nil
+}
@@ -0,0 +1,450 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-git/go-billy/v5/osfs"
+	"github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/cache"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+)
+
+// nativeBackend implements GitBackend in-process with go-git, so snapshot
+// operations work without a git binary and without process spawn overhead
+type nativeBackend struct {
+	git *GitManager
+
+	mu   sync.Mutex
+	repo *git.Repository
+}
+
+// Name returns the backend name
+func (b *nativeBackend) Name() string {
+	return BackendNative
+}
+
+// open lazily opens the shadow repository with the project as work tree
+func (b *nativeBackend) open() (*git.Repository, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.repo != nil {
+		return b.repo, nil
+	}
+
+	storage := filesystem.NewStorage(osfs.New(b.git.State.ShadowRepoDir), cache.NewObjectLRUDefault())
+	repo, err := git.Open(storage, osfs.New(b.git.State.ProjectRoot))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open shadow repository: %w", err)
+	}
+
+	b.repo = repo
+	return repo, nil
+}
+
+// CreateSnapshot stages everything, or the include paths, and commits if
+// anything changed
+func (b *nativeBackend) CreateSnapshot(message string) error {
+	if b.git.signingKey() != "" {
+		// go-git only signs with OpenPGP keys loaded in-process; git signs
+		// with either kind through the user's agent
+		return execBackend{b.git}.CreateSnapshot(message)
+	}
+	if b.git.State.Config != nil && len(b.git.State.Config.Watcher.ChurnFilters) > 0 {
+		// Churn is compared and unstaged with git
+		return execBackend{b.git}.CreateSnapshot(message)
+	}
+
+	repo, err := b.open()
+	if err != nil {
+		return err
+	}
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return fmt.Errorf("failed to open work tree: %w", err)
+	}
+
+	// Stage everything including untracked files and deletions
+	includes := b.git.State.IncludePaths
+	if len(includes) == 0 {
+		if err := worktree.AddWithOptions(&git.AddOptions{All: true}); err != nil {
+			return fmt.Errorf("failed to stage files: %w", err)
+		}
+	}
+	for _, include := range includes {
+		if err := worktree.AddWithOptions(&git.AddOptions{Path: include}); err != nil {
+			return fmt.Errorf("failed to stage %s: %w", include, err)
+		}
+	}
+
+	status, err := worktree.Status()
+	if err != nil {
+		return fmt.Errorf("failed to check status: %w", err)
+	}
+
+	// If no changes, don't create empty commits; outside the include paths
+	// nothing is staged
+	var files []string
+	for path, fileStatus := range status {
+		if fileStatus.Staging != git.Unmodified && fileStatus.Staging != git.Untracked {
+			files = append(files, filepath.ToSlash(path))
+		}
+	}
+	if len(files) == 0 {
+		return nil
+	}
+	if err := b.git.checkStagedSecrets(); err != nil {
+		return err
+	}
+
+	if message == "" {
+		sort.Strings(files)
+		message = b.git.snapshotMessage(files)
+	}
+
+	commits := b.git.pendingProjectCommits()
+	signature := b.signature(repo)
+	if _, err := worktree.Commit(b.git.snapshotMessageWithTrailers(message, commits), &git.CommitOptions{Author: signature, Committer: signature}); err != nil {
+		return fmt.Errorf("failed to create snapshot: %w", err)
+	}
+	b.git.clearProjectCommits(commits)
+
+	return nil
+}
+
+// signature uses the identity copied into the shadow repo at init time,
+// falling back to the global git identity
+func (b *nativeBackend) signature(repo *git.Repository) *object.Signature {
+	signature := &object.Signature{Name: "Time Machine", Email: "timemachine@localhost", When: time.Now()}
+
+	for _, scope := range []gitconfig.Scope{gitconfig.LocalScope, gitconfig.GlobalScope} {
+		cfg, err := repo.ConfigScoped(scope)
+		if err != nil || cfg.User.Name == "" {
+			continue
+		}
+		signature.Name = cfg.User.Name
+		if cfg.User.Email != "" {
+			signature.Email = cfg.User.Email
+		}
+		break
+	}
+
+	return signature
+}
+
+// WalkSnapshots walks history from HEAD (or the filter's branch), newest
+// first. go-git walks from a single commit and has no pathspec matching, so
+// listing all branches at once and pathspecs are left to git.
+func (b *nativeBackend) WalkSnapshots(filter SnapshotFilter, fn func(Snapshot) error) error {
+	if filter.AllBranches || len(filter.Pathspecs) > 0 {
+		return execBackend{b.git}.WalkSnapshots(filter, fn)
+	}
+
+	repo, err := b.open()
+	if err != nil {
+		return err
+	}
+
+	var head *plumbing.Reference
+	if filter.Branch != "" {
+		head, err = repo.Reference(plumbing.ReferenceName(b.git.snapshotBranchRef(filter.Branch)), true)
+		if errors.Is(err, plumbing.ErrReferenceNotFound) {
+			return NewNotFoundError("no snapshots on branch %q", filter.Branch)
+		}
+	} else {
+		head, err = repo.Head()
+	}
+	if err != nil {
+		// If no commits exist yet, return empty slice (not error)
+		if errors.Is(err, plumbing.ErrReferenceNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to list snapshots: %w", err)
+	}
+
+	options := &git.LogOptions{From: head.Hash()}
+	if !filter.Since.IsZero() {
+		options.Since = &filter.Since
+	}
+	if !filter.Until.IsZero() {
+		options.Until = &filter.Until
+	}
+	var grep *regexp.Regexp
+	if filter.Grep != "" {
+		if grep, err = regexp.Compile("(?i)" + filter.Grep); err != nil {
+			return fmt.Errorf("invalid --grep pattern: %w", err)
+		}
+	}
+	if filter.File != "" {
+		path, err := b.git.rootRelativePath(filter.File)
+		if err != nil {
+			return err
+		}
+		options.PathFilter = func(name string) bool {
+			return path == "" || name == path || strings.HasPrefix(name, path+"/")
+		}
+	}
+
+	commits, err := repo.Log(options)
+	if err != nil {
+		return fmt.Errorf("failed to list snapshots: %w", err)
+	}
+	defer commits.Close()
+
+	now := time.Now()
+	matched, emitted := 0, 0
+	var fnErr error
+	err = commits.ForEach(func(commit *object.Commit) error {
+		if filter.Limit > 0 && emitted >= filter.Limit {
+			return storer.ErrStop
+		}
+		if grep != nil && !grep.MatchString(commit.Message) {
+			return nil
+		}
+		if filter.Session != "" && !hasSessionTrailer(commit.Message, filter.Session) {
+			return nil
+		}
+		if filter.Agent != "" && !hasAgentTrailer(commit.Message, filter.Agent) {
+			return nil
+		}
+		if matched++; matched <= filter.Offset {
+			return nil
+		}
+		message, _, _ := strings.Cut(commit.Message, "\n")
+		emitted++
+		snapshot := Snapshot{
+			Hash:       commit.Hash.String(),
+			ShortHash:  ShortHash(commit.Hash.String()),
+			Message:    message,
+			Time:       relativeTime(now, commit.Committer.When),
+			Timestamp:  commit.Committer.When,
+			AuthorTime: commit.Author.When,
+		}
+		if len(commit.ParentHashes) > 0 {
+			snapshot.Parent = commit.ParentHashes[0].String()
+		}
+		if filter.Stats {
+			stats, err := commit.Stats()
+			if err != nil {
+				return fmt.Errorf("failed to count the changes of %s: %w", ShortHash(snapshot.Hash), err)
+			}
+			for _, file := range stats {
+				snapshot.FilesChanged++
+				snapshot.Insertions += file.Addition
+				snapshot.Deletions += file.Deletion
+			}
+		}
+		fnErr = fn(snapshot)
+		if fnErr != nil {
+			return storer.ErrStop
+		}
+		return nil
+	})
+	if fnErr != nil {
+		return fnErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to list snapshots: %w", err)
+	}
+
+	return nil
+}
+
+// RestoreSnapshot writes files from the snapshot into the work tree without
+// touching the index. Like `git restore --worktree`, tracked files that are
+// missing from the snapshot are removed.
+func (b *nativeBackend) RestoreSnapshot(hash string, files []string) error {
+	repo, err := b.open()
+	if err != nil {
+		return err
+	}
+
+	commitHash, err := repo.ResolveRevision(plumbing.Revision(hash))
+	if err != nil {
+		return NewNotFoundError("failed to restore snapshot: snapshot %s not found: %w", hash, err)
+	}
+	commit, err := repo.CommitObject(*commitHash)
+	if err != nil {
+		return fmt.Errorf("failed to restore snapshot: %w", err)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return fmt.Errorf("failed to restore snapshot: %w", err)
+	}
+
+	// No files restores the whole tree, regardless of the current directory
+	if len(files) == 0 {
+		files = []string{topPathspecPrefix + "."}
+	}
+
+	// On case-insensitive filesystems names differing only in case are the
+	// same file, so they are matched and compared case-folded
+	fold := b.git.State.CaseInsensitive()
+
+	prefixes := make([]string, 0, len(files))
+	for _, file := range files {
+		prefix, err := b.git.rootRelativePath(file)
+		if err != nil {
+			return fmt.Errorf("failed to restore snapshot: %w", err)
+		}
+		prefixes = append(prefixes, pathKey(prefix, fold))
+	}
+
+	matched := make([]bool, len(prefixes))
+	inSnapshot := make(map[string]bool)
+
+	err = tree.Files().ForEach(func(file *object.File) error {
+		index := matchPrefix(prefixes, pathKey(file.Name, fold))
+		if index < 0 {
+			return nil
+		}
+		matched[index] = true
+		inSnapshot[pathKey(file.Name, fold)] = true
+		return b.writeFile(file)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to restore snapshot: %w", err)
+	}
+
+	// Remove tracked files that do not exist in the snapshot
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		return fmt.Errorf("failed to restore snapshot: %w", err)
+	}
+	for _, entry := range idx.Entries {
+		index := matchPrefix(prefixes, pathKey(entry.Name, fold))
+		if index < 0 {
+			continue
+		}
+		matched[index] = true
+		if inSnapshot[pathKey(entry.Name, fold)] {
+			continue
+		}
+		target := filepath.Join(b.git.State.ProjectRoot, filepath.FromSlash(entry.Name))
+		if err := os.Remove(target); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to restore snapshot: %w", err)
+		}
+	}
+
+	for i, ok := range matched {
+		if !ok {
+			return fmt.Errorf("failed to restore snapshot: pathspec '%s' did not match any file(s) known to git", files[i])
+		}
+	}
+
+	return nil
+}
+
+// writeFile writes one snapshot file into the work tree
+func (b *nativeBackend) writeFile(file *object.File) error {
+	target := filepath.Join(b.git.State.ProjectRoot, filepath.FromSlash(file.Name))
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+
+	reader, err := file.Reader()
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	if file.Mode == filemode.Symlink {
+		link, err := io.ReadAll(reader)
+		if err != nil {
+			return err
+		}
+		if err := os.Remove(target); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return os.Symlink(string(link), target)
+	}
+
+	perm := os.FileMode(0644)
+	if file.Mode == filemode.Executable {
+		perm = 0755
+	}
+
+	out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, reader); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+
+	return os.Chmod(target, perm)
+}
+
+// matchPrefix returns the index of the first prefix covering name, or -1.
+// An empty prefix covers the whole tree.
+func matchPrefix(prefixes []string, name string) int {
+	for i, prefix := range prefixes {
+		if prefix == "" || name == prefix || strings.HasPrefix(name, prefix+"/") {
+			return i
+		}
+	}
+	return -1
+}
+
+// relativeTime formats a time like git's %ar ("5 minutes ago")
+func relativeTime(now, t time.Time) string {
+	seconds := int64(now.Sub(t).Seconds())
+	if seconds < 0 {
+		return "in the future"
+	}
+
+	plural := func(n int64, unit string) string {
+		if n == 1 {
+			return fmt.Sprintf("1 %s ago", unit)
+		}
+		return fmt.Sprintf("%d %ss ago", n, unit)
+	}
+
+	switch days := (seconds + 43200) / 86400; {
+	case seconds < 90:
+		return plural(seconds, "second")
+	case seconds < 90*60:
+		return plural((seconds+30)/60, "minute")
+	case seconds < 36*3600:
+		return plural((seconds+1800)/3600, "hour")
+	case days < 14:
+		return plural(days, "day")
+	case days < 70:
+		return plural((days+3)/7, "week")
+	case days < 365:
+		return plural((days+15)/30, "month")
+	default:
+		years := days / 365
+		months := (days%365 + 15) / 30
+		if months == 0 || years >= 5 {
+			return plural(years, "year")
+		}
+		unit := "years"
+		if years == 1 {
+			unit = "year"
+		}
+		if months == 1 {
+			return fmt.Sprintf("%d %s, 1 month ago", years, unit)
+		}
+		return fmt.Sprintf("%d %s, %d months ago", years, unit, months)
+	}
+}
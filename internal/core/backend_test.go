@@ -0,0 +1,292 @@
+package core
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/config"
+)
+
+func TestNewGitManagerBackendSelection(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   *config.Config
+		expected string
+	}{
+		{"nil config", nil, BackendExec},
+		{"empty backend", &config.Config{}, BackendExec},
+		{"exec backend", &config.Config{Git: config.GitConfig{Backend: BackendExec}}, BackendExec},
+		{"native backend", &config.Config{Git: config.GitConfig{Backend: BackendNative}}, BackendNative},
+		{"unknown backend", &config.Config{Git: config.GitConfig{Backend: "bogus"}}, BackendExec},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gitManager := NewGitManager(&AppState{Config: tt.config})
+			if got := gitManager.Backend().Name(); got != tt.expected {
+				t.Errorf("Expected backend %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestNativeBackendRoundTrip(t *testing.T) {
+	tempDir, state, _ := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	state.Config = &config.Config{Git: config.GitConfig{Backend: BackendNative}}
+	gitManager := NewGitManager(state)
+
+	// No commits yet
+	snapshots, err := gitManager.ListSnapshots(0, "")
+	if err != nil {
+		t.Fatalf("ListSnapshots on empty repo failed: %v", err)
+	}
+	if len(snapshots) != 0 {
+		t.Fatalf("Expected 0 snapshots, got %d", len(snapshots))
+	}
+
+	mainFile := filepath.Join(tempDir, "main.go")
+	subFile := filepath.Join(tempDir, "sub", "util.go")
+	if err := os.MkdirAll(filepath.Dir(subFile), 0755); err != nil {
+		t.Fatalf("Failed to create sub dir: %v", err)
+	}
+	if err := os.WriteFile(mainFile, []byte("v1"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := os.WriteFile(subFile, []byte("util v1"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	if err := gitManager.CreateSnapshot("first"); err != nil {
+		t.Fatalf("CreateSnapshot failed: %v", err)
+	}
+
+	// Unchanged work tree must not produce an empty snapshot
+	if err := gitManager.CreateSnapshot("empty"); err != nil {
+		t.Fatalf("CreateSnapshot failed: %v", err)
+	}
+
+	if err := os.WriteFile(mainFile, []byte("v2"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	newFile := filepath.Join(tempDir, "new.txt")
+	if err := os.WriteFile(newFile, []byte("new"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := gitManager.CreateSnapshot("second"); err != nil {
+		t.Fatalf("CreateSnapshot failed: %v", err)
+	}
+
+	snapshots, err = gitManager.ListSnapshots(0, "")
+	if err != nil {
+		t.Fatalf("ListSnapshots failed: %v", err)
+	}
+	if len(snapshots) != 2 {
+		t.Fatalf("Expected 2 snapshots, got %d", len(snapshots))
+	}
+	if snapshots[0].Message != "second" || snapshots[1].Message != "first" {
+		t.Errorf("Expected newest first, got %q then %q", snapshots[0].Message, snapshots[1].Message)
+	}
+
+	// The exec backend must see the same history
+	var execSnapshots []Snapshot
+	err = (execBackend{gitManager}).WalkSnapshots(SnapshotFilter{}, func(snapshot Snapshot) error {
+		execSnapshots = append(execSnapshots, snapshot)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("exec ListSnapshots failed: %v", err)
+	}
+	if len(execSnapshots) != 2 || execSnapshots[0].Hash != snapshots[0].Hash {
+		t.Errorf("Expected exec backend to list the same snapshots, got %+v", execSnapshots)
+	}
+
+	// File filter and limit
+	filtered, err := gitManager.ListSnapshots(0, ":(top,literal)sub")
+	if err != nil {
+		t.Fatalf("ListSnapshots with filter failed: %v", err)
+	}
+	if len(filtered) != 1 || filtered[0].Message != "first" {
+		t.Errorf("Expected only the first snapshot to touch sub/, got %+v", filtered)
+	}
+	limited, err := gitManager.ListSnapshots(1, "")
+	if err != nil {
+		t.Fatalf("ListSnapshots with limit failed: %v", err)
+	}
+	if len(limited) != 1 {
+		t.Errorf("Expected 1 snapshot with limit, got %d", len(limited))
+	}
+
+	// Restore a single file
+	if err := gitManager.RestoreSnapshot(snapshots[1].Hash, []string{":(top,literal)main.go"}); err != nil {
+		t.Fatalf("RestoreSnapshot failed: %v", err)
+	}
+	if content, _ := os.ReadFile(mainFile); string(content) != "v1" {
+		t.Errorf("Expected main.go to be restored to v1, got %q", content)
+	}
+	if _, err := os.Stat(newFile); err != nil {
+		t.Errorf("Expected new.txt to survive a single-file restore: %v", err)
+	}
+
+	// Restore everything removes files that did not exist in the snapshot
+	if err := gitManager.RestoreSnapshot(snapshots[1].Hash, nil); err != nil {
+		t.Fatalf("RestoreSnapshot failed: %v", err)
+	}
+	if _, err := os.Stat(newFile); !os.IsNotExist(err) {
+		t.Errorf("Expected new.txt to be removed by a full restore")
+	}
+
+	// Unknown paths fail like git restore does
+	if err := gitManager.RestoreSnapshot(snapshots[1].Hash, []string{":(top,literal)missing.go"}); err == nil {
+		t.Error("Expected error restoring a path that is not in the snapshot")
+	}
+}
+
+func TestRelativeTime(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		ago      time.Duration
+		expected string
+	}{
+		{1 * time.Second, "1 second ago"},
+		{45 * time.Second, "45 seconds ago"},
+		{5 * time.Minute, "5 minutes ago"},
+		{3 * time.Hour, "3 hours ago"},
+		{2 * 24 * time.Hour, "2 days ago"},
+		{21 * 24 * time.Hour, "3 weeks ago"},
+		{-time.Minute, "in the future"},
+	}
+
+	for _, tt := range tests {
+		if got := relativeTime(now, now.Add(-tt.ago)); got != tt.expected {
+			t.Errorf("Expected %q for %v, got %q", tt.expected, tt.ago, got)
+		}
+	}
+}
+
+func TestFilterSnapshots(t *testing.T) {
+	tempDir, state, gitManager := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	day := func(d int) time.Time { return time.Date(2024, 3, d, 12, 0, 0, 0, time.Local) }
+	snapshotFileAt(t, tempDir, gitManager, "fix parser", day(1))
+	snapshotFileAt(t, tempDir, gitManager, "add login", day(2))
+	if err := os.WriteFile(filepath.Join(tempDir, "other.txt"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	snapshotFileAt(t, tempDir, gitManager, "Fix login", day(3))
+	if _, err := gitManager.RunCommand("branch", "older", "HEAD~1"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		filter   SnapshotFilter
+		expected []string
+	}{
+		{"no filter", SnapshotFilter{}, []string{"Fix login", "add login", "fix parser"}},
+		{"since", SnapshotFilter{Since: day(2)}, []string{"Fix login", "add login"}},
+		{"until", SnapshotFilter{Until: day(2)}, []string{"add login", "fix parser"}},
+		{"grep ignores case", SnapshotFilter{Grep: "^fix"}, []string{"Fix login", "fix parser"}},
+		{"branch", SnapshotFilter{Branch: "older"}, []string{"add login", "fix parser"}},
+		{"file", SnapshotFilter{File: ":(top,literal)other.txt"}, []string{"Fix login"}},
+		{"pathspecs", SnapshotFilter{Pathspecs: []string{":(top,glob)*.txt", ":(top,exclude,literal)file.txt"}}, []string{"Fix login"}},
+		{"combined", SnapshotFilter{Grep: "login", Until: day(2), Branch: "older"}, []string{"add login"}},
+		{"limit", SnapshotFilter{Grep: "login", Limit: 1}, []string{"Fix login"}},
+		{"offset", SnapshotFilter{Offset: 1, Limit: 1}, []string{"add login"}},
+		{"offset counts matches", SnapshotFilter{Grep: "login", Offset: 1}, []string{"add login"}},
+	}
+
+	for _, backend := range []string{BackendExec, BackendNative} {
+		state.Config = &config.Config{Git: config.GitConfig{Backend: backend}}
+		manager := NewGitManager(state)
+		for _, tt := range tests {
+			t.Run(backend+"/"+tt.name, func(t *testing.T) {
+				snapshots, err := manager.FilterSnapshots(tt.filter)
+				if err != nil {
+					t.Fatalf("FilterSnapshots failed: %v", err)
+				}
+				var messages []string
+				for _, snapshot := range snapshots {
+					messages = append(messages, snapshot.Message)
+				}
+				if strings.Join(messages, ",") != strings.Join(tt.expected, ",") {
+					t.Errorf("Expected %v, got %v", tt.expected, messages)
+				}
+			})
+		}
+
+		if _, err := manager.FilterSnapshots(SnapshotFilter{Branch: "missing"}); err == nil {
+			t.Errorf("%s: expected an error for a missing branch", backend)
+		}
+		if _, err := manager.FilterSnapshots(SnapshotFilter{Grep: "("}); err == nil {
+			t.Errorf("%s: expected an error for an invalid pattern", backend)
+		}
+
+		// An error from the callback stops the walk early and is returned as is
+		stop := errors.New("stop")
+		calls := 0
+		err := manager.WalkSnapshots(SnapshotFilter{}, func(Snapshot) error {
+			calls++
+			return stop
+		})
+		if err != stop || calls != 1 {
+			t.Errorf("%s: expected the walk to stop after 1 call with its error, got %d calls and %v", backend, calls, err)
+		}
+	}
+}
+
+func TestSnapshotMetadata(t *testing.T) {
+	tempDir, state, gitManager := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	os.WriteFile(filepath.Join(tempDir, "a.txt"), []byte("one\ntwo\n"), 0644)
+	if err := gitManager.CreateSnapshot("First"); err != nil {
+		t.Fatalf("CreateSnapshot failed: %v", err)
+	}
+	os.WriteFile(filepath.Join(tempDir, "a.txt"), []byte("one\nTWO\nthree\n"), 0644)
+	os.WriteFile(filepath.Join(tempDir, "b.txt"), []byte("b\n"), 0644)
+	if err := gitManager.CreateSnapshot("Second"); err != nil {
+		t.Fatalf("CreateSnapshot failed: %v", err)
+	}
+
+	for _, backend := range []string{BackendExec, BackendNative} {
+		state.Config = &config.Config{Git: config.GitConfig{Backend: backend}}
+		manager := NewGitManager(state)
+
+		snapshots, err := manager.FilterSnapshots(SnapshotFilter{Stats: true})
+		if err != nil || len(snapshots) != 2 {
+			t.Fatalf("%s: expected 2 snapshots, got %+v, %v", backend, snapshots, err)
+		}
+		second, first := snapshots[0], snapshots[1]
+		if second.Parent != first.Hash || first.Parent != "" || second.ShortHash != ShortHash(second.Hash) {
+			t.Errorf("%s: unexpected hashes %+v, %+v", backend, second, first)
+		}
+		if second.AuthorTime.IsZero() || second.AuthorTime.After(second.Timestamp) {
+			t.Errorf("%s: unexpected author time %v (committed %v)", backend, second.AuthorTime, second.Timestamp)
+		}
+		if second.FilesChanged != 2 || second.Insertions != 3 || second.Deletions != 1 {
+			t.Errorf("%s: unexpected stats of the second snapshot %+v", backend, second)
+		}
+		if first.FilesChanged != 1 || first.Insertions != 2 || first.Deletions != 0 {
+			t.Errorf("%s: unexpected stats of the first snapshot %+v", backend, first)
+		}
+
+		// Stats cover the whole snapshot, even when listing one file's
+		snapshots, err = manager.FilterSnapshots(SnapshotFilter{File: filepath.Join(tempDir, "b.txt"), Stats: true})
+		if err != nil || len(snapshots) != 1 || snapshots[0].FilesChanged != 2 {
+			t.Errorf("%s: expected the full stats of the snapshot changing b.txt, got %+v, %v", backend, snapshots, err)
+		}
+		// Without Stats, nothing is counted
+		snapshots, _ = manager.FilterSnapshots(SnapshotFilter{Limit: 1})
+		if len(snapshots) != 1 || snapshots[0].FilesChanged != 0 || snapshots[0].Parent != first.Hash {
+			t.Errorf("%s: unexpected snapshot without stats %+v", backend, snapshots)
+		}
+	}
+}
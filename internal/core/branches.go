@@ -0,0 +1,211 @@
+package core
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ShadowBranch is a branch of the shadow repository
+type ShadowBranch struct {
+	Name         string
+	Snapshots    int
+	Unique       int // Snapshots on no other branch; deleting the branch deletes them
+	LastSnapshot time.Time
+	LastTime     string // LastSnapshot as a relative time (e.g., "2 hours ago")
+	Current      bool   // New snapshots are added to it
+	InProject    bool   // The project repository has a branch of that name
+}
+
+// ShadowBranches lists the branches of the shadow repository by name
+func (g *GitManager) ShadowBranches() ([]ShadowBranch, error) {
+	output, err := g.RunCommand("for-each-ref", "--format=%(refname:short)%09%(committerdate:unix)", "refs/heads")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list shadow branches: %w", err)
+	}
+	current, _ := g.RunCommand("symbolic-ref", "--short", "HEAD")
+
+	counts, err := g.BranchSnapshotCounts()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var branches []ShadowBranch
+	for _, line := range strings.Split(output, "\n") {
+		name, date, ok := strings.Cut(line, "\t")
+		if !ok {
+			continue
+		}
+		branch := ShadowBranch{
+			Name:      name,
+			Snapshots: counts[name],
+			Current:   name == current,
+		}
+		if seconds, err := strconv.ParseInt(date, 10, 64); err == nil {
+			branch.LastSnapshot = time.Unix(seconds, 0)
+			branch.LastTime = relativeTime(now, branch.LastSnapshot)
+		}
+		// --exclude patterns are relative to refs/heads when used with --branches
+		unique, err := g.RunCommand("rev-list", "--count", "refs/heads/"+name, "--not", "--exclude="+name, "--branches")
+		if err != nil {
+			return nil, fmt.Errorf("failed to count snapshots on %s: %w", name, err)
+		}
+		branch.Unique, _ = strconv.Atoi(unique)
+		if hash, err := ResolveRef(g.State.GitDir, "refs/heads/"+name); err == nil && hash != "" {
+			branch.InProject = true
+		}
+		branches = append(branches, branch)
+	}
+	sort.Slice(branches, func(i, j int) bool { return branches[i].Name < branches[j].Name })
+
+	return branches, nil
+}
+
+// DeleteShadowBranches deletes shadow branches and garbage-collects the
+// snapshots only they contained. The current branch can't be deleted, and
+// neither, unless force is set, can one the project repository still has.
+// Nothing is deleted if any of the branches can't be.
+func (g *GitManager) DeleteShadowBranches(names []string, force bool) ([]ShadowBranch, error) {
+	branches, err := g.ShadowBranches()
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]ShadowBranch, len(branches))
+	for _, branch := range branches {
+		byName[branch.Name] = branch
+	}
+
+	var deleted []ShadowBranch
+	for _, name := range names {
+		branch, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("no shadow branch %q", name)
+		}
+		if branch.Current {
+			return nil, fmt.Errorf("shadow branch %q is the current one and receives new snapshots", name)
+		}
+		if branch.InProject && !force {
+			return nil, fmt.Errorf("branch %q still exists in the project repository (use --force to delete its snapshots anyway)", name)
+		}
+		deleted = append(deleted, branch)
+	}
+
+	for _, branch := range deleted {
+		if err := g.deleteShadowBranch(branch.Name); err != nil {
+			return nil, err
+		}
+	}
+	if len(deleted) == 0 {
+		return nil, nil
+	}
+	return deleted, g.reclaimSpace()
+}
+
+// deleteShadowBranch deletes a shadow branch ref; its snapshots stay in the
+// object store until the next garbage collection
+func (g *GitManager) deleteShadowBranch(name string) error {
+	if _, err := g.RunCommand("update-ref", "-d", "refs/heads/"+name); err != nil {
+		return fmt.Errorf("failed to delete shadow branch %q: %w", name, err)
+	}
+	return nil
+}
+
+// BranchChange is a shadow branch renamed or pruned by ReconcileBranches
+type BranchChange struct {
+	Branch    string
+	RenamedTo string // Empty when the branch was pruned
+	Unique    int    // Snapshots only the pruned branch contained
+}
+
+// ReconcileBranches makes the shadow branches follow the project
+// repository: a branch renamed there is renamed along with it, and one
+// deleted there is pruned. The current shadow branch is never pruned, and
+// branches that were only moved (rebase, checkout -B) are left alone.
+// Pruned snapshots are not garbage-collected here, so the caller isn't held
+// up by a gc; they disappear with the shadow repository's next one.
+func (g *GitManager) ReconcileBranches() ([]BranchChange, error) {
+	branches, err := g.ShadowBranches()
+	if err != nil {
+		return nil, err
+	}
+	shadow := make(map[string]bool, len(branches))
+	for _, branch := range branches {
+		shadow[branch.Name] = true
+	}
+
+	var renames map[string]string // Read lazily; most reconciliations change nothing
+	var changes []BranchChange
+	for _, branch := range branches {
+		if branch.InProject {
+			continue
+		}
+		if renames == nil {
+			renames = projectRenames(CommonDir(g.State.GitDir))
+		}
+		if to := g.renamedTo(branch.Name, renames); to != "" && !shadow[to] {
+			if _, err := g.RunCommand("branch", "-m", branch.Name, to); err != nil {
+				return changes, fmt.Errorf("failed to rename shadow branch %q to %q: %w", branch.Name, to, err)
+			}
+			shadow[to] = true
+			changes = append(changes, BranchChange{Branch: branch.Name, RenamedTo: to})
+			continue
+		}
+		if branch.Current {
+			continue
+		}
+		if err := g.deleteShadowBranch(branch.Name); err != nil {
+			return changes, err
+		}
+		changes = append(changes, BranchChange{Branch: branch.Name, Unique: branch.Unique})
+	}
+	return changes, nil
+}
+
+// renamedTo follows the renames of a branch to the name it has in the
+// project repository now, or returns "" if it was not renamed or the
+// renamed branch is gone as well
+func (g *GitManager) renamedTo(name string, renames map[string]string) string {
+	for range renames {
+		to, ok := renames[name]
+		if !ok {
+			return ""
+		}
+		if hash, err := ResolveRef(g.State.GitDir, "refs/heads/"+to); err == nil && hash != "" {
+			return to
+		}
+		name = to
+	}
+	return ""
+}
+
+// projectRenames maps the branches renamed in the project repository to
+// their new names, read from the "Branch: renamed" entries of its reflogs
+func projectRenames(gitDir string) map[string]string {
+	renames := make(map[string]string)
+	filepath.WalkDir(filepath.Join(gitDir, "logs", "refs", "heads"), func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return nil
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+		for _, line := range strings.Split(string(content), "\n") {
+			_, message, ok := strings.Cut(line, "\tBranch: renamed refs/heads/")
+			if !ok {
+				continue
+			}
+			if from, to, ok := strings.Cut(message, " to refs/heads/"); ok {
+				renames[from] = to
+			}
+		}
+		return nil
+	})
+	return renames
+}
@@ -0,0 +1,194 @@
+package core
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// projectGit runs git in the project repository of a test
+func projectGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	output, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v failed: %v\n%s", args, err, output)
+	}
+	return strings.TrimSpace(string(output))
+}
+
+func TestShadowBranches(t *testing.T) {
+	tempDir, _, gitManager := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	snapshotFile(t, tempDir, gitManager, "one")
+	snapshotFile(t, tempDir, gitManager, "two")
+	snapshotFile(t, tempDir, gitManager, "three")
+	current, err := gitManager.RunCommand("symbolic-ref", "--short", "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// "kept" exists in the project, "gone" only in the shadow repo with a
+	// snapshot of its own
+	projectGit(t, tempDir, "commit", "--allow-empty", "-m", "init")
+	projectGit(t, tempDir, "branch", "kept")
+	if _, err := gitManager.RunCommand("branch", "kept", "HEAD~1"); err != nil {
+		t.Fatal(err)
+	}
+	unique, err := gitManager.RunCommand("commit-tree", "HEAD^{tree}", "-p", "HEAD", "-m", "unique")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := gitManager.RunCommand("branch", "gone", unique); err != nil {
+		t.Fatal(err)
+	}
+
+	branches, err := gitManager.ShadowBranches()
+	if err != nil {
+		t.Fatalf("ShadowBranches failed: %v", err)
+	}
+	byName := make(map[string]ShadowBranch)
+	for _, branch := range branches {
+		byName[branch.Name] = branch
+	}
+	if len(branches) != 3 || !byName[current].Current || !byName[current].InProject {
+		t.Fatalf("Expected 3 branches with %s current, got %+v", current, branches)
+	}
+	if gone := byName["gone"]; gone.InProject || gone.Snapshots != 4 || gone.Unique != 1 || gone.LastTime == "" {
+		t.Errorf("Expected gone to have 4 snapshots, 1 of its own, and no project branch, got %+v", gone)
+	}
+	if kept := byName["kept"]; !kept.InProject || kept.Snapshots != 2 || kept.Unique != 0 {
+		t.Errorf("Expected kept to have 2 shared snapshots and a project branch, got %+v", kept)
+	}
+
+	// Listing all branches tells where each snapshot was found
+	found := false
+	err = gitManager.WalkSnapshots(SnapshotFilter{AllBranches: true}, func(snapshot Snapshot) error {
+		if snapshot.Message == "unique" {
+			found = snapshot.Branch == "gone"
+		}
+		return nil
+	})
+	if err != nil || !found {
+		t.Errorf("Expected the unique snapshot to be listed on gone (err %v)", err)
+	}
+	if _, err := gitManager.FilterSnapshots(SnapshotFilter{AllBranches: true, Branch: "gone"}); err == nil {
+		t.Errorf("Expected an error when combining a branch with all branches")
+	}
+
+	for _, names := range [][]string{{current}, {"kept"}, {"missing"}, {"gone", "kept"}} {
+		if _, err := gitManager.DeleteShadowBranches(names, false); err == nil {
+			t.Errorf("Expected deleting %v to fail", names)
+		}
+	}
+	if branches, _ := gitManager.ShadowBranches(); len(branches) != 3 {
+		t.Fatalf("Expected a failed deletion to delete nothing, got %+v", branches)
+	}
+
+	deleted, err := gitManager.DeleteShadowBranches([]string{"gone"}, false)
+	if err != nil || len(deleted) != 1 || deleted[0].Unique != 1 {
+		t.Fatalf("Expected gone to be deleted with 1 snapshot, got %+v, %v", deleted, err)
+	}
+	if _, err := gitManager.RunCommand("cat-file", "-e", unique); err == nil {
+		t.Errorf("Expected the unique snapshot to be garbage-collected")
+	}
+	if _, err := gitManager.DeleteShadowBranches([]string{"kept"}, true); err != nil {
+		t.Errorf("Expected --force to delete kept: %v", err)
+	}
+	if branches, _ := gitManager.ShadowBranches(); len(branches) != 1 {
+		t.Errorf("Expected only %s to be left, got %+v", current, branches)
+	}
+}
+
+func TestReconcileBranches(t *testing.T) {
+	tempDir, _, gitManager := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	snapshotFile(t, tempDir, gitManager, "one")
+	current, err := gitManager.RunCommand("symbolic-ref", "--short", "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	project := func(args ...string) string {
+		t.Helper()
+		return projectGit(t, tempDir, args...)
+	}
+	project("commit", "--allow-empty", "-m", "init")
+	main := project("symbolic-ref", "--short", "HEAD")
+	for _, name := range []string{"old", "gone", "rebased", "reset"} {
+		project("branch", name)
+		if _, err := gitManager.RunCommand("branch", name); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Rebased and reset branches move but keep their names
+	project("checkout", "-q", "rebased")
+	project("commit", "--allow-empty", "-m", "feature")
+	project("checkout", "-q", main)
+	project("commit", "--allow-empty", "-m", "upstream")
+	project("rebase", "-q", main, "rebased")
+	project("checkout", "-q", "-B", "reset", main+"~1")
+	project("checkout", "-q", main)
+	project("branch", "-m", "old", "new")
+	project("branch", "-D", "gone")
+
+	changes, err := gitManager.ReconcileBranches()
+	if err != nil {
+		t.Fatalf("ReconcileBranches failed: %v", err)
+	}
+	expected := []BranchChange{{Branch: "gone"}, {Branch: "old", RenamedTo: "new"}}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Errorf("Expected changes %+v, got %+v", expected, changes)
+	}
+
+	var names []string
+	branches, _ := gitManager.ShadowBranches()
+	for _, branch := range branches {
+		names = append(names, branch.Name)
+	}
+	if expected := []string{current, "new", "rebased", "reset"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("Expected shadow branches %v, got %v", expected, names)
+	}
+
+	if changes, err := gitManager.ReconcileBranches(); err != nil || len(changes) != 0 {
+		t.Errorf("Expected nothing left to reconcile, got %+v, %v", changes, err)
+	}
+}
+
+func TestWatcherFollowsBranchRename(t *testing.T) {
+	tempDir, state, gitManager := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	projectGit(t, tempDir, "commit", "--allow-empty", "-m", "init")
+	projectGit(t, tempDir, "branch", "feature")
+	if err := os.WriteFile(filepath.Join(tempDir, "file.txt"), []byte("content"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	watcher, err := NewWatcher(state, gitManager)
+	if err != nil {
+		t.Fatalf("NewWatcher failed: %v", err)
+	}
+	if err := watcher.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer watcher.Stop()
+	if _, err := gitManager.RunCommand("branch", "feature"); err != nil {
+		t.Fatal(err)
+	}
+
+	projectGit(t, tempDir, "branch", "-m", "feature", "feature/renamed")
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if hash, _ := gitManager.RunCommand("rev-parse", "--verify", "--quiet", "refs/heads/feature/renamed"); hash != "" {
+			return
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	t.Errorf("Expected the shadow branch to follow the rename")
+}
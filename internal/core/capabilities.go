@@ -0,0 +1,186 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/mattn/go-isatty"
+)
+
+// GitVersion is a parsed `git version` number
+type GitVersion struct {
+	Major, Minor, Patch int
+}
+
+// AtLeast reports whether the version is major.minor or newer
+func (v GitVersion) AtLeast(major, minor int) bool {
+	return v.Major > major || (v.Major == major && v.Minor >= minor)
+}
+
+// String formats the version as major.minor.patch
+func (v GitVersion) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// parseGitVersion parses `git version` output such as "git version 2.39.5",
+// "git version 2.45.1.windows.1" or "git version 2.39.3 (Apple Git-146)"
+func parseGitVersion(output string) (GitVersion, error) {
+	fields := strings.Fields(output)
+	if len(fields) < 3 || fields[0] != "git" || fields[1] != "version" {
+		return GitVersion{}, fmt.Errorf("unexpected git version output %q", strings.TrimSpace(output))
+	}
+
+	var numbers [3]int
+	for i, part := range strings.SplitN(fields[2], ".", 4) {
+		if i == 3 {
+			break
+		}
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			if i < 2 {
+				return GitVersion{}, fmt.Errorf("unexpected git version %q", fields[2])
+			}
+			break // e.g. "2.40.rc0"
+		}
+		numbers[i] = n
+	}
+	return GitVersion{Major: numbers[0], Minor: numbers[1], Patch: numbers[2]}, nil
+}
+
+// Capabilities describes what the environment supports. Commands consult it
+// to fall back to older mechanisms, or to explain what is unavailable,
+// instead of failing with a cryptic git error.
+type Capabilities struct {
+	Git          bool       // A git binary is on PATH
+	GitVersion   GitVersion // Zero when git is missing
+	GitRestore   bool       // git restore/switch (2.23)
+	GitDiskUsage bool       // git rev-list --disk-usage (2.31)
+
+	WatchBackend string // fsnotify backend for this platform
+	WatchError   error  // Why a file watcher cannot be created, if it can't
+
+	Color         bool   // Standard output is a terminal that accepts color
+	Clipboard     string // Command that copies to the clipboard, "" if none
+	Notifications string // Command that shows desktop notifications, "" if none
+}
+
+var (
+	capabilitiesOnce  sync.Once
+	probeCapabilities *Capabilities
+)
+
+// ProbeCapabilities detects the environment's capabilities once per process
+func ProbeCapabilities() *Capabilities {
+	capabilitiesOnce.Do(func() {
+		probeCapabilities = detectCapabilities()
+	})
+	return probeCapabilities
+}
+
+// Capabilities returns the environment's capabilities, probing on first use
+func (s *AppState) Capabilities() *Capabilities {
+	if s.capabilities == nil {
+		s.capabilities = ProbeCapabilities()
+	}
+	return s.capabilities
+}
+
+func detectCapabilities() *Capabilities {
+	caps := &Capabilities{WatchBackend: watchBackend()}
+
+	if output, err := exec.Command("git", "version").Output(); err == nil {
+		if version, err := parseGitVersion(string(output)); err == nil {
+			caps.Git = true
+			caps.GitVersion = version
+			caps.GitRestore = version.AtLeast(2, 23)
+			caps.GitDiskUsage = version.AtLeast(2, 31)
+		}
+	}
+
+	if watcher, err := fsnotify.NewWatcher(); err != nil {
+		caps.WatchError = err
+	} else {
+		watcher.Close()
+	}
+
+	fd := os.Stdout.Fd()
+	caps.Color = (isatty.IsTerminal(fd) || isatty.IsCygwinTerminal(fd)) &&
+		os.Getenv("NO_COLOR") == "" && os.Getenv("TERM") != "dumb"
+	caps.Clipboard = firstCommand(clipboardCommands())
+	caps.Notifications = firstCommand(notificationCommands())
+
+	return caps
+}
+
+// watchBackend names the kernel interface fsnotify uses on this platform
+func watchBackend() string {
+	switch runtime.GOOS {
+	case "linux", "android":
+		return "inotify"
+	case "darwin", "freebsd", "openbsd", "netbsd", "dragonfly":
+		return "kqueue"
+	case "windows":
+		return "ReadDirectoryChangesW"
+	case "solaris", "illumos":
+		return "FEN"
+	}
+	return "unsupported"
+}
+
+func clipboardCommands() []string {
+	switch runtime.GOOS {
+	case "darwin":
+		return []string{"pbcopy"}
+	case "windows":
+		return []string{"clip.exe"}
+	}
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		return []string{"wl-copy", "xclip", "xsel"}
+	}
+	return []string{"xclip", "xsel", "wl-copy"}
+}
+
+func notificationCommands() []string {
+	switch runtime.GOOS {
+	case "darwin":
+		return []string{"terminal-notifier", "osascript"}
+	case "windows":
+		return []string{"powershell.exe"}
+	}
+	return []string{"notify-send"}
+}
+
+// firstCommand returns the first of names found on PATH
+func firstCommand(names []string) string {
+	for _, name := range names {
+		if _, err := exec.LookPath(name); err == nil {
+			return name
+		}
+	}
+	return ""
+}
+
+// Limitations describes, one line each, what works differently or not at
+// all in this environment
+func (c *Capabilities) Limitations() []string {
+	var limits []string
+	if !c.Git {
+		return append(limits, "git was not found on PATH; Time Machine needs git to work")
+	}
+	if !c.GitRestore {
+		limits = append(limits, fmt.Sprintf("git %s has no 'git restore' (2.23+): restores use 'git checkout' and leave files added since the snapshot in place", c.GitVersion))
+	}
+	if !c.GitDiskUsage {
+		limits = append(limits, fmt.Sprintf("git %s has no 'rev-list --disk-usage' (2.31+): snapshot sizes are computed object by object, which is slower", c.GitVersion))
+	}
+	if c.WatchError != nil {
+		limits = append(limits, fmt.Sprintf("file watching (%s) is unavailable: %v", c.WatchBackend, c.WatchError))
+	}
+	return limits
+}
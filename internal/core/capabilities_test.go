@@ -0,0 +1,121 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseGitVersion(t *testing.T) {
+	tests := []struct {
+		output   string
+		expected GitVersion
+		wantErr  bool
+	}{
+		{"git version 2.39.5\n", GitVersion{2, 39, 5}, false},
+		{"git version 2.45.1.windows.1", GitVersion{2, 45, 1}, false},
+		{"git version 2.39.3 (Apple Git-146)", GitVersion{2, 39, 3}, false},
+		{"git version 2.40.rc0", GitVersion{2, 40, 0}, false},
+		{"git version 1.8", GitVersion{1, 8, 0}, false},
+		{"hub version 2.14.2", GitVersion{}, true},
+		{"git version banana", GitVersion{}, true},
+		{"", GitVersion{}, true},
+	}
+
+	for _, tt := range tests {
+		version, err := parseGitVersion(tt.output)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseGitVersion(%q): expected error %v, got %v", tt.output, tt.wantErr, err)
+			continue
+		}
+		if version != tt.expected {
+			t.Errorf("parseGitVersion(%q): expected %v, got %v", tt.output, tt.expected, version)
+		}
+	}
+}
+
+func TestGitVersionAtLeast(t *testing.T) {
+	tests := []struct {
+		version      GitVersion
+		major, minor int
+		expected     bool
+	}{
+		{GitVersion{2, 23, 0}, 2, 23, true},
+		{GitVersion{2, 22, 9}, 2, 23, false},
+		{GitVersion{3, 0, 0}, 2, 31, true},
+		{GitVersion{1, 99, 0}, 2, 0, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.version.AtLeast(tt.major, tt.minor); got != tt.expected {
+			t.Errorf("%v.AtLeast(%d, %d): expected %v, got %v", tt.version, tt.major, tt.minor, tt.expected, got)
+		}
+	}
+}
+
+func TestCapabilitiesLimitations(t *testing.T) {
+	caps := &Capabilities{Git: true, GitVersion: GitVersion{2, 20, 1}}
+	if limits := caps.Limitations(); len(limits) != 2 {
+		t.Errorf("Expected restore and disk usage limitations for git 2.20, got %q", limits)
+	}
+
+	caps = &Capabilities{Git: true, GitVersion: GitVersion{2, 39, 5}, GitRestore: true, GitDiskUsage: true}
+	if limits := caps.Limitations(); len(limits) != 0 {
+		t.Errorf("Expected no limitations, got %q", limits)
+	}
+
+	if limits := (&Capabilities{}).Limitations(); len(limits) != 1 {
+		t.Errorf("Expected a single missing git limitation, got %q", limits)
+	}
+}
+
+func TestRestoreWithoutGitRestore(t *testing.T) {
+	tempDir, state, gitManager := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	snapshotFile(t, tempDir, gitManager, "original")
+	hash, _ := gitManager.RunCommand("rev-parse", "HEAD")
+	indexBefore, _ := os.ReadFile(filepath.Join(state.ShadowRepoDir, "index"))
+
+	os.WriteFile(filepath.Join(tempDir, "file.txt"), []byte("modified"), 0644)
+
+	// Pretend to run on git older than 2.23
+	state.capabilities = &Capabilities{Git: true, GitVersion: GitVersion{2, 20, 1}}
+	if err := gitManager.RestoreSnapshot(hash, []string{"file.txt"}); err != nil {
+		t.Fatalf("RestoreSnapshot failed: %v", err)
+	}
+
+	if content, _ := os.ReadFile(filepath.Join(tempDir, "file.txt")); string(content) != "original" {
+		t.Errorf("Expected file to be restored to %q, got %q", "original", content)
+	}
+	if indexAfter, _ := os.ReadFile(filepath.Join(state.ShadowRepoDir, "index")); string(indexAfter) != string(indexBefore) {
+		t.Errorf("Expected the shadow index to be left alone")
+	}
+	if matches, _ := filepath.Glob(filepath.Join(state.ShadowRepoDir, "restore-index-*")); len(matches) != 0 {
+		t.Errorf("Expected the temporary index to be removed, found %v", matches)
+	}
+}
+
+func TestDiskUsageWithoutGitSupport(t *testing.T) {
+	tempDir, state, gitManager := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	snapshotFile(t, tempDir, gitManager, "v1")
+	snapshotFile(t, tempDir, gitManager, "v2")
+	hash, _ := gitManager.RunCommand("rev-parse", "HEAD")
+
+	state.capabilities = &Capabilities{Git: true, GitDiskUsage: true}
+	expected, err := gitManager.diskUsage([]string{hash, hash + "~1"})
+	if err != nil {
+		t.Fatalf("diskUsage failed: %v", err)
+	}
+
+	state.capabilities = &Capabilities{Git: true}
+	got, err := gitManager.diskUsage([]string{hash, hash + "~1"})
+	if err != nil {
+		t.Fatalf("diskUsage fallback failed: %v", err)
+	}
+	if got != expected || got == 0 {
+		t.Errorf("Expected fallback size %d, got %d", expected, got)
+	}
+}
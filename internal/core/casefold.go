@@ -0,0 +1,101 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// caseInsensitiveDirs caches IsCaseInsensitiveFS results per directory
+var caseInsensitiveDirs sync.Map
+
+// IsCaseInsensitiveFS reports whether the filesystem holding dir treats
+// names that differ only in case as the same file, as macOS and Windows do
+// by default. The result is cached per directory.
+func IsCaseInsensitiveFS(dir string) bool {
+	if cached, ok := caseInsensitiveDirs.Load(dir); ok {
+		return cached.(bool)
+	}
+	insensitive := detectCaseInsensitive(dir)
+	caseInsensitiveDirs.Store(dir, insensitive)
+	return insensitive
+}
+
+// detectCaseInsensitive looks up an existing entry of dir under a different
+// case (a project root always has .git), creating a probe file only when no
+// entry has letters in its name
+func detectCaseInsensitive(dir string) bool {
+	if entries, err := os.ReadDir(dir); err == nil {
+		for _, entry := range entries {
+			name := entry.Name()
+			swapped := swapCase(name)
+			if swapped == name {
+				continue
+			}
+			original, err := os.Lstat(filepath.Join(dir, name))
+			if err != nil {
+				continue
+			}
+			other, err := os.Lstat(filepath.Join(dir, swapped))
+			return err == nil && os.SameFile(original, other)
+		}
+	}
+
+	probe, err := os.CreateTemp(dir, ".timemachine-case-probe-")
+	if err != nil {
+		return false
+	}
+	probe.Close()
+	defer os.Remove(probe.Name())
+
+	_, err = os.Lstat(filepath.Join(dir, swapCase(filepath.Base(probe.Name()))))
+	return err == nil
+}
+
+func swapCase(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsUpper(r) {
+			return unicode.ToLower(r)
+		}
+		return unicode.ToUpper(r)
+	}, s)
+}
+
+// pathKey returns the key under which the filesystem identifies a path:
+// the path itself, or its case-folded form on case-insensitive filesystems
+func pathKey(path string, caseInsensitive bool) string {
+	if caseInsensitive {
+		return strings.ToLower(path)
+	}
+	return path
+}
+
+// CaseInsensitive reports whether the project lives on a case-insensitive
+// filesystem
+func (s *AppState) CaseInsensitive() bool {
+	return IsCaseInsensitiveFS(s.ProjectRoot)
+}
+
+// icasePathspecs adds git's icase magic to pathspecs on case-insensitive
+// filesystems, so "readme.md" finds README.md like the filesystem would.
+// Pathspecs using short magic (":/", ":!") are left as they are.
+func (g *GitManager) icasePathspecs(pathspecs []string) []string {
+	if !g.State.CaseInsensitive() {
+		return pathspecs
+	}
+
+	result := make([]string, len(pathspecs))
+	for i, pathspec := range pathspecs {
+		switch {
+		case strings.HasPrefix(pathspec, ":("):
+			result[i] = ":(icase," + strings.TrimPrefix(pathspec, ":(")
+		case strings.HasPrefix(pathspec, ":"):
+			result[i] = pathspec
+		default:
+			result[i] = ":(icase)" + pathspec
+		}
+	}
+	return result
+}
@@ -0,0 +1,133 @@
+package core
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// simulateCaseInsensitive makes IsCaseInsensitiveFS report dir as
+// case-insensitive until the returned function is called
+func simulateCaseInsensitive(dir string) func() {
+	caseInsensitiveDirs.Store(dir, true)
+	return func() { caseInsensitiveDirs.Delete(dir) }
+}
+
+func TestDetectCaseInsensitive(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "timemachine-case-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	// Empty directory: detection falls back to a probe file
+	empty := detectCaseInsensitive(tempDir)
+	entries, _ := os.ReadDir(tempDir)
+	if len(entries) != 0 {
+		t.Errorf("Expected probe file to be removed, found %d entries", len(entries))
+	}
+
+	if err := os.WriteFile(filepath.Join(tempDir, "Name.txt"), nil, 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	_, err = os.Stat(filepath.Join(tempDir, "nAME.TXT"))
+	expected := err == nil
+
+	if got := detectCaseInsensitive(tempDir); got != expected {
+		t.Errorf("Expected case-insensitive %v, got %v", expected, got)
+	}
+	if empty != expected {
+		t.Errorf("Expected probe result %v, got %v", expected, empty)
+	}
+}
+
+func TestIcasePathspecs(t *testing.T) {
+	tempDir, _, gitManager := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	input := []string{"src/Main.go", topPathspecPrefix + "README.md", ":/docs", ":!vendor"}
+	if got := gitManager.icasePathspecs(input); !reflect.DeepEqual(got, input) {
+		t.Errorf("Expected pathspecs unchanged on case-sensitive filesystem, got %v", got)
+	}
+
+	defer simulateCaseInsensitive(tempDir)()
+	expected := []string{":(icase)src/Main.go", ":(icase,top,literal)README.md", ":/docs", ":!vendor"}
+	if got := gitManager.icasePathspecs(input); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+func TestIgnoreManagerCaseInsensitive(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "timemachine-case-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+	defer simulateCaseInsensitive(tempDir)()
+
+	if err := os.WriteFile(filepath.Join(tempDir, DefaultIgnoreFile), []byte("Build/\n*.LOG\n"), 0644); err != nil {
+		t.Fatalf("Failed to write ignore file: %v", err)
+	}
+	manager := NewEnhancedIgnoreManager(tempDir)
+
+	tests := []struct {
+		path     string
+		expected bool
+	}{
+		{"build/out.bin", true},
+		{"BUILD/out.bin", true},
+		{"debug.log", true},
+		{"Debug.Log", true},
+		{"src/Main.go", false},
+	}
+	for _, tt := range tests {
+		if got := manager.ShouldIgnore(filepath.Join(tempDir, tt.path)); got != tt.expected {
+			t.Errorf("ShouldIgnore(%q): expected %v, got %v", tt.path, tt.expected, got)
+		}
+	}
+
+	// Differently cased spellings of one file share a cache entry
+	manager.ClearCache()
+	manager.ShouldIgnore(filepath.Join(tempDir, "src", "Main.go"))
+	manager.ShouldIgnore(filepath.Join(tempDir, "SRC", "main.GO"))
+	if hits, misses, _, _, _ := manager.GetStats(); hits != 1 || misses != 1 {
+		t.Errorf("Expected 1 hit and 1 miss, got %d hits and %d misses", hits, misses)
+	}
+}
+
+func TestJournaledRestoreCaseInsensitive(t *testing.T) {
+	tempDir, _, gitManager := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	readme := filepath.Join(tempDir, "README.md")
+	if err := os.WriteFile(readme, []byte("original"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := gitManager.CreateSnapshot("original"); err != nil {
+		t.Fatalf("CreateSnapshot failed: %v", err)
+	}
+	source, _ := gitManager.RunCommand("rev-parse", "HEAD")
+
+	if err := os.WriteFile(readme, []byte("changed"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	defer simulateCaseInsensitive(tempDir)()
+	wd, _ := os.Getwd()
+	defer os.Chdir(wd)
+	os.Chdir(tempDir)
+
+	// The user spells the file the way their filesystem lets them
+	journal, err := gitManager.JournaledRestore(context.Background(), source, []string{"readme.md"}, DefaultRestoreBatchSize, nil)
+	if err != nil {
+		t.Fatalf("JournaledRestore failed: %v", err)
+	}
+	if !reflect.DeepEqual(journal.Files, []string{"README.md"}) {
+		t.Errorf("Expected README.md to be restored, got %v", journal.Files)
+	}
+	if content, _ := os.ReadFile(readme); string(content) != "original" {
+		t.Errorf("Expected restored content, got %q", content)
+	}
+}
@@ -0,0 +1,68 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWatcherCatchUp(t *testing.T) {
+	tempDir, state, gitManager := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(tempDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	start := func(catchUp bool) {
+		t.Helper()
+		watcher, err := NewWatcher(state, gitManager)
+		if err != nil {
+			t.Fatalf("NewWatcher failed: %v", err)
+		}
+		watcher.SetCatchUp(catchUp)
+		if err := watcher.Start(); err != nil {
+			t.Fatalf("Start failed: %v", err)
+		}
+		watcher.Stop()
+	}
+	latest := func() string {
+		message, err := gitManager.RunCommand("log", "-1", "--format=%s")
+		if err != nil {
+			t.Fatalf("git log failed: %v", err)
+		}
+		return message
+	}
+	count := func() string {
+		count, _ := gitManager.RunCommand("rev-list", "--count", "HEAD")
+		return count
+	}
+
+	write("a.txt", "v1")
+	start(true)
+	if message := latest(); strings.HasPrefix(message, "Catch-up") {
+		t.Errorf("Expected the first snapshot not to be a catch-up, got %q", message)
+	}
+
+	// Nothing changed while stopped
+	start(true)
+	if count() != "1" {
+		t.Errorf("Expected no catch-up snapshot without changes, got %s snapshots", count())
+	}
+
+	write("a.txt", "v2") // While stopped
+	start(false)
+	if count() != "1" {
+		t.Errorf("Expected --no-catchup to skip the catch-up snapshot, got %s snapshots", count())
+	}
+
+	start(true)
+	if message := latest(); !strings.HasPrefix(message, "Catch-up snapshot at ") {
+		t.Errorf("Expected a catch-up snapshot, got %q", message)
+	}
+	if content, _ := gitManager.RunCommand("show", "HEAD:a.txt"); content != "v2" {
+		t.Errorf("Expected the catch-up snapshot to hold the change, got %q", content)
+	}
+}
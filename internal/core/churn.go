@@ -0,0 +1,153 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/config"
+)
+
+// Normalizers of watcher.churn_filters, applied in this order whatever order
+// they are listed in (see config.ChurnNormalizers)
+const (
+	NormalizeTimestamps = "timestamps" // Dates and times become a placeholder
+	NormalizeJSON       = "json"       // JSON is reformatted with sorted keys
+	NormalizeSortLines  = "sort_lines" // Lines are sorted
+	NormalizeWhitespace = "whitespace" // Runs of whitespace become one space
+)
+
+// maxChurnFileSize bounds the files churn filters read; larger changes are
+// always snapshotted
+const maxChurnFileSize = 16 << 20
+
+// timestampPattern matches ISO 8601 dates and times, with optional seconds,
+// fractions and zone, and the date-only form
+var timestampPattern = regexp.MustCompile(`\d{4}-\d{2}-\d{2}(?:[T ]\d{2}:\d{2}(?::\d{2}(?:[.,]\d+)?)?(?:Z|[+-]\d{2}:?\d{2})?)?`)
+
+// churnFilter is a watcher.churn_filters entry, its pattern parsed like a
+// line of a .gitignore at the project root
+type churnFilter struct {
+	pattern     gitignorePattern
+	normalizers []string
+}
+
+// parseChurnFilters parses watcher.churn_filters, skipping entries without
+// a pattern or normalizers
+func parseChurnFilters(filters []config.ChurnFilter) []churnFilter {
+	var parsed []churnFilter
+	for _, filter := range filters {
+		pattern, ok := parseGitignoreLine(filter.Pattern, "")
+		if !ok || pattern.negation || len(filter.Normalize) == 0 {
+			continue
+		}
+		parsed = append(parsed, churnFilter{pattern: pattern, normalizers: filter.Normalize})
+	}
+	return parsed
+}
+
+// churnNormalizersFor returns the normalizers of the first filter matching
+// a project-root-relative path, nil when none does
+func churnNormalizersFor(filters []churnFilter, rel string) []string {
+	for _, filter := range filters {
+		if filter.pattern.matches(rel, false) || filter.pattern.matchesParent(rel) {
+			return filter.normalizers
+		}
+	}
+	return nil
+}
+
+// NormalizeContent applies normalizers to content in their fixed order.
+// Content the json normalizer can't parse is left as it is.
+func NormalizeContent(content []byte, normalizers []string) []byte {
+	if slices.Contains(normalizers, NormalizeTimestamps) {
+		content = timestampPattern.ReplaceAll(content, []byte("<timestamp>"))
+	}
+	if slices.Contains(normalizers, NormalizeJSON) {
+		var value interface{}
+		decoder := json.NewDecoder(bytes.NewReader(content))
+		decoder.UseNumber()
+		if decoder.Decode(&value) == nil {
+			// Maps are encoded with their keys sorted
+			if encoded, err := json.MarshalIndent(value, "", "  "); err == nil {
+				content = encoded
+			}
+		}
+	}
+	if slices.Contains(normalizers, NormalizeSortLines) {
+		lines := strings.Split(strings.TrimRight(string(content), "\n"), "\n")
+		slices.Sort(lines)
+		content = []byte(strings.Join(lines, "\n"))
+	}
+	if slices.Contains(normalizers, NormalizeWhitespace) {
+		content = []byte(strings.Join(strings.Fields(string(content)), " "))
+	}
+	return content
+}
+
+// unstageChurn takes staged changes that only churn back out of the index:
+// modified files matching watcher.churn_filters whose snapshotted and
+// staged contents are the same once normalized. The working copies are
+// untouched, so a later real change snapshots them whole. It returns the
+// paths unstaged.
+func (g *GitManager) unstageChurn() ([]string, error) {
+	if g.State.Config == nil || len(g.State.Config.Watcher.ChurnFilters) == 0 {
+		return nil, nil
+	}
+	filters := parseChurnFilters(g.State.Config.Watcher.ChurnFilters)
+
+	output, err := g.runCommandRaw("diff", "--cached", "--name-only", "-z", "--no-renames", "--diff-filter=M")
+	if err != nil {
+		// Nothing to compare against before the first snapshot
+		return nil, nil
+	}
+
+	var churned []string
+	for _, path := range strings.Split(string(output), "\x00") {
+		normalizers := churnNormalizersFor(filters, path)
+		if path == "" || normalizers == nil {
+			continue
+		}
+		before, err := g.churnBlob("HEAD:" + path)
+		if err != nil {
+			continue
+		}
+		after, err := g.churnBlob(":0:" + path)
+		if err != nil {
+			continue
+		}
+		if bytes.Equal(NormalizeContent(before, normalizers), NormalizeContent(after, normalizers)) {
+			churned = append(churned, path)
+		}
+	}
+
+	for start := 0; start < len(churned); start += stageChunkSize {
+		chunk := churned[start:min(start+stageChunkSize, len(churned))]
+		args := append([]string{"reset", "-q", "HEAD", "--"}, topPathspecs(chunk)...)
+		if _, err := g.RunCommand(args...); err != nil {
+			return nil, fmt.Errorf("failed to unstage churned files: %w", err)
+		}
+	}
+	return churned, nil
+}
+
+// churnBlob reads a blob for comparison, refusing ones over
+// maxChurnFileSize
+func (g *GitManager) churnBlob(object string) ([]byte, error) {
+	output, err := g.RunCommand("cat-file", "-s", object)
+	if err != nil {
+		return nil, err
+	}
+	size, err := strconv.ParseInt(output, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("unexpected size %q of %s", output, object)
+	}
+	if size > maxChurnFileSize {
+		return nil, fmt.Errorf("%s is too large to normalize", object)
+	}
+	return g.runCommandRaw("cat-file", "blob", object)
+}
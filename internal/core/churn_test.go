@@ -0,0 +1,106 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/config"
+)
+
+func TestNormalizeContent(t *testing.T) {
+	tests := []struct {
+		name        string
+		before      string
+		after       string
+		normalizers []string
+		same        bool
+	}{
+		{"json key order", `{"b": 1, "a": [1, 2]}`, "{\n  \"a\": [1,2],\n  \"b\": 1\n}\n", []string{NormalizeJSON}, true},
+		{"json value changed", `{"a": 1}`, `{"a": 2}`, []string{NormalizeJSON}, false},
+		{"json big numbers kept", `{"a": 12345678901234567890}`, `{"a": 12345678901234567891}`, []string{NormalizeJSON}, false},
+		{"not json", "a: 1", "a:  1", []string{NormalizeJSON}, false},
+		{"timestamps", "built 2026-01-02T03:04:05Z\n", "built 2026-10-16 08:09:10.123+02:00\n", []string{NormalizeTimestamps}, true},
+		{"timestamps keep text", "built 2026-01-02 by a", "built 2026-01-03 by b", []string{NormalizeTimestamps}, false},
+		{"sorted lines", "b\na\nc\n", "a\nb\nc", []string{NormalizeSortLines}, true},
+		{"whitespace", "a  b\n\tc\n", "a b c", []string{NormalizeWhitespace}, true},
+		{"whitespace keeps words apart", "a b", "ab", []string{NormalizeWhitespace}, false},
+		{"fixed order", "z 2026-01-02\na\n", "a\nz 2025-12-31", []string{NormalizeSortLines, NormalizeTimestamps}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := string(NormalizeContent([]byte(tt.before), tt.normalizers))
+			after := string(NormalizeContent([]byte(tt.after), tt.normalizers))
+			if (before == after) != tt.same {
+				t.Errorf("Expected same=%v, normalized to %q and %q", tt.same, before, after)
+			}
+		})
+	}
+}
+
+func TestChurnFilters(t *testing.T) {
+	for _, backend := range []string{BackendExec, BackendNative} {
+		t.Run(backend, func(t *testing.T) {
+			tempDir, state, _ := setupTestRepo(t)
+			defer os.RemoveAll(tempDir)
+			state.Config = &config.Config{
+				Git: config.GitConfig{Backend: backend},
+				Watcher: config.WatcherConfig{ChurnFilters: []config.ChurnFilter{
+					{Pattern: "*.lock.json", Normalize: []string{NormalizeJSON}},
+				}},
+			}
+			gitManager := NewGitManager(state)
+
+			write := func(name, content string) {
+				if err := os.WriteFile(filepath.Join(tempDir, name), []byte(content), 0644); err != nil {
+					t.Fatalf("Failed to write %s: %v", name, err)
+				}
+			}
+			count := func() string {
+				out, _ := gitManager.RunCommand("rev-list", "--count", "HEAD")
+				return out
+			}
+
+			write("deps.lock.json", `{"a": 1, "b": 2}`)
+			write("main.go", "package main")
+			if err := gitManager.CreateSnapshot("base"); err != nil {
+				t.Fatalf("CreateSnapshot failed: %v", err)
+			}
+
+			// Only the key order changed: no snapshot
+			write("deps.lock.json", `{"b": 2, "a": 1}`)
+			if err := gitManager.CreateSnapshot(""); err != nil {
+				t.Fatalf("CreateSnapshot failed: %v", err)
+			}
+			if err := gitManager.CreateSnapshotForPaths("", []string{"deps.lock.json"}); err != nil {
+				t.Fatalf("CreateSnapshotForPaths failed: %v", err)
+			}
+			if got := count(); got != "1" {
+				t.Errorf("Expected churn not to be snapshotted, got %s snapshots", got)
+			}
+
+			// Churn alongside a real change stays out of the snapshot
+			write("main.go", "package main // changed")
+			if err := gitManager.CreateSnapshot("change"); err != nil {
+				t.Fatalf("CreateSnapshot failed: %v", err)
+			}
+			files, _ := gitManager.RunCommand("diff-tree", "--no-commit-id", "--name-only", "-r", "HEAD")
+			if files != "main.go" {
+				t.Errorf("Expected only main.go in the snapshot, got %q", files)
+			}
+
+			// A real change to the lockfile is snapshotted whole
+			write("deps.lock.json", `{"b": 3, "a": 1}`)
+			if err := gitManager.CreateSnapshot("bump"); err != nil {
+				t.Fatalf("CreateSnapshot failed: %v", err)
+			}
+			if content, _ := gitManager.RunCommand("show", "HEAD:deps.lock.json"); content != `{"b": 3, "a": 1}` {
+				t.Errorf("Expected the changed lockfile, got %q", content)
+			}
+			if got := count(); got != "3" {
+				t.Errorf("Expected 3 snapshots, got %s", got)
+			}
+		})
+	}
+}
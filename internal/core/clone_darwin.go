@@ -0,0 +1,16 @@
+package core
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// cloneFile creates dst as a copy-on-write clone of src with clonefile(2),
+// which APFS supports. It fails on other filesystems or across volumes.
+func cloneFile(src, dst string, mode os.FileMode) error {
+	if err := unix.Clonefile(src, dst, unix.CLONE_NOFOLLOW); err != nil {
+		return err
+	}
+	return os.Chmod(dst, mode)
+}
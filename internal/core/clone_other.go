@@ -0,0 +1,13 @@
+//go:build !linux && !darwin
+
+package core
+
+import (
+	"errors"
+	"os"
+)
+
+// cloneFile is not supported on this platform; files are copied instead
+func cloneFile(src, dst string, mode os.FileMode) error {
+	return errors.ErrUnsupported
+}
@@ -0,0 +1,393 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// BackupStateFile records, in the shadow repository, what the last upload
+// to backup.url contained, so the next one only sends what is new
+const BackupStateFile = "backup_state.json"
+
+// restoredRefPrefix holds branches fetched from backup bundles while
+// RestoreFromBackup adopts them
+const restoredRefPrefix = "refs/timemachine/restored/"
+
+// Statuses of a branch after RestoreFromBackup
+const (
+	BackupBranchCreated  = "created"    // Didn't exist locally
+	BackupBranchUpdated  = "updated"    // Fast-forwarded to the backup
+	BackupBranchReplaced = "replaced"   // Diverged and replaced; the old history is in BackupRef
+	BackupBranchUpToDate = "up-to-date" // Already had everything
+	BackupBranchDiverged = "diverged"   // Diverged and left alone
+)
+
+// backupState is the content of BackupStateFile
+type backupState struct {
+	URL        string            `json:"url"`
+	Machine    string            `json:"machine"`
+	Sequence   int               `json:"sequence"` // Of the last bundle uploaded
+	Tips       map[string]string `json:"tips"`     // Branch tips the last bundle covered
+	UploadedAt time.Time         `json:"uploaded_at"`
+}
+
+// BackupUpload describes one run of UploadBackup
+type BackupUpload struct {
+	Store     string // Where the bundle went
+	Key       string // Object key of the bundle; empty when nothing was new
+	Snapshots int    // Snapshots in the bundle
+	Bytes     int64
+}
+
+// BackupBundle is one uploaded bundle
+type BackupBundle struct {
+	Key      string `json:"key"`
+	Machine  string `json:"machine"`
+	Sequence int    `json:"sequence"`
+}
+
+// RestoredBranch describes what RestoreFromBackup did to one branch
+type RestoredBranch struct {
+	Branch    string
+	Status    string // One of the BackupBranch statuses
+	Snapshots int    // Snapshots new to this repository
+	BackupRef string // Ref holding replaced local history
+}
+
+// backupURL returns backup.url
+func (g *GitManager) backupURL() string {
+	if g.State.Config == nil {
+		return ""
+	}
+	return g.State.Config.Backup.URL
+}
+
+// backupInterval returns backup.interval, 0 when uploading from the watcher
+// is off
+func (g *GitManager) backupInterval() time.Duration {
+	if g.State.Config == nil || g.backupURL() == "" {
+		return 0
+	}
+	return g.State.Config.Backup.Interval
+}
+
+func (g *GitManager) backupStatePath() string {
+	return filepath.Join(g.State.ShadowRepoDir, BackupStateFile)
+}
+
+func (g *GitManager) readBackupState() backupState {
+	var state backupState
+	if data, err := os.ReadFile(g.backupStatePath()); err == nil {
+		json.Unmarshal(data, &state)
+	}
+	return state
+}
+
+// writeBackupState atomically replaces the backup state on disk
+func (g *GitManager) writeBackupState(state backupState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := g.backupStatePath() + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write backup state: %w", err)
+	}
+	if err := os.Rename(tmp, g.backupStatePath()); err != nil {
+		return fmt.Errorf("failed to write backup state: %w", err)
+	}
+	return nil
+}
+
+// branchTips returns the tip of every shadow branch
+func (g *GitManager) branchTips() (map[string]string, error) {
+	output, err := g.RunCommand("for-each-ref", "--format=%(refname:short) %(objectname)", "refs/heads/")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list branches: %w", err)
+	}
+	tips := make(map[string]string)
+	for _, line := range strings.Split(output, "\n") {
+		if branch, tip, ok := strings.Cut(line, " "); ok {
+			tips[branch] = tip
+		}
+	}
+	return tips, nil
+}
+
+// UploadBackup uploads the snapshots taken since the last upload to
+// backup.url as a git bundle under <machine>/. The first upload, and the
+// first after retention or compaction dropped every snapshot the last one
+// ended at, sends everything. Bundles are numbered, and restoring fetches
+// them in order.
+func (g *GitManager) UploadBackup() (*BackupUpload, error) {
+	store, err := OpenObjectStore(g.backupURL())
+	if err != nil {
+		return nil, err
+	}
+	machine := g.machineName()
+	upload := &BackupUpload{Store: store.String()}
+
+	state := g.readBackupState()
+	if state.URL != g.backupURL() || state.Machine != machine {
+		// A new destination starts from a full bundle, numbered after
+		// whatever this machine already has there
+		bundles, err := listBackupBundles(store, machine)
+		if err != nil {
+			return nil, err
+		}
+		state = backupState{URL: g.backupURL(), Machine: machine}
+		if len(bundles) > 0 {
+			state.Sequence = bundles[len(bundles)-1].Sequence
+		}
+	}
+
+	tips, err := g.branchTips()
+	if err != nil {
+		return nil, err
+	}
+	revs := []string{"--branches"}
+	for _, tip := range state.Tips {
+		// Tips rewritten away since are no longer there to build on
+		if _, err := g.RunCommand("cat-file", "-e", tip+"^{commit}"); err == nil {
+			revs = append(revs, "^"+tip)
+		}
+	}
+	upload.Snapshots = g.countCommits(revs...)
+	if upload.Snapshots == 0 {
+		state.Tips = tips
+		return upload, g.writeBackupState(state)
+	}
+
+	dir, err := os.MkdirTemp("", "timemachine-backup-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+	bundle := filepath.Join(dir, "snapshots.bundle")
+	if _, err := g.RunCommand(append([]string{"bundle", "create", "--quiet", bundle}, revs...)...); err != nil {
+		return nil, fmt.Errorf("failed to create backup bundle: %w", err)
+	}
+	if info, err := os.Stat(bundle); err == nil {
+		upload.Bytes = info.Size()
+	}
+
+	now := time.Now().UTC()
+	key := fmt.Sprintf("%s/%06d-%s.bundle", machine, state.Sequence+1, now.Format("20060102T150405Z"))
+	if err := store.Put(key, bundle); err != nil {
+		return nil, err
+	}
+	upload.Key = key
+	state.Sequence++
+	state.Tips = tips
+	state.UploadedAt = now
+	return upload, g.writeBackupState(state)
+}
+
+// ListBackupBundles returns the bundles at backup.url, in upload order;
+// those of machine only when it is set
+func (g *GitManager) ListBackupBundles(machine string) ([]BackupBundle, error) {
+	store, err := OpenObjectStore(g.backupURL())
+	if err != nil {
+		return nil, err
+	}
+	return listBackupBundles(store, machine)
+}
+
+func listBackupBundles(store ObjectStore, machine string) ([]BackupBundle, error) {
+	if machine != "" && !remoteName.MatchString(machine) {
+		return nil, NewValidationError("invalid machine name %q", machine)
+	}
+	keys, err := store.List(machine)
+	if err != nil {
+		return nil, err
+	}
+	var bundles []BackupBundle
+	for _, key := range keys {
+		dir, file := path.Split(key)
+		sequence, _, ok := strings.Cut(strings.TrimSuffix(file, ".bundle"), "-")
+		if !ok || !strings.HasSuffix(file, ".bundle") || strings.Count(dir, "/") != 1 {
+			continue // Not ours
+		}
+		n, err := strconv.Atoi(sequence)
+		if err != nil {
+			continue
+		}
+		bundles = append(bundles, BackupBundle{Key: key, Machine: strings.TrimSuffix(dir, "/"), Sequence: n})
+	}
+	sort.Slice(bundles, func(i, j int) bool {
+		if bundles[i].Machine != bundles[j].Machine {
+			return bundles[i].Machine < bundles[j].Machine
+		}
+		return bundles[i].Sequence < bundles[j].Sequence
+	})
+	return bundles, nil
+}
+
+// RestoreFromBackup rebuilds the shadow branches of machine, this one when
+// empty, from the bundles at backup.url. Branches missing here are created
+// and those behind are fast-forwarded; the current branch follows the rules
+// of ImportSnapshots. Diverged branches are left alone unless force is set,
+// when they are replaced and kept under refs/timemachine/backup/.
+func (g *GitManager) RestoreFromBackup(machine string, force bool) ([]RestoredBranch, error) {
+	if machine == "" {
+		machine = g.machineName()
+	}
+	store, err := OpenObjectStore(g.backupURL())
+	if err != nil {
+		return nil, err
+	}
+	bundles, err := listBackupBundles(store, machine)
+	if err != nil {
+		return nil, err
+	}
+	if len(bundles) == 0 {
+		return nil, NewNotFoundError("no backups of %s at %s", machine, store)
+	}
+
+	dir, err := os.MkdirTemp("", "timemachine-restore-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	prefix := restoredRefPrefix + machine + "/"
+	defer g.deleteRefs(prefix)
+	for _, bundle := range bundles {
+		file := filepath.Join(dir, path.Base(bundle.Key))
+		if err := store.Get(bundle.Key, file); err != nil {
+			return nil, err
+		}
+		// Later bundles hold later tips, so they overwrite earlier ones
+		if _, err := g.RunCommand("fetch", "--quiet", "--no-tags", file, "+refs/heads/*:"+prefix+"*"); err != nil {
+			return nil, fmt.Errorf("failed to read backup %s: %w", bundle.Key, err)
+		}
+		os.Remove(file)
+	}
+
+	restored, err := g.RunCommand("for-each-ref", "--format=%(refname) %(objectname)", prefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read restored snapshots: %w", err)
+	}
+	current, _ := g.RunCommand("symbolic-ref", "--short", "HEAD")
+
+	var results []RestoredBranch
+	for _, line := range strings.Split(restored, "\n") {
+		ref, tip, ok := strings.Cut(line, " ")
+		if !ok {
+			continue
+		}
+		branch := strings.TrimPrefix(ref, prefix)
+		result, err := g.adoptBackupBranch(branch, tip, branch == current, force)
+		if err != nil {
+			return results, fmt.Errorf("failed to restore branch %s: %w", branch, err)
+		}
+		results = append(results, *result)
+	}
+	return results, nil
+}
+
+// adoptBackupBranch points branch at tip, a snapshot restored from a backup
+func (g *GitManager) adoptBackupBranch(branch, tip string, current, force bool) (*RestoredBranch, error) {
+	result := &RestoredBranch{Branch: branch}
+	if current {
+		_, headErr := g.RunCommand("rev-parse", "--verify", "--quiet", "HEAD")
+		imported, err := g.adoptSnapshots(tip, force)
+		if errors.Is(err, ErrHistoryDiverged) {
+			result.Status = BackupBranchDiverged
+			return result, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		result.Snapshots, result.BackupRef = imported.Snapshots, imported.BackupRef
+		switch {
+		case imported.BackupRef != "":
+			result.Status = BackupBranchReplaced
+		case headErr != nil:
+			result.Status = BackupBranchCreated
+		case imported.Snapshots == 0:
+			result.Status = BackupBranchUpToDate
+		default:
+			result.Status = BackupBranchUpdated
+		}
+		return result, nil
+	}
+
+	ref := "refs/heads/" + branch
+	local, err := g.RunCommand("rev-parse", "--verify", "--quiet", ref)
+	switch {
+	case err != nil:
+		result.Status = BackupBranchCreated
+		result.Snapshots = g.countCommits(tip)
+	case g.isAncestor(tip, local):
+		result.Status = BackupBranchUpToDate
+		return result, nil
+	case g.isAncestor(local, tip):
+		result.Status = BackupBranchUpdated
+		result.Snapshots = g.countCommits(tip, "^"+local)
+	case !force:
+		result.Status = BackupBranchDiverged
+		return result, nil
+	default:
+		result.Status = BackupBranchReplaced
+		result.BackupRef = backupRefPrefix + strconv.FormatInt(time.Now().Unix(), 10) + "-" + strings.ReplaceAll(branch, "/", "-")
+		if _, err := g.RunCommand("update-ref", result.BackupRef, local); err != nil {
+			return nil, fmt.Errorf("failed to back up local snapshots: %w", err)
+		}
+		result.Snapshots = g.countCommits(tip, "^"+local)
+	}
+	if _, err := g.RunCommand("update-ref", "-m", "timemachine restore-from-backup", ref, tip); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// deleteRefs removes every ref under prefix
+func (g *GitManager) deleteRefs(prefix string) {
+	refs, err := g.RunCommand("for-each-ref", "--format=%(refname)", prefix)
+	if err != nil {
+		return
+	}
+	for _, ref := range strings.Fields(refs) {
+		g.RunCommand("update-ref", "-d", ref)
+	}
+}
+
+// backupLoop uploads new snapshots to backup.url every interval. Failures,
+// such as expired credentials, are reported and retried next time.
+func (w *Watcher) backupLoop(interval time.Duration) {
+	defer w.wg.Done()
+
+	for {
+		select {
+		case <-w.stopChan:
+			return
+		case <-time.After(interval):
+		}
+		w.uploadBackup()
+	}
+}
+
+// uploadBackup runs one scheduled upload and reports what it sent
+func (w *Watcher) uploadBackup() {
+	timestamp := time.Now().Format("2006-01-02 15:04:05")
+	upload, err := w.gitManager.UploadBackup()
+	if err != nil {
+		color.Yellow("⚠️  %s Backup to %s failed: %v", timestamp, w.gitManager.backupURL(), err)
+		w.emitError("backup", err)
+		return
+	}
+	if upload.Key != "" {
+		fmt.Printf("☁️  %s Backed up %d snapshot(s) to %s\n", timestamp, upload.Snapshots, upload.Store)
+	}
+}
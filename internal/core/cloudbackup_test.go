@@ -0,0 +1,135 @@
+package core
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/config"
+)
+
+func TestUploadAndRestoreBackup(t *testing.T) {
+	tempDir, state, gitManager := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+	storeDir := t.TempDir()
+	state.Config = &config.Config{
+		Backup: config.BackupConfig{URL: "file://" + storeDir},
+		Remote: config.RemoteConfig{Machine: "laptop"},
+	}
+
+	upload := func(wantSnapshots int) {
+		t.Helper()
+		result, err := gitManager.UploadBackup()
+		if err != nil {
+			t.Fatalf("UploadBackup failed: %v", err)
+		}
+		if result.Snapshots != wantSnapshots || (wantSnapshots == 0) != (result.Key == "") {
+			t.Fatalf("Expected %d snapshots uploaded, got %+v", wantSnapshots, result)
+		}
+	}
+
+	snapshotFile(t, tempDir, gitManager, "v1")
+	upload(1)
+	upload(0)
+	snapshotFile(t, tempDir, gitManager, "v2")
+	snapshotFile(t, tempDir, gitManager, "v3")
+	upload(2)
+
+	bundles, err := gitManager.ListBackupBundles("")
+	if err != nil {
+		t.Fatalf("ListBackupBundles failed: %v", err)
+	}
+	if len(bundles) != 2 || bundles[0].Sequence != 1 || bundles[1].Sequence != 2 || bundles[1].Machine != "laptop" {
+		t.Fatalf("Unexpected bundles %+v", bundles)
+	}
+
+	// A new machine rebuilds the history from the bundles
+	otherDir, otherState, other := setupTestRepo(t)
+	defer os.RemoveAll(otherDir)
+	otherState.Config = &config.Config{Backup: state.Config.Backup}
+	if _, err := other.RestoreFromBackup("desktop", false); ExitCode(err) != ExitNotFound {
+		t.Errorf("Expected restoring a machine without backups to be not found, got %v", err)
+	}
+	results, err := other.RestoreFromBackup("laptop", false)
+	if err != nil {
+		t.Fatalf("RestoreFromBackup failed: %v", err)
+	}
+	if len(results) != 1 || results[0].Status != BackupBranchCreated || results[0].Snapshots != 3 {
+		t.Fatalf("Unexpected restore %+v", results)
+	}
+	head, _ := gitManager.RunCommand("rev-parse", "HEAD")
+	if restored, _ := other.RunCommand("rev-parse", "HEAD"); restored != head {
+		t.Errorf("Expected restored HEAD %s, got %s", head, restored)
+	}
+	if refs, _ := other.RunCommand("for-each-ref", restoredRefPrefix); refs != "" {
+		t.Errorf("Expected temporary refs to be removed, got %s", refs)
+	}
+
+	// Diverged history is left alone unless forced
+	snapshotFile(t, otherDir, other, "local")
+	snapshotFile(t, tempDir, gitManager, "v4")
+	upload(1)
+	if results, _ = other.RestoreFromBackup("laptop", false); len(results) != 1 || results[0].Status != BackupBranchDiverged {
+		t.Fatalf("Expected a diverged branch, got %+v", results)
+	}
+	if results, err = other.RestoreFromBackup("laptop", true); err != nil || len(results) != 1 || results[0].Status != BackupBranchReplaced {
+		t.Fatalf("Expected a replaced branch, got %+v, %v", results, err)
+	}
+	if results[0].BackupRef == "" || !strings.HasPrefix(results[0].BackupRef, backupRefPrefix) {
+		t.Errorf("Expected the local history kept, got %q", results[0].BackupRef)
+	}
+}
+
+func TestUploadBackupAfterRewrite(t *testing.T) {
+	tempDir, state, gitManager := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+	state.Config = &config.Config{Backup: config.BackupConfig{URL: t.TempDir()}}
+
+	snapshotFile(t, tempDir, gitManager, "v1")
+	if _, err := gitManager.UploadBackup(); err != nil {
+		t.Fatalf("UploadBackup failed: %v", err)
+	}
+	// Compaction rewrote the branch: the new history goes up whole
+	gitManager.RunCommand("commit", "--quiet", "--amend", "-m", "rewritten")
+	gitManager.RunCommand("reflog", "expire", "--expire=now", "--all")
+	gitManager.RunCommand("gc", "--quiet", "--prune=now")
+	result, err := gitManager.UploadBackup()
+	if err != nil {
+		t.Fatalf("UploadBackup failed: %v", err)
+	}
+	if result.Snapshots != gitManager.countCommits("--branches") {
+		t.Errorf("Expected a full bundle after a rewrite, got %d snapshots", result.Snapshots)
+	}
+}
+
+func TestCLIStoreList(t *testing.T) {
+	original := runStorageCLI
+	defer func() { runStorageCLI = original }()
+
+	var ran []string
+	runStorageCLI = func(name string, args ...string) ([]byte, error) {
+		ran = append([]string{name}, args...)
+		if name == "aws" {
+			return []byte("2024-01-15 10:30:00      12345 team/tm/laptop/000001-20240115T103000Z.bundle\n"), nil
+		}
+		return []byte("gs://bucket/team/tm/laptop/000001-20240115T103000Z.bundle\ngs://bucket/team/tm/laptop/\n"), nil
+	}
+
+	for _, url := range []string{"s3://bucket/team/tm/", "gs://bucket/team/tm"} {
+		store, err := OpenObjectStore(url)
+		if err != nil {
+			t.Fatalf("OpenObjectStore(%s) failed: %v", url, err)
+		}
+		keys, err := store.List("laptop")
+		if err != nil {
+			t.Fatalf("List failed: %v", err)
+		}
+		if len(keys) != 1 || keys[0] != "laptop/000001-20240115T103000Z.bundle" {
+			t.Errorf("%s: unexpected keys %v (ran %v)", url, keys, ran)
+		}
+	}
+
+	if _, err := OpenObjectStore("ftp://host/dir"); ExitCode(err) != ExitValidation {
+		t.Errorf("Expected an unsupported scheme to fail, got %v", err)
+	}
+}
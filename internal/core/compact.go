@@ -0,0 +1,206 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// compactTrailer records in an aggregate snapshot's message how many
+// snapshots it replaced, so compacting it again keeps an accurate count
+const compactTrailer = "Compacted-Snapshots: "
+
+// CompactGroup is a run of consecutive old snapshots taken within one
+// interval. Compaction replaces it with a single aggregate snapshot: the
+// newest one's files and message.
+type CompactGroup struct {
+	Start     time.Time  // Start of the interval
+	Snapshots []Snapshot // Newest first
+	Count     int        // Snapshots they stand for, counting earlier compactions
+}
+
+// CompactPlan describes a compaction of the current shadow branch
+type CompactPlan struct {
+	OlderThan time.Duration
+	Interval  time.Duration
+	Groups    []CompactGroup // Only runs of two or more snapshots
+	Total     int            // Snapshots before compaction
+}
+
+// Squashed returns how many snapshots the compaction removes
+func (p *CompactPlan) Squashed() int {
+	squashed := 0
+	for _, group := range p.Groups {
+		squashed += len(group.Snapshots) - 1
+	}
+	return squashed
+}
+
+// PlanCompaction groups the snapshots taken before now-olderThan into runs
+// within the same interval (aligned to local time, so 24h means calendar
+// days). Newer snapshots keep their full granularity.
+func (g *GitManager) PlanCompaction(olderThan, interval time.Duration, now time.Time) (*CompactPlan, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("the compaction interval must be positive")
+	}
+	snapshots, err := g.ListSnapshots(0, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+	counts, err := g.compactedCounts()
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &CompactPlan{OlderThan: olderThan, Interval: interval, Total: len(snapshots)}
+	cutoff := now.Add(-olderThan)
+	var group *CompactGroup
+	for _, snapshot := range snapshots {
+		if !snapshot.Timestamp.Before(cutoff) {
+			continue
+		}
+		start := intervalStart(snapshot.Timestamp, interval)
+		if group == nil || !group.Start.Equal(start) {
+			if group != nil && len(group.Snapshots) > 1 {
+				plan.Groups = append(plan.Groups, *group)
+			}
+			group = &CompactGroup{Start: start}
+		}
+		group.Snapshots = append(group.Snapshots, snapshot)
+		group.Count += max(counts[snapshot.Hash], 1)
+	}
+	if group != nil && len(group.Snapshots) > 1 {
+		plan.Groups = append(plan.Groups, *group)
+	}
+
+	return plan, nil
+}
+
+// intervalStart returns the start of the interval containing t, with
+// intervals aligned to local midnight rather than UTC
+func intervalStart(t time.Time, interval time.Duration) time.Time {
+	t = t.Local()
+	_, offset := t.Zone()
+	shift := time.Duration(offset) * time.Second
+	return t.Add(shift).Truncate(interval).Add(-shift)
+}
+
+// compactedCounts returns, by hash, how many snapshots each aggregate of
+// an earlier compaction replaced
+func (g *GitManager) compactedCounts() (map[string]int, error) {
+	// Records end with \x1e; the body follows \x1f
+	output, err := g.RunCommand("log", "--first-parent", "--format=%H%x1f%b%x1e", "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot messages: %w", err)
+	}
+	counts := make(map[string]int)
+	for _, record := range strings.Split(output, "\x1e") {
+		hash, body, _ := strings.Cut(strings.TrimSpace(record), "\x1f")
+		for _, line := range strings.Split(body, "\n") {
+			if value, ok := strings.CutPrefix(line, compactTrailer); ok {
+				if count, err := strconv.Atoi(strings.TrimSpace(value)); err == nil {
+					counts[hash] = count
+				}
+			}
+		}
+	}
+	return counts, nil
+}
+
+// Compact applies a plan: each group's older snapshots are dropped and its
+// newest becomes the aggregate, recording in its message how many snapshots
+// it replaced. Later snapshots get new hashes; the returned map translates
+// old hashes to new ones. Snapshots taken after planning are kept.
+func (g *GitManager) Compact(plan *CompactPlan) (map[string]string, error) {
+	if len(plan.Groups) == 0 {
+		return map[string]string{}, nil
+	}
+
+	branch, err := g.RunCommand("symbolic-ref", "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine shadow branch: %w", err)
+	}
+	oldHead, err := g.RunCommand("rev-parse", "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+	output, err := g.RunCommand("rev-list", "--reverse", "--first-parent", oldHead)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+	history := strings.Fields(output)
+	recorded, err := g.recordedProjectCommits()
+	if err != nil {
+		return nil, err
+	}
+
+	dropped := make(map[string]bool)
+	aggregates := make(map[string]int)
+	// Aggregates keep the project commits their group recorded, oldest first
+	projectCommits := make(map[string][]string)
+	for _, group := range plan.Groups {
+		aggregate := group.Snapshots[0].Hash
+		aggregates[aggregate] = group.Count
+		for i := len(group.Snapshots) - 1; i >= 0; i-- {
+			projectCommits[aggregate] = append(projectCommits[aggregate], recorded[group.Snapshots[i].Hash]...)
+		}
+		for _, snapshot := range group.Snapshots[1:] {
+			dropped[snapshot.Hash] = true
+		}
+	}
+
+	// Snapshots before the first compacted one keep their hashes
+	parent := ""
+	i := 0
+	for ; i < len(history) && !dropped[history[i]]; i++ {
+		parent = history[i]
+	}
+	if i == len(history) {
+		return map[string]string{}, nil
+	}
+
+	mapping := make(map[string]string)
+	for _, commit := range history[i:] {
+		if dropped[commit] {
+			continue
+		}
+		tree, err := g.RunCommand("rev-parse", commit+"^{tree}")
+		if err != nil {
+			return nil, fmt.Errorf("failed to read tree of %s: %w", commit, err)
+		}
+		var newCommit string
+		if count, ok := aggregates[commit]; ok {
+			subject, err := g.RunCommand("log", "-1", "--format=%s", commit)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read message of %s: %w", commit, err)
+			}
+			message := fmt.Sprintf("%s\n\n%s%d", subject, compactTrailer, count)
+			for _, projectCommit := range projectCommits[commit] {
+				message += "\n" + projectCommitTrailer + projectCommit
+			}
+			newCommit, err = g.recreateCommitMessage(commit, tree, parent, message)
+			if err != nil {
+				return nil, err
+			}
+		} else if newCommit, err = g.recreateCommit(commit, tree, parent); err != nil {
+			return nil, err
+		}
+		mapping[commit] = newCommit
+		parent = newCommit
+	}
+
+	if err := g.carryNotes(mapping); err != nil {
+		return nil, err
+	}
+
+	// Compare-and-swap so a snapshot created concurrently is never lost
+	if _, err := g.RunCommand("update-ref", "-m", "timemachine: compact snapshots", branch, parent, oldHead); err != nil {
+		return nil, fmt.Errorf("failed to update shadow branch (was a snapshot created during compaction?): %w", err)
+	}
+
+	if err := g.reclaimSpace(); err != nil {
+		return mapping, err
+	}
+	return mapping, nil
+}
@@ -0,0 +1,81 @@
+package core
+
+import (
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCompact(t *testing.T) {
+	tempDir, _, gitManager := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	now := time.Now()
+	day := intervalStart(now.Add(-10*24*time.Hour), 24*time.Hour).Add(time.Hour)
+	for _, snapshot := range []struct {
+		content string
+		at      time.Time
+	}{
+		{"a1", day.Add(5 * time.Minute)},
+		{"a2", day.Add(10 * time.Minute)},
+		{"a3", day.Add(20 * time.Minute)},
+		{"b1", day.Add(70 * time.Minute)},
+		{"c1", day.Add(121 * time.Minute)},
+		{"c2", day.Add(122 * time.Minute)},
+		{"recent1", now.Add(-time.Hour)},
+		{"recent2", now.Add(-50 * time.Minute)},
+	} {
+		snapshotFileAt(t, tempDir, gitManager, snapshot.content, snapshot.at)
+	}
+
+	plan, err := gitManager.PlanCompaction(7*24*time.Hour, time.Hour, now)
+	if err != nil {
+		t.Fatalf("PlanCompaction failed: %v", err)
+	}
+	if len(plan.Groups) != 2 || plan.Groups[0].Count != 2 || plan.Groups[1].Count != 3 || plan.Squashed() != 3 {
+		t.Fatalf("Expected groups of 2 and 3 snapshots, got %+v", plan.Groups)
+	}
+	if _, err := gitManager.Compact(plan); err != nil {
+		t.Fatalf("Compact failed: %v", err)
+	}
+	assertMessages(t, gitManager, []string{"recent2", "recent1", "c2", "b1", "a3"})
+	if content, err := gitManager.RunCommand("show", "HEAD~4:file.txt"); err != nil || content != "a3" {
+		t.Errorf("Expected the aggregate to keep the newest files, got %q (%v)", content, err)
+	}
+
+	// Compacting by day again sums up what each aggregate replaced
+	plan, err = gitManager.PlanCompaction(7*24*time.Hour, 24*time.Hour, now)
+	if err != nil {
+		t.Fatalf("PlanCompaction failed: %v", err)
+	}
+	if len(plan.Groups) != 1 || plan.Groups[0].Count != 6 {
+		t.Fatalf("Expected one group standing for 6 snapshots, got %+v", plan.Groups)
+	}
+	if _, err := gitManager.Compact(plan); err != nil {
+		t.Fatalf("Compact failed: %v", err)
+	}
+	assertMessages(t, gitManager, []string{"recent2", "recent1", "c2"})
+	if body, _ := gitManager.RunCommand("log", "-1", "--format=%b", "HEAD~2"); body != compactTrailer+"6" {
+		t.Errorf("Expected the aggregate to record 6 snapshots, got %q", body)
+	}
+
+	if plan, err := gitManager.PlanCompaction(7*24*time.Hour, 24*time.Hour, now); err != nil || len(plan.Groups) != 0 {
+		t.Errorf("Expected nothing left to compact, got %+v (%v)", plan, err)
+	}
+}
+
+func assertMessages(t *testing.T, gitManager *GitManager, expected []string) {
+	t.Helper()
+	snapshots, err := gitManager.ListSnapshots(0, "")
+	if err != nil {
+		t.Fatalf("ListSnapshots failed: %v", err)
+	}
+	var messages []string
+	for _, snapshot := range snapshots {
+		messages = append(messages, snapshot.Message)
+	}
+	if !reflect.DeepEqual(messages, expected) {
+		t.Errorf("Expected snapshots %v, got %v", expected, messages)
+	}
+}
@@ -0,0 +1,157 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// WorktreeChange describes how restoring a snapshot would affect one file
+type WorktreeChange struct {
+	Status string // "M" (modified), "A" (recreated) or "D" (deleted) on restore
+	Path   string
+}
+
+// Describe returns a human readable description of the restore action
+func (c WorktreeChange) Describe() string {
+	switch c.Status {
+	case "A":
+		return "recreate"
+	case "D":
+		return "delete"
+	default:
+		return "modify"
+	}
+}
+
+// CompareWorktree lists the files that would change if the snapshot were
+// restored over the current working tree, optionally limited to pathspecs.
+// Returned paths are relative to the project root.
+func (g *GitManager) CompareWorktree(hash string, pathspecs ...string) ([]WorktreeChange, error) {
+	args := append([]string{"diff", "-R", "--no-renames", "--name-status", "-z", hash, "--"}, pathspecs...)
+	output, err := g.RunCommand(args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare snapshot with working tree: %w", err)
+	}
+
+	return parseNameStatusZ(output), nil
+}
+
+// WorktreeDiff returns the patch that restoring the snapshot would apply,
+// optionally limited to a single path relative to the project root
+func (g *GitManager) WorktreeDiff(hash, path string) (string, error) {
+	args := []string{"diff", "-R", "--no-renames", hash, "--"}
+	if path != "" {
+		args = append(args, g.icasePathspecs(topPathspecs([]string{path}))...)
+	}
+
+	output, err := g.RunCommand(args...)
+	if err != nil {
+		return "", fmt.Errorf("failed to diff snapshot against working tree: %w", err)
+	}
+
+	return output, nil
+}
+
+// parseNameStatusZ parses `git diff --name-status -z` output
+func parseNameStatusZ(output string) []WorktreeChange {
+	fields := strings.Split(strings.TrimRight(output, "\x00"), "\x00")
+
+	var changes []WorktreeChange
+	for i := 0; i+1 < len(fields); i += 2 {
+		status := strings.TrimSpace(fields[i])
+		if status == "" {
+			continue
+		}
+		changes = append(changes, WorktreeChange{Status: status[:1], Path: fields[i+1]})
+	}
+
+	return changes
+}
+
+// ProjectChange is a file with uncommitted work in the project repository
+type ProjectChange struct {
+	Path     string // Relative to the project root
+	Staged   bool   // Changed in the index
+	Unstaged bool   // Changed in the working tree since staging
+}
+
+// Describe returns a human readable description of the uncommitted work
+func (c ProjectChange) Describe() string {
+	switch {
+	case c.Staged && c.Unstaged:
+		return "staged and unstaged changes"
+	case c.Staged:
+		return "staged changes"
+	default:
+		return "unstaged changes"
+	}
+}
+
+// RestoreConflicts returns the files that restoring the snapshot, limited to
+// pathspecs, would change although they have staged or unstaged changes in
+// the project repository. Untracked files are not reported.
+func (g *GitManager) RestoreConflicts(hash string, pathspecs []string) ([]ProjectChange, error) {
+	pathspecs = g.icasePathspecs(pathspecs)
+	changes, err := g.CompareWorktree(hash, pathspecs...)
+	if err != nil || len(changes) == 0 {
+		return nil, err
+	}
+
+	args := append([]string{"status", "--porcelain", "-z", "--untracked-files=no", "--no-renames", "--"}, pathspecs...)
+	output, err := g.runProjectCommand(args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read project repository status: %w", err)
+	}
+
+	fold := g.State.CaseInsensitive()
+	restored := make(map[string]bool, len(changes))
+	for _, change := range changes {
+		restored[pathKey(change.Path, fold)] = true
+	}
+
+	var conflicts []ProjectChange
+	for _, record := range strings.Split(output, "\x00") {
+		// "XY path": X is the index status, Y the working tree status
+		if len(record) < 4 || !restored[pathKey(record[3:], fold)] {
+			continue
+		}
+		conflicts = append(conflicts, ProjectChange{
+			Path:     record[3:],
+			Staged:   record[0] != ' ',
+			Unstaged: record[1] != ' ',
+		})
+	}
+	return conflicts, nil
+}
+
+// StashProjectChanges moves the uncommitted work on the given files into a
+// stash of the project repository, so restoring over them loses nothing
+func (g *GitManager) StashProjectChanges(conflicts []ProjectChange, message string) error {
+	if len(conflicts) == 0 {
+		return nil
+	}
+	args := []string{"stash", "push", "--quiet", "--message", message, "--"}
+	for _, conflict := range conflicts {
+		args = append(args, ":(top,literal)"+conflict.Path)
+	}
+	if _, err := g.runProjectCommand(args...); err != nil {
+		return fmt.Errorf("failed to stash uncommitted changes: %w", err)
+	}
+	return nil
+}
+
+// runProjectCommand runs git in the project repository itself, not the
+// shadow repository
+func (g *GitManager) runProjectCommand(args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"--git-dir=" + g.State.GitDir, "--work-tree=" + g.State.ProjectRoot}, args...)...)
+	cmd.Dir = g.State.ProjectRoot
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return "", &GitError{Args: args, Output: stderr.String(), Err: err}
+	}
+	return strings.TrimRight(string(output), "\n"), nil
+}
@@ -0,0 +1,128 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCompareWorktree(t *testing.T) {
+	tempDir, _, gitManager := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	writeFile := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(tempDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	writeFile("keep.txt", "original\n")
+	writeFile("edited.txt", "original\n")
+	writeFile("removed.txt", "original\n")
+	if err := gitManager.CreateSnapshot("baseline"); err != nil {
+		t.Fatalf("Failed to create snapshot: %v", err)
+	}
+	baseline, err := gitManager.RunCommand("rev-parse", "HEAD")
+	if err != nil {
+		t.Fatalf("Failed to resolve HEAD: %v", err)
+	}
+
+	writeFile("edited.txt", "changed\n")
+	writeFile("added.txt", "new\n")
+	if err := os.Remove(filepath.Join(tempDir, "removed.txt")); err != nil {
+		t.Fatalf("Failed to remove file: %v", err)
+	}
+	if err := gitManager.CreateSnapshot("later"); err != nil {
+		t.Fatalf("Failed to create snapshot: %v", err)
+	}
+
+	changes, err := gitManager.CompareWorktree(baseline)
+	if err != nil {
+		t.Fatalf("CompareWorktree failed: %v", err)
+	}
+
+	expected := []WorktreeChange{
+		{Status: "D", Path: "added.txt"},
+		{Status: "M", Path: "edited.txt"},
+		{Status: "A", Path: "removed.txt"},
+	}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Errorf("Expected %v, got %v", expected, changes)
+	}
+
+	diff, err := gitManager.WorktreeDiff(baseline, "edited.txt")
+	if err != nil {
+		t.Fatalf("WorktreeDiff failed: %v", err)
+	}
+	if !strings.Contains(diff, "-changed") || !strings.Contains(diff, "+original") {
+		t.Errorf("Expected diff to restore original content, got:\n%s", diff)
+	}
+}
+
+func TestParseNameStatusZ(t *testing.T) {
+	output := "M\x00src/app.go\x00A\x00dir with space/file.txt\x00"
+	expected := []WorktreeChange{
+		{Status: "M", Path: "src/app.go"},
+		{Status: "A", Path: "dir with space/file.txt"},
+	}
+
+	if changes := parseNameStatusZ(output); !reflect.DeepEqual(changes, expected) {
+		t.Errorf("Expected %v, got %v", expected, changes)
+	}
+	if changes := parseNameStatusZ(""); len(changes) != 0 {
+		t.Errorf("Expected no changes for empty output, got %v", changes)
+	}
+}
+
+func TestRestoreConflicts(t *testing.T) {
+	tempDir, _, gitManager := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	for _, name := range []string{"unstaged.txt", "staged.txt", "clean.txt", "untouched.txt"} {
+		if err := os.WriteFile(filepath.Join(tempDir, name), []byte("committed"), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	projectGit(t, tempDir, "add", ".")
+	projectGit(t, tempDir, "commit", "-q", "-m", "initial")
+	if err := gitManager.CreateSnapshot("baseline"); err != nil {
+		t.Fatalf("CreateSnapshot failed: %v", err)
+	}
+	baseline, _ := gitManager.RunCommand("rev-parse", "HEAD")
+
+	os.WriteFile(filepath.Join(tempDir, "unstaged.txt"), []byte("work"), 0644)
+	os.WriteFile(filepath.Join(tempDir, "staged.txt"), []byte("work"), 0644)
+	projectGit(t, tempDir, "add", "staged.txt")
+	// Committed after the snapshot: restoring changes it, but nothing is uncommitted
+	os.WriteFile(filepath.Join(tempDir, "clean.txt"), []byte("newer"), 0644)
+	projectGit(t, tempDir, "commit", "-q", "-m", "newer", "clean.txt")
+
+	conflicts, err := gitManager.RestoreConflicts(baseline, nil)
+	if err != nil {
+		t.Fatalf("RestoreConflicts failed: %v", err)
+	}
+	expected := []ProjectChange{
+		{Path: "staged.txt", Staged: true},
+		{Path: "unstaged.txt", Unstaged: true},
+	}
+	if !reflect.DeepEqual(conflicts, expected) {
+		t.Errorf("Expected %v, got %v", expected, conflicts)
+	}
+
+	conflicts, err = gitManager.RestoreConflicts(baseline, []string{":(top,literal)clean.txt"})
+	if err != nil || len(conflicts) != 0 {
+		t.Errorf("Expected no conflicts restoring clean.txt, got %v (%v)", conflicts, err)
+	}
+
+	if err := gitManager.StashProjectChanges(expected, "test stash"); err != nil {
+		t.Fatalf("StashProjectChanges failed: %v", err)
+	}
+	if status := projectGit(t, tempDir, "status", "--porcelain", "--untracked-files=no"); status != "" {
+		t.Errorf("Expected a clean project after stashing, got %q", status)
+	}
+	if stash := projectGit(t, tempDir, "stash", "list"); !strings.Contains(stash, "test stash") {
+		t.Errorf("Expected the stash to be listed, got %q", stash)
+	}
+}
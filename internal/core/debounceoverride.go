@@ -0,0 +1,40 @@
+package core
+
+import (
+	"time"
+
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/config"
+)
+
+// debounceOverride is a watcher.debounce_overrides entry, its pattern parsed
+// like a line of a .gitignore at the project root
+type debounceOverride struct {
+	pattern gitignorePattern
+	delay   time.Duration
+}
+
+// parseDebounceOverrides parses watcher.debounce_overrides, skipping
+// entries without a pattern
+func parseDebounceOverrides(overrides []config.DebounceOverride) []debounceOverride {
+	var parsed []debounceOverride
+	for _, override := range overrides {
+		if pattern, ok := parseGitignoreLine(override.Pattern, ""); ok && !pattern.negation {
+			parsed = append(parsed, debounceOverride{pattern: pattern, delay: override.Delay})
+		}
+	}
+	return parsed
+}
+
+// debounceDelayFor returns the debounce delay of a project-root-relative
+// path: that of the longest pattern matching it or a directory containing
+// it, 0 when none does
+func debounceDelayFor(overrides []debounceOverride, rel string) time.Duration {
+	delay, longest := time.Duration(0), -1
+	for _, override := range overrides {
+		pattern := override.pattern
+		if len(pattern.pattern) > longest && (pattern.matches(rel, false) || pattern.matchesParent(rel)) {
+			delay, longest = override.delay, len(pattern.pattern)
+		}
+	}
+	return delay
+}
@@ -8,9 +8,10 @@ import (
 // Debouncer groups rapid events together to prevent spam
 // Critical for preventing hundreds of snapshots during npm install, etc.
 type Debouncer struct {
-	delay time.Duration
-	timer *time.Timer
-	mu    sync.Mutex
+	delay    time.Duration
+	timer    *time.Timer
+	deadline time.Time // When the pending execution runs
+	mu       sync.Mutex
 }
 
 // NewDebouncer creates a new debouncer with the specified delay
@@ -24,16 +25,42 @@ func NewDebouncer(delay time.Duration) *Debouncer {
 // If called again before the delay expires, the previous call is cancelled
 // This ensures rapid changes create only ONE snapshot
 func (d *Debouncer) Trigger(fn func()) {
+	d.mu.Lock()
+	delay := d.delay
+	d.mu.Unlock()
+	d.schedule(delay, true, fn)
+}
+
+// TriggerAfter is Trigger with a delay of its own. A pending execution due
+// later isn't brought forward, so changes that need a longer quiet period
+// (watcher.debounce_overrides) aren't cut short by quicker ones.
+func (d *Debouncer) TriggerAfter(delay time.Duration, fn func()) {
+	d.schedule(delay, true, fn)
+}
+
+// Flush runs fn right away instead of after the delay, replacing any pending
+// execution. Used when a batch is complete before things quiet down.
+func (d *Debouncer) Flush(fn func()) {
+	d.schedule(0, false, fn)
+}
+
+// schedule runs fn after delay, replacing any pending execution; with
+// keepLater, a pending execution due later keeps its time
+func (d *Debouncer) schedule(delay time.Duration, keepLater bool, fn func()) {
 	d.mu.Lock()
 	defer d.mu.Unlock()
 
 	// Cancel existing timer if any
 	if d.timer != nil {
+		if left := time.Until(d.deadline); keepLater && left > delay {
+			delay = left
+		}
 		d.timer.Stop()
 	}
+	d.deadline = time.Now().Add(delay)
 
 	// Create new timer with delay
-	d.timer = time.AfterFunc(d.delay, func() {
+	d.timer = time.AfterFunc(delay, func() {
 		fn()
 		// Clear timer after execution
 		d.mu.Lock()
@@ -59,4 +86,11 @@ func (d *Debouncer) IsActive() bool {
 	defer d.mu.Unlock()
 
 	return d.timer != nil
-}
\ No newline at end of file
+}
+// SetDelay changes the delay for executions scheduled from now on
+func (d *Debouncer) SetDelay(delay time.Duration) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.delay = delay
+}
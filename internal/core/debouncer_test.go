@@ -4,6 +4,8 @@ import (
 	"sync/atomic"
 	"testing"
 	"time"
+
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/config"
 )
 
 func TestDebouncer_SingleTrigger(t *testing.T) {
@@ -189,4 +191,72 @@ func TestDebouncer_DifferentDelays(t *testing.T) {
 				test.delay, test.expected, executed)
 		}
 	}
-}
\ No newline at end of file
+}
+func TestDebouncer_Flush(t *testing.T) {
+	debouncer := NewDebouncer(time.Hour)
+	var executed int64
+
+	debouncer.Trigger(func() {
+		atomic.AddInt64(&executed, 1)
+	})
+	// Flushing replaces the pending call and runs without waiting
+	debouncer.Flush(func() {
+		atomic.AddInt64(&executed, 10)
+	})
+
+	time.Sleep(50 * time.Millisecond)
+
+	if atomic.LoadInt64(&executed) != 10 {
+		t.Errorf("Expected only the flushed function to run, got %d", executed)
+	}
+	if debouncer.IsActive() {
+		t.Error("Expected no pending execution after flush")
+	}
+}
+
+func TestDebouncer_TriggerAfter(t *testing.T) {
+	debouncer := NewDebouncer(20 * time.Millisecond)
+	var executed int64
+
+	// A slow path's longer delay isn't cut short by a quick one's change
+	debouncer.TriggerAfter(150*time.Millisecond, func() {
+		atomic.AddInt64(&executed, 1)
+	})
+	debouncer.Trigger(func() {
+		atomic.AddInt64(&executed, 1)
+	})
+
+	time.Sleep(80 * time.Millisecond)
+	if atomic.LoadInt64(&executed) != 0 || !debouncer.IsActive() {
+		t.Fatalf("Expected the execution to wait for the longer delay, got %d", executed)
+	}
+	time.Sleep(150 * time.Millisecond)
+	if atomic.LoadInt64(&executed) != 1 {
+		t.Errorf("Expected 1 execution, got %d", executed)
+	}
+}
+
+func TestDebounceDelayFor(t *testing.T) {
+	overrides := parseDebounceOverrides([]config.DebounceOverride{
+		{Pattern: "data/**", Delay: 30 * time.Second},
+		{Pattern: "data/live/**", Delay: time.Second},
+		{Pattern: "*.csv", Delay: time.Minute},
+		{Pattern: "logs/", Delay: 10 * time.Second},
+		{Pattern: "", Delay: time.Hour},
+	})
+
+	tests := map[string]time.Duration{
+		"src/main.go":         0,
+		"data/raw/input.bin":  30 * time.Second,
+		"data/live/feed.json": time.Second,
+		"data/live/feed.csv":  time.Second, // "data/live/**" is longer than "*.csv"
+		"report.csv":          time.Minute,
+		"logs/app/server.log": 10 * time.Second,
+		"src/data/readme.txt": 0,
+	}
+	for rel, expected := range tests {
+		if got := debounceDelayFor(overrides, rel); got != expected {
+			t.Errorf("debounceDelayFor(%q) = %s, want %s", rel, got, expected)
+		}
+	}
+}
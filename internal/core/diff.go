@@ -0,0 +1,85 @@
+package core
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// DiffOptions controls DiffSnapshots output
+type DiffOptions struct {
+	Stat      bool     // Diffstat instead of a patch
+	Pathspecs []string // Optional pathspecs limiting the diff
+}
+
+// DiffSnapshots returns the diff between two snapshots. An empty to compares
+// from against the current working tree, including files created since the
+// last snapshot.
+func (g *GitManager) DiffSnapshots(from, to string, opts DiffOptions) (string, error) {
+	args := []string{"diff", "--find-renames"}
+	if opts.Stat {
+		args = append(args, "--stat")
+	}
+	args = append(args, from)
+	if to != "" {
+		args = append(args, to)
+	}
+	args = append(args, "--")
+	args = append(args, opts.Pathspecs...)
+
+	if to != "" {
+		output, err := g.RunCommand(args...)
+		if err != nil {
+			return "", fmt.Errorf("failed to diff snapshots: %w", err)
+		}
+		return output, nil
+	}
+
+	var output string
+	err := g.withWorktreeIndex(func(env []string) error {
+		var err error
+		output, err = g.runCommandEnv(env, args...)
+		return err
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to diff snapshot against working tree: %w", err)
+	}
+
+	return output, nil
+}
+
+// withWorktreeIndex stages the whole working tree into a throwaway index so
+// untracked files take part in comparisons, leaving the shadow index (and
+// therefore the next snapshot) untouched. fn receives the environment that
+// selects the temporary index.
+func (g *GitManager) withWorktreeIndex(fn func(env []string) error) error {
+	tmp, err := os.CreateTemp(g.State.ShadowRepoDir, "diff-index-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary index: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	// Seed from the real index so unchanged files are not rehashed. Git
+	// rejects an empty index file, so without one start from nothing.
+	index, err := os.Open(filepath.Join(g.State.ShadowRepoDir, "index"))
+	if err == nil {
+		_, err = io.Copy(tmp, index)
+		index.Close()
+	} else if os.IsNotExist(err) {
+		err = os.Remove(tmp.Name())
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to prepare temporary index: %w", err)
+	}
+
+	env := []string{"GIT_INDEX_FILE=" + tmp.Name()}
+	if _, err := g.runCommandEnv(env, "add", "-A"); err != nil {
+		return fmt.Errorf("failed to stage working tree: %w", err)
+	}
+
+	return fn(env)
+}
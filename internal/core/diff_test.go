@@ -0,0 +1,89 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDiffSnapshots(t *testing.T) {
+	tempDir, state, gitManager := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	writeFile := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(tempDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	head := func() string {
+		hash, err := gitManager.RunCommand("rev-parse", "HEAD")
+		if err != nil {
+			t.Fatalf("Failed to resolve HEAD: %v", err)
+		}
+		return hash
+	}
+
+	writeFile("a.txt", "one\n")
+	writeFile("b.txt", "bee\n")
+	if err := gitManager.CreateSnapshot("first"); err != nil {
+		t.Fatalf("Failed to create snapshot: %v", err)
+	}
+	first := head()
+
+	writeFile("a.txt", "two\n")
+	if err := gitManager.CreateSnapshot("second"); err != nil {
+		t.Fatalf("Failed to create snapshot: %v", err)
+	}
+	second := head()
+
+	diff, err := gitManager.DiffSnapshots(first, second, DiffOptions{})
+	if err != nil {
+		t.Fatalf("DiffSnapshots failed: %v", err)
+	}
+	if !strings.Contains(diff, "-one") || !strings.Contains(diff, "+two") {
+		t.Errorf("Expected a.txt change in diff, got:\n%s", diff)
+	}
+	if strings.Contains(diff, "b.txt") {
+		t.Errorf("Expected unchanged b.txt to be absent, got:\n%s", diff)
+	}
+
+	stat, err := gitManager.DiffSnapshots(first, second, DiffOptions{Stat: true})
+	if err != nil {
+		t.Fatalf("DiffSnapshots --stat failed: %v", err)
+	}
+	if !strings.Contains(stat, "a.txt") || !strings.Contains(stat, "1 file changed") {
+		t.Errorf("Expected diffstat for a.txt, got:\n%s", stat)
+	}
+
+	// Working tree comparison includes files never snapshotted
+	writeFile("new.txt", "fresh\n")
+	indexBefore, _ := os.ReadFile(filepath.Join(state.ShadowRepoDir, "index"))
+
+	diff, err = gitManager.DiffSnapshots(second, "", DiffOptions{})
+	if err != nil {
+		t.Fatalf("DiffSnapshots against worktree failed: %v", err)
+	}
+	if !strings.Contains(diff, "+fresh") {
+		t.Errorf("Expected untracked new.txt in worktree diff, got:\n%s", diff)
+	}
+
+	indexAfter, _ := os.ReadFile(filepath.Join(state.ShadowRepoDir, "index"))
+	if string(indexBefore) != string(indexAfter) {
+		t.Error("Expected worktree diff to leave the shadow index untouched")
+	}
+
+	entries, _ := filepath.Glob(filepath.Join(state.ShadowRepoDir, "diff-index-*"))
+	if len(entries) != 0 {
+		t.Errorf("Expected temporary index to be removed, found %v", entries)
+	}
+
+	// Pathspecs limit the output
+	diff, err = gitManager.DiffSnapshots(first, "", DiffOptions{Pathspecs: []string{":(top,literal)new.txt"}})
+	if err != nil {
+		t.Fatalf("DiffSnapshots with pathspec failed: %v", err)
+	}
+	if strings.Contains(diff, "a.txt") || !strings.Contains(diff, "new.txt") {
+		t.Errorf("Expected only new.txt in filtered diff, got:\n%s", diff)
+	}
+}
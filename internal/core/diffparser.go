@@ -0,0 +1,133 @@
+package core
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DiffParserArgs are the git diff options whose output DiffParser reads:
+// raw status and line counts per file, NUL-terminated so any path survives,
+// with renames and copies detected
+var DiffParserArgs = []string{"--raw", "--numstat", "-z", "-M", "-C"}
+
+// DiffParser turns the output of git diff, show or log with DiffParserArgs
+// into FileChanges, so commands don't each pick git's text apart
+type DiffParser struct{}
+
+// Parse parses the changes of one diff. Status lines come first and line
+// counts follow, in the same order.
+func (DiffParser) Parse(output string) ([]FileChange, error) {
+	fields := strings.Split(strings.TrimSuffix(output, "\x00"), "\x00")
+	var changes []FileChange
+	numstat := 0
+	for i := 0; i < len(fields); i++ {
+		field := strings.TrimLeft(fields[i], "\n")
+		switch {
+		case field == "":
+			continue
+		case strings.HasPrefix(field, ":"):
+			// ":oldmode newmode oldhash newhash status", then the path, or
+			// the old and new path of a rename or copy
+			meta := strings.Fields(field)
+			if len(meta) != 5 || i+1 >= len(fields) {
+				return nil, fmt.Errorf("invalid raw diff line %q", field)
+			}
+			change := FileChange{Status: meta[4][:1], Path: fields[i+1]}
+			i++
+			if change.Status == "R" || change.Status == "C" {
+				if i+1 >= len(fields) {
+					return nil, fmt.Errorf("invalid raw diff line %q", field)
+				}
+				change.OldPath, change.Path = change.Path, fields[i+1]
+				change.Similarity, _ = strconv.Atoi(meta[4][1:])
+				i++
+			}
+			changes = append(changes, change)
+		default:
+			// "added\tdeleted\tpath", or "added\tdeleted\t" followed by the
+			// old and new path of a rename or copy
+			counts, ok := parseNumstatLine(field)
+			if !ok {
+				return nil, fmt.Errorf("invalid numstat line %q", field)
+			}
+			if counts.Path == "" {
+				i += 2
+			}
+			if numstat >= len(changes) {
+				return nil, fmt.Errorf("line counts without a file status: %q", field)
+			}
+			changes[numstat].Insertions = counts.Insertions
+			changes[numstat].Deletions = counts.Deletions
+			changes[numstat].Binary = counts.Binary
+			numstat++
+		}
+	}
+	return changes, nil
+}
+
+// SnapshotFileChanges returns what a snapshot changed, file by file, with
+// renames and copies detected. pathspecs limit the files.
+func (g *GitManager) SnapshotFileChanges(hash string, pathspecs ...string) ([]FileChange, error) {
+	if strings.HasPrefix(hash, "-") {
+		return nil, NewValidationError("invalid snapshot hash %q", hash)
+	}
+	args := append([]string{"show", "--format=", "--no-color"}, DiffParserArgs...)
+	args = append(append(args, hash, "--"), pathspecs...)
+	output, err := g.runCommandRaw(args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the changes of snapshot %s: %w", ShortHash(hash), err)
+	}
+	return DiffParser{}.Parse(string(output))
+}
+
+// ChangeGroup sums the changes to the files sharing a directory or type
+type ChangeGroup struct {
+	Name       string `json:"name"`
+	Files      int    `json:"files"`
+	Insertions int    `json:"insertions"`
+	Deletions  int    `json:"deletions"`
+}
+
+// GroupChanges sums changes by directory ("." for the project root) and
+// by file type (the lowercased extension, or the name without one), the
+// most changed files first
+func GroupChanges(changes []FileChange) (byDirectory, byType []ChangeGroup) {
+	return groupChanges(changes, func(p string) string { return path.Dir(p) }),
+		groupChanges(changes, fileType)
+}
+
+func groupChanges(changes []FileChange, key func(string) string) []ChangeGroup {
+	index := make(map[string]int)
+	var groups []ChangeGroup
+	for _, change := range changes {
+		name := key(change.Path)
+		i, ok := index[name]
+		if !ok {
+			i = len(groups)
+			index[name] = i
+			groups = append(groups, ChangeGroup{Name: name})
+		}
+		groups[i].Files++
+		groups[i].Insertions += change.Insertions
+		groups[i].Deletions += change.Deletions
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		if groups[i].Files != groups[j].Files {
+			return groups[i].Files > groups[j].Files
+		}
+		return groups[i].Name < groups[j].Name
+	})
+	return groups
+}
+
+// fileType is what GroupChanges groups a file by
+func fileType(p string) string {
+	name := path.Base(p)
+	if ext := path.Ext(name); ext != "" && ext != name {
+		return strings.ToLower(ext)
+	}
+	return name
+}
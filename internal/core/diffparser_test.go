@@ -0,0 +1,77 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiffParser(t *testing.T) {
+	output := ":100644 100644 aaa bbb M\x00src/main.go\x00" +
+		":000000 100644 000 ccc A\x00docs/new file.md\x00" +
+		":100644 100644 ddd eee R090\x00old.go\x00pkg/renamed.go\x00" +
+		":100644 100644 fff fff C100\x00logo.png\x00assets/logo.png\x00" +
+		"3\t1\tsrc/main.go\x00" +
+		"10\t0\tdocs/new file.md\x00" +
+		"1\t1\t\x00old.go\x00pkg/renamed.go\x00" +
+		"-\t-\t\x00logo.png\x00assets/logo.png\x00"
+
+	changes, err := DiffParser{}.Parse(output)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	expected := []FileChange{
+		{Path: "src/main.go", Status: "M", Insertions: 3, Deletions: 1},
+		{Path: "docs/new file.md", Status: "A", Insertions: 10},
+		{Path: "pkg/renamed.go", Status: "R", OldPath: "old.go", Similarity: 90, Insertions: 1, Deletions: 1},
+		{Path: "assets/logo.png", Status: "C", OldPath: "logo.png", Similarity: 100, Binary: true},
+	}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Errorf("Parse() =\n%+v\nwant\n%+v", changes, expected)
+	}
+
+	byDirectory, byType := GroupChanges(append(expected, FileChange{Path: "Makefile", Insertions: 2}))
+	if byDirectory[0] != (ChangeGroup{Name: ".", Files: 1, Insertions: 2}) || len(byDirectory) != 5 {
+		t.Errorf("Unexpected directory groups %+v", byDirectory)
+	}
+	if byType[0] != (ChangeGroup{Name: ".go", Files: 2, Insertions: 4, Deletions: 2}) || byType[len(byType)-1].Name != "Makefile" {
+		t.Errorf("Unexpected type groups %+v", byType)
+	}
+
+	if _, err := (DiffParser{}).Parse(":100644 M\x00x\x00"); err == nil {
+		t.Error("Expected an error for a malformed status line")
+	}
+}
+
+func TestSnapshotFileChanges(t *testing.T) {
+	tempDir, _, gitManager := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	body := strings.Repeat("line of code\n", 20)
+	os.WriteFile(filepath.Join(tempDir, "old.go"), []byte(body), 0644)
+	os.WriteFile(filepath.Join(tempDir, "keep.go"), []byte("package keep\n"), 0644)
+	if err := gitManager.CreateSnapshot("base"); err != nil {
+		t.Fatalf("CreateSnapshot failed: %v", err)
+	}
+
+	os.MkdirAll(filepath.Join(tempDir, "pkg"), 0755)
+	os.Rename(filepath.Join(tempDir, "old.go"), filepath.Join(tempDir, "pkg", "new.go"))
+	os.WriteFile(filepath.Join(tempDir, "keep.go"), []byte("package keep\n\nvar x = 1\n"), 0644)
+	if err := gitManager.CreateSnapshot("rename"); err != nil {
+		t.Fatalf("CreateSnapshot failed: %v", err)
+	}
+
+	changes, err := gitManager.SnapshotFileChanges("HEAD")
+	if err != nil {
+		t.Fatalf("SnapshotFileChanges failed: %v", err)
+	}
+	expected := []FileChange{
+		{Path: "keep.go", Status: "M", Insertions: 2},
+		{Path: "pkg/new.go", Status: "R", OldPath: "old.go", Similarity: 100},
+	}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Errorf("SnapshotFileChanges() = %+v, want %+v", changes, expected)
+	}
+}
@@ -0,0 +1,102 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DefaultMinFreeSpaceMB is the free space below which snapshots and gc are
+// skipped when no configuration is loaded
+const DefaultMinFreeSpaceMB = 100
+
+// LowDiskSpaceError reports that the shadow repository volume is below the
+// configured free space threshold. Nothing was written when it is returned.
+type LowDiskSpaceError struct {
+	Path     string
+	Free     uint64
+	Required uint64
+}
+
+func (e *LowDiskSpaceError) Error() string {
+	return fmt.Sprintf("low disk space: %d MB free on the volume holding %s, %d MB required",
+		e.Free/(1024*1024), e.Path, e.Required/(1024*1024))
+}
+
+// IsLowDiskSpace reports whether err is (or wraps) a LowDiskSpaceError
+func IsLowDiskSpace(err error) bool {
+	var lowSpace *LowDiskSpaceError
+	return errors.As(err, &lowSpace)
+}
+
+// minFreeSpace returns the configured threshold in bytes; 0 disables the check
+func (g *GitManager) minFreeSpace() uint64 {
+	mb := DefaultMinFreeSpaceMB
+	if g.State.Config != nil {
+		mb = g.State.Config.Git.MinFreeSpaceMB
+	}
+	if mb <= 0 {
+		return 0
+	}
+	return uint64(mb) * 1024 * 1024
+}
+
+// CheckDiskSpace returns a LowDiskSpaceError when the shadow repository
+// volume has less free space than git.min_free_space_mb. Failing to read
+// the free space is not treated as low space.
+func (g *GitManager) CheckDiskSpace() error {
+	required := g.minFreeSpace()
+	if required == 0 {
+		return nil
+	}
+
+	free, err := freeSpace(g.State.ShadowRepoDir)
+	if err != nil {
+		return nil
+	}
+	if free < required {
+		return &LowDiskSpaceError{Path: g.State.ShadowRepoDir, Free: free, Required: required}
+	}
+
+	return nil
+}
+
+// MetadataRecord lists paths that changed while snapshots were paused for
+// lack of disk space
+type MetadataRecord struct {
+	Time  time.Time `json:"time"`
+	Paths []string  `json:"paths"`
+}
+
+// MetadataLogPath is where metadata-only records are appended while
+// snapshots are paused
+func (g *GitManager) MetadataLogPath() string {
+	return filepath.Join(g.State.ShadowRepoDir, "metadata-only.jsonl")
+}
+
+// RecordMetadataOnly appends the changed paths (relative to the project
+// root) to the metadata-only log. Each record is a single small line so
+// it still fits on a nearly full disk.
+func (g *GitManager) RecordMetadataOnly(paths []string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+
+	line, err := json.Marshal(MetadataRecord{Time: time.Now(), Paths: paths})
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(g.MetadataLogPath(), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open metadata log: %w", err)
+	}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write metadata log: %w", err)
+	}
+	return file.Close()
+}
@@ -0,0 +1,132 @@
+package core
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/config"
+)
+
+func TestCheckDiskSpace(t *testing.T) {
+	tempDir, state, gitManager := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	free, err := freeSpace(state.ShadowRepoDir)
+	if err != nil {
+		t.Fatalf("freeSpace failed: %v", err)
+	}
+	if free == 0 {
+		t.Fatal("Expected non-zero free space")
+	}
+
+	tests := []struct {
+		name        string
+		thresholdMB int
+		expectLow   bool
+	}{
+		{"disabled", 0, false},
+		{"below free space", 1, false},
+		{"above free space", int(free/(1024*1024)) + 1024, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state.Config = &config.Config{Git: config.GitConfig{MinFreeSpaceMB: tt.thresholdMB}}
+
+			err := gitManager.CheckDiskSpace()
+			if IsLowDiskSpace(err) != tt.expectLow {
+				t.Errorf("Expected low space %v, got %v", tt.expectLow, err)
+			}
+		})
+	}
+}
+
+func TestCreateSnapshotRefusesOnLowDiskSpace(t *testing.T) {
+	tempDir, state, gitManager := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	// Larger than any real disk
+	state.Config = &config.Config{Git: config.GitConfig{MinFreeSpaceMB: 1 << 30}}
+
+	if err := os.WriteFile(filepath.Join(tempDir, "file.txt"), []byte("content"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	err := gitManager.CreateSnapshot("blocked")
+	if !IsLowDiskSpace(err) {
+		t.Fatalf("Expected LowDiskSpaceError, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "MB required") {
+		t.Errorf("Expected error to mention the threshold, got %v", err)
+	}
+
+	// Nothing may have been staged or committed
+	if status, _ := gitManager.RunCommand("status", "--porcelain"); !strings.HasPrefix(status, "??") {
+		t.Errorf("Expected file to stay untracked, got status %q", status)
+	}
+	snapshots, err := gitManager.ListSnapshots(0, "")
+	if err != nil {
+		t.Fatalf("ListSnapshots failed: %v", err)
+	}
+	if len(snapshots) != 0 {
+		t.Errorf("Expected no snapshots, got %d", len(snapshots))
+	}
+
+	// Snapshots resume once the threshold is satisfied
+	state.Config.Git.MinFreeSpaceMB = 0
+	if err := gitManager.CreateSnapshot("resumed"); err != nil {
+		t.Fatalf("CreateSnapshot failed: %v", err)
+	}
+}
+
+func TestRecordMetadataOnly(t *testing.T) {
+	tempDir, _, gitManager := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	if err := gitManager.RecordMetadataOnly(nil); err != nil {
+		t.Fatalf("RecordMetadataOnly with no paths failed: %v", err)
+	}
+	if _, err := os.Stat(gitManager.MetadataLogPath()); !os.IsNotExist(err) {
+		t.Error("Expected no log file for an empty record")
+	}
+
+	batches := [][]string{{"a.txt", "src/b.go"}, {"c.md"}}
+	for _, paths := range batches {
+		if err := gitManager.RecordMetadataOnly(paths); err != nil {
+			t.Fatalf("RecordMetadataOnly failed: %v", err)
+		}
+	}
+
+	file, err := os.Open(gitManager.MetadataLogPath())
+	if err != nil {
+		t.Fatalf("Failed to open metadata log: %v", err)
+	}
+	defer file.Close()
+
+	var records []MetadataRecord
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var record MetadataRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("Invalid metadata record %q: %v", scanner.Text(), err)
+		}
+		records = append(records, record)
+	}
+
+	if len(records) != len(batches) {
+		t.Fatalf("Expected %d records, got %d", len(batches), len(records))
+	}
+	for i, record := range records {
+		if !reflect.DeepEqual(record.Paths, batches[i]) {
+			t.Errorf("Record %d: expected %v, got %v", i, batches[i], record.Paths)
+		}
+		if record.Time.IsZero() {
+			t.Errorf("Record %d has no timestamp", i)
+		}
+	}
+}
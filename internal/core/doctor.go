@@ -0,0 +1,371 @@
+package core
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/config"
+)
+
+// Outcomes of a doctor check
+const (
+	CheckOK   = "ok"
+	CheckWarn = "warn" // Works, but worse than it could
+	CheckFail = "fail" // Snapshots fail or are at risk
+)
+
+// minWatchLimit is the lowest inotify limit doctor accepts even for small
+// projects: the old kernel default, 8192, is easily used up by editors and
+// other tools sharing it
+const minWatchLimit = 8193
+
+// Check is one diagnosis made by 'timemachine doctor'
+type Check struct {
+	Name   string       // What was checked, e.g. "Git"
+	Status string       // CheckOK, CheckWarn or CheckFail
+	Detail string       // What was found
+	Fix    string       // What to do about a problem; empty when OK
+	Apply  func() error // Applies Fix; nil unless it is safe to do unasked
+}
+
+// Diagnose checks the environment and the project for problems that keep
+// Time Machine from working well. state may be from NewLightAppState: the
+// configuration is loaded and checked here.
+func Diagnose(state *AppState) []Check {
+	caps := ProbeCapabilities()
+	manager := config.NewManager()
+	configErr := manager.Load(state.ProjectRoot)
+
+	checks := []Check{
+		checkGit(caps),
+		checkWatching(state, caps),
+		checkConfig(manager, configErr),
+		checkEnvironment(manager),
+		checkShadowRepo(state, caps),
+		checkHooks(state),
+		checkIgnoreFile(state),
+	}
+	if runtime.GOOS != "windows" {
+		checks = append(checks, checkConfigPermissions(state))
+	}
+	return checks
+}
+
+// HealthScore rates checks from 0 to 100: a passed check counts fully, a
+// warning half and a failure not at all
+func HealthScore(checks []Check) int {
+	if len(checks) == 0 {
+		return 100
+	}
+	points := 0
+	for _, check := range checks {
+		switch check.Status {
+		case CheckOK:
+			points += 2
+		case CheckWarn:
+			points++
+		}
+	}
+	return points * 100 / (2 * len(checks))
+}
+
+func checkGit(caps *Capabilities) Check {
+	check := Check{Name: "Git"}
+	switch {
+	case !caps.Git:
+		check.Status, check.Detail = CheckFail, "git was not found on PATH"
+		check.Fix = "Install git 2.31 or newer"
+	case !caps.GitRestore || !caps.GitDiskUsage:
+		check.Status = CheckWarn
+		check.Detail = strings.Join(caps.Limitations(), "; ")
+		check.Fix = "Upgrade git to 2.31 or newer"
+	default:
+		check.Status, check.Detail = CheckOK, "git "+caps.GitVersion.String()
+	}
+	return check
+}
+
+func checkWatching(state *AppState, caps *Capabilities) Check {
+	check := Check{Name: "File watching"}
+	if caps.WatchError != nil {
+		check.Status = CheckFail
+		check.Detail = fmt.Sprintf("%s is unavailable: %v", caps.WatchBackend, caps.WatchError)
+		check.Fix = watchLimitHint()
+		return check
+	}
+
+	limit := watchLimit()
+	if limit == 0 {
+		check.Status, check.Detail = CheckOK, caps.WatchBackend
+		return check
+	}
+	dirs := countWatchedDirs(state.ProjectRoot)
+	check.Detail = fmt.Sprintf("%s allows %d watched directories; the project has %d", caps.WatchBackend, limit, dirs)
+	switch {
+	case dirs > limit:
+		check.Status = CheckWarn
+		check.Detail += ", so the rest are polled"
+		check.Fix = watchLimitHint()
+	case limit < minWatchLimit || dirs > limit/2:
+		check.Status = CheckWarn
+		check.Detail += "; editors and other watchers share the limit"
+		check.Fix = watchLimitHint()
+	default:
+		check.Status = CheckOK
+	}
+	return check
+}
+
+// countWatchedDirs counts the directories the watcher would watch
+func countWatchedDirs(root string) int {
+	ignoreManager := NewEnhancedIgnoreManager(root)
+	dirs := 0
+	filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || !entry.IsDir() {
+			return nil
+		}
+		if entry.Name() == ".git" || ignoreManager.ShouldIgnoreDirectory(path) {
+			return filepath.SkipDir
+		}
+		dirs++
+		return nil
+	})
+	return dirs
+}
+
+func checkConfig(manager *config.Manager, loadErr error) Check {
+	check := Check{Name: "Configuration"}
+	if loadErr != nil {
+		check.Status, check.Detail = CheckFail, loadErr.Error()
+		check.Fix = "Correct the configuration; every command falls back to partial settings until then"
+		return check
+	}
+	check.Status = CheckOK
+	if path := manager.GetViper().ConfigFileUsed(); path != "" {
+		check.Detail = "valid (" + path + ")"
+	} else {
+		check.Detail = "no configuration file, using defaults"
+	}
+	return check
+}
+
+// checkEnvironment finds TIMEMACHINE_* variables that are misspelled or
+// silently override the configuration file
+func checkEnvironment(manager *config.Manager) Check {
+	check := Check{Name: "Environment variables", Status: CheckOK}
+	bindings := config.EnvBindings()
+	var problems []string
+	for _, entry := range os.Environ() {
+		name, _, _ := strings.Cut(entry, "=")
+		if !strings.HasPrefix(name, "TIMEMACHINE_") || name == "TIMEMACHINE_PAGER" || name == FaultInjectionEnv || name == IgnoreEnv {
+			continue
+		}
+		if _, ok := bindings[name]; !ok {
+			problems = append(problems, name+" is not a setting and has no effect")
+		}
+	}
+	sort.Strings(problems)
+
+	overrides := manager.EnvOverrides()
+	for _, override := range overrides {
+		if override.File != "" && override.File != override.Value {
+			problems = append(problems, fmt.Sprintf("%s=%s overrides %s: %s from the configuration file",
+				override.Env, override.Value, override.Key, override.File))
+		}
+	}
+
+	if len(problems) > 0 {
+		check.Status, check.Detail = CheckWarn, strings.Join(problems, "; ")
+		check.Fix = "Unset the variables, or move their values into timemachine.yaml"
+	} else if len(overrides) > 0 {
+		check.Detail = fmt.Sprintf("%d setting(s) overridden", len(overrides))
+	} else {
+		check.Detail = "no overrides"
+	}
+	return check
+}
+
+func checkShadowRepo(state *AppState, caps *Capabilities) Check {
+	check := Check{Name: "Shadow repository"}
+	if !state.IsInitialized {
+		check.Status, check.Detail = CheckFail, "not initialized"
+		check.Fix = "Run 'timemachine init'"
+		return check
+	}
+	if !caps.Git {
+		check.Status, check.Detail = CheckWarn, "can't be checked without git"
+		return check
+	}
+
+	gitManager := NewGitManager(state)
+	var missing []string
+	for _, key := range []string{"user.name", "user.email"} {
+		if value, err := gitManager.RunCommand("config", key); err != nil || strings.TrimSpace(value) == "" {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) == 0 {
+		check.Status, check.Detail = CheckOK, state.ShadowRepoDir
+		return check
+	}
+
+	check.Status = CheckFail
+	check.Detail = "no " + strings.Join(missing, " or ") + ", so snapshots can't be committed"
+	if mainRepoHasIdentity(state) {
+		check.Fix = "Copy user.name and user.email from the project's repository"
+		check.Apply = gitManager.copyGitConfig
+	} else {
+		check.Fix = "Run 'git config --global user.name \"Your Name\"' and 'git config --global user.email you@example.com'"
+	}
+	return check
+}
+
+// mainRepoHasIdentity reports whether the project's repository sets both
+// user.name and user.email
+func mainRepoHasIdentity(state *AppState) bool {
+	for _, key := range []string{"user.name", "user.email"} {
+		output, err := exec.Command("git", "--git-dir="+state.GitDir, "config", key).Output()
+		if err != nil || strings.TrimSpace(string(output)) == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// checkHooks finds Time Machine's Git hooks that Git won't run
+func checkHooks(state *AppState) Check {
+	check := Check{Name: "Git hooks", Status: CheckOK}
+	hooksDir := filepath.Join(CommonDir(state.GitDir), "hooks")
+	var installed, broken []string
+	for _, name := range []string{"pre-push", "post-commit", "post-merge"} {
+		path := filepath.Join(hooksDir, name)
+		content, err := os.ReadFile(path)
+		if err != nil || !strings.Contains(string(content), "timemachine") {
+			continue
+		}
+		installed = append(installed, name)
+		if info, err := os.Stat(path); err == nil && runtime.GOOS != "windows" && info.Mode().Perm()&0111 == 0 {
+			broken = append(broken, path)
+		}
+	}
+
+	switch {
+	case len(broken) > 0:
+		check.Status = CheckWarn
+		check.Detail = strings.Join(broken, ", ") + " not executable, so Git skips it"
+		check.Fix = "Make the hooks executable"
+		check.Apply = func() error {
+			for _, path := range broken {
+				if err := os.Chmod(path, 0755); err != nil {
+					return fmt.Errorf("failed to make %s executable: %w", path, err)
+				}
+			}
+			return nil
+		}
+	case len(installed) > 0:
+		check.Detail = strings.Join(installed, ", ")
+	default:
+		check.Detail = "none installed"
+	}
+	return check
+}
+
+// checkIgnoreFile finds .timemachine-ignore content beyond what is read
+func checkIgnoreFile(state *AppState) Check {
+	check := Check{Name: "Ignore file", Status: CheckOK}
+	path := filepath.Join(state.ProjectRoot, DefaultIgnoreFile)
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		check.Detail = "no " + DefaultIgnoreFile + ", using built-in patterns"
+		return check
+	} else if err != nil {
+		check.Status, check.Detail = CheckWarn, err.Error()
+		return check
+	}
+	if info.Size() > MaxIgnoreFileSize {
+		check.Status = CheckFail
+		check.Detail = fmt.Sprintf("%s is %d bytes, over the %d byte limit, so none of it is used", DefaultIgnoreFile, info.Size(), MaxIgnoreFileSize)
+		check.Fix = "Shorten it, e.g. with directory patterns instead of one line per file"
+		return check
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		check.Status, check.Detail = CheckWarn, err.Error()
+		return check
+	}
+	defer file.Close()
+	lines, patterns, long := 0, 0, 0
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, MaxPatternLength), 1024*1024)
+	for scanner.Scan() {
+		lines++
+		line := trimPatternLine(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns++
+		if len(line) > MaxPatternLength {
+			long++
+		}
+	}
+
+	var problems []string
+	if lines > MaxIgnoreLines {
+		problems = append(problems, fmt.Sprintf("lines beyond %d of %d are skipped", MaxIgnoreLines, lines))
+	}
+	if patterns > MaxPatterns {
+		problems = append(problems, fmt.Sprintf("patterns beyond %d of %d are skipped", MaxPatterns, patterns))
+	}
+	if long > 0 {
+		problems = append(problems, fmt.Sprintf("%d pattern(s) over %d characters are skipped", long, MaxPatternLength))
+	}
+	if len(problems) > 0 {
+		check.Status, check.Detail = CheckWarn, strings.Join(problems, "; ")
+		check.Fix = "Shorten " + DefaultIgnoreFile + ", e.g. with directory patterns instead of one line per file"
+		return check
+	}
+	check.Detail = fmt.Sprintf("%d pattern(s)", patterns)
+	return check
+}
+
+// checkConfigPermissions finds configuration files others can change:
+// hooks in them run as commands
+func checkConfigPermissions(state *AppState) Check {
+	check := Check{Name: "Configuration permissions", Status: CheckOK, Detail: "only writable by you"}
+	paths := []string{
+		filepath.Join(state.ProjectRoot, "timemachine.yaml"),
+		filepath.Join(state.ProjectRoot, ".timemachine", "timemachine.yaml"),
+	}
+	if dir, err := os.UserConfigDir(); err == nil {
+		paths = append(paths, filepath.Join(dir, "timemachine", "timemachine.yaml"))
+	}
+
+	var writable []string
+	for _, path := range paths {
+		if info, err := os.Stat(path); err == nil && info.Mode().Perm()&0022 != 0 {
+			writable = append(writable, path)
+		}
+	}
+	if len(writable) > 0 {
+		check.Status = CheckFail
+		check.Detail = strings.Join(writable, ", ") + " writable by other users, who could add hook commands"
+		check.Fix = "Restrict them to owner read/write (0600)"
+		check.Apply = func() error {
+			for _, path := range writable {
+				if err := os.Chmod(path, 0600); err != nil {
+					return fmt.Errorf("failed to restrict %s: %w", path, err)
+				}
+			}
+			return nil
+		}
+	}
+	return check
+}
@@ -0,0 +1,87 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestHealthScore(t *testing.T) {
+	checks := []Check{{Status: CheckOK}, {Status: CheckWarn}, {Status: CheckFail}, {Status: CheckOK}}
+	if score := HealthScore(checks); score != 62 {
+		t.Errorf("Expected a score of 62, got %d", score)
+	}
+	if score := HealthScore([]Check{{Status: CheckOK}}); score != 100 {
+		t.Errorf("Expected a score of 100, got %d", score)
+	}
+}
+
+func TestDiagnose(t *testing.T) {
+	tempDir, state, _ := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+	t.Setenv("TIMEMACHINE_LOG_LEVL", "debug")
+
+	checks := Diagnose(state)
+	byName := make(map[string]Check)
+	for _, check := range checks {
+		byName[check.Name] = check
+	}
+	if check := byName["Shadow repository"]; check.Status != CheckOK {
+		t.Errorf("Expected the shadow repository to be fine, got %+v", check)
+	}
+	if check := byName["Environment variables"]; check.Status != CheckWarn || !strings.Contains(check.Detail, "TIMEMACHINE_LOG_LEVL") {
+		t.Errorf("Expected the misspelled variable to be reported, got %+v", check)
+	}
+}
+
+func TestCheckIgnoreFile(t *testing.T) {
+	tempDir := t.TempDir()
+	state := &AppState{ProjectRoot: tempDir}
+	if check := checkIgnoreFile(state); check.Status != CheckOK {
+		t.Errorf("Expected a missing ignore file to be fine, got %+v", check)
+	}
+
+	var patterns []string
+	for i := 0; i <= MaxPatterns; i++ {
+		patterns = append(patterns, "generated"+strings.Repeat("x", i%10)+"/"+string(rune('a'+i%26))+"/")
+	}
+	os.WriteFile(filepath.Join(tempDir, DefaultIgnoreFile), []byte(strings.Join(patterns, "\n")), 0644)
+	if check := checkIgnoreFile(state); check.Status != CheckWarn || !strings.Contains(check.Detail, "patterns beyond 1000") {
+		t.Errorf("Expected the pattern limit to be reported, got %+v", check)
+	}
+}
+
+func TestDoctorFixes(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("File modes don't apply on Windows")
+	}
+	tempDir, state, _ := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	hook := filepath.Join(state.GitDir, "hooks", "pre-push")
+	os.MkdirAll(filepath.Dir(hook), 0755)
+	os.WriteFile(hook, []byte("#!/bin/sh\ntimemachine clean --auto --quiet\n"), 0644)
+	configPath := filepath.Join(tempDir, "timemachine.yaml")
+	os.WriteFile(configPath, []byte("log:\n  level: info\n"), 0644)
+	os.Chmod(configPath, 0666)
+
+	for _, check := range []Check{checkHooks(state), checkConfigPermissions(state)} {
+		if check.Status == CheckOK || check.Apply == nil {
+			t.Fatalf("Expected a problem with a safe fix, got %+v", check)
+		}
+		if err := check.Apply(); err != nil {
+			t.Fatalf("%s fix failed: %v", check.Name, err)
+		}
+	}
+	if info, _ := os.Stat(hook); info.Mode().Perm()&0111 == 0 {
+		t.Errorf("Expected the hook to be executable")
+	}
+	if info, _ := os.Stat(configPath); info.Mode().Perm() != 0600 {
+		t.Errorf("Expected the configuration to be owner-only, got %v", info.Mode().Perm())
+	}
+	if check := checkHooks(state); check.Status != CheckOK {
+		t.Errorf("Expected the hooks to be fine after the fix, got %+v", check)
+	}
+}
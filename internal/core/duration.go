@@ -0,0 +1,38 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/config"
+)
+
+// ParseAge parses human-friendly ages such as "90m", "2h", "7d", "2w",
+// "1mo" and "1y"; see config.ParseAge
+func ParseAge(s string) (time.Duration, error) {
+	return config.ParseAge(s)
+}
+
+// ParseTimeBound parses a point in time given as an age before now ("2h",
+// "7d"), an RFC 3339 timestamp, "2006-01-02 15:04" or a date. A date alone
+// means the start of that day, or its end when upper is set, so a date works
+// as an inclusive upper bound.
+func ParseTimeBound(s string, upper bool) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if age, err := ParseAge(s); err == nil {
+		return time.Now().Add(-age), nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04", "2006-01-02T15:04"} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	if t, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		if upper {
+			return t.AddDate(0, 0, 1).Add(-time.Second), nil
+		}
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("unrecognized time %q (use an age like 2h or 7d, or a date like 2006-01-02)", s)
+}
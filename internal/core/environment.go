@@ -0,0 +1,157 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Environments DetectEnvironment recognizes
+const (
+	EnvWSL          = "wsl"
+	EnvDevcontainer = "devcontainer"
+	EnvCodespaces   = "codespaces"
+)
+
+// pollFilesystems don't report changes made from outside the machine the
+// watcher runs on: by Windows for drives mounted into WSL, by the host for
+// Docker Desktop's bind mounts, by other clients for network filesystems.
+// The watcher polls projects on them.
+var pollFilesystems = map[string]bool{
+	"drvfs":         true,
+	"9p":            true,
+	"fuse.grpcfuse": true,
+	"fakeowner":     true,
+	"osxfs":         true,
+	"vboxsf":        true,
+	"cifs":          true,
+	"smb3":          true,
+	"nfs":           true,
+	"nfs4":          true,
+}
+
+// Environment describes where timemachine runs and what holds the project
+type Environment struct {
+	Kind       string `json:"kind,omitempty"`       // EnvWSL, EnvDevcontainer or EnvCodespaces; empty elsewhere
+	Filesystem string `json:"filesystem,omitempty"` // Type of the filesystem holding the project, where known
+	Poll       bool   `json:"poll"`                 // Changes aren't reported reliably, so the watcher polls
+
+	drives map[string]string // Windows drive letter (lower case) to its WSL mount point
+}
+
+// environments caches DetectEnvironment results per directory
+var environments sync.Map
+
+// DetectEnvironment recognizes WSL, dev containers and Codespaces, and the
+// filesystem holding dir. The result is cached per directory.
+func DetectEnvironment(dir string) Environment {
+	if cached, ok := environments.Load(dir); ok {
+		return cached.(Environment)
+	}
+	mounts, _ := os.ReadFile("/proc/mounts")
+	osRelease, _ := os.ReadFile("/proc/sys/kernel/osrelease")
+	_, err := os.Stat("/.dockerenv")
+	env := detectEnvironment(dir, os.Getenv, string(mounts), string(osRelease), err == nil)
+	environments.Store(dir, env)
+	return env
+}
+
+// detectEnvironment does the work of DetectEnvironment with its inputs
+// passed in, so they can be faked
+func detectEnvironment(dir string, getenv func(string) string, mounts, osRelease string, docker bool) Environment {
+	var env Environment
+	switch {
+	case getenv("CODESPACES") == "true":
+		env.Kind = EnvCodespaces
+	case getenv("WSL_DISTRO_NAME") != "" || strings.Contains(strings.ToLower(osRelease), "microsoft"):
+		env.Kind = EnvWSL
+	case getenv("REMOTE_CONTAINERS") == "true" || getenv("DEVCONTAINER") != "" || docker:
+		env.Kind = EnvDevcontainer
+	}
+
+	// The project's filesystem is the longest mount point containing it
+	longest := -1
+	for _, line := range strings.Split(mounts, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 4 {
+			continue
+		}
+		device, point, fsType := unescapeMount(fields[0]), unescapeMount(fields[1]), fields[2]
+		// WSL 2 mounts Windows drives over 9p
+		if fsType == "9p" && strings.Contains(fields[3], "aname=drvfs") {
+			fsType = "drvfs"
+		}
+		if fsType == "drvfs" && len(device) >= 2 && device[1] == ':' {
+			if env.drives == nil {
+				env.drives = make(map[string]string)
+			}
+			env.drives[strings.ToLower(device[:1])] = point
+		}
+		if len(point) > longest && (point == "/" || dir == point || strings.HasPrefix(dir, point+"/")) {
+			longest = len(point)
+			env.Filesystem = fsType
+		}
+	}
+	env.Poll = pollFilesystems[env.Filesystem]
+	return env
+}
+
+// unescapeMount decodes the octal escapes (\040 for a space) of /proc/mounts
+func unescapeMount(field string) string {
+	if !strings.Contains(field, `\`) {
+		return field
+	}
+	var b strings.Builder
+	for i := 0; i < len(field); i++ {
+		if field[i] == '\\' && i+4 <= len(field) {
+			if code, err := strconv.ParseUint(field[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(code))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(field[i])
+	}
+	return b.String()
+}
+
+// String describes the environment for 'timemachine status'
+func (e Environment) String() string {
+	name := map[string]string{
+		EnvWSL:          "WSL",
+		EnvDevcontainer: "dev container",
+		EnvCodespaces:   "GitHub Codespaces",
+	}[e.Kind]
+	if name == "" {
+		name = "local"
+	}
+	if e.Poll {
+		name += ", project on " + e.Filesystem
+	}
+	return name
+}
+
+// LocalPath turns a Windows path such as C:\Users\me\project given in WSL
+// into the path of the mounted drive (/mnt/c/Users/me/project). Other paths
+// are returned unchanged.
+func (e Environment) LocalPath(path string) string {
+	if e.Kind != EnvWSL || len(path) < 3 || path[1] != ':' || (path[2] != '\\' && path[2] != '/') {
+		return path
+	}
+	letter := strings.ToLower(path[:1])
+	root, ok := e.drives[letter]
+	if !ok {
+		if letter[0] < 'a' || letter[0] > 'z' {
+			return path
+		}
+		root = "/mnt/" + letter // WSL's default automount root
+	}
+	return filepath.Join(root, filepath.FromSlash(strings.ReplaceAll(path[3:], `\`, "/")))
+}
+
+// Environment returns the environment the project is used in
+func (s *AppState) Environment() Environment {
+	return DetectEnvironment(s.ProjectRoot)
+}
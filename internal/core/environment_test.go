@@ -0,0 +1,59 @@
+package core
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestDetectEnvironment(t *testing.T) {
+	// WSL 2 mounts Windows drives over 9p
+	mounts := `/dev/sdc / ext4 rw,relatime 0 0
+C:\134 /mnt/c 9p rw,noatime,aname=drvfs;path=C:\;uid=1000 0 0
+D:\134 /mnt/data\040drive 9p rw,noatime,aname=drvfs;path=D:\ 0 0
+`
+	getenv := func(key string) string {
+		if key == "WSL_DISTRO_NAME" {
+			return "Ubuntu"
+		}
+		return ""
+	}
+
+	env := detectEnvironment("/mnt/c/Users/me/project", getenv, mounts, "", false)
+	if env.Kind != EnvWSL || env.Filesystem != "drvfs" || !env.Poll {
+		t.Errorf("Expected a polled WSL project on drvfs, got %+v", env)
+	}
+	env = detectEnvironment("/home/me/project", getenv, mounts, "", false)
+	if env.Kind != EnvWSL || env.Filesystem != "ext4" || env.Poll {
+		t.Errorf("Expected a watched WSL project on ext4, got %+v", env)
+	}
+
+	tests := map[string]string{
+		`C:\Users\me\project\main.go`: "/mnt/c/Users/me/project/main.go",
+		`d:/notes.txt`:                "/mnt/data drive/notes.txt",
+		`e:\file`:                     "/mnt/e/file", // Not mounted yet: WSL's default root
+		"src/main.go":                 "src/main.go",
+		"/mnt/c/file":                 "/mnt/c/file",
+	}
+	for path, expected := range tests {
+		if got := env.LocalPath(path); got != filepath.FromSlash(expected) {
+			t.Errorf("Expected %s to become %s, got %s", path, expected, got)
+		}
+	}
+
+	// Windows paths mean nothing outside WSL
+	plain := detectEnvironment("/home/me/project", func(string) string { return "" }, "", "6.8.0-generic", false)
+	if plain.Kind != "" || plain.LocalPath(`C:\file`) != `C:\file` {
+		t.Errorf("Expected a plain Linux environment, got %+v", plain)
+	}
+	if env := detectEnvironment("/workspaces/app", func(key string) string {
+		if key == "CODESPACES" {
+			return "true"
+		}
+		return ""
+	}, "", "", true); env.Kind != EnvCodespaces {
+		t.Errorf("Expected Codespaces, got %+v", env)
+	}
+	if env := detectEnvironment("/app", func(string) string { return "" }, "", "", true); env.Kind != EnvDevcontainer {
+		t.Errorf("Expected a container, got %+v", env)
+	}
+}
@@ -0,0 +1,153 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/ui"
+)
+
+// Exit codes of the timemachine command. Scripts rely on them, so a code's
+// meaning never changes; new failures get new codes.
+const (
+	ExitOK             = 0
+	ExitFailure        = 1 // Any failure without a more specific code
+	ExitNotInitialized = 2 // Time Machine isn't initialized in the repository
+	ExitNotGitRepo     = 3 // Not inside a Git repository
+	ExitValidation     = 4 // Invalid arguments, flags or configuration, or a needed answer that can't be given
+	ExitGit            = 5 // A git command failed
+	ExitNotFound       = 6 // A snapshot or configuration key doesn't exist
+	ExitLowDiskSpace   = 7 // Below git.min_free_space_mb
+	ExitSecrets        = 8 // Snapshot blocked by detected secrets
+	ExitBusy           = 9 // Shadow repository locked or a restore interrupted
+)
+
+// ErrNotInitialized is returned by commands that need 'timemachine init' to
+// have been run
+var ErrNotInitialized = errors.New("Time Machine is not initialized")
+
+// ErrNotGitRepository is returned when no Git repository contains the
+// working directory
+var ErrNotGitRepository = errors.New("not in a Git repository (or any parent directory)")
+
+// ErrWatcherRunning is wrapped by the error of a watcher refusing to start
+// while another one runs in the same repository
+var ErrWatcherRunning = errors.New("another watcher is running")
+
+// ValidationError reports invalid user input: arguments, flags, a snapshot
+// hash or the configuration. Nothing was changed when it is returned.
+type ValidationError struct {
+	Err error
+}
+
+func (e *ValidationError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// NewValidationError formats a ValidationError like fmt.Errorf
+func NewValidationError(format string, a ...interface{}) error {
+	return &ValidationError{Err: fmt.Errorf(format, a...)}
+}
+
+// NotFoundError reports that a snapshot, or another thing named by the user,
+// doesn't exist
+type NotFoundError struct {
+	Err error
+}
+
+func (e *NotFoundError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *NotFoundError) Unwrap() error {
+	return e.Err
+}
+
+// NewNotFoundError formats a NotFoundError like fmt.Errorf
+func NewNotFoundError(format string, a ...interface{}) error {
+	return &NotFoundError{Err: fmt.Errorf(format, a...)}
+}
+
+// GitError reports a git command that failed, with what it printed
+type GitError struct {
+	Args   []string
+	Output string
+	Err    error // Usually an *exec.ExitError
+}
+
+func (e *GitError) Error() string {
+	return fmt.Sprintf("git command failed: %s\nOutput: %s", e.Err.Error(), e.Output)
+}
+
+func (e *GitError) Unwrap() error {
+	return e.Err
+}
+
+// reportedError marks an error whose message a command already showed in
+// its own words, so main only sets the exit code
+type reportedError struct {
+	err error
+}
+
+func (e *reportedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *reportedError) Unwrap() error {
+	return e.err
+}
+
+// Reported marks err as already shown to the user
+func Reported(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &reportedError{err: err}
+}
+
+// IsReported reports whether err was marked by Reported
+func IsReported(err error) bool {
+	var reported *reportedError
+	return errors.As(err, &reported)
+}
+
+// ExitCode maps an error returned by a command to the process exit code.
+// The most specific cause wins: a git failure while validating a snapshot
+// hash is a validation error.
+func ExitCode(err error) int {
+	var (
+		validation *ValidationError
+		notFound   *NotFoundError
+		gitErr     *GitError
+		busy       *ShadowRepoBusyError
+		restore    *RestoreInProgressError
+		plugin     *PluginExitError
+	)
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.As(err, &plugin):
+		return plugin.Code
+	case errors.Is(err, ErrNotInitialized):
+		return ExitNotInitialized
+	case errors.Is(err, ErrNotGitRepository):
+		return ExitNotGitRepo
+	case errors.As(err, &validation), errors.Is(err, ui.ErrNoInput):
+		return ExitValidation
+	case errors.As(err, &notFound):
+		return ExitNotFound
+	case IsLowDiskSpace(err):
+		return ExitLowDiskSpace
+	case IsSecretsDetected(err):
+		return ExitSecrets
+	case errors.As(err, &busy), errors.As(err, &restore), errors.Is(err, ErrWatcherRunning):
+		return ExitBusy
+	case errors.As(err, &gitErr):
+		return ExitGit
+	}
+	return ExitFailure
+}
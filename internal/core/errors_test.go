@@ -0,0 +1,68 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"testing"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"success", nil, ExitOK},
+		{"plain error", errors.New("boom"), ExitFailure},
+		{"not initialized", Reported(ErrNotInitialized), ExitNotInitialized},
+		{"not a git repository", fmt.Errorf("failed to initialize app state: %w", ErrNotGitRepository), ExitNotGitRepo},
+		{"validation", NewValidationError("invalid snapshot hash %q", "-x"), ExitValidation},
+		{"not found", NewNotFoundError("snapshot %s not found", "abc1"), ExitNotFound},
+		{"not found wrapping git", NewNotFoundError("snapshot not found: %w", &GitError{Err: errors.New("exit status 128")}), ExitNotFound},
+		{"git", fmt.Errorf("failed to list snapshots: %w", &GitError{Err: errors.New("exit status 1")}), ExitGit},
+		{"low disk space", &LowDiskSpaceError{Path: "/tmp"}, ExitLowDiskSpace},
+		{"secrets", &SecretsDetectedError{Findings: []SecretFinding{{}}}, ExitSecrets},
+		{"busy", &ShadowRepoBusyError{Locks: []string{"index.lock"}}, ExitBusy},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExitCode(tt.err); got != tt.want {
+				t.Errorf("ExitCode(%v) = %d, want %d", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReported(t *testing.T) {
+	if Reported(nil) != nil {
+		t.Error("Reported(nil) should be nil")
+	}
+	err := Reported(ErrNotInitialized)
+	if !IsReported(err) || IsReported(ErrNotInitialized) {
+		t.Error("IsReported should only recognize errors marked by Reported")
+	}
+	if err.Error() != ErrNotInitialized.Error() {
+		t.Errorf("Reported changed the message to %q", err.Error())
+	}
+}
+
+func TestGitErrorFromCommand(t *testing.T) {
+	tempDir, _, gitManager := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	_, err := gitManager.RunCommand("rev-parse", "--verify", "deadbeef")
+	var gitErr *GitError
+	if !errors.As(err, &gitErr) {
+		t.Fatalf("expected a GitError, got %T: %v", err, err)
+	}
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		t.Error("GitError should wrap the exec error")
+	}
+	if ExitCode(err) != ExitGit {
+		t.Errorf("ExitCode = %d, want %d", ExitCode(err), ExitGit)
+	}
+}
@@ -0,0 +1,122 @@
+package core
+
+import (
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// Watcher event types, published by 'timemachine start --porcelain'
+const (
+	EventSnapshotCreated = "snapshot_created"
+	EventBranchChanged   = "branch_changed"
+	EventFilesIgnored    = "files_ignored"
+	EventChangeStorm     = "change_storm"
+	EventError           = "error"
+)
+
+// Branch changes reported by branch_changed events
+const (
+	BranchCheckout = "checkout" // Another branch was checked out in the project
+	BranchRenamed  = "renamed"  // The shadow branch followed a rename in the project
+	BranchPruned   = "pruned"   // The shadow branch of a deleted branch was pruned
+)
+
+// ignoredReportDelay gathers ignored changes into one files_ignored event
+const ignoredReportDelay = time.Second
+
+// maxIgnoredReported bounds the files listed by one files_ignored event
+const maxIgnoredReported = 1000
+
+// WatcherEvent is something the watcher did. Its JSON form is a stable
+// interface for editor plugins and scripts: fields and event types are only
+// ever added, never renamed or removed.
+type WatcherEvent struct {
+	Type    string    `json:"type"`
+	Time    time.Time `json:"time"`
+	Hash    string    `json:"hash,omitempty"`    // snapshot_created: the new snapshot
+	Message string    `json:"message,omitempty"` // snapshot_created: the snapshot's message; error: what went wrong
+	Files   []string  `json:"files,omitempty"`   // snapshot_created, files_ignored: paths relative to the project root
+	Count   int       `json:"count,omitempty"`   // files_ignored: ignored paths, including any beyond those listed; change_storm: changes during the storm, once ended
+	Action  string    `json:"action,omitempty"`  // branch_changed: checkout, renamed or pruned; change_storm: started or ended
+	Branch  string    `json:"branch,omitempty"`  // branch_changed: the branch checked out, renamed to or pruned
+	From    string    `json:"from,omitempty"`    // branch_changed: the branch checked out or named before
+	Source  string    `json:"source,omitempty"`  // error: snapshot, watcher, branches, integrity or heal
+}
+
+// SetEventHandler registers a callback receiving the watcher's events; call
+// before Start. It is called from several goroutines, one event at a time
+// from each.
+func (w *Watcher) SetEventHandler(fn func(WatcherEvent)) {
+	w.onEvent = fn
+}
+
+// emit publishes an event, if anyone listens
+func (w *Watcher) emit(event WatcherEvent) {
+	if w.onEvent == nil {
+		return
+	}
+	event.Time = time.Now().UTC()
+	w.onEvent(event)
+}
+
+// emitError publishes an error event
+func (w *Watcher) emitError(source string, err error) {
+	w.emit(WatcherEvent{Type: EventError, Source: source, Message: err.Error()})
+}
+
+// noteIgnored records a change to an ignored path, reported together with
+// others after ignoredReportDelay. Only called by the event loop.
+func (w *Watcher) noteIgnored(path string) {
+	if w.onEvent == nil {
+		return
+	}
+	rel, err := filepath.Rel(w.state.ProjectRoot, path)
+	if err != nil {
+		return
+	}
+	if len(w.ignored) == 0 {
+		if w.ignoredTimer == nil {
+			w.ignoredTimer = time.AfterFunc(ignoredReportDelay, func() {
+				select {
+				case w.ignoredDue <- struct{}{}:
+				default: // A report is already due
+				}
+			})
+		} else {
+			w.ignoredTimer.Reset(ignoredReportDelay)
+		}
+	}
+	w.ignored[filepath.ToSlash(rel)] = true
+}
+
+// reportIgnored publishes the ignored changes gathered since the last report
+func (w *Watcher) reportIgnored() {
+	if len(w.ignored) == 0 {
+		return
+	}
+	files := make([]string, 0, len(w.ignored))
+	for file := range w.ignored {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	count := len(files)
+	if count > maxIgnoredReported {
+		files = files[:maxIgnoredReported]
+	}
+	w.ignored = make(map[string]bool)
+	w.emit(WatcherEvent{Type: EventFilesIgnored, Files: files, Count: count})
+}
+
+// followCheckout publishes a branch_changed event when another branch was
+// checked out in the project since the last call
+func (w *Watcher) followCheckout() {
+	branch := projectBranch(w.state.GitDir)
+	if branch == w.branch {
+		return
+	}
+	if w.branch != "" && branch != "" {
+		w.emit(WatcherEvent{Type: EventBranchChanged, Action: BranchCheckout, Branch: branch, From: w.branch})
+	}
+	w.branch = branch
+}
@@ -0,0 +1,80 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+func TestWatcherEvents(t *testing.T) {
+	tempDir, state, gitManager := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	if err := os.WriteFile(filepath.Join(tempDir, DefaultIgnoreFile), []byte(".git/\n*.log\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	watcher, err := NewWatcher(state, gitManager)
+	if err != nil {
+		t.Fatalf("NewWatcher failed: %v", err)
+	}
+	defer watcher.fsWatcher.Close()
+
+	var events []WatcherEvent
+	watcher.SetEventHandler(func(event WatcherEvent) {
+		events = append(events, event)
+	})
+	take := func() []WatcherEvent {
+		taken := events
+		events = nil
+		return taken
+	}
+
+	// Ignored changes are gathered into one event
+	for _, name := range []string{"b.log", "a.log", "b.log"} {
+		watcher.handleEvent(fsnotify.Event{Name: filepath.Join(tempDir, name), Op: fsnotify.Write})
+	}
+	watcher.ignoredTimer.Stop()
+	if len(events) != 0 {
+		t.Fatalf("Expected ignored changes to wait for the report, got %v", events)
+	}
+	watcher.reportIgnored()
+	got := take()
+	if len(got) != 1 || got[0].Type != EventFilesIgnored || !reflect.DeepEqual(got[0].Files, []string{"a.log", "b.log"}) || got[0].Count != 2 {
+		t.Errorf("Expected one files_ignored event for a.log and b.log, got %+v", got)
+	}
+
+	// A snapshot is published with its files
+	if err := os.WriteFile(filepath.Join(tempDir, "main.go"), []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	watcher.handleEvent(fsnotify.Event{Name: filepath.Join(tempDir, "main.go"), Op: fsnotify.Create})
+	watcher.debouncer.Cancel()
+	watcher.createSnapshot()
+	got = take()
+	if len(got) != 1 || got[0].Type != EventSnapshotCreated || got[0].Hash == "" || !reflect.DeepEqual(got[0].Files, []string{"main.go"}) {
+		t.Errorf("Expected a snapshot_created event for main.go, got %+v", got)
+	}
+
+	// Checking out another branch is published, but not the first look
+	watcher.followCheckout()
+	if got := take(); len(got) != 0 {
+		t.Errorf("Expected no event for the branch checked out at start, got %+v", got)
+	}
+	from := watcher.branch
+	if err := os.WriteFile(filepath.Join(state.GitDir, "HEAD"), []byte("ref: refs/heads/feature\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	watcher.followCheckout()
+	got = take()
+	expected := WatcherEvent{Type: EventBranchChanged, Action: BranchCheckout, Branch: "feature", From: from}
+	if len(got) != 1 {
+		t.Fatalf("Expected one branch_changed event, got %+v", got)
+	}
+	got[0].Time = expected.Time
+	if !reflect.DeepEqual(got[0], expected) {
+		t.Errorf("Expected %+v, got %+v", expected, got[0])
+	}
+}
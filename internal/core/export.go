@@ -0,0 +1,348 @@
+package core
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Archive formats for exported snapshots
+const (
+	ArchiveBundle = "bundle" // git bundle, optionally limited to recent snapshots
+	ArchiveTarGz  = "tar.gz" // The shadow repository's refs and objects
+)
+
+// importRef temporarily holds fetched snapshots during an import
+const importRef = "refs/timemachine/import"
+
+// backupRefPrefix keeps the history an import --force replaced reachable
+const backupRefPrefix = "refs/timemachine/backup/"
+
+// InitialSnapshotMessage is the message of the snapshot taken by init
+const InitialSnapshotMessage = "Initial Time Machine snapshot"
+
+// ErrHistoryDiverged is returned when imported and local snapshots don't
+// share a line of history and the import was not forced
+var ErrHistoryDiverged = errors.New("imported snapshots and local snapshots have diverged")
+
+// ArchiveFormat infers the archive format from a file name: .tar.gz and .tgz
+// are tarballs, anything else is a git bundle
+func ArchiveFormat(path string) string {
+	lower := strings.ToLower(path)
+	if strings.HasSuffix(lower, ".tar.gz") || strings.HasSuffix(lower, ".tgz") {
+		return ArchiveTarGz
+	}
+	return ArchiveBundle
+}
+
+// ExportOptions controls ExportSnapshots
+type ExportOptions struct {
+	Since time.Duration // Only snapshots newer than this (bundles only); 0 exports everything
+}
+
+// ExportSnapshots writes the snapshot history to output, in the format given
+// by its extension, and returns the number of snapshots exported. A bundle
+// limited with Since can only be imported where the older snapshots exist.
+func (g *GitManager) ExportSnapshots(output string, opts ExportOptions) (int, error) {
+	format := ArchiveFormat(output)
+	if opts.Since > 0 && format != ArchiveBundle {
+		return 0, fmt.Errorf("--since is only supported for bundle exports")
+	}
+
+	revArgs := []string{"HEAD"}
+	if opts.Since > 0 {
+		since := time.Now().Add(-opts.Since).UTC().Format(time.RFC3339)
+		revArgs = []string{"--since=" + since, "HEAD"}
+	}
+
+	countOutput, err := g.RunCommand(append([]string{"rev-list", "--count"}, revArgs...)...)
+	if err != nil {
+		return 0, fmt.Errorf("no snapshots to export")
+	}
+	count, _ := strconv.Atoi(countOutput)
+	if count == 0 {
+		return 0, fmt.Errorf("no snapshots to export in the selected range")
+	}
+
+	output, err = filepath.Abs(output)
+	if err != nil {
+		return 0, fmt.Errorf("invalid output path: %w", err)
+	}
+
+	if format == ArchiveTarGz {
+		return count, g.writeRepoArchive(output)
+	}
+
+	if _, err := g.RunCommand(append([]string{"bundle", "create", "--quiet", output}, revArgs...)...); err != nil {
+		return 0, fmt.Errorf("failed to create bundle: %w", err)
+	}
+	return count, nil
+}
+
+// writeRepoArchive writes HEAD, refs, packed-refs and objects of the shadow
+// repository to a tar.gz; configuration, index and daemon files stay local
+func (g *GitManager) writeRepoArchive(output string) (err error) {
+	file, err := os.Create(output)
+	if err != nil {
+		return fmt.Errorf("failed to create archive: %w", err)
+	}
+	defer func() {
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(output)
+		}
+	}()
+
+	gz := gzip.NewWriter(file)
+	tw := tar.NewWriter(gz)
+
+	for _, name := range []string{"HEAD", "packed-refs", "refs", "objects"} {
+		root := filepath.Join(g.State.ShadowRepoDir, name)
+		if _, statErr := os.Stat(root); os.IsNotExist(statErr) {
+			continue
+		}
+		err = filepath.WalkDir(root, func(path string, entry fs.DirEntry, walkErr error) error {
+			if walkErr != nil {
+				return walkErr
+			}
+			if strings.HasSuffix(path, ".lock") {
+				return nil // Belongs to a running git process
+			}
+			return addToArchive(tw, g.State.ShadowRepoDir, path, entry)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to archive %s: %w", name, err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+	return nil
+}
+
+func addToArchive(tw *tar.Writer, base, path string, entry fs.DirEntry) error {
+	info, err := entry.Info()
+	if err != nil {
+		return err
+	}
+	if !info.Mode().IsRegular() && !info.IsDir() {
+		return nil
+	}
+
+	rel, err := filepath.Rel(base, path)
+	if err != nil {
+		return err
+	}
+	header, err := tar.FileInfoHeader(info, "")
+	if err != nil {
+		return err
+	}
+	header.Name = filepath.ToSlash(rel)
+	if info.IsDir() {
+		header.Name += "/"
+	}
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = io.Copy(tw, file)
+	return err
+}
+
+// ImportResult describes what ImportSnapshots changed
+type ImportResult struct {
+	Snapshots int    // Snapshots that were new to this repository
+	Head      string // Latest snapshot after the import
+	BackupRef string // Ref holding the replaced history after a forced import
+}
+
+// ImportSnapshots adds the snapshots from a bundle or tar.gz created by
+// ExportSnapshots. Imported history must extend the local history, or the
+// local repository must have nothing but the snapshot taken by init; with
+// force, diverged local history is replaced and kept under
+// refs/timemachine/backup/.
+func (g *GitManager) ImportSnapshots(input string, force bool) (*ImportResult, error) {
+	input, err := filepath.Abs(input)
+	if err != nil {
+		return nil, fmt.Errorf("invalid input path: %w", err)
+	}
+	if _, err := os.Stat(input); err != nil {
+		return nil, fmt.Errorf("cannot read %s: %w", input, err)
+	}
+
+	source := input
+	if ArchiveFormat(input) == ArchiveTarGz {
+		dir, err := os.MkdirTemp("", "timemachine-import-")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+		}
+		defer os.RemoveAll(dir)
+
+		if err := extractRepoArchive(input, dir); err != nil {
+			return nil, err
+		}
+		source = dir
+	} else if _, err := g.RunCommand("bundle", "verify", input); err != nil {
+		if strings.Contains(err.Error(), "prerequisite") {
+			return nil, fmt.Errorf("bundle builds on snapshots this repository doesn't have; import the full export first")
+		}
+		return nil, fmt.Errorf("invalid bundle: %w", err)
+	}
+
+	if _, err := g.RunCommand("fetch", "--quiet", "--no-tags", source, "+HEAD:"+importRef); err != nil {
+		return nil, fmt.Errorf("failed to read snapshots: %w", err)
+	}
+	defer g.RunCommand("update-ref", "-d", importRef)
+
+	imported, err := g.RunCommand("rev-parse", importRef)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read imported snapshots: %w", err)
+	}
+	return g.adoptSnapshots(imported, force)
+}
+
+// adoptSnapshots makes imported, a snapshot fetched from elsewhere, the
+// latest snapshot of the current branch, under the rules of ImportSnapshots
+func (g *GitManager) adoptSnapshots(imported string, force bool) (*ImportResult, error) {
+	result := &ImportResult{Head: imported}
+	local, err := g.RunCommand("rev-parse", "--verify", "--quiet", "HEAD")
+	switch {
+	case err != nil:
+		// No local snapshots yet
+		result.Snapshots = g.countCommits(imported)
+	case g.isAncestor(imported, local):
+		// Everything imported is already here
+		result.Head = local
+		return result, nil
+	case g.isAncestor(local, imported):
+		result.Snapshots = g.countCommits(imported, "^"+local)
+	case g.onlyInitialSnapshot(local):
+		// A fresh init on this machine; its snapshot is retaken from the
+		// working tree by the next snapshot, so nothing is lost
+		result.Snapshots = g.countCommits(imported)
+	case !force:
+		return nil, fmt.Errorf("%w; use --force to replace local snapshots", ErrHistoryDiverged)
+	default:
+		result.BackupRef = backupRefPrefix + strconv.FormatInt(time.Now().Unix(), 10)
+		if _, err := g.RunCommand("update-ref", result.BackupRef, local); err != nil {
+			return nil, fmt.Errorf("failed to back up local snapshots: %w", err)
+		}
+		result.Snapshots = g.countCommits(imported, "^"+local)
+	}
+
+	if _, err := g.RunCommand("update-ref", "-m", "timemachine import", "HEAD", imported); err != nil {
+		return nil, fmt.Errorf("failed to update snapshots: %w", err)
+	}
+	// Match the index to the new latest snapshot so the next snapshot only
+	// records real changes
+	if _, err := g.RunCommand("read-tree", "HEAD"); err != nil {
+		return nil, fmt.Errorf("failed to update index: %w", err)
+	}
+
+	return result, nil
+}
+
+// onlyInitialSnapshot reports whether head is the lone snapshot taken by init
+func (g *GitManager) onlyInitialSnapshot(head string) bool {
+	if g.countCommits(head) != 1 {
+		return false
+	}
+	message, err := g.RunCommand("log", "-1", "--format=%s", head)
+	return err == nil && message == InitialSnapshotMessage
+}
+
+func (g *GitManager) isAncestor(ancestor, descendant string) bool {
+	_, err := g.RunCommand("merge-base", "--is-ancestor", ancestor, descendant)
+	return err == nil
+}
+
+func (g *GitManager) countCommits(revs ...string) int {
+	output, err := g.RunCommand(append([]string{"rev-list", "--count"}, revs...)...)
+	if err != nil {
+		return 0
+	}
+	count, _ := strconv.Atoi(output)
+	return count
+}
+
+// extractRepoArchive unpacks a tar.gz written by writeRepoArchive into dir,
+// rejecting entries that would escape it
+func extractRepoArchive(input, dir string) error {
+	file, err := os.Open(input)
+	if err != nil {
+		return fmt.Errorf("cannot read %s: %w", input, err)
+	}
+	defer file.Close()
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return fmt.Errorf("invalid archive: %w", err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("invalid archive: %w", err)
+		}
+
+		name := filepath.FromSlash(header.Name)
+		if filepath.IsAbs(name) || name == ".." || strings.HasPrefix(filepath.Clean(name), ".."+string(filepath.Separator)) {
+			return fmt.Errorf("invalid archive: unsafe path %q", header.Name)
+		}
+		target := filepath.Join(dir, name)
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return fmt.Errorf("failed to extract archive: %w", err)
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return fmt.Errorf("failed to extract archive: %w", err)
+			}
+			out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+			if err != nil {
+				return fmt.Errorf("failed to extract archive: %w", err)
+			}
+			_, copyErr := io.Copy(out, tr)
+			out.Close()
+			if copyErr != nil {
+				return fmt.Errorf("failed to extract archive: %w", copyErr)
+			}
+		default:
+			return fmt.Errorf("invalid archive: unsupported entry %q", header.Name)
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "HEAD")); err != nil {
+		return fmt.Errorf("invalid archive: not a Time Machine export")
+	}
+	return nil
+}
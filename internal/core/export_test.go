@@ -0,0 +1,208 @@
+package core
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// snapshotFile writes content to file.txt and snapshots it
+func snapshotFile(t testing.TB, dir string, gitManager *GitManager, content string) {
+	if err := os.WriteFile(filepath.Join(dir, "file.txt"), []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := gitManager.CreateSnapshot(content); err != nil {
+		t.Fatalf("CreateSnapshot failed: %v", err)
+	}
+}
+
+// snapshotFileAt is snapshotFile with a fixed commit date
+func snapshotFileAt(t *testing.T, dir string, gitManager *GitManager, content string, date time.Time) {
+	os.Setenv("GIT_COMMITTER_DATE", date.Format(time.RFC3339))
+	defer os.Unsetenv("GIT_COMMITTER_DATE")
+	snapshotFile(t, dir, gitManager, content)
+}
+
+func TestArchiveFormat(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"backup.bundle", ArchiveBundle},
+		{"backup", ArchiveBundle},
+		{"backup.tar.gz", ArchiveTarGz},
+		{"BACKUP.TGZ", ArchiveTarGz},
+	}
+
+	for _, tt := range tests {
+		if got := ArchiveFormat(tt.path); got != tt.expected {
+			t.Errorf("ArchiveFormat(%q): expected %q, got %q", tt.path, tt.expected, got)
+		}
+	}
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	for _, name := range []string{"snapshots.bundle", "snapshots.tar.gz"} {
+		t.Run(name, func(t *testing.T) {
+			srcDir, _, source := setupTestRepo(t)
+			defer os.RemoveAll(srcDir)
+			dstDir, _, target := setupTestRepo(t)
+			defer os.RemoveAll(dstDir)
+
+			snapshotFile(t, srcDir, source, "v1")
+			snapshotFile(t, srcDir, source, "v2")
+
+			archive := filepath.Join(srcDir, name)
+			count, err := source.ExportSnapshots(archive, ExportOptions{})
+			if err != nil {
+				t.Fatalf("ExportSnapshots failed: %v", err)
+			}
+			if count != 2 {
+				t.Errorf("Expected 2 exported snapshots, got %d", count)
+			}
+
+			result, err := target.ImportSnapshots(archive, false)
+			if err != nil {
+				t.Fatalf("ImportSnapshots failed: %v", err)
+			}
+			if result.Snapshots != 2 {
+				t.Errorf("Expected 2 imported snapshots, got %d", result.Snapshots)
+			}
+
+			snapshots, err := target.ListSnapshots(0, "")
+			if err != nil || len(snapshots) != 2 || snapshots[0].Message != "v2" {
+				t.Fatalf("Expected imported snapshots v2, v1, got %v (%v)", snapshots, err)
+			}
+			if refs, _ := target.RunCommand("for-each-ref", "refs/timemachine/"); refs != "" {
+				t.Errorf("Expected temporary import ref to be removed, got %q", refs)
+			}
+
+			// Importing again changes nothing
+			result, err = target.ImportSnapshots(archive, false)
+			if err != nil || result.Snapshots != 0 {
+				t.Errorf("Expected repeated import to be a no-op, got %+v (%v)", result, err)
+			}
+		})
+	}
+}
+
+func TestImportFastForwardsAndRejectsDivergedHistory(t *testing.T) {
+	srcDir, _, source := setupTestRepo(t)
+	defer os.RemoveAll(srcDir)
+	dstDir, _, target := setupTestRepo(t)
+	defer os.RemoveAll(dstDir)
+
+	snapshotFileAt(t, srcDir, source, "v1", time.Now().Add(-48*time.Hour))
+	full := filepath.Join(srcDir, "full.bundle")
+	if _, err := source.ExportSnapshots(full, ExportOptions{}); err != nil {
+		t.Fatalf("ExportSnapshots failed: %v", err)
+	}
+	if _, err := target.ImportSnapshots(full, false); err != nil {
+		t.Fatalf("ImportSnapshots failed: %v", err)
+	}
+
+	// A later partial export applies on top of the earlier import
+	snapshotFile(t, srcDir, source, "v2")
+	recent := filepath.Join(srcDir, "recent.bundle")
+	count, err := source.ExportSnapshots(recent, ExportOptions{Since: 24 * time.Hour})
+	if err != nil {
+		t.Fatalf("ExportSnapshots --since failed: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("Expected 1 recent snapshot, got %d", count)
+	}
+	result, err := target.ImportSnapshots(recent, false)
+	if err != nil || result.Snapshots != 1 {
+		t.Fatalf("Expected fast-forward of 1 snapshot, got %+v (%v)", result, err)
+	}
+
+	// Local snapshots the export doesn't know about make the histories diverge
+	snapshotFile(t, dstDir, target, "local")
+	snapshotFile(t, srcDir, source, "v3")
+	latest := filepath.Join(srcDir, "latest.bundle")
+	if _, err := source.ExportSnapshots(latest, ExportOptions{}); err != nil {
+		t.Fatalf("ExportSnapshots failed: %v", err)
+	}
+	if _, err := target.ImportSnapshots(latest, false); !errors.Is(err, ErrHistoryDiverged) {
+		t.Fatalf("Expected ErrHistoryDiverged, got %v", err)
+	}
+
+	result, err = target.ImportSnapshots(latest, true)
+	if err != nil {
+		t.Fatalf("Forced import failed: %v", err)
+	}
+	if !strings.HasPrefix(result.BackupRef, backupRefPrefix) {
+		t.Errorf("Expected backup ref, got %q", result.BackupRef)
+	}
+	if message, _ := target.RunCommand("log", "-1", "--format=%s", result.BackupRef); message != "local" {
+		t.Errorf("Expected backup ref to keep local snapshot, got %q", message)
+	}
+	if message, _ := target.RunCommand("log", "-1", "--format=%s", "HEAD"); message != "v3" {
+		t.Errorf("Expected imported head v3, got %q", message)
+	}
+}
+
+func TestImportReplacesInitialSnapshot(t *testing.T) {
+	srcDir, _, source := setupTestRepo(t)
+	defer os.RemoveAll(srcDir)
+	dstDir, _, target := setupTestRepo(t)
+	defer os.RemoveAll(dstDir)
+
+	snapshotFile(t, srcDir, source, "v1")
+	archive := filepath.Join(srcDir, "snapshots.bundle")
+	if _, err := source.ExportSnapshots(archive, ExportOptions{}); err != nil {
+		t.Fatalf("ExportSnapshots failed: %v", err)
+	}
+
+	// A freshly initialized clone only has the snapshot taken by init
+	snapshotFile(t, dstDir, target, "clone")
+	if _, err := target.RunCommand("commit", "--amend", "--quiet", "-m", InitialSnapshotMessage); err != nil {
+		t.Fatalf("Failed to reword snapshot: %v", err)
+	}
+
+	result, err := target.ImportSnapshots(archive, false)
+	if err != nil {
+		t.Fatalf("Expected import over initial snapshot, got %v", err)
+	}
+	if result.Snapshots != 1 || result.BackupRef != "" {
+		t.Errorf("Expected 1 snapshot and no backup, got %+v", result)
+	}
+}
+
+func TestImportPartialBundleNeedsEarlierSnapshots(t *testing.T) {
+	srcDir, _, source := setupTestRepo(t)
+	defer os.RemoveAll(srcDir)
+	dstDir, _, target := setupTestRepo(t)
+	defer os.RemoveAll(dstDir)
+
+	snapshotFileAt(t, srcDir, source, "v1", time.Now().Add(-48*time.Hour))
+	snapshotFile(t, srcDir, source, "v2")
+
+	recent := filepath.Join(srcDir, "recent.bundle")
+	if _, err := source.ExportSnapshots(recent, ExportOptions{Since: 24 * time.Hour}); err != nil {
+		t.Fatalf("ExportSnapshots failed: %v", err)
+	}
+
+	_, err := target.ImportSnapshots(recent, false)
+	if err == nil || !strings.Contains(err.Error(), "import the full export first") {
+		t.Errorf("Expected missing prerequisite error, got %v", err)
+	}
+}
+
+func TestExportErrors(t *testing.T) {
+	tempDir, _, gitManager := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	if _, err := gitManager.ExportSnapshots(filepath.Join(tempDir, "empty.bundle"), ExportOptions{}); err == nil {
+		t.Error("Expected error exporting without snapshots")
+	}
+
+	snapshotFile(t, tempDir, gitManager, "v1")
+	_, err := gitManager.ExportSnapshots(filepath.Join(tempDir, "x.tar.gz"), ExportOptions{Since: time.Hour})
+	if err == nil || !strings.Contains(err.Error(), "--since") {
+		t.Errorf("Expected --since error for tar.gz, got %v", err)
+	}
+}
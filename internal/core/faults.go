@@ -0,0 +1,180 @@
+package core
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FaultInjectionEnv must be set to "1" before fault injection can be enabled.
+// This keeps the chaos mode out of reach of regular users and scripts.
+const FaultInjectionEnv = "TIMEMACHINE_ALLOW_FAULT_INJECTION"
+
+// FaultInjector randomly injects failures into the watcher pipeline so the
+// e2e harness can verify recovery without corrupting the shadow repository.
+// All methods are safe to call on a nil injector (no faults).
+type FaultInjector struct {
+	GitDelayProbability     float64       // Chance a git call is delayed
+	MaxGitDelay             time.Duration // Upper bound for injected git delays
+	DropEventProbability    float64       // Chance a file system event is dropped
+	KillDebounceProbability float64       // Chance a pending debounce is cancelled
+
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+var (
+	activeFaults   *FaultInjector
+	activeFaultsMu sync.RWMutex
+)
+
+// EnableFaultInjection parses a spec such as
+// "git-delay=0.3,max-delay=2s,drop-events=0.1,kill-debounce=0.05,seed=42"
+// and activates fault injection process-wide. An empty spec uses defaults.
+func EnableFaultInjection(spec string) (*FaultInjector, error) {
+	if os.Getenv(FaultInjectionEnv) != "1" {
+		return nil, fmt.Errorf("fault injection is disabled; set %s=1 to enable it", FaultInjectionEnv)
+	}
+
+	injector, err := ParseFaultSpec(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	activeFaultsMu.Lock()
+	activeFaults = injector
+	activeFaultsMu.Unlock()
+
+	return injector, nil
+}
+
+// DisableFaultInjection turns fault injection off
+func DisableFaultInjection() {
+	activeFaultsMu.Lock()
+	activeFaults = nil
+	activeFaultsMu.Unlock()
+}
+
+// ParseFaultSpec builds a FaultInjector from a comma-separated key=value spec
+func ParseFaultSpec(spec string) (*FaultInjector, error) {
+	injector := &FaultInjector{
+		GitDelayProbability:     0.2,
+		MaxGitDelay:             2 * time.Second,
+		DropEventProbability:    0.1,
+		KillDebounceProbability: 0.05,
+	}
+	seed := time.Now().UnixNano()
+
+	for _, field := range strings.Split(spec, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+
+		key, value, ok := strings.Cut(field, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid fault spec entry %q (expected key=value)", field)
+		}
+
+		var err error
+		switch key {
+		case "git-delay":
+			injector.GitDelayProbability, err = parseProbability(value)
+		case "max-delay":
+			injector.MaxGitDelay, err = time.ParseDuration(value)
+		case "drop-events":
+			injector.DropEventProbability, err = parseProbability(value)
+		case "kill-debounce":
+			injector.KillDebounceProbability, err = parseProbability(value)
+		case "seed":
+			seed, err = strconv.ParseInt(value, 10, 64)
+		default:
+			return nil, fmt.Errorf("unknown fault spec key %q", key)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for %s: %w", key, err)
+		}
+	}
+
+	injector.rng = rand.New(rand.NewSource(seed))
+	return injector, nil
+}
+
+// String describes the active fault probabilities
+func (f *FaultInjector) String() string {
+	if f == nil {
+		return "disabled"
+	}
+	return fmt.Sprintf("git-delay=%.2f (max %s), drop-events=%.2f, kill-debounce=%.2f",
+		f.GitDelayProbability, f.MaxGitDelay, f.DropEventProbability, f.KillDebounceProbability)
+}
+
+func parseProbability(value string) (float64, error) {
+	p, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, err
+	}
+	if p < 0 || p > 1 {
+		return 0, fmt.Errorf("probability must be between 0 and 1")
+	}
+	return p, nil
+}
+
+// roll returns true with the given probability
+func (f *FaultInjector) roll(probability float64) bool {
+	if f == nil || probability <= 0 {
+		return false
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.rng.Float64() < probability
+}
+
+// delayGit sleeps for a random duration before a git call
+func (f *FaultInjector) delayGit(args []string) {
+	if f == nil || !f.roll(f.GitDelayProbability) || f.MaxGitDelay <= 0 {
+		return
+	}
+	f.mu.Lock()
+	delay := time.Duration(f.rng.Int63n(int64(f.MaxGitDelay)))
+	f.mu.Unlock()
+
+	fmt.Printf("🧪 fault: delaying git %s by %s\n", firstArg(args), delay.Round(time.Millisecond))
+	time.Sleep(delay)
+}
+
+// dropEvent reports whether a file system event should be discarded
+func (f *FaultInjector) dropEvent(path string) bool {
+	if f == nil || !f.roll(f.DropEventProbability) {
+		return false
+	}
+	fmt.Printf("🧪 fault: dropping event for %s\n", path)
+	return true
+}
+
+// killDebounce reports whether the pending debounce should be cancelled
+func (f *FaultInjector) killDebounce() bool {
+	if f == nil || !f.roll(f.KillDebounceProbability) {
+		return false
+	}
+	fmt.Println("🧪 fault: cancelling pending debounce")
+	return true
+}
+
+// faults returns the process-wide injector, or nil when disabled
+func faults() *FaultInjector {
+	activeFaultsMu.RLock()
+	defer activeFaultsMu.RUnlock()
+	return activeFaults
+}
+
+func firstArg(args []string) string {
+	if len(args) == 0 {
+		return ""
+	}
+	return args[0]
+}
@@ -0,0 +1,93 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseFaultSpec(t *testing.T) {
+	testCases := []struct {
+		name    string
+		spec    string
+		wantErr bool
+	}{
+		{name: "empty spec uses defaults", spec: ""},
+		{name: "full spec", spec: "git-delay=0.5,max-delay=100ms,drop-events=0.2,kill-debounce=1,seed=7"},
+		{name: "missing value", spec: "git-delay", wantErr: true},
+		{name: "unknown key", spec: "explode=1", wantErr: true},
+		{name: "probability out of range", spec: "drop-events=1.5", wantErr: true},
+		{name: "invalid duration", spec: "max-delay=soon", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ParseFaultSpec(tc.spec)
+			if tc.wantErr && err == nil {
+				t.Errorf("Expected error for spec %q, got nil", tc.spec)
+			}
+			if !tc.wantErr && err != nil {
+				t.Errorf("Unexpected error for spec %q: %v", tc.spec, err)
+			}
+		})
+	}
+
+	injector, err := ParseFaultSpec("max-delay=100ms,drop-events=0.2")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if injector.MaxGitDelay != 100*time.Millisecond {
+		t.Errorf("Expected max delay 100ms, got %s", injector.MaxGitDelay)
+	}
+	if injector.DropEventProbability != 0.2 {
+		t.Errorf("Expected drop probability 0.2, got %f", injector.DropEventProbability)
+	}
+}
+
+func TestFaultInjectorProbabilities(t *testing.T) {
+	var disabled *FaultInjector
+	if disabled.dropEvent("file.txt") || disabled.killDebounce() {
+		t.Error("Expected nil injector to never inject faults")
+	}
+
+	always, err := ParseFaultSpec("git-delay=0,drop-events=1,kill-debounce=1,seed=1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !always.dropEvent("file.txt") {
+		t.Error("Expected drop-events=1 to always drop events")
+	}
+	if !always.killDebounce() {
+		t.Error("Expected kill-debounce=1 to always cancel the debounce")
+	}
+
+	never, err := ParseFaultSpec("git-delay=0,drop-events=0,kill-debounce=0")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for i := 0; i < 100; i++ {
+		if never.dropEvent("file.txt") || never.killDebounce() {
+			t.Fatal("Expected zero probabilities to never inject faults")
+		}
+	}
+}
+
+func TestEnableFaultInjectionRequiresEnv(t *testing.T) {
+	t.Setenv(FaultInjectionEnv, "")
+	if _, err := EnableFaultInjection(""); err == nil {
+		t.Error("Expected error when fault injection env guard is not set")
+	}
+
+	t.Setenv(FaultInjectionEnv, "1")
+	defer DisableFaultInjection()
+	if _, err := EnableFaultInjection("seed=1"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if faults() == nil {
+		t.Error("Expected fault injection to be active")
+	}
+
+	DisableFaultInjection()
+	if faults() != nil {
+		t.Error("Expected fault injection to be disabled")
+	}
+}
@@ -0,0 +1,111 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FileVersion is one snapshot in which a file changed. Versions are numbered
+// from 1 (oldest), so numbers stay stable as new snapshots are added.
+type FileVersion struct {
+	Number       int       `json:"number"`
+	Hash         string    `json:"hash"`
+	Time         time.Time `json:"time"`
+	RelativeTime string    `json:"-"`
+	Message      string    `json:"message"`
+	Insertions   int       `json:"insertions"`
+	Deletions    int       `json:"deletions"`
+	Binary       bool      `json:"binary,omitempty"`
+	Deleted      bool      `json:"deleted,omitempty"` // The snapshot removed the file
+}
+
+// FileHistory lists the snapshots that changed path (relative to the
+// current directory, absolute, or a :(top,literal) pathspec), newest first.
+// It also returns the path relative to the project root.
+func (g *GitManager) FileHistory(path string) ([]FileVersion, string, error) {
+	rel, err := g.rootRelativePath(path)
+	if err != nil {
+		return nil, "", err
+	}
+	if rel == "" {
+		return nil, "", fmt.Errorf("history needs a file, not the project root")
+	}
+
+	// Records start with a NUL byte; --raw lines carry the change status and
+	// --numstat lines the line counts
+	output, err := g.RunCommand("log", "--raw", "--numstat", "--no-renames",
+		"--format=%x00%H%x1f%ct%x1f%s", "HEAD", "--", g.icasePathspecs(topPathspecs([]string{rel}))[0])
+	if err != nil {
+		if strings.Contains(err.Error(), "does not have any commits yet") || strings.Contains(err.Error(), "unknown revision") {
+			return []FileVersion{}, rel, nil
+		}
+		return nil, rel, fmt.Errorf("failed to read file history: %w", err)
+	}
+
+	return parseFileHistory(output, time.Now()), rel, nil
+}
+
+// parseFileHistory parses the `git log --raw --numstat` output produced by
+// FileHistory (newest first) and numbers the versions oldest first
+func parseFileHistory(output string, now time.Time) []FileVersion {
+	versions := []FileVersion{}
+
+	for _, record := range strings.Split(output, "\x00") {
+		lines := strings.Split(strings.TrimSpace(record), "\n")
+		header := strings.Split(lines[0], "\x1f")
+		if len(header) != 3 {
+			continue
+		}
+
+		version := FileVersion{Hash: header[0], Message: header[2]}
+		if seconds, err := strconv.ParseInt(header[1], 10, 64); err == nil {
+			version.Time = time.Unix(seconds, 0)
+			version.RelativeTime = relativeTime(now, version.Time)
+		}
+
+		deleted, files := 0, 0
+		for _, line := range lines[1:] {
+			if strings.HasPrefix(line, ":") {
+				// ":<old mode> <new mode> <old sha> <new sha> <status>\t<path>"
+				meta, _, _ := strings.Cut(line, "\t")
+				fields := strings.Fields(meta)
+				files++
+				if len(fields) == 5 && fields[4] == "D" {
+					deleted++
+				}
+				continue
+			}
+			if file, ok := parseNumstatLine(line); ok {
+				version.Insertions += file.Insertions
+				version.Deletions += file.Deletions
+				version.Binary = version.Binary || file.Binary
+			}
+		}
+		version.Deleted = files > 0 && deleted == files
+
+		versions = append(versions, version)
+	}
+
+	for i := range versions {
+		versions[i].Number = len(versions) - i
+	}
+
+	return versions
+}
+
+// FileContentAt returns the content of a project-root-relative path as it
+// was in the given snapshot
+func (g *GitManager) FileContentAt(hash, rel string) ([]byte, error) {
+	if strings.HasPrefix(hash, "-") {
+		return nil, NewValidationError("invalid snapshot hash %q", hash)
+	}
+
+	output, err := g.runCommandRaw("show", hash+":"+rel)
+	if err != nil {
+		return nil, fmt.Errorf("%s does not exist in snapshot %s", rel, ShortHash(hash))
+	}
+
+	return output, nil
+}
@@ -0,0 +1,105 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseFileHistory(t *testing.T) {
+	output := "\x00cccc\x1f1700001200\x1fThird\n\n:100644 000000 abc 000 D\tmain.go\n0\t2\tmain.go\n" +
+		"\x00bbbb\x1f1700000600\x1fSecond\n\n:100644 100644 abc def M\tmain.go\n3\t1\tmain.go\n" +
+		"\x00aaaa\x1f1700000000\x1fFirst\n\n:000000 100644 000 abc A\tmain.go\n2\t0\tmain.go\n"
+
+	now := time.Unix(1700001200+300, 0)
+	versions := parseFileHistory(output, now)
+	if len(versions) != 3 {
+		t.Fatalf("Expected 3 versions, got %d", len(versions))
+	}
+
+	expected := []struct {
+		number     int
+		hash       string
+		insertions int
+		deletions  int
+		deleted    bool
+	}{
+		{3, "cccc", 0, 2, true},
+		{2, "bbbb", 3, 1, false},
+		{1, "aaaa", 2, 0, false},
+	}
+	for i, exp := range expected {
+		v := versions[i]
+		if v.Number != exp.number || v.Hash != exp.hash || v.Insertions != exp.insertions ||
+			v.Deletions != exp.deletions || v.Deleted != exp.deleted {
+			t.Errorf("Version %d: expected %+v, got %+v", i, exp, v)
+		}
+	}
+
+	if versions[0].RelativeTime != "5 minutes ago" {
+		t.Errorf("Expected relative time '5 minutes ago', got %q", versions[0].RelativeTime)
+	}
+	if len(parseFileHistory("", now)) != 0 {
+		t.Error("Expected no versions for empty output")
+	}
+}
+
+func TestFileHistory(t *testing.T) {
+	tempDir, _, gitManager := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	target := filepath.Join(tempDir, "notes.txt")
+	other := filepath.Join(tempDir, "other.txt")
+
+	steps := []struct {
+		write   func() error
+		message string
+	}{
+		{func() error { return os.WriteFile(target, []byte("one\n"), 0644) }, "create"},
+		{func() error { return os.WriteFile(other, []byte("unrelated\n"), 0644) }, "other file"},
+		{func() error { return os.WriteFile(target, []byte("one\ntwo\n"), 0644) }, "append"},
+		{func() error { return os.Remove(target) }, "delete"},
+	}
+	for _, step := range steps {
+		if err := step.write(); err != nil {
+			t.Fatalf("Failed to prepare %q: %v", step.message, err)
+		}
+		if err := gitManager.CreateSnapshot(step.message); err != nil {
+			t.Fatalf("Failed to create snapshot: %v", err)
+		}
+	}
+
+	versions, rel, err := gitManager.FileHistory(":(top,literal)notes.txt")
+	if err != nil {
+		t.Fatalf("FileHistory failed: %v", err)
+	}
+	if rel != "notes.txt" {
+		t.Errorf("Expected root-relative path notes.txt, got %q", rel)
+	}
+	if len(versions) != 3 {
+		t.Fatalf("Expected 3 versions (unrelated snapshot excluded), got %d", len(versions))
+	}
+	if !versions[0].Deleted || versions[0].Message != "delete" {
+		t.Errorf("Expected newest version to be the deletion, got %+v", versions[0])
+	}
+	if versions[1].Insertions != 1 || versions[1].Number != 2 {
+		t.Errorf("Expected version 2 to add one line, got %+v", versions[1])
+	}
+
+	content, err := gitManager.FileContentAt(versions[1].Hash, rel)
+	if err != nil {
+		t.Fatalf("FileContentAt failed: %v", err)
+	}
+	if string(content) != "one\ntwo\n" {
+		t.Errorf("Expected exact content with trailing newline, got %q", content)
+	}
+
+	if _, err := gitManager.FileContentAt(versions[0].Hash, rel); err == nil {
+		t.Error("Expected error reading a file from the snapshot that deleted it")
+	}
+
+	if _, _, err := gitManager.FileHistory(":(top,literal)"); err == nil {
+		t.Error("Expected error for the project root")
+	}
+}
@@ -0,0 +1,88 @@
+package core
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// snapshotCommitArgs commit a snapshot without git's own automatic gc,
+// which may detach after any commit and overlap the next snapshot. The
+// watcher runs it between snapshots instead (git.auto_gc).
+var snapshotCommitArgs = []string{"-c", "gc.auto=0", "-c", "maintenance.auto=false", "commit"}
+
+// GCRun describes the watcher's last garbage collection of the shadow
+// repository
+type GCRun struct {
+	At         time.Time `json:"at"`
+	DurationMs int64     `json:"duration_ms"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// AutoGC runs 'git gc --auto' on the shadow repository in the foreground:
+// git repacks only once enough loose objects or packs piled up, so it is
+// cheap to call when there is nothing to do
+func (g *GitManager) AutoGC() error {
+	// gc repacks into new files before deleting old ones; running it on a
+	// nearly full disk can leave the repository half-written
+	if err := g.CheckDiskSpace(); err != nil {
+		return fmt.Errorf("skipped garbage collection: %w", err)
+	}
+	if _, err := g.RunCommand("-c", "gc.autoDetach=false", "gc", "--auto", "--quiet"); err != nil {
+		return fmt.Errorf("failed to garbage-collect shadow repository: %w", err)
+	}
+	return nil
+}
+
+// scheduleGC starts a garbage collection in the background once
+// git.cleanup_threshold snapshots were taken since the last one, when
+// git.auto_gc is set. Called by createSnapshot, under snapshotMu.
+func (w *Watcher) scheduleGC() {
+	cfg := w.state.Config
+	if cfg == nil || !cfg.Git.AutoGC || cfg.Git.CleanupThreshold <= 0 {
+		return
+	}
+	w.snapshotsSinceGC++
+	if w.snapshotsSinceGC < cfg.Git.CleanupThreshold {
+		return
+	}
+
+	select {
+	case <-w.stopChan:
+		return // Stopping; the next watcher catches up
+	default:
+	}
+	w.mu.Lock()
+	running := w.gcRunning
+	w.gcRunning = true
+	w.mu.Unlock()
+	if running {
+		return
+	}
+	w.snapshotsSinceGC = 0
+
+	w.wg.Add(1)
+	go w.runGC()
+}
+
+// runGC garbage-collects the shadow repository. It holds snapshotMu so it
+// never overlaps a snapshot; snapshots that fall due meanwhile wait for it.
+func (w *Watcher) runGC() {
+	defer w.wg.Done()
+	w.snapshotMu.Lock()
+	defer w.snapshotMu.Unlock()
+
+	started := time.Now()
+	err := w.gitManager.AutoGC()
+	run := &GCRun{At: started.UTC(), DurationMs: time.Since(started).Milliseconds()}
+	if err != nil {
+		run.Error = err.Error()
+		color.Yellow("⚠️  %s Garbage collection failed: %v", started.Format("2006-01-02 15:04:05"), err)
+	}
+
+	w.mu.Lock()
+	w.lastGC = run
+	w.gcRunning = false
+	w.mu.Unlock()
+}
@@ -0,0 +1,54 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/config"
+)
+
+func TestWatcherAutoGC(t *testing.T) {
+	tempDir, state, gitManager := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	state.Config = &config.Config{Git: config.GitConfig{AutoGC: true, CleanupThreshold: 2}}
+	watcher, err := NewWatcher(state, gitManager)
+	if err != nil {
+		t.Fatalf("NewWatcher failed: %v", err)
+	}
+	defer watcher.fsWatcher.Close()
+
+	snapshot := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(tempDir, "file.txt"), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		watcher.mu.Lock()
+		watcher.rescan = true
+		watcher.mu.Unlock()
+		watcher.createSnapshot()
+	}
+
+	snapshot("one")
+	if watcher.Stats().LastGC != nil {
+		t.Fatalf("Expected no gc before cleanup_threshold snapshots")
+	}
+
+	snapshot("two")
+	watcher.wg.Wait()
+	lastGC := watcher.Stats().LastGC
+	if lastGC == nil || lastGC.Error != "" {
+		t.Fatalf("Expected a successful gc after 2 snapshots, got %+v", lastGC)
+	}
+
+	// Disabled by git.auto_gc
+	state.Config.Git.AutoGC = false
+	watcher.lastGC = nil
+	snapshot("three")
+	snapshot("four")
+	watcher.wg.Wait()
+	if watcher.Stats().LastGC != nil {
+		t.Errorf("Expected no gc with auto_gc disabled")
+	}
+}
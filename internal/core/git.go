@@ -1,27 +1,109 @@
 package core
 
 import (
+	"bufio"
+	"bytes"
 	"fmt"
 	"os"
 	"os/exec"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 )
 
 // GitManager wraps all Git operations for the shadow repository
 type GitManager struct {
-	State *AppState
+	State   *AppState
+	Tool    string // What triggers snapshots, such as an MCP client, for git.provenance
+	backend GitBackend
 }
 
-// NewGitManager creates a new GitManager with the given state
+// NewGitManager creates a new GitManager with the given state. The backend
+// for snapshot operations is chosen by git.backend (exec when unset).
 func NewGitManager(state *AppState) *GitManager {
-	return &GitManager{State: state}
+	g := &GitManager{State: state}
+
+	backend := BackendExec
+	if state.Config != nil && state.Config.Git.Backend != "" {
+		backend = state.Config.Git.Backend
+	}
+	g.backend = newBackend(g, backend)
+
+	return g
+}
+
+// Backend returns the backend used for snapshot operations
+func (g *GitManager) Backend() GitBackend {
+	return g.backend
+}
+
+// CreateSnapshot creates a new snapshot in the shadow repository. It
+// refuses with a LowDiskSpaceError, before touching the repository, when
+// free space is below git.min_free_space_mb.
+func (g *GitManager) CreateSnapshot(message string) error {
+	if err := g.CheckDiskSpace(); err != nil {
+		return err
+	}
+	return g.backend.CreateSnapshot(message)
+}
+
+// ListSnapshots returns a list of snapshots, optionally filtered by file
+func (g *GitManager) ListSnapshots(limit int, filePath string) ([]Snapshot, error) {
+	return g.FilterSnapshots(SnapshotFilter{Limit: limit, File: filePath})
+}
+
+// FilterSnapshots returns the snapshots matching every set field of filter,
+// newest first. Filtering happens in git log (or the native walk) so it stays
+// fast on long histories.
+func (g *GitManager) FilterSnapshots(filter SnapshotFilter) ([]Snapshot, error) {
+	snapshots := []Snapshot{}
+	err := g.WalkSnapshots(filter, func(snapshot Snapshot) error {
+		snapshots = append(snapshots, snapshot)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return snapshots, nil
+}
+
+// WalkSnapshots calls fn for each snapshot matching filter, newest first, as
+// the history is read; an error from fn stops the walk and is returned. Use it
+// instead of FilterSnapshots when the result may be huge.
+func (g *GitManager) WalkSnapshots(filter SnapshotFilter, fn func(Snapshot) error) error {
+	if strings.HasPrefix(filter.Branch, "-") {
+		return fmt.Errorf("invalid branch name %q", filter.Branch)
+	}
+	if filter.Branch != "" && filter.AllBranches {
+		return fmt.Errorf("a branch and all branches can't be listed at once")
+	}
+	if filter.Limit < 0 || filter.Offset < 0 {
+		return NewValidationError("limit and offset must not be negative")
+	}
+	if filter.Grep != "" {
+		if _, err := regexp.Compile(filter.Grep); err != nil {
+			return fmt.Errorf("invalid --grep pattern: %w", err)
+		}
+	}
+	return g.backend.WalkSnapshots(filter, fn)
+}
+
+// RestoreSnapshot restores files from a specific snapshot to the working
+// tree without touching the staging area
+func (g *GitManager) RestoreSnapshot(hash string, files []string) error {
+	return g.backend.RestoreSnapshot(hash, files)
 }
 
 // RunCommand executes a git command with the shadow repo as the git directory
 // CRITICAL: ALWAYS uses --git-dir and --work-tree to ensure operations
 // happen in shadow repo, not main repo
 func (g *GitManager) RunCommand(args ...string) (string, error) {
+	return g.runCommandEnv(nil, args...)
+}
+
+// runCommandEnv is RunCommand with extra environment variables (KEY=value)
+func (g *GitManager) runCommandEnv(env []string, args ...string) (string, error) {
 	// Build command: git --git-dir=<shadow_repo_path> --work-tree=<project_root> <args>
 	fullArgs := []string{
 		"--git-dir=" + g.State.ShadowRepoDir,
@@ -29,18 +111,70 @@ func (g *GitManager) RunCommand(args ...string) (string, error) {
 	}
 	fullArgs = append(fullArgs, args...)
 	
+	faults().delayGit(args)
+	
 	cmd := exec.Command("git", fullArgs...)
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
 	
 	// Capture both stdout and stderr
 	output, err := cmd.CombinedOutput()
 	
 	if err != nil {
-		return "", fmt.Errorf("git command failed: %s\nOutput: %s", err.Error(), string(output))
+		return "", &GitError{Args: args, Output: string(output), Err: err}
 	}
 	
 	return strings.TrimSpace(string(output)), nil
 }
 
+// runCommandRaw is RunCommand returning stdout byte for byte, for callers
+// that need exact file contents. stderr is only used in the error.
+func (g *GitManager) runCommandRaw(args ...string) ([]byte, error) {
+	fullArgs := []string{
+		"--git-dir=" + g.State.ShadowRepoDir,
+		"--work-tree=" + g.State.ProjectRoot,
+	}
+	fullArgs = append(fullArgs, args...)
+
+	faults().delayGit(args)
+
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", fullArgs...)
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, &GitError{Args: args, Output: stderr.String(), Err: err}
+	}
+
+	return output, nil
+}
+
+// runCommandInput is RunCommand with input fed to git's standard input,
+// returning stdout byte for byte
+func (g *GitManager) runCommandInput(input string, args ...string) ([]byte, error) {
+	fullArgs := []string{
+		"--git-dir=" + g.State.ShadowRepoDir,
+		"--work-tree=" + g.State.ProjectRoot,
+	}
+	fullArgs = append(fullArgs, args...)
+
+	faults().delayGit(args)
+
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", fullArgs...)
+	cmd.Stdin = strings.NewReader(input)
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		return output, &GitError{Args: args, Output: stderr.String(), Err: err}
+	}
+
+	return output, nil
+}
+
 // InitializeShadowRepo creates and initializes the shadow repository
 func (g *GitManager) InitializeShadowRepo() error {
 	// Create .git/timemachine_snapshots directory
@@ -92,16 +226,18 @@ func (g *GitManager) copyGitConfig() error {
 	return nil
 }
 
-// CreateSnapshot creates a new snapshot in the shadow repository
-func (g *GitManager) CreateSnapshot(message string) error {
-	// Stage everything including untracked files
-	_, err := g.RunCommand("add", "-A")
+// CreateSnapshot creates a new snapshot using the git binary
+func (g execBackend) CreateSnapshot(message string) error {
+	// Stage everything including untracked files, within the include paths
+	// if there are any
+	pathspecs := includePathspecs(g.State.IncludePaths)
+	_, err := g.RunCommand(append([]string{"add", "-A"}, pathspecs...)...)
 	if err != nil {
 		return fmt.Errorf("failed to stage files: %w", err)
 	}
 	
 	// Check if there are any changes to commit
-	status, err := g.RunCommand("status", "--porcelain")
+	status, err := g.RunCommand(append([]string{"status", "--porcelain"}, pathspecs...)...)
 	if err != nil {
 		return fmt.Errorf("failed to check status: %w", err)
 	}
@@ -111,83 +247,315 @@ func (g *GitManager) CreateSnapshot(message string) error {
 		return nil
 	}
 	
-	// Use timestamp if no message provided
+	// Changes that only churn are left out; if nothing else changed, there
+	// is nothing to snapshot
+	churned, err := g.unstageChurn()
+	if err != nil {
+		return err
+	}
+	if len(churned) > 0 {
+		staged, err := g.RunCommand("diff", "--cached", "--name-only")
+		if err != nil {
+			return fmt.Errorf("failed to check staged changes: %w", err)
+		}
+		if staged == "" {
+			return nil
+		}
+	}
+	
+	if err := g.checkStagedSecrets(); err != nil {
+		return err
+	}
+	
+	// Use the message template if no message provided
 	if message == "" {
-		now := time.Now()
-		message = fmt.Sprintf("Snapshot at %s", now.Format("15:04:05"))
+		message = g.snapshotMessage(g.stagedFiles())
 	}
+	commits := g.pendingProjectCommits()
 	
 	// Create the commit
-	_, err = g.RunCommand("commit", "-m", message)
+	_, err = g.RunCommand(g.snapshotCommit(g.snapshotMessageWithTrailers(message, commits))...)
 	if err != nil {
 		return fmt.Errorf("failed to create snapshot: %w", err)
 	}
+	g.clearProjectCommits(commits)
 	
 	return nil
 }
 
 // Snapshot represents a Git commit snapshot
 type Snapshot struct {
-	Hash    string // Full commit hash
-	Message string // Commit message
-	Time    string // Relative time (e.g., "2 minutes ago")
+	Hash       string    // Full commit hash
+	ShortHash  string    // Hash abbreviated for display, see ShortHash
+	Message    string    // Commit message
+	Time       string    // Relative time (e.g., "2 minutes ago")
+	Timestamp  time.Time // Commit time (timezone-independent, compare directly)
+	AuthorTime time.Time // When the changes were snapshotted; earlier than Timestamp for imported or rewritten snapshots
+	Parent     string    // The previous snapshot's hash; empty for the first one
+	Branch     string    // Shadow branch it was found on; only set when listing all branches
+
+	// Only set when SnapshotFilter.Stats asks for them
+	FilesChanged int
+	Insertions   int // Lines added; binary files count none
+	Deletions    int
 }
 
-// ListSnapshots returns a list of snapshots, optionally filtered by file
-func (g *GitManager) ListSnapshots(limit int, filePath string) ([]Snapshot, error) {
+// SnapshotFilter selects snapshots; zero fields match everything
+type SnapshotFilter struct {
+	Limit       int       // At most this many, newest first
+	Offset      int       // Skip this many matches first
+	File        string    // Only snapshots that changed this path
+	Pathspecs   []string  // Only snapshots that changed files matching these git pathspecs, with File
+	Branch      string    // Shadow branch to list instead of the current one
+	AllBranches bool      // Every shadow branch, newest first across them
+	Since       time.Time // Committed at or after
+	Until       time.Time // Committed at or before
+	Grep        string    // Case-insensitive regular expression matched against messages
+	Session     string    // Only snapshots tagged with this session ID
+	Agent       string    // Only snapshots taken while this AI agent was running
+	Stats       bool      // Also count the files and lines each snapshot changed, in the same pass
+}
+
+// WalkSnapshots streams snapshots from git log through a pipe, so the first
+// ones arrive before git has walked the whole history
+func (g execBackend) WalkSnapshots(filter SnapshotFilter, fn func(Snapshot) error) error {
 	// Build git log command
-	args := []string{"log", "--oneline", "--date=relative"}
+	args := []string{"log", "--date=relative"}
 	
-	// Add pretty format to get hash, message, and relative time
-	args = append(args, "--pretty=format:%H|%s|%ar")
+	// Add pretty format to get hash, commit time, relative time, author
+	// time, parents and message, preceded by the branch when listing all of
+	// them. Fields are separated by the ASCII unit separator so messages may
+	// contain anything; with stats, records start with a NUL byte so numstat
+	// lines can be told apart.
+	format := "%H%x1f%ct%x1f%ar%x1f%at%x1f%P%x1f%s"
+	if filter.AllBranches {
+		format = "%H%x1f%ct%x1f%ar%x1f%at%x1f%P%x1f%S%x1f%s"
+		args = append(args, "--branches", "--source")
+	}
+	if filter.Stats {
+		format = "%x00" + format
+		args = append(args, "--numstat")
+		if filter.File != "" || len(filter.Pathspecs) > 0 {
+			// Count everything the snapshot changed, not just the file
+			args = append(args, "--full-diff")
+		}
+	}
+	args = append(args, "--pretty=format:"+format)
 	
 	// Add limit if specified
-	if limit > 0 {
-		args = append(args, fmt.Sprintf("-%d", limit))
+	if filter.Limit > 0 {
+		args = append(args, fmt.Sprintf("-%d", filter.Limit))
+	}
+	if filter.Offset > 0 {
+		args = append(args, fmt.Sprintf("--skip=%d", filter.Offset))
+	}
+	if !filter.Since.IsZero() {
+		args = append(args, fmt.Sprintf("--since=%d", filter.Since.Unix()))
+	}
+	if !filter.Until.IsZero() {
+		args = append(args, fmt.Sprintf("--until=%d", filter.Until.Unix()))
+	}
+	if filter.Grep != "" {
+		args = append(args, "--grep="+filter.Grep, "--extended-regexp", "--regexp-ignore-case")
+	}
+	if filter.Session != "" {
+		args = append(args, "--grep=^"+regexp.QuoteMeta(provenanceSessionTrailer+filter.Session)+"$", "--extended-regexp", "--all-match")
+	}
+	if filter.Agent != "" {
+		args = append(args, "--grep=^"+regexp.QuoteMeta(agentTrailer)+"(.*, )?"+regexp.QuoteMeta(filter.Agent)+"(, .*)?$", "--extended-regexp", "--regexp-ignore-case", "--all-match")
+	}
+	if filter.Branch != "" {
+		args = append(args, g.snapshotBranchRef(filter.Branch))
 	}
 	
 	// Add file filter if specified
-	if filePath != "" {
-		args = append(args, "--", filePath)
+	if filter.File != "" || len(filter.Pathspecs) > 0 {
+		args = append(args, "--")
+		if filter.File != "" {
+			args = append(args, filter.File)
+		}
+		args = append(args, filter.Pathspecs...)
 	}
 	
-	output, err := g.RunCommand(args...)
+	faults().delayGit(args)
+	
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", append([]string{
+		"--git-dir=" + g.State.ShadowRepoDir,
+		"--work-tree=" + g.State.ProjectRoot,
+	}, args...)...)
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
 	if err != nil {
-		// If no commits exist yet, return empty slice (not error)
-		if strings.Contains(err.Error(), "does not have any commits yet") {
-			return []Snapshot{}, nil
-		}
-		return nil, fmt.Errorf("failed to list snapshots: %w", err)
+		return fmt.Errorf("failed to list snapshots: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to list snapshots: %w", err)
 	}
 	
-	// Parse output into Snapshot structs
-	lines := strings.Split(strings.TrimSpace(output), "\n")
-	snapshots := make([]Snapshot, 0, len(lines))
-	
-	for _, line := range lines {
-		if line == "" {
-			continue
+	// With stats, a snapshot is complete once the next one starts
+	var pending *Snapshot
+	emit := func(snapshot *Snapshot) error {
+		if snapshot == nil {
+			return nil
+		}
+		return fn(*snapshot)
+	}
+
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if filter.Stats {
+			header, ok := strings.CutPrefix(line, "\x00")
+			if !ok {
+				if file, ok := parseNumstatLine(line); ok && pending != nil {
+					pending.FilesChanged++
+					pending.Insertions += file.Insertions
+					pending.Deletions += file.Deletions
+				}
+				continue
+			}
+			line = header
 		}
-		
-		parts := strings.SplitN(line, "|", 3)
-		if len(parts) != 3 {
+		snapshot, ok := parseSnapshotLine(line, filter.AllBranches)
+		if !ok {
 			continue
 		}
-		
-		snapshots = append(snapshots, Snapshot{
-			Hash:    parts[0],
-			Message: parts[1],
-			Time:    parts[2],
-		})
+		var err error
+		if filter.Stats {
+			err = emit(pending)
+			pending = &snapshot
+		} else {
+			err = fn(snapshot)
+		}
+		if err != nil {
+			// Nothing more is read; don't wait for git to walk the rest
+			cmd.Process.Kill()
+			cmd.Wait()
+			return err
+		}
 	}
 	
-	return snapshots, nil
+	if err := cmd.Wait(); err != nil {
+		// If no commits exist yet, there is nothing to list (not an error)
+		if strings.Contains(stderr.String(), "does not have any commits yet") {
+			return nil
+		}
+		if filter.Branch != "" && strings.Contains(stderr.String(), "unknown revision") {
+			return NewNotFoundError("no snapshots on branch %q", filter.Branch)
+		}
+		return fmt.Errorf("failed to list snapshots: %w", &GitError{Args: args, Output: stderr.String(), Err: err})
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return emit(pending)
+}
+
+// parseSnapshotLine parses one line of WalkSnapshots' git log output
+func parseSnapshotLine(line string, withBranch bool) (Snapshot, bool) {
+	branch := ""
+	if withBranch {
+		fields := strings.SplitN(line, "\x1f", 7)
+		if len(fields) != 7 {
+			return Snapshot{}, false
+		}
+		branch = fields[5]
+		line = strings.Join(append(fields[:5], fields[6]), "\x1f")
+	}
+	parts := strings.SplitN(line, "\x1f", 6)
+	if len(parts) != 6 {
+		return Snapshot{}, false
+	}
+	
+	parent, _, _ := strings.Cut(parts[4], " ")
+	snapshot := Snapshot{
+		Branch:    branch,
+		Hash:      parts[0],
+		ShortHash: ShortHash(parts[0]),
+		Time:      parts[2],
+		Parent:    parent,
+		Message:   parts[5],
+	}
+	if seconds, err := strconv.ParseInt(parts[1], 10, 64); err == nil {
+		snapshot.Timestamp = time.Unix(seconds, 0)
+	}
+	if seconds, err := strconv.ParseInt(parts[3], 10, 64); err == nil {
+		snapshot.AuthorTime = time.Unix(seconds, 0)
+	}
+	return snapshot, true
+}
+
+// CountSnapshotsSince counts snapshots committed at or after the given time.
+// A zero time counts all snapshots.
+func (g *GitManager) CountSnapshotsSince(since time.Time) (int, error) {
+	args := []string{"rev-list", "--count"}
+	if !since.IsZero() {
+		args = append(args, fmt.Sprintf("--since=%d", since.Unix()))
+	}
+	output, err := g.RunCommand(append(args, "HEAD")...)
+	if err != nil {
+		if strings.Contains(err.Error(), "unknown revision") || strings.Contains(err.Error(), "does not have any commits yet") {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to count snapshots: %w", err)
+	}
+
+	count, err := strconv.Atoi(strings.TrimSpace(output))
+	if err != nil {
+		return 0, fmt.Errorf("unexpected rev-list output %q: %w", output, err)
+	}
+
+	return count, nil
+}
+
+// BranchSnapshotCounts counts the snapshots on each branch of the shadow
+// repository
+func (g *GitManager) BranchSnapshotCounts() (map[string]int, error) {
+	output, err := g.RunCommand("for-each-ref", "--format=%(refname:short)", "refs/heads")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list shadow branches: %w", err)
+	}
+
+	counts := make(map[string]int)
+	for _, branch := range strings.Fields(output) {
+		count, err := g.RunCommand("rev-list", "--count", "refs/heads/"+branch)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count snapshots on %s: %w", branch, err)
+		}
+		if counts[branch], err = strconv.Atoi(count); err != nil {
+			return nil, fmt.Errorf("unexpected rev-list output %q: %w", count, err)
+		}
+	}
+
+	return counts, nil
+}
+
+// MainHeadTime returns the commit time of HEAD in the main repository
+func (g *GitManager) MainHeadTime() (time.Time, error) {
+	cmd := exec.Command("git", "--git-dir="+g.State.GitDir, "log", "-1", "--format=%ct", "HEAD")
+	output, err := cmd.Output()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to read main repository HEAD: %w", err)
+	}
+
+	seconds, err := strconv.ParseInt(strings.TrimSpace(string(output)), 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("unexpected commit time %q: %w", output, err)
+	}
+
+	return time.Unix(seconds, 0), nil
 }
 
-// RestoreSnapshot restores files from a specific snapshot
+// RestoreSnapshot restores files from a specific snapshot using the git binary
 // NEVER use checkout or reset - they affect staging area
 // ALWAYS use git restore --source=<hash> --worktree
-func (g *GitManager) RestoreSnapshot(hash string, files []string) error {
+func (g execBackend) RestoreSnapshot(hash string, files []string) error {
+	if !g.State.Capabilities().GitRestore {
+		return g.restoreWithCheckout(hash, files)
+	}
+
 	args := []string{"restore", "--source=" + hash, "--worktree"}
 	
 	if len(files) == 0 {
@@ -204,4 +572,31 @@ func (g *GitManager) RestoreSnapshot(hash string, files []string) error {
 	}
 	
 	return nil
-}
\ No newline at end of file
+}
+
+// restoreWithCheckout is RestoreSnapshot for git older than 2.23, which has no
+// git restore. checkout also writes the index, so it is pointed at a throwaway
+// one to leave the shadow index alone. Unlike git restore, files added after
+// the snapshot are left in place.
+func (g execBackend) restoreWithCheckout(hash string, files []string) error {
+	index, err := os.CreateTemp(g.State.ShadowRepoDir, "restore-index-")
+	if err != nil {
+		return fmt.Errorf("failed to restore snapshot: %w", err)
+	}
+	index.Close()
+	// git wants a valid index or none at all
+	os.Remove(index.Name())
+	defer os.Remove(index.Name())
+
+	args := []string{"checkout", hash, "--"}
+	if len(files) == 0 {
+		args = append(args, ".")
+	} else {
+		args = append(args, files...)
+	}
+
+	if _, err := g.runCommandEnv([]string{"GIT_INDEX_FILE=" + index.Name()}, args...); err != nil {
+		return fmt.Errorf("failed to restore snapshot: %w", err)
+	}
+	return nil
+}
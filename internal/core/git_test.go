@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestGitManager_RunCommand(t *testing.T) {
@@ -176,6 +177,10 @@ func TestGitManager_CreateSnapshot(t *testing.T) {
 		t.Errorf("Expected message 'Test snapshot', got '%s'", snapshots[0].Message)
 	}
 
+	if time.Since(snapshots[0].Timestamp) > time.Minute {
+		t.Errorf("Expected a recent commit timestamp, got %v", snapshots[0].Timestamp)
+	}
+
 	// Test creating snapshot with auto-generated message
 	if err := os.WriteFile(testFile, []byte("Updated content"), 0644); err != nil {
 		t.Fatalf("Failed to update test file: %v", err)
@@ -360,7 +365,7 @@ func TestGitManager_RestoreSnapshot(t *testing.T) {
 }
 
 // Helper function to set up a test repository
-func setupTestRepo(t *testing.T) (string, *AppState, *GitManager) {
+func setupTestRepo(t testing.TB) (string, *AppState, *GitManager) {
 	tempDir, err := os.MkdirTemp("", "timemachine-test")
 	if err != nil {
 		t.Fatalf("Failed to create temp dir: %v", err)
@@ -402,4 +407,35 @@ func setupTestRepo(t *testing.T) (string, *AppState, *GitManager) {
 	}
 
 	return tempDir, state, gitManager
-}
\ No newline at end of file
+}
+func TestGitManager_BranchSnapshotCounts(t *testing.T) {
+	tempDir, _, gitManager := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	snapshotFile(t, tempDir, gitManager, "v1")
+	snapshotFile(t, tempDir, gitManager, "v2")
+	branch, _ := gitManager.RunCommand("symbolic-ref", "--short", "HEAD")
+	if _, err := gitManager.RunCommand("branch", "feature", "HEAD~1"); err != nil {
+		t.Fatalf("Failed to create branch: %v", err)
+	}
+
+	counts, err := gitManager.BranchSnapshotCounts()
+	if err != nil {
+		t.Fatalf("BranchSnapshotCounts failed: %v", err)
+	}
+	if len(counts) != 2 || counts[branch] != 2 || counts["feature"] != 1 {
+		t.Errorf("Expected %s: 2 and feature: 1, got %v", branch, counts)
+	}
+}
+
+func TestGitManager_FilterSnapshotsNegativeLimit(t *testing.T) {
+	tempDir, _, gitManager := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	for _, filter := range []SnapshotFilter{{Limit: -5}, {Offset: -1}} {
+		_, err := gitManager.FilterSnapshots(filter)
+		if ExitCode(err) != ExitValidation {
+			t.Errorf("Expected %+v to be a validation error, got %v", filter, err)
+		}
+	}
+}
@@ -0,0 +1,258 @@
+package core
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// GitignoreFile is the name of git's per-directory ignore file
+const GitignoreFile = ".gitignore"
+
+// gitignorePattern is one line of a .gitignore file, with git's semantics:
+// a pattern containing a slash is matched against the path below the
+// .gitignore's directory, any other pattern against the name at any depth
+type gitignorePattern struct {
+	base     string   // Directory holding the .gitignore, relative to the project root ("" for the root)
+	pattern  string   // Pattern without "!", leading and trailing slashes
+	segments []string // pattern split on "/", for anchored patterns
+	negation bool     // Starts with "!": re-includes matching paths
+	dirOnly  bool     // Ends with "/": only matches directories
+	anchored bool     // Contains a slash: matched against the full path below base
+	original string   // The line as written, for explanations
+	line     int      // Line number in its file (1-based)
+}
+
+// parseGitignoreLine parses a .gitignore line; ok is false for blank lines
+// and comments
+func parseGitignoreLine(line, base string) (gitignorePattern, bool) {
+	line = strings.TrimSuffix(line, "\r")
+	// Trailing spaces are ignored unless escaped with a backslash
+	for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, "\\ ") {
+		line = line[:len(line)-1]
+	}
+	if line == "" || strings.HasPrefix(line, "#") {
+		return gitignorePattern{}, false
+	}
+
+	pattern := gitignorePattern{base: base}
+	if strings.HasPrefix(line, "!") {
+		pattern.negation = true
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		pattern.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if strings.Contains(line, "/") {
+		pattern.anchored = true
+		line = strings.TrimPrefix(line, "/")
+	}
+	if line == "" {
+		return gitignorePattern{}, false
+	}
+
+	pattern.pattern = normalizeClassNegation(line)
+	pattern.segments = strings.Split(pattern.pattern, "/")
+	return pattern, true
+}
+
+// matches reports whether the pattern applies to a project-root-relative path
+func (p gitignorePattern) matches(rel string, isDir bool) bool {
+	if p.dirOnly && !isDir {
+		return false
+	}
+	if p.base != "" {
+		if !strings.HasPrefix(rel, p.base+"/") {
+			return false
+		}
+		rel = rel[len(p.base)+1:]
+	}
+
+	if p.anchored {
+		return matchGlob(p.segments, strings.Split(rel, "/"))
+	}
+	matched, err := path.Match(p.pattern, path.Base(rel))
+	return err == nil && matched
+}
+
+// EnableGitignore makes the manager also honor the project's .gitignore
+// files, nested ones included. .timemachine-ignore patterns are applied on
+// top, so they can still un-ignore paths (e.g. "!dist/").
+func (eim *EnhancedIgnoreManager) EnableGitignore() error {
+	eim.respectGitignore = true
+	eim.ClearCache()
+	return eim.loadNestedIgnores()
+}
+
+// SetRespectGitignore changes whether .gitignore files are honored. The
+// change takes effect on the next ReloadIgnoreFile.
+func (eim *EnhancedIgnoreManager) SetRespectGitignore(respect bool) {
+	eim.respectGitignore = respect
+}
+
+// loadNestedIgnores reads the .timemachine-ignore files below the project
+// root and, when honored, every .gitignore. Parents are read before children
+// so deeper files take precedence. Directories that are already ignored are
+// not descended into, as git doesn't either.
+func (eim *EnhancedIgnoreManager) loadNestedIgnores() error {
+	eim.gitignore = nil
+	eim.nested = nil
+
+	err := filepath.WalkDir(eim.projectRoot, func(dir string, entry fs.DirEntry, err error) error {
+		if err != nil || !entry.IsDir() {
+			return nil // Unreadable entries are skipped, like the watcher does
+		}
+		if entry.Name() == ".git" {
+			return filepath.SkipDir
+		}
+
+		rel := ""
+		if dir != eim.projectRoot {
+			relPath, err := filepath.Rel(eim.projectRoot, dir)
+			if err != nil {
+				return nil
+			}
+			rel = pathKey(filepath.ToSlash(relPath), eim.caseInsensitive)
+			if eim.matchPatterns(rel, true) {
+				return filepath.SkipDir
+			}
+		}
+
+		if eim.respectGitignore {
+			patterns, err := eim.readGitignore(filepath.Join(dir, GitignoreFile), rel)
+			if err != nil {
+				log.Printf("Warning: %v", err)
+			}
+			eim.gitignore = append(eim.gitignore, patterns...)
+		}
+		if rel != "" {
+			patterns, err := eim.readGitignore(filepath.Join(dir, DefaultIgnoreFile), rel)
+			if err != nil {
+				log.Printf("Warning: %v", err)
+			}
+			eim.nested = append(eim.nested, patterns...)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to load nested ignore files: %w", err)
+	}
+
+	if eim.respectGitignore {
+		log.Printf("Loaded %d patterns from %s files", len(eim.gitignore), GitignoreFile)
+	}
+	if len(eim.nested) > 0 {
+		log.Printf("Loaded %d patterns from nested %s files", len(eim.nested), DefaultIgnoreFile)
+	}
+	return nil
+}
+
+// readGitignore parses one .gitignore, or a nested .timemachine-ignore,
+// applying the same size and pattern limits as the root .timemachine-ignore
+func (eim *EnhancedIgnoreManager) readGitignore(file, base string) ([]gitignorePattern, error) {
+	f, err := os.Open(file)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", file, err)
+	}
+	defer f.Close()
+
+	if stat, err := f.Stat(); err == nil && stat.Size() > MaxIgnoreFileSize {
+		return nil, fmt.Errorf("%s too large: %d bytes (max %d bytes)", file, stat.Size(), MaxIgnoreFileSize)
+	}
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, MaxPatternLength), MaxPatternLength)
+
+	var patterns []gitignorePattern
+	for lines := 0; scanner.Scan(); lines++ {
+		if lines >= MaxIgnoreLines || len(patterns) >= MaxPatterns {
+			log.Printf("Warning: %s has too many patterns, ignoring the rest", file)
+			break
+		}
+		if pattern, ok := parseGitignoreLine(pathKey(scanner.Text(), eim.caseInsensitive), base); ok {
+			pattern.original, pattern.line = strings.TrimSpace(scanner.Text()), lines+1
+			patterns = append(patterns, pattern)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return patterns, fmt.Errorf("failed to read %s: %w", file, err)
+	}
+
+	return patterns, nil
+}
+
+// matchGitignore reports whether .gitignore files exclude a
+// project-root-relative path. As in git, nothing inside an ignored directory
+// can be re-included, so parent directories are checked first.
+func (eim *EnhancedIgnoreManager) matchGitignore(rel string, isDir bool) bool {
+	if len(eim.gitignore) == 0 {
+		return false
+	}
+
+	for i := strings.Index(rel, "/"); i >= 0; {
+		if eim.gitignoreDecision(rel[:i], true) {
+			return true
+		}
+		next := strings.Index(rel[i+1:], "/")
+		if next < 0 {
+			break
+		}
+		i += next + 1
+	}
+
+	return eim.gitignoreDecision(rel, isDir)
+}
+
+// gitignoreDecision applies the last matching pattern to a single path
+func (eim *EnhancedIgnoreManager) gitignoreDecision(rel string, isDir bool) bool {
+	ignored := false
+	for _, pattern := range eim.gitignore {
+		if pattern.matches(rel, isDir) {
+			ignored = !pattern.negation
+		}
+	}
+	return ignored
+}
+
+// matchNested applies the nested .timemachine-ignore patterns on top of the
+// verdict so far. As with the root file, a pattern matching a parent
+// directory also matches everything inside it.
+func (eim *EnhancedIgnoreManager) matchNested(rel string, isDir, ignored bool) bool {
+	for _, pattern := range eim.nested {
+		if pattern.matches(rel, isDir) || pattern.matchesParent(rel) {
+			ignored = !pattern.negation
+		}
+	}
+	return ignored
+}
+
+// matchesParent reports whether the pattern matches a directory containing
+// the project-root-relative path, below the pattern's own directory
+func (p gitignorePattern) matchesParent(rel string) bool {
+	start := 0
+	if p.base != "" {
+		if !strings.HasPrefix(rel, p.base+"/") {
+			return false
+		}
+		start = len(p.base) + 1
+	}
+	for {
+		i := strings.Index(rel[start:], "/")
+		if i < 0 {
+			return false
+		}
+		if p.matches(rel[:start+i], true) {
+			return true
+		}
+		start += i + 1
+	}
+}
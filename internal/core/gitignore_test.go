@@ -0,0 +1,213 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseGitignoreLine(t *testing.T) {
+	tests := []struct {
+		line     string
+		ok       bool
+		expected gitignorePattern
+	}{
+		{"", false, gitignorePattern{}},
+		{"# comment", false, gitignorePattern{}},
+		{"*.log", true, gitignorePattern{pattern: "*.log"}},
+		{"build/", true, gitignorePattern{pattern: "build", dirOnly: true}},
+		{"/dist", true, gitignorePattern{pattern: "dist", anchored: true}},
+		{"docs/*.html", true, gitignorePattern{pattern: "docs/*.html", anchored: true}},
+		{"!keep.log", true, gitignorePattern{pattern: "keep.log", negation: true}},
+		{"**/cache/", true, gitignorePattern{pattern: "**/cache", anchored: true, dirOnly: true}},
+		{"trailing   ", true, gitignorePattern{pattern: "trailing"}},
+		{`space\ `, true, gitignorePattern{pattern: `space\ `}},
+		{"/", false, gitignorePattern{}},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseGitignoreLine(tt.line, "")
+		if ok != tt.ok {
+			t.Errorf("parseGitignoreLine(%q): expected ok %v, got %v", tt.line, tt.ok, ok)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if got.pattern != tt.expected.pattern || got.negation != tt.expected.negation ||
+			got.dirOnly != tt.expected.dirOnly || got.anchored != tt.expected.anchored {
+			t.Errorf("parseGitignoreLine(%q): expected %+v, got %+v", tt.line, tt.expected, got)
+		}
+	}
+}
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		name     string
+		expected bool
+	}{
+		{"**/foo", "foo", true},
+		{"**/foo", "a/b/foo", true},
+		{"**/foo", "a/foo/bar", false},
+		{"foo/**", "foo/bar", true},
+		{"foo/**", "foo/a/b", true},
+		{"foo/**", "foo", false},
+		{"a/**/b", "a/b", true},
+		{"a/**/b", "a/x/y/b", true},
+		{"a/**/b", "a/x/c", false},
+		{"src/*.go", "src/main.go", true},
+		{"src/*.go", "src/pkg/main.go", false},
+		{"v[0-9]/out", "v1/out", true},
+		{"v[0-9]/out", "vx/out", false},
+		{`a/\*`, "a/*", true},
+		{`a/\*`, "a/b", false},
+	}
+
+	for _, tt := range tests {
+		got := matchGlob(strings.Split(tt.pattern, "/"), strings.Split(tt.name, "/"))
+		if got != tt.expected {
+			t.Errorf("matchGlob(%q, %q): expected %v, got %v", tt.pattern, tt.name, tt.expected, got)
+		}
+	}
+}
+
+func TestRespectGitignore(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "timemachine-gitignore-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	files := map[string]string{
+		".gitignore":               "node_modules/\n*.log\nbuild/\n/coverage\n**/tmp/**\n",
+		"app/.gitignore":           "!keep.log\ngenerated/\n",
+		"app/generated/.gitignore": "!*\n", // Inside an ignored directory: never read
+		"node_modules/.gitignore":  "!*\n",
+		DefaultIgnoreFile:          "!build/\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(tempDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	manager := NewEnhancedIgnoreManager(tempDir)
+	if manager.ShouldIgnore(filepath.Join(tempDir, "debug.log")) {
+		t.Fatal("Expected .gitignore to be ignored until enabled")
+	}
+	if err := manager.EnableGitignore(); err != nil {
+		t.Fatalf("EnableGitignore failed: %v", err)
+	}
+
+	tests := []struct {
+		path     string
+		isDir    bool
+		expected bool
+	}{
+		{"node_modules", true, true},
+		{"node_modules/pkg/index.js", false, true},
+		{"debug.log", false, true},
+		{"app/debug.log", false, true},
+		{"app/keep.log", false, false}, // Nested negation
+		{"keep.log", false, true},      // Negation only applies below app/
+		{"app/generated/x.go", false, true},
+		{"app/generated", false, false}, // Directory-only pattern, queried as file
+		{"coverage/index.html", false, true},
+		{"app/coverage/index.html", false, false}, // Anchored to the root
+		{"src/tmp/cache/a.bin", false, true},
+		{"build/out.bin", false, false}, // Re-included by .timemachine-ignore
+		{"src/main.go", false, false},
+	}
+
+	for _, tt := range tests {
+		path := filepath.Join(tempDir, filepath.FromSlash(tt.path))
+		var got bool
+		if tt.isDir {
+			got = manager.ShouldIgnoreDirectory(path)
+		} else {
+			got = manager.ShouldIgnoreFile(path)
+		}
+		if got != tt.expected {
+			t.Errorf("ShouldIgnore(%q): expected %v, got %v", tt.path, tt.expected, got)
+		}
+	}
+}
+
+func TestNestedIgnoreFiles(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "timemachine-nested-ignore-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	files := map[string]string{
+		DefaultIgnoreFile:                                  "*.log\nvendor/\n",
+		"packages/web/" + DefaultIgnoreFile:                "dist/\n/local.json\n!keep.log\n",
+		"packages/web/src/" + DefaultIgnoreFile:            "*.snap\n",
+		"packages/api/" + DefaultIgnoreFile:                "*.gen.go\n",
+		"vendor/lib/" + DefaultIgnoreFile:                  "!*\n", // Inside an ignored directory: never read
+		"packages/web/src/components/" + DefaultIgnoreFile: "!*.snap\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(tempDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	manager := NewEnhancedIgnoreManager(tempDir)
+
+	tests := []struct {
+		path     string
+		isDir    bool
+		expected bool
+	}{
+		{"debug.log", false, true},
+		{"packages/web/keep.log", false, false}, // Deeper file re-includes
+		{"packages/api/keep.log", false, true},  // Negation only applies below web/
+		{"packages/web/dist", true, true},
+		{"packages/web/dist/app.js", false, true}, // Inside an ignored directory
+		{"dist/app.js", false, false},             // Scoped to packages/web
+		{"packages/web/local.json", false, true},
+		{"packages/web/src/local.json", false, false}, // Anchored to packages/web
+		{"packages/web/src/a.snap", false, true},
+		{"packages/web/src/components/b.snap", false, false}, // Deepest file wins
+		{"packages/api/types.gen.go", false, true},
+		{"packages/web/types.gen.go", false, false},
+		{"vendor/lib/x.go", false, true},
+		{"packages/web/src/main.ts", false, false},
+	}
+
+	for _, tt := range tests {
+		path := filepath.Join(tempDir, filepath.FromSlash(tt.path))
+		var got bool
+		if tt.isDir {
+			got = manager.ShouldIgnoreDirectory(path)
+		} else {
+			got = manager.ShouldIgnoreFile(path)
+		}
+		if got != tt.expected {
+			t.Errorf("ShouldIgnore(%q): expected %v, got %v", tt.path, tt.expected, got)
+		}
+	}
+
+	// Editing a nested file takes effect on reload
+	if err := os.WriteFile(filepath.Join(tempDir, "packages", "api", DefaultIgnoreFile), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.ReloadIgnoreFile(); err != nil {
+		t.Fatalf("ReloadIgnoreFile failed: %v", err)
+	}
+	if manager.ShouldIgnoreFile(filepath.Join(tempDir, "packages", "api", "types.gen.go")) {
+		t.Errorf("Expected types.gen.go to be watched after emptying its %s", DefaultIgnoreFile)
+	}
+}
@@ -0,0 +1,134 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// healThreshold is how many snapshots in a row must fail before the watcher
+// checks whether the shadow repository itself is broken
+const healThreshold = 3
+
+// shadowRepoEntries are what git keeps in the shadow repository. A heal
+// moves them aside; Time Machine's own files beside them stay.
+var shadowRepoEntries = []string{
+	"HEAD", "config", "description", "index", "packed-refs", "shallow",
+	"ORIG_HEAD", "FETCH_HEAD", "COMMIT_EDITMSG", "hooks", "info", "logs", "objects", "refs",
+}
+
+// ShadowHeal describes the watcher's last re-initialization of a broken
+// shadow repository (git.auto_heal)
+type ShadowHeal struct {
+	At         time.Time `json:"at"`
+	Reason     string    `json:"reason"`               // What was wrong with the shadow repository
+	Quarantine string    `json:"quarantine,omitempty"` // Where what was left of it was moved
+	Error      string    `json:"error,omitempty"`      // Set when re-initializing failed
+}
+
+// CheckShadowRepo returns what keeps the shadow repository from taking
+// snapshots, or nil if it looks healthy
+func (g *GitManager) CheckShadowRepo() error {
+	if _, err := os.Stat(filepath.Join(g.State.ShadowRepoDir, "HEAD")); err != nil {
+		return fmt.Errorf("the shadow repository is missing")
+	}
+	if _, err := g.RunCommand("rev-parse", "--git-dir"); err != nil {
+		return fmt.Errorf("the shadow repository is not a valid Git repository: %w", err)
+	}
+
+	head, err := g.RunCommand("rev-parse", "--verify", "--quiet", "HEAD")
+	if err != nil || head == "" {
+		// A branch without snapshots yet is fine; an unreadable one is not
+		if ref, err := g.RunCommand("symbolic-ref", "HEAD"); err == nil {
+			if hash, err := ResolveRef(g.State.ShadowRepoDir, ref); err == nil && hash == "" {
+				return nil
+			}
+		}
+		return fmt.Errorf("the shadow repository's HEAD is unreadable")
+	}
+	if _, err := g.RunCommand("ls-tree", "-r", "--name-only", head); err != nil {
+		return fmt.Errorf("the latest snapshot is unreadable: %w", err)
+	}
+	return nil
+}
+
+// HealShadowRepo re-initializes a deleted or corrupted shadow repository.
+// What is left of the old one is moved to a quarantine directory beside it,
+// whose path is returned ("" when nothing was left), for recovery by hand.
+func (g *GitManager) HealShadowRepo() (string, error) {
+	quarantine := ""
+	for _, name := range shadowRepoEntries {
+		path := filepath.Join(g.State.ShadowRepoDir, name)
+		if _, err := os.Lstat(path); err != nil {
+			continue
+		}
+		if quarantine == "" {
+			quarantine = fmt.Sprintf("%s.corrupt-%s", g.State.ShadowRepoDir, time.Now().Format("20060102-150405"))
+			if err := os.MkdirAll(quarantine, 0755); err != nil {
+				return "", fmt.Errorf("failed to create quarantine directory: %w", err)
+			}
+		}
+		if err := os.Rename(path, filepath.Join(quarantine, name)); err != nil {
+			return quarantine, fmt.Errorf("failed to move aside %s: %w", name, err)
+		}
+	}
+
+	if err := g.InitializeShadowRepo(); err != nil {
+		return quarantine, err
+	}
+	return quarantine, nil
+}
+
+// snapshotFailed counts a failed snapshot and, once healThreshold failed in
+// a row, checks the shadow repository, re-initializing it when it is broken
+// and git.auto_heal is set. Reports whether it was re-initialized, so the
+// snapshot can be retried. Called by createSnapshot, under snapshotMu.
+func (w *Watcher) snapshotFailed() bool {
+	w.failedSnapshots++
+	if w.failedSnapshots < healThreshold {
+		return false
+	}
+	w.failedSnapshots = 0
+	problem := w.gitManager.CheckShadowRepo()
+	if problem == nil {
+		return false // Failing for another reason
+	}
+
+	timestamp := time.Now().Format("2006-01-02 15:04:05")
+	if cfg := w.state.Config; cfg != nil && !cfg.Git.AutoHeal {
+		if !w.healWarned {
+			w.healWarned = true
+			fmt.Println()
+			color.Red("🚨 %s Snapshots keep failing: %v", timestamp, problem)
+			fmt.Println("   Set git.auto_heal to have the watcher re-create the shadow repository")
+		}
+		w.emitError("heal", problem)
+		return false
+	}
+
+	fmt.Println()
+	quarantine, err := w.gitManager.HealShadowRepo()
+	heal := &ShadowHeal{At: time.Now().UTC(), Reason: problem.Error(), Quarantine: quarantine}
+	if err != nil {
+		heal.Error = err.Error()
+	}
+	w.mu.Lock()
+	w.lastHeal = heal
+	w.mu.Unlock()
+
+	if err != nil {
+		color.Red("🚨 %s Snapshots keep failing: %v, and re-creating the shadow repository failed: %v", timestamp, problem, err)
+		w.emitError("heal", fmt.Errorf("%v; re-creating the shadow repository failed: %w", problem, err))
+		return false
+	}
+	color.Red("🚨 %s Snapshots kept failing: %v", timestamp, problem)
+	color.Red("   The shadow repository was re-created; earlier snapshots are no longer listed")
+	if quarantine != "" {
+		fmt.Printf("   What was left of it is in %s\n", quarantine)
+	}
+	w.emitError("heal", fmt.Errorf("%v; the shadow repository was re-created", problem))
+	return true
+}
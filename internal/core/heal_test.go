@@ -0,0 +1,102 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+func TestCheckAndHealShadowRepo(t *testing.T) {
+	tempDir, state, gitManager := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	if err := gitManager.CheckShadowRepo(); err != nil {
+		t.Errorf("Expected a new shadow repository to be healthy, got %v", err)
+	}
+	os.WriteFile(filepath.Join(tempDir, "main.go"), []byte("package main\n"), 0644)
+	if err := gitManager.CreateSnapshot("first"); err != nil {
+		t.Fatalf("CreateSnapshot failed: %v", err)
+	}
+	if err := gitManager.CheckShadowRepo(); err != nil {
+		t.Errorf("Expected the shadow repository to be healthy, got %v", err)
+	}
+
+	// Lose the latest snapshot's objects
+	if err := os.RemoveAll(filepath.Join(state.ShadowRepoDir, "objects")); err != nil {
+		t.Fatal(err)
+	}
+	os.MkdirAll(filepath.Join(state.ShadowRepoDir, "objects"), 0755)
+	if err := gitManager.CheckShadowRepo(); err == nil {
+		t.Fatalf("Expected missing objects to be detected")
+	}
+
+	// Time Machine's own files survive; git's are moved aside
+	runtimeFile := filepath.Join(state.ShadowRepoDir, "state.json")
+	os.WriteFile(runtimeFile, []byte("{}"), 0644)
+	quarantine, err := gitManager.HealShadowRepo()
+	if err != nil {
+		t.Fatalf("HealShadowRepo failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(quarantine, "HEAD")); err != nil {
+		t.Errorf("Expected the broken repository in %s, got %v", quarantine, err)
+	}
+	if _, err := os.Stat(runtimeFile); err != nil {
+		t.Errorf("Expected state.json to be kept, got %v", err)
+	}
+	if err := gitManager.CheckShadowRepo(); err != nil {
+		t.Errorf("Expected the re-created shadow repository to be healthy, got %v", err)
+	}
+
+	os.RemoveAll(state.ShadowRepoDir)
+	if err := gitManager.CheckShadowRepo(); err == nil || !strings.Contains(err.Error(), "missing") {
+		t.Errorf("Expected a deleted shadow repository to be reported missing, got %v", err)
+	}
+}
+
+func TestWatcherHealsDeletedShadowRepo(t *testing.T) {
+	tempDir, state, gitManager := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	watcher, err := NewWatcher(state, gitManager)
+	if err != nil {
+		t.Fatalf("NewWatcher failed: %v", err)
+	}
+	defer watcher.fsWatcher.Close()
+	var events []WatcherEvent
+	watcher.SetEventHandler(func(event WatcherEvent) {
+		events = append(events, event)
+	})
+
+	if err := os.RemoveAll(state.ShadowRepoDir); err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(tempDir, "main.go")
+	for i := 0; i < healThreshold; i++ {
+		if stats := watcher.Stats(); stats.LastHeal != nil {
+			t.Fatalf("Expected no heal before %d failures, healed after %d", healThreshold, i)
+		}
+		os.WriteFile(file, []byte(strings.Repeat("x", i+1)), 0644)
+		watcher.handleEvent(fsnotify.Event{Name: file, Op: fsnotify.Write})
+		watcher.debouncer.Cancel()
+		watcher.createSnapshot()
+	}
+
+	heal := watcher.Stats().LastHeal
+	if heal == nil || heal.Error != "" || !strings.Contains(heal.Reason, "missing") {
+		t.Fatalf("Expected the shadow repository to be re-created, got %+v", heal)
+	}
+	message, err := gitManager.RunCommand("log", "-1", "--format=%s")
+	if err != nil || !strings.Contains(message, "shadow repository re-created") {
+		t.Errorf("Expected a snapshot right after re-creating, got %q, %v", message, err)
+	}
+	healed := false
+	for _, event := range events {
+		healed = healed || (event.Type == EventError && event.Source == "heal")
+	}
+	if !healed {
+		t.Errorf("Expected a heal error event, got %+v", events)
+	}
+}
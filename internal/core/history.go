@@ -0,0 +1,207 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultSessionGap is the idle time that separates two work sessions
+const DefaultSessionGap = 30 * time.Minute
+
+// FileChange holds line statistics for one file changed in a snapshot. The
+// status fields are only set by DiffParser.
+type FileChange struct {
+	Path       string `json:"path"`
+	Status     string `json:"status,omitempty"`     // A, M, D, R (renamed), C (copied) or T (type changed)
+	OldPath    string `json:"old_path,omitempty"`   // Source of a rename or copy
+	Similarity int    `json:"similarity,omitempty"` // Percent of a rename or copy source kept
+	Insertions int    `json:"insertions"`
+	Deletions  int    `json:"deletions"`
+	Binary     bool   `json:"binary,omitempty"` // Binary files have no line counts
+}
+
+// SnapshotChanges pairs a snapshot with the files it changed
+type SnapshotChanges struct {
+	Hash    string
+	Message string
+	Time    time.Time
+	Files   []FileChange
+}
+
+// Totals sums insertions and deletions across all files
+func (s SnapshotChanges) Totals() (insertions, deletions int) {
+	for _, file := range s.Files {
+		insertions += file.Insertions
+		deletions += file.Deletions
+	}
+	return insertions, deletions
+}
+
+// LogChanges returns snapshots with per-file diffstats in chronological order
+// (oldest first). since may be empty, a snapshot ref, an age such as "2h" or
+// "7d", or a date (YYYY-MM-DD or RFC3339).
+func (g *GitManager) LogChanges(since string) ([]SnapshotChanges, error) {
+	rangeArgs, err := g.sinceArgs(since)
+	if err != nil {
+		return nil, err
+	}
+
+	// Records start with a NUL byte so numstat lines can be told apart
+	args := []string{"log", "--reverse", "--numstat", "--format=%x00%H%x1f%ct%x1f%s"}
+	args = append(args, rangeArgs...)
+
+	output, err := g.RunCommand(args...)
+	if err != nil {
+		if strings.Contains(err.Error(), "does not have any commits yet") {
+			return []SnapshotChanges{}, nil
+		}
+		return nil, fmt.Errorf("failed to read snapshot history: %w", err)
+	}
+
+	return parseLogChanges(output), nil
+}
+
+// sinceArgs converts a --since value into git log arguments
+func (g *GitManager) sinceArgs(since string) ([]string, error) {
+	since = strings.TrimSpace(since)
+	if since == "" {
+		return []string{"HEAD"}, nil
+	}
+
+	// Never let user input be interpreted as a git option
+	if strings.HasPrefix(since, "-") {
+		return nil, fmt.Errorf("invalid --since value %q", since)
+	}
+
+	if t, err := ParseTimeBound(since, false); err == nil {
+		return []string{fmt.Sprintf("--since=%d", t.Unix()), "HEAD"}, nil
+	}
+
+	if _, err := g.RunCommand("rev-parse", "--verify", "--quiet", since+"^{commit}"); err == nil {
+		return []string{since + "..HEAD"}, nil
+	}
+
+	return nil, fmt.Errorf("unrecognized --since value %q (use a snapshot hash, an age like 2h or 7d, or a date)", since)
+}
+
+// parseLogChanges parses `git log --numstat` output produced by LogChanges
+func parseLogChanges(output string) []SnapshotChanges {
+	var snapshots []SnapshotChanges
+
+	for _, record := range strings.Split(output, "\x00") {
+		lines := strings.Split(strings.TrimSpace(record), "\n")
+		header := strings.Split(lines[0], "\x1f")
+		if len(header) != 3 {
+			continue
+		}
+
+		snapshot := SnapshotChanges{
+			Hash:    header[0],
+			Message: header[2],
+		}
+		if seconds, err := strconv.ParseInt(header[1], 10, 64); err == nil {
+			snapshot.Time = time.Unix(seconds, 0)
+		}
+
+		for _, line := range lines[1:] {
+			if file, ok := parseNumstatLine(line); ok {
+				snapshot.Files = append(snapshot.Files, file)
+			}
+		}
+
+		snapshots = append(snapshots, snapshot)
+	}
+
+	return snapshots
+}
+
+// parseNumstatLine parses "<added>\t<deleted>\t<path>"; binary files use "-"
+func parseNumstatLine(line string) (FileChange, bool) {
+	parts := strings.SplitN(line, "\t", 3)
+	if len(parts) != 3 {
+		return FileChange{}, false
+	}
+
+	file := FileChange{Path: parts[2]}
+	if parts[0] == "-" && parts[1] == "-" {
+		file.Binary = true
+		return file, true
+	}
+
+	insertions, err1 := strconv.Atoi(parts[0])
+	deletions, err2 := strconv.Atoi(parts[1])
+	if err1 != nil || err2 != nil {
+		return FileChange{}, false
+	}
+	file.Insertions = insertions
+	file.Deletions = deletions
+
+	return file, true
+}
+
+// GroupSessions splits chronologically ordered snapshots into work sessions:
+// a new session starts whenever the gap since the previous snapshot exceeds gap
+func GroupSessions(snapshots []SnapshotChanges, gap time.Duration) [][]SnapshotChanges {
+	var groups [][]SnapshotChanges
+
+	for i, snapshot := range snapshots {
+		if i == 0 || snapshot.Time.Sub(snapshots[i-1].Time) > gap {
+			groups = append(groups, nil)
+		}
+		groups[len(groups)-1] = append(groups[len(groups)-1], snapshot)
+	}
+
+	return groups
+}
+
+// GroupByDay splits chronologically ordered snapshots by local calendar day
+func GroupByDay(snapshots []SnapshotChanges) [][]SnapshotChanges {
+	var groups [][]SnapshotChanges
+	lastDay := ""
+
+	for _, snapshot := range snapshots {
+		day := snapshot.Time.Local().Format("2006-01-02")
+		if day != lastDay {
+			groups = append(groups, nil)
+			lastDay = day
+		}
+		groups[len(groups)-1] = append(groups[len(groups)-1], snapshot)
+	}
+
+	return groups
+}
+
+// AggregateFiles merges per-file statistics across snapshots, sorted by
+// total churn (largest first)
+func AggregateFiles(snapshots []SnapshotChanges) []FileChange {
+	byPath := make(map[string]*FileChange)
+	var order []string
+
+	for _, snapshot := range snapshots {
+		for _, file := range snapshot.Files {
+			total, exists := byPath[file.Path]
+			if !exists {
+				total = &FileChange{Path: file.Path}
+				byPath[file.Path] = total
+				order = append(order, file.Path)
+			}
+			total.Insertions += file.Insertions
+			total.Deletions += file.Deletions
+			total.Binary = total.Binary || file.Binary
+		}
+	}
+
+	files := make([]FileChange, 0, len(order))
+	for _, path := range order {
+		files = append(files, *byPath[path])
+	}
+
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].Insertions+files[i].Deletions > files[j].Insertions+files[j].Deletions
+	})
+
+	return files
+}
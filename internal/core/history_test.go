@@ -0,0 +1,202 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseLogChanges(t *testing.T) {
+	output := "\x00aaaa\x1f1700000000\x1fFirst snapshot\n\n3\t1\tmain.go\n-\t-\tlogo.png\n" +
+		"\x00bbbb\x1f1700000600\x1fSecond snapshot\n\n10\t0\tdir/new file.txt\n"
+
+	snapshots := parseLogChanges(output)
+	if len(snapshots) != 2 {
+		t.Fatalf("Expected 2 snapshots, got %d", len(snapshots))
+	}
+
+	first := snapshots[0]
+	if first.Hash != "aaaa" || first.Message != "First snapshot" {
+		t.Errorf("Unexpected first snapshot: %+v", first)
+	}
+	if !first.Time.Equal(time.Unix(1700000000, 0)) {
+		t.Errorf("Unexpected time: %v", first.Time)
+	}
+	if len(first.Files) != 2 {
+		t.Fatalf("Expected 2 files, got %d", len(first.Files))
+	}
+	if !first.Files[1].Binary {
+		t.Error("Expected logo.png to be detected as binary")
+	}
+	if insertions, deletions := first.Totals(); insertions != 3 || deletions != 1 {
+		t.Errorf("Expected totals +3/-1, got +%d/-%d", insertions, deletions)
+	}
+
+	if snapshots[1].Files[0].Path != "dir/new file.txt" {
+		t.Errorf("Expected path with spaces to be preserved, got %q", snapshots[1].Files[0].Path)
+	}
+}
+
+func TestGroupSessions(t *testing.T) {
+	base := time.Date(2024, 5, 1, 9, 0, 0, 0, time.Local)
+	snapshots := []SnapshotChanges{
+		{Hash: "a", Time: base},
+		{Hash: "b", Time: base.Add(10 * time.Minute)},
+		{Hash: "c", Time: base.Add(20 * time.Minute)},
+		{Hash: "d", Time: base.Add(2 * time.Hour)},
+		{Hash: "e", Time: base.Add(2*time.Hour + 5*time.Minute)},
+	}
+
+	groups := GroupSessions(snapshots, 30*time.Minute)
+	if len(groups) != 2 {
+		t.Fatalf("Expected 2 sessions, got %d", len(groups))
+	}
+	if len(groups[0]) != 3 || len(groups[1]) != 2 {
+		t.Errorf("Unexpected session sizes: %d and %d", len(groups[0]), len(groups[1]))
+	}
+
+	if groups := GroupSessions(nil, time.Minute); len(groups) != 0 {
+		t.Errorf("Expected no sessions for no snapshots, got %d", len(groups))
+	}
+}
+
+func TestGroupByDay(t *testing.T) {
+	day1 := time.Date(2024, 5, 1, 23, 0, 0, 0, time.Local)
+	snapshots := []SnapshotChanges{
+		{Hash: "a", Time: day1},
+		{Hash: "b", Time: day1.Add(30 * time.Minute)},
+		{Hash: "c", Time: day1.Add(2 * time.Hour)},
+	}
+
+	groups := GroupByDay(snapshots)
+	if len(groups) != 2 {
+		t.Fatalf("Expected 2 days, got %d", len(groups))
+	}
+}
+
+func TestAggregateFiles(t *testing.T) {
+	snapshots := []SnapshotChanges{
+		{Files: []FileChange{{Path: "a.go", Insertions: 1}, {Path: "b.go", Insertions: 5}}},
+		{Files: []FileChange{{Path: "a.go", Insertions: 10, Deletions: 2}}},
+	}
+
+	files := AggregateFiles(snapshots)
+	if len(files) != 2 {
+		t.Fatalf("Expected 2 files, got %d", len(files))
+	}
+	if files[0].Path != "a.go" || files[0].Insertions != 11 || files[0].Deletions != 2 {
+		t.Errorf("Expected a.go with +11/-2 first, got %+v", files[0])
+	}
+}
+
+func TestParseAge(t *testing.T) {
+	day := 24 * time.Hour
+	testCases := []struct {
+		input   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"90min", 90 * time.Minute, false},
+		{"2h", 2 * time.Hour, false},
+		{"7d", 7 * day, false},
+		{"2w", 14 * day, false},
+		{"1m", 30 * day, false},
+		{"3mo", 90 * day, false},
+		{"1y", 365 * day, false},
+		{" 7D ", 7 * day, false},
+		{"", 0, true},
+		{"d", 0, true},
+		{"7", 0, true},
+		{"7x", 0, true},
+		{"-7d", 0, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			got, err := ParseAge(tc.input)
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("ParseAge(%q) expected error, got %v", tc.input, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseAge(%q) unexpected error: %v", tc.input, err)
+			}
+			if got != tc.want {
+				t.Errorf("ParseAge(%q) = %v, want %v", tc.input, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseTimeBound(t *testing.T) {
+	march9 := time.Date(2024, 3, 9, 0, 0, 0, 0, time.Local)
+	testCases := []struct {
+		input string
+		upper bool
+		want  time.Time
+	}{
+		{"2024-03-09", false, march9},
+		{"2024-03-09", true, march9.Add(24*time.Hour - time.Second)},
+		{"2024-03-09 14:30", true, march9.Add(14*time.Hour + 30*time.Minute)},
+		{"2024-03-09T14:30:00Z", false, time.Date(2024, 3, 9, 14, 30, 0, 0, time.UTC)},
+	}
+
+	for _, tc := range testCases {
+		got, err := ParseTimeBound(tc.input, tc.upper)
+		if err != nil {
+			t.Fatalf("ParseTimeBound(%q) unexpected error: %v", tc.input, err)
+		}
+		if !got.Equal(tc.want) {
+			t.Errorf("ParseTimeBound(%q, %t) = %v, want %v", tc.input, tc.upper, got, tc.want)
+		}
+	}
+
+	got, err := ParseTimeBound("2h", false)
+	if err != nil || time.Since(got) < 2*time.Hour || time.Since(got) > 2*time.Hour+time.Minute {
+		t.Errorf("ParseTimeBound(\"2h\") = %v, %v; want two hours ago", got, err)
+	}
+	if _, err := ParseTimeBound("soon", false); err == nil {
+		t.Errorf("ParseTimeBound(\"soon\") expected error")
+	}
+}
+
+func TestGitManager_LogChanges(t *testing.T) {
+	tempDir, _, gitManager := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	for i, content := range []string{"one\n", "one\ntwo\n"} {
+		if err := os.WriteFile(filepath.Join(tempDir, "notes.txt"), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+		if err := gitManager.CreateSnapshot("Snapshot " + string(rune('1'+i))); err != nil {
+			t.Fatalf("Failed to create snapshot: %v", err)
+		}
+	}
+
+	snapshots, err := gitManager.LogChanges("")
+	if err != nil {
+		t.Fatalf("LogChanges failed: %v", err)
+	}
+	if len(snapshots) != 2 {
+		t.Fatalf("Expected 2 snapshots, got %d", len(snapshots))
+	}
+	if snapshots[0].Message != "Snapshot 1" {
+		t.Errorf("Expected oldest snapshot first, got %q", snapshots[0].Message)
+	}
+
+	// Snapshots after the first one only
+	since, err := gitManager.LogChanges(snapshots[0].Hash)
+	if err != nil {
+		t.Fatalf("LogChanges with ref failed: %v", err)
+	}
+	if len(since) != 1 || since[0].Hash != snapshots[1].Hash {
+		t.Errorf("Expected only the second snapshot, got %+v", since)
+	}
+
+	if _, err := gitManager.LogChanges("--all"); err == nil {
+		t.Error("Expected option-like --since value to be rejected")
+	}
+}
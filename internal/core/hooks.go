@@ -0,0 +1,142 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HookEvent is a snapshot lifecycle event that runs the commands configured
+// under the hooks key of the same name
+type HookEvent string
+
+const (
+	HookPreSnapshot  HookEvent = "pre_snapshot"
+	HookPostSnapshot HookEvent = "post_snapshot"
+	HookPostRestore  HookEvent = "post_restore"
+)
+
+// defaultHookTimeout bounds each hook command when no configuration is loaded
+const defaultHookTimeout = time.Minute
+
+// maxHookFiles caps the files listed in TM_CHANGED_FILES, keeping the
+// environment well below operating system limits; TM_CHANGED_COUNT always
+// has the full count
+const maxHookFiles = 1000
+
+// HookContext describes an event to hook commands
+type HookContext struct {
+	Hash         string   // Snapshot taken, or restored from (TM_SNAPSHOT_HASH)
+	Message      string   // Snapshot message (TM_SNAPSHOT_MESSAGE)
+	BackupHash   string   // Pre-restore snapshot (TM_BACKUP_HASH)
+	ChangedFiles []string // Paths relative to the project root (TM_CHANGED_FILES)
+}
+
+// HasHooks reports whether any command is configured for event
+func (g *GitManager) HasHooks(event HookEvent) bool {
+	return len(g.hookCommands(event)) > 0
+}
+
+func (g *GitManager) hookCommands(event HookEvent) []string {
+	if g.State.Config == nil {
+		return nil
+	}
+	switch event {
+	case HookPreSnapshot:
+		return g.State.Config.Hooks.PreSnapshot
+	case HookPostSnapshot:
+		return g.State.Config.Hooks.PostSnapshot
+	case HookPostRestore:
+		return g.State.Config.Hooks.PostRestore
+	}
+	return nil
+}
+
+// RunHooks runs the commands configured for event one after another in the
+// project root, through the shell, with the event described in TM_*
+// environment variables. Output goes to the console (or the daemon log). It
+// stops at the first command that fails or exceeds the hook timeout.
+func (g *GitManager) RunHooks(event HookEvent, hc HookContext) error {
+	commands := g.hookCommands(event)
+	if len(commands) == 0 {
+		return nil
+	}
+
+	timeout := defaultHookTimeout
+	if g.State.Config != nil && g.State.Config.Hooks.Timeout > 0 {
+		timeout = g.State.Config.Hooks.Timeout
+	}
+	env := append(os.Environ(), hookEnv(g.State.ProjectRoot, event, hc)...)
+
+	for _, command := range commands {
+		if err := runHookCommand(command, g.State.ProjectRoot, env, timeout); err != nil {
+			return fmt.Errorf("%s hook %q failed: %w", event, command, err)
+		}
+	}
+	return nil
+}
+
+// hookEnv builds the TM_* variables for an event
+func hookEnv(projectRoot string, event HookEvent, hc HookContext) []string {
+	files := hc.ChangedFiles
+	if len(files) > maxHookFiles {
+		files = files[:maxHookFiles]
+	}
+	return []string{
+		"TM_EVENT=" + string(event),
+		"TM_PROJECT_ROOT=" + projectRoot,
+		"TM_SNAPSHOT_HASH=" + hc.Hash,
+		"TM_SNAPSHOT_MESSAGE=" + hc.Message,
+		"TM_BACKUP_HASH=" + hc.BackupHash,
+		"TM_CHANGED_FILES=" + strings.Join(files, "\n"),
+		"TM_CHANGED_COUNT=" + strconv.Itoa(len(hc.ChangedFiles)),
+	}
+}
+
+func runHookCommand(command, dir string, env []string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	cmd.Dir = dir
+	cmd.Env = env
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	killProcessTree(cmd)
+	// Background processes started by the hook may keep its output open
+	cmd.WaitDelay = time.Second
+
+	err := cmd.Run()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("timed out after %s", timeout)
+	}
+	return err
+}
+
+// SnapshotFiles returns the paths a snapshot changed relative to its parent
+// (every path for the first snapshot)
+func (g *GitManager) SnapshotFiles(hash string) ([]string, error) {
+	output, err := g.RunCommand("diff-tree", "-r", "-z", "--root", "--no-commit-id", "--name-only", "--no-renames", hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshot changes: %w", err)
+	}
+
+	var files []string
+	for _, path := range strings.Split(output, "\x00") {
+		if path != "" {
+			files = append(files, path)
+		}
+	}
+	return files, nil
+}
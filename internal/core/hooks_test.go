@@ -0,0 +1,68 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/config"
+)
+
+func TestRunHooks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hook commands in this test use sh")
+	}
+	tempDir, state, gitManager := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	state.Config = &config.Config{Hooks: config.HooksConfig{
+		PostSnapshot: []string{
+			`printf '%s|%s|%s|%s' "$TM_EVENT" "$TM_SNAPSHOT_HASH" "$TM_CHANGED_COUNT" "$TM_CHANGED_FILES" > hook.out`,
+		},
+		PreSnapshot: []string{"exit 3", "touch not-reached"},
+		PostRestore: []string{"sleep 5"},
+		Timeout:     200 * time.Millisecond,
+	}}
+
+	err := gitManager.RunHooks(HookPostSnapshot, HookContext{Hash: "abc123", ChangedFiles: []string{"a.txt", "dir/b.txt"}})
+	if err != nil {
+		t.Fatalf("RunHooks failed: %v", err)
+	}
+	output, _ := os.ReadFile(filepath.Join(tempDir, "hook.out"))
+	if expected := "post_snapshot|abc123|2|a.txt\ndir/b.txt"; string(output) != expected {
+		t.Errorf("Expected hook to see %q, got %q", expected, output)
+	}
+
+	if err := gitManager.RunHooks(HookPreSnapshot, HookContext{}); err == nil {
+		t.Errorf("Expected a failing hook to return an error")
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, "not-reached")); err == nil {
+		t.Errorf("Expected hooks to stop at the first failure")
+	}
+
+	if err := gitManager.RunHooks(HookPostRestore, HookContext{}); err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("Expected a timeout error, got %v", err)
+	}
+}
+
+func TestSnapshotFiles(t *testing.T) {
+	tempDir, _, gitManager := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	snapshotFile(t, tempDir, gitManager, "v1")
+	os.WriteFile(filepath.Join(tempDir, "other.txt"), []byte("other"), 0644)
+	snapshotFile(t, tempDir, gitManager, "v2")
+
+	hash, _ := gitManager.RunCommand("rev-parse", "HEAD")
+	files, err := gitManager.SnapshotFiles(hash)
+	if err != nil {
+		t.Fatalf("SnapshotFiles failed: %v", err)
+	}
+	if expected := []string{"file.txt", "other.txt"}; !reflect.DeepEqual(files, expected) {
+		t.Errorf("Expected %v, got %v", expected, files)
+	}
+}
@@ -0,0 +1,17 @@
+//go:build !windows
+
+package core
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// killProcessTree makes cancelling cmd kill everything the hook started, not
+// just the shell, by running it in its own process group
+func killProcessTree(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+}
@@ -0,0 +1,9 @@
+//go:build windows
+
+package core
+
+import "os/exec"
+
+// killProcessTree is a no-op on Windows: cancelling cmd kills the shell,
+// and WaitDelay stops waiting for anything it left running
+func killProcessTree(cmd *exec.Cmd) {}
@@ -2,9 +2,11 @@ package core
 
 import (
 	"bufio"
+	"container/list"
 	"fmt"
 	"log"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 	"sync"
@@ -29,40 +31,73 @@ type IgnorePattern struct {
 	IsNegation  bool   // Pattern starts with !
 	IsDirectory bool   // Pattern ends with /
 	IsAbsolute  bool   // Pattern starts with /
-	IsSimple    bool   // No wildcards (fast path)
+	IsSimple    bool   // No wildcards or escapes (fast path)
+	HasGlobstar bool   // Has a "**" segment, matching any number of directories
+	Line        int    // Line in the ignore file, or position in its source's list (1-based)
 }
 
 // EnhancedIgnoreManager provides high-performance ignore pattern matching
 // with Git-inspired optimizations and thread-safe caching
 type EnhancedIgnoreManager struct {
 	// Core data
-	patterns    []IgnorePattern
-	projectRoot string
-	ignoreFile  string
-
-	// Performance cache (thread-safe)
-	pathCache   map[string]bool
-	cacheMutex  sync.RWMutex
-	cacheMemory int64
+	patterns        []IgnorePattern
+	projectRoot     string
+	ignoreFile      string
+	caseInsensitive bool // Match case-folded, as the filesystem does
+
+	// .gitignore patterns, honored when watcher.respect_gitignore is set
+	gitignore        []gitignorePattern
+	respectGitignore bool
+
+	// Patterns from .timemachine-ignore files in subdirectories, scoped to
+	// their directory and applied after the root file, parents first
+	nested []gitignorePattern
+
+	// Patterns from watcher.ignore_patterns, TIMEMACHINE_WATCHER_IGNORE and
+	// start --ignore, keyed by source (see SetPatterns)
+	extra map[string][]IgnorePattern
+
+	// Performance cache (thread-safe). cacheOrder holds the entries most
+	// recently used first, or most recently added first without LRU.
+	pathCache       map[string]*list.Element
+	cacheOrder      *list.List
+	cacheMaxEntries int
+	cacheLRU        bool
+	cacheMutex      sync.RWMutex
+	cacheMemory     int64
 
 	// Statistics (for monitoring/debugging)
-	cacheHits   int64
-	cacheMisses int64
-	totalChecks int64
+	cacheHits      int64
+	cacheMisses    int64
+	cacheEvictions int64
+	totalChecks    int64
+}
+
+// cacheEntry is a cached ignore result
+type cacheEntry struct {
+	key     string
+	ignored bool
 }
 
 // NewEnhancedIgnoreManager creates a new enhanced ignore manager with caching
 func NewEnhancedIgnoreManager(projectRoot string) *EnhancedIgnoreManager {
 	manager := &EnhancedIgnoreManager{
-		projectRoot: projectRoot,
-		ignoreFile:  filepath.Join(projectRoot, DefaultIgnoreFile),
-		pathCache:   make(map[string]bool),
+		projectRoot:     projectRoot,
+		ignoreFile:      filepath.Join(projectRoot, DefaultIgnoreFile),
+		caseInsensitive: IsCaseInsensitiveFS(projectRoot),
+		pathCache:       make(map[string]*list.Element),
+		cacheOrder:      list.New(),
+		cacheMaxEntries: MaxPathCacheEntries,
+		cacheLRU:        true,
 	}
 
 	// Load patterns from .timemachine-ignore file
 	if err := manager.loadIgnoreFile(); err != nil {
 		log.Printf("Warning: Failed to load ignore patterns: %v", err)
 	}
+	if err := manager.loadNestedIgnores(); err != nil {
+		log.Printf("Warning: %v", err)
+	}
 
 	return manager
 }
@@ -106,7 +141,7 @@ func (eim *EnhancedIgnoreManager) loadIgnoreFile() error {
 			break
 		}
 
-		line := strings.TrimSpace(scanner.Text())
+		line := trimPatternLine(scanner.Text())
 		
 		// Skip empty lines and comments
 		if line == "" || strings.HasPrefix(line, "#") {
@@ -132,6 +167,7 @@ func (eim *EnhancedIgnoreManager) loadIgnoreFile() error {
 			break
 		}
 
+		pattern.Line = lineCount
 		eim.patterns = append(eim.patterns, pattern)
 		patternCount++
 	}
@@ -150,15 +186,17 @@ func (eim *EnhancedIgnoreManager) parsePattern(line string) (IgnorePattern, erro
 		return IgnorePattern{}, fmt.Errorf("empty pattern")
 	}
 
+	// On case-insensitive filesystems "Build/" must also ignore "build/";
+	// paths are folded the same way in ShouldIgnore
 	pattern := IgnorePattern{
 		Original: line,
-		Pattern:  line,
+		Pattern:  pathKey(line, eim.caseInsensitive),
 	}
 
 	// Handle negation (!)
 	if strings.HasPrefix(line, "!") {
 		pattern.IsNegation = true
-		pattern.Pattern = line[1:]
+		pattern.Pattern = pattern.Pattern[1:]
 		if pattern.Pattern == "" {
 			return IgnorePattern{}, fmt.Errorf("empty negation pattern")
 		}
@@ -176,43 +214,88 @@ func (eim *EnhancedIgnoreManager) parsePattern(line string) (IgnorePattern, erro
 		pattern.Pattern = strings.TrimPrefix(pattern.Pattern, "/")
 	}
 
-	// Check if pattern is simple (no wildcards) for fast path
-	pattern.IsSimple = !strings.ContainsAny(pattern.Pattern, "*?[]")
+	// Check if pattern is simple (no wildcards or escapes) for fast path
+	pattern.Pattern = normalizeClassNegation(pattern.Pattern)
+	pattern.IsSimple = !strings.ContainsAny(pattern.Pattern, "*?[]\\")
 
 	// Basic validation
 	if pattern.Pattern == "" {
 		return IgnorePattern{}, fmt.Errorf("empty pattern after processing")
 	}
 
+	for _, segment := range strings.Split(pattern.Pattern, "/") {
+		if segment == "**" {
+			pattern.HasGlobstar = true
+		} else if _, err := path.Match(segment, ""); err != nil {
+			return IgnorePattern{}, fmt.Errorf("malformed pattern: %w", err)
+		}
+	}
+
 	return pattern, nil
 }
 
+// trimPatternLine strips surrounding whitespace from an ignore file line,
+// keeping trailing spaces escaped with a backslash as gitignore does
+func trimPatternLine(line string) string {
+	line = strings.TrimLeft(line, " \t")
+	for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, "\\ ") ||
+		strings.HasSuffix(line, "\t") || strings.HasSuffix(line, "\r") {
+		line = line[:len(line)-1]
+	}
+	return line
+}
+
+// normalizeClassNegation rewrites gitignore's "[!a-z]" negated character
+// classes to the "[^a-z]" form path.Match understands
+func normalizeClassNegation(pattern string) string {
+	if !strings.Contains(pattern, "[!") {
+		return pattern
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		b.WriteByte(c)
+		switch {
+		case c == '\\' && i+1 < len(pattern):
+			i++
+			b.WriteByte(pattern[i])
+		case c == '[' && i+1 < len(pattern) && pattern[i+1] == '!':
+			b.WriteByte('^')
+			i++
+		}
+	}
+	return b.String()
+}
+
 // ShouldIgnore determines if a file path should be ignored
 // This is the main entry point called by the watcher
 func (eim *EnhancedIgnoreManager) ShouldIgnore(path string) bool {
-	// Convert to relative path
-	relPath, err := filepath.Rel(eim.projectRoot, path)
+	// ShouldIgnoreDirectory marks directories with a trailing slash
+	isDir := strings.HasSuffix(path, "/")
+
+	// Convert to relative path; on case-insensitive filesystems the root may
+	// be spelled with different case too, so compare both sides folded
+	relPath, err := filepath.Rel(pathKey(eim.projectRoot, eim.caseInsensitive), pathKey(path, eim.caseInsensitive))
 	if err != nil {
 		relPath = path // Fallback to absolute path
 	}
 	relPath = filepath.ToSlash(relPath) // Normalize path separators
 
-	// Check cache first (thread-safe read)
-	eim.cacheMutex.RLock()
-	result, exists := eim.pathCache[relPath]
-	eim.cacheMutex.RUnlock()
-
-	if exists {
-		// Thread-safe counter increment
-		eim.cacheMutex.Lock()
-		eim.cacheHits++
-		eim.totalChecks++
-		eim.cacheMutex.Unlock()
+	// Directory-only patterns can give a directory a different answer than
+	// a file of the same name
+	cacheKey := relPath
+	if isDir {
+		cacheKey += "/"
+	}
+
+	// Check cache first
+	if result, exists := eim.lookupCache(cacheKey); exists {
 		return result
 	}
 
 	// Compute result
-	result = eim.matchPatterns(relPath)
+	result := eim.matchPatterns(relPath, isDir)
 
 	// Cache result and update stats (thread-safe)
 	eim.cacheMutex.Lock()
@@ -220,36 +303,37 @@ func (eim *EnhancedIgnoreManager) ShouldIgnore(path string) bool {
 	eim.totalChecks++
 	eim.cacheMutex.Unlock()
 	
-	eim.addToCache(relPath, result)
+	eim.addToCache(cacheKey, result)
 
 	return result
 }
 
 // matchPatterns checks if a path matches any ignore patterns
-func (eim *EnhancedIgnoreManager) matchPatterns(relPath string) bool {
-	filename := filepath.Base(relPath)
-	dirname := filepath.Dir(relPath)
-	
-	// Process patterns in order (later patterns can override earlier ones)
-	ignored := false
+func (eim *EnhancedIgnoreManager) matchPatterns(relPath string, isDir bool) bool {
+	// Process patterns in order (later patterns can override earlier ones),
+	// starting from the .gitignore verdict so "!pattern" can re-include
+	ignored := eim.respectGitignore && eim.matchGitignore(relPath, isDir)
+	ignored = eim.applyExtra(IgnoreSourceConfig, relPath, ignored)
 	
 	for _, pattern := range eim.patterns {
-		var matched bool
-		
-		if pattern.IsDirectory {
-			// Directory pattern: check against directory components
-			matched = eim.matchDirectoryPattern(pattern, relPath, dirname)
-		} else {
-			// File pattern: check against filename or full path
-			matched = eim.matchFilePattern(pattern, relPath, filename)
-		}
-		
-		if matched {
+		if eim.matchPattern(pattern, relPath) {
 			ignored = !pattern.IsNegation // Negation patterns un-ignore
 		}
 	}
 	
-	return ignored
+	ignored = eim.matchNested(relPath, isDir, ignored)
+	ignored = eim.applyExtra(IgnoreSourceEnv, relPath, ignored)
+	return eim.applyExtra(IgnoreSourceFlags, relPath, ignored)
+}
+
+// matchPattern checks a path against one pattern, negated or not
+func (eim *EnhancedIgnoreManager) matchPattern(pattern IgnorePattern, relPath string) bool {
+	if pattern.IsDirectory {
+		// Directory pattern: check against directory components
+		return eim.matchDirectoryPattern(pattern, relPath, filepath.Dir(relPath))
+	}
+	// File pattern: check against filename or full path
+	return eim.matchFilePattern(pattern, relPath, filepath.Base(relPath))
 }
 
 // matchFilePattern matches a file pattern against a path
@@ -260,8 +344,7 @@ func (eim *EnhancedIgnoreManager) matchFilePattern(pattern IgnorePattern, relPat
 			// Check if path starts with pattern (for directories) or equals pattern (for files)
 			return strings.HasPrefix(relPath, pattern.Pattern+"/") || relPath == pattern.Pattern
 		}
-		matched, err := filepath.Match(pattern.Pattern, relPath)
-		return err == nil && matched
+		return matchPathOrParent(pattern, relPath)
 	}
 
 	// For non-absolute patterns, match against filename or check if file is within pattern directory
@@ -276,14 +359,13 @@ func (eim *EnhancedIgnoreManager) matchFilePattern(pattern IgnorePattern, relPat
 		return filename == pattern.Pattern
 	}
 
-	// Use Go's filepath.Match for wildcard patterns
-	// If pattern contains slash, match against full relative path, otherwise just filename
-	matchTarget := filename
+	// Wildcard patterns with a slash match the path from the root (like the
+	// simple case above, a matching directory covers its contents); others
+	// only the filename
 	if strings.Contains(pattern.Pattern, "/") {
-		matchTarget = relPath
+		return matchPathOrParent(pattern, relPath)
 	}
-	matched, err := filepath.Match(pattern.Pattern, matchTarget)
-	return err == nil && matched
+	return matchWildcard(pattern, filename)
 }
 
 // matchDirectoryPattern matches a directory pattern against a path
@@ -295,8 +377,7 @@ func (eim *EnhancedIgnoreManager) matchDirectoryPattern(pattern IgnorePattern, r
 			       dirname == pattern.Pattern ||
 			       relPath == pattern.Pattern
 		}
-		matched, err := filepath.Match(pattern.Pattern, dirname)
-		return err == nil && matched
+		return matchPathOrParent(pattern, relPath)
 	}
 
 	// For non-absolute directory patterns, match against any directory component
@@ -308,10 +389,15 @@ func (eim *EnhancedIgnoreManager) matchDirectoryPattern(pattern IgnorePattern, r
 		       relPath == pattern.Pattern  // Match the directory name itself
 	}
 
+	// Patterns like "**/cache/" or "src/*/tmp/" match from the root
+	if strings.Contains(pattern.Pattern, "/") {
+		return matchPathOrParent(pattern, relPath)
+	}
+
 	// Check each directory component with wildcards
 	dirs := strings.Split(dirname, "/")
 	for _, dir := range dirs {
-		if matched, err := filepath.Match(pattern.Pattern, dir); err == nil && matched {
+		if matchWildcard(pattern, dir) {
 			return true
 		}
 	}
@@ -319,45 +405,156 @@ func (eim *EnhancedIgnoreManager) matchDirectoryPattern(pattern IgnorePattern, r
 	return false
 }
 
-// addToCache adds a result to the cache with memory management
+// matchPathOrParent matches a wildcard pattern against a root-relative path
+// and each of its parent directories, so a matching directory also covers
+// everything inside it
+func matchPathOrParent(pattern IgnorePattern, relPath string) bool {
+	if pattern.HasGlobstar {
+		for i := 0; i < len(relPath); i++ {
+			if relPath[i] == '/' && matchWildcard(pattern, relPath[:i]) {
+				return true
+			}
+		}
+		return matchWildcard(pattern, relPath)
+	}
+
+	// Without "**" only the prefix with as many segments as the pattern can
+	// match, so a single comparison is enough
+	slashes := strings.Count(pattern.Pattern, "/")
+	for i := 0; i < len(relPath); i++ {
+		if relPath[i] == '/' {
+			if slashes == 0 {
+				return matchWildcard(pattern, relPath[:i])
+			}
+			slashes--
+		}
+	}
+	return slashes == 0 && matchWildcard(pattern, relPath)
+}
+
+// matchWildcard matches a wildcard pattern against a name or slash-separated
+// path. path.Match handles *, ?, [a-z] classes and backslash escapes (and,
+// unlike filepath.Match, behaves the same on Windows); "**" segments need
+// matchGlob.
+func matchWildcard(pattern IgnorePattern, name string) bool {
+	if pattern.HasGlobstar {
+		return matchGlob(strings.Split(pattern.Pattern, "/"), strings.Split(name, "/"))
+	}
+	matched, err := path.Match(pattern.Pattern, name)
+	return err == nil && matched
+}
+
+// matchGlob matches path segments against pattern segments. "**" matches
+// any number of segments (at least one when it ends the pattern, so "dir/**"
+// matches what is inside dir but not dir itself); other segments use
+// path.Match.
+func matchGlob(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			if len(pattern) == 1 {
+				return len(name) > 0
+			}
+			for i := 0; i <= len(name); i++ {
+				if matchGlob(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if len(name) == 0 {
+			return false
+		}
+		if matched, err := path.Match(pattern[0], name[0]); err != nil || !matched {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// ConfigureCache applies the cache.max_entries and cache.enable_lru
+// settings. Without LRU the oldest entries are evicted first, however often
+// they are used. maxEntries <= 0 keeps the current limit.
+func (eim *EnhancedIgnoreManager) ConfigureCache(maxEntries int, lru bool) {
+	eim.cacheMutex.Lock()
+	defer eim.cacheMutex.Unlock()
+
+	if maxEntries > 0 {
+		eim.cacheMaxEntries = maxEntries
+	}
+	eim.cacheLRU = lru
+	for eim.cacheOrder != nil && eim.cacheOrder.Len() > eim.cacheMaxEntries {
+		eim.evictCacheEntry()
+	}
+}
+
+// lookupCache returns the cached result for a key, counting the check and
+// marking the entry as recently used
+func (eim *EnhancedIgnoreManager) lookupCache(key string) (result, exists bool) {
+	eim.cacheMutex.Lock()
+	defer eim.cacheMutex.Unlock()
+
+	element, exists := eim.pathCache[key]
+	if !exists {
+		return false, false
+	}
+	if eim.cacheLRU {
+		eim.cacheOrder.MoveToFront(element)
+	}
+	eim.cacheHits++
+	eim.totalChecks++
+	return element.Value.(*cacheEntry).ignored, true
+}
+
+// addToCache adds a result to the cache, evicting the least recently used
+// entries to stay within the entry and memory limits
 func (eim *EnhancedIgnoreManager) addToCache(path string, result bool) {
 	eim.cacheMutex.Lock()
 	defer eim.cacheMutex.Unlock()
 
-	// Memory management: check cache size
-	if len(eim.pathCache) >= MaxPathCacheEntries {
-		// Simple eviction: clear oldest half of cache
-		// This is more predictable than LRU for our use case
-		eim.clearOldestCacheEntries()
+	if eim.pathCache == nil {
+		eim.resetCache()
+	}
+	if element, exists := eim.pathCache[path]; exists {
+		// Computed concurrently by another goroutine
+		element.Value.(*cacheEntry).ignored = result
+		return
 	}
 
+	maxEntries := eim.cacheMaxEntries
+	if maxEntries <= 0 {
+		maxEntries = MaxPathCacheEntries
+	}
 	// Estimate memory usage (rough calculation)
 	entrySize := int64(len(path) + 1) // path + bool
-	if eim.cacheMemory+entrySize > MaxCacheMemoryMB*1024*1024 {
-		eim.clearOldestCacheEntries()
-		eim.cacheMemory = 0 // Reset counter after clear
+	for eim.cacheOrder.Len() > 0 &&
+		(eim.cacheOrder.Len() >= maxEntries || eim.cacheMemory+entrySize > MaxCacheMemoryMB*1024*1024) {
+		eim.evictCacheEntry()
 	}
 
-	eim.pathCache[path] = result
+	eim.pathCache[path] = eim.cacheOrder.PushFront(&cacheEntry{key: path, ignored: result})
 	eim.cacheMemory += entrySize
 }
 
-// clearOldestCacheEntries clears half the cache (simple eviction strategy)
-func (eim *EnhancedIgnoreManager) clearOldestCacheEntries() {
-	targetSize := len(eim.pathCache) / 2
-	count := 0
-	
-	// Clear entries until we reach target size
-	for path := range eim.pathCache {
-		delete(eim.pathCache, path)
-		count++
-		if count >= targetSize {
-			break
-		}
+// evictCacheEntry drops the entry at the back of the cache order; the
+// caller holds cacheMutex
+func (eim *EnhancedIgnoreManager) evictCacheEntry() {
+	element := eim.cacheOrder.Back()
+	if element == nil {
+		return
 	}
-	
-	// Reset memory counter (rough estimate)
-	eim.cacheMemory = eim.cacheMemory / 2
+	entry := eim.cacheOrder.Remove(element).(*cacheEntry)
+	delete(eim.pathCache, entry.key)
+	eim.cacheMemory -= int64(len(entry.key) + 1)
+	eim.cacheEvictions++
+}
+
+// resetCache drops every cached result; the caller holds cacheMutex
+func (eim *EnhancedIgnoreManager) resetCache() {
+	eim.pathCache = make(map[string]*list.Element)
+	eim.cacheOrder = list.New()
+	eim.cacheMemory = 0
 }
 
 // ClearCache clears the entire cache (useful for testing or memory pressure)
@@ -365,20 +562,22 @@ func (eim *EnhancedIgnoreManager) ClearCache() {
 	eim.cacheMutex.Lock()
 	defer eim.cacheMutex.Unlock()
 	
-	eim.pathCache = make(map[string]bool)
-	eim.cacheMemory = 0
+	eim.resetCache()
 	eim.cacheHits = 0
 	eim.cacheMisses = 0
+	eim.cacheEvictions = 0
 }
 
-// GetStats returns cache performance statistics
-func (eim *EnhancedIgnoreManager) GetStats() (hits, misses, total int64, hitRate float64) {
+// GetStats returns cache performance statistics; evictions counts entries
+// dropped to stay within the cache limits
+func (eim *EnhancedIgnoreManager) GetStats() (hits, misses, total int64, hitRate float64, evictions int64) {
 	eim.cacheMutex.RLock()
 	defer eim.cacheMutex.RUnlock()
 	
 	hits = eim.cacheHits
 	misses = eim.cacheMisses
 	total = eim.totalChecks
+	evictions = eim.cacheEvictions
 	
 	if total > 0 {
 		hitRate = float64(hits) / float64(total) * 100
@@ -387,19 +586,43 @@ func (eim *EnhancedIgnoreManager) GetStats() (hits, misses, total int64, hitRate
 	return
 }
 
-// ReloadIgnoreFile reloads the ignore file (useful for dynamic updates)
+// ReloadIgnoreFile reloads the ignore file (useful for dynamic updates).
+// Cached results are dropped; the hit and miss statistics are kept.
 func (eim *EnhancedIgnoreManager) ReloadIgnoreFile() error {
-	// Clear existing patterns and cache
+	// Clear existing patterns and cached results
 	eim.patterns = nil
-	eim.ClearCache()
+	eim.cacheMutex.Lock()
+	eim.resetCache()
+	eim.cacheMutex.Unlock()
 	
 	// Reload from file
-	return eim.loadIgnoreFile()
+	if err := eim.loadIgnoreFile(); err != nil {
+		return err
+	}
+	return eim.loadNestedIgnores()
+}
+
+// IsIgnoreSource reports whether path is a file patterns are loaded from:
+// any .timemachine-ignore in the project, or any .gitignore when those are
+// honored
+func (eim *EnhancedIgnoreManager) IsIgnoreSource(path string) bool {
+	key := pathKey(filepath.Clean(path), eim.caseInsensitive)
+	if key == pathKey(eim.ignoreFile, eim.caseInsensitive) {
+		return true
+	}
+	name := filepath.Base(key)
+	isGitignore := eim.respectGitignore && name == pathKey(GitignoreFile, eim.caseInsensitive)
+	if !isGitignore && name != pathKey(DefaultIgnoreFile, eim.caseInsensitive) {
+		return false
+	}
+	rel, err := filepath.Rel(pathKey(eim.projectRoot, eim.caseInsensitive), key)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
 }
 
-// GetPatternsCount returns the number of loaded patterns
+// GetPatternsCount returns the number of loaded .timemachine-ignore
+// patterns, nested files included
 func (eim *EnhancedIgnoreManager) GetPatternsCount() int {
-	return len(eim.patterns)
+	return len(eim.patterns) + len(eim.nested)
 }
 
 // EstimateMemoryUsage returns estimated memory usage in bytes
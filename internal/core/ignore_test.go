@@ -7,6 +7,7 @@ import (
 	"strings"
 	"sync"
 	"testing"
+	"time"
 )
 
 // TestEnhancedIgnoreManager tests the basic functionality
@@ -194,12 +195,12 @@ func TestCachePerformance(t *testing.T) {
 	
 	// First call - should be cache miss
 	result1 := manager.ShouldIgnore(testPath)
-	hits1, misses1, total1, _ := manager.GetStats()
+	hits1, misses1, total1, _, _ := manager.GetStats()
 	t.Logf("After first call: hits=%d, misses=%d, total=%d", hits1, misses1, total1)
 	
 	// Second call - should be cache hit
 	result2 := manager.ShouldIgnore(testPath)
-	hits2, misses2, total2, hitRate := manager.GetStats()
+	hits2, misses2, total2, hitRate, _ := manager.GetStats()
 	t.Logf("After second call: hits=%d, misses=%d, total=%d", hits2, misses2, total2)
 	
 	// Verify results are consistent
@@ -271,7 +272,7 @@ func TestConcurrentAccess(t *testing.T) {
 	}
 	
 	// Verify cache stats
-	hits, misses, total, hitRate := manager.GetStats()
+	hits, misses, total, hitRate, _ := manager.GetStats()
 	expectedTotal := int64(numGoroutines * callsPerGoroutine)
 	
 	if total != expectedTotal {
@@ -332,7 +333,6 @@ invalid pattern with 	tab
 		manager := &EnhancedIgnoreManager{
 			projectRoot: tempDir,
 			ignoreFile:  ignoreFile,
-			pathCache:   make(map[string]bool),
 		}
 		
 		err := manager.loadIgnoreFile()
@@ -372,7 +372,7 @@ func TestMemoryManagement(t *testing.T) {
 	}
 
 	// Verify cache size is controlled
-	hits, misses, total, hitRate := manager.GetStats()
+	hits, misses, total, hitRate, _ := manager.GetStats()
 	memoryUsage := manager.EstimateMemoryUsage()
 	
 	t.Logf("Memory test stats: hits=%d, misses=%d, total=%d, hit rate=%.2f%%, memory=%d bytes", 
@@ -385,6 +385,51 @@ func TestMemoryManagement(t *testing.T) {
 	}
 }
 
+// TestCacheEviction tests that the least recently used result is evicted
+// first with LRU, and the oldest one without
+func TestCacheEviction(t *testing.T) {
+	tempDir := t.TempDir()
+
+	for _, tt := range []struct {
+		lru     bool
+		evicted string
+		kept    string
+	}{
+		{true, "b.txt", "a.txt"},
+		{false, "a.txt", "b.txt"},
+	} {
+		manager := NewEnhancedIgnoreManager(tempDir)
+		manager.ConfigureCache(3, tt.lru)
+
+		for _, name := range []string{"a.txt", "b.txt", "c.txt", "a.txt", "d.txt"} {
+			manager.ShouldIgnore(filepath.Join(tempDir, name))
+		}
+		hits, _, _, _, evictions := manager.GetStats()
+		if hits != 1 || evictions != 1 {
+			t.Errorf("LRU %v: expected 1 hit and 1 eviction, got %d hits and %d evictions", tt.lru, hits, evictions)
+		}
+
+		manager.ShouldIgnore(filepath.Join(tempDir, tt.kept))
+		if hits, _, _, _, _ := manager.GetStats(); hits != 2 {
+			t.Errorf("LRU %v: expected %s to still be cached", tt.lru, tt.kept)
+		}
+		manager.ShouldIgnore(filepath.Join(tempDir, tt.evicted))
+		if hits, _, _, _, _ := manager.GetStats(); hits != 2 {
+			t.Errorf("LRU %v: expected %s to have been evicted", tt.lru, tt.evicted)
+		}
+	}
+
+	// Lowering the limit evicts right away
+	manager := NewEnhancedIgnoreManager(tempDir)
+	for i := 0; i < 10; i++ {
+		manager.ShouldIgnore(filepath.Join(tempDir, fmt.Sprintf("file%d", i)))
+	}
+	manager.ConfigureCache(4, true)
+	if _, _, _, _, evictions := manager.GetStats(); evictions != 6 {
+		t.Errorf("Expected 6 evictions after lowering the limit, got %d", evictions)
+	}
+}
+
 // TestReloadIgnoreFile tests dynamic reloading of ignore file
 func TestReloadIgnoreFile(t *testing.T) {
 	tempDir, err := os.MkdirTemp("", "timemachine-reload-test")
@@ -429,6 +474,92 @@ func TestReloadIgnoreFile(t *testing.T) {
 	if !manager.ShouldIgnore(tmpPath) {
 		t.Errorf("app.tmp should be ignored after reload")
 	}
+
+	// Statistics survive a reload; they back monotonic metrics
+	if _, _, total, _, _ := manager.GetStats(); total != 3 {
+		t.Errorf("Expected 3 checks to be counted across the reload, got %d", total)
+	}
+}
+
+func TestIsIgnoreSource(t *testing.T) {
+	tempDir := t.TempDir()
+	manager := NewEnhancedIgnoreManager(tempDir)
+
+	if !manager.IsIgnoreSource(filepath.Join(tempDir, DefaultIgnoreFile)) {
+		t.Errorf("Expected %s to be an ignore source", DefaultIgnoreFile)
+	}
+	if !manager.IsIgnoreSource(filepath.Join(tempDir, "sub", DefaultIgnoreFile)) {
+		t.Errorf("Expected a nested %s to be an ignore source", DefaultIgnoreFile)
+	}
+	nested := filepath.Join(tempDir, "sub", GitignoreFile)
+	if manager.IsIgnoreSource(nested) {
+		t.Errorf("Expected .gitignore to be skipped unless gitignores are honored")
+	}
+
+	if err := manager.EnableGitignore(); err != nil {
+		t.Fatal(err)
+	}
+	if !manager.IsIgnoreSource(nested) {
+		t.Errorf("Expected nested .gitignore to be an ignore source")
+	}
+	if manager.IsIgnoreSource(filepath.Join(filepath.Dir(tempDir), GitignoreFile)) {
+		t.Errorf("Expected a .gitignore outside the project not to be an ignore source")
+	}
+}
+
+func TestWatcherReloadsIgnoreFile(t *testing.T) {
+	tempDir, state, gitManager := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	build := filepath.Join(tempDir, "build")
+	if err := os.Mkdir(build, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	watcher, err := NewWatcher(state, gitManager)
+	if err != nil {
+		t.Fatalf("NewWatcher failed: %v", err)
+	}
+	if err := watcher.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer watcher.Stop()
+
+	watched := func() bool {
+		for _, dir := range watcher.fsWatcher.WatchList() {
+			if dir == build {
+				return true
+			}
+		}
+		return false
+	}
+	waitFor := func(want bool) bool {
+		for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(20 * time.Millisecond) {
+			if watched() == want {
+				return true
+			}
+		}
+		return false
+	}
+
+	if !watched() {
+		t.Fatalf("Expected build/ to be watched initially")
+	}
+
+	ignoreFile := filepath.Join(tempDir, DefaultIgnoreFile)
+	if err := os.WriteFile(ignoreFile, []byte("build/\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if !waitFor(false) {
+		t.Fatalf("Expected build/ to stop being watched once ignored")
+	}
+
+	if err := os.Remove(ignoreFile); err != nil {
+		t.Fatal(err)
+	}
+	if !waitFor(true) {
+		t.Errorf("Expected build/ to be watched again once the pattern is gone")
+	}
 }
 
 // BenchmarkIgnoreCheck benchmarks the ignore checking performance
@@ -511,6 +642,38 @@ coverage/
 	}
 }
 
+// BenchmarkMatchPatterns benchmarks pattern matching without the cache, so
+// matcher changes show up directly
+func BenchmarkMatchPatterns(b *testing.B) {
+	manager := &EnhancedIgnoreManager{}
+	for _, line := range []string{
+		"node_modules/", "*.log", "build/", "/dist/", ".vscode/", "*.test.*",
+		"docs/*.html", "/src/gen*/", "!important.log", ".DS_Store",
+	} {
+		pattern, err := manager.parsePattern(line)
+		if err != nil {
+			b.Fatalf("Failed to parse %q: %v", line, err)
+		}
+		manager.patterns = append(manager.patterns, pattern)
+	}
+
+	paths := []string{
+		"src/main.go",
+		"src/components/header/index.tsx",
+		"app.log",
+		"node_modules/react/index.js",
+		"docs/api/index.html",
+		"src/generated/models/user.go",
+		"test/unit/parser.test.js",
+		"a/very/deep/directory/structure/with/many/levels/file.txt",
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		manager.matchPatterns(paths[i%len(paths)], false)
+	}
+}
+
 // BenchmarkCachePerformance benchmarks cache hit vs miss performance
 func BenchmarkCachePerformance(b *testing.B) {
 	tempDir, err := os.MkdirTemp("", "timemachine-cache-bench")
@@ -874,4 +1037,85 @@ func TestEdgeCases(t *testing.T) {
 			t.Errorf("Expected 0 patterns from empty file, got %d", manager.GetPatternsCount())
 		}
 	})
-}
\ No newline at end of file
+}
+func TestGlobstarAndCharacterClasses(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "timemachine-globstar-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	ignoreContent := strings.Join([]string{
+		"**/cache/",
+		"logs/**",
+		"a/**/b",
+		"**/*.min.js",
+		"report[0-9].txt",
+		"tmp[!a-z]",
+		`\#notes`,
+		`literal\*`,
+		`space\ `,
+		"bad[",
+	}, "\n")
+	ignoreFile := filepath.Join(tempDir, DefaultIgnoreFile)
+	if err := os.WriteFile(ignoreFile, []byte(ignoreContent), 0644); err != nil {
+		t.Fatalf("Failed to write ignore file: %v", err)
+	}
+
+	manager := NewEnhancedIgnoreManager(tempDir)
+
+	// The malformed "bad[" is skipped
+	if manager.GetPatternsCount() != 9 {
+		t.Errorf("Expected 9 patterns, got %d", manager.GetPatternsCount())
+	}
+
+	tests := []struct {
+		path     string
+		expected bool
+	}{
+		{"cache/data.bin", true},
+		{"src/deep/cache/data.bin", true},
+		{"src/cache.go", false},
+		{"logs/app/today.log", true},
+		{"logs", false},
+		{"src/logs/app.log", false},
+		{"a/b", true},
+		{"a/x/y/b", true},
+		{"a/x/y/b/file.txt", true},
+		{"a/x/c", false},
+		{"dist/app.min.js", true},
+		{"app.min.js", true},
+		{"app.js", false},
+		{"report1.txt", true},
+		{"reports/report9.txt", true},
+		{"reportx.txt", false},
+		{"tmp1", true},
+		{"tmpa", false},
+		{"#notes", true},
+		{"notes", false},
+		{"literal*", true},
+		{"literalx", false},
+		{"space ", true},
+		{"space", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			result := manager.ShouldIgnore(filepath.Join(tempDir, tt.path))
+			if result != tt.expected {
+				t.Errorf("Expected ShouldIgnore(%s) = %v, got %v", tt.path, tt.expected, result)
+			}
+		})
+	}
+
+	pattern, err := manager.parsePattern("src/**/*.go")
+	if err != nil {
+		t.Fatalf("parsePattern failed: %v", err)
+	}
+	if !pattern.HasGlobstar || pattern.IsSimple {
+		t.Errorf("Expected globstar, non-simple pattern, got %+v", pattern)
+	}
+	if _, err := manager.parsePattern("src/[a-"); err == nil {
+		t.Error("Expected error for malformed character class")
+	}
+}
@@ -0,0 +1,121 @@
+package core
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// IgnoreSourceDefault is the rule no pattern can change: nothing inside
+// .git is ever watched or snapshotted
+const IgnoreSourceDefault = "built-in"
+
+// IgnoreMatch explains the verdict on a path: the pattern that decided it,
+// the last one to match, as git check-ignore -v reports
+type IgnoreMatch struct {
+	Path    string // Slash-separated, relative to the project root
+	Ignored bool
+	Source  string // One of the IgnoreSource constants; "" when no pattern matched
+	File    string // The ignore file holding the pattern, relative to the project root; "" for other sources
+	Line    int    // Line in File, or position in watcher.ignore_patterns, TIMEMACHINE_WATCHER_IGNORE or --ignore
+	Pattern string // The pattern as written, "!" included
+}
+
+// NewProjectIgnoreManager creates the ignore manager a watcher started now
+// would use, with the patterns in state.IgnoreFlags as if given to start
+// --ignore. The error reports patterns that were rejected; the manager is
+// usable regardless.
+func NewProjectIgnoreManager(state *AppState) (*EnhancedIgnoreManager, error) {
+	manager := NewEnhancedIgnoreManager(state.ProjectRoot)
+	var gitignoreErr error
+	if state.Config != nil {
+		manager.ConfigureCache(state.Config.Cache.MaxEntries, state.Config.Cache.EnableLRU)
+		if state.Config.Watcher.RespectGitignore {
+			gitignoreErr = manager.EnableGitignore()
+		}
+	}
+	if err := ConfigureIgnoreSources(manager, state); err != nil {
+		return manager, err
+	}
+	return manager, gitignoreErr
+}
+
+// Explain reports whether a path is ignored and which pattern decided it.
+// It follows ShouldIgnore, source by source, but skips the cache.
+func (eim *EnhancedIgnoreManager) Explain(file string, isDir bool) IgnoreMatch {
+	rel, err := filepath.Rel(pathKey(eim.projectRoot, eim.caseInsensitive), pathKey(file, eim.caseInsensitive))
+	if err != nil {
+		rel = file
+	}
+	rel = filepath.ToSlash(rel)
+	match := IgnoreMatch{Path: rel}
+
+	if rel == ".git" || strings.HasPrefix(rel, ".git/") {
+		match.Ignored, match.Source, match.Pattern = true, IgnoreSourceDefault, ".git/"
+		return match
+	}
+
+	if eim.respectGitignore {
+		if pattern, ok := eim.explainGitignore(rel, isDir); ok {
+			match.setGitignore(IgnoreSourceGitignore, GitignoreFile, pattern)
+		}
+	}
+	match.apply(IgnoreSourceConfig, eim.extra[IgnoreSourceConfig], eim, rel)
+	match.apply(IgnoreSourceFile, eim.patterns, eim, rel)
+	if match.Source == IgnoreSourceFile {
+		match.File = DefaultIgnoreFile
+	}
+	for _, pattern := range eim.nested {
+		if pattern.matches(rel, isDir) || pattern.matchesParent(rel) {
+			match.setGitignore(IgnoreSourceNested, DefaultIgnoreFile, pattern)
+		}
+	}
+	match.apply(IgnoreSourceEnv, eim.extra[IgnoreSourceEnv], eim, rel)
+	match.apply(IgnoreSourceFlags, eim.extra[IgnoreSourceFlags], eim, rel)
+	return match
+}
+
+// apply records the last of patterns from source matching rel
+func (m *IgnoreMatch) apply(source string, patterns []IgnorePattern, eim *EnhancedIgnoreManager, rel string) {
+	for _, pattern := range patterns {
+		if eim.matchPattern(pattern, rel) {
+			m.Ignored = !pattern.IsNegation
+			m.Source, m.File, m.Line, m.Pattern = source, "", pattern.Line, pattern.Original
+		}
+	}
+}
+
+// setGitignore records a pattern from a .gitignore or nested
+// .timemachine-ignore file
+func (m *IgnoreMatch) setGitignore(source, name string, pattern gitignorePattern) {
+	m.Ignored = !pattern.negation
+	m.Source, m.File, m.Line, m.Pattern = source, path.Join(pattern.base, name), pattern.line, pattern.original
+}
+
+// explainGitignore finds the .gitignore pattern deciding rel, checking
+// parent directories first as matchGitignore does
+func (eim *EnhancedIgnoreManager) explainGitignore(rel string, isDir bool) (gitignorePattern, bool) {
+	for i := strings.Index(rel, "/"); i >= 0; {
+		if pattern, ok := eim.lastGitignoreMatch(rel[:i], true); ok && !pattern.negation {
+			return pattern, true
+		}
+		next := strings.Index(rel[i+1:], "/")
+		if next < 0 {
+			break
+		}
+		i += next + 1
+	}
+	return eim.lastGitignoreMatch(rel, isDir)
+}
+
+// lastGitignoreMatch returns the last .gitignore pattern matching a path
+func (eim *EnhancedIgnoreManager) lastGitignoreMatch(rel string, isDir bool) (gitignorePattern, bool) {
+	var last gitignorePattern
+	found := false
+	for _, pattern := range eim.gitignore {
+		if pattern.matches(rel, isDir) {
+			last, found = pattern, true
+		}
+	}
+	return last, found
+}
@@ -0,0 +1,60 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/config"
+)
+
+func TestIgnoreExplain(t *testing.T) {
+	tempDir := t.TempDir()
+	os.MkdirAll(filepath.Join(tempDir, "pkg"), 0755)
+	os.WriteFile(filepath.Join(tempDir, DefaultIgnoreFile), []byte("# build output\ndist/\n*.log\n!keep.log\n"), 0644)
+	os.WriteFile(filepath.Join(tempDir, "pkg", DefaultIgnoreFile), []byte("gen/\n"), 0644)
+	os.WriteFile(filepath.Join(tempDir, GitignoreFile), []byte("coverage/\n"), 0644)
+
+	state := &AppState{
+		ProjectRoot: tempDir,
+		Config: &config.Config{Watcher: config.WatcherConfig{
+			RespectGitignore: true,
+			IgnorePatterns:   []string{"*.tmp", "*.bak"},
+		}},
+		IgnoreFlags: []string{"!debug.bak"},
+	}
+	manager, err := NewProjectIgnoreManager(state)
+	if err != nil {
+		t.Fatalf("NewProjectIgnoreManager failed: %v", err)
+	}
+
+	tests := []struct {
+		path    string
+		ignored bool
+		source  string
+		file    string
+		line    int
+		pattern string
+	}{
+		{"main.go", false, "", "", 0, ""},
+		{"dist/app.js", true, IgnoreSourceFile, DefaultIgnoreFile, 2, "dist/"},
+		{"keep.log", false, IgnoreSourceFile, DefaultIgnoreFile, 4, "!keep.log"},
+		{"pkg/gen/types.go", true, IgnoreSourceNested, "pkg/" + DefaultIgnoreFile, 1, "gen/"},
+		{"coverage/index.html", true, IgnoreSourceGitignore, GitignoreFile, 1, "coverage/"},
+		{"x.bak", true, IgnoreSourceConfig, "", 2, "*.bak"},
+		{"debug.bak", false, IgnoreSourceFlags, "", 1, "!debug.bak"},
+		{".git/config", true, IgnoreSourceDefault, "", 0, ".git/"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			abs := filepath.Join(tempDir, filepath.FromSlash(tt.path))
+			match := manager.Explain(abs, false)
+			if match.Ignored != tt.ignored || match.Source != tt.source || match.File != tt.file || match.Line != tt.line || match.Pattern != tt.pattern {
+				t.Errorf("Explain(%s) = %+v, want ignored %v by %s %s:%d %q", tt.path, match, tt.ignored, tt.source, tt.file, tt.line, tt.pattern)
+			}
+			if tt.source != IgnoreSourceDefault && manager.ShouldIgnoreFile(abs) != match.Ignored {
+				t.Errorf("Explain(%s) disagrees with ShouldIgnoreFile", tt.path)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// IgnoreEnv adds ignore patterns, separated by commas, on top of the
+// configuration and ignore files
+const IgnoreEnv = "TIMEMACHINE_WATCHER_IGNORE"
+
+// Sources of ignore patterns. They are applied in this order, so a later
+// source wins: its "!pattern" re-includes what an earlier one ignored.
+const (
+	IgnoreSourceGitignore = GitignoreFile
+	IgnoreSourceConfig    = "watcher.ignore_patterns"
+	IgnoreSourceFile      = DefaultIgnoreFile
+	IgnoreSourceNested    = DefaultIgnoreFile + " (subdirectories)"
+	IgnoreSourceEnv       = IgnoreEnv
+	IgnoreSourceFlags     = "start --ignore"
+)
+
+// IgnoreSource is a source of ignore patterns and how many it contributes
+type IgnoreSource struct {
+	Name     string `json:"name"`
+	Patterns int    `json:"patterns"`
+}
+
+// SetPatterns replaces the patterns of one of the sources that aren't
+// files: IgnoreSourceConfig, IgnoreSourceEnv or IgnoreSourceFlags. Valid
+// patterns are kept even when others are rejected.
+func (eim *EnhancedIgnoreManager) SetPatterns(source string, lines []string) error {
+	if source != IgnoreSourceConfig && source != IgnoreSourceEnv && source != IgnoreSourceFlags {
+		return fmt.Errorf("unknown ignore source %q", source)
+	}
+
+	var patterns []IgnorePattern
+	var invalid []string
+	for i, line := range lines {
+		line = trimPatternLine(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		pattern, err := eim.parsePattern(line)
+		if err != nil {
+			invalid = append(invalid, fmt.Sprintf("%q (%v)", line, err))
+			continue
+		}
+		pattern.Line = i + 1
+		patterns = append(patterns, pattern)
+	}
+
+	if eim.extra == nil {
+		eim.extra = make(map[string][]IgnorePattern)
+	}
+	eim.extra[source] = patterns
+	eim.ClearCache()
+
+	if len(invalid) > 0 {
+		return fmt.Errorf("invalid ignore patterns from %s: %s", source, strings.Join(invalid, ", "))
+	}
+	return nil
+}
+
+// IgnoreSources lists the sources of ignore patterns in the order they are
+// applied, .gitignore only when honored
+func (eim *EnhancedIgnoreManager) IgnoreSources() []IgnoreSource {
+	var sources []IgnoreSource
+	if eim.respectGitignore {
+		sources = append(sources, IgnoreSource{Name: IgnoreSourceGitignore, Patterns: len(eim.gitignore)})
+	}
+	return append(sources,
+		IgnoreSource{Name: IgnoreSourceConfig, Patterns: len(eim.extra[IgnoreSourceConfig])},
+		IgnoreSource{Name: IgnoreSourceFile, Patterns: len(eim.patterns)},
+		IgnoreSource{Name: IgnoreSourceNested, Patterns: len(eim.nested)},
+		IgnoreSource{Name: IgnoreSourceEnv, Patterns: len(eim.extra[IgnoreSourceEnv])},
+		IgnoreSource{Name: IgnoreSourceFlags, Patterns: len(eim.extra[IgnoreSourceFlags])},
+	)
+}
+
+// ConfigureIgnoreSources loads the patterns the configuration, IgnoreEnv
+// and start --ignore add to the ignore files. Every source is loaded; the
+// error reports the patterns rejected.
+func ConfigureIgnoreSources(eim *EnhancedIgnoreManager, state *AppState) error {
+	var problems []string
+	var configured []string
+	if state.Config != nil {
+		configured = state.Config.Watcher.IgnorePatterns
+	}
+	sources := []struct {
+		name  string
+		lines []string
+	}{
+		{IgnoreSourceConfig, configured},
+		{IgnoreSourceEnv, SplitIgnoreEnv(os.Getenv(IgnoreEnv))},
+		{IgnoreSourceFlags, state.IgnoreFlags},
+	}
+	for _, source := range sources {
+		if err := eim.SetPatterns(source.name, source.lines); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// SplitIgnoreEnv splits the value of IgnoreEnv into patterns
+func SplitIgnoreEnv(value string) []string {
+	var patterns []string
+	for _, pattern := range strings.Split(value, ",") {
+		if pattern = strings.TrimSpace(pattern); pattern != "" {
+			patterns = append(patterns, pattern)
+		}
+	}
+	return patterns
+}
+
+// applyExtra applies the patterns of one source to a verdict
+func (eim *EnhancedIgnoreManager) applyExtra(source, relPath string, ignored bool) bool {
+	for _, pattern := range eim.extra[source] {
+		if eim.matchPattern(pattern, relPath) {
+			ignored = !pattern.IsNegation
+		}
+	}
+	return ignored
+}
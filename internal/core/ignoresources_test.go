@@ -0,0 +1,73 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/config"
+)
+
+func TestIgnoreSourcePrecedence(t *testing.T) {
+	tempDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tempDir, DefaultIgnoreFile), []byte("!keep.log\ndist/\n"), 0644); err != nil {
+		t.Fatalf("Failed to write ignore file: %v", err)
+	}
+	t.Setenv(IgnoreEnv, "*.lock, dist/ ,")
+
+	state := &AppState{
+		ProjectRoot: tempDir,
+		Config:      &config.Config{Watcher: config.WatcherConfig{IgnorePatterns: []string{"*.log", "*.lock"}}},
+		IgnoreFlags: []string{"!yarn.lock"},
+	}
+	manager := NewEnhancedIgnoreManager(tempDir)
+	if err := ConfigureIgnoreSources(manager, state); err != nil {
+		t.Fatalf("ConfigureIgnoreSources failed: %v", err)
+	}
+
+	tests := []struct {
+		path    string
+		ignored bool
+		reason  string
+	}{
+		{"debug.log", true, "watcher.ignore_patterns"},
+		{"keep.log", false, ".timemachine-ignore re-includes what the configuration ignores"},
+		{"Cargo.lock", true, "configuration and environment"},
+		{"yarn.lock", false, "--ignore re-includes what the environment ignores"},
+		{"dist/app.js", true, ".timemachine-ignore"},
+		{"main.go", false, "no source ignores it"},
+	}
+	for _, tt := range tests {
+		if got := manager.ShouldIgnoreFile(filepath.Join(tempDir, tt.path)); got != tt.ignored {
+			t.Errorf("%s: ignored = %v, want %v (%s)", tt.path, got, tt.ignored, tt.reason)
+		}
+	}
+
+	expected := []IgnoreSource{
+		{IgnoreSourceConfig, 2},
+		{IgnoreSourceFile, 2},
+		{IgnoreSourceNested, 0},
+		{IgnoreSourceEnv, 2},
+		{IgnoreSourceFlags, 1},
+	}
+	if sources := manager.IgnoreSources(); !reflect.DeepEqual(sources, expected) {
+		t.Errorf("IgnoreSources() = %v, want %v", sources, expected)
+	}
+
+	// Reloading the ignore file keeps the other sources
+	if err := manager.ReloadIgnoreFile(); err != nil {
+		t.Fatalf("ReloadIgnoreFile failed: %v", err)
+	}
+	if !manager.ShouldIgnoreFile(filepath.Join(tempDir, "Cargo.lock")) {
+		t.Error("Expected patterns from the environment to survive a reload")
+	}
+
+	// Invalid patterns are reported, the valid ones still apply
+	if err := manager.SetPatterns(IgnoreSourceFlags, []string{"[", "*.tmp"}); err == nil {
+		t.Error("Expected an error for an invalid pattern")
+	}
+	if !manager.ShouldIgnoreFile(filepath.Join(tempDir, "x.tmp")) {
+		t.Error("Expected the valid pattern to apply")
+	}
+}
@@ -0,0 +1,110 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ResolveIncludePaths turns paths given on the command line, relative to
+// dir, into include paths relative to the project root. Each must be an
+// existing directory inside the project.
+func ResolveIncludePaths(projectRoot, dir string, paths []string) ([]string, error) {
+	var includes []string
+	for _, p := range paths {
+		abs := p
+		if !filepath.IsAbs(abs) {
+			abs = filepath.Join(dir, p)
+		}
+		rel, err := filepath.Rel(projectRoot, abs)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return nil, fmt.Errorf("%s is outside the project", p)
+		}
+		if info, err := os.Stat(abs); err != nil || !info.IsDir() {
+			return nil, fmt.Errorf("%s is not a directory", p)
+		}
+		includes = append(includes, filepath.ToSlash(rel))
+	}
+	return cleanIncludePaths(includes), nil
+}
+
+// cleanIncludePaths normalizes include paths (slash-separated, relative to
+// the project root) and drops those inside another. It returns nil, the
+// whole project, if one of them is the root.
+func cleanIncludePaths(paths []string) []string {
+	var cleaned []string
+	for _, p := range paths {
+		p = strings.Trim(path.Clean(filepath.ToSlash(p)), "/")
+		if p == "." || p == "" {
+			return nil
+		}
+		cleaned = append(cleaned, p)
+	}
+
+	var kept []string
+	for i, p := range cleaned {
+		covered := false
+		for j, other := range cleaned {
+			// Of duplicates, the first is kept
+			if (other != p || j < i) && isWithin(p, other) {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			kept = append(kept, p)
+		}
+	}
+	return kept
+}
+
+// isIncluded reports whether a slash-separated path relative to the project
+// root is snapshotted: it lies within one of the include paths, or there
+// are none
+func isIncluded(rel string, includes []string) bool {
+	if len(includes) == 0 {
+		return true
+	}
+	for _, include := range includes {
+		if isWithin(rel, include) {
+			return true
+		}
+	}
+	return false
+}
+
+// isWithin reports whether rel is dir or below it
+func isWithin(rel, dir string) bool {
+	return rel == dir || strings.HasPrefix(rel, dir+"/")
+}
+
+// includePathspecs returns the arguments limiting a git command to the
+// include paths, none if there are none
+func includePathspecs(includes []string) []string {
+	if len(includes) == 0 {
+		return nil
+	}
+	return append([]string{"--"}, topPathspecs(includes)...)
+}
+
+// includedPaths keeps the paths within the include paths
+func includedPaths(paths, includes []string) []string {
+	if len(includes) == 0 {
+		return paths
+	}
+	var kept []string
+	for _, p := range paths {
+		if isIncluded(p, includes) {
+			kept = append(kept, p)
+		}
+	}
+	return kept
+}
+
+// IsIncluded reports whether a slash-separated path relative to the project
+// root lies within the include paths, if any
+func (s *AppState) IsIncluded(rel string) bool {
+	return isIncluded(rel, s.IncludePaths)
+}
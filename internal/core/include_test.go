@@ -0,0 +1,83 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/config"
+)
+
+func TestCleanIncludePaths(t *testing.T) {
+	tests := []struct {
+		name     string
+		paths    []string
+		expected []string
+	}{
+		{"none", nil, nil},
+		{"trailing slashes", []string{"services/payments/", "./web"}, []string{"services/payments", "web"}},
+		{"nested", []string{"services/payments/api", "services", "services"}, []string{"services"}},
+		{"prefix isn't nesting", []string{"web", "website"}, []string{"web", "website"}},
+		{"root", []string{"web", "."}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cleanIncludePaths(tt.paths); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("cleanIncludePaths(%v) = %v, want %v", tt.paths, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestResolveIncludePaths(t *testing.T) {
+	root := t.TempDir()
+	os.MkdirAll(filepath.Join(root, "services", "payments"), 0755)
+	os.WriteFile(filepath.Join(root, "README.md"), []byte("readme"), 0644)
+
+	includes, err := ResolveIncludePaths(root, filepath.Join(root, "services"), []string{"payments"})
+	if err != nil || !reflect.DeepEqual(includes, []string{"services/payments"}) {
+		t.Errorf("Expected services/payments, got %v, %v", includes, err)
+	}
+	for _, bad := range []string{"../..", "README.md", "missing"} {
+		if _, err := ResolveIncludePaths(root, root, []string{bad}); err == nil {
+			t.Errorf("Expected %s to be rejected", bad)
+		}
+	}
+}
+
+func TestSnapshotIncludePaths(t *testing.T) {
+	for _, backend := range []string{BackendExec, BackendNative} {
+		t.Run(backend, func(t *testing.T) {
+			tempDir, state, _ := setupTestRepo(t)
+			defer os.RemoveAll(tempDir)
+
+			state.Config = &config.Config{Git: config.GitConfig{Backend: backend}}
+			state.IncludePaths = []string{"services/payments"}
+			gitManager := NewGitManager(state)
+
+			os.MkdirAll(filepath.Join(tempDir, "services", "payments"), 0755)
+			os.MkdirAll(filepath.Join(tempDir, "web"), 0755)
+			os.WriteFile(filepath.Join(tempDir, "services", "payments", "charge.go"), []byte("package payments\n"), 0644)
+			os.WriteFile(filepath.Join(tempDir, "web", "index.html"), []byte("<html>"), 0644)
+
+			if err := gitManager.CreateSnapshot("included"); err != nil {
+				t.Fatalf("CreateSnapshot failed: %v", err)
+			}
+			files, err := gitManager.SnapshotFiles("HEAD")
+			if err != nil || !reflect.DeepEqual(files, []string{"services/payments/charge.go"}) {
+				t.Fatalf("Expected only the included file, got %v, %v", files, err)
+			}
+
+			// Changes elsewhere don't make a snapshot
+			os.WriteFile(filepath.Join(tempDir, "web", "index.html"), []byte("<html></html>"), 0644)
+			if err := gitManager.CreateSnapshot("outside"); err != nil {
+				t.Fatalf("CreateSnapshot failed: %v", err)
+			}
+			snapshots, err := gitManager.ListSnapshots(0, "")
+			if err != nil || len(snapshots) != 1 {
+				t.Errorf("Expected one snapshot, got %d, %v", len(snapshots), err)
+			}
+		})
+	}
+}
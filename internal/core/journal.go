@@ -0,0 +1,273 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// RestoreJournalFile is the intent log of an in-progress restore, kept in the
+// shadow repository
+const RestoreJournalFile = "restore-journal.json"
+
+// DefaultRestoreBatchSize is the number of files restored per journal update
+const DefaultRestoreBatchSize = 200
+
+// ErrNoRestoreInProgress is returned by resume/abort when there is no journal
+var ErrNoRestoreInProgress = errors.New("no interrupted restore to resume or abort")
+
+// RestoreInProgressError is returned when a new restore is started while an
+// interrupted one still has a journal
+type RestoreInProgressError struct {
+	Journal *RestoreJournal
+}
+
+func (e *RestoreInProgressError) Error() string {
+	return fmt.Sprintf("an interrupted restore of %s is in progress (%d/%d files)",
+		ShortHash(e.Journal.Source), e.Journal.Done, len(e.Journal.Files))
+}
+
+// RestoreJournal records what a restore intends to change so an interrupted
+// restore can be finished or rolled back
+type RestoreJournal struct {
+	Source    string    `json:"source"` // Snapshot being restored
+	Backup    string    `json:"backup"` // Pre-restore snapshot used by abort
+	Files     []string  `json:"files"`  // Paths relative to the project root
+	Done      int       `json:"done"`   // Number of files already restored
+	StartedAt time.Time `json:"started_at"`
+
+	BackupPatch string `json:"backup_patch,omitempty"` // ID of the reverse patch in RestoreBackupsDir
+
+}
+
+// RestoreProgress is called after each batch with the number of files done
+type RestoreProgress func(done, total int)
+
+// journalPath returns the location of the restore journal
+func (g *GitManager) journalPath() string {
+	return filepath.Join(g.State.ShadowRepoDir, RestoreJournalFile)
+}
+
+// ReadRestoreJournal loads the journal of an interrupted restore, returning
+// ErrNoRestoreInProgress if there is none
+func (g *GitManager) ReadRestoreJournal() (*RestoreJournal, error) {
+	data, err := os.ReadFile(g.journalPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNoRestoreInProgress
+		}
+		return nil, fmt.Errorf("failed to read restore journal: %w", err)
+	}
+
+	var journal RestoreJournal
+	if err := json.Unmarshal(data, &journal); err != nil {
+		return nil, fmt.Errorf("failed to parse restore journal: %w", err)
+	}
+
+	return &journal, nil
+}
+
+// writeRestoreJournal atomically replaces the journal on disk
+func (g *GitManager) writeRestoreJournal(journal *RestoreJournal) error {
+	data, err := json.MarshalIndent(journal, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := g.journalPath() + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write restore journal: %w", err)
+	}
+	if err := os.Rename(tmp, g.journalPath()); err != nil {
+		return fmt.Errorf("failed to write restore journal: %w", err)
+	}
+
+	return nil
+}
+
+// JournaledRestore restores files from a snapshot in batches. Before touching
+// the working tree it takes a pre-restore snapshot and writes an intent log,
+// so an interruption can be finished with ResumeRestore or rolled back with
+// AbortRestore. pathspecs limit the restore; none means the whole tree.
+// Cancelling ctx stops between batches and leaves the journal in place.
+func (g *GitManager) JournaledRestore(ctx context.Context, hash string, pathspecs []string, batchSize int, progress RestoreProgress) (*RestoreJournal, error) {
+	if journal, err := g.ReadRestoreJournal(); err == nil {
+		return nil, &RestoreInProgressError{Journal: journal}
+	} else if !errors.Is(err, ErrNoRestoreInProgress) {
+		return nil, err
+	}
+
+	source, err := g.RunCommand("rev-parse", "--verify", hash+"^{commit}")
+	if err != nil {
+		return nil, NewNotFoundError("snapshot %s not found: %w", hash, err)
+	}
+
+	// Capture the current state so the restore can be rolled back
+	if err := g.CreateSnapshot("Pre-restore backup before restoring " + ShortHash(source)); err != nil {
+		return nil, fmt.Errorf("failed to create pre-restore snapshot: %w", err)
+	}
+	backup, err := g.RunCommand("rev-parse", "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve pre-restore snapshot: %w", err)
+	}
+
+	changes, err := g.CompareWorktree(source, g.icasePathspecs(pathspecs)...)
+	if err != nil {
+		return nil, err
+	}
+
+	journal := &RestoreJournal{
+		Source:    source,
+		Backup:    backup,
+		Files:     make([]string, 0, len(changes)),
+		StartedAt: time.Now(),
+	}
+	// Paths differing only in case are one file on case-insensitive
+	// filesystems; restoring it once is enough
+	fold := g.State.CaseInsensitive()
+	seen := make(map[string]bool, len(changes))
+	for _, change := range changes {
+		if key := pathKey(change.Path, fold); !seen[key] {
+			seen[key] = true
+			journal.Files = append(journal.Files, change.Path)
+		}
+	}
+
+	if len(journal.Files) == 0 {
+		return journal, nil
+	}
+
+	// Save what the restore replaces before replacing anything
+	if journal.BackupPatch, err = g.saveRestoreBackup(source, backup, source, journal.Files); err != nil {
+		return nil, err
+	}
+	if err := g.writeRestoreJournal(journal); err != nil {
+		return nil, err
+	}
+
+	return journal, g.applyJournal(ctx, journal, batchSize, progress)
+}
+
+// ResumeRestore finishes an interrupted restore
+func (g *GitManager) ResumeRestore(ctx context.Context, batchSize int, progress RestoreProgress) (*RestoreJournal, error) {
+	journal, err := g.ReadRestoreJournal()
+	if err != nil {
+		return nil, err
+	}
+
+	return journal, g.applyJournal(ctx, journal, batchSize, progress)
+}
+
+// AbortRestore rolls every journaled file back to the pre-restore snapshot
+// and discards the journal
+func (g *GitManager) AbortRestore() (*RestoreJournal, error) {
+	journal, err := g.ReadRestoreJournal()
+	if err != nil {
+		return nil, err
+	}
+
+	// Files that did not exist before the restore are removed again
+	for start := 0; start < len(journal.Files); start += DefaultRestoreBatchSize {
+		end := min(start+DefaultRestoreBatchSize, len(journal.Files))
+		if err := g.restorePaths(journal.Backup, journal.Files[start:end]); err != nil {
+			return journal, fmt.Errorf("failed to roll back restore: %w", err)
+		}
+	}
+
+	if err := os.Remove(g.journalPath()); err != nil {
+		return journal, fmt.Errorf("failed to remove restore journal: %w", err)
+	}
+
+	return journal, nil
+}
+
+// applyJournal restores the remaining files batch by batch, recording
+// progress after each batch. Re-applying a batch is harmless, so a crash
+// between restoring and recording only repeats work.
+func (g *GitManager) applyJournal(ctx context.Context, journal *RestoreJournal, batchSize int, progress RestoreProgress) error {
+	if batchSize <= 0 {
+		batchSize = DefaultRestoreBatchSize
+	}
+
+	for journal.Done < len(journal.Files) {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		end := min(journal.Done+batchSize, len(journal.Files))
+		if err := g.restorePaths(journal.Source, journal.Files[journal.Done:end]); err != nil {
+			return err
+		}
+
+		journal.Done = end
+		if err := g.writeRestoreJournal(journal); err != nil {
+			return err
+		}
+		if progress != nil {
+			progress(journal.Done, len(journal.Files))
+		}
+	}
+
+	if err := os.Remove(g.journalPath()); err != nil {
+		return fmt.Errorf("failed to remove restore journal: %w", err)
+	}
+
+	return nil
+}
+
+// restorePaths makes the given root-relative paths match the snapshot:
+// paths in the snapshot are restored, paths missing from it are deleted
+func (g *GitManager) restorePaths(hash string, paths []string) error {
+	// On case-insensitive filesystems a path that differs from a snapshot
+	// path only in case is the same file: restore it under the snapshot's
+	// spelling, never delete it. ls-tree has no icase pathspecs, so the
+	// whole tree is listed and matched case-folded instead.
+	fold := g.State.CaseInsensitive()
+
+	args := []string{"ls-tree", "-r", "-z", "--name-only", "--full-tree", hash}
+	if !fold {
+		args = append(append(args, "--"), topPathspecs(paths)...)
+	}
+	output, err := g.RunCommand(args...)
+	if err != nil {
+		return fmt.Errorf("failed to list snapshot files: %w", err)
+	}
+
+	inSnapshot := make(map[string]string)
+	for _, path := range strings.Split(output, "\x00") {
+		if path != "" {
+			inSnapshot[pathKey(path, fold)] = path
+		}
+	}
+
+	var present []string
+	for _, path := range paths {
+		if snapshotPath, ok := inSnapshot[pathKey(path, fold)]; ok {
+			present = append(present, snapshotPath)
+			continue
+		}
+		if err := os.Remove(filepath.Join(g.State.ProjectRoot, filepath.FromSlash(path))); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove %s: %w", path, err)
+		}
+	}
+
+	if len(present) == 0 {
+		return nil
+	}
+	return g.RestoreSnapshot(hash, topPathspecs(present))
+}
+
+// topPathspecs turns project-root-relative paths into literal pathspecs that
+// git resolves independently of the current directory
+func topPathspecs(paths []string) []string {
+	pathspecs := make([]string, len(paths))
+	for i, path := range paths {
+		pathspecs[i] = topPathspecPrefix + path
+	}
+	return pathspecs
+}
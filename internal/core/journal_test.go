@@ -0,0 +1,152 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// setupRestoreScenario creates a snapshot with three files, then changes all
+// of them so restoring the snapshot touches every file
+func setupRestoreScenario(t *testing.T) (string, *GitManager, string) {
+	t.Helper()
+	tempDir, _, gitManager := setupTestRepo(t)
+
+	for _, name := range []string{"a.txt", "b.txt", "c.txt"} {
+		if err := os.WriteFile(filepath.Join(tempDir, name), []byte("original\n"), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	if err := gitManager.CreateSnapshot("original"); err != nil {
+		t.Fatalf("Failed to create snapshot: %v", err)
+	}
+	source, err := gitManager.RunCommand("rev-parse", "HEAD")
+	if err != nil {
+		t.Fatalf("Failed to resolve HEAD: %v", err)
+	}
+
+	for _, name := range []string{"a.txt", "b.txt"} {
+		if err := os.WriteFile(filepath.Join(tempDir, name), []byte("edited\n"), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	if err := os.Remove(filepath.Join(tempDir, "c.txt")); err != nil {
+		t.Fatalf("Failed to remove c.txt: %v", err)
+	}
+
+	return tempDir, gitManager, source
+}
+
+func readContent(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "<missing>"
+		}
+		t.Fatalf("Failed to read %s: %v", path, err)
+	}
+	return string(data)
+}
+
+func TestJournaledRestore(t *testing.T) {
+	tempDir, gitManager, source := setupRestoreScenario(t)
+	defer os.RemoveAll(tempDir)
+
+	journal, err := gitManager.JournaledRestore(context.Background(), source, nil, 2, nil)
+	if err != nil {
+		t.Fatalf("JournaledRestore failed: %v", err)
+	}
+
+	if len(journal.Files) != 3 || journal.Done != 3 {
+		t.Errorf("Expected 3 of 3 files restored, got %d of %d", journal.Done, len(journal.Files))
+	}
+	for _, name := range []string{"a.txt", "b.txt", "c.txt"} {
+		if got := readContent(t, filepath.Join(tempDir, name)); got != "original\n" {
+			t.Errorf("Expected %s to be restored, got %q", name, got)
+		}
+	}
+	if _, err := gitManager.ReadRestoreJournal(); !errors.Is(err, ErrNoRestoreInProgress) {
+		t.Errorf("Expected journal to be removed, got %v", err)
+	}
+
+	// The pre-restore snapshot holds the edited content
+	backup, err := gitManager.RunCommand("show", journal.Backup+":a.txt")
+	if err != nil || backup != "edited" {
+		t.Errorf("Expected pre-restore snapshot with edited content, got %q (%v)", backup, err)
+	}
+}
+
+func TestJournaledRestoreResume(t *testing.T) {
+	tempDir, gitManager, source := setupRestoreScenario(t)
+	defer os.RemoveAll(tempDir)
+
+	// Interrupt after the first batch
+	ctx, cancel := context.WithCancel(context.Background())
+	_, err := gitManager.JournaledRestore(ctx, source, nil, 1, func(done, total int) { cancel() })
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected interrupted restore, got %v", err)
+	}
+
+	journal, err := gitManager.ReadRestoreJournal()
+	if err != nil {
+		t.Fatalf("Expected journal after interruption: %v", err)
+	}
+	if journal.Done != 1 {
+		t.Errorf("Expected 1 file done, got %d", journal.Done)
+	}
+
+	// A new restore must not start on top of the interrupted one
+	var inProgress *RestoreInProgressError
+	if _, err := gitManager.JournaledRestore(context.Background(), source, nil, 1, nil); !errors.As(err, &inProgress) {
+		t.Errorf("Expected RestoreInProgressError, got %v", err)
+	}
+
+	if _, err := gitManager.ResumeRestore(context.Background(), 1, nil); err != nil {
+		t.Fatalf("ResumeRestore failed: %v", err)
+	}
+	for _, name := range []string{"a.txt", "b.txt", "c.txt"} {
+		if got := readContent(t, filepath.Join(tempDir, name)); got != "original\n" {
+			t.Errorf("Expected %s to be restored, got %q", name, got)
+		}
+	}
+	if _, err := gitManager.ReadRestoreJournal(); !errors.Is(err, ErrNoRestoreInProgress) {
+		t.Errorf("Expected journal to be removed, got %v", err)
+	}
+}
+
+func TestJournaledRestoreAbort(t *testing.T) {
+	tempDir, gitManager, source := setupRestoreScenario(t)
+	defer os.RemoveAll(tempDir)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := gitManager.JournaledRestore(ctx, source, nil, 1, nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected interrupted restore, got %v", err)
+	}
+
+	// Simulate a partially applied batch
+	if err := os.WriteFile(filepath.Join(tempDir, "a.txt"), []byte("original\n"), 0644); err != nil {
+		t.Fatalf("Failed to write a.txt: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, "c.txt"), []byte("original\n"), 0644); err != nil {
+		t.Fatalf("Failed to write c.txt: %v", err)
+	}
+
+	if _, err := gitManager.AbortRestore(); err != nil {
+		t.Fatalf("AbortRestore failed: %v", err)
+	}
+
+	expected := map[string]string{"a.txt": "edited\n", "b.txt": "edited\n", "c.txt": "<missing>"}
+	for name, want := range expected {
+		if got := readContent(t, filepath.Join(tempDir, name)); got != want {
+			t.Errorf("Expected %s to be %q after abort, got %q", name, want, got)
+		}
+	}
+	if _, err := gitManager.AbortRestore(); !errors.Is(err, ErrNoRestoreInProgress) {
+		t.Errorf("Expected ErrNoRestoreInProgress after abort, got %v", err)
+	}
+}
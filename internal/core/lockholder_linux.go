@@ -0,0 +1,38 @@
+//go:build linux
+
+package core
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// shadowRepoInUse reports whether another process was started with
+// --git-dir pointing at the shadow repository. known is always true on Linux.
+func shadowRepoInUse(shadowRepoDir string) (busy bool, known bool) {
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return false, false
+	}
+
+	needle := []byte("--git-dir=" + shadowRepoDir + "\x00")
+	self := os.Getpid()
+
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil || pid == self {
+			continue
+		}
+		cmdline, err := os.ReadFile(filepath.Join("/proc", entry.Name(), "cmdline"))
+		if err != nil {
+			continue // Exited or not ours to read
+		}
+		if bytes.Contains(append(cmdline, 0), needle) {
+			return true, true
+		}
+	}
+
+	return false, true
+}
@@ -0,0 +1,9 @@
+//go:build !linux
+
+package core
+
+// shadowRepoInUse cannot inspect other processes on this platform, so
+// callers fall back to the lock file age
+func shadowRepoInUse(shadowRepoDir string) (busy bool, known bool) {
+	return false, false
+}
@@ -0,0 +1,311 @@
+package core
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// MaterializeResult describes a snapshot written out by MaterializeSnapshot
+type MaterializeResult struct {
+	Files  int   // Files written to the destination
+	Cloned int   // Files cloned copy-on-write from the working tree
+	Bytes  int64 // Total size of the files written
+}
+
+// treeEntry is one file of a snapshot as listed by `git ls-tree -l`
+type treeEntry struct {
+	mode   string
+	object string
+	size   int64
+	path   string
+}
+
+// perm returns the permissions a regular file entry is written with
+func (e treeEntry) perm() os.FileMode {
+	if e.mode == "100755" {
+		return 0755
+	}
+	return 0644
+}
+
+// MaterializeSnapshot writes the files of a snapshot, optionally limited to
+// paths (relative to the current directory, absolute, or :(top,literal)
+// pathspecs), into dest without touching the working tree. dest must be
+// missing or empty.
+//
+// Files that are unchanged in the working tree are cloned copy-on-write where
+// the filesystem supports it (reflinks on Btrfs and XFS, clonefile on APFS),
+// so even large snapshots materialize almost instantly and share storage
+// until either copy is modified. Everywhere else they are copied, and
+// changed files are written from the shadow repository. Hard links are never
+// used: editing the copy would also change the working tree.
+func (g *GitManager) MaterializeSnapshot(hash, dest string, paths []string) (*MaterializeResult, error) {
+	if strings.HasPrefix(hash, "-") {
+		return nil, NewValidationError("invalid snapshot hash %q", hash)
+	}
+	commit, err := g.RunCommand("rev-parse", "--verify", "--quiet", hash+"^{commit}")
+	if err != nil {
+		return nil, fmt.Errorf("snapshot %s does not exist", hash)
+	}
+
+	var rels []string
+	for _, path := range paths {
+		rel, err := g.rootRelativePath(path)
+		if err != nil {
+			return nil, err
+		}
+		if rel == "" {
+			rels = nil // The project root: everything
+			break
+		}
+		rels = append(rels, rel)
+	}
+
+	pathspecs := topPathspecs(rels)
+	entries, err := g.listTreeEntries(commit, pathspecs)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("no files in snapshot %s match the given paths", ShortHash(commit))
+	}
+
+	dest, err = filepath.Abs(dest)
+	if err != nil {
+		return nil, fmt.Errorf("invalid destination: %w", err)
+	}
+	if err := prepareDestination(dest); err != nil {
+		return nil, err
+	}
+
+	changes, err := g.CompareWorktree(commit, g.icasePathspecs(pathspecs)...)
+	if err != nil {
+		return nil, err
+	}
+	fold := g.State.CaseInsensitive()
+	changed := make(map[string]bool, len(changes))
+	for _, change := range changes {
+		changed[pathKey(change.Path, fold)] = true
+	}
+
+	result := &MaterializeResult{}
+	canClone := true
+	var fromRepo []treeEntry
+
+	for _, entry := range entries {
+		target := filepath.Join(dest, filepath.FromSlash(entry.path))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create directory for %s: %w", entry.path, err)
+		}
+		if entry.mode == "120000" || changed[pathKey(entry.path, fold)] {
+			fromRepo = append(fromRepo, entry)
+			continue
+		}
+
+		source := filepath.Join(g.State.ProjectRoot, filepath.FromSlash(entry.path))
+		if canClone {
+			err := cloneFile(source, target, entry.perm())
+			if err == nil {
+				result.Files++
+				result.Cloned++
+				result.Bytes += entry.size
+				continue
+			}
+			// Unsupported here; don't pay for a failing syscall per file
+			canClone = errors.Is(err, fs.ErrNotExist)
+		}
+		if err := copyFile(source, target, entry.perm()); err != nil {
+			// Changed or removed since the comparison; the snapshot has it
+			os.Remove(target)
+			fromRepo = append(fromRepo, entry)
+			continue
+		}
+		result.Files++
+		result.Bytes += entry.size
+	}
+
+	if err := g.writeBlobs(dest, fromRepo); err != nil {
+		return nil, err
+	}
+	for _, entry := range fromRepo {
+		result.Files++
+		result.Bytes += entry.size
+	}
+
+	return result, nil
+}
+
+// prepareDestination creates dest, or checks that an existing dest is an
+// empty directory so nothing is overwritten
+func prepareDestination(dest string) error {
+	entries, err := os.ReadDir(dest)
+	switch {
+	case os.IsNotExist(err):
+		if err := os.MkdirAll(dest, 0755); err != nil {
+			return fmt.Errorf("failed to create %s: %w", dest, err)
+		}
+		return nil
+	case err != nil:
+		return fmt.Errorf("cannot use %s as destination: %w", dest, err)
+	case len(entries) > 0:
+		return fmt.Errorf("destination %s is not empty", dest)
+	}
+	return nil
+}
+
+// listTreeEntries lists the files (blobs and symlinks) of a snapshot,
+// optionally limited to pathspecs
+func (g *GitManager) listTreeEntries(commit string, pathspecs []string) ([]treeEntry, error) {
+	args := append([]string{"ls-tree", "-r", "-z", "-l", "--full-tree", commit, "--"}, pathspecs...)
+	output, err := g.RunCommand(args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshot files: %w", err)
+	}
+
+	var entries []treeEntry
+	for _, record := range strings.Split(output, "\x00") {
+		// "<mode> <type> <object> <size>\t<path>"
+		meta, path, ok := strings.Cut(record, "\t")
+		fields := strings.Fields(meta)
+		if !ok || len(fields) != 4 || fields[1] != "blob" {
+			continue // Submodules have no content to write
+		}
+		size, _ := strconv.ParseInt(fields[3], 10, 64)
+		entries = append(entries, treeEntry{mode: fields[0], object: fields[2], size: size, path: path})
+	}
+
+	return entries, nil
+}
+
+// copyFile copies a working tree file to a new file at dst
+func copyFile(src, dst string, mode os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_EXCL|os.O_WRONLY, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// writeBlobs writes the content of the given entries below dest
+func (g *GitManager) writeBlobs(dest string, entries []treeEntry) error {
+	objects := make([]string, len(entries))
+	for i, entry := range entries {
+		objects[i] = entry.object
+	}
+
+	return g.batchObjects(objects, func(i int, _ string, content []byte, err error) error {
+		entry := entries[i]
+		if err != nil {
+			return fmt.Errorf("failed to read %s from snapshot: %w", entry.path, err)
+		}
+		if err := writeEntry(filepath.Join(dest, filepath.FromSlash(entry.path)), entry, content); err != nil {
+			return fmt.Errorf("failed to write %s: %w", entry.path, err)
+		}
+		return nil
+	})
+}
+
+// batchObjects streams objects through a single `git cat-file --batch`
+// rather than one git process per object, calling fn in order with the index,
+// type and content of each. Missing objects reach fn as errObjectMissing;
+// any other read error is passed to fn and ends the stream. Iteration stops
+// at the first error fn returns, which is returned.
+func (g *GitManager) batchObjects(objects []string, fn func(i int, objectType string, content []byte, err error) error) error {
+	if len(objects) == 0 {
+		return nil
+	}
+
+	cmd := exec.Command("git", "--git-dir="+g.State.ShadowRepoDir, "cat-file", "--batch")
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return fmt.Errorf("failed to read snapshot objects: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("failed to read snapshot objects: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to read snapshot objects: %w", err)
+	}
+
+	go func() {
+		defer stdin.Close()
+		w := bufio.NewWriter(stdin)
+		for _, object := range objects {
+			fmt.Fprintln(w, object)
+		}
+		w.Flush()
+	}()
+
+	var fnErr error
+	reader := bufio.NewReader(stdout)
+	for i := range objects {
+		objectType, content, err := readBatchObject(reader)
+		if fnErr = fn(i, objectType, content, err); fnErr != nil {
+			break
+		}
+		if err != nil && err != errObjectMissing {
+			break // git gives up on objects it cannot inflate
+		}
+	}
+
+	// After stopping early git would block writing output nobody reads
+	cmd.Process.Kill()
+	cmd.Wait()
+	return fnErr
+}
+
+// errObjectMissing is returned by readBatchObject for objects git doesn't have
+var errObjectMissing = errors.New("object missing")
+
+// readBatchObject reads one "<object> <type> <size>\n<content>\n" record
+// from `git cat-file --batch`, returning the type and content
+func readBatchObject(reader *bufio.Reader) (string, []byte, error) {
+	header, err := reader.ReadString('\n')
+	if err != nil {
+		return "", nil, err
+	}
+	fields := strings.Fields(header)
+	if len(fields) == 2 && fields[1] == "missing" {
+		return "", nil, errObjectMissing
+	}
+	if len(fields) != 3 {
+		return "", nil, fmt.Errorf("unexpected object header %q", strings.TrimSpace(header))
+	}
+	size, err := strconv.Atoi(fields[2])
+	if err != nil {
+		return "", nil, fmt.Errorf("unexpected object header %q", strings.TrimSpace(header))
+	}
+
+	content := make([]byte, size+1) // Content is followed by a newline
+	if _, err := io.ReadFull(reader, content); err != nil {
+		return "", nil, err
+	}
+	return fields[1], content[:size], nil
+}
+
+// writeEntry creates a file or symlink with a snapshot entry's content
+func writeEntry(target string, entry treeEntry, content []byte) error {
+	if entry.mode == "120000" {
+		return os.Symlink(string(content), target)
+	}
+	return os.WriteFile(target, content, entry.perm())
+}
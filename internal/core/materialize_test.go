@@ -0,0 +1,133 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMaterializeSnapshot(t *testing.T) {
+	tempDir, _, gitManager := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	files := map[string]string{
+		"unchanged.txt":  "same",
+		"changed.txt":    "before",
+		"deleted.txt":    "gone",
+		"src/nested.txt": "nested",
+	}
+	for name, content := range files {
+		path := filepath.Join(tempDir, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, "run.sh"), []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatalf("Failed to write run.sh: %v", err)
+	}
+	if err := gitManager.CreateSnapshot("snapshot"); err != nil {
+		t.Fatalf("CreateSnapshot failed: %v", err)
+	}
+	hash, _ := gitManager.RunCommand("rev-parse", "HEAD")
+
+	// Change the working tree after the snapshot
+	os.WriteFile(filepath.Join(tempDir, "changed.txt"), []byte("after"), 0644)
+	os.Remove(filepath.Join(tempDir, "deleted.txt"))
+	os.WriteFile(filepath.Join(tempDir, "new.txt"), []byte("new"), 0644)
+
+	dest := filepath.Join(t.TempDir(), "preview")
+	result, err := gitManager.MaterializeSnapshot(hash, dest, nil)
+	if err != nil {
+		t.Fatalf("MaterializeSnapshot failed: %v", err)
+	}
+	if result.Files != 5 {
+		t.Errorf("Expected 5 files, got %d", result.Files)
+	}
+
+	files["run.sh"] = "#!/bin/sh\n"
+	for name, expected := range files {
+		content, err := os.ReadFile(filepath.Join(dest, filepath.FromSlash(name)))
+		if err != nil || string(content) != expected {
+			t.Errorf("Expected %s to contain %q, got %q (%v)", name, expected, content, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dest, "new.txt")); !os.IsNotExist(err) {
+		t.Errorf("Expected new.txt, created after the snapshot, not to be written")
+	}
+	if info, err := os.Stat(filepath.Join(dest, "run.sh")); err != nil || info.Mode().Perm()&0100 == 0 {
+		t.Errorf("Expected run.sh to stay executable, got %v (%v)", info, err)
+	}
+
+	// The working tree is untouched
+	if content, _ := os.ReadFile(filepath.Join(tempDir, "changed.txt")); string(content) != "after" {
+		t.Errorf("Expected working tree to keep its changes, got %q", content)
+	}
+
+	// A copy is independent of the working tree
+	os.WriteFile(filepath.Join(dest, "unchanged.txt"), []byte("edited copy"), 0644)
+	if content, _ := os.ReadFile(filepath.Join(tempDir, "unchanged.txt")); string(content) != "same" {
+		t.Errorf("Expected editing the copy to leave the working tree alone, got %q", content)
+	}
+}
+
+func TestMaterializeSnapshotPaths(t *testing.T) {
+	tempDir, _, gitManager := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	os.MkdirAll(filepath.Join(tempDir, "src"), 0755)
+	os.WriteFile(filepath.Join(tempDir, "src", "a.txt"), []byte("a"), 0644)
+	os.WriteFile(filepath.Join(tempDir, "b.txt"), []byte("b"), 0644)
+	if err := gitManager.CreateSnapshot("snapshot"); err != nil {
+		t.Fatalf("CreateSnapshot failed: %v", err)
+	}
+
+	dest := t.TempDir()
+	result, err := gitManager.MaterializeSnapshot("HEAD", dest, []string{filepath.Join(tempDir, "src")})
+	if err != nil {
+		t.Fatalf("MaterializeSnapshot failed: %v", err)
+	}
+	if result.Files != 1 {
+		t.Errorf("Expected 1 file, got %d", result.Files)
+	}
+	if _, err := os.Stat(filepath.Join(dest, "b.txt")); !os.IsNotExist(err) {
+		t.Errorf("Expected b.txt to be left out")
+	}
+}
+
+func TestMaterializeSnapshotErrors(t *testing.T) {
+	tempDir, _, gitManager := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	snapshotFile(t, tempDir, gitManager, "v1")
+
+	notEmpty := t.TempDir()
+	os.WriteFile(filepath.Join(notEmpty, "keep.txt"), []byte("keep"), 0644)
+
+	tests := []struct {
+		name     string
+		hash     string
+		dest     string
+		paths    []string
+		expected string
+	}{
+		{"unknown snapshot", "deadbeef", t.TempDir(), nil, "does not exist"},
+		{"option-like hash", "--all", t.TempDir(), nil, "invalid snapshot hash"},
+		{"destination not empty", "HEAD", notEmpty, nil, "not empty"},
+		{"no matching files", "HEAD", t.TempDir(), []string{filepath.Join(tempDir, "missing.txt")}, "no files"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := gitManager.MaterializeSnapshot(tt.hash, tt.dest, tt.paths)
+			if err == nil || !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("Expected error containing %q, got %v", tt.expected, err)
+			}
+		})
+	}
+
+	if content, _ := os.ReadFile(filepath.Join(notEmpty, "keep.txt")); string(content) != "keep" {
+		t.Errorf("Expected existing files to be left alone, got %q", content)
+	}
+}
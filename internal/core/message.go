@@ -0,0 +1,123 @@
+package core
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// DefaultMessageTemplate is used for snapshots without a message when
+// git.message_template is not set
+const DefaultMessageTemplate = "Snapshot at {{.Time}}"
+
+// maxMessageDirs bounds the directories listed in MessageData.Dirs
+const maxMessageDirs = 5
+
+// MessageData is what git.message_template can refer to
+type MessageData struct {
+	Time      string    // 15:04:05
+	Date      string    // 2006-01-02
+	Timestamp time.Time // For custom layouts: {{.Timestamp.Format "Jan 2 15:04"}}
+	Branch    string    // Branch checked out in the project, or a short hash when detached
+	FileCount int       // Files changed since the previous snapshot
+	Files     []string  // Their paths, slash-separated
+	Dirs      []string  // Top-level directories touched, "." for the root, at most 5
+}
+
+// messageFuncs are available in message templates
+var messageFuncs = template.FuncMap{
+	"join": strings.Join,
+}
+
+// RenderMessage executes a snapshot message template
+func RenderMessage(text string, data MessageData) (string, error) {
+	tmpl, err := template.New("message").Funcs(messageFuncs).Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// NewMessageData describes a snapshot of the given changed files
+func NewMessageData(state *AppState, files []string, now time.Time) MessageData {
+	data := MessageData{
+		Time:      now.Format("15:04:05"),
+		Date:      now.Format("2006-01-02"),
+		Timestamp: now,
+		Branch:    projectBranch(state.GitDir),
+		FileCount: len(files),
+		Files:     files,
+	}
+
+	seen := make(map[string]bool)
+	for _, file := range files {
+		dir := "."
+		if top, _, ok := strings.Cut(file, "/"); ok {
+			dir = top
+		}
+		if !seen[dir] {
+			seen[dir] = true
+			data.Dirs = append(data.Dirs, dir)
+		}
+	}
+	sort.Strings(data.Dirs)
+	if len(data.Dirs) > maxMessageDirs {
+		data.Dirs = append(data.Dirs[:maxMessageDirs], "…")
+	}
+	return data
+}
+
+// snapshotMessage renders the configured message template for the given
+// changed files, falling back to DefaultMessageTemplate when the template is
+// unset or fails
+func (g *GitManager) snapshotMessage(files []string) string {
+	data := NewMessageData(g.State, files, time.Now())
+
+	if g.State.Config != nil && g.State.Config.Git.MessageTemplate != "" {
+		if message, err := RenderMessage(g.State.Config.Git.MessageTemplate, data); err == nil && message != "" {
+			return message
+		}
+	}
+	message, _ := RenderMessage(DefaultMessageTemplate, data)
+	return message
+}
+
+// stagedFiles lists the paths staged for the next snapshot
+func (g *GitManager) stagedFiles() []string {
+	output, err := g.runCommandRaw("diff", "--cached", "--name-only", "-z", "--no-renames")
+	if err != nil {
+		return nil
+	}
+	var files []string
+	for _, file := range strings.Split(string(output), "\x00") {
+		if file != "" {
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+// projectBranch returns the branch checked out in the project repository,
+// read from HEAD directly so it works without a git binary
+func projectBranch(gitDir string) string {
+	content, err := os.ReadFile(filepath.Join(gitDir, "HEAD"))
+	if err != nil {
+		return ""
+	}
+	head := strings.TrimSpace(string(content))
+	if ref, ok := strings.CutPrefix(head, "ref: "); ok {
+		return strings.TrimPrefix(ref, "refs/heads/")
+	}
+	if len(head) > 7 {
+		return head[:7]
+	}
+	return head
+}
@@ -0,0 +1,131 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/config"
+)
+
+func TestNewMessageData(t *testing.T) {
+	gitDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(gitDir, "HEAD"), []byte("ref: refs/heads/feature/login\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2024, 3, 9, 14, 5, 7, 0, time.Local)
+	files := []string{"src/a.go", "README.md", "docs/x.md", "src/b.go"}
+	data := NewMessageData(&AppState{GitDir: gitDir}, files, now)
+
+	if data.Time != "14:05:07" || data.Date != "2024-03-09" {
+		t.Errorf("Expected 14:05:07 on 2024-03-09, got %s on %s", data.Time, data.Date)
+	}
+	if data.Branch != "feature/login" {
+		t.Errorf("Expected branch feature/login, got %q", data.Branch)
+	}
+	if data.FileCount != 4 {
+		t.Errorf("Expected 4 files, got %d", data.FileCount)
+	}
+	if expected := []string{".", "docs", "src"}; !reflect.DeepEqual(data.Dirs, expected) {
+		t.Errorf("Expected dirs %v, got %v", expected, data.Dirs)
+	}
+
+	// Detached HEAD shows a short hash
+	if err := os.WriteFile(filepath.Join(gitDir, "HEAD"), []byte("0123456789abcdef0123456789abcdef01234567\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if branch := NewMessageData(&AppState{GitDir: gitDir}, nil, now).Branch; branch != "0123456" {
+		t.Errorf("Expected short hash for detached HEAD, got %q", branch)
+	}
+
+	many := []string{"a/1", "b/1", "c/1", "d/1", "e/1", "f/1", "g/1"}
+	if dirs := NewMessageData(&AppState{GitDir: gitDir}, many, now).Dirs; len(dirs) != maxMessageDirs+1 || dirs[maxMessageDirs] != "…" {
+		t.Errorf("Expected %d dirs and an ellipsis, got %v", maxMessageDirs, dirs)
+	}
+}
+
+func TestRenderMessage(t *testing.T) {
+	data := MessageData{Time: "10:00:00", Branch: "main", FileCount: 2, Dirs: []string{"api", "web"}}
+
+	message, err := RenderMessage(`{{.Time}} [{{.Branch}}] {{.FileCount}} files in {{join .Dirs ", "}}`, data)
+	if err != nil {
+		t.Fatalf("RenderMessage failed: %v", err)
+	}
+	if message != "10:00:00 [main] 2 files in api, web" {
+		t.Errorf("Expected rendered message, got %q", message)
+	}
+
+	if _, err := RenderMessage("{{.Nope}}", data); err == nil {
+		t.Errorf("Expected an error for an unknown field")
+	}
+	if _, err := RenderMessage("{{.Time", data); err == nil {
+		t.Errorf("Expected an error for an unterminated action")
+	}
+}
+
+func TestCreateSnapshotMessageTemplate(t *testing.T) {
+	for _, backend := range []string{BackendExec, BackendNative} {
+		t.Run(backend, func(t *testing.T) {
+			tempDir, state, gitManager := setupTestRepo(t)
+			defer os.RemoveAll(tempDir)
+
+			state.Config = &config.Config{Git: config.GitConfig{
+				Backend:         backend,
+				MessageTemplate: "{{.FileCount}} files in {{join .Dirs \",\"}}",
+			}}
+			gitManager = NewGitManager(state)
+
+			if err := os.MkdirAll(filepath.Join(tempDir, "src"), 0755); err != nil {
+				t.Fatal(err)
+			}
+			for _, name := range []string{"src/main.go", "notes.txt"} {
+				if err := os.WriteFile(filepath.Join(tempDir, filepath.FromSlash(name)), []byte(name), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := gitManager.CreateSnapshot(""); err != nil {
+				t.Fatalf("CreateSnapshot failed: %v", err)
+			}
+			assertLatestMessage(t, gitManager, "2 files in .,src")
+
+			if err := os.WriteFile(filepath.Join(tempDir, "src", "main.go"), []byte("changed"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := gitManager.CreateSnapshotForPaths("", []string{"src/main.go"}); err != nil {
+				t.Fatalf("CreateSnapshotForPaths failed: %v", err)
+			}
+			assertLatestMessage(t, gitManager, "1 files in src")
+
+			// A broken template falls back to the default message
+			state.Config.Git.MessageTemplate = "{{.Nope}}"
+			if err := os.WriteFile(filepath.Join(tempDir, "notes.txt"), []byte("changed"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := gitManager.CreateSnapshot(""); err != nil {
+				t.Fatalf("CreateSnapshot failed: %v", err)
+			}
+			snapshots, err := gitManager.ListSnapshots(1, "")
+			if err != nil || len(snapshots) != 1 {
+				t.Fatalf("ListSnapshots failed: %v", err)
+			}
+			if !strings.HasPrefix(snapshots[0].Message, "Snapshot at ") {
+				t.Errorf("Expected the default message, got %q", snapshots[0].Message)
+			}
+		})
+	}
+}
+
+func assertLatestMessage(t *testing.T, gitManager *GitManager, expected string) {
+	t.Helper()
+	snapshots, err := gitManager.ListSnapshots(1, "")
+	if err != nil || len(snapshots) != 1 {
+		t.Fatalf("ListSnapshots failed: %v", err)
+	}
+	if snapshots[0].Message != expected {
+		t.Errorf("Expected message %q, got %q", expected, snapshots[0].Message)
+	}
+}
@@ -0,0 +1,150 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// snapshotDurationBuckets are the upper bounds, in seconds, of the snapshot
+// duration histogram
+var snapshotDurationBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+// Metrics counts watcher activity for the Prometheus endpoint. A nil
+// *Metrics ignores every observation, so the watcher records unconditionally.
+type Metrics struct {
+	mu              sync.Mutex
+	snapshots       uint64
+	failures        uint64
+	filesStaged     uint64
+	debounceFirings uint64
+	durationCounts  []uint64 // Per bucket, not cumulative
+	durationSum     float64
+}
+
+// NewMetrics creates an empty set of counters
+func NewMetrics() *Metrics {
+	return &Metrics{durationCounts: make([]uint64, len(snapshotDurationBuckets)+1)}
+}
+
+// debounceFired counts a firing of the debounced (or flushed) snapshot
+func (m *Metrics) debounceFired() {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.debounceFirings++
+}
+
+// observeSnapshot records a snapshot attempt that took d and, when it
+// succeeded, staged files changed paths
+func (m *Metrics) observeSnapshot(d time.Duration, files int, err error) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err != nil {
+		m.failures++
+	} else {
+		m.snapshots++
+		m.filesStaged += uint64(files)
+	}
+
+	seconds := d.Seconds()
+	bucket := len(snapshotDurationBuckets)
+	for i, bound := range snapshotDurationBuckets {
+		if seconds <= bound {
+			bucket = i
+			break
+		}
+	}
+	m.durationCounts[bucket]++
+	m.durationSum += seconds
+}
+
+// SetMetrics makes the watcher record activity into m; call before Start
+func (w *Watcher) SetMetrics(m *Metrics) {
+	w.metrics = m
+}
+
+// WriteMetrics writes the watcher's counters and current state in the
+// Prometheus text exposition format
+func (w *Watcher) WriteMetrics(out io.Writer) {
+	stats := w.Stats()
+
+	m := w.metrics
+	if m == nil {
+		m = NewMetrics()
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	metric := func(name, kind, help string, value interface{}) {
+		fmt.Fprintf(out, "# HELP %s %s\n# TYPE %s %s\n%s %v\n", name, help, name, kind, name, value)
+	}
+	metric("timemachine_snapshots_total", "counter", "Snapshots created by the watcher.", m.snapshots)
+	metric("timemachine_snapshot_failures_total", "counter", "Snapshot attempts that failed.", m.failures)
+	metric("timemachine_files_staged_total", "counter", "Changed paths recorded in snapshots.", m.filesStaged)
+	metric("timemachine_debounce_firings_total", "counter", "Times the debouncer or a full batch triggered a snapshot.", m.debounceFirings)
+	metric("timemachine_ignore_cache_hits_total", "counter", "Ignore checks answered from the cache.", stats.IgnoreCacheHits)
+	metric("timemachine_ignore_cache_misses_total", "counter", "Ignore checks that missed the cache.", stats.IgnoreCacheMisses)
+	metric("timemachine_ignore_cache_evictions_total", "counter", "Ignore results evicted from the cache.", stats.IgnoreCacheEvictions)
+	metric("timemachine_watched_directories", "gauge", "Directories currently watched.", stats.WatchedDirs)
+	metric("timemachine_watched_files", "gauge", "Files in watched directories, counted against watcher.max_watched_files.", stats.WatchedFiles)
+	metric("timemachine_polled_directories", "gauge", "Directories polled because of watch limits.", stats.PolledDirs)
+	metric("timemachine_pending_changes", "gauge", "Changed paths waiting for the next snapshot.", stats.PendingChanges)
+	metric("timemachine_start_time_seconds", "gauge", "When the watcher started, in seconds since the epoch.", stats.StartedAt.Unix())
+
+	name := "timemachine_snapshot_duration_seconds"
+	fmt.Fprintf(out, "# HELP %s Time taken to stage and commit a snapshot.\n# TYPE %s histogram\n", name, name)
+	var cumulative uint64
+	for i, bound := range snapshotDurationBuckets {
+		cumulative += m.durationCounts[i]
+		fmt.Fprintf(out, "%s_bucket{le=\"%g\"} %d\n", name, bound, cumulative)
+	}
+	cumulative += m.durationCounts[len(snapshotDurationBuckets)]
+	fmt.Fprintf(out, "%s_bucket{le=\"+Inf\"} %d\n%s_sum %g\n%s_count %d\n", name, cumulative, name, m.durationSum, name, cumulative)
+}
+
+// ServeMetrics starts recording metrics and serves them at /metrics on addr
+// until the returned server is shut down. Listening happens before it
+// returns, so a busy port is reported right away.
+func (w *Watcher) ServeMetrics(addr string) (*http.Server, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for metrics on %s: %w", addr, err)
+	}
+
+	if w.metrics == nil {
+		w.SetMetrics(NewMetrics())
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		w.WriteMetrics(rw)
+	})
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+
+	go func() {
+		if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			fmt.Printf("Warning: metrics endpoint stopped: %v\n", err)
+		}
+	}()
+	return server, nil
+}
+
+// StopMetrics shuts a metrics server down, waiting briefly for scrapes in
+// flight
+func StopMetrics(server *http.Server) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	server.Shutdown(ctx)
+}
@@ -0,0 +1,55 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteMetrics(t *testing.T) {
+	tempDir, state, gitManager := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	watcher, err := NewWatcher(state, gitManager)
+	if err != nil {
+		t.Fatalf("NewWatcher failed: %v", err)
+	}
+	defer watcher.fsWatcher.Close()
+
+	metrics := NewMetrics()
+	watcher.SetMetrics(metrics)
+	metrics.debounceFired()
+	metrics.debounceFired()
+	metrics.observeSnapshot(30*time.Millisecond, 3, nil)
+	metrics.observeSnapshot(2*time.Second, 5, nil)
+	metrics.observeSnapshot(90*time.Second, 0, errors.New("disk full"))
+
+	var out bytes.Buffer
+	watcher.WriteMetrics(&out)
+
+	for _, line := range []string{
+		"timemachine_snapshots_total 2",
+		"timemachine_snapshot_failures_total 1",
+		"timemachine_files_staged_total 8",
+		"timemachine_debounce_firings_total 2",
+		"# TYPE timemachine_snapshot_duration_seconds histogram",
+		`timemachine_snapshot_duration_seconds_bucket{le="0.05"} 1`,
+		`timemachine_snapshot_duration_seconds_bucket{le="2.5"} 2`,
+		`timemachine_snapshot_duration_seconds_bucket{le="60"} 2`,
+		`timemachine_snapshot_duration_seconds_bucket{le="+Inf"} 3`,
+		"timemachine_snapshot_duration_seconds_count 3",
+	} {
+		if !strings.Contains(out.String(), line+"\n") {
+			t.Errorf("Expected metrics to contain %q, got:\n%s", line, out.String())
+		}
+	}
+}
+
+func TestNilMetricsIgnoresObservations(t *testing.T) {
+	var metrics *Metrics
+	metrics.debounceFired()
+	metrics.observeSnapshot(time.Second, 1, nil)
+}
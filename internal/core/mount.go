@@ -0,0 +1,262 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// MountsFile records the snapshots mounted with MountSnapshot, kept in the
+// shadow repository
+const MountsFile = "mounts.json"
+
+// Mount is a snapshot extracted read-only into a directory. Without FUSE the
+// files are real copies (cloned copy-on-write where possible), so a mount
+// shows the snapshot as of mounting until it is refreshed.
+type Mount struct {
+	Dir       string    `json:"dir"`             // Absolute path of the mount
+	Ref       string    `json:"ref"`             // Snapshot as given, e.g. HEAD~2
+	Snapshot  string    `json:"snapshot"`        // Full hash the ref resolved to
+	Paths     []string  `json:"paths,omitempty"` // Pathspecs mounted; none means all
+	Files     int       `json:"files"`
+	MountedAt time.Time `json:"mounted_at"`
+}
+
+// mountsPath returns the location of the mount registry
+func (g *GitManager) mountsPath() string {
+	return filepath.Join(g.State.ShadowRepoDir, MountsFile)
+}
+
+// Mounts returns the mounted snapshots, ordered by directory
+func (g *GitManager) Mounts() ([]Mount, error) {
+	data, err := os.ReadFile(g.mountsPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read mounts: %w", err)
+	}
+
+	var mounts []Mount
+	if err := json.Unmarshal(data, &mounts); err != nil {
+		return nil, fmt.Errorf("failed to parse mounts: %w", err)
+	}
+	return mounts, nil
+}
+
+// writeMounts atomically replaces the mount registry, removing it when empty
+func (g *GitManager) writeMounts(mounts []Mount) error {
+	if len(mounts) == 0 {
+		if err := os.Remove(g.mountsPath()); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to write mounts: %w", err)
+		}
+		return nil
+	}
+	sort.Slice(mounts, func(i, j int) bool { return mounts[i].Dir < mounts[j].Dir })
+	data, err := json.MarshalIndent(mounts, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := g.mountsPath() + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write mounts: %w", err)
+	}
+	if err := os.Rename(tmp, g.mountsPath()); err != nil {
+		return fmt.Errorf("failed to write mounts: %w", err)
+	}
+	return nil
+}
+
+// findMount returns the index of the mount at dir, or -1
+func findMount(mounts []Mount, dir string) int {
+	for i, mount := range mounts {
+		if mount.Dir == dir {
+			return i
+		}
+	}
+	return -1
+}
+
+// MountSnapshot extracts a snapshot, optionally limited to paths, into dir
+// (which must be missing or empty), makes it read-only and records it so
+// Unmount can clean it up and RefreshMount can follow ref
+func (g *GitManager) MountSnapshot(ref, dir string, paths []string) (*Mount, *MaterializeResult, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid mount directory: %w", err)
+	}
+	mounts, err := g.Mounts()
+	if err != nil {
+		return nil, nil, err
+	}
+	if findMount(mounts, dir) >= 0 {
+		return nil, nil, fmt.Errorf("%s is already a mount; unmount it first or use --refresh", dir)
+	}
+
+	mount, result, err := g.extractMount(ref, dir, paths)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := g.writeMounts(append(mounts, *mount)); err != nil {
+		g.removeMountDir(dir)
+		return nil, nil, err
+	}
+	return mount, result, nil
+}
+
+// extractMount materializes ref into dir read-only
+func (g *GitManager) extractMount(ref, dir string, paths []string) (*Mount, *MaterializeResult, error) {
+	commit, err := g.ResolveSnapshot(ref)
+	if err != nil {
+		return nil, nil, err
+	}
+	result, err := g.MaterializeSnapshot(commit, dir, paths)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := setReadOnly(dir, true); err != nil {
+		g.removeMountDir(dir)
+		return nil, nil, fmt.Errorf("failed to make %s read-only: %w", dir, err)
+	}
+
+	mount := &Mount{
+		Dir:       dir,
+		Ref:       ref,
+		Snapshot:  commit,
+		Paths:     paths,
+		Files:     result.Files,
+		MountedAt: time.Now().UTC(),
+	}
+	return mount, result, nil
+}
+
+// RefreshMount re-extracts a mount if its ref now resolves to another
+// snapshot (e.g. a mount of HEAD after new snapshots), reporting whether it
+// changed
+func (g *GitManager) RefreshMount(dir string) (*Mount, bool, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, false, fmt.Errorf("invalid mount directory: %w", err)
+	}
+	mounts, err := g.Mounts()
+	if err != nil {
+		return nil, false, err
+	}
+	i := findMount(mounts, dir)
+	if i < 0 {
+		return nil, false, fmt.Errorf("%s is not a mounted snapshot", dir)
+	}
+
+	current := mounts[i]
+	commit, err := g.ResolveSnapshot(current.Ref)
+	if err != nil {
+		return nil, false, err
+	}
+	if commit == current.Snapshot {
+		if _, err := os.Stat(dir); err == nil {
+			return &current, false, nil
+		}
+	}
+
+	if err := g.removeMountDir(dir); err != nil {
+		return nil, false, err
+	}
+	mount, _, err := g.extractMount(current.Ref, dir, current.Paths)
+	if err != nil {
+		// The old contents are gone; forget the mount rather than keep a stale record
+		g.writeMounts(append(mounts[:i:i], mounts[i+1:]...))
+		return nil, false, err
+	}
+	mounts[i] = *mount
+	if err := g.writeMounts(mounts); err != nil {
+		return nil, false, err
+	}
+	return mount, true, nil
+}
+
+// Unmount deletes a mounted snapshot's directory and forgets it. A mount
+// whose directory was removed by hand is just forgotten.
+func (g *GitManager) Unmount(dir string) error {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return fmt.Errorf("invalid mount directory: %w", err)
+	}
+	mounts, err := g.Mounts()
+	if err != nil {
+		return err
+	}
+	i := findMount(mounts, dir)
+	if i < 0 {
+		// Never delete a directory we didn't create
+		return fmt.Errorf("%s is not a mounted snapshot", dir)
+	}
+
+	if err := g.removeMountDir(dir); err != nil {
+		return err
+	}
+	return g.writeMounts(append(mounts[:i], mounts[i+1:]...))
+}
+
+// removeMountDir makes a mount writable again and deletes it
+func (g *GitManager) removeMountDir(dir string) error {
+	if _, err := os.Lstat(dir); os.IsNotExist(err) {
+		return nil
+	}
+	// Read-only directories can't have entries removed, and Windows refuses
+	// to delete read-only files
+	setReadOnly(dir, false)
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("failed to remove %s: %w", dir, err)
+	}
+	return nil
+}
+
+// setReadOnly removes (or restores) write permission on every file and
+// directory below root. Symlinks are left alone.
+func setReadOnly(root string, readOnly bool) error {
+	var dirs []string
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.Type()&fs.ModeSymlink != 0 {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if !readOnly {
+				// Directories must be writable before their entries are visited
+				return os.Chmod(path, info.Mode().Perm()|0200)
+			}
+			dirs = append(dirs, path)
+			return nil
+		}
+		mode := info.Mode().Perm() &^ 0222
+		if !readOnly {
+			mode = info.Mode().Perm() | 0200
+		}
+		return os.Chmod(path, mode)
+	})
+	if err != nil {
+		return err
+	}
+	// Directories last, or their files couldn't be changed
+	for i := len(dirs) - 1; i >= 0; i-- {
+		info, err := os.Stat(dirs[i])
+		if err != nil {
+			return err
+		}
+		if err := os.Chmod(dirs[i], info.Mode().Perm()&^0222); err != nil {
+			return err
+		}
+	}
+	return nil
+}
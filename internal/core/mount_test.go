@@ -0,0 +1,73 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMountSnapshot(t *testing.T) {
+	tempDir, _, gitManager := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	snapshotFile(t, tempDir, gitManager, "first")
+	dir := filepath.Join(t.TempDir(), "mount")
+	mount, result, err := gitManager.MountSnapshot("HEAD", dir, nil)
+	if err != nil {
+		t.Fatalf("MountSnapshot failed: %v", err)
+	}
+	if result.Files != 1 || mount.Ref != "HEAD" || mount.Dir != dir {
+		t.Errorf("Unexpected mount %+v with %d files", mount, result.Files)
+	}
+
+	file := filepath.Join(dir, "file.txt")
+	content, err := os.ReadFile(file)
+	if err != nil || string(content) != "first" {
+		t.Fatalf("Expected file.txt to contain 'first', got %q (%v)", content, err)
+	}
+	// Checked by mode rather than by writing: root may write anyway
+	info, _ := os.Stat(file)
+	if info.Mode().Perm()&0222 != 0 {
+		t.Errorf("Expected file.txt to be read-only, got %v", info.Mode().Perm())
+	}
+
+	if _, _, err := gitManager.MountSnapshot("HEAD", dir, nil); err == nil {
+		t.Error("Expected mounting twice at the same directory to fail")
+	}
+
+	// Unchanged revision: nothing to do
+	if _, changed, err := gitManager.RefreshMount(dir); err != nil || changed {
+		t.Errorf("Expected an up-to-date mount, got changed=%v, err=%v", changed, err)
+	}
+	snapshotFile(t, tempDir, gitManager, "second")
+	refreshed, changed, err := gitManager.RefreshMount(dir)
+	if err != nil || !changed {
+		t.Fatalf("Expected the mount to be refreshed, got changed=%v, err=%v", changed, err)
+	}
+	head, _ := gitManager.RunCommand("rev-parse", "HEAD")
+	if refreshed.Snapshot != head {
+		t.Errorf("Expected the mount to follow HEAD to %s, got %s", head, refreshed.Snapshot)
+	}
+	if content, _ := os.ReadFile(file); string(content) != "second" {
+		t.Errorf("Expected refreshed file.txt to contain 'second', got %q", content)
+	}
+
+	// Only mounts are removed
+	other := t.TempDir()
+	if err := gitManager.Unmount(other); err == nil {
+		t.Error("Expected unmounting an unknown directory to fail")
+	}
+	if _, err := os.Stat(other); err != nil {
+		t.Errorf("Expected the unknown directory to be left alone: %v", err)
+	}
+
+	if err := gitManager.Unmount(dir); err != nil {
+		t.Fatalf("Unmount failed: %v", err)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("Expected the mount directory to be removed, got %v", err)
+	}
+	if mounts, err := gitManager.Mounts(); err != nil || len(mounts) != 0 {
+		t.Errorf("Expected no mounts left, got %+v (%v)", mounts, err)
+	}
+}
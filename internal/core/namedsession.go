@@ -0,0 +1,199 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NamedSessionsFile records, in the shadow repository, the sessions started
+// with 'timemachine session start'
+const NamedSessionsFile = "sessions.json"
+
+// NamedSession is a stretch of work marked explicitly, such as one AI agent
+// task. Every snapshot taken while it runs carries its ID in a
+// Snapshot-Session trailer.
+type NamedSession struct {
+	ID            string    `json:"id"`
+	Name          string    `json:"name"`
+	Branch        string    `json:"branch,omitempty"`
+	Start         time.Time `json:"start"`
+	End           time.Time `json:"end,omitzero"`
+	StartSnapshot string    `json:"start_snapshot"` // The state before the session
+	EndSnapshot   string    `json:"end_snapshot,omitempty"`
+}
+
+// Active reports whether the session hasn't been ended
+func (s NamedSession) Active() bool {
+	return s.End.IsZero()
+}
+
+func (g *GitManager) namedSessionsPath() string {
+	return filepath.Join(g.State.ShadowRepoDir, NamedSessionsFile)
+}
+
+// NamedSessions returns the sessions started with StartNamedSession, oldest
+// first
+func (g *GitManager) NamedSessions() ([]NamedSession, error) {
+	data, err := os.ReadFile(g.namedSessionsPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sessions: %w", err)
+	}
+	var sessions []NamedSession
+	if err := json.Unmarshal(data, &sessions); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", NamedSessionsFile, err)
+	}
+	return sessions, nil
+}
+
+// writeNamedSessions atomically replaces the sessions on disk
+func (g *GitManager) writeNamedSessions(sessions []NamedSession) error {
+	data, err := json.MarshalIndent(sessions, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := g.namedSessionsPath() + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write sessions: %w", err)
+	}
+	if err := os.Rename(tmp, g.namedSessionsPath()); err != nil {
+		return fmt.Errorf("failed to write sessions: %w", err)
+	}
+	return nil
+}
+
+// ActiveSession returns the running session, nil when there is none. It is
+// read on every snapshot, so a session started from another process tags
+// the watcher's snapshots.
+func (g *GitManager) ActiveSession() *NamedSession {
+	sessions, err := g.NamedSessions()
+	if err != nil {
+		return nil
+	}
+	for i := range sessions {
+		if sessions[i].Active() {
+			return &sessions[i]
+		}
+	}
+	return nil
+}
+
+// FindNamedSession looks a session up by ID or name; "latest" is the one
+// started last
+func (g *GitManager) FindNamedSession(id string) (*NamedSession, error) {
+	sessions, err := g.NamedSessions()
+	if err != nil {
+		return nil, err
+	}
+	if id == "latest" && len(sessions) > 0 {
+		return &sessions[len(sessions)-1], nil
+	}
+	var found *NamedSession
+	for i := range sessions {
+		if sessions[i].ID == id {
+			return &sessions[i], nil
+		}
+		if sessions[i].Name == id {
+			// Names may repeat; the latest wins
+			found = &sessions[i]
+		}
+	}
+	if found == nil {
+		return nil, NewNotFoundError("session %q not found; 'timemachine session list' shows them", id)
+	}
+	return found, nil
+}
+
+// StartNamedSession snapshots the working tree, the state the session can be
+// rolled back to, and starts tagging snapshots with a new session
+func (g *GitManager) StartNamedSession(name string) (*NamedSession, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || strings.ContainsAny(name, "\r\n") {
+		return nil, NewValidationError("a session needs a name on one line, e.g. \"claude: refactor auth\"")
+	}
+	sessions, err := g.NamedSessions()
+	if err != nil {
+		return nil, err
+	}
+	for _, session := range sessions {
+		if session.Active() {
+			return nil, NewValidationError("session %s (%s) is still running; end it with 'timemachine session end' first", session.ID, session.Name)
+		}
+	}
+
+	if err := g.CreateSnapshot("Before session: " + name); err != nil {
+		return nil, fmt.Errorf("failed to snapshot the start of the session: %w", err)
+	}
+	start, err := g.RunCommand("rev-parse", "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve the start of the session: %w", err)
+	}
+	branch, _ := g.RunCommand("symbolic-ref", "--short", "HEAD")
+
+	session := NamedSession{
+		ID:            "s" + strconv.Itoa(len(sessions)+1),
+		Name:          name,
+		Branch:        branch,
+		Start:         time.Now(),
+		StartSnapshot: start,
+	}
+	if err := g.writeNamedSessions(append(sessions, session)); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// EndNamedSession takes the session's last snapshot and stops tagging
+// snapshots with it
+func (g *GitManager) EndNamedSession() (*NamedSession, error) {
+	sessions, err := g.NamedSessions()
+	if err != nil {
+		return nil, err
+	}
+	for i := range sessions {
+		if !sessions[i].Active() {
+			continue
+		}
+		// Taken while the session still runs, so it is tagged
+		if err := g.CreateSnapshot("End of session: " + sessions[i].Name); err != nil {
+			return nil, fmt.Errorf("failed to snapshot the end of the session: %w", err)
+		}
+		sessions[i].End = time.Now()
+		sessions[i].EndSnapshot, _ = g.RunCommand("rev-parse", "HEAD")
+		if err := g.writeNamedSessions(sessions); err != nil {
+			return nil, err
+		}
+		return &sessions[i], nil
+	}
+	return nil, NewNotFoundError("no session is running; start one with 'timemachine session start <name>'")
+}
+
+// hasSessionTrailer reports whether a snapshot message carries the trailer
+// of session id
+func hasSessionTrailer(message, id string) bool {
+	for _, line := range strings.Split(message, "\n") {
+		if line == provenanceSessionTrailer+id {
+			return true
+		}
+	}
+	return false
+}
+
+// sessionTrailer returns the Snapshot-Session trailer of a new snapshot: the
+// running session, else TIMEMACHINE_SESSION when git.provenance is set
+func (g *GitManager) sessionTrailer(provenance bool) string {
+	if session := g.ActiveSession(); session != nil {
+		return provenanceSessionTrailer + session.ID
+	}
+	if session := os.Getenv(SessionEnv); session != "" && provenance {
+		return provenanceSessionTrailer + trailerValue(session)
+	}
+	return ""
+}
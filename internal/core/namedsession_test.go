@@ -0,0 +1,70 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/config"
+)
+
+func TestNamedSessions(t *testing.T) {
+	tempDir, state, gitManager := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	snapshotFile(t, tempDir, gitManager, "before")
+	if _, err := gitManager.EndNamedSession(); ExitCode(err) != ExitNotFound {
+		t.Errorf("Expected ending without a session to be not found, got %v", err)
+	}
+	if _, err := gitManager.StartNamedSession(" \n"); ExitCode(err) != ExitValidation {
+		t.Errorf("Expected an empty name to be rejected, got %v", err)
+	}
+
+	// Uncommitted work is snapshotted first, so rolling back keeps it
+	os.WriteFile(filepath.Join(tempDir, "file.txt"), []byte("unsaved"), 0644)
+	session, err := gitManager.StartNamedSession("claude: refactor auth")
+	if err != nil {
+		t.Fatalf("StartNamedSession failed: %v", err)
+	}
+	if session.ID != "s1" || !session.Active() {
+		t.Fatalf("Unexpected session %+v", session)
+	}
+	if _, err := gitManager.StartNamedSession("another"); ExitCode(err) != ExitValidation {
+		t.Errorf("Expected a second running session to be rejected, got %v", err)
+	}
+
+	snapshotFile(t, tempDir, gitManager, "edit 1")
+	snapshotFile(t, tempDir, gitManager, "edit 2")
+	os.WriteFile(filepath.Join(tempDir, "file.txt"), []byte("edit 3"), 0644)
+	ended, err := gitManager.EndNamedSession()
+	if err != nil {
+		t.Fatalf("EndNamedSession failed: %v", err)
+	}
+	if ended.Active() || ended.EndSnapshot == "" {
+		t.Errorf("Expected the session ended, got %+v", ended)
+	}
+	snapshotFile(t, tempDir, gitManager, "after")
+
+	for _, backend := range []string{BackendExec, BackendNative} {
+		state.Config = &config.Config{Git: config.GitConfig{Backend: backend}}
+		snapshots, err := NewGitManager(state).FilterSnapshots(SnapshotFilter{Session: "s1"})
+		if err != nil {
+			t.Fatalf("%s: FilterSnapshots failed: %v", backend, err)
+		}
+		if len(snapshots) != 3 || snapshots[2].Message != "edit 1" {
+			t.Errorf("%s: expected the 3 snapshots of the session, got %+v", backend, snapshots)
+		}
+	}
+
+	found, err := gitManager.FindNamedSession("claude: refactor auth")
+	if err != nil || found.ID != "s1" {
+		t.Fatalf("Expected to find the session by name, got %+v, %v", found, err)
+	}
+	content, err := gitManager.RunCommand("show", found.StartSnapshot+":file.txt")
+	if err != nil || content != "unsaved" {
+		t.Errorf("Expected the session to start from the unsaved work, got %q, %v", content, err)
+	}
+	if _, err := gitManager.FindNamedSession("s9"); ExitCode(err) != ExitNotFound {
+		t.Errorf("Expected a missing session to be not found, got %v", err)
+	}
+}
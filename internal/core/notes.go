@@ -0,0 +1,127 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// NotesRef holds the notes attached to snapshots with 'timemachine annotate',
+// kept apart from any notes git itself would use
+const NotesRef = "refs/notes/timemachine"
+
+// AddNote attaches a note to a snapshot, replacing its note or, with
+// appendNote, adding a paragraph to it. Returns the snapshot's full hash.
+func (g *GitManager) AddNote(ref, note string, appendNote bool) (string, error) {
+	note = strings.TrimSpace(note)
+	if note == "" {
+		return "", fmt.Errorf("note is empty")
+	}
+	commit, err := g.ResolveSnapshot(ref)
+	if err != nil {
+		return "", err
+	}
+
+	args := []string{"notes", "--ref=" + NotesRef, "add", "--force", "--file=-", commit}
+	if appendNote {
+		args = []string{"notes", "--ref=" + NotesRef, "append", "--file=-", commit}
+	}
+	if _, err := g.runCommandInput(note, args...); err != nil {
+		return "", fmt.Errorf("failed to annotate snapshot %s: %w", ShortHash(commit), err)
+	}
+	return commit, nil
+}
+
+// RemoveNote deletes a snapshot's note. Returns the snapshot's full hash.
+func (g *GitManager) RemoveNote(ref string) (string, error) {
+	commit, err := g.ResolveSnapshot(ref)
+	if err != nil {
+		return "", err
+	}
+	if _, err := g.RunCommand("notes", "--ref="+NotesRef, "remove", "--ignore-missing", commit); err != nil {
+		return "", fmt.Errorf("failed to remove note of %s: %w", ShortHash(commit), err)
+	}
+	return commit, nil
+}
+
+// Note returns a snapshot's note, or "" without one
+func (g *GitManager) Note(ref string) (string, error) {
+	commit, err := g.ResolveSnapshot(ref)
+	if err != nil {
+		return "", err
+	}
+	notes, err := g.Notes()
+	if err != nil {
+		return "", err
+	}
+	return notes[commit], nil
+}
+
+// Notes returns every snapshot note, by full snapshot hash. Notes are few
+// compared to snapshots, so they are read all at once.
+func (g *GitManager) Notes() (map[string]string, error) {
+	notes := make(map[string]string)
+	output, err := g.RunCommand("notes", "--ref="+NotesRef, "list")
+	if err != nil || output == "" {
+		// No note was ever added
+		return notes, nil
+	}
+
+	// <note blob> SP <snapshot>
+	var blobs, commits []string
+	for _, line := range strings.Split(output, "\n") {
+		if fields := strings.Fields(line); len(fields) == 2 {
+			blobs = append(blobs, fields[0])
+			commits = append(commits, fields[1])
+		}
+	}
+	contents, err := g.runCommandInput(strings.Join(blobs, "\n")+"\n", "cat-file", "--batch")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read notes: %w", err)
+	}
+
+	// <blob> SP blob SP <size> LF <content> LF
+	for i := 0; i < len(commits); i++ {
+		header, rest, ok := strings.Cut(string(contents), "\n")
+		fields := strings.Fields(header)
+		if !ok || len(fields) != 3 {
+			return nil, fmt.Errorf("failed to read notes: unexpected output %q", header)
+		}
+		var size int
+		if _, err := fmt.Sscanf(fields[2], "%d", &size); err != nil || size > len(rest) {
+			return nil, fmt.Errorf("failed to read notes: unexpected output %q", header)
+		}
+		notes[commits[i]] = strings.TrimSpace(rest[:size])
+		contents = []byte(strings.TrimPrefix(rest[size:], "\n"))
+	}
+	return notes, nil
+}
+
+// NoteSummary returns the first line of a note, for one-line listings
+func NoteSummary(note string) string {
+	line, _, _ := strings.Cut(note, "\n")
+	return strings.TrimSpace(line)
+}
+
+// carryNotes moves notes along with snapshots a history rewrite recreated,
+// given the rewrite's mapping of old to new hashes
+func (g *GitManager) carryNotes(mapping map[string]string) error {
+	notes, err := g.Notes()
+	if err != nil || len(notes) == 0 {
+		return err
+	}
+	var lines []string
+	for old, commit := range mapping {
+		if _, ok := notes[old]; ok && old != commit {
+			lines = append(lines, old+" "+commit)
+		}
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	sort.Strings(lines)
+	if _, err := g.runCommandInput(strings.Join(lines, "\n")+"\n", "notes", "--ref="+NotesRef, "copy", "--force", "--stdin"); err != nil {
+		return fmt.Errorf("failed to carry snapshot notes over: %w", err)
+	}
+	return nil
+}
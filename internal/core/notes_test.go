@@ -0,0 +1,62 @@
+package core
+
+import (
+	"os"
+	"testing"
+)
+
+func TestSnapshotNotes(t *testing.T) {
+	tempDir, _, gitManager := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	if notes, err := gitManager.Notes(); err != nil || len(notes) != 0 {
+		t.Fatalf("Expected no notes yet, got %v (%v)", notes, err)
+	}
+
+	snapshotFile(t, tempDir, gitManager, "first")
+	snapshotFile(t, tempDir, gitManager, "second")
+	snapshotFile(t, tempDir, gitManager, "third")
+
+	middle, err := gitManager.AddNote("HEAD~1", "auth flow broken from here", false)
+	if err != nil {
+		t.Fatalf("AddNote failed: %v", err)
+	}
+	if _, err := gitManager.AddNote(middle, "tests still pass", true); err != nil {
+		t.Fatalf("Appending to the note failed: %v", err)
+	}
+	head, err := gitManager.AddNote("HEAD", "latest", false)
+	if err != nil {
+		t.Fatalf("AddNote failed: %v", err)
+	}
+	if _, err := gitManager.AddNote("HEAD", "  ", false); err == nil {
+		t.Error("Expected an empty note to be rejected")
+	}
+
+	note, err := gitManager.Note(ShortHash(middle))
+	if err != nil || note != "auth flow broken from here\n\ntests still pass" {
+		t.Errorf("Expected the appended note, got %q (%v)", note, err)
+	}
+	if summary := NoteSummary(note); summary != "auth flow broken from here" {
+		t.Errorf("Expected the first line as summary, got %q", summary)
+	}
+
+	// Notes move with the snapshots a history rewrite recreates
+	mapping, err := gitManager.PruneSnapshots(2)
+	if err != nil {
+		t.Fatalf("PruneSnapshots failed: %v", err)
+	}
+	notes, err := gitManager.Notes()
+	if err != nil {
+		t.Fatalf("Notes failed: %v", err)
+	}
+	if notes[mapping[middle]] != note || notes[mapping[head]] != "latest" {
+		t.Errorf("Expected notes to follow the pruned history, got %v", notes)
+	}
+
+	if _, err := gitManager.RemoveNote("HEAD"); err != nil {
+		t.Fatalf("RemoveNote failed: %v", err)
+	}
+	if note, _ := gitManager.Note("HEAD"); note != "" {
+		t.Errorf("Expected the note to be removed, got %q", note)
+	}
+}
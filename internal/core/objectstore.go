@@ -0,0 +1,209 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ObjectStore stores backup bundles under slash-separated keys. S3 and GCS
+// go through the aws and gcloud CLIs, which bring their own credentials:
+// environment variables, profiles, SSO and instance roles. Nothing secret is
+// ever kept in timemachine.yaml.
+type ObjectStore interface {
+	// Put uploads a local file to key
+	Put(key, file string) error
+	// Get downloads key to a local file
+	Get(key, file string) error
+	// List returns the keys under prefix, sorted
+	List(prefix string) ([]string, error)
+	// String names the store for messages
+	String() string
+}
+
+// runStorageCLI runs an object storage CLI and returns its standard output;
+// replaced in tests
+var runStorageCLI = func(name string, args ...string) ([]byte, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command(name, args...)
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		if _, lookErr := exec.LookPath(name); lookErr != nil {
+			return nil, fmt.Errorf("%s is not installed; backups to this URL need its CLI", name)
+		}
+		return nil, fmt.Errorf("%s %s failed: %w\n%s", name, args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return output, nil
+}
+
+// OpenObjectStore returns the store for backup.url: s3://bucket/prefix,
+// gs://bucket/prefix, or a local directory (a path or file:// URL) such as
+// a mounted network share
+func OpenObjectStore(rawURL string) (ObjectStore, error) {
+	if rawURL == "" {
+		return nil, NewValidationError("backup.url is not set; set it to s3://bucket/prefix, gs://bucket/prefix or a directory")
+	}
+	if filepath.IsAbs(rawURL) {
+		return fileStore{dir: rawURL}, nil
+	}
+	location, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, NewValidationError("invalid backup.url %q: %v", rawURL, err)
+	}
+	prefix := strings.Trim(location.Path, "/")
+	switch location.Scheme {
+	case "s3":
+		return cliStore{bucket: "s3://" + location.Host, prefix: prefix, cli: "aws"}, nil
+	case "gs":
+		return cliStore{bucket: "gs://" + location.Host, prefix: prefix, cli: "gcloud"}, nil
+	case "file":
+		return fileStore{dir: filepath.FromSlash(location.Path)}, nil
+	}
+	return nil, NewValidationError("unsupported backup.url %q: use s3://, gs:// or an absolute directory", rawURL)
+}
+
+// fileStore keeps objects as files below a directory
+type fileStore struct {
+	dir string
+}
+
+func (s fileStore) String() string {
+	return s.dir
+}
+
+func (s fileStore) Put(key, file string) error {
+	target := filepath.Join(s.dir, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return fmt.Errorf("failed to upload %s: %w", key, err)
+	}
+	// Written under a temporary name so a crash never leaves half a bundle
+	os.Remove(target + ".tmp")
+	if err := copyFile(file, target+".tmp", 0644); err != nil {
+		return fmt.Errorf("failed to upload %s: %w", key, err)
+	}
+	return os.Rename(target+".tmp", target)
+}
+
+func (s fileStore) Get(key, file string) error {
+	os.Remove(file)
+	if err := copyFile(filepath.Join(s.dir, filepath.FromSlash(key)), file, 0644); err != nil {
+		return fmt.Errorf("failed to download %s: %w", key, err)
+	}
+	return nil
+}
+
+func (s fileStore) List(prefix string) ([]string, error) {
+	var keys []string
+	root := filepath.Join(s.dir, filepath.FromSlash(prefix))
+	err := filepath.WalkDir(root, func(name string, entry os.DirEntry, err error) error {
+		if os.IsNotExist(err) {
+			return filepath.SkipDir
+		}
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || strings.HasSuffix(name, ".tmp") {
+			return nil
+		}
+		rel, err := filepath.Rel(s.dir, name)
+		if err != nil {
+			return err
+		}
+		keys = append(keys, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", root, err)
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// cliStore keeps objects in an S3 or GCS bucket through the aws or gcloud
+// CLI. Both upload atomically: an object appears whole or not at all.
+type cliStore struct {
+	bucket string // s3://name or gs://name
+	prefix string
+	cli    string // aws or gcloud
+}
+
+func (s cliStore) String() string {
+	return strings.TrimSuffix(s.bucket+"/"+s.prefix, "/")
+}
+
+func (s cliStore) url(key string) string {
+	return s.bucket + "/" + path.Join(s.prefix, key)
+}
+
+func (s cliStore) copyArgs(src, dst string) []string {
+	if s.cli == "aws" {
+		return []string{"s3", "cp", "--only-show-errors", src, dst}
+	}
+	return []string{"storage", "cp", "--quiet", src, dst}
+}
+
+func (s cliStore) Put(key, file string) error {
+	_, err := runStorageCLI(s.cli, s.copyArgs(file, s.url(key))...)
+	return err
+}
+
+func (s cliStore) Get(key, file string) error {
+	_, err := runStorageCLI(s.cli, s.copyArgs(s.url(key), file)...)
+	return err
+}
+
+func (s cliStore) List(prefix string) ([]string, error) {
+	base := strings.TrimSuffix(s.url(prefix), "/")
+	var args []string
+	if s.cli == "aws" {
+		args = []string{"s3", "ls", "--recursive", base + "/"}
+	} else {
+		args = []string{"storage", "ls", base + "/**"}
+	}
+	output, err := runStorageCLI(s.cli, args...)
+	if err != nil {
+		// Both report a prefix without objects as an error: aws with exit
+		// status 1 (it uses 2 and up for real failures), gcloud by message
+		if (s.cli == "aws" && strings.Contains(err.Error(), "exit status 1\n")) || strings.Contains(err.Error(), "matched no objects") {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var keys []string
+	for _, line := range strings.Split(string(output), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		var key string
+		if s.cli == "aws" {
+			// 2024-01-15 10:30:00      12345 prefix/machine/000001-....bundle
+			fields := strings.Fields(line)
+			if len(fields) < 4 {
+				continue
+			}
+			key = fields[len(fields)-1]
+		} else {
+			key = strings.TrimPrefix(line, s.bucket+"/")
+		}
+		if s.prefix != "" {
+			var ok bool
+			if key, ok = strings.CutPrefix(key, s.prefix+"/"); !ok {
+				continue
+			}
+		}
+		if key != "" && !strings.HasSuffix(key, "/") {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
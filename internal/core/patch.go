@@ -0,0 +1,235 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// noNewlineMarker follows a diff line lacking a trailing newline
+const noNewlineMarker = `\ No newline at end of file`
+
+// Hunk is one hunk of the changes restoring a file from a snapshot makes
+type Hunk struct {
+	Header   string   // The "@@ -a,b +c,d @@" line
+	Lines    []string // ' ' context, '-' current or '+' snapshot lines, with noNewlineMarker lines
+	oldStart int      // First line of the working copy the hunk replaces
+	oldCount int
+}
+
+// RestorePatch is what restoring one file from a snapshot would change in
+// the working copy, hunk by hunk, so hunks can be restored one at a time
+type RestorePatch struct {
+	Source string // Full hash of the snapshot
+	Path   string // Relative to the project root
+	Hunks  []Hunk
+	mode   os.FileMode
+}
+
+// RestorePatch diffs a file's working copy against a snapshot. The file
+// must be a text file in the snapshot; it may be missing from the working
+// directory.
+func (g *GitManager) RestorePatch(hash, file string) (*RestorePatch, error) {
+	if strings.HasPrefix(hash, "-") {
+		return nil, NewValidationError("invalid snapshot hash %q", hash)
+	}
+	source, err := g.RunCommand("rev-parse", "--verify", hash+"^{commit}")
+	if err != nil {
+		return nil, NewNotFoundError("snapshot %s not found", hash)
+	}
+	rel, err := g.rootRelativePath(file)
+	if err != nil {
+		return nil, err
+	}
+	if rel == "" {
+		return nil, fmt.Errorf("--patch needs a file, not the project root")
+	}
+	if _, err := g.FileContentAt(source, rel); err != nil {
+		return nil, err
+	}
+
+	patch := &RestorePatch{Source: source, Path: rel, mode: 0644}
+	if info, err := os.Stat(filepath.Join(g.State.ProjectRoot, filepath.FromSlash(rel))); err == nil {
+		if info.IsDir() {
+			return nil, fmt.Errorf("%s is a directory; --patch restores one file", rel)
+		}
+		patch.mode = info.Mode().Perm()
+	}
+
+	output, err := g.runCommandRaw("diff", "-R", "--no-renames", "--no-color", "--no-ext-diff", source, "--",
+		g.icasePathspecs(topPathspecs([]string{rel}))[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff snapshot against working tree: %w", err)
+	}
+	if bytes.Contains(output, []byte("\nBinary files ")) || bytes.HasPrefix(output, []byte("Binary files ")) {
+		return nil, fmt.Errorf("%s is a binary file; restore it whole with --file", rel)
+	}
+	patch.Hunks, err = parseHunks(string(output))
+	if err != nil {
+		return nil, err
+	}
+	return patch, nil
+}
+
+// ApplyRestorePatch restores the selected hunks (indexes into patch.Hunks)
+// to the working copy, after a pre-restore snapshot it returns the hash of,
+// and saves the reverse patch it returns the ID of. The working copy must
+// not have changed since RestorePatch.
+func (g *GitManager) ApplyRestorePatch(patch *RestorePatch, selected []int) (string, string, error) {
+	path := filepath.Join(g.State.ProjectRoot, filepath.FromSlash(patch.Path))
+	current, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return "", "", fmt.Errorf("failed to read %s: %w", patch.Path, err)
+	}
+
+	chosen := make(map[int]bool, len(selected))
+	for _, i := range selected {
+		if i < 0 || i >= len(patch.Hunks) {
+			return "", "", fmt.Errorf("no hunk %d in %s", i+1, patch.Path)
+		}
+		chosen[i] = true
+	}
+	restored, err := applyHunks(current, patch.Hunks, chosen)
+	if err != nil {
+		return "", "", fmt.Errorf("%s changed since it was compared with the snapshot: %w", patch.Path, err)
+	}
+
+	// Capture the current state so the restore can be undone
+	if err := g.CreateSnapshot("Pre-restore backup before restoring hunks of " + patch.Path + " from " + ShortHash(patch.Source)); err != nil {
+		return "", "", fmt.Errorf("failed to create pre-restore snapshot: %w", err)
+	}
+	backup, err := g.RunCommand("rev-parse", "HEAD")
+	if err != nil {
+		return "", "", fmt.Errorf("failed to resolve pre-restore snapshot: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return backup, "", fmt.Errorf("failed to restore %s: %w", patch.Path, err)
+	}
+	if err := os.WriteFile(path, restored, patch.mode); err != nil {
+		return backup, "", fmt.Errorf("failed to restore %s: %w", patch.Path, err)
+	}
+	id, err := g.saveRestoreBackup(patch.Source, backup, "", []string{patch.Path})
+	if err != nil {
+		return backup, "", err
+	}
+	return backup, id, nil
+}
+
+// parseHunks parses the hunks of a single-file unified diff
+func parseHunks(diff string) ([]Hunk, error) {
+	var hunks []Hunk
+	lines := strings.Split(strings.TrimSuffix(diff, "\n"), "\n")
+	for _, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "@@"):
+			start, count, err := parseHunkHeader(line)
+			if err != nil {
+				return nil, err
+			}
+			hunks = append(hunks, Hunk{Header: line, oldStart: start, oldCount: count})
+		case len(hunks) == 0:
+			// File header: diff --git, index, ---, +++
+		case line == noNewlineMarker || line != "" && strings.ContainsRune(" +-", rune(line[0])):
+			hunks[len(hunks)-1].Lines = append(hunks[len(hunks)-1].Lines, line)
+		default:
+			return nil, fmt.Errorf("unexpected diff line %q", line)
+		}
+	}
+	return hunks, nil
+}
+
+// parseHunkHeader returns the old range of "@@ -a,b +c,d @@"
+func parseHunkHeader(header string) (int, int, error) {
+	fields := strings.Fields(header)
+	if len(fields) < 3 || !strings.HasPrefix(fields[1], "-") {
+		return 0, 0, fmt.Errorf("invalid hunk header %q", header)
+	}
+	start, count, hasCount := strings.Cut(fields[1][1:], ",")
+	first, err := strconv.Atoi(start)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid hunk header %q", header)
+	}
+	n := 1
+	if hasCount {
+		if n, err = strconv.Atoi(count); err != nil {
+			return 0, 0, fmt.Errorf("invalid hunk header %q", header)
+		}
+	}
+	return first, n, nil
+}
+
+// applyHunks replaces the old side of each chosen hunk with its new side.
+// Hunks are checked against content, chosen or not.
+func applyHunks(content []byte, hunks []Hunk, chosen map[int]bool) ([]byte, error) {
+	lines := splitLinesKeepEnds(content)
+	var out bytes.Buffer
+	next := 0 // First line of content not yet copied
+	for i, hunk := range hunks {
+		// A hunk adding to an empty range starts after line oldStart
+		at := hunk.oldStart - 1
+		if hunk.oldCount == 0 {
+			at = hunk.oldStart
+		}
+		if at < next || at+hunk.oldCount > len(lines) {
+			return nil, fmt.Errorf("hunk %d is out of range", i+1)
+		}
+		for _, line := range lines[next:at] {
+			out.WriteString(line)
+		}
+
+		old, updated := hunkSides(hunk)
+		if strings.Join(lines[at:at+hunk.oldCount], "") != old {
+			return nil, fmt.Errorf("hunk %d no longer matches", i+1)
+		}
+		if chosen[i] {
+			out.WriteString(updated)
+		} else {
+			out.WriteString(old)
+		}
+		next = at + hunk.oldCount
+	}
+	for _, line := range lines[next:] {
+		out.WriteString(line)
+	}
+	return out.Bytes(), nil
+}
+
+// hunkSides returns the text a hunk replaces and the text it replaces it with
+func hunkSides(hunk Hunk) (string, string) {
+	var old, updated strings.Builder
+	for i, line := range hunk.Lines {
+		if line == noNewlineMarker {
+			continue
+		}
+		text := line[1:]
+		if i+1 >= len(hunk.Lines) || hunk.Lines[i+1] != noNewlineMarker {
+			text += "\n"
+		}
+		if line[0] != '+' {
+			old.WriteString(text)
+		}
+		if line[0] != '-' {
+			updated.WriteString(text)
+		}
+	}
+	return old.String(), updated.String()
+}
+
+// splitLinesKeepEnds splits content after each newline
+func splitLinesKeepEnds(content []byte) []string {
+	var lines []string
+	for len(content) > 0 {
+		i := bytes.IndexByte(content, '\n')
+		if i < 0 {
+			lines = append(lines, string(content))
+			break
+		}
+		lines = append(lines, string(content[:i+1]))
+		content = content[i+1:]
+	}
+	return lines
+}
@@ -0,0 +1,74 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRestorePatch(t *testing.T) {
+	tempDir, _, gitManager := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	path := filepath.Join(tempDir, "main.go")
+	original := "package main\n\nfunc a() {}\n\nfunc b() {}\n\nfunc c() {}\n\nfunc d() {}\n\nfunc e() {}\n\nfunc f() {}\n\nfunc g() {}"
+	if err := os.WriteFile(path, []byte(original), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := gitManager.CreateSnapshot("original"); err != nil {
+		t.Fatalf("CreateSnapshot failed: %v", err)
+	}
+	hash, _ := gitManager.RunCommand("rev-parse", "HEAD")
+
+	// Rewritten at both ends; the middle is unchanged
+	rewritten := "package main\n\nfunc a() { panic(1) }\n\nfunc b() {}\n\nfunc c() {}\n\nfunc d() {}\n\nfunc e() {}\n\nfunc f() {}\n\nfunc g() { panic(2) }\n"
+	if err := os.WriteFile(path, []byte(rewritten), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	patch, err := gitManager.RestorePatch(hash[:8], path)
+	if err != nil {
+		t.Fatalf("RestorePatch failed: %v", err)
+	}
+	if patch.Path != "main.go" || len(patch.Hunks) != 2 {
+		t.Fatalf("Expected 2 hunks for main.go, got %d for %s", len(patch.Hunks), patch.Path)
+	}
+
+	backup, backupPatch, err := gitManager.ApplyRestorePatch(patch, []int{1})
+	if err != nil {
+		t.Fatalf("ApplyRestorePatch failed: %v", err)
+	}
+	content, _ := os.ReadFile(path)
+	expected := "package main\n\nfunc a() { panic(1) }\n\nfunc b() {}\n\nfunc c() {}\n\nfunc d() {}\n\nfunc e() {}\n\nfunc f() {}\n\nfunc g() {}"
+	if string(content) != expected {
+		t.Errorf("Expected only the second hunk restored, got:\n%s", content)
+	}
+	if saved, _ := gitManager.FileContentAt(backup, "main.go"); string(saved) != rewritten {
+		t.Errorf("Expected the pre-restore snapshot to keep the rewritten file, got:\n%s", saved)
+	}
+	if backupPatch == "" {
+		t.Error("Expected a reverse patch of the restored hunk")
+	}
+
+	// The working copy changed since the patch was made
+	if _, _, err := gitManager.ApplyRestorePatch(patch, []int{0}); err == nil {
+		t.Error("Expected an error applying a stale patch")
+	}
+
+	// A deleted file comes back whole
+	os.Remove(path)
+	patch, err = gitManager.RestorePatch(hash, path)
+	if err != nil || len(patch.Hunks) != 1 {
+		t.Fatalf("Expected one hunk for a deleted file, got %v, %v", patch, err)
+	}
+	if _, _, err := gitManager.ApplyRestorePatch(patch, []int{0}); err != nil {
+		t.Fatalf("ApplyRestorePatch failed: %v", err)
+	}
+	if content, _ := os.ReadFile(path); string(content) != original {
+		t.Errorf("Expected the deleted file restored, got:\n%s", content)
+	}
+
+	if _, err := gitManager.RestorePatch(hash, filepath.Join(tempDir, "missing.go")); err == nil {
+		t.Error("Expected an error for a file not in the snapshot")
+	}
+}
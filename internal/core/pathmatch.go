@@ -0,0 +1,121 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// PathMatch is what one restore argument matched in a snapshot
+type PathMatch struct {
+	Pattern string   // The argument as given
+	Path    string   // The argument relative to the project root ("" is the root)
+	Glob    bool     // The argument is a glob pattern rather than a file or directory
+	Files   []string // Matching snapshot files, relative to the project root
+}
+
+// IsGlob reports whether a path contains glob characters
+func IsGlob(pattern string) bool {
+	return strings.ContainsAny(pattern, "*?[")
+}
+
+// MatchSnapshotPaths expands restore arguments against the files of a
+// snapshot. Arguments are relative to the current directory and are files,
+// directories (matching everything below them) or glob patterns, where *, ?
+// and [a-z] match within a path segment and a "**" segment spans any number
+// of directories. A glob that matches no snapshot file is an error; so is a
+// file or directory that is neither in the snapshot nor in the working tree
+// (one only in the working tree is fine: restoring removes it).
+func (g *GitManager) MatchSnapshotPaths(hash string, patterns []string) ([]PathMatch, error) {
+	if strings.HasPrefix(hash, "-") {
+		return nil, NewValidationError("invalid snapshot hash %q", hash)
+	}
+	output, err := g.RunCommand("ls-tree", "-r", "-z", "--name-only", "--full-tree", hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshot files: %w", err)
+	}
+	var files []string
+	for _, file := range strings.Split(output, "\x00") {
+		if file != "" {
+			files = append(files, file)
+		}
+	}
+
+	matches := make([]PathMatch, 0, len(patterns))
+	for _, pattern := range patterns {
+		rel, err := g.rootRelativePath(pattern)
+		if err != nil {
+			return nil, err
+		}
+		match := PathMatch{Pattern: pattern, Path: rel, Glob: IsGlob(rel)}
+
+		if match.Glob {
+			segments := strings.Split(rel, "/")
+			for _, segment := range segments {
+				if _, err := path.Match(segment, ""); err != nil {
+					return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+				}
+			}
+			for _, file := range files {
+				if matchGlob(segments, strings.Split(file, "/")) {
+					match.Files = append(match.Files, file)
+				}
+			}
+			if len(match.Files) == 0 {
+				return nil, fmt.Errorf("pattern %q matches no files in snapshot %s", pattern, ShortHash(hash))
+			}
+		} else {
+			for _, file := range files {
+				if rel == "" || file == rel || strings.HasPrefix(file, rel+"/") {
+					match.Files = append(match.Files, file)
+				}
+			}
+			if len(match.Files) == 0 {
+				if _, err := os.Lstat(filepath.Join(g.State.ProjectRoot, filepath.FromSlash(rel))); err != nil {
+					return nil, fmt.Errorf("%s is not in snapshot %s or the working directory", pattern, ShortHash(hash))
+				}
+			}
+		}
+
+		matches = append(matches, match)
+	}
+
+	return matches, nil
+}
+
+// MatchedPathspecs turns matches into pathspecs for a restore. Files and
+// directories are restored whole, so files created below a directory since
+// the snapshot are removed; globs restore exactly the files they matched.
+// Nil means the whole tree.
+func MatchedPathspecs(matches []PathMatch) []string {
+	var paths []string
+	for _, match := range matches {
+		switch {
+		case match.Glob:
+			paths = append(paths, match.Files...)
+		case match.Path == "":
+			return nil
+		default:
+			paths = append(paths, match.Path)
+		}
+	}
+	return topPathspecs(paths)
+}
+
+// MatchedFiles returns the snapshot files matched by any of matches, without
+// duplicates, in the order they were matched
+func MatchedFiles(matches []PathMatch) []string {
+	seen := make(map[string]bool)
+	var files []string
+	for _, match := range matches {
+		for _, file := range match.Files {
+			if !seen[file] {
+				seen[file] = true
+				files = append(files, file)
+			}
+		}
+	}
+	return files
+}
@@ -0,0 +1,61 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestMatchSnapshotPaths(t *testing.T) {
+	tempDir, _, gitManager := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	for _, file := range []string{"src/main.ts", "src/lib/util.ts", "src/lib/util.js", "docs/guide.md"} {
+		path := filepath.Join(tempDir, filepath.FromSlash(file))
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte(file), 0644)
+	}
+	if err := gitManager.CreateSnapshot("tree"); err != nil {
+		t.Fatalf("CreateSnapshot failed: %v", err)
+	}
+	hash, _ := gitManager.RunCommand("rev-parse", "HEAD")
+	os.WriteFile(filepath.Join(tempDir, "new.txt"), []byte("new"), 0644)
+
+	oldDir, _ := os.Getwd()
+	os.Chdir(tempDir)
+	defer os.Chdir(oldDir)
+
+	tests := []struct {
+		pattern  string
+		expected []string
+	}{
+		{"src/**/*.ts", []string{"src/lib/util.ts", "src/main.ts"}},
+		{"src/*.ts", []string{"src/main.ts"}},
+		{"src/lib", []string{"src/lib/util.js", "src/lib/util.ts"}},
+		{"docs/guide.md", []string{"docs/guide.md"}},
+		{"new.txt", nil}, // Only in the working tree
+	}
+	for _, tt := range tests {
+		matches, err := gitManager.MatchSnapshotPaths(hash, []string{tt.pattern})
+		if err != nil {
+			t.Errorf("MatchSnapshotPaths(%q) failed: %v", tt.pattern, err)
+			continue
+		}
+		if !reflect.DeepEqual(matches[0].Files, tt.expected) {
+			t.Errorf("MatchSnapshotPaths(%q): expected %v, got %v", tt.pattern, tt.expected, matches[0].Files)
+		}
+	}
+
+	for _, pattern := range []string{"src/**/*.go", "missing.txt", "src/[a"} {
+		if _, err := gitManager.MatchSnapshotPaths(hash, []string{pattern}); err == nil {
+			t.Errorf("Expected %q to be rejected", pattern)
+		}
+	}
+
+	matches, _ := gitManager.MatchSnapshotPaths(hash, []string{"src/**/*.ts", "docs"})
+	expected := []string{":(top,literal)src/lib/util.ts", ":(top,literal)src/main.ts", ":(top,literal)docs"}
+	if pathspecs := MatchedPathspecs(matches); !reflect.DeepEqual(pathspecs, expected) {
+		t.Errorf("Expected pathspecs %v, got %v", expected, pathspecs)
+	}
+}
@@ -0,0 +1,278 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+)
+
+// PluginPrefix starts the executable name of a plugin: timemachine-<name>
+// runs as 'timemachine <name>'
+const PluginPrefix = "timemachine-"
+
+// PluginAPIVersion is the version of the context plugins are handed; it only
+// changes when a field is removed or changes meaning
+const PluginAPIVersion = 1
+
+// PluginInfoFlag asks a plugin to describe itself: it prints a PluginInfo as
+// JSON and exits
+const PluginInfoFlag = "--timemachine-plugin-info"
+
+// pluginInfoTimeout bounds the describe handshake, so a plugin that ignores
+// the flag can't hang 'plugin list'
+const pluginInfoTimeout = 2 * time.Second
+
+// Plugin is an executable extending the CLI with a command
+type Plugin struct {
+	Name     string   `json:"name"`
+	Path     string   `json:"path"`
+	Shadowed []string `json:"shadowed,omitempty"` // Executables of the same name found later, which never run
+}
+
+// PluginInfo is what a plugin answers PluginInfoFlag with
+type PluginInfo struct {
+	APIVersion  int    `json:"api_version"`
+	Description string `json:"description"`
+	Usage       string `json:"usage,omitempty"`
+}
+
+// PluginContext describes the invocation to a plugin, as JSON in the file
+// TM_PLUGIN_CONTEXT names. Project fields are empty outside a Git
+// repository.
+type PluginContext struct {
+	APIVersion     int      `json:"api_version"`
+	Version        string   `json:"version"`    // Of the timemachine command
+	Executable     string   `json:"executable"` // The timemachine command, to call back
+	Plugin         string   `json:"plugin"`
+	Args           []string `json:"args"`
+	ProjectRoot    string   `json:"project_root,omitempty"`
+	GitDir         string   `json:"git_dir,omitempty"`
+	ShadowRepo     string   `json:"shadow_repo,omitempty"`
+	Initialized    bool     `json:"initialized"`
+	Branch         string   `json:"branch,omitempty"`          // Shadow branch snapshots go to
+	LatestSnapshot string   `json:"latest_snapshot,omitempty"` // Hash of the newest snapshot on it
+}
+
+// PluginsDir is where plugins are installed for the user, searched before
+// PATH
+func PluginsDir() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "timemachine", "plugins")
+}
+
+// pluginSearchPath lists the directories plugins are looked up in, in order
+func pluginSearchPath() []string {
+	var dirs []string
+	if dir := PluginsDir(); dir != "" {
+		dirs = append(dirs, dir)
+	}
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		if dir != "" {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+// pluginName returns the command an executable provides, if it is a plugin
+func pluginName(fileName string) (string, bool) {
+	name, ok := strings.CutPrefix(fileName, PluginPrefix)
+	if !ok {
+		return "", false
+	}
+	if runtime.GOOS == "windows" {
+		ext := strings.ToLower(filepath.Ext(name))
+		if !strings.Contains(strings.ToLower(os.Getenv("PATHEXT")+";.exe;.bat;.cmd"), ext+";") && ext != "" {
+			return "", false
+		}
+		name = strings.TrimSuffix(name, filepath.Ext(name))
+	}
+	if name == "" || strings.HasPrefix(name, "-") || strings.ContainsAny(name, " \t") {
+		return "", false
+	}
+	return name, true
+}
+
+// isExecutable reports whether path is a file the user can run
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return false
+	}
+	return runtime.GOOS == "windows" || info.Mode().Perm()&0111 != 0
+}
+
+// DiscoverPlugins finds every plugin in PluginsDir and on PATH, sorted by
+// name. The first executable of a name wins, like a shell's lookup.
+func DiscoverPlugins() []Plugin {
+	found := make(map[string]*Plugin)
+	for _, dir := range pluginSearchPath() {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name, ok := pluginName(entry.Name())
+			path := filepath.Join(dir, entry.Name())
+			if !ok || !isExecutable(path) {
+				continue
+			}
+			if plugin, ok := found[name]; ok {
+				if plugin.Path != path {
+					plugin.Shadowed = append(plugin.Shadowed, path)
+				}
+				continue
+			}
+			found[name] = &Plugin{Name: name, Path: path}
+		}
+	}
+
+	plugins := make([]Plugin, 0, len(found))
+	for _, plugin := range found {
+		plugins = append(plugins, *plugin)
+	}
+	sort.Slice(plugins, func(i, j int) bool { return plugins[i].Name < plugins[j].Name })
+	return plugins
+}
+
+// FindPlugin looks up the plugin providing a command without listing every
+// directory
+func FindPlugin(name string) (Plugin, bool) {
+	if _, ok := pluginName(PluginPrefix + name); !ok || strings.ContainsAny(name, `/\`) {
+		return Plugin{}, false
+	}
+	for _, dir := range pluginSearchPath() {
+		candidates := []string{filepath.Join(dir, PluginPrefix+name)}
+		if runtime.GOOS == "windows" {
+			for _, ext := range []string{".exe", ".bat", ".cmd"} {
+				candidates = append(candidates, candidates[0]+ext)
+			}
+		}
+		for _, path := range candidates {
+			if isExecutable(path) {
+				return Plugin{Name: name, Path: path}, true
+			}
+		}
+	}
+	return Plugin{}, false
+}
+
+// Describe runs the plugin with PluginInfoFlag. Plugins that don't answer
+// with JSON are still run as commands; they just go undescribed.
+func (p Plugin) Describe() (PluginInfo, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), pluginInfoTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, p.Path, PluginInfoFlag)
+	cmd.Env = append(os.Environ(), "TM_PLUGIN_API="+fmt.Sprint(PluginAPIVersion))
+	output, err := cmd.Output()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return PluginInfo{}, fmt.Errorf("%s didn't describe itself within %s", p.Name, pluginInfoTimeout)
+	}
+	if err != nil {
+		return PluginInfo{}, fmt.Errorf("%s doesn't describe itself: %w", p.Name, err)
+	}
+	var info PluginInfo
+	if err := json.Unmarshal(output, &info); err != nil {
+		return PluginInfo{}, fmt.Errorf("%s doesn't describe itself: %w", p.Name, err)
+	}
+	if info.APIVersion > PluginAPIVersion {
+		return info, fmt.Errorf("%s needs plugin API version %d; this timemachine supports %d", p.Name, info.APIVersion, PluginAPIVersion)
+	}
+	return info, nil
+}
+
+// NewPluginContext describes the invocation of a plugin from the current
+// directory. The configuration isn't loaded, so a broken one doesn't keep
+// plugins from running.
+func NewPluginContext(version, plugin string, args []string) PluginContext {
+	pc := PluginContext{
+		APIVersion: PluginAPIVersion,
+		Version:    version,
+		Plugin:     plugin,
+		Args:       args,
+	}
+	if args == nil {
+		pc.Args = []string{}
+	}
+	if executable, err := os.Executable(); err == nil {
+		pc.Executable = executable
+	}
+
+	state, err := NewLightAppState()
+	if err != nil {
+		return pc
+	}
+	pc.ProjectRoot, pc.GitDir, pc.ShadowRepo = state.ProjectRoot, state.GitDir, state.ShadowRepoDir
+	pc.Initialized = state.IsInitialized
+	if state.IsInitialized {
+		git := NewGitManager(state)
+		pc.Branch, _ = git.RunCommand("symbolic-ref", "--short", "HEAD")
+		pc.LatestSnapshot, _ = git.RunCommand("rev-parse", "--verify", "--quiet", "HEAD")
+	}
+	return pc
+}
+
+// env returns the TM_* variables handing the context to the plugin
+func (pc PluginContext) env(contextFile string) []string {
+	return []string{
+		"TM_PLUGIN_API=" + fmt.Sprint(pc.APIVersion),
+		"TM_PLUGIN_CONTEXT=" + contextFile,
+		"TM_VERSION=" + pc.Version,
+		"TM_EXECUTABLE=" + pc.Executable,
+		"TM_PROJECT_ROOT=" + pc.ProjectRoot,
+		"TM_GIT_DIR=" + pc.GitDir,
+		"TM_SHADOW_REPO=" + pc.ShadowRepo,
+		"TM_BRANCH=" + pc.Branch,
+	}
+}
+
+// PluginExitError is a plugin that exited unsuccessfully; the timemachine
+// command exits with the same code
+type PluginExitError struct {
+	Plugin string
+	Code   int
+}
+
+func (e *PluginExitError) Error() string {
+	return fmt.Sprintf("plugin %s exited with status %d", e.Plugin, e.Code)
+}
+
+// Run runs the plugin with the terminal, its arguments and the context in
+// TM_* environment variables and a JSON file
+func (p Plugin) Run(pc PluginContext) error {
+	file, err := os.CreateTemp("", "timemachine-plugin-*.json")
+	if err != nil {
+		return fmt.Errorf("failed to write the plugin context: %w", err)
+	}
+	defer os.Remove(file.Name())
+	if err := json.NewEncoder(file).Encode(pc); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write the plugin context: %w", err)
+	}
+	file.Close()
+
+	cmd := exec.Command(p.Path, pc.Args...)
+	cmd.Env = append(os.Environ(), pc.env(file.Name())...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	err = cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return &PluginExitError{Plugin: p.Name, Code: exitErr.ExitCode()}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to run plugin %s: %w", p.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestPlugins(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugins are shell scripts")
+	}
+	configDir, binDir := t.TempDir(), t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configDir)
+	t.Setenv("HOME", configDir)
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	output := filepath.Join(t.TempDir(), "output")
+	script := `#!/bin/sh
+if [ "$1" = "--timemachine-plugin-info" ]; then
+	echo '{"api_version": 1, "description": "Says hello"}'
+	exit 0
+fi
+echo "$TM_PLUGIN_API $*" > ` + output + `
+cp "$TM_PLUGIN_CONTEXT" ` + output + `.json
+exit 3
+`
+	os.WriteFile(filepath.Join(binDir, "timemachine-hello"), []byte(script), 0755)
+	os.WriteFile(filepath.Join(binDir, "timemachine-notes.txt"), []byte("not a plugin"), 0644)
+	os.MkdirAll(PluginsDir(), 0755)
+	os.WriteFile(filepath.Join(PluginsDir(), "timemachine-hello"), []byte(script), 0755)
+
+	plugins := DiscoverPlugins()
+	if len(plugins) != 1 || plugins[0].Name != "hello" {
+		t.Fatalf("Expected the hello plugin, got %+v", plugins)
+	}
+	if plugins[0].Path != filepath.Join(PluginsDir(), "timemachine-hello") || len(plugins[0].Shadowed) != 1 {
+		t.Errorf("Expected the plugins directory to come before PATH, got %+v", plugins[0])
+	}
+	plugin, ok := FindPlugin("hello")
+	if !ok || plugin.Path != plugins[0].Path {
+		t.Fatalf("FindPlugin found %+v, %v", plugin, ok)
+	}
+	if _, ok := FindPlugin("../hello"); ok {
+		t.Error("Expected a path not to name a plugin")
+	}
+
+	info, err := plugin.Describe()
+	if err != nil || info.Description != "Says hello" {
+		t.Errorf("Describe returned %+v, %v", info, err)
+	}
+
+	err = plugin.Run(NewPluginContext("1.0.0", "hello", []string{"--loud", "world"}))
+	var exitErr *PluginExitError
+	if !errors.As(err, &exitErr) || ExitCode(err) != 3 {
+		t.Errorf("Expected the plugin's exit code, got %v", err)
+	}
+	if content, _ := os.ReadFile(output); strings.TrimSpace(string(content)) != "1 --loud world" {
+		t.Errorf("Unexpected plugin output %q", content)
+	}
+	var pc PluginContext
+	content, _ := os.ReadFile(output + ".json")
+	if err := json.Unmarshal(content, &pc); err != nil || pc.Plugin != "hello" || pc.Version != "1.0.0" || len(pc.Args) != 2 {
+		t.Errorf("Unexpected plugin context %s: %v", content, err)
+	}
+}
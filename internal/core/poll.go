@@ -0,0 +1,209 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"syscall"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/fsnotify/fsnotify"
+)
+
+// Directories that can't be watched, because watching them would exceed
+// watcher.max_watched_files or the kernel ran out of watches, are polled
+// instead. Polling backs off while nothing changes and never takes more
+// than 1/pollCostFactor of the time.
+const (
+	pollMinInterval = 2 * time.Second
+	pollMaxInterval = time.Minute
+	pollCostFactor  = 20
+
+	// envPollInterval caps the polling of projects on filesystems that
+	// don't report changes at all (see pollFilesystems)
+	envPollInterval = 5 * time.Second
+)
+
+// fileStamp is what a poll pass compares to notice a changed file
+type fileStamp struct {
+	modTime int64
+	size    int64
+}
+
+// isWatchLimitError reports whether watching a directory failed because a
+// kernel limit ran out (inotify watches, or open files for kqueue) rather
+// than because of the directory itself
+func isWatchLimitError(err error) bool {
+	return errors.Is(err, syscall.ENOSPC) || errors.Is(err, syscall.EMFILE)
+}
+
+// watchDirectory watches dir, counting files against watcher.max_watched_files.
+// When the budget is spent or the kernel refuses more watches, dir and
+// everything below it are polled instead; it returns false so the walk skips
+// them.
+func (w *Watcher) watchDirectory(dir string) bool {
+	w.mu.Lock()
+	_, watched := w.dirFiles[dir]
+	full := !watched && w.maxWatchedFiles > 0 && w.watchedFiles >= w.maxWatchedFiles
+	w.mu.Unlock()
+	if full {
+		w.warnWatchLimit(fmt.Sprintf("More than %d files to watch (watcher.max_watched_files)", w.maxWatchedFiles))
+		w.pollDirectory(dir)
+		return false
+	}
+
+	if err := w.fsWatcher.Add(dir); err != nil {
+		if isWatchLimitError(err) {
+			w.warnWatchLimit(fmt.Sprintf("Ran out of file watches at %s: %v\n   %s", dir, err, watchLimitHint()))
+			w.pollDirectory(dir)
+			return false
+		}
+		// Log but don't fail - some directories might not be accessible
+		fmt.Printf("Warning: couldn't watch directory %s: %v\n", dir, err)
+		return true
+	}
+
+	// Walked again (e.g. after an ignore reload): count its files afresh
+	w.mu.Lock()
+	w.watchedFiles -= w.dirFiles[dir]
+	w.dirFiles[dir] = 0
+	w.mu.Unlock()
+	return true
+}
+
+// countWatchedFile counts a file found while walking a watched directory
+func (w *Watcher) countWatchedFile(path string) {
+	dir := filepath.Dir(path)
+	w.mu.Lock()
+	if _, ok := w.dirFiles[dir]; ok {
+		w.dirFiles[dir]++
+		w.watchedFiles++
+	}
+	w.mu.Unlock()
+}
+
+// forgetWatchedDir stops counting the files of a directory that was removed
+// or is no longer watched
+func (w *Watcher) forgetWatchedDir(dir string) {
+	w.mu.Lock()
+	if files, ok := w.dirFiles[dir]; ok {
+		w.watchedFiles -= files
+		delete(w.dirFiles, dir)
+	}
+	w.mu.Unlock()
+}
+
+// warnWatchLimit tells the user, once, that directories are being polled
+func (w *Watcher) warnWatchLimit(reason string) {
+	if w.limitWarned {
+		return
+	}
+	w.limitWarned = true
+	color.Yellow("⚠️  %s\n   Polling the remaining directories instead; changes there are noticed within %s", reason, w.maxPollInterval)
+}
+
+// isPolled reports whether path is inside a polled directory
+func (w *Watcher) isPolled(path string) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for dir := path; ; dir = filepath.Dir(dir) {
+		if w.polled[dir] {
+			return true
+		}
+		if parent := filepath.Dir(dir); parent == dir {
+			return false
+		}
+	}
+}
+
+// pollDirectory starts polling dir and everything below it. Its current
+// files are the baseline the first pass compares with.
+func (w *Watcher) pollDirectory(dir string) {
+	w.mu.Lock()
+	w.polled[dir] = true
+	w.mu.Unlock()
+	w.stampTree(dir, w.pollStamps)
+
+	if w.pollTimer == nil {
+		w.pollInterval = pollMinInterval
+		w.pollTimer = time.AfterFunc(w.pollInterval, func() {
+			select {
+			case w.pollDue <- struct{}{}:
+			default: // A pass is already due
+			}
+		})
+	}
+}
+
+// polledDirs returns the polled directories, sorted
+func (w *Watcher) polledDirs() []string {
+	w.mu.Lock()
+	dirs := make([]string, 0, len(w.polled))
+	for dir := range w.polled {
+		dirs = append(dirs, dir)
+	}
+	w.mu.Unlock()
+	sort.Strings(dirs)
+	return dirs
+}
+
+// stampTree records the files below root that aren't ignored
+func (w *Watcher) stampTree(root string, stamps map[string]fileStamp) {
+	filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if entry.IsDir() {
+			if _, ok := w.gitDirPath(path); ok || (path != root && w.ignoreManager.ShouldIgnoreDirectory(path)) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if w.ignoreManager.ShouldIgnoreFile(path) {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil
+		}
+		stamps[path] = fileStamp{modTime: info.ModTime().UnixNano(), size: info.Size()}
+		return nil
+	})
+}
+
+// pollDirectories compares the polled directories with the last pass and
+// handles what changed like watcher events. It runs on the event loop, the
+// only goroutine matching paths.
+func (w *Watcher) pollDirectories() {
+	started := time.Now()
+	stamps := make(map[string]fileStamp, len(w.pollStamps))
+	for _, dir := range w.polledDirs() {
+		w.stampTree(dir, stamps)
+	}
+
+	changed := 0
+	for path, stamp := range stamps {
+		if old, ok := w.pollStamps[path]; !ok || old != stamp {
+			w.handleEvent(fsnotify.Event{Name: path, Op: fsnotify.Write})
+			changed++
+		}
+	}
+	for path := range w.pollStamps {
+		if _, ok := stamps[path]; !ok {
+			w.handleEvent(fsnotify.Event{Name: path, Op: fsnotify.Remove})
+			changed++
+		}
+	}
+	w.pollStamps = stamps
+
+	// Poll often while files change, back off while they don't
+	interval := min(w.pollInterval*2, w.maxPollInterval)
+	if changed > 0 {
+		interval = pollMinInterval
+	}
+	w.pollInterval = max(interval, time.Since(started)*pollCostFactor)
+	w.pollTimer.Reset(w.pollInterval)
+}
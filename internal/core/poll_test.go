@@ -0,0 +1,85 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatcherPollsBeyondMaxWatchedFiles(t *testing.T) {
+	tempDir, state, gitManager := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	// As written by init; the project's own repository isn't watched for changes
+	if err := os.WriteFile(filepath.Join(tempDir, DefaultIgnoreFile), []byte(".git/\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"a.txt", "b.txt", "zz/c.txt", "zz/deep/d.txt"} {
+		path := filepath.Join(tempDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	watcher, err := NewWatcher(state, gitManager)
+	if err != nil {
+		t.Fatalf("NewWatcher failed: %v", err)
+	}
+	defer watcher.Stop()
+	watcher.maxWatchedFiles = 2
+	if err := watcher.addDirectoryRecursive(tempDir); err != nil {
+		t.Fatalf("addDirectoryRecursive failed: %v", err)
+	}
+	defer watcher.pollTimer.Stop()
+
+	polled := filepath.Join(tempDir, "zz")
+	for _, dir := range watcher.fsWatcher.WatchList() {
+		if dir == polled || dir == filepath.Join(polled, "deep") {
+			t.Errorf("Expected %s to be polled rather than watched", dir)
+		}
+	}
+	stats := watcher.Stats()
+	if stats.PolledDirs != 1 || stats.WatchedFiles < 2 {
+		t.Errorf("Expected zz/ to be polled past 2 watched files, got %+v", stats)
+	}
+
+	// Walking again, as an ignore reload does, neither double counts nor
+	// watches polled directories
+	if err := watcher.addDirectoryRecursive(tempDir); err != nil {
+		t.Fatalf("addDirectoryRecursive failed: %v", err)
+	}
+	if again := watcher.Stats(); again.WatchedFiles != stats.WatchedFiles || again.PolledDirs != 1 {
+		t.Errorf("Expected the same counts after walking again, got %+v", again)
+	}
+
+	// Nothing changed: nothing to report, and the next pass backs off
+	watcher.pollDirectories()
+	if len(watcher.changed) != 0 {
+		t.Errorf("Expected no changes, got %v", watcher.changed)
+	}
+	if watcher.pollInterval <= pollMinInterval {
+		t.Errorf("Expected polling to back off, got %s", watcher.pollInterval)
+	}
+
+	deep := filepath.Join(polled, "deep", "d.txt")
+	if err := os.WriteFile(deep, []byte("changed content"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(polled, "c.txt")); err != nil {
+		t.Fatal(err)
+	}
+	watcher.pollDirectories()
+	watcher.debouncer.Cancel()
+	for _, rel := range []string{"zz/deep/d.txt", "zz/c.txt"} {
+		if _, ok := watcher.changed[pathKey(rel, state.CaseInsensitive())]; !ok {
+			t.Errorf("Expected the poll pass to report %s, got %v", rel, watcher.changed)
+		}
+	}
+	if watcher.pollInterval > pollMinInterval+time.Second {
+		t.Errorf("Expected polling to speed up after changes, got %s", watcher.pollInterval)
+	}
+}
@@ -0,0 +1,136 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// projectCommitTrailer records, in a snapshot's message, a commit made in
+// the project since the previous snapshot
+const projectCommitTrailer = "Project-Commit: "
+
+// projectCommitsFile queues, in the shadow repository, the commits the
+// project's post-commit and post-merge hooks recorded for the next snapshot
+const projectCommitsFile = "project_commits"
+
+func (g *GitManager) projectCommitsPath() string {
+	return filepath.Join(g.State.ShadowRepoDir, projectCommitsFile)
+}
+
+// RecordProjectCommit queues the commit checked out in the project to be
+// recorded in the next snapshot's message. Called by the project's
+// post-commit and post-merge hooks; returns the commit queued.
+func (g *GitManager) RecordProjectCommit() (string, error) {
+	_, hash, err := ReadHead(g.State.GitDir)
+	if err != nil {
+		return "", err
+	}
+	if hash == "" {
+		return "", fmt.Errorf("the project has no commits yet")
+	}
+	pending := g.pendingProjectCommits()
+	if len(pending) > 0 && pending[len(pending)-1] == hash {
+		return hash, nil
+	}
+
+	f, err := os.OpenFile(g.projectCommitsPath(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return "", fmt.Errorf("failed to record project commit: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(hash + "\n"); err != nil {
+		return "", fmt.Errorf("failed to record project commit: %w", err)
+	}
+	return hash, nil
+}
+
+// pendingProjectCommits returns the commits queued for the next snapshot,
+// oldest first
+func (g *GitManager) pendingProjectCommits() []string {
+	content, err := os.ReadFile(g.projectCommitsPath())
+	if err != nil {
+		return nil
+	}
+	return strings.Fields(string(content))
+}
+
+// withProjectCommits appends a trailer per queued commit to a snapshot message
+func withProjectCommits(message string, commits []string) string {
+	if len(commits) == 0 {
+		return message
+	}
+	trailers := make([]string, len(commits))
+	for i, commit := range commits {
+		trailers[i] = projectCommitTrailer + commit
+	}
+	return message + "\n\n" + strings.Join(trailers, "\n")
+}
+
+// clearProjectCommits drops the commits a snapshot recorded from the queue,
+// keeping any the hooks appended meanwhile
+func (g *GitManager) clearProjectCommits(recorded []string) {
+	if len(recorded) == 0 {
+		return
+	}
+	pending := g.pendingProjectCommits()
+	if len(pending) <= len(recorded) {
+		os.Remove(g.projectCommitsPath())
+		return
+	}
+	os.WriteFile(g.projectCommitsPath(), []byte(strings.Join(pending[len(recorded):], "\n")+"\n"), 0644)
+}
+
+// SinceProjectCommit returns the time from which snapshots followed a commit
+// of the project: the time of the snapshot that recorded it, or when it was
+// committed if no snapshot did
+func (g *GitManager) SinceProjectCommit(rev string) (time.Time, error) {
+	if rev == "" || strings.HasPrefix(rev, "-") {
+		return time.Time{}, NewValidationError("invalid commit %q", rev)
+	}
+	commit, err := g.runProjectCommand("rev-parse", "--verify", "--quiet", rev+"^{commit}")
+	if err != nil || commit == "" {
+		return time.Time{}, NewNotFoundError("commit %q not found in the project", rev)
+	}
+
+	// The oldest snapshot recording it, should several branches have
+	output, err := g.RunCommand("log", "--all", "--reverse", "--format=%ct", "--fixed-strings", "--grep="+projectCommitTrailer+commit)
+	if err == nil && output != "" {
+		if seconds, err := strconv.ParseInt(strings.Fields(output)[0], 10, 64); err == nil {
+			return time.Unix(seconds, 0), nil
+		}
+	}
+
+	output, err = g.runProjectCommand("log", "-1", "--format=%ct", commit)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to read commit %s: %w", commit, err)
+	}
+	seconds, err := strconv.ParseInt(output, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to read time of commit %s: %w", commit, err)
+	}
+	return time.Unix(seconds, 0), nil
+}
+
+// recordedProjectCommits returns, by hash, the project commits snapshots on
+// the current shadow branch recorded, oldest first
+func (g *GitManager) recordedProjectCommits() (map[string][]string, error) {
+	// Records end with \x1e; the body follows \x1f
+	output, err := g.RunCommand("log", "--first-parent", "--format=%H%x1f%b%x1e", "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot messages: %w", err)
+	}
+	recorded := make(map[string][]string)
+	for _, record := range strings.Split(output, "\x1e") {
+		hash, body, _ := strings.Cut(strings.TrimSpace(record), "\x1f")
+		for _, line := range strings.Split(body, "\n") {
+			if commit, ok := strings.CutPrefix(line, projectCommitTrailer); ok {
+				recorded[hash] = append(recorded[hash], strings.TrimSpace(commit))
+			}
+		}
+	}
+	return recorded, nil
+}
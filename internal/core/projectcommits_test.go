@@ -0,0 +1,85 @@
+package core
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func TestProjectCommitCorrelation(t *testing.T) {
+	tempDir, _, gitManager := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	commit := func(content string) string {
+		if err := os.WriteFile(filepath.Join(tempDir, "main.go"), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		for _, args := range [][]string{{"add", "main.go"}, {"commit", "-m", "change"}} {
+			cmd := exec.Command("git", args...)
+			cmd.Dir = tempDir
+			if output, err := cmd.CombinedOutput(); err != nil {
+				t.Fatalf("git %v failed: %v\n%s", args, err, output)
+			}
+		}
+		hash, err := gitManager.RecordProjectCommit()
+		if err != nil {
+			t.Fatalf("RecordProjectCommit failed: %v", err)
+		}
+		return hash
+	}
+	message := func() string {
+		message, err := gitManager.RunCommand("log", "-1", "--format=%B")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return message
+	}
+
+	first := commit("package main\n")
+	if again, _ := gitManager.RecordProjectCommit(); again != first || len(gitManager.pendingProjectCommits()) != 1 {
+		t.Errorf("Expected recording the same commit twice to queue it once, got %v", gitManager.pendingProjectCommits())
+	}
+	second := commit("package main // v2\n")
+	if err := gitManager.CreateSnapshot("after two commits"); err != nil {
+		t.Fatalf("CreateSnapshot failed: %v", err)
+	}
+	expected := "after two commits\n\nProject-Commit: " + first + "\nProject-Commit: " + second
+	if got := message(); got != expected {
+		t.Errorf("Expected message %q, got %q", expected, got)
+	}
+	if pending := gitManager.pendingProjectCommits(); len(pending) != 0 {
+		t.Errorf("Expected the queue to be cleared, got %v", pending)
+	}
+
+	// Later snapshots record nothing
+	os.WriteFile(filepath.Join(tempDir, "other.go"), []byte("package main\n"), 0644)
+	if err := gitManager.CreateSnapshot("plain"); err != nil {
+		t.Fatalf("CreateSnapshot failed: %v", err)
+	}
+	if got := message(); got != "plain" {
+		t.Errorf("Expected no trailers, got %q", got)
+	}
+
+	since, err := gitManager.SinceProjectCommit(second)
+	if err != nil {
+		t.Fatalf("SinceProjectCommit failed: %v", err)
+	}
+	recorded, _ := gitManager.RunCommand("log", "-1", "--format=%ct", "HEAD~1")
+	if got := strconv.FormatInt(since.Unix(), 10); got != recorded {
+		t.Errorf("Expected the time of the snapshot recording the commit (%s), got %s", recorded, got)
+	}
+
+	// A commit no snapshot recorded falls back to its own time
+	unrecorded := commit("package main // v3\n")
+	os.Remove(gitManager.projectCommitsPath())
+	if since, err := gitManager.SinceProjectCommit(unrecorded); err != nil || since.IsZero() {
+		t.Errorf("Expected the commit time, got %v, %v", since, err)
+	}
+	for _, rev := range []string{"--all", "nope"} {
+		if _, err := gitManager.SinceProjectCommit(rev); err == nil {
+			t.Errorf("Expected %q to be refused", rev)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Trailers git.provenance adds to snapshot messages, recording where each
+// snapshot was taken
+const (
+	provenanceHostTrailer    = "Snapshot-Host: "
+	provenanceToolTrailer    = "Snapshot-Tool: "
+	provenanceSessionTrailer = "Snapshot-Session: "
+)
+
+// Environment variables naming what triggered a snapshot. Wrappers around
+// agents and editors set them before running timemachine.
+const (
+	SessionEnv = "TIMEMACHINE_SESSION"
+	ToolEnv    = "TIMEMACHINE_TOOL"
+)
+
+// Signature states reported in Provenance, from git's %G?
+const (
+	SignatureNone      = "none"      // Not signed
+	SignatureGood      = "good"      // Valid and trusted
+	SignatureUntrusted = "untrusted" // Valid, but the key isn't trusted or allowed
+	SignatureBad       = "bad"       // Doesn't match the snapshot
+	SignatureUnknown   = "unknown"   // Can't be checked, e.g. the key is missing
+)
+
+// signatureStates maps git's %G? letters to signature states
+var signatureStates = map[string]string{
+	"G": SignatureGood,
+	"U": SignatureUntrusted,
+	"X": SignatureGood, // Good, but the signature has expired
+	"Y": SignatureGood, // Good, made by a key that has since expired
+	"R": SignatureUntrusted,
+	"B": SignatureBad,
+	"E": SignatureUnknown,
+	"N": SignatureNone,
+}
+
+// Provenance describes who and what produced a snapshot
+type Provenance struct {
+	Host      string `json:"host,omitempty"`
+	Tool      string `json:"tool,omitempty"`
+	Session   string `json:"session,omitempty"`
+	Agents    string `json:"agents,omitempty"` // AI coding agents running in the project, comma-separated
+	Signature string `json:"signature"`        // One of the Signature states
+	Signer    string `json:"signer,omitempty"` // Who signed, as git reports it
+	Key       string `json:"key,omitempty"`    // Fingerprint or ID of the signing key
+}
+
+// IsProvenanceTrailer reports whether a snapshot message line is one of the
+// trailers git.provenance adds
+func IsProvenanceTrailer(line string) bool {
+	return strings.HasPrefix(line, provenanceHostTrailer) ||
+		strings.HasPrefix(line, provenanceToolTrailer) ||
+		strings.HasPrefix(line, provenanceSessionTrailer) ||
+		strings.HasPrefix(line, agentTrailer)
+}
+
+// provenanceTrailers returns the trailers to add to a new snapshot's
+// message: the running session's, the detected agents' with detect.agents,
+// and the rest only when git.provenance is set. The tool is TIMEMACHINE_TOOL, or what the GitManager was told
+// triggered the snapshot.
+func (g *GitManager) provenanceTrailers() []string {
+	provenance := g.State.Config != nil && g.State.Config.Git.Provenance
+	var trailers []string
+	if provenance {
+		if host, err := os.Hostname(); err == nil && host != "" {
+			trailers = append(trailers, provenanceHostTrailer+trailerValue(host))
+		}
+		tool := os.Getenv(ToolEnv)
+		if tool == "" {
+			tool = g.Tool
+		}
+		if tool != "" {
+			trailers = append(trailers, provenanceToolTrailer+trailerValue(tool))
+		}
+	}
+	if session := g.sessionTrailer(provenance); session != "" {
+		trailers = append(trailers, session)
+	}
+	if agents := g.agentsTrailer(); agents != "" {
+		trailers = append(trailers, agents)
+	}
+	return trailers
+}
+
+// trailerValue keeps a value on its trailer's line
+func trailerValue(value string) string {
+	return strings.Join(strings.Fields(value), " ")
+}
+
+// snapshotMessageWithTrailers appends the trailers of the queued project
+// commits and of provenance to a snapshot message
+func (g *GitManager) snapshotMessageWithTrailers(message string, commits []string) string {
+	message = withProjectCommits(message, commits)
+	trailers := g.provenanceTrailers()
+	if len(trailers) == 0 {
+		return message
+	}
+	separator := "\n\n"
+	if len(commits) > 0 {
+		separator = "\n" // One trailer block
+	}
+	return message + separator + strings.Join(trailers, "\n")
+}
+
+// signingKey returns git.signing_key, with a leading ~/ expanded
+func (g *GitManager) signingKey() string {
+	if g.State.Config == nil {
+		return ""
+	}
+	key := g.State.Config.Git.SigningKey
+	if rest, ok := strings.CutPrefix(key, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			key = filepath.Join(home, rest)
+		}
+	}
+	return key
+}
+
+// isSSHSigningKey tells SSH keys, given as a key file or a literal public
+// key, from GPG key IDs
+func isSSHSigningKey(key string) bool {
+	for _, prefix := range []string{"ssh-", "ecdsa-", "sk-", "key::"} {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	if strings.ContainsAny(key, `/\`) {
+		return true
+	}
+	_, err := os.Stat(key)
+	return err == nil
+}
+
+// snapshotCommit returns the arguments that commit a snapshot with message,
+// signed when git.signing_key is set
+func (g *GitManager) snapshotCommit(message string) []string {
+	key := g.signingKey()
+	if key == "" {
+		return append(append([]string{}, snapshotCommitArgs...), "-m", message)
+	}
+	format := "openpgp"
+	if isSSHSigningKey(key) {
+		format = "ssh"
+	}
+	args := []string{"-c", "gpg.format=" + format, "-c", "user.signingkey=" + key}
+	return append(append(append(args, snapshotCommitArgs...), "-S"), "-m", message)
+}
+
+// SnapshotProvenance reads the provenance trailers of a snapshot and checks
+// its signature. SSH signatures are only checked against the keys listed in
+// git's gpg.ssh.allowedSignersFile; without one they are "unknown".
+func (g *GitManager) SnapshotProvenance(hash string) (*Provenance, error) {
+	if strings.HasPrefix(hash, "-") {
+		return nil, NewValidationError("invalid snapshot hash %q", hash)
+	}
+	output, err := g.runCommandRaw("log", "-1", "--format=%G?%x00%GS%x00%GK%x00%B", hash, "--")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot %s: %w", hash, err)
+	}
+	fields := strings.SplitN(string(output), "\x00", 4)
+	if len(fields) < 4 {
+		return nil, fmt.Errorf("failed to read snapshot %s: unexpected git log output", hash)
+	}
+
+	provenance := &Provenance{Signature: signatureStates[fields[0]], Signer: fields[1], Key: fields[2]}
+	if provenance.Signature == "" {
+		provenance.Signature = SignatureUnknown
+	}
+	if provenance.Signature == SignatureNone {
+		// git reports SSH signatures it has no allowed signers file to
+		// check against as missing
+		if raw, err := g.RunCommand("cat-file", "commit", hash); err == nil && strings.Contains(raw, "\ngpgsig") {
+			provenance.Signature = SignatureUnknown
+		}
+	}
+	for _, line := range strings.Split(fields[3], "\n") {
+		if value, ok := strings.CutPrefix(line, provenanceHostTrailer); ok {
+			provenance.Host = value
+		} else if value, ok := strings.CutPrefix(line, provenanceToolTrailer); ok {
+			provenance.Tool = value
+		} else if value, ok := strings.CutPrefix(line, provenanceSessionTrailer); ok {
+			provenance.Session = value
+		} else if value, ok := strings.CutPrefix(line, agentTrailer); ok {
+			provenance.Agents = value
+		}
+	}
+	return provenance, nil
+}
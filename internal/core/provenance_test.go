@@ -0,0 +1,117 @@
+package core
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/config"
+)
+
+func TestSnapshotProvenanceTrailers(t *testing.T) {
+	tempDir, state, gitManager := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	os.WriteFile(filepath.Join(tempDir, "plain.txt"), []byte("plain\n"), 0644)
+	if err := gitManager.CreateSnapshot("plain"); err != nil {
+		t.Fatal(err)
+	}
+	provenance, err := gitManager.SnapshotProvenance("HEAD")
+	if err != nil {
+		t.Fatalf("SnapshotProvenance failed: %v", err)
+	}
+	if provenance.Host != "" || provenance.Signature != SignatureNone {
+		t.Errorf("Expected no provenance without git.provenance, got %+v", provenance)
+	}
+
+	state.Config = &config.Config{Git: config.GitConfig{Provenance: true}}
+	gitManager.Tool = "mcp:editor"
+	t.Setenv(ToolEnv, "")
+	t.Setenv(SessionEnv, "review\n42")
+	os.WriteFile(filepath.Join(tempDir, "traced.txt"), []byte("traced\n"), 0644)
+	if err := gitManager.CreateSnapshot("traced"); err != nil {
+		t.Fatal(err)
+	}
+
+	provenance, err = gitManager.SnapshotProvenance("HEAD")
+	if err != nil {
+		t.Fatalf("SnapshotProvenance failed: %v", err)
+	}
+	host, _ := os.Hostname()
+	if provenance.Host != host || provenance.Tool != "mcp:editor" || provenance.Session != "review 42" {
+		t.Errorf("Unexpected provenance %+v", provenance)
+	}
+	message, _ := gitManager.RunCommand("log", "-1", "--format=%s")
+	if message != "traced" {
+		t.Errorf("Expected the trailers after the subject, got subject %q", message)
+	}
+
+	t.Setenv(ToolEnv, "vim")
+	os.WriteFile(filepath.Join(tempDir, "traced.txt"), []byte("again\n"), 0644)
+	gitManager.CreateSnapshot("")
+	if provenance, _ := gitManager.SnapshotProvenance("HEAD"); provenance.Tool != "vim" {
+		t.Errorf("Expected %s to name the tool, got %q", ToolEnv, provenance.Tool)
+	}
+
+	if _, err := gitManager.SnapshotProvenance("--all"); ExitCode(err) != ExitValidation {
+		t.Errorf("Expected a validation error for an option as hash, got %v", err)
+	}
+}
+
+func TestSnapshotSigning(t *testing.T) {
+	if _, err := exec.LookPath("ssh-keygen"); err != nil {
+		t.Skip("ssh-keygen not available")
+	}
+	tempDir, state, gitManager := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	key := filepath.Join(t.TempDir(), "key")
+	if output, err := exec.Command("ssh-keygen", "-q", "-t", "ed25519", "-N", "", "-f", key).CombinedOutput(); err != nil {
+		t.Fatalf("ssh-keygen failed: %v\n%s", err, output)
+	}
+	state.Config = &config.Config{Git: config.GitConfig{SigningKey: key}}
+
+	os.WriteFile(filepath.Join(tempDir, "signed.txt"), []byte("signed\n"), 0644)
+	if err := gitManager.CreateSnapshot("signed"); err != nil {
+		t.Fatalf("CreateSnapshot failed: %v", err)
+	}
+	raw, _ := gitManager.RunCommand("cat-file", "commit", "HEAD")
+	if !strings.Contains(raw, "-----BEGIN SSH SIGNATURE-----") {
+		t.Fatalf("Expected an SSH signature, got:\n%s", raw)
+	}
+
+	// Without allowed signers git can't check the signature
+	provenance, err := gitManager.SnapshotProvenance("HEAD")
+	if err != nil {
+		t.Fatalf("SnapshotProvenance failed: %v", err)
+	}
+	if provenance.Signature != SignatureUnknown {
+		t.Errorf("Expected an unchecked signature, got %+v", provenance)
+	}
+
+	public, _ := os.ReadFile(key + ".pub")
+	allowed := filepath.Join(t.TempDir(), "allowed_signers")
+	os.WriteFile(allowed, []byte("test@example.com "+string(public)), 0644)
+	gitManager.RunCommand("config", "gpg.ssh.allowedSignersFile", allowed)
+	provenance, _ = gitManager.SnapshotProvenance("HEAD")
+	if provenance.Signature != SignatureGood || provenance.Key == "" {
+		t.Errorf("Expected a good signature, got %+v", provenance)
+	}
+}
+
+func TestIsSSHSigningKey(t *testing.T) {
+	for key, want := range map[string]bool{
+		"~/.ssh/id_ed25519.pub":       true,
+		"ssh-ed25519 AAAAC3Nza":       true,
+		"key::ssh-ed25519 AAAAC3Nza":  true,
+		"3AA5C34371567BD2":            false,
+		"release@example.com":         false,
+		`C:\Users\me\.ssh\id_ed25519`: true,
+	} {
+		if got := isSSHSigningKey(key); got != want {
+			t.Errorf("isSSHSigningKey(%q) = %t, want %t", key, got, want)
+		}
+	}
+}
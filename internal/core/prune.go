@@ -0,0 +1,196 @@
+package core
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// PruneSnapshots rewrites the shadow history so that only the keep most
+// recent snapshots remain on every shadow branch, then garbage-collects the
+// dropped objects. Each snapshot stores a full tree, so the oldest retained
+// snapshot of a branch simply becomes its new root commit. Retained
+// snapshots get new hashes because their parents change; the returned map
+// translates old hashes to new ones. A snapshot retained by several
+// branches maps to its copy on the current branch.
+func (g *GitManager) PruneSnapshots(keep int) (map[string]string, error) {
+	if keep <= 0 {
+		return nil, fmt.Errorf("keep must be at least 1")
+	}
+
+	output, err := g.RunCommand("for-each-ref", "--format=%(refname)", "refs/heads")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list shadow branches: %w", err)
+	}
+	branches := strings.Fields(output)
+
+	// The current branch goes last so its copies win in the mapping
+	if current, err := g.RunCommand("symbolic-ref", "-q", "HEAD"); err == nil {
+		if i := slices.Index(branches, current); i >= 0 {
+			branches = append(slices.Delete(branches, i, i+1), current)
+		}
+	}
+
+	mapping := make(map[string]string)
+	for _, branch := range branches {
+		rewritten, err := g.pruneBranch(branch, keep)
+		if err != nil {
+			return nil, err
+		}
+		maps.Copy(mapping, rewritten)
+	}
+	if len(mapping) == 0 {
+		return mapping, nil
+	}
+
+	if err := g.reclaimSpace(); err != nil {
+		return mapping, err
+	}
+
+	return mapping, nil
+}
+
+// PruneCurrentBranch is PruneSnapshots for the current shadow branch only,
+// for cuts not made by count, such as by age
+func (g *GitManager) PruneCurrentBranch(keep int) (map[string]string, error) {
+	if keep <= 0 {
+		return nil, fmt.Errorf("keep must be at least 1")
+	}
+
+	branch, err := g.RunCommand("symbolic-ref", "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine shadow branch: %w", err)
+	}
+
+	mapping, err := g.pruneBranch(branch, keep)
+	if err != nil || len(mapping) == 0 {
+		return mapping, err
+	}
+
+	if err := g.reclaimSpace(); err != nil {
+		return mapping, err
+	}
+
+	return mapping, nil
+}
+
+// pruneBranch rewrites a branch to its keep most recent snapshots, leaving
+// garbage collection to the caller
+func (g *GitManager) pruneBranch(branch string, keep int) (map[string]string, error) {
+	oldHead, err := g.RunCommand("rev-parse", branch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", branch, err)
+	}
+
+	// Oldest first, limited to the commits we keep
+	output, err := g.RunCommand("rev-list", "--reverse", "--first-parent", fmt.Sprintf("--max-count=%d", keep), oldHead)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list retained snapshots of %s: %w", branch, err)
+	}
+	retained := strings.Fields(output)
+
+	// Nothing to drop if the oldest retained commit is already a root
+	if parents, err := g.RunCommand("log", "-1", "--format=%P", retained[0]); err == nil && strings.TrimSpace(parents) == "" {
+		return map[string]string{}, nil
+	}
+
+	newHead, mapping, err := g.rewriteCommits(retained, "")
+	if err != nil {
+		return nil, err
+	}
+
+	// Compare-and-swap so a snapshot created concurrently is never lost
+	if _, err := g.RunCommand("update-ref", "-m", "timemachine: prune snapshots", branch, newHead, oldHead); err != nil {
+		return nil, fmt.Errorf("failed to update %s (was a snapshot created during cleanup?): %w", branch, err)
+	}
+
+	return mapping, nil
+}
+
+// rewriteCommits recreates the given commits (oldest first) on top of parent
+// ("" for a new root), preserving tree, message, author and committer.
+// Returns the new tip and a map of old to new hashes.
+func (g *GitManager) rewriteCommits(commits []string, parent string) (string, map[string]string, error) {
+	mapping := make(map[string]string, len(commits))
+
+	for _, commit := range commits {
+		tree, err := g.RunCommand("rev-parse", commit+"^{tree}")
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to read tree of %s: %w", commit, err)
+		}
+
+		newCommit, err := g.recreateCommit(commit, tree, parent)
+		if err != nil {
+			return "", nil, err
+		}
+
+		mapping[commit] = newCommit
+		parent = newCommit
+	}
+
+	if err := g.carryNotes(mapping); err != nil {
+		return "", nil, err
+	}
+	return parent, mapping, nil
+}
+
+// recreateCommit writes a commit with the given tree and parent that copies
+// the message, author and committer of an existing commit
+func (g *GitManager) recreateCommit(commit, tree, parent string) (string, error) {
+	message, err := g.RunCommand("log", "-1", "--format=%B", commit)
+	if err != nil {
+		return "", fmt.Errorf("failed to read message of %s: %w", commit, err)
+	}
+	return g.recreateCommitMessage(commit, tree, parent, message)
+}
+
+// recreateCommitMessage is recreateCommit with a new message
+func (g *GitManager) recreateCommitMessage(commit, tree, parent, message string) (string, error) {
+	meta, err := g.RunCommand("log", "-1", "--format=%an%x00%ae%x00%ad%x00%cn%x00%ce%x00%cd", "--date=raw", commit)
+	if err != nil {
+		return "", fmt.Errorf("failed to read metadata of %s: %w", commit, err)
+	}
+	fields := strings.Split(meta, "\x00")
+	if len(fields) != 6 {
+		return "", fmt.Errorf("unexpected metadata for %s", commit)
+	}
+
+	env := []string{
+		"GIT_AUTHOR_NAME=" + fields[0],
+		"GIT_AUTHOR_EMAIL=" + fields[1],
+		"GIT_AUTHOR_DATE=" + fields[2],
+		"GIT_COMMITTER_NAME=" + fields[3],
+		"GIT_COMMITTER_EMAIL=" + fields[4],
+		"GIT_COMMITTER_DATE=" + fields[5],
+	}
+
+	args := []string{"commit-tree", tree, "-m", message}
+	if parent != "" {
+		args = append(args, "-p", parent)
+	}
+
+	newCommit, err := g.runCommandEnv(env, args...)
+	if err != nil {
+		return "", fmt.Errorf("failed to rewrite snapshot %s: %w", commit, err)
+	}
+
+	return newCommit, nil
+}
+
+// reclaimSpace drops reflog entries and unreachable objects left behind by
+// a history rewrite
+func (g *GitManager) reclaimSpace() error {
+	if _, err := g.RunCommand("reflog", "expire", "--expire=now", "--all"); err != nil {
+		return fmt.Errorf("failed to expire reflog: %w", err)
+	}
+	// gc repacks into new files before deleting old ones; running it on a
+	// nearly full disk can leave the repository half-written
+	if err := g.CheckDiskSpace(); err != nil {
+		return fmt.Errorf("skipped garbage collection: %w", err)
+	}
+	if _, err := g.RunCommand("gc", "--prune=now", "--quiet"); err != nil {
+		return fmt.Errorf("failed to garbage-collect shadow repository: %w", err)
+	}
+	return nil
+}
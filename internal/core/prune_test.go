@@ -0,0 +1,134 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGitManager_PruneSnapshots(t *testing.T) {
+	tempDir, _, gitManager := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	testFile := filepath.Join(tempDir, "test.txt")
+	for i := 0; i < 5; i++ {
+		content := []byte("Version " + string(rune('1'+i)))
+		if err := os.WriteFile(testFile, content, 0644); err != nil {
+			t.Fatalf("Failed to write test file: %v", err)
+		}
+		if err := gitManager.CreateSnapshot("Snapshot " + string(rune('1'+i))); err != nil {
+			t.Fatalf("Failed to create snapshot %d: %v", i+1, err)
+		}
+	}
+
+	before, err := gitManager.ListSnapshots(0, "")
+	if err != nil {
+		t.Fatalf("Failed to list snapshots: %v", err)
+	}
+
+	mapping, err := gitManager.PruneSnapshots(2)
+	if err != nil {
+		t.Fatalf("PruneSnapshots failed: %v", err)
+	}
+
+	after, err := gitManager.ListSnapshots(0, "")
+	if err != nil {
+		t.Fatalf("Failed to list snapshots after prune: %v", err)
+	}
+	if len(after) != 2 {
+		t.Fatalf("Expected 2 snapshots after prune, got %d", len(after))
+	}
+
+	// Messages and contents of the retained snapshots must be unchanged
+	if after[0].Message != "Snapshot 5" || after[1].Message != "Snapshot 4" {
+		t.Errorf("Unexpected retained snapshots: %q, %q", after[0].Message, after[1].Message)
+	}
+	if mapping[before[0].Hash] != after[0].Hash {
+		t.Errorf("Expected mapping %s -> %s, got %s", before[0].Hash, after[0].Hash, mapping[before[0].Hash])
+	}
+
+	content, err := gitManager.RunCommand("show", after[1].Hash+":test.txt")
+	if err != nil {
+		t.Fatalf("Failed to read retained content: %v", err)
+	}
+	if content != "Version 4" {
+		t.Errorf("Expected retained root to contain 'Version 4', got %q", content)
+	}
+
+	// Dropped snapshots must be gone from the object store
+	if _, err := gitManager.RunCommand("cat-file", "-e", before[4].Hash); err == nil {
+		t.Error("Expected pruned snapshot object to be garbage-collected")
+	}
+
+	// Pruning again with the same count is a no-op
+	mapping, err = gitManager.PruneSnapshots(2)
+	if err != nil {
+		t.Fatalf("Second PruneSnapshots failed: %v", err)
+	}
+	if len(mapping) != 0 {
+		t.Errorf("Expected no rewrite when nothing to prune, got %d", len(mapping))
+	}
+
+	if _, err := gitManager.PruneSnapshots(0); err == nil {
+		t.Error("Expected error for keep=0")
+	}
+}
+
+func TestGitManager_PruneSnapshotsEveryBranch(t *testing.T) {
+	tempDir, _, gitManager := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	for i := 1; i <= 3; i++ {
+		snapshotFile(t, tempDir, gitManager, "main "+string(rune('0'+i)))
+	}
+	// "feature" forks after the first snapshot and has three of its own
+	fork, err := gitManager.RunCommand("rev-parse", "HEAD~2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	parent := fork
+	for i := 1; i <= 3; i++ {
+		commit, err := gitManager.RunCommand("commit-tree", parent+"^{tree}", "-p", parent, "-m", "feature "+string(rune('0'+i)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		parent = commit
+	}
+	if _, err := gitManager.RunCommand("branch", "feature", parent); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := gitManager.PruneSnapshots(2); err != nil {
+		t.Fatalf("PruneSnapshots failed: %v", err)
+	}
+
+	for _, branch := range []string{"HEAD", "feature"} {
+		messages, err := gitManager.RunCommand("log", "--format=%s", branch)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", branch, err)
+		}
+		want := "main 3\nmain 2"
+		if branch == "feature" {
+			want = "feature 3\nfeature 2"
+		}
+		if messages != want {
+			t.Errorf("Expected %s to keep %q, got %q", branch, want, messages)
+		}
+	}
+
+	// The first snapshot was only reachable from the dropped history
+	if _, err := gitManager.RunCommand("cat-file", "-e", fork); err == nil {
+		t.Error("Expected the shared pruned snapshot to be garbage-collected")
+	}
+
+	// PruneCurrentBranch leaves other branches alone
+	if _, err := gitManager.PruneCurrentBranch(1); err != nil {
+		t.Fatalf("PruneCurrentBranch failed: %v", err)
+	}
+	if count, _ := gitManager.RunCommand("rev-list", "--count", "feature"); count != "2" {
+		t.Errorf("Expected feature to keep 2 snapshots, got %s", count)
+	}
+	if count, _ := gitManager.RunCommand("rev-list", "--count", "HEAD"); count != "1" {
+		t.Errorf("Expected the current branch to keep 1 snapshot, got %s", count)
+	}
+}
@@ -0,0 +1,268 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// PurgeRedaction replaces purged values in snapshot history
+const PurgeRedaction = "[REDACTED]"
+
+// maxPurgeCheckSize is the largest working tree file checked for values that
+// are still present after a purge
+const maxPurgeCheckSize = 10 * 1024 * 1024
+
+// PurgeResult describes a rewrite of snapshot history that erased a value
+type PurgeResult struct {
+	Blobs     int               // File versions that contained the value
+	Snapshots int               // Snapshots rewritten; they have new hashes
+	Mapping   map[string]string // Old to new hashes of the rewritten snapshots
+	Remaining []string          // Working tree files that still contain it
+}
+
+// PurgeSecrets rewrites every snapshot, on every shadow branch and backup
+// ref, replacing matches of the patterns in file contents and messages with
+// PurgeRedaction, then garbage-collects the originals. Snapshots that
+// contained a match and every snapshot after them get new hashes.
+func (g *GitManager) PurgeSecrets(patterns []*regexp.Regexp) (*PurgeResult, error) {
+	result := &PurgeResult{Mapping: map[string]string{}}
+	if len(patterns) == 0 {
+		return result, nil
+	}
+
+	output, err := g.RunCommand("for-each-ref", "--format=%(refname)", "refs/heads", "refs/timemachine")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list shadow refs: %w", err)
+	}
+	refs := strings.Fields(output)
+	if len(refs) == 0 {
+		return result, nil
+	}
+
+	blobs, err := g.purgeBlobs(refs, patterns)
+	if err != nil {
+		return nil, err
+	}
+	result.Blobs = len(blobs)
+	if err := g.purgeRefs(refs, blobs, patterns, result); err != nil {
+		return nil, err
+	}
+	if err := g.carryNotes(result.Mapping); err != nil {
+		return nil, err
+	}
+
+	result.Remaining = g.purgeRemaining(patterns)
+
+	if result.Snapshots == 0 {
+		return result, nil
+	}
+	// The index would otherwise keep the original contents reachable
+	if _, err := g.RunCommand("read-tree", "HEAD"); err != nil {
+		return nil, fmt.Errorf("failed to reset shadow index: %w", err)
+	}
+	return result, g.reclaimSpace()
+}
+
+// purgeBlobs writes a redacted copy of every file version reachable from
+// refs that matches a pattern, returning old to new blob hashes
+func (g *GitManager) purgeBlobs(refs []string, patterns []*regexp.Regexp) (map[string]string, error) {
+	output, err := g.RunCommand(append([]string{"rev-list", "--objects"}, refs...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshot objects: %w", err)
+	}
+	var objects strings.Builder
+	for _, line := range strings.Split(output, "\n") {
+		if fields := strings.Fields(line); len(fields) > 0 {
+			objects.WriteString(fields[0] + "\n")
+		}
+	}
+
+	types, err := g.runCommandInput(objects.String(), "cat-file", "--batch-check=%(objectname) %(objecttype)")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshot objects: %w", err)
+	}
+	var candidates []string
+	for _, line := range strings.Split(string(types), "\n") {
+		if hash, objectType, ok := strings.Cut(line, " "); ok && objectType == "blob" {
+			candidates = append(candidates, hash)
+		}
+	}
+
+	redacted := make(map[string][]byte)
+	err = g.batchObjects(candidates, func(i int, objectType string, content []byte, err error) error {
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", candidates[i], err)
+		}
+		changed := content
+		for _, pattern := range patterns {
+			changed = pattern.ReplaceAllLiteral(changed, []byte(PurgeRedaction))
+		}
+		if string(changed) != string(content) {
+			redacted[candidates[i]] = changed
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	blobs := make(map[string]string, len(redacted))
+	for old, content := range redacted {
+		hash, err := g.runCommandInput(string(content), "hash-object", "-w", "--stdin", "--no-filters")
+		if err != nil {
+			return nil, fmt.Errorf("failed to store redacted file: %w", err)
+		}
+		blobs[old] = strings.TrimSpace(string(hash))
+	}
+	return blobs, nil
+}
+
+// purgeRefs recreates the commits of every ref with redacted trees and
+// messages and moves the refs, compare-and-swap, onto the new commits
+func (g *GitManager) purgeRefs(refs []string, blobs map[string]string, patterns []*regexp.Regexp, result *PurgeResult) error {
+	trees := make(map[string]string)
+	commits := make(map[string]string)
+
+	for _, ref := range refs {
+		// Records start with \x01; the message follows \x02
+		output, err := g.RunCommand("log", "--reverse", "--format=%x01%H %T %P%x02%B", ref)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", ref, err)
+		}
+		tip := ""
+		for _, record := range strings.Split(output, "\x01") {
+			header, message, _ := strings.Cut(record, "\x02")
+			fields := strings.Fields(header)
+			if len(fields) < 2 {
+				continue
+			}
+			commit, tree := fields[0], fields[1]
+			tip = commit
+			if _, done := commits[commit]; done {
+				continue // Shared with a ref rewritten earlier
+			}
+
+			newTree, err := g.purgeTree(tree, blobs, trees)
+			if err != nil {
+				return err
+			}
+			parent, newParent := "", ""
+			if len(fields) > 2 {
+				parent = fields[2] // Snapshots have at most one parent
+				newParent = commits[parent]
+			}
+			message = strings.TrimSpace(message)
+			newMessage := message
+			for _, pattern := range patterns {
+				newMessage = pattern.ReplaceAllLiteralString(newMessage, PurgeRedaction)
+			}
+			if newTree == tree && newParent == parent && newMessage == message {
+				commits[commit] = commit
+				continue
+			}
+
+			newCommit, err := g.recreateCommitMessage(commit, newTree, newParent, newMessage)
+			if err != nil {
+				return err
+			}
+			commits[commit] = newCommit
+			result.Mapping[commit] = newCommit
+		}
+
+		if tip == "" || commits[tip] == tip {
+			continue
+		}
+		if _, err := g.RunCommand("update-ref", "-m", "timemachine: purge secret", ref, commits[tip], tip); err != nil {
+			return fmt.Errorf("failed to update %s (was a snapshot created during the purge?): %w", ref, err)
+		}
+	}
+
+	result.Snapshots = len(result.Mapping)
+	return nil
+}
+
+// purgeTree returns the tree with purged blobs replaced, building it in a
+// temporary index. Trees are cached since consecutive snapshots share most.
+func (g *GitManager) purgeTree(tree string, blobs, trees map[string]string) (string, error) {
+	if newTree, ok := trees[tree]; ok {
+		return newTree, nil
+	}
+
+	output, err := g.runCommandRaw("ls-tree", "-r", "-z", "--full-tree", tree)
+	if err != nil {
+		return "", fmt.Errorf("failed to read tree %s: %w", tree, err)
+	}
+	var updates []string
+	for _, entry := range strings.Split(string(output), "\x00") {
+		// <mode> SP <type> SP <object> TAB <path>
+		info, path, ok := strings.Cut(entry, "\t")
+		fields := strings.Fields(info)
+		if !ok || len(fields) != 3 {
+			continue
+		}
+		if replacement, ok := blobs[fields[2]]; ok {
+			updates = append(updates, fields[0]+","+replacement+","+path)
+		}
+	}
+	if len(updates) == 0 {
+		trees[tree] = tree
+		return tree, nil
+	}
+
+	index := filepath.Join(g.State.ShadowRepoDir, "purge-index")
+	defer os.Remove(index)
+	env := []string{"GIT_INDEX_FILE=" + index}
+	if _, err := g.runCommandEnv(env, "read-tree", tree); err != nil {
+		return "", fmt.Errorf("failed to read tree %s: %w", tree, err)
+	}
+	for start := 0; start < len(updates); start += stageChunkSize {
+		args := []string{"update-index"}
+		for _, update := range updates[start:min(start+stageChunkSize, len(updates))] {
+			args = append(args, "--cacheinfo", update)
+		}
+		if _, err := g.runCommandEnv(env, args...); err != nil {
+			return "", fmt.Errorf("failed to redact tree %s: %w", tree, err)
+		}
+	}
+	newTree, err := g.runCommandEnv(env, "write-tree")
+	if err != nil {
+		return "", fmt.Errorf("failed to write redacted tree: %w", err)
+	}
+
+	trees[tree] = newTree
+	return newTree, nil
+}
+
+// purgeRemaining lists the snapshotted files whose working tree copy still
+// matches a pattern; the next snapshot would store the value again
+func (g *GitManager) purgeRemaining(patterns []*regexp.Regexp) []string {
+	output, err := g.runCommandRaw("ls-tree", "-r", "-z", "--name-only", "--full-tree", "HEAD")
+	if err != nil {
+		return nil
+	}
+
+	var remaining []string
+	for _, path := range strings.Split(string(output), "\x00") {
+		if path == "" {
+			continue
+		}
+		file := filepath.Join(g.State.ProjectRoot, filepath.FromSlash(path))
+		if info, err := os.Lstat(file); err != nil || !info.Mode().IsRegular() || info.Size() > maxPurgeCheckSize {
+			continue
+		}
+		content, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		for _, pattern := range patterns {
+			if pattern.Match(content) {
+				remaining = append(remaining, path)
+				break
+			}
+		}
+	}
+	return remaining
+}
@@ -0,0 +1,138 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// quotaHeadroom is the share of a quota left in use after pruning, so the
+// next snapshots don't exceed it again right away and rewrite the history
+// on every snapshot
+const quotaHeadroom = 0.9
+
+// quotaCheckInterval is how often the watcher checks the quota
+const quotaCheckInterval = time.Minute
+
+// QuotaUsage is how much of git.max_commits and git.max_repo_size_mb the
+// shadow repository uses. A zero maximum is no limit.
+type QuotaUsage struct {
+	Snapshots    int   `json:"snapshots"` // On the current shadow branch, the one pruned
+	MaxSnapshots int   `json:"max_snapshots"`
+	RepoBytes    int64 `json:"repo_bytes"`
+	MaxRepoBytes int64 `json:"max_repo_bytes"`
+}
+
+// Exceeded reports whether either limit is exceeded
+func (u QuotaUsage) Exceeded() bool {
+	return u.MaxSnapshots > 0 && u.Snapshots > u.MaxSnapshots ||
+		u.MaxRepoBytes > 0 && u.RepoBytes > u.MaxRepoBytes
+}
+
+// QuotaUsage measures the shadow repository against its quota
+func (g *GitManager) QuotaUsage() (QuotaUsage, error) {
+	var usage QuotaUsage
+	if cfg := g.State.Config; cfg != nil {
+		usage.MaxSnapshots = cfg.Git.MaxCommits
+		usage.MaxRepoBytes = int64(cfg.Git.MaxRepoSizeMB) * 1024 * 1024
+	}
+
+	if output, err := g.RunCommand("rev-list", "--count", "--first-parent", "HEAD"); err == nil {
+		count, err := strconv.Atoi(output)
+		if err != nil {
+			return usage, fmt.Errorf("unexpected rev-list output %q: %w", output, err)
+		}
+		usage.Snapshots = count
+	} // Without a snapshot yet HEAD doesn't resolve
+
+	size, err := DirectorySize(g.State.ShadowRepoDir)
+	if err != nil {
+		return usage, fmt.Errorf("failed to measure shadow repository: %w", err)
+	}
+	usage.RepoBytes = size
+	return usage, nil
+}
+
+// PlanQuota plans pruning a shadow repository over its quota: the retention
+// policy first, then the oldest snapshots until the rest fit within
+// quotaHeadroom of each limit. It returns nil when the quota isn't exceeded.
+func (g *GitManager) PlanQuota(usage QuotaUsage, now time.Time) (*RetentionPlan, error) {
+	if !usage.Exceeded() {
+		return nil, nil
+	}
+	policy, err := g.RetentionPolicy()
+	if err != nil {
+		return nil, err
+	}
+	plan, err := g.PlanRetention(policy, now)
+	if err != nil {
+		return nil, err
+	}
+
+	drop := 0
+	if usage.MaxSnapshots > 0 {
+		if keep := int(float64(usage.MaxSnapshots) * quotaHeadroom); len(plan.Keep) > keep {
+			drop = len(plan.Keep) - keep
+		}
+	}
+	if usage.MaxRepoBytes > 0 && usage.RepoBytes > usage.MaxRepoBytes && len(plan.Keep) > 1 {
+		// Only snapshot objects shrink; the rest of the repository stays
+		used, err := g.diskUsage(snapshotHashes(plan.Keep))
+		if err != nil {
+			return nil, err
+		}
+		overhead := usage.RepoBytes - used
+		if overhead < 0 {
+			overhead = 0
+		}
+		limit := int64(float64(usage.MaxRepoBytes)*quotaHeadroom) - overhead
+		if limit < 1 {
+			limit = 1
+		}
+		bySize, err := g.oldestOverSize(plan.Keep, limit)
+		if err != nil {
+			return nil, err
+		}
+		if bySize > drop {
+			drop = bySize
+		}
+	}
+	if drop >= len(plan.Keep) {
+		drop = len(plan.Keep) - 1 // The newest snapshot always stays
+	}
+
+	cut := len(plan.Keep) - drop
+	plan.Remove = append(plan.Remove, plan.Keep[cut:]...)
+	plan.Keep = plan.Keep[:cut]
+	sort.SliceStable(plan.Remove, func(i, j int) bool {
+		return plan.Remove[i].Timestamp.After(plan.Remove[j].Timestamp)
+	})
+	return plan, nil
+}
+
+// EnforceQuota prunes the shadow repository when it exceeds its quota,
+// returning the plan applied, or nil when within the quota
+func (g *GitManager) EnforceQuota(now time.Time) (*RetentionPlan, error) {
+	usage, err := g.QuotaUsage()
+	if err != nil {
+		return nil, err
+	}
+	plan, err := g.PlanQuota(usage, now)
+	if err != nil || plan == nil {
+		return nil, err
+	}
+	if _, err := g.RemoveSnapshots(snapshotHashes(plan.Remove)); err != nil {
+		return plan, err
+	}
+	return plan, nil
+}
+
+// snapshotHashes returns the hashes of snapshots
+func snapshotHashes(snapshots []Snapshot) []string {
+	hashes := make([]string, len(snapshots))
+	for i, snapshot := range snapshots {
+		hashes[i] = snapshot.Hash
+	}
+	return hashes
+}
@@ -0,0 +1,62 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/config"
+)
+
+func TestEnforceQuota(t *testing.T) {
+	tempDir, state, gitManager := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+	state.Config = &config.Config{Git: config.GitConfig{MaxCommits: 10}}
+
+	for i := 0; i < 10; i++ {
+		snapshotFile(t, tempDir, gitManager, fmt.Sprintf("v%d", i))
+	}
+	plan, err := gitManager.EnforceQuota(time.Now())
+	if err != nil || plan != nil {
+		t.Fatalf("Expected nothing to prune at the limit, got %+v, %v", plan, err)
+	}
+
+	snapshotFile(t, tempDir, gitManager, "v10")
+	usage, err := gitManager.QuotaUsage()
+	if err != nil || usage.Snapshots != 11 || !usage.Exceeded() {
+		t.Fatalf("Expected 11 snapshots over the quota of 10, got %+v, %v", usage, err)
+	}
+	plan, err = gitManager.EnforceQuota(time.Now())
+	if err != nil {
+		t.Fatalf("EnforceQuota failed: %v", err)
+	}
+	if plan == nil || len(plan.Keep) != 9 || len(plan.Remove) != 2 {
+		t.Fatalf("Expected 9 snapshots kept and 2 removed, got %+v", plan)
+	}
+
+	snapshots, err := gitManager.ListSnapshots(0, "")
+	if err != nil || len(snapshots) != 9 {
+		t.Fatalf("Expected 9 snapshots left, got %d, %v", len(snapshots), err)
+	}
+	if snapshots[0].Message != "v10" || snapshots[8].Message != "v2" {
+		t.Errorf("Expected the oldest snapshots to go, left %q..%q", snapshots[8].Message, snapshots[0].Message)
+	}
+}
+
+func TestQuotaUsageExceeded(t *testing.T) {
+	tests := []struct {
+		usage    QuotaUsage
+		expected bool
+	}{
+		{QuotaUsage{Snapshots: 10, MaxSnapshots: 10}, false},
+		{QuotaUsage{Snapshots: 11, MaxSnapshots: 10}, true},
+		{QuotaUsage{RepoBytes: 2 << 20, MaxRepoBytes: 1 << 20}, true},
+		{QuotaUsage{RepoBytes: 2 << 20}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.usage.Exceeded(); got != tt.expected {
+			t.Errorf("%+v.Exceeded() = %v, want %v", tt.usage, got, tt.expected)
+		}
+	}
+}
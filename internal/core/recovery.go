@@ -0,0 +1,156 @@
+package core
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ShadowRepoBusyError reports lock files that may still belong to a running
+// git process, so they were left alone
+type ShadowRepoBusyError struct {
+	Locks []string
+}
+
+func (e *ShadowRepoBusyError) Error() string {
+	return fmt.Sprintf("shadow repository is locked by another process (%s)", strings.Join(e.Locks, ", "))
+}
+
+// shadowLockFiles lists git lock files in the shadow repository, relative
+// to it: index.lock, HEAD.lock, config.lock, packed-refs.lock and ref locks
+func (g *GitManager) shadowLockFiles() []string {
+	var locks []string
+
+	for _, name := range []string{"index.lock", "HEAD.lock", "config.lock", "packed-refs.lock"} {
+		if _, err := os.Lstat(filepath.Join(g.State.ShadowRepoDir, name)); err == nil {
+			locks = append(locks, name)
+		}
+	}
+
+	refsDir := filepath.Join(g.State.ShadowRepoDir, "refs")
+	filepath.WalkDir(refsDir, func(path string, entry fs.DirEntry, err error) error {
+		if err == nil && !entry.IsDir() && strings.HasSuffix(path, ".lock") {
+			if rel, err := filepath.Rel(g.State.ShadowRepoDir, path); err == nil {
+				locks = append(locks, filepath.ToSlash(rel))
+			}
+		}
+		return nil
+	})
+
+	sort.Strings(locks)
+	return locks
+}
+
+// RecoverInterruptedCommit repairs the state a git process killed mid-commit
+// leaves in the shadow repository, which otherwise makes every later
+// snapshot fail: stale lock files are removed and a truncated index is
+// rebuilt from HEAD. A lock is only removed when no running git process uses
+// the shadow repository (checked via /proc on Linux) or, where that can't be
+// checked, when it is older than any snapshot should take. Returns a
+// description of each repair, plus a ShadowRepoBusyError when a lock may
+// still be in use.
+func (g *GitManager) RecoverInterruptedCommit() ([]string, error) {
+	removed, err := g.removeStaleLocks()
+	repairs := make([]string, 0, len(removed)+1)
+	for _, lock := range removed {
+		repairs = append(repairs, "removed stale "+lock)
+	}
+	if err != nil {
+		return repairs, err
+	}
+
+	rebuilt, err := g.repairIndex()
+	if err != nil {
+		return repairs, err
+	}
+	if rebuilt {
+		repairs = append(repairs, "rebuilt truncated index from HEAD")
+	}
+
+	return repairs, nil
+}
+
+// removeStaleLocks removes lock files no running process can still own
+func (g *GitManager) removeStaleLocks() ([]string, error) {
+	locks := g.shadowLockFiles()
+	if len(locks) == 0 {
+		return nil, nil
+	}
+
+	minAge := staleLockAgeUnknownHolder
+	if busy, known := shadowRepoInUse(g.State.ShadowRepoDir); known {
+		if busy {
+			return nil, &ShadowRepoBusyError{Locks: locks}
+		}
+		minAge = staleLockAgeNoHolder
+	}
+
+	var removed, busy []string
+	for _, lock := range locks {
+		path := filepath.Join(g.State.ShadowRepoDir, filepath.FromSlash(lock))
+		info, err := os.Lstat(path)
+		if err != nil {
+			continue // Released in the meantime
+		}
+		if time.Since(info.ModTime()) < minAge {
+			busy = append(busy, lock)
+			continue
+		}
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return removed, fmt.Errorf("failed to remove stale %s: %w", lock, err)
+		}
+		removed = append(removed, lock)
+	}
+
+	if len(busy) > 0 {
+		return removed, &ShadowRepoBusyError{Locks: busy}
+	}
+	return removed, nil
+}
+
+// repairIndex rebuilds the shadow index from HEAD when it doesn't start with
+// git's "DIRC" signature. Git replaces the index atomically, so this only
+// happens when the system crashed while the file was being written.
+func (g *GitManager) repairIndex() (bool, error) {
+	indexPath := filepath.Join(g.State.ShadowRepoDir, "index")
+	file, err := os.Open(indexPath)
+	if err != nil {
+		return false, nil // No index yet; git creates one
+	}
+	header := make([]byte, 4)
+	_, err = io.ReadFull(file, header)
+	file.Close()
+	if err == nil && string(header) == "DIRC" {
+		return false, nil
+	}
+
+	if err := os.Remove(indexPath); err != nil {
+		return false, fmt.Errorf("failed to remove truncated index: %w", err)
+	}
+	// Without commits there is nothing to rebuild; the next add starts fresh
+	if _, err := g.RunCommand("rev-parse", "--verify", "--quiet", "HEAD"); err != nil {
+		return true, nil
+	}
+	if _, err := g.RunCommand("read-tree", "HEAD"); err != nil {
+		return true, fmt.Errorf("failed to rebuild index: %w", err)
+	}
+	return true, nil
+}
+
+// Minimum lock age before removal. With no git process on the shadow repo
+// a short grace period covers a process that is just starting; without a
+// way to check for one, wait far longer than any snapshot takes.
+const (
+	staleLockAgeNoHolder      = 2 * time.Second
+	staleLockAgeUnknownHolder = 2 * time.Minute
+)
+
+// isLockError reports whether a git error was caused by an existing lock
+func isLockError(err error) bool {
+	return err != nil && strings.Contains(err.Error(), ".lock': File exists")
+}
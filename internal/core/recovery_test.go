@@ -0,0 +1,151 @@
+package core
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// writeStaleLock creates a lock file old enough to count as abandoned
+func writeStaleLock(t *testing.T, state *AppState, name string) string {
+	path := filepath.Join(state.ShadowRepoDir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("Failed to create lock dir: %v", err)
+	}
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatalf("Failed to write lock: %v", err)
+	}
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(path, old, old); err != nil {
+		t.Fatalf("Failed to age lock: %v", err)
+	}
+	return path
+}
+
+func TestRecoverInterruptedCommitRemovesStaleLocks(t *testing.T) {
+	tempDir, state, gitManager := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	if err := os.WriteFile(filepath.Join(tempDir, "file.txt"), []byte("v1"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := gitManager.CreateSnapshot("first"); err != nil {
+		t.Fatalf("CreateSnapshot failed: %v", err)
+	}
+
+	writeStaleLock(t, state, "index.lock")
+	writeStaleLock(t, state, "refs/heads/master.lock")
+
+	if err := os.WriteFile(filepath.Join(tempDir, "file.txt"), []byte("v2"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := gitManager.CreateSnapshot("blocked"); !isLockError(err) {
+		t.Fatalf("Expected lock error, got %v", err)
+	}
+
+	repairs, err := gitManager.RecoverInterruptedCommit()
+	if err != nil {
+		t.Fatalf("RecoverInterruptedCommit failed: %v", err)
+	}
+	expected := []string{"removed stale index.lock", "removed stale refs/heads/master.lock"}
+	if !reflect.DeepEqual(repairs, expected) {
+		t.Errorf("Expected repairs %v, got %v", expected, repairs)
+	}
+
+	if err := gitManager.CreateSnapshot("recovered"); err != nil {
+		t.Fatalf("Expected snapshot after recovery, got %v", err)
+	}
+	snapshots, err := gitManager.ListSnapshots(1, "")
+	if err != nil || len(snapshots) != 1 || snapshots[0].Message != "recovered" {
+		t.Errorf("Expected latest snapshot 'recovered', got %v (%v)", snapshots, err)
+	}
+
+	// Nothing left to do on a clean repository
+	repairs, err = gitManager.RecoverInterruptedCommit()
+	if err != nil || len(repairs) != 0 {
+		t.Errorf("Expected no repairs, got %v (%v)", repairs, err)
+	}
+}
+
+func TestRecoverInterruptedCommitKeepsFreshLocks(t *testing.T) {
+	tempDir, state, gitManager := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	lock := filepath.Join(state.ShadowRepoDir, "index.lock")
+	if err := os.WriteFile(lock, nil, 0644); err != nil {
+		t.Fatalf("Failed to write lock: %v", err)
+	}
+
+	// A lock created just now may belong to a git process that is starting
+	repairs, err := gitManager.RecoverInterruptedCommit()
+	if _, ok := err.(*ShadowRepoBusyError); !ok {
+		t.Errorf("Expected ShadowRepoBusyError, got %v", err)
+	}
+	if len(repairs) != 0 {
+		t.Errorf("Expected no repairs, got %v", repairs)
+	}
+	if _, err := os.Stat(lock); err != nil {
+		t.Errorf("Expected fresh lock to be kept: %v", err)
+	}
+}
+
+func TestRecoverInterruptedCommitKeepsLocksOfRunningProcess(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("process detection is only available on Linux")
+	}
+
+	tempDir, state, gitManager := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	lock := writeStaleLock(t, state, "index.lock")
+
+	// Stand-in for a slow git process working on the shadow repository
+	holder := exec.Command("sh", "-c", "sleep 10", "--git-dir="+state.ShadowRepoDir)
+	if err := holder.Start(); err != nil {
+		t.Fatalf("Failed to start holder: %v", err)
+	}
+	defer func() {
+		holder.Process.Kill()
+		holder.Wait()
+	}()
+
+	if _, err := gitManager.RecoverInterruptedCommit(); err == nil {
+		t.Error("Expected error while another process uses the shadow repository")
+	}
+	if _, err := os.Stat(lock); err != nil {
+		t.Errorf("Expected lock to be kept: %v", err)
+	}
+}
+
+func TestRecoverInterruptedCommitRebuildsTruncatedIndex(t *testing.T) {
+	tempDir, state, gitManager := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	if err := os.WriteFile(filepath.Join(tempDir, "file.txt"), []byte("content"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := gitManager.CreateSnapshot("first"); err != nil {
+		t.Fatalf("CreateSnapshot failed: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(state.ShadowRepoDir, "index"), nil, 0644); err != nil {
+		t.Fatalf("Failed to truncate index: %v", err)
+	}
+
+	repairs, err := gitManager.RecoverInterruptedCommit()
+	if err != nil {
+		t.Fatalf("RecoverInterruptedCommit failed: %v", err)
+	}
+	if !reflect.DeepEqual(repairs, []string{"rebuilt truncated index from HEAD"}) {
+		t.Errorf("Expected index rebuild, got %v", repairs)
+	}
+
+	files, err := gitManager.RunCommand("ls-files")
+	if err != nil || files != "file.txt" {
+		t.Errorf("Expected rebuilt index to list file.txt, got %q (%v)", files, err)
+	}
+}
@@ -0,0 +1,87 @@
+package core
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ReadHead resolves the commit the repository's HEAD points to by reading
+// ref files directly, without spawning git. Returns the ref name (empty when
+// HEAD is detached) and the commit hash (empty on an unborn branch).
+func ReadHead(gitDir string) (ref string, hash string, err error) {
+	content, err := os.ReadFile(filepath.Join(gitDir, "HEAD"))
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read HEAD: %w", err)
+	}
+
+	head := strings.TrimSpace(string(content))
+	if !strings.HasPrefix(head, "ref: ") {
+		// Detached HEAD contains the commit hash directly
+		return "", head, nil
+	}
+
+	ref = strings.TrimPrefix(head, "ref: ")
+	hash, err = ResolveRef(gitDir, ref)
+	return ref, hash, err
+}
+
+// ResolveRef looks up a fully qualified ref (e.g. refs/heads/main) in loose
+// ref files first and then packed-refs, in the common directory too for a
+// linked worktree. Returns an empty hash if the ref does not exist.
+func ResolveRef(gitDir, ref string) (string, error) {
+	if content, err := os.ReadFile(filepath.Join(gitDir, filepath.FromSlash(ref))); err == nil {
+		return strings.TrimSpace(string(content)), nil
+	}
+	commonDir := CommonDir(gitDir)
+	if commonDir != gitDir {
+		if content, err := os.ReadFile(filepath.Join(commonDir, filepath.FromSlash(ref))); err == nil {
+			return strings.TrimSpace(string(content)), nil
+		}
+	}
+
+	file, err := os.Open(filepath.Join(commonDir, "packed-refs"))
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to open packed-refs: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		// Skip header comments and peeled tag lines
+		if strings.HasPrefix(line, "#") || strings.HasPrefix(line, "^") {
+			continue
+		}
+		parts := strings.SplitN(line, " ", 2)
+		if len(parts) == 2 && parts[1] == ref {
+			return parts[0], nil
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("failed to read packed-refs: %w", err)
+	}
+
+	return "", nil
+}
+
+// CommonDir returns the directory holding what all worktrees of a
+// repository share (branches, packed-refs, hooks): the main repository's
+// git directory for a linked worktree's, gitDir itself otherwise
+func CommonDir(gitDir string) string {
+	content, err := os.ReadFile(filepath.Join(gitDir, "commondir"))
+	if err != nil {
+		return gitDir
+	}
+	dir := strings.TrimSpace(string(content))
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(gitDir, dir)
+	}
+	return filepath.Clean(dir)
+}
@@ -0,0 +1,77 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadHead(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "timemachine-refs-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	gitDir := filepath.Join(tempDir, ".git")
+	if err := os.MkdirAll(filepath.Join(gitDir, "refs", "heads"), 0755); err != nil {
+		t.Fatalf("Failed to create refs dir: %v", err)
+	}
+
+	looseHash := "1111111111111111111111111111111111111111"
+	packedHash := "2222222222222222222222222222222222222222"
+
+	writeFile := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(gitDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	writeFile("refs/heads/main", looseHash+"\n")
+	writeFile("packed-refs", "# pack-refs with: peeled fully-peeled sorted\n"+
+		packedHash+" refs/heads/packed\n^3333333333333333333333333333333333333333\n")
+
+	t.Run("LooseRef", func(t *testing.T) {
+		writeFile("HEAD", "ref: refs/heads/main\n")
+		ref, hash, err := ReadHead(gitDir)
+		if err != nil {
+			t.Fatalf("ReadHead failed: %v", err)
+		}
+		if ref != "refs/heads/main" || hash != looseHash {
+			t.Errorf("Expected refs/heads/main at %s, got %s at %s", looseHash, ref, hash)
+		}
+	})
+
+	t.Run("PackedRef", func(t *testing.T) {
+		writeFile("HEAD", "ref: refs/heads/packed\n")
+		_, hash, err := ReadHead(gitDir)
+		if err != nil {
+			t.Fatalf("ReadHead failed: %v", err)
+		}
+		if hash != packedHash {
+			t.Errorf("Expected packed hash %s, got %s", packedHash, hash)
+		}
+	})
+
+	t.Run("UnbornBranch", func(t *testing.T) {
+		writeFile("HEAD", "ref: refs/heads/unborn\n")
+		_, hash, err := ReadHead(gitDir)
+		if err != nil {
+			t.Fatalf("ReadHead failed: %v", err)
+		}
+		if hash != "" {
+			t.Errorf("Expected empty hash for unborn branch, got %s", hash)
+		}
+	})
+
+	t.Run("DetachedHead", func(t *testing.T) {
+		writeFile("HEAD", looseHash+"\n")
+		ref, hash, err := ReadHead(gitDir)
+		if err != nil {
+			t.Fatalf("ReadHead failed: %v", err)
+		}
+		if ref != "" || hash != looseHash {
+			t.Errorf("Expected detached HEAD at %s, got ref %q hash %s", looseHash, ref, hash)
+		}
+	})
+}
@@ -0,0 +1,118 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/config"
+	"github.com/fatih/color"
+)
+
+// restartKeys are settings a running watcher only reads when it starts
+var restartKeys = map[string]bool{
+	"api.enabled":               true,
+	"api.listen":                true,
+	"backup.interval":           true,
+	"git.backend":               true,
+	"git.verify_interval":       true,
+	"git.verify_sample":         true,
+	"metrics.listen":            true,
+	"remote.push_interval":      true,
+	"watcher.include_paths":     true,
+	"watcher.interval_snapshot": true,
+	"watcher.max_watched_files": true,
+}
+
+// ConfigReload describes the outcome of reloading the configuration in a
+// running watcher
+type ConfigReload struct {
+	At              time.Time `json:"at"`
+	Changed         []string  `json:"changed,omitempty"`          // Keys whose values changed
+	RestartRequired []string  `json:"restart_required,omitempty"` // Changed keys that only apply after a restart
+	Error           string    `json:"error,omitempty"`            // Why the configuration was not applied
+}
+
+// configReloadRequest hands a loaded configuration to the event loop
+type configReloadRequest struct {
+	manager *config.Manager
+	done    chan ConfigReload
+}
+
+// ReloadConfig loads and validates the configuration again and applies it
+// to the running watcher. An invalid configuration is reported and the
+// current one kept.
+func (w *Watcher) ReloadConfig() ConfigReload {
+	manager := config.NewManager()
+	if err := manager.Load(w.state.ProjectRoot); err != nil {
+		reload := ConfigReload{At: time.Now(), Error: err.Error()}
+		color.Yellow("⚠️  Configuration not reloaded: %v", err)
+		w.recordReload(reload)
+		return reload
+	}
+
+	request := configReloadRequest{manager: manager, done: make(chan ConfigReload, 1)}
+	select {
+	case w.configReloads <- request:
+	case <-w.stopChan:
+		return ConfigReload{At: time.Now(), Error: "the watcher is stopping"}
+	}
+	return <-request.done
+}
+
+// applyConfig switches the watcher to a reloaded configuration. It runs on
+// the event loop, so ignore patterns never change under a check, and holds
+// snapshotMu, so no snapshot sees a mix of old and new settings.
+func (w *Watcher) applyConfig(manager *config.Manager) ConfigReload {
+	w.snapshotMu.Lock()
+	defer w.snapshotMu.Unlock()
+
+	cfg := manager.Get()
+	reload := ConfigReload{At: time.Now()}
+	if w.state.Config != nil {
+		reload.Changed = config.Changed(w.state.Config, cfg)
+	} else {
+		reload.Changed = config.Keys()
+	}
+	for _, key := range reload.Changed {
+		if restartKeys[key] {
+			reload.RestartRequired = append(reload.RestartRequired, key)
+		}
+	}
+
+	w.state.Config = cfg
+	w.state.ConfigManager = manager
+
+	w.debouncer.SetDelay(cfg.Watcher.DebounceDelay)
+	w.mu.Lock()
+	w.batchSize, w.batchWindow = cfg.Watcher.BatchSize, cfg.Watcher.BatchWindow
+	w.stormThreshold = cfg.Watcher.StormThreshold
+	w.debounceOverrides = parseDebounceOverrides(cfg.Watcher.DebounceOverrides)
+	w.mu.Unlock()
+	w.ignoreManager.ConfigureCache(cfg.Cache.MaxEntries, cfg.Cache.EnableLRU)
+	if err := w.ignoreManager.SetPatterns(IgnoreSourceConfig, cfg.Watcher.IgnorePatterns); err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
+	if w.ignoreManager.respectGitignore != cfg.Watcher.RespectGitignore {
+		w.ignoreManager.SetRespectGitignore(cfg.Watcher.RespectGitignore)
+		w.reloadIgnorePatterns()
+	}
+
+	if len(reload.Changed) == 0 {
+		fmt.Println("🔄 Reloaded configuration: nothing changed")
+	} else {
+		fmt.Printf("🔄 Reloaded configuration: %s\n", strings.Join(reload.Changed, ", "))
+	}
+	if len(reload.RestartRequired) > 0 {
+		color.Yellow("⚠️  Restart the watcher to apply: %s", strings.Join(reload.RestartRequired, ", "))
+	}
+	w.recordReload(reload)
+	return reload
+}
+
+// recordReload keeps the outcome of the last reload for Stats
+func (w *Watcher) recordReload(reload ConfigReload) {
+	w.mu.Lock()
+	w.lastReload = &reload
+	w.mu.Unlock()
+}
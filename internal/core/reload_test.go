@@ -0,0 +1,76 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/config"
+)
+
+func TestWatcherReloadConfig(t *testing.T) {
+	tempDir, state, gitManager := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	configFile := filepath.Join(tempDir, "timemachine.yaml")
+	writeConfig := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(configFile, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeConfig("watcher:\n  debounce_delay: 1s\n")
+
+	manager := config.NewManager()
+	if err := manager.Load(tempDir); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	state.Config = manager.Get()
+	state.ConfigManager = manager
+
+	watcher, err := NewWatcher(state, gitManager)
+	if err != nil {
+		t.Fatalf("NewWatcher failed: %v", err)
+	}
+	if err := watcher.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer watcher.Stop()
+
+	writeConfig("watcher:\n  debounce_delay: 3s\n  batch_size: 5\n  respect_gitignore: true\nmetrics:\n  listen: 127.0.0.1:9190\n")
+	reload := watcher.ReloadConfig()
+	if reload.Error != "" {
+		t.Fatalf("ReloadConfig failed: %s", reload.Error)
+	}
+	expected := []string{"metrics.listen", "watcher.batch_size", "watcher.debounce_delay", "watcher.respect_gitignore"}
+	if !reflect.DeepEqual(reload.Changed, expected) {
+		t.Errorf("Expected changes %v, got %v", expected, reload.Changed)
+	}
+	if !reflect.DeepEqual(reload.RestartRequired, []string{"metrics.listen"}) {
+		t.Errorf("Expected metrics.listen to require a restart, got %v", reload.RestartRequired)
+	}
+	if state.Config.Watcher.DebounceDelay != 3*time.Second || watcher.debouncer.delay != 3*time.Second {
+		t.Errorf("Expected the new debounce delay to apply")
+	}
+	if watcher.batchSize != 5 {
+		t.Errorf("Expected batch size 5, got %d", watcher.batchSize)
+	}
+	if !watcher.ignoreManager.respectGitignore {
+		t.Errorf("Expected .gitignore files to be honored after the reload")
+	}
+	if stats := watcher.Stats(); stats.LastConfigReload == nil || !reflect.DeepEqual(stats.LastConfigReload.Changed, expected) {
+		t.Errorf("Expected the reload in the watcher stats, got %+v", stats.LastConfigReload)
+	}
+
+	// An invalid configuration is rejected and the current one kept
+	writeConfig("watcher:\n  debounce_delay: 1ms\n")
+	reload = watcher.ReloadConfig()
+	if reload.Error == "" {
+		t.Errorf("Expected an invalid configuration to be rejected")
+	}
+	if state.Config.Watcher.DebounceDelay != 3*time.Second {
+		t.Errorf("Expected the previous configuration to be kept, got debounce %s", state.Config.Watcher.DebounceDelay)
+	}
+}
@@ -0,0 +1,342 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// DefaultRemote is the remote push and pull use when remote.name is unset
+const DefaultRemote = "origin"
+
+// remoteName matches remote and machine names that are safe in ref names and
+// as git arguments, like remote.name and remote.machine allow
+var remoteName = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9._-]*$`)
+
+// unsafeMachineChars are replaced in hostnames used as machine names
+var unsafeMachineChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// remoteGitEnv keeps git from prompting for credentials, which nobody would
+// answer in the watcher; remotes authenticate with SSH keys or a credential
+// helper
+var remoteGitEnv = []string{"GIT_TERMINAL_PROMPT=0"}
+
+// Remote is a remote backup of the shadow repository
+type Remote struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+// RemoteBranch is a shadow branch of one machine, as last fetched from a
+// remote. Ref names it for list --branch and restore.
+type RemoteBranch struct {
+	Ref          string    `json:"ref"` // <remote>/<machine>/<branch>
+	Machine      string    `json:"machine"`
+	Branch       string    `json:"branch"`
+	Snapshots    int       `json:"snapshots"`
+	LastSnapshot time.Time `json:"last_snapshot"`
+}
+
+// Push outcomes of a branch
+const (
+	PushCreated  = "created"    // The remote had no snapshots of the branch
+	PushUpdated  = "updated"    // New snapshots appended
+	PushMerged   = "merged"     // Local history was rewritten; joined to the remote's so nothing there is lost
+	PushUpToDate = "up-to-date" // Nothing new to push
+)
+
+// PushResult describes the push of one shadow branch
+type PushResult struct {
+	Branch string `json:"branch"`
+	Ref    string `json:"ref"` // Branch on the remote, <machine>/<branch>
+	Status string `json:"status"`
+}
+
+// remoteSettings returns remote.name and the machine name
+func (g *GitManager) remoteSettings() (name, machine string) {
+	name = DefaultRemote
+	if g.State.Config != nil && g.State.Config.Remote.Name != "" {
+		name = g.State.Config.Remote.Name
+	}
+	return name, g.machineName()
+}
+
+// machineName names this machine on remotes and in backups: remote.machine,
+// or the hostname
+func (g *GitManager) machineName() string {
+	machine := ""
+	if g.State.Config != nil {
+		machine = g.State.Config.Remote.Machine
+	}
+	if machine == "" {
+		host, _ := os.Hostname()
+		machine = strings.Trim(unsafeMachineChars.ReplaceAllString(host, "-"), "-.")
+	}
+	if machine == "" {
+		machine = "default"
+	}
+	return machine
+}
+
+// remoteOrDefault validates a remote name, remote.name when empty
+func (g *GitManager) remoteOrDefault(name string) (string, error) {
+	if name == "" {
+		name, _ = g.remoteSettings()
+	}
+	if !remoteName.MatchString(name) {
+		return "", NewValidationError("invalid remote name %q: use letters, digits, '.', '_' and '-'", name)
+	}
+	return name, nil
+}
+
+// Remotes lists the remotes of the shadow repository by name
+func (g *GitManager) Remotes() ([]Remote, error) {
+	output, err := g.RunCommand("remote", "-v")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list remotes: %w", err)
+	}
+	var remotes []Remote
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 3 || fields[2] != "(fetch)" {
+			continue
+		}
+		remotes = append(remotes, Remote{Name: fields[0], URL: fields[1]})
+	}
+	sort.Slice(remotes, func(i, j int) bool { return remotes[i].Name < remotes[j].Name })
+	return remotes, nil
+}
+
+// remoteURL returns the URL of a remote, or a NotFoundError
+func (g *GitManager) remoteURL(name string) (string, error) {
+	url, err := g.RunCommand("config", "--get", "remote."+name+".url")
+	if err != nil || url == "" {
+		return "", NewNotFoundError("remote %q not found; add it with 'timemachine remote add %s <url>'", name, name)
+	}
+	return url, nil
+}
+
+// AddRemote adds a remote to back up the shadow repository to. Use a
+// private repository: snapshots hold every file of the project, ignored
+// secrets aside.
+func (g *GitManager) AddRemote(name, url string) error {
+	name, err := g.remoteOrDefault(name)
+	if err != nil {
+		return err
+	}
+	if url == "" || strings.HasPrefix(url, "-") || strings.ContainsAny(url, "\n\r") {
+		return NewValidationError("invalid remote URL %q", url)
+	}
+	if _, err := g.remoteURL(name); err == nil {
+		return NewValidationError("remote %q already exists; remove it first with 'timemachine remote remove %s'", name, name)
+	}
+	if _, err := g.RunCommand("remote", "add", name, url); err != nil {
+		return fmt.Errorf("failed to add remote %s: %w", name, err)
+	}
+	return nil
+}
+
+// RemoveRemote removes a remote and the branches pulled from it; their
+// snapshots go with the next garbage collection. The remote itself is left
+// untouched.
+func (g *GitManager) RemoveRemote(name string) error {
+	name, err := g.remoteOrDefault(name)
+	if err != nil {
+		return err
+	}
+	if _, err := g.remoteURL(name); err != nil {
+		return err
+	}
+	if _, err := g.RunCommand("remote", "remove", name); err != nil {
+		return fmt.Errorf("failed to remove remote %s: %w", name, err)
+	}
+	return nil
+}
+
+// PushSnapshots pushes every shadow branch to the remote, each to
+// <machine>/<branch> so machines never overwrite one another. Pushes are
+// append-only: nothing is ever forced, and a branch whose local history was
+// rewritten (by retention, compact or clean) since the last push is joined
+// to the remote's by a merge, so snapshots already on the remote stay
+// reachable. Local branches are never changed.
+func (g *GitManager) PushSnapshots(remote string) ([]PushResult, error) {
+	remote, err := g.remoteOrDefault(remote)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := g.remoteURL(remote); err != nil {
+		return nil, err
+	}
+	_, machine := g.remoteSettings()
+
+	// Learn what the remote has of this machine's branches
+	namespace := fmt.Sprintf("+refs/heads/%s/*:refs/remotes/%s/%s/*", machine, remote, machine)
+	if _, err := g.runCommandEnv(remoteGitEnv, "fetch", "--no-tags", "--quiet", remote, namespace); err != nil {
+		return nil, fmt.Errorf("failed to fetch from %s: %w", remote, err)
+	}
+
+	output, err := g.RunCommand("for-each-ref", "--format=%(refname:short)%09%(objectname)", "refs/heads")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list shadow branches: %w", err)
+	}
+	var results []PushResult
+	var refspecs []string
+	for _, line := range strings.Split(output, "\n") {
+		branch, local, ok := strings.Cut(line, "\t")
+		if !ok {
+			continue
+		}
+		result := PushResult{Branch: branch, Ref: machine + "/" + branch}
+		remoteTip, _ := g.RunCommand("rev-parse", "--verify", "--quiet", "refs/remotes/"+remote+"/"+result.Ref)
+
+		push := local
+		switch {
+		case remoteTip == "":
+			result.Status = PushCreated
+		case remoteTip == local || g.isAncestor(local, remoteTip):
+			result.Status = PushUpToDate
+		case g.isAncestor(remoteTip, local):
+			result.Status = PushUpdated
+		default:
+			merge, err := g.RunCommand("commit-tree", local+"^{tree}", "-p", remoteTip, "-p", local,
+				"-m", fmt.Sprintf("Keep earlier snapshots of %s on %s", branch, remote))
+			if err != nil {
+				return nil, fmt.Errorf("failed to join the history of %s: %w", branch, err)
+			}
+			push = merge
+			result.Status = PushMerged
+		}
+		if result.Status != PushUpToDate {
+			refspecs = append(refspecs, push+":refs/heads/"+result.Ref)
+		}
+		results = append(results, result)
+	}
+
+	if len(refspecs) > 0 {
+		args := append([]string{"push", "--quiet", "--no-verify", remote}, refspecs...)
+		if _, err := g.runCommandEnv(remoteGitEnv, args...); err != nil {
+			return nil, fmt.Errorf("failed to push to %s: %w", remote, err)
+		}
+		// Record what the remote has now, for the next push
+		for _, spec := range refspecs {
+			hash, ref, _ := strings.Cut(spec, ":")
+			tracking := "refs/remotes/" + remote + "/" + strings.TrimPrefix(ref, "refs/heads/")
+			g.RunCommand("update-ref", tracking, hash)
+		}
+	}
+	return results, nil
+}
+
+// PullSnapshots fetches the snapshots of every machine from the remote, to
+// list and restore after losing a machine. They are kept under
+// <remote>/<machine>/<branch>, apart from the local branches. Branches
+// deleted on the remote are kept.
+func (g *GitManager) PullSnapshots(remote string) ([]RemoteBranch, error) {
+	remote, err := g.remoteOrDefault(remote)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := g.remoteURL(remote); err != nil {
+		return nil, err
+	}
+	refspec := fmt.Sprintf("+refs/heads/*:refs/remotes/%s/*", remote)
+	if _, err := g.runCommandEnv(remoteGitEnv, "fetch", "--no-tags", "--quiet", remote, refspec); err != nil {
+		return nil, fmt.Errorf("failed to fetch from %s: %w", remote, err)
+	}
+	return g.RemoteBranches(remote)
+}
+
+// RemoteBranches lists the branches last fetched from a remote
+func (g *GitManager) RemoteBranches(remote string) ([]RemoteBranch, error) {
+	output, err := g.RunCommand("for-each-ref", "--format=%(refname)%09%(committerdate:unix)", "refs/remotes/"+remote+"/")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list branches of %s: %w", remote, err)
+	}
+	var branches []RemoteBranch
+	for _, line := range strings.Split(output, "\n") {
+		ref, date, ok := strings.Cut(line, "\t")
+		if !ok {
+			continue
+		}
+		name := strings.TrimPrefix(ref, "refs/remotes/")
+		machine, branch, ok := strings.Cut(strings.TrimPrefix(name, remote+"/"), "/")
+		if !ok {
+			continue // Not written by push, e.g. HEAD
+		}
+		count, err := g.RunCommand("rev-list", "--count", ref)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count snapshots on %s: %w", name, err)
+		}
+		remoteBranch := RemoteBranch{Ref: name, Machine: machine, Branch: branch}
+		remoteBranch.Snapshots, _ = strconv.Atoi(count)
+		if seconds, err := strconv.ParseInt(date, 10, 64); err == nil {
+			remoteBranch.LastSnapshot = time.Unix(seconds, 0)
+		}
+		branches = append(branches, remoteBranch)
+	}
+	return branches, nil
+}
+
+// snapshotBranchRef returns the ref list --branch walks: a shadow branch, or
+// else a branch fetched by pull, named <remote>/<machine>/<branch>
+func (g *GitManager) snapshotBranchRef(name string) string {
+	if strings.Contains(name, "/") {
+		if _, err := g.RunCommand("rev-parse", "--verify", "--quiet", "refs/heads/"+name); err != nil {
+			if _, err := g.RunCommand("rev-parse", "--verify", "--quiet", "refs/remotes/"+name); err == nil {
+				return "refs/remotes/" + name
+			}
+		}
+	}
+	return "refs/heads/" + name
+}
+
+// pushInterval returns remote.push_interval, 0 when pushing from the
+// watcher is off
+func (g *GitManager) pushInterval() time.Duration {
+	if g.State.Config == nil {
+		return 0
+	}
+	return g.State.Config.Remote.PushInterval
+}
+
+// pushLoop pushes snapshots to remote.name every interval. Failures, such as
+// being offline, are reported and retried at the next interval.
+func (w *Watcher) pushLoop(interval time.Duration) {
+	defer w.wg.Done()
+
+	for {
+		select {
+		case <-w.stopChan:
+			return
+		case <-time.After(interval):
+		}
+		w.pushSnapshots()
+	}
+}
+
+// pushSnapshots runs one scheduled push and reports what it sent
+func (w *Watcher) pushSnapshots() {
+	timestamp := time.Now().Format("2006-01-02 15:04:05")
+	remote, _ := w.gitManager.remoteSettings()
+	results, err := w.gitManager.PushSnapshots(remote)
+	if err != nil {
+		color.Yellow("⚠️  %s Push to %s failed: %v", timestamp, remote, err)
+		w.emitError("push", err)
+		return
+	}
+	pushed := 0
+	for _, result := range results {
+		if result.Status != PushUpToDate {
+			pushed++
+		}
+	}
+	if pushed > 0 {
+		fmt.Printf("☁️  %s Pushed %d branch(es) to %s\n", timestamp, pushed, remote)
+	}
+}
@@ -0,0 +1,97 @@
+package core
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/config"
+)
+
+func TestPushAndPullSnapshots(t *testing.T) {
+	tempDir, state, gitManager := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+	state.Config = &config.Config{Remote: config.RemoteConfig{Machine: "laptop"}}
+
+	bare := filepath.Join(t.TempDir(), "backup.git")
+	if output, err := exec.Command("git", "init", "--quiet", "--bare", bare).CombinedOutput(); err != nil {
+		t.Fatalf("git init --bare failed: %v\n%s", err, output)
+	}
+
+	if _, err := gitManager.PushSnapshots(""); ExitCode(err) != ExitNotFound {
+		t.Errorf("Expected pushing to a missing remote to be not found, got %v", err)
+	}
+	if err := gitManager.AddRemote("", bare); err != nil {
+		t.Fatalf("AddRemote failed: %v", err)
+	}
+	if err := gitManager.AddRemote("", bare); ExitCode(err) != ExitValidation {
+		t.Errorf("Expected adding a remote twice to fail, got %v", err)
+	}
+	if err := gitManager.AddRemote("-bad", bare); ExitCode(err) != ExitValidation {
+		t.Errorf("Expected an invalid remote name to fail, got %v", err)
+	}
+	if remotes, _ := gitManager.Remotes(); len(remotes) != 1 || remotes[0].Name != DefaultRemote || remotes[0].URL != bare {
+		t.Errorf("Unexpected remotes %+v", remotes)
+	}
+
+	branch, _ := gitManager.RunCommand("symbolic-ref", "--short", "HEAD")
+	push := func(want string) {
+		t.Helper()
+		results, err := gitManager.PushSnapshots("")
+		if err != nil {
+			t.Fatalf("PushSnapshots failed: %v", err)
+		}
+		if len(results) != 1 || results[0].Ref != "laptop/"+branch || results[0].Status != want {
+			t.Fatalf("Expected %s, got %+v", want, results)
+		}
+	}
+
+	snapshotFile(t, tempDir, gitManager, "v1")
+	push(PushCreated)
+	push(PushUpToDate)
+	snapshotFile(t, tempDir, gitManager, "v2")
+	push(PushUpdated)
+
+	// Rewriting local history never drops what the remote has
+	pushed, _ := gitManager.RunCommand("rev-parse", "HEAD")
+	gitManager.RunCommand("reset", "--quiet", "--soft", "HEAD~1")
+	gitManager.RunCommand("commit", "--quiet", "-m", "rewritten")
+	push(PushMerged)
+	remoteTip, _ := exec.Command("git", "--git-dir="+bare, "rev-parse", "laptop/"+branch).Output()
+	if !gitManager.isAncestor(pushed, string(remoteTip[:len(remoteTip)-1])) {
+		t.Error("Expected the remote to keep the snapshots pushed before the rewrite")
+	}
+	if head, _ := gitManager.RunCommand("log", "-1", "--format=%s"); head != "rewritten" {
+		t.Errorf("Expected the local branch to be left alone, got %q", head)
+	}
+
+	// Another machine pulls them
+	otherDir, otherState, other := setupTestRepo(t)
+	defer os.RemoveAll(otherDir)
+	otherState.Config = &config.Config{}
+	if err := other.AddRemote("backup", bare); err != nil {
+		t.Fatalf("AddRemote failed: %v", err)
+	}
+	branches, err := other.PullSnapshots("backup")
+	if err != nil {
+		t.Fatalf("PullSnapshots failed: %v", err)
+	}
+	if len(branches) != 1 || branches[0].Ref != "backup/laptop/"+branch || branches[0].Machine != "laptop" || branches[0].Snapshots < 4 {
+		t.Fatalf("Unexpected pulled branches %+v", branches)
+	}
+	snapshots, err := other.FilterSnapshots(SnapshotFilter{Branch: branches[0].Ref})
+	if err != nil {
+		t.Fatalf("FilterSnapshots failed: %v", err)
+	}
+	if len(snapshots) != branches[0].Snapshots {
+		t.Errorf("Expected %d snapshots on %s, got %d", branches[0].Snapshots, branches[0].Ref, len(snapshots))
+	}
+
+	if err := other.RemoveRemote("backup"); err != nil {
+		t.Fatalf("RemoveRemote failed: %v", err)
+	}
+	if branches, _ := other.RemoteBranches("backup"); len(branches) != 0 {
+		t.Errorf("Expected the pulled branches to go with the remote, got %+v", branches)
+	}
+}
@@ -0,0 +1,215 @@
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RestoreBackupsDir holds, in the shadow repository, a reverse patch of
+// every restore: applying it brings back what the restore replaced
+const RestoreBackupsDir = "backups"
+
+// restoreBackupTimeFormat is the timestamp in a backup's ID
+const restoreBackupTimeFormat = "20060102-150405"
+
+// restoreBackupID matches the IDs of restore backups, "restore-<timestamp>"
+// with a counter for restores within the same second
+var restoreBackupID = regexp.MustCompile(`^restore-[0-9]{8}-[0-9]{6}(-[0-9]+)?$`)
+
+// Header lines of a backup patch; git apply skips text before the first
+// "diff --git"
+const (
+	backupHeaderSource = "# Restored snapshot: "
+	backupHeaderBackup = "# Pre-restore snapshot: "
+	backupHeaderTime   = "# Time: "
+	backupHeaderFiles  = "# Files: "
+)
+
+// RestoreBackup describes the reverse patch of one restore
+type RestoreBackup struct {
+	ID     string    `json:"id"`
+	Path   string    `json:"path"`
+	Time   time.Time `json:"time"`
+	Source string    `json:"source"` // Snapshot that was restored
+	Backup string    `json:"backup"` // Pre-restore snapshot
+	Files  int       `json:"files"`  // Files the restore replaced
+}
+
+func (g *GitManager) restoreBackupsDir() string {
+	return filepath.Join(g.State.ShadowRepoDir, RestoreBackupsDir)
+}
+
+// saveRestoreBackup writes the reverse patch of a restore of paths from
+// source, taken after the pre-restore snapshot backup. With from set it is
+// the diff from that snapshot to backup, so it can be written before any
+// file changes; with from empty, the diff from the working tree to backup,
+// after the restore. Returns the backup's ID, or "" when nothing differs.
+func (g *GitManager) saveRestoreBackup(source, backup, from string, paths []string) (string, error) {
+	args := []string{"diff", "--binary", "--full-index", "--no-color", "--no-ext-diff", "--no-renames",
+		"--src-prefix=a/", "--dst-prefix=b/"}
+	if from != "" {
+		args = append(args, from, backup)
+	} else {
+		args = append(args, "-R", backup)
+	}
+	args = append(append(args, "--"), topPathspecs(paths)...)
+	diff, err := g.runCommandRaw(args...)
+	if err != nil {
+		return "", fmt.Errorf("failed to save the restore backup: %w", err)
+	}
+	if len(diff) == 0 {
+		return "", nil
+	}
+
+	if err := os.MkdirAll(g.restoreBackupsDir(), 0755); err != nil {
+		return "", fmt.Errorf("failed to save the restore backup: %w", err)
+	}
+	now := time.Now()
+	var header bytes.Buffer
+	fmt.Fprintf(&header, "# Time Machine restore backup: apply with 'timemachine restore --apply-backup'\n")
+	fmt.Fprintf(&header, "%s%s\n%s%s\n%s%s\n%s%d\n\n",
+		backupHeaderSource, source, backupHeaderBackup, backup,
+		backupHeaderTime, now.Format(time.RFC3339), backupHeaderFiles, len(paths))
+
+	base := "restore-" + now.Format(restoreBackupTimeFormat)
+	for n := 1; ; n++ {
+		id := base
+		if n > 1 {
+			id = fmt.Sprintf("%s-%d", base, n)
+		}
+		file, err := os.OpenFile(filepath.Join(g.restoreBackupsDir(), id+".patch"), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if os.IsExist(err) {
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to save the restore backup: %w", err)
+		}
+		_, err = file.Write(append(header.Bytes(), diff...))
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(file.Name())
+			return "", fmt.Errorf("failed to save the restore backup: %w", err)
+		}
+		return id, nil
+	}
+}
+
+// ListRestoreBackups returns the saved restore backups, newest first
+func (g *GitManager) ListRestoreBackups() ([]RestoreBackup, error) {
+	entries, err := os.ReadDir(g.restoreBackupsDir())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list restore backups: %w", err)
+	}
+
+	var backups []RestoreBackup
+	for _, entry := range entries {
+		id, ok := strings.CutSuffix(entry.Name(), ".patch")
+		if !ok || !restoreBackupID.MatchString(id) {
+			continue
+		}
+		backup, err := g.readRestoreBackup(id)
+		if err != nil {
+			return nil, err
+		}
+		backups = append(backups, *backup)
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		if !backups[i].Time.Equal(backups[j].Time) {
+			return backups[i].Time.After(backups[j].Time)
+		}
+		return backups[i].ID > backups[j].ID
+	})
+	return backups, nil
+}
+
+// readRestoreBackup reads the header of a backup patch
+func (g *GitManager) readRestoreBackup(id string) (*RestoreBackup, error) {
+	backup := &RestoreBackup{ID: id, Path: filepath.Join(g.restoreBackupsDir(), id+".patch")}
+	file, err := os.Open(backup.Path)
+	if os.IsNotExist(err) {
+		return nil, NewNotFoundError("restore backup %s not found", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read restore backup %s: %w", id, err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "#") {
+			break
+		}
+		if value, ok := strings.CutPrefix(line, backupHeaderSource); ok {
+			backup.Source = value
+		} else if value, ok := strings.CutPrefix(line, backupHeaderBackup); ok {
+			backup.Backup = value
+		} else if value, ok := strings.CutPrefix(line, backupHeaderTime); ok {
+			backup.Time, _ = time.Parse(time.RFC3339, value)
+		} else if value, ok := strings.CutPrefix(line, backupHeaderFiles); ok {
+			backup.Files, _ = strconv.Atoi(value)
+		}
+	}
+	if backup.Time.IsZero() {
+		// Written by hand or damaged; the ID still tells when
+		backup.Time, _ = time.ParseInLocation(restoreBackupTimeFormat, strings.TrimPrefix(id, "restore-")[:15], time.Local)
+	}
+	return backup, scanner.Err()
+}
+
+// ApplyRestoreBackup applies the reverse patch of a restore to the working
+// tree, bringing back what the restore replaced. A pre-restore snapshot is
+// taken first, so applying can itself be undone. Files changed since the
+// restore make the patch fail to apply, and nothing is changed.
+func (g *GitManager) ApplyRestoreBackup(id string) (*RestoreBackup, string, error) {
+	id = strings.TrimSuffix(id, ".patch")
+	if !restoreBackupID.MatchString(id) {
+		return nil, "", NewValidationError("invalid restore backup %q: expected an ID such as restore-20060102-150405", id)
+	}
+	backup, err := g.readRestoreBackup(id)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if _, err := g.runApply("--check", backup.Path); err != nil {
+		return backup, "", fmt.Errorf("restore backup %s no longer applies, files changed since the restore: %w", id, err)
+	}
+	if err := g.CreateSnapshot("Pre-restore backup before applying " + id); err != nil {
+		return backup, "", fmt.Errorf("failed to create pre-restore snapshot: %w", err)
+	}
+	snapshot, err := g.RunCommand("rev-parse", "HEAD")
+	if err != nil {
+		return backup, "", fmt.Errorf("failed to resolve pre-restore snapshot: %w", err)
+	}
+	if _, err := g.runApply(backup.Path); err != nil {
+		return backup, snapshot, fmt.Errorf("failed to apply restore backup %s: %w", id, err)
+	}
+	return backup, snapshot, nil
+}
+
+// runApply runs git apply on the working tree. It runs from the project
+// root, since git apply only patches paths below the current directory.
+func (g *GitManager) runApply(args ...string) (string, error) {
+	args = append([]string{"apply", "--binary", "--whitespace=nowarn"}, args...)
+	cmd := exec.Command("git", append([]string{"--git-dir=" + g.State.ShadowRepoDir, "--work-tree=" + g.State.ProjectRoot}, args...)...)
+	cmd.Dir = g.State.ProjectRoot
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", &GitError{Args: args, Output: string(output), Err: err}
+	}
+	return string(output), nil
+}
@@ -0,0 +1,85 @@
+package core
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRestoreBackup(t *testing.T) {
+	tempDir, gitManager, source := setupRestoreScenario(t)
+	defer os.RemoveAll(tempDir)
+
+	journal, err := gitManager.JournaledRestore(context.Background(), source, nil, 2, nil)
+	if err != nil {
+		t.Fatalf("JournaledRestore failed: %v", err)
+	}
+	if journal.BackupPatch == "" {
+		t.Fatal("Expected the restore to save a reverse patch")
+	}
+
+	backups, err := gitManager.ListRestoreBackups()
+	if err != nil {
+		t.Fatalf("ListRestoreBackups failed: %v", err)
+	}
+	if len(backups) != 1 || backups[0].ID != journal.BackupPatch {
+		t.Fatalf("Expected backup %s, got %+v", journal.BackupPatch, backups)
+	}
+	backup := backups[0]
+	if backup.Source != source || backup.Backup != journal.Backup || backup.Files != 3 || backup.Time.IsZero() {
+		t.Errorf("Unexpected backup header: %+v", backup)
+	}
+
+	// Applying it brings back what the restore replaced, after a snapshot
+	applied, snapshot, err := gitManager.ApplyRestoreBackup(backup.ID + ".patch")
+	if err != nil {
+		t.Fatalf("ApplyRestoreBackup failed: %v", err)
+	}
+	if applied.ID != backup.ID || snapshot == "" {
+		t.Errorf("Unexpected result %+v, %q", applied, snapshot)
+	}
+	for name, want := range map[string]string{"a.txt": "edited\n", "b.txt": "edited\n", "c.txt": "<missing>"} {
+		if got := readContent(t, filepath.Join(tempDir, name)); got != want {
+			t.Errorf("Expected %s to be %q again, got %q", name, want, got)
+		}
+	}
+	if content, _ := gitManager.RunCommand("show", snapshot+":a.txt"); content != "original" {
+		t.Errorf("Expected the pre-apply snapshot to keep the restored content, got %q", content)
+	}
+
+	// Applied twice, the patch no longer fits and nothing changes
+	if _, _, err := gitManager.ApplyRestoreBackup(backup.ID); err == nil || !strings.Contains(err.Error(), "no longer applies") {
+		t.Errorf("Expected a stale backup to be refused, got %v", err)
+	}
+
+	if _, _, err := gitManager.ApplyRestoreBackup("../../etc/passwd"); ExitCode(err) != ExitValidation {
+		t.Errorf("Expected a validation error for a bad ID, got %v", err)
+	}
+	if _, _, err := gitManager.ApplyRestoreBackup("restore-20000101-000000"); ExitCode(err) != ExitNotFound {
+		t.Errorf("Expected a missing backup to be not found, got %v", err)
+	}
+}
+
+func TestRestoreBackupNothingReplaced(t *testing.T) {
+	tempDir, _, gitManager := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	os.WriteFile(filepath.Join(tempDir, "a.txt"), []byte("same\n"), 0644)
+	if err := gitManager.CreateSnapshot("same"); err != nil {
+		t.Fatal(err)
+	}
+	head, _ := gitManager.RunCommand("rev-parse", "HEAD")
+
+	journal, err := gitManager.JournaledRestore(context.Background(), head, nil, 0, nil)
+	if err != nil {
+		t.Fatalf("JournaledRestore failed: %v", err)
+	}
+	if journal.BackupPatch != "" {
+		t.Errorf("Expected no reverse patch when nothing is replaced, got %s", journal.BackupPatch)
+	}
+	if backups, _ := gitManager.ListRestoreBackups(); len(backups) != 0 {
+		t.Errorf("Expected no backups, got %+v", backups)
+	}
+}
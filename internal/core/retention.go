@@ -0,0 +1,317 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/config"
+)
+
+// recentWindow is how far back the keep_hourly/daily/weekly rules leave
+// snapshots alone, so the most recent undo history stays complete
+const recentWindow = time.Hour
+
+// RetentionPolicy decides which snapshots survive a pruning pass. Zero
+// values disable a rule; the newest snapshot is always kept.
+type RetentionPolicy struct {
+	MaxAge       time.Duration // Remove snapshots older than this
+	MaxTotalSize int64         // Remove the oldest snapshots while their stored size exceeds this (bytes)
+	KeepHourly   int           // Beyond recentWindow, keep the newest snapshot of each of the last N hours
+	KeepDaily    int           // ... of each of the last N days
+	KeepWeekly   int           // ... of each of the last N weeks
+}
+
+// NewRetentionPolicy builds a policy from the retention configuration
+func NewRetentionPolicy(cfg config.RetentionConfig) (RetentionPolicy, error) {
+	policy := RetentionPolicy{
+		MaxTotalSize: int64(cfg.MaxTotalSizeMB) * 1024 * 1024,
+		KeepHourly:   cfg.KeepHourly,
+		KeepDaily:    cfg.KeepDaily,
+		KeepWeekly:   cfg.KeepWeekly,
+	}
+	if cfg.MaxAge != "" {
+		age, err := ParseAge(cfg.MaxAge)
+		if err != nil {
+			return RetentionPolicy{}, fmt.Errorf("invalid retention.max_age: %w", err)
+		}
+		policy.MaxAge = age
+	}
+	return policy, nil
+}
+
+// RetentionPolicy returns the configured retention policy; without
+// configuration every rule is disabled
+func (g *GitManager) RetentionPolicy() (RetentionPolicy, error) {
+	if g.State.Config == nil {
+		return RetentionPolicy{}, nil
+	}
+	return NewRetentionPolicy(g.State.Config.Retention)
+}
+
+// Enabled reports whether any rule is set
+func (p RetentionPolicy) Enabled() bool {
+	return p.MaxAge > 0 || p.MaxTotalSize > 0 || p.thins()
+}
+
+// thins reports whether the keep_hourly/daily/weekly rules apply
+func (p RetentionPolicy) thins() bool {
+	return p.KeepHourly > 0 || p.KeepDaily > 0 || p.KeepWeekly > 0
+}
+
+// String describes the policy, e.g. "max age 720h0m0s, hourly for 24h"
+func (p RetentionPolicy) String() string {
+	var rules []string
+	if p.MaxAge > 0 {
+		rules = append(rules, "max age "+p.MaxAge.String())
+	}
+	if p.MaxTotalSize > 0 {
+		rules = append(rules, "max size "+strconv.FormatInt(p.MaxTotalSize/(1024*1024), 10)+" MB")
+	}
+	if p.KeepHourly > 0 {
+		rules = append(rules, fmt.Sprintf("hourly for %dh", p.KeepHourly))
+	}
+	if p.KeepDaily > 0 {
+		rules = append(rules, fmt.Sprintf("daily for %dd", p.KeepDaily))
+	}
+	if p.KeepWeekly > 0 {
+		rules = append(rules, fmt.Sprintf("weekly for %dw", p.KeepWeekly))
+	}
+	if len(rules) == 0 {
+		return "keep everything"
+	}
+	return strings.Join(rules, ", ")
+}
+
+// RetentionPlan splits snapshots, newest first, into those a policy keeps
+// and those it removes
+type RetentionPlan struct {
+	Keep   []Snapshot
+	Remove []Snapshot
+}
+
+// PlanRetention applies a policy to the current snapshots
+func (g *GitManager) PlanRetention(policy RetentionPolicy, now time.Time) (*RetentionPlan, error) {
+	snapshots, err := g.ListSnapshots(0, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+
+	plan := &RetentionPlan{}
+	keep := policy.selectSnapshots(snapshots, now)
+	for i, snapshot := range snapshots {
+		if keep[i] {
+			plan.Keep = append(plan.Keep, snapshot)
+		} else {
+			plan.Remove = append(plan.Remove, snapshot)
+		}
+	}
+
+	if policy.MaxTotalSize > 0 && len(plan.Keep) > 1 {
+		drop, err := g.oldestOverSize(plan.Keep, policy.MaxTotalSize)
+		if err != nil {
+			return nil, err
+		}
+		cut := len(plan.Keep) - drop
+		plan.Remove = append(plan.Remove, plan.Keep[cut:]...)
+		plan.Keep = plan.Keep[:cut]
+		sort.SliceStable(plan.Remove, func(i, j int) bool {
+			return plan.Remove[i].Timestamp.After(plan.Remove[j].Timestamp)
+		})
+	}
+
+	return plan, nil
+}
+
+// selectSnapshots applies the age and thinning rules to snapshots (newest
+// first) and reports which to keep
+func (p RetentionPolicy) selectSnapshots(snapshots []Snapshot, now time.Time) []bool {
+	keep := make([]bool, len(snapshots))
+	hours := make(map[string]bool)
+	days := make(map[string]bool)
+	weeks := make(map[string]bool)
+
+	// Newest first, so the first snapshot to claim a period is its latest
+	claim := func(periods map[string]bool, key string) bool {
+		if periods[key] {
+			return false
+		}
+		periods[key] = true
+		return true
+	}
+
+	for i, snapshot := range snapshots {
+		age := now.Sub(snapshot.Timestamp)
+		local := snapshot.Timestamp.Local()
+		year, week := local.ISOWeek()
+
+		kept := !p.thins() || age < recentWindow
+		if p.KeepHourly > 0 && age < time.Duration(p.KeepHourly)*time.Hour && claim(hours, local.Format("2006-01-02T15")) {
+			kept = true
+		}
+		if p.KeepDaily > 0 && age < time.Duration(p.KeepDaily)*24*time.Hour && claim(days, local.Format("2006-01-02")) {
+			kept = true
+		}
+		if p.KeepWeekly > 0 && age < time.Duration(p.KeepWeekly)*7*24*time.Hour && claim(weeks, fmt.Sprintf("%d-%d", year, week)) {
+			kept = true
+		}
+		if p.MaxAge > 0 && age > p.MaxAge {
+			kept = false
+		}
+
+		keep[i] = kept || i == 0
+	}
+
+	return keep
+}
+
+// oldestOverSize returns how many of the oldest snapshots (newest first
+// list) must go for the rest to fit in limit bytes of stored objects,
+// always leaving the newest
+func (g *GitManager) oldestOverSize(snapshots []Snapshot, limit int64) (int, error) {
+	size := func(keep int) (int64, error) {
+		hashes := make([]string, keep)
+		for i, snapshot := range snapshots[:keep] {
+			hashes[i] = snapshot.Hash
+		}
+		return g.diskUsage(hashes)
+	}
+
+	// Size only shrinks as snapshots are dropped: find the fewest drops
+	// that fit by binary search
+	lo, hi := 0, len(snapshots)-1
+	for lo < hi {
+		mid := (lo + hi) / 2
+		total, err := size(len(snapshots) - mid)
+		if err != nil {
+			return 0, err
+		}
+		if total <= limit {
+			hi = mid
+		} else {
+			lo = mid + 1
+		}
+	}
+	return lo, nil
+}
+
+// diskUsage returns the on-disk size of the objects the given snapshots use
+// on their own (their trees, not their history)
+func (g *GitManager) diskUsage(hashes []string) (int64, error) {
+	if g.State.Capabilities().GitDiskUsage {
+		output, err := g.RunCommand(append([]string{"rev-list", "--objects", "--no-walk", "--disk-usage"}, hashes...)...)
+		if err != nil {
+			return 0, fmt.Errorf("failed to measure snapshot size: %w", err)
+		}
+		return strconv.ParseInt(output, 10, 64)
+	}
+
+	// Older git: list the objects and ask for each one's size
+	output, err := g.RunCommand(append([]string{"rev-list", "--objects", "--no-walk"}, hashes...)...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to measure snapshot size: %w", err)
+	}
+	var objects strings.Builder
+	for _, line := range strings.Split(output, "\n") {
+		if fields := strings.Fields(line); len(fields) > 0 {
+			objects.WriteString(fields[0] + "\n")
+		}
+	}
+
+	sizes, err := g.runCommandInput(objects.String(), "cat-file", "--batch-check=%(objectsize:disk)")
+	if err != nil {
+		return 0, fmt.Errorf("failed to measure snapshot size: %w", err)
+	}
+	var total int64
+	for _, field := range strings.Fields(string(sizes)) {
+		size, err := strconv.ParseInt(field, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("unexpected object size %q", field)
+		}
+		total += size
+	}
+	return total, nil
+}
+
+// RemoveSnapshots rewrites the shadow history without the given snapshots
+// and garbage-collects them. Snapshots newer than the oldest removed one get
+// new hashes; the returned map translates old hashes to new ones. Snapshots
+// taken after the removal list was computed are kept.
+func (g *GitManager) RemoveSnapshots(remove []string) (map[string]string, error) {
+	if len(remove) == 0 {
+		return map[string]string{}, nil
+	}
+
+	branch, err := g.RunCommand("symbolic-ref", "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine shadow branch: %w", err)
+	}
+	oldHead, err := g.RunCommand("rev-parse", "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+
+	output, err := g.RunCommand("rev-list", "--reverse", "--first-parent", oldHead)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+	history := strings.Fields(output)
+
+	removed := make(map[string]bool, len(remove))
+	for _, hash := range remove {
+		removed[hash] = true
+	}
+	if removed[oldHead] {
+		return nil, fmt.Errorf("refusing to remove the latest snapshot")
+	}
+
+	// Snapshots before the first removed one keep their hashes
+	parent := ""
+	i := 0
+	for ; i < len(history) && !removed[history[i]]; i++ {
+		parent = history[i]
+	}
+	var retained []string
+	for _, commit := range history[i:] {
+		if !removed[commit] {
+			retained = append(retained, commit)
+		}
+	}
+	if i == len(history) {
+		return map[string]string{}, nil
+	}
+
+	newHead, mapping, err := g.rewriteCommits(retained, parent)
+	if err != nil {
+		return nil, err
+	}
+
+	// Compare-and-swap so a snapshot created concurrently is never lost
+	if _, err := g.RunCommand("update-ref", "-m", "timemachine: apply retention policy", branch, newHead, oldHead); err != nil {
+		return nil, fmt.Errorf("failed to update shadow branch (was a snapshot created during cleanup?): %w", err)
+	}
+
+	if err := g.reclaimSpace(); err != nil {
+		return mapping, err
+	}
+	return mapping, nil
+}
+
+// ApplyRetention plans and applies a policy, returning the plan
+func (g *GitManager) ApplyRetention(policy RetentionPolicy, now time.Time) (*RetentionPlan, error) {
+	plan, err := g.PlanRetention(policy, now)
+	if err != nil {
+		return nil, err
+	}
+
+	hashes := make([]string, len(plan.Remove))
+	for i, snapshot := range plan.Remove {
+		hashes[i] = snapshot.Hash
+	}
+	if _, err := g.RemoveSnapshots(hashes); err != nil {
+		return plan, err
+	}
+	return plan, nil
+}
@@ -0,0 +1,188 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/config"
+)
+
+// snapshotsEvery returns count snapshots, newest first, spaced step apart
+// going back from now
+func snapshotsEvery(now time.Time, count int, step time.Duration) []Snapshot {
+	snapshots := make([]Snapshot, count)
+	for i := range snapshots {
+		snapshots[i] = Snapshot{Hash: fmt.Sprintf("%040d", i), Timestamp: now.Add(-time.Duration(i) * step)}
+	}
+	return snapshots
+}
+
+func countKept(keep []bool) int {
+	kept := 0
+	for _, k := range keep {
+		if k {
+			kept++
+		}
+	}
+	return kept
+}
+
+func TestRetentionPolicySelectSnapshots(t *testing.T) {
+	now := time.Date(2026, 3, 11, 12, 0, 0, 0, time.Local)
+	// Every 10 minutes for three days: 432 snapshots
+	snapshots := snapshotsEvery(now, 3*24*6, 10*time.Minute)
+
+	tests := []struct {
+		name     string
+		policy   RetentionPolicy
+		expected int
+	}{
+		{"no rules keeps everything", RetentionPolicy{}, 432},
+		// 6 in the last hour, then one per hour for the next 23 hours
+		{"hourly", RetentionPolicy{KeepHourly: 24}, 6 + 23},
+		// The last hour, plus one per earlier day (today's is already kept)
+		{"daily", RetentionPolicy{KeepDaily: 7}, 6 + 3},
+		{"max age", RetentionPolicy{MaxAge: 24 * time.Hour}, 6*24 + 1},
+		// Only yesterday's newest snapshot is younger than a day
+		{"max age overrides keep rules", RetentionPolicy{MaxAge: 24 * time.Hour, KeepDaily: 7}, 6 + 1},
+		{"newest always kept", RetentionPolicy{MaxAge: time.Minute}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keep := tt.policy.selectSnapshots(snapshots, now)
+			if got := countKept(keep); got != tt.expected {
+				t.Errorf("Expected %d snapshots kept, got %d", tt.expected, got)
+			}
+			if !keep[0] {
+				t.Errorf("Expected the newest snapshot to be kept")
+			}
+		})
+	}
+}
+
+func TestRetentionPolicyKeepsNewestOfEachPeriod(t *testing.T) {
+	now := time.Date(2026, 3, 11, 12, 0, 0, 0, time.Local)
+	snapshots := snapshotsEvery(now, 3*24*6, 10*time.Minute)
+
+	keep := RetentionPolicy{KeepDaily: 7}.selectSnapshots(snapshots, now)
+	for i, snapshot := range snapshots {
+		if !keep[i] || now.Sub(snapshot.Timestamp) < recentWindow {
+			continue
+		}
+		// The snapshot kept for a day must be the latest of that day
+		if i > 0 && snapshots[i-1].Timestamp.Day() == snapshot.Timestamp.Day() {
+			t.Errorf("Expected only the newest snapshot of %s to be kept, got one at %s",
+				snapshot.Timestamp.Format("2006-01-02"), snapshot.Timestamp.Format("15:04"))
+		}
+	}
+}
+
+func TestNewRetentionPolicy(t *testing.T) {
+	policy, err := NewRetentionPolicy(config.RetentionConfig{MaxAge: "30d", MaxTotalSizeMB: 512, KeepHourly: 24})
+	if err != nil {
+		t.Fatalf("NewRetentionPolicy failed: %v", err)
+	}
+	if policy.MaxAge != 30*24*time.Hour || policy.MaxTotalSize != 512*1024*1024 || policy.KeepHourly != 24 {
+		t.Errorf("Unexpected policy %+v", policy)
+	}
+	if !policy.Enabled() {
+		t.Errorf("Expected policy to be enabled")
+	}
+
+	if _, err := NewRetentionPolicy(config.RetentionConfig{MaxAge: "soon"}); err == nil {
+		t.Errorf("Expected an invalid max_age to be rejected")
+	}
+	if policy, _ := NewRetentionPolicy(config.RetentionConfig{}); policy.Enabled() {
+		t.Errorf("Expected an empty configuration to disable retention")
+	}
+}
+
+func TestApplyRetention(t *testing.T) {
+	tempDir, _, gitManager := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	now := time.Now()
+	// Two snapshots on each of the last four days
+	for day := 3; day >= 0; day-- {
+		for _, hour := range []int{10, 2} {
+			date := now.Add(-time.Duration(day)*24*time.Hour - time.Duration(hour)*time.Hour)
+			snapshotFileAt(t, tempDir, gitManager, fmt.Sprintf("day %d hour %d", day, hour), date)
+		}
+	}
+	snapshotFile(t, tempDir, gitManager, "latest")
+
+	plan, err := gitManager.ApplyRetention(RetentionPolicy{MaxAge: 3 * 24 * time.Hour, KeepDaily: 7}, now)
+	if err != nil {
+		t.Fatalf("ApplyRetention failed: %v", err)
+	}
+	if len(plan.Remove) == 0 {
+		t.Fatalf("Expected some snapshots to be removed")
+	}
+
+	snapshots, err := gitManager.ListSnapshots(0, "")
+	if err != nil {
+		t.Fatalf("ListSnapshots failed: %v", err)
+	}
+	if len(snapshots) != len(plan.Keep) {
+		t.Errorf("Expected %d snapshots after retention, got %d", len(plan.Keep), len(snapshots))
+	}
+	if snapshots[0].Message != "latest" {
+		t.Errorf("Expected the latest snapshot to survive, got %q", snapshots[0].Message)
+	}
+	for _, snapshot := range snapshots {
+		if strings.HasPrefix(snapshot.Message, "day 3") {
+			t.Errorf("Expected snapshots past max_age to be removed, found %q", snapshot.Message)
+		}
+	}
+
+	// The kept snapshots still have their content
+	content, err := gitManager.RunCommand("show", snapshots[len(snapshots)-1].Hash+":file.txt")
+	if err != nil || content != snapshots[len(snapshots)-1].Message {
+		t.Errorf("Expected rewritten snapshot to keep its content, got %q (%v)", content, err)
+	}
+}
+
+func TestPlanRetentionMaxTotalSize(t *testing.T) {
+	tempDir, _, gitManager := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	for i := 0; i < 4; i++ {
+		// Incompressible enough that each snapshot adds real size
+		var content strings.Builder
+		for j := 0; j < 2000; j++ {
+			fmt.Fprintf(&content, "%d-%d-%x\n", i, j, j*7919+i)
+		}
+		snapshotFile(t, tempDir, gitManager, content.String())
+	}
+
+	plan, err := gitManager.PlanRetention(RetentionPolicy{MaxTotalSize: 1}, time.Now())
+	if err != nil {
+		t.Fatalf("PlanRetention failed: %v", err)
+	}
+	if len(plan.Keep) != 1 || len(plan.Remove) != 3 {
+		t.Errorf("Expected only the newest snapshot to fit, got %d kept and %d removed", len(plan.Keep), len(plan.Remove))
+	}
+
+	plan, err = gitManager.PlanRetention(RetentionPolicy{MaxTotalSize: 1 << 30}, time.Now())
+	if err != nil {
+		t.Fatalf("PlanRetention failed: %v", err)
+	}
+	if len(plan.Remove) != 0 {
+		t.Errorf("Expected everything to fit, got %d removed", len(plan.Remove))
+	}
+}
+
+func TestRemoveSnapshotsRefusesLatest(t *testing.T) {
+	tempDir, _, gitManager := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	snapshotFile(t, tempDir, gitManager, "only")
+	head, _ := gitManager.RunCommand("rev-parse", "HEAD")
+	if _, err := gitManager.RemoveSnapshots([]string{head}); err == nil {
+		t.Errorf("Expected removing the latest snapshot to fail")
+	}
+}
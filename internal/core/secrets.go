@@ -0,0 +1,282 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// Secret scanning modes (secrets.mode)
+const (
+	SecretsWarn  = "warn"  // Report secrets in changed lines, snapshot anyway
+	SecretsBlock = "block" // Skip snapshots that would store a secret
+	SecretsOff   = "off"
+)
+
+// maxSecretFindings bounds the findings collected for one snapshot
+const maxSecretFindings = 50
+
+// SecretRule detects one kind of secret. When the pattern has a named group
+// "secret", only that group is redacted so the surrounding context survives.
+type SecretRule struct {
+	Name    string
+	Pattern *regexp.Regexp
+}
+
+// BuiltinSecretRules are the detectors that are always active
+var BuiltinSecretRules = []SecretRule{
+	{Name: "private-key", Pattern: regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----(?:[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----)?`)},
+	{Name: "aws-access-key", Pattern: regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{Name: "aws-secret-key", Pattern: regexp.MustCompile(`(?i)aws_?secret_?access_?key\s*[:=]\s*["']?(?P<secret>[A-Za-z0-9/+=]{40})`)},
+	{Name: "github-token", Pattern: regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36,}\b`)},
+	{Name: "slack-token", Pattern: regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}`)},
+	{Name: "env-secret", Pattern: regexp.MustCompile(`(?i)\b[A-Z0-9_]*(?:SECRET|PASSWORD|PASSWD|TOKEN|API_?KEY)[A-Z0-9_]*\s*[:=]\s*["']?(?P<secret>[^\s"']{8,})`)},
+}
+
+// RedactSecrets replaces secrets found by the given rules with
+// [REDACTED:<rule>] markers. It returns the redacted text and the sorted,
+// de-duplicated names of the rules that matched.
+func RedactSecrets(text string, rules []SecretRule) (string, []string) {
+	matched := make(map[string]bool)
+
+	for _, rule := range rules {
+		pattern := rule.Pattern
+		group := pattern.SubexpIndex("secret")
+		marker := "[REDACTED:" + rule.Name + "]"
+
+		text = pattern.ReplaceAllStringFunc(text, func(match string) string {
+			matched[rule.Name] = true
+			if group > 0 {
+				loc := pattern.FindStringSubmatchIndex(match)
+				if loc != nil && loc[2*group] >= 0 {
+					return match[:loc[2*group]] + marker + match[loc[2*group+1]:]
+				}
+			}
+			return marker
+		})
+	}
+
+	names := make([]string, 0, len(matched))
+	for name := range matched {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return text, names
+}
+
+// SecretFinding is a likely secret on a line a snapshot would add
+type SecretFinding struct {
+	Path    string
+	Line    int    // In the new version of the file
+	Rule    string // Name of the detector that matched
+	Preview string // The line with the secret redacted
+}
+
+func (f SecretFinding) String() string {
+	return fmt.Sprintf("%s:%d: %s", f.Path, f.Line, f.Rule)
+}
+
+// SecretsDetectedError reports that a snapshot was skipped because the
+// changes it would store contain likely secrets (secrets.mode: block).
+// Nothing was committed when it is returned.
+type SecretsDetectedError struct {
+	Findings []SecretFinding
+}
+
+func (e *SecretsDetectedError) Error() string {
+	first := e.Findings[0].String()
+	if len(e.Findings) == 1 {
+		return "snapshot blocked: possible secret at " + first
+	}
+	return fmt.Sprintf("snapshot blocked: %d possible secrets, first at %s", len(e.Findings), first)
+}
+
+// IsSecretsDetected reports whether err is (or wraps) a SecretsDetectedError
+func IsSecretsDetected(err error) bool {
+	var detected *SecretsDetectedError
+	return errors.As(err, &detected)
+}
+
+// SecretScanner finds secrets with the built-in detectors plus configured
+// deny patterns, skipping lines that match an allow pattern
+type SecretScanner struct {
+	rules []SecretRule
+	allow []*regexp.Regexp
+}
+
+// NewSecretScanner compiles the allow and deny patterns
+func NewSecretScanner(allow, deny []string) (*SecretScanner, error) {
+	scanner := &SecretScanner{rules: append([]SecretRule(nil), BuiltinSecretRules...)}
+	for _, pattern := range deny {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid secrets.deny pattern %q: %w", pattern, err)
+		}
+		scanner.rules = append(scanner.rules, SecretRule{Name: "deny", Pattern: re})
+	}
+	for _, pattern := range allow {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid secrets.allow pattern %q: %w", pattern, err)
+		}
+		scanner.allow = append(scanner.allow, re)
+	}
+	return scanner, nil
+}
+
+// ScanLine returns the names of the rules that match a line, or nothing
+// when the line is allowed
+func (s *SecretScanner) ScanLine(line string) []string {
+	var matched []string
+	for _, rule := range s.rules {
+		if rule.Pattern.MatchString(line) {
+			matched = append(matched, rule.Name)
+		}
+	}
+	if len(matched) == 0 {
+		return nil
+	}
+	for _, allow := range s.allow {
+		if allow.MatchString(line) {
+			return nil
+		}
+	}
+	return matched
+}
+
+// secretsMode returns the configured scanning mode
+func (g *GitManager) secretsMode() string {
+	if g.State.Config == nil || g.State.Config.Secrets.Mode == "" {
+		return SecretsWarn
+	}
+	return g.State.Config.Secrets.Mode
+}
+
+// secretScanner builds the scanner from the configuration
+func (g *GitManager) secretScanner() (*SecretScanner, error) {
+	if g.State.Config == nil {
+		return NewSecretScanner(nil, nil)
+	}
+	return NewSecretScanner(g.State.Config.Secrets.Allow, g.State.Config.Secrets.Deny)
+}
+
+// ScanStagedSecrets looks for secrets on the lines the staged changes add
+// compared to the last snapshot. Content that was already snapshotted is not
+// scanned again, and neither is the initial snapshot.
+func (g *GitManager) ScanStagedSecrets() ([]SecretFinding, error) {
+	if _, err := g.RunCommand("rev-parse", "--verify", "--quiet", "HEAD"); err != nil {
+		return nil, nil
+	}
+
+	scanner, err := g.secretScanner()
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := g.runCommandRaw("diff", "--cached", "-U0", "--no-color", "--no-ext-diff",
+		"--no-textconv", "--no-renames", "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read staged changes: %w", err)
+	}
+	return scanAddedLines(string(output), scanner), nil
+}
+
+// scanAddedLines scans the added lines of a zero-context unified diff
+func scanAddedLines(diff string, scanner *SecretScanner) []SecretFinding {
+	var findings []SecretFinding
+	path, line := "", 0
+	for _, text := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(text, "+++ "):
+			path = diffPath(strings.TrimPrefix(text, "+++ "))
+		case strings.HasPrefix(text, "@@ "):
+			line = hunkStart(text)
+		case strings.HasPrefix(text, "+") && path != "":
+			added := text[1:]
+			for _, rule := range scanner.ScanLine(added) {
+				preview, _ := RedactSecrets(added, scanner.rules)
+				findings = append(findings, SecretFinding{Path: path, Line: line, Rule: rule, Preview: secretPreview(preview)})
+				if len(findings) == maxSecretFindings {
+					return findings
+				}
+			}
+			line++
+		}
+	}
+	return findings
+}
+
+// secretPreview trims a redacted line for display
+func secretPreview(line string) string {
+	const maxPreview = 80
+	line = strings.TrimSpace(line)
+	if runes := []rune(line); len(runes) > maxPreview {
+		return string(runes[:maxPreview]) + "..."
+	}
+	return line
+}
+
+// diffPath extracts the path from a "+++ b/<path>" header; deleted files
+// (/dev/null) have none
+func diffPath(header string) string {
+	if strings.HasPrefix(header, "\"") {
+		if unquoted, err := strconv.Unquote(header); err == nil {
+			header = unquoted
+		}
+	}
+	path, ok := strings.CutPrefix(header, "b/")
+	if !ok {
+		return ""
+	}
+	return path
+}
+
+// hunkStart returns the first new-file line of a "@@ -a,b +c,d @@" header
+func hunkStart(header string) int {
+	fields := strings.Fields(header)
+	if len(fields) < 3 {
+		return 0
+	}
+	start, _, _ := strings.Cut(strings.TrimPrefix(fields[2], "+"), ",")
+	n, _ := strconv.Atoi(start)
+	return n
+}
+
+// checkStagedSecrets applies secrets.mode to the staged changes: in block
+// mode it returns a SecretsDetectedError, in warn mode it prints the
+// findings and lets the snapshot proceed
+func (g *GitManager) checkStagedSecrets() error {
+	if g.secretsMode() == SecretsOff {
+		return nil
+	}
+
+	findings, err := g.ScanStagedSecrets()
+	if err != nil {
+		return err
+	}
+	if len(findings) == 0 {
+		return nil
+	}
+	if g.secretsMode() == SecretsBlock {
+		return &SecretsDetectedError{Findings: findings}
+	}
+
+	fmt.Println()
+	color.Yellow("⚠️  Possible secrets in this snapshot:")
+	printSecretFindings(findings)
+	fmt.Println("   Remove them and run 'timemachine clean --purge-secret <value>' to erase them from snapshots.")
+	return nil
+}
+
+// printSecretFindings lists findings with their redacted lines
+func printSecretFindings(findings []SecretFinding) {
+	for _, finding := range findings {
+		fmt.Printf("   %s  %s\n", finding, finding.Preview)
+	}
+}
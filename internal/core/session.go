@@ -0,0 +1,82 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Session is a run of snapshots with no more than the session gap between them.
+// Its ID is the short hash of the first snapshot, which stays stable as new
+// snapshots are added to the session.
+type Session struct {
+	ID        string
+	Start     time.Time
+	End       time.Time
+	Snapshots []SnapshotChanges
+}
+
+// ListSessions groups all snapshots into sessions, oldest first
+func (g *GitManager) ListSessions(gap time.Duration) ([]Session, error) {
+	snapshots, err := g.LogChanges("")
+	if err != nil {
+		return nil, err
+	}
+
+	var sessions []Session
+	for _, group := range GroupSessions(snapshots, gap) {
+		sessions = append(sessions, Session{
+			ID:        ShortHash(group[0].Hash),
+			Start:     group[0].Time,
+			End:       group[len(group)-1].Time,
+			Snapshots: group,
+		})
+	}
+
+	return sessions, nil
+}
+
+// FindSession looks up a session by ID (or a prefix of its first snapshot
+// hash). "latest" selects the most recent session.
+func FindSession(sessions []Session, id string) (*Session, error) {
+	if len(sessions) == 0 {
+		return nil, fmt.Errorf("no sessions found")
+	}
+
+	if id == "latest" {
+		return &sessions[len(sessions)-1], nil
+	}
+
+	var found *Session
+	for i := range sessions {
+		if strings.HasPrefix(sessions[i].Snapshots[0].Hash, id) {
+			if found != nil {
+				return nil, fmt.Errorf("session id %q is ambiguous", id)
+			}
+			found = &sessions[i]
+		}
+	}
+
+	if found == nil {
+		return nil, NewNotFoundError("session %q not found", id)
+	}
+
+	return found, nil
+}
+
+// SnapshotPatch returns the unified diff a snapshot introduced
+func (g *GitManager) SnapshotPatch(hash string) (string, error) {
+	output, err := g.RunCommand("show", "--format=", "--no-color", "--no-ext-diff", hash)
+	if err != nil {
+		return "", fmt.Errorf("failed to read snapshot diff: %w", err)
+	}
+	return output, nil
+}
+
+// ShortHash abbreviates a commit hash for display
+func ShortHash(hash string) string {
+	if len(hash) > 8 {
+		return hash[:8]
+	}
+	return hash
+}
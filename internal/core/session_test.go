@@ -0,0 +1,88 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFindSession(t *testing.T) {
+	sessions := []Session{
+		{ID: "aaaa1111", Snapshots: []SnapshotChanges{{Hash: "aaaa1111ffff"}}},
+		{ID: "aaaa2222", Snapshots: []SnapshotChanges{{Hash: "aaaa2222ffff"}}},
+		{ID: "bbbb3333", Snapshots: []SnapshotChanges{{Hash: "bbbb3333ffff"}}},
+	}
+
+	testCases := []struct {
+		id      string
+		want    string
+		wantErr bool
+	}{
+		{id: "latest", want: "bbbb3333"},
+		{id: "aaaa2222", want: "aaaa2222"},
+		{id: "bbbb", want: "bbbb3333"},
+		{id: "aaaa", wantErr: true},
+		{id: "cccc", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		session, err := FindSession(sessions, tc.id)
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("FindSession(%q) expected error, got %s", tc.id, session.ID)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("FindSession(%q) unexpected error: %v", tc.id, err)
+			continue
+		}
+		if session.ID != tc.want {
+			t.Errorf("FindSession(%q) = %s, want %s", tc.id, session.ID, tc.want)
+		}
+	}
+
+	if _, err := FindSession(nil, "latest"); err == nil {
+		t.Error("Expected error when there are no sessions")
+	}
+}
+
+func TestListSessionsAndPatch(t *testing.T) {
+	tempDir, _, gitManager := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	for i, content := range []string{"one\n", "one\ntwo\n"} {
+		if err := os.WriteFile(filepath.Join(tempDir, "notes.txt"), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+		if err := gitManager.CreateSnapshot("snapshot " + string(rune('1'+i))); err != nil {
+			t.Fatalf("Failed to create snapshot: %v", err)
+		}
+	}
+
+	sessions, err := gitManager.ListSessions(time.Hour)
+	if err != nil {
+		t.Fatalf("ListSessions failed: %v", err)
+	}
+	if len(sessions) != 1 {
+		t.Fatalf("Expected 1 session, got %d", len(sessions))
+	}
+
+	session := sessions[0]
+	if len(session.Snapshots) != 2 {
+		t.Errorf("Expected 2 snapshots in session, got %d", len(session.Snapshots))
+	}
+	if !strings.HasPrefix(session.Snapshots[0].Hash, session.ID) {
+		t.Errorf("Expected session ID to prefix first snapshot hash, got %s", session.ID)
+	}
+
+	patch, err := gitManager.SnapshotPatch(session.Snapshots[1].Hash)
+	if err != nil {
+		t.Fatalf("SnapshotPatch failed: %v", err)
+	}
+	if !strings.Contains(patch, "+two") {
+		t.Errorf("Expected patch to contain added line, got:\n%s", patch)
+	}
+}
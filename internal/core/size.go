@@ -0,0 +1,278 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SnapshotSize is the storage one snapshot added: the file contents it was
+// the first to store. Trees and commits are left out, so it's approximate.
+type SnapshotSize struct {
+	Hash      string    `json:"hash"`
+	Time      time.Time `json:"time"`
+	Message   string    `json:"message"`
+	NewBlobs  int       `json:"new_blobs"`  // File versions first stored by this snapshot
+	DiskBytes int64     `json:"disk_bytes"` // Their size on disk (compressed or delta-encoded)
+}
+
+// FileSize is the storage taken by every stored version of one file
+type FileSize struct {
+	Path      string `json:"path"`
+	Versions  int    `json:"versions"`
+	Bytes     int64  `json:"bytes"`      // Uncompressed size of the largest version
+	DiskBytes int64  `json:"disk_bytes"` // On-disk size of all versions
+}
+
+// SizeGrowth is the storage added by the snapshots of one day, or one week
+// in GrowthSeries
+type SizeGrowth struct {
+	Day       time.Time `json:"day"` // Local midnight starting the period
+	Snapshots int       `json:"snapshots"`
+	DiskBytes int64     `json:"disk_bytes"` // Added that day
+	Total     int64     `json:"total"`      // Running total at the end of the day
+}
+
+// Periods GrowthSeries totals storage growth over
+const (
+	GrowthPerDay  = "day"
+	GrowthPerWeek = "week" // Starting on Monday
+)
+
+// SizeReport breaks down where the shadow repository's storage goes
+type SizeReport struct {
+	Snapshots []SnapshotSize `json:"snapshots"`  // Chronological (oldest first)
+	Files     []FileSize     `json:"files"`      // Largest on disk first
+	Growth    []SizeGrowth   `json:"growth"`     // Chronological
+	Blobs     int            `json:"blobs"`      // Distinct file versions stored
+	DiskBytes int64          `json:"disk_bytes"` // Their total size on disk
+}
+
+// LargestSnapshots returns the n snapshots that added the most storage
+func (r *SizeReport) LargestSnapshots(n int) []SnapshotSize {
+	sorted := append([]SnapshotSize(nil), r.Snapshots...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].DiskBytes > sorted[j].DiskBytes })
+	if n > 0 && len(sorted) > n {
+		sorted = sorted[:n]
+	}
+	return sorted
+}
+
+// LargestFiles returns the n files that take the most storage
+func (r *SizeReport) LargestFiles(n int) []FileSize {
+	if n > 0 && len(r.Files) > n {
+		return r.Files[:n]
+	}
+	return r.Files
+}
+
+// sizeBlob is a file version and the first snapshot that stored it
+type sizeBlob struct {
+	path     string
+	snapshot int // Index into SizeReport.Snapshots
+}
+
+// SizeReport measures the storage used by the snapshots on every shadow
+// branch. Each file version is charged to the first snapshot that stored it.
+func (g *GitManager) SizeReport() (*SizeReport, error) {
+	// Records start with \x01; -z keeps unusual paths unquoted
+	output, err := g.runCommandRaw("log", "--reverse", "--branches", "--raw", "-z", "--no-abbrev",
+		"--no-renames", "-r", "--root", "--format=%x01%H%x1f%ct%x1f%s")
+	if err != nil {
+		if strings.Contains(err.Error(), "does not have any commits yet") {
+			return &SizeReport{}, nil
+		}
+		return nil, fmt.Errorf("failed to read snapshot history: %w", err)
+	}
+
+	report := &SizeReport{}
+	blobs := make(map[string]sizeBlob)
+	var order []string
+	tokens := strings.Split(string(output), "\x00")
+	for i := 0; i < len(tokens); i++ {
+		token := strings.TrimLeft(tokens[i], "\n")
+		switch {
+		case strings.HasPrefix(token, "\x01"):
+			header := strings.SplitN(token[1:], "\x1f", 3)
+			if len(header) != 3 {
+				continue
+			}
+			snapshot := SnapshotSize{Hash: header[0], Message: header[2]}
+			if seconds, err := strconv.ParseInt(header[1], 10, 64); err == nil {
+				snapshot.Time = time.Unix(seconds, 0)
+			}
+			report.Snapshots = append(report.Snapshots, snapshot)
+		case strings.HasPrefix(token, ":") && i+1 < len(tokens):
+			// :<old mode> <new mode> <old object> <new object> <status>, then the path
+			fields := strings.Fields(token)
+			i++
+			if len(fields) != 5 || fields[4] == "D" || !strings.HasPrefix(fields[1], "10") || len(report.Snapshots) == 0 {
+				continue // Deletions, symlinks and submodules store no file content
+			}
+			if _, seen := blobs[fields[3]]; seen {
+				continue
+			}
+			blobs[fields[3]] = sizeBlob{path: tokens[i], snapshot: len(report.Snapshots) - 1}
+			order = append(order, fields[3])
+		}
+	}
+
+	if err := g.measureBlobs(report, blobs, order); err != nil {
+		return nil, err
+	}
+	report.Growth = sizeGrowth(report.Snapshots)
+	return report, nil
+}
+
+// measureBlobs asks git for the size of each blob and charges it to its
+// snapshot and file
+func (g *GitManager) measureBlobs(report *SizeReport, blobs map[string]sizeBlob, order []string) error {
+	if len(order) == 0 {
+		return nil
+	}
+
+	output, err := g.runCommandInput(strings.Join(order, "\n")+"\n",
+		"cat-file", "--batch-check=%(objectname) %(objectsize) %(objectsize:disk)")
+	if err != nil {
+		return fmt.Errorf("failed to measure snapshot objects: %w", err)
+	}
+
+	files := make(map[string]*FileSize)
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			continue // "<object> missing" is reported by verify, not here
+		}
+		blob, ok := blobs[fields[0]]
+		if !ok {
+			continue
+		}
+		size, err1 := strconv.ParseInt(fields[1], 10, 64)
+		disk, err2 := strconv.ParseInt(fields[2], 10, 64)
+		if err1 != nil || err2 != nil {
+			return fmt.Errorf("unexpected object size %q", line)
+		}
+
+		report.Blobs++
+		report.DiskBytes += disk
+		report.Snapshots[blob.snapshot].NewBlobs++
+		report.Snapshots[blob.snapshot].DiskBytes += disk
+
+		file := files[blob.path]
+		if file == nil {
+			file = &FileSize{Path: blob.path}
+			files[blob.path] = file
+		}
+		file.Versions++
+		file.DiskBytes += disk
+		if size > file.Bytes {
+			file.Bytes = size
+		}
+	}
+
+	for _, file := range files {
+		report.Files = append(report.Files, *file)
+	}
+	sort.Slice(report.Files, func(i, j int) bool {
+		if report.Files[i].DiskBytes != report.Files[j].DiskBytes {
+			return report.Files[i].DiskBytes > report.Files[j].DiskBytes
+		}
+		return report.Files[i].Path < report.Files[j].Path
+	})
+	return nil
+}
+
+// sizeGrowth totals the storage added per local day
+func sizeGrowth(snapshots []SnapshotSize) []SizeGrowth {
+	byDay := make(map[time.Time]*SizeGrowth)
+	for _, snapshot := range snapshots {
+		t := snapshot.Time.Local()
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+		growth := byDay[day]
+		if growth == nil {
+			growth = &SizeGrowth{Day: day}
+			byDay[day] = growth
+		}
+		growth.Snapshots++
+		growth.DiskBytes += snapshot.DiskBytes
+	}
+
+	growth := make([]SizeGrowth, 0, len(byDay))
+	for _, day := range byDay {
+		growth = append(growth, *day)
+	}
+	sort.Slice(growth, func(i, j int) bool { return growth[i].Day.Before(growth[j].Day) })
+
+	var total int64
+	for i := range growth {
+		total += growth[i].DiskBytes
+		growth[i].Total = total
+	}
+	return growth
+}
+
+// growthPeriodStart returns the local midnight starting the day or week of t
+func growthPeriodStart(t time.Time, period string) time.Time {
+	t = t.Local()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+	if period == GrowthPerWeek {
+		day = day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	}
+	return day
+}
+
+// GrowthSeries totals the storage added per day or week, from the period of
+// the first snapshot to that of until. Periods without snapshots are
+// included, so the series can be charted.
+func (r *SizeReport) GrowthSeries(period string, until time.Time) ([]SizeGrowth, error) {
+	step := 1
+	switch period {
+	case GrowthPerDay:
+	case GrowthPerWeek:
+		step = 7
+	default:
+		return nil, NewValidationError("unsupported period: %s (use '%s' or '%s')", period, GrowthPerDay, GrowthPerWeek)
+	}
+	if len(r.Snapshots) == 0 {
+		return nil, nil
+	}
+
+	byPeriod := make(map[time.Time]*SizeGrowth)
+	first := growthPeriodStart(r.Snapshots[0].Time, period)
+	for _, snapshot := range r.Snapshots {
+		start := growthPeriodStart(snapshot.Time, period)
+		if start.Before(first) {
+			first = start // Clocks going back
+		}
+		growth := byPeriod[start]
+		if growth == nil {
+			growth = &SizeGrowth{Day: start}
+			byPeriod[start] = growth
+		}
+		growth.Snapshots++
+		growth.DiskBytes += snapshot.DiskBytes
+	}
+
+	last := growthPeriodStart(until, period)
+	for start := range byPeriod {
+		if start.After(last) {
+			last = start
+		}
+	}
+
+	var series []SizeGrowth
+	var total int64
+	// AddDate keeps local midnight across daylight saving changes
+	for start := first; !start.After(last); start = start.AddDate(0, 0, step) {
+		growth := SizeGrowth{Day: start}
+		if counted := byPeriod[start]; counted != nil {
+			growth = *counted
+		}
+		total += growth.DiskBytes
+		growth.Total = total
+		series = append(series, growth)
+	}
+	return series, nil
+}
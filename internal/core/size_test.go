@@ -0,0 +1,104 @@
+package core
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestSizeReport(t *testing.T) {
+	tempDir, _, gitManager := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	day1 := time.Date(2025, 3, 1, 10, 0, 0, 0, time.Local)
+	day2 := day1.AddDate(0, 0, 1)
+	snapshotFileAt(t, tempDir, gitManager, "first version", day1)
+	snapshotFileAt(t, tempDir, gitManager, "second version, somewhat longer", day1.Add(time.Hour))
+	snapshotFileAt(t, tempDir, gitManager, "first version", day2) // Reuses stored content
+
+	report, err := gitManager.SizeReport()
+	if err != nil {
+		t.Fatalf("SizeReport failed: %v", err)
+	}
+	if len(report.Snapshots) < 3 {
+		t.Fatalf("Expected at least 3 snapshots, got %d", len(report.Snapshots))
+	}
+
+	last := report.Snapshots[len(report.Snapshots)-1]
+	if last.Message != "first version" || last.NewBlobs != 0 || last.DiskBytes != 0 {
+		t.Errorf("Expected the last snapshot to add nothing, got %+v", last)
+	}
+	second := report.Snapshots[len(report.Snapshots)-2]
+	if second.NewBlobs != 1 || second.DiskBytes <= 0 {
+		t.Errorf("Expected the second snapshot to add one file version, got %+v", second)
+	}
+
+	var file *FileSize
+	for i := range report.Files {
+		if report.Files[i].Path == "file.txt" {
+			file = &report.Files[i]
+		}
+	}
+	if file == nil {
+		t.Fatalf("Expected file.txt in %+v", report.Files)
+	}
+	if file.Versions != 2 || file.Bytes != int64(len("second version, somewhat longer")) {
+		t.Errorf("Expected 2 versions of file.txt, largest %d bytes, got %+v", len("second version, somewhat longer"), *file)
+	}
+
+	var total int64
+	for _, snapshot := range report.Snapshots {
+		total += snapshot.DiskBytes
+	}
+	if total != report.DiskBytes {
+		t.Errorf("Expected snapshot sizes to add up to %d, got %d", report.DiskBytes, total)
+	}
+
+	growth := report.Growth[len(report.Growth)-1]
+	if !growth.Day.Equal(time.Date(2025, 3, 2, 0, 0, 0, 0, time.Local)) || growth.Snapshots != 1 || growth.DiskBytes != 0 {
+		t.Errorf("Unexpected growth for the last day: %+v", growth)
+	}
+	if growth.Total != report.DiskBytes {
+		t.Errorf("Expected the running total to end at %d, got %d", report.DiskBytes, growth.Total)
+	}
+
+	if largest := report.LargestSnapshots(1); len(largest) != 1 || largest[0].DiskBytes == 0 {
+		t.Errorf("Expected the largest snapshot to have added something, got %+v", largest)
+	}
+}
+
+func TestGrowthSeries(t *testing.T) {
+	at := func(day, hour int) time.Time { return time.Date(2025, 3, day, hour, 0, 0, 0, time.Local) }
+	report := &SizeReport{Snapshots: []SnapshotSize{
+		{Time: at(3, 9), DiskBytes: 100}, // Monday
+		{Time: at(3, 18), DiskBytes: 50},
+		{Time: at(6, 12), DiskBytes: 10},
+		{Time: at(11, 8), DiskBytes: 0},
+	}}
+
+	days, err := report.GrowthSeries(GrowthPerDay, at(12, 0))
+	if err != nil {
+		t.Fatalf("GrowthSeries failed: %v", err)
+	}
+	if len(days) != 10 || !days[0].Day.Equal(at(3, 0)) || !days[9].Day.Equal(at(12, 0)) {
+		t.Fatalf("Expected every day from March 3 to 12, got %+v", days)
+	}
+	if days[0].Snapshots != 2 || days[0].DiskBytes != 150 || days[1].Snapshots != 0 || days[1].Total != 150 {
+		t.Errorf("Unexpected first days %+v", days[:2])
+	}
+	if days[9].Total != 160 || days[8].Snapshots != 1 {
+		t.Errorf("Unexpected last days %+v", days[8:])
+	}
+
+	weeks, err := report.GrowthSeries(GrowthPerWeek, at(12, 0))
+	if err != nil || len(weeks) != 2 {
+		t.Fatalf("Expected 2 weeks, got %+v, %v", weeks, err)
+	}
+	if !weeks[1].Day.Equal(at(10, 0)) || weeks[0].Snapshots != 3 || weeks[1].Snapshots != 1 || weeks[1].Total != 160 {
+		t.Errorf("Unexpected weeks %+v", weeks)
+	}
+
+	if _, err := report.GrowthSeries("month", at(12, 0)); ExitCode(err) != ExitValidation {
+		t.Errorf("Expected an unsupported period to be rejected, got %v", err)
+	}
+}
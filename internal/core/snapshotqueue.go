@@ -0,0 +1,61 @@
+package core
+
+import (
+	"fmt"
+	"time"
+)
+
+// snapshotQueueSize bounds the snapshots waiting for the worker. A snapshot
+// takes every path changed until it starts, so a queued one already covers
+// the changes behind later requests: those collapse into it.
+const snapshotQueueSize = 1
+
+// queueSnapshot asks the snapshot worker for a snapshot without waiting for
+// git. A request made while another is queued collapses into it.
+func (w *Watcher) queueSnapshot() {
+	select {
+	case w.snapshotQueue <- struct{}{}:
+	default:
+	}
+}
+
+// snapshotWorker creates the queued snapshots one at a time, so a slow git
+// operation never holds up event processing. With watcher.interval_snapshot
+// set, changes pending that long after the last snapshot are snapshotted
+// too, even while the debouncer or a change storm keeps deferring them.
+func (w *Watcher) snapshotWorker() {
+	defer w.wg.Done()
+
+	var timer *time.Timer
+	var due <-chan time.Time
+	if w.snapshotEvery > 0 {
+		timer = time.NewTimer(w.snapshotEvery)
+		defer timer.Stop()
+		due = timer.C
+	}
+
+	for {
+		select {
+		case <-w.stopChan:
+			return
+		case <-w.snapshotQueue:
+			w.createSnapshot()
+		case <-due:
+			if w.hasPendingChanges() {
+				fmt.Printf("⏰ %s Changes pending for %s; snapshotting (watcher.interval_snapshot)\n",
+					time.Now().Format("2006-01-02 15:04:05"), w.snapshotEvery)
+				w.createSnapshot()
+			}
+		}
+		if timer != nil {
+			timer.Reset(w.snapshotEvery)
+		}
+	}
+}
+
+// hasPendingChanges reports whether changes wait for a snapshot
+func (w *Watcher) hasPendingChanges() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return len(w.changed) > 0 || w.rescan
+}
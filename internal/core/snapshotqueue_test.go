@@ -0,0 +1,104 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSnapshotQueue(t *testing.T) {
+	tempDir, state, gitManager := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	watcher, err := NewWatcher(state, gitManager)
+	if err != nil {
+		t.Fatalf("NewWatcher failed: %v", err)
+	}
+	defer watcher.fsWatcher.Close()
+
+	for _, name := range []string{"a.txt", "b.txt", "c.txt"} {
+		if err := os.WriteFile(filepath.Join(tempDir, name), []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+		watcher.requeueChangedPaths([]string{name}, false)
+		watcher.queueSnapshot() // Never blocks, even with no worker running
+	}
+	if queued := len(watcher.snapshotQueue); queued != 1 {
+		t.Fatalf("Expected requests to collapse into one queued snapshot, got %d", queued)
+	}
+	if !watcher.Stats().SnapshotPending {
+		t.Error("Expected a queued snapshot to be reported as pending")
+	}
+
+	done := make(chan error, 1)
+	watcher.SetSnapshotHandler(func(err error) { done <- err })
+	watcher.wg.Add(1)
+	go watcher.snapshotWorker()
+	defer func() {
+		close(watcher.stopChan)
+		watcher.wg.Wait()
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Queued snapshot failed: %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Timed out waiting for the queued snapshot")
+	}
+	files, err := gitManager.SnapshotFiles("HEAD")
+	if err != nil || len(files) != 3 {
+		t.Errorf("Expected one snapshot of all 3 files, got %v, %v", files, err)
+	}
+	if count, _ := gitManager.RunCommand("rev-list", "--count", "HEAD"); count != "1" {
+		t.Errorf("Expected a single snapshot, got %s", count)
+	}
+}
+
+func TestIntervalSnapshot(t *testing.T) {
+	tempDir, state, gitManager := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	watcher, err := NewWatcher(state, gitManager)
+	if err != nil {
+		t.Fatalf("NewWatcher failed: %v", err)
+	}
+	defer watcher.fsWatcher.Close()
+	watcher.snapshotEvery = 50 * time.Millisecond
+	watcher.storming = true // Debounced snapshots are deferred
+
+	done := make(chan error, 1)
+	watcher.SetSnapshotHandler(func(err error) { done <- err })
+	watcher.wg.Add(1)
+	go watcher.snapshotWorker()
+	defer func() {
+		close(watcher.stopChan)
+		watcher.wg.Wait()
+	}()
+
+	// Nothing pending: the interval passes without a snapshot
+	time.Sleep(3 * watcher.snapshotEvery)
+	select {
+	case <-done:
+		t.Fatal("Expected no snapshot without pending changes")
+	default:
+	}
+
+	if err := os.WriteFile(filepath.Join(tempDir, "eval.log"), []byte("step 1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	watcher.requeueChangedPaths([]string{"eval.log"}, false)
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Interval snapshot failed: %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Timed out waiting for the interval snapshot")
+	}
+	if files, err := gitManager.SnapshotFiles("HEAD"); err != nil || len(files) != 1 {
+		t.Errorf("Expected a snapshot of eval.log, got %v, %v", files, err)
+	}
+}
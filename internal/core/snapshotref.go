@@ -0,0 +1,67 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// LatestSnapshotRef names the newest snapshot on the current shadow branch
+const LatestSnapshotRef = "latest"
+
+// maxSnapshotRefLength bounds what is handed to git as a revision
+const maxSnapshotRefLength = 256
+
+// snapshotRefPattern allows hashes, tag and branch names and the revision
+// suffixes ~, ^ and @{...}; no whitespace, quotes or shell metacharacters
+var snapshotRefPattern = regexp.MustCompile(`^[A-Za-z0-9._/@{}~^+-]+$`)
+
+// SnapshotsAgoRef names the snapshot n before the latest, what -n n
+// selects on the command line
+func SnapshotsAgoRef(n int) string {
+	return fmt.Sprintf("%s~%d", LatestSnapshotRef, n)
+}
+
+// ValidateSnapshotRef checks that ref can only be read as a revision: a
+// full or short hash, a tag or branch, "latest" or a relative revision
+// such as HEAD~3 or latest^. Options and ranges are rejected.
+func ValidateSnapshotRef(ref string) error {
+	switch {
+	case ref == "":
+		return NewValidationError("empty snapshot hash not allowed")
+	case len(ref) > maxSnapshotRefLength:
+		return NewValidationError("invalid snapshot hash %q: longer than %d characters", ref[:16]+"…", maxSnapshotRefLength)
+	case strings.HasPrefix(ref, "-"):
+		return NewValidationError("invalid snapshot hash %q: must not start with '-'", ref)
+	case strings.Contains(ref, ".."):
+		return NewValidationError("invalid snapshot hash %q: ranges name several snapshots", ref)
+	case !snapshotRefPattern.MatchString(ref):
+		return NewValidationError("invalid snapshot hash %q: use a hash, tag, 'latest' or a revision such as HEAD~3", ref)
+	}
+	return nil
+}
+
+// ResolveSnapshot resolves a snapshot hash, short hash, tag, "latest",
+// "latest~N" or revision such as HEAD~2 to a full hash. The ref is
+// validated and passed after --end-of-options, so it can't be read as an
+// option.
+func (g *GitManager) ResolveSnapshot(ref string) (string, error) {
+	if err := ValidateSnapshotRef(ref); err != nil {
+		return "", err
+	}
+	revision := ref
+	if rest, ok := strings.CutPrefix(ref, LatestSnapshotRef); ok && (rest == "" || strings.ContainsAny(rest[:1], "~^")) {
+		revision = "HEAD" + rest
+	}
+
+	output, err := g.runCommandRaw("rev-parse", "--verify", "--end-of-options", revision+"^{commit}")
+	if err != nil {
+		var gitErr *GitError
+		if errors.As(err, &gitErr) && strings.Contains(gitErr.Output, "ambiguous") {
+			return "", NewValidationError("snapshot hash %s is ambiguous: give more of its characters", ref)
+		}
+		return "", NewNotFoundError("snapshot %s does not exist", ref)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
@@ -0,0 +1,67 @@
+package core
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestValidateSnapshotRef(t *testing.T) {
+	valid := []string{"abc1", "abc", "ABCdef123", "abc123def456789012345678901234567890abcd", "v1.2-rc", "latest", "latest~3", "HEAD^", "main@{1}"}
+	for _, ref := range valid {
+		if err := ValidateSnapshotRef(ref); err != nil {
+			t.Errorf("ValidateSnapshotRef(%q) unexpected error: %v", ref, err)
+		}
+	}
+
+	invalid := []string{"", "-n", "--output=x", "abc..def", "abc123; rm -rf /", "$(rm -rf /)", "`id`", "a b", "tag\n", strings.Repeat("a", 300)}
+	for _, ref := range invalid {
+		if err := ValidateSnapshotRef(ref); ExitCode(err) != ExitValidation {
+			t.Errorf("ValidateSnapshotRef(%q) = %v, want a validation error", ref, err)
+		}
+	}
+}
+
+func TestResolveSnapshot(t *testing.T) {
+	tempDir, _, gitManager := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	var hashes []string
+	for _, content := range []string{"one", "two", "three", "four"} {
+		os.WriteFile(tempDir+"/file.txt", []byte(content), 0644)
+		if err := gitManager.CreateSnapshot(content); err != nil {
+			t.Fatalf("CreateSnapshot failed: %v", err)
+		}
+		hash, _ := gitManager.RunCommand("rev-parse", "HEAD")
+		hashes = append(hashes, hash)
+	}
+	latest := hashes[len(hashes)-1]
+	if _, err := gitManager.RunCommand("tag", "-a", "-m", "Release", "v1", hashes[0]); err != nil {
+		t.Fatalf("tag failed: %v", err)
+	}
+
+	for ref, expected := range map[string]string{
+		latest:             latest,
+		latest[:7]:         latest,
+		LatestSnapshotRef:  latest,
+		SnapshotsAgoRef(0): latest,
+		SnapshotsAgoRef(3): hashes[0],
+		"latest^":          hashes[2],
+		"HEAD~2":           hashes[1],
+		"v1":               hashes[0],
+	} {
+		hash, err := gitManager.ResolveSnapshot(ref)
+		if err != nil || hash != expected {
+			t.Errorf("ResolveSnapshot(%q) = %q, %v, want %q", ref, hash, err, expected)
+		}
+	}
+
+	for _, ref := range []string{"deadbeef", "latest~10", "nope"} {
+		if _, err := gitManager.ResolveSnapshot(ref); ExitCode(err) != ExitNotFound {
+			t.Errorf("ResolveSnapshot(%q) = %v, want a not found error", ref, err)
+		}
+	}
+	if _, err := gitManager.ResolveSnapshot("--all"); ExitCode(err) != ExitValidation {
+		t.Errorf("Expected an option to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,40 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SnapshotStatsBatch is how many snapshots SnapshotStats reads per git
+// process; list renders that many at a time
+const SnapshotStatsBatch = 50
+
+// SnapshotStats returns the files each snapshot changed, with line counts,
+// keyed by hash. One git log --numstat pass reads a batch of snapshots,
+// instead of a git show --stat per snapshot.
+func (g *GitManager) SnapshotStats(hashes []string) (map[string]SnapshotChanges, error) {
+	stats := make(map[string]SnapshotChanges, len(hashes))
+	for start := 0; start < len(hashes); start += SnapshotStatsBatch {
+		end := start + SnapshotStatsBatch
+		if end > len(hashes) {
+			end = len(hashes)
+		}
+
+		// Records start with a NUL byte so numstat lines can be told apart
+		args := []string{"log", "--no-walk=unsorted", "--numstat", "--format=%x00%H%x1f%ct%x1f%s"}
+		for _, hash := range hashes[start:end] {
+			if strings.HasPrefix(hash, "-") {
+				return nil, NewValidationError("invalid snapshot hash %q", hash)
+			}
+			args = append(args, hash)
+		}
+		output, err := g.RunCommand(append(args, "--")...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read snapshot statistics: %w", err)
+		}
+		for _, snapshot := range parseLogChanges(output) {
+			stats[snapshot.Hash] = snapshot
+		}
+	}
+	return stats, nil
+}
@@ -0,0 +1,84 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSnapshotStats(t *testing.T) {
+	tempDir, _, gitManager := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	snapshotFile(t, tempDir, gitManager, "one\n")
+	os.WriteFile(filepath.Join(tempDir, "other.txt"), []byte("a\nb\n"), 0644)
+	snapshotFile(t, tempDir, gitManager, "one\ntwo\n")
+
+	snapshots, err := gitManager.ListSnapshots(0, "")
+	if err != nil || len(snapshots) != 2 {
+		t.Fatalf("ListSnapshots = %d snapshots, %v", len(snapshots), err)
+	}
+	stats, err := gitManager.SnapshotStats([]string{snapshots[0].Hash, snapshots[1].Hash})
+	if err != nil {
+		t.Fatalf("SnapshotStats failed: %v", err)
+	}
+
+	latest := stats[snapshots[0].Hash]
+	if insertions, deletions := latest.Totals(); len(latest.Files) != 2 || insertions != 3 || deletions != 0 {
+		t.Errorf("latest snapshot: %d files +%d -%d, want 2 files +3 -0", len(latest.Files), insertions, deletions)
+	}
+	first := stats[snapshots[1].Hash]
+	if insertions, _ := first.Totals(); len(first.Files) != 1 || insertions != 1 {
+		t.Errorf("first snapshot: %+v, want 1 file +1", first)
+	}
+
+	if _, err := gitManager.SnapshotStats([]string{"--output=x"}); err == nil {
+		t.Error("expected an option-like hash to be rejected")
+	}
+}
+
+// benchmarkSnapshots creates a shadow repository with n snapshots and
+// returns their hashes
+func benchmarkSnapshots(b *testing.B, n int) (*GitManager, []string) {
+	tempDir, _, gitManager := setupTestRepo(b)
+	b.Cleanup(func() { os.RemoveAll(tempDir) })
+	for i := 0; i < n; i++ {
+		snapshotFile(b, tempDir, gitManager, fmt.Sprintf("version %d\n", i))
+	}
+	snapshots, err := gitManager.ListSnapshots(0, "")
+	if err != nil {
+		b.Fatalf("ListSnapshots failed: %v", err)
+	}
+	hashes := make([]string, len(snapshots))
+	for i, snapshot := range snapshots {
+		hashes[i] = snapshot.Hash
+	}
+	return gitManager, hashes
+}
+
+// BenchmarkSnapshotStats measures reading the statistics list shows for a
+// page of 100 snapshots
+func BenchmarkSnapshotStats(b *testing.B) {
+	gitManager, hashes := benchmarkSnapshots(b, 100)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := gitManager.SnapshotStats(hashes); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkSnapshotStatsPerSnapshot is the git show --stat per snapshot
+// that SnapshotStats replaces, as a baseline
+func BenchmarkSnapshotStatsPerSnapshot(b *testing.B) {
+	gitManager, hashes := benchmarkSnapshots(b, 100)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, hash := range hashes {
+			if _, err := gitManager.RunCommand("show", "--stat", "--format=", hash); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
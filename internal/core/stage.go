@@ -0,0 +1,126 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// stageChunkSize bounds the paths passed to one git invocation, well below
+// command line limits (32K characters on Windows)
+const stageChunkSize = 100
+
+// CreateSnapshotForPaths creates a snapshot like CreateSnapshot, but stages
+// only the given paths (slash-separated, relative to the project root)
+// instead of scanning the whole tree with `git add -A`. Paths may be files or
+// directories that were changed, created or deleted; changes anywhere else
+// are not picked up, so callers must pass everything changed since the last
+// snapshot.
+func (g *GitManager) CreateSnapshotForPaths(message string, paths []string) error {
+	paths = includedPaths(paths, g.State.IncludePaths)
+	if len(paths) == 0 {
+		return nil
+	}
+	if err := g.CheckDiskSpace(); err != nil {
+		return err
+	}
+	if g.backend.Name() != BackendExec {
+		// The native backend stages in-process without spawning git
+		return g.backend.CreateSnapshot(message)
+	}
+
+	if err := g.stagePaths(paths); err != nil {
+		return err
+	}
+	if _, err := g.unstageChurn(); err != nil {
+		return err
+	}
+
+	// Only the index is compared, so this stays cheap on large trees
+	staged, err := g.RunCommand("diff", "--cached", "--name-only")
+	if err != nil {
+		return fmt.Errorf("failed to check staged changes: %w", err)
+	}
+	if staged == "" {
+		return nil
+	}
+	if err := g.checkStagedSecrets(); err != nil {
+		return err
+	}
+
+	if message == "" {
+		message = g.snapshotMessage(g.stagedFiles())
+	}
+	commits := g.pendingProjectCommits()
+	if _, err := g.RunCommand(g.snapshotCommit(g.snapshotMessageWithTrailers(message, commits))...); err != nil {
+		return fmt.Errorf("failed to create snapshot: %w", err)
+	}
+	g.clearProjectCommits(commits)
+	return nil
+}
+
+// stagePaths updates the index for exactly the given paths. git add fails
+// outright on a path that matches nothing, so paths that no longer exist are
+// removed from the index instead, and it fails on ignored paths, so those
+// are left out.
+func (g *GitManager) stagePaths(paths []string) error {
+	var present, gone []string
+	for _, path := range paths {
+		if _, err := os.Lstat(filepath.Join(g.State.ProjectRoot, filepath.FromSlash(path))); err == nil {
+			present = append(present, path)
+		} else {
+			gone = append(gone, path)
+		}
+	}
+
+	present, err := g.dropIgnored(present)
+	if err != nil {
+		return err
+	}
+
+	for start := 0; start < len(gone); start += stageChunkSize {
+		chunk := gone[start:min(start+stageChunkSize, len(gone))]
+		args := append([]string{"rm", "--cached", "-r", "-q", "--ignore-unmatch", "--"}, topPathspecs(chunk)...)
+		if _, err := g.RunCommand(args...); err != nil {
+			return fmt.Errorf("failed to stage deletions: %w", err)
+		}
+	}
+	for start := 0; start < len(present); start += stageChunkSize {
+		chunk := present[start:min(start+stageChunkSize, len(present))]
+		args := append([]string{"add", "-A", "--"}, topPathspecs(chunk)...)
+		if _, err := g.RunCommand(args...); err != nil {
+			return fmt.Errorf("failed to stage files: %w", err)
+		}
+	}
+	return nil
+}
+
+// dropIgnored returns the paths git does not ignore. Tracked files are never
+// ignored.
+func (g *GitManager) dropIgnored(paths []string) ([]string, error) {
+	if len(paths) == 0 {
+		return nil, nil
+	}
+
+	output, err := g.runCommandInput(strings.Join(paths, "\x00")+"\x00", "check-ignore", "-z", "--stdin")
+	if err != nil {
+		// Exit status 1 means none of the paths are ignored
+		if len(output) == 0 && strings.Contains(err.Error(), "exit status 1") {
+			return paths, nil
+		}
+		return nil, fmt.Errorf("failed to check ignored paths: %w", err)
+	}
+
+	ignored := make(map[string]bool)
+	for _, path := range strings.Split(string(output), "\x00") {
+		ignored[path] = true
+	}
+	kept := paths[:0:0]
+	for _, path := range paths {
+		if !ignored[path] {
+			kept = append(kept, path)
+		}
+	}
+	return kept, nil
+}
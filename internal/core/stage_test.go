@@ -0,0 +1,145 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCreateSnapshotForPaths(t *testing.T) {
+	tempDir, _, gitManager := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	write := func(name, content string) {
+		path := filepath.Join(tempDir, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	write(".gitignore", "*.log\n")
+	write("changed.txt", "v1")
+	write("deleted.txt", "v1")
+	write("untouched.txt", "v1")
+	write("dir/a.txt", "v1")
+	write("dir/b.txt", "v1")
+	if err := gitManager.CreateSnapshot("base"); err != nil {
+		t.Fatalf("CreateSnapshot failed: %v", err)
+	}
+
+	write("changed.txt", "v2")
+	write("untouched.txt", "v2") // Changed, but not reported
+	write("new.txt", "new")
+	write("debug.log", "ignored")
+	os.Remove(filepath.Join(tempDir, "deleted.txt"))
+	os.RemoveAll(filepath.Join(tempDir, "dir"))
+
+	paths := []string{"changed.txt", "new.txt", "debug.log", "deleted.txt", "dir", "vanished.tmp"}
+	if err := gitManager.CreateSnapshotForPaths("batch", paths); err != nil {
+		t.Fatalf("CreateSnapshotForPaths failed: %v", err)
+	}
+
+	files, err := gitManager.RunCommand("ls-tree", "-r", "--name-only", "HEAD")
+	if err != nil {
+		t.Fatalf("ls-tree failed: %v", err)
+	}
+	if expected := ".gitignore\nchanged.txt\nnew.txt\nuntouched.txt"; files != expected {
+		t.Errorf("Expected snapshot files %q, got %q", expected, files)
+	}
+	if content, _ := gitManager.RunCommand("show", "HEAD:changed.txt"); content != "v2" {
+		t.Errorf("Expected changed.txt to be staged, got %q", content)
+	}
+	if content, _ := gitManager.RunCommand("show", "HEAD:untouched.txt"); content != "v1" {
+		t.Errorf("Expected unreported changes to be left alone, got %q", content)
+	}
+	if message, _ := gitManager.RunCommand("log", "-1", "--format=%s"); message != "batch" {
+		t.Errorf("Expected snapshot message %q, got %q", "batch", message)
+	}
+}
+
+func TestCreateSnapshotForPathsNoChanges(t *testing.T) {
+	tempDir, _, gitManager := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	snapshotFile(t, tempDir, gitManager, "same")
+	before, _ := gitManager.RunCommand("rev-parse", "HEAD")
+
+	// Touched but identical, and a path that matches nothing
+	os.WriteFile(filepath.Join(tempDir, "file.txt"), []byte("same"), 0644)
+	if err := gitManager.CreateSnapshotForPaths("", []string{"file.txt", "gone.txt"}); err != nil {
+		t.Fatalf("CreateSnapshotForPaths failed: %v", err)
+	}
+	if after, _ := gitManager.RunCommand("rev-parse", "HEAD"); after != before {
+		t.Errorf("Expected no snapshot without changes")
+	}
+}
+
+func TestCreateSnapshotForPathsManyPaths(t *testing.T) {
+	tempDir, _, gitManager := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	// More paths than fit in one git invocation
+	var paths []string
+	for i := 0; i < stageChunkSize*2+5; i++ {
+		name := fmt.Sprintf("file-%03d.txt", i)
+		os.WriteFile(filepath.Join(tempDir, name), []byte(name), 0644)
+		paths = append(paths, name)
+	}
+	if err := gitManager.CreateSnapshotForPaths("many", paths); err != nil {
+		t.Fatalf("CreateSnapshotForPaths failed: %v", err)
+	}
+
+	files, _ := gitManager.RunCommand("ls-tree", "-r", "--name-only", "HEAD")
+	if count := len(strings.Split(files, "\n")); count != len(paths) {
+		t.Errorf("Expected %d files in the snapshot, got %d", len(paths), count)
+	}
+}
+
+func TestWatcherPeriodicFullScan(t *testing.T) {
+	tempDir, state, gitManager := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	watcher, err := NewWatcher(state, gitManager)
+	if err != nil {
+		t.Fatalf("NewWatcher failed: %v", err)
+	}
+	defer watcher.fsWatcher.Close()
+
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(tempDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	snapshot := func(changed string) {
+		watcher.requeueChangedPaths([]string{changed}, false)
+		watcher.createSnapshot()
+	}
+	tracked := func() string {
+		files, err := gitManager.RunCommand("ls-tree", "-r", "--name-only", "HEAD")
+		if err != nil {
+			t.Fatalf("ls-tree failed: %v", err)
+		}
+		return files
+	}
+
+	write("a.txt", "v1")
+	write("missed.txt", "v1") // No event reported
+	snapshot("a.txt")
+	if files := tracked(); strings.Contains(files, "missed.txt") {
+		t.Fatalf("Expected only the changed path to be staged, got %q", files)
+	}
+
+	write("a.txt", "v2")
+	watcher.lastFullScan = time.Now().Add(-fullScanInterval)
+	snapshot("a.txt")
+	if files := tracked(); !strings.Contains(files, "missed.txt") {
+		t.Errorf("Expected the periodic full scan to stage missed.txt, got %q", files)
+	}
+	if time.Since(watcher.lastFullScan) > time.Minute {
+		t.Error("Expected the full scan to be recorded")
+	}
+}
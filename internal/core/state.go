@@ -1,43 +1,116 @@
 package core
 
 import (
-	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	
 	"github.com/deepakkumarnarayana/timemachine-cli/internal/config"
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/render"
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/ui"
 )
 
 // AppState contains the application state and paths
 type AppState struct {
 	ProjectRoot   string          // Absolute path to project root (parent of .git)
-	GitDir        string          // Path to .git directory
-	ShadowRepoDir string          // Path to .git/timemachine_snapshots
+	GitDir        string          // Path to .git directory, or a linked worktree's own git directory
+	ShadowRepoDir string          // Path to .git/timemachine_snapshots, per worktree
 	IsInitialized bool            // Whether shadow repo exists and is valid
 	Config        *config.Config  // Application configuration
 	ConfigManager *config.Manager // Configuration manager
+	IncludePaths  []string        // Subtrees snapshots are limited to, slash-separated relative to ProjectRoot; nil for everything
+	IgnoreFlags   []string        // Ignore patterns given to start --ignore
+
+	capabilities *Capabilities // Probed on first use, see Capabilities
 }
 
 // NewAppState creates a new AppState by finding the Git repository
 // and checking if the shadow repository is initialized
 func NewAppState() (*AppState, error) {
+	state, err := NewLightAppState()
+	if err != nil {
+		return nil, err
+	}
+
+	// Initialize configuration manager
+	configManager := config.NewManager()
+	
+	// Load configuration (don't fail if config doesn't exist)
+	err = configManager.Load(state.ProjectRoot)
+	if err != nil {
+		// Log warning but continue - config is optional
+		fmt.Printf("Warning: failed to load configuration: %v\n", err)
+	}
+
+	state.Config = configManager.Get()
+	state.ConfigManager = configManager
+
+	// A config that failed to load may be half-populated; keep the default look
+	if err == nil {
+		applyUIConfig(state.Config)
+		state.IncludePaths = cleanIncludePaths(state.Config.Watcher.IncludePaths)
+	}
+	applyTelemetryConfig(state)
+
+	return state, nil
+}
+
+// applyUIConfig activates the configured output theme for all commands
+func applyUIConfig(cfg *config.Config) {
+	if cfg == nil {
+		return
+	}
+	if err := ui.Configure(cfg.UI.Theme, cfg.UI.CustomTheme, cfg.UI.ColorOutput); err != nil {
+		fmt.Printf("Warning: %v, using the default theme\n", err)
+	}
+	if err := ui.SetLanguage(cfg.UI.Language); err != nil {
+		fmt.Printf("Warning: %v, using English\n", err)
+	}
+	if !cfg.UI.Emoji {
+		ui.DisableEmoji()
+	}
+	if err := render.ConfigureSyntax(cfg.UI.SyntaxTheme); err != nil {
+		fmt.Printf("Warning: %v, using the default syntax theme\n", err)
+	}
+}
+
+// NewLightAppState resolves repository paths without loading configuration.
+// Intended for latency-sensitive callers such as shell prompt helpers;
+// Config and ConfigManager are left nil.
+func NewLightAppState() (*AppState, error) {
 	// Get current working directory
 	cwd, err := os.Getwd()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get current working directory: %w", err)
 	}
 
+	return NewLightAppStateAt(cwd)
+}
+
+// NewLightAppStateAt is NewLightAppState for the repository containing dir
+// rather than the current directory
+func NewLightAppStateAt(dir string) (*AppState, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("invalid directory %s: %w", dir, err)
+	}
+
 	// Walk up directory tree looking for .git directory
-	gitDir := findGitDir(cwd)
-	if gitDir == "" {
-		return nil, errors.New("not in a Git repository (or any parent directory)")
+	dotGit := findGitDir(dir)
+	if dotGit == "" {
+		return nil, ErrNotGitRepository
 	}
 
 	// Set ProjectRoot to parent of .git
-	projectRoot := filepath.Dir(gitDir)
+	projectRoot := filepath.Dir(dotGit)
+	gitDir, err := resolveGitDir(dotGit)
+	if err != nil {
+		return nil, err
+	}
 	
-	// Set ShadowRepoDir to .git/timemachine_snapshots
+	// Set ShadowRepoDir to .git/timemachine_snapshots; a linked worktree's
+	// git directory is its own, so each worktree gets a shadow repository
 	shadowRepoDir := filepath.Join(gitDir, "timemachine_snapshots")
 	
 	// Check if shadow repo exists by looking for HEAD file
@@ -47,25 +120,33 @@ func NewAppState() (*AppState, error) {
 		isInitialized = true
 	}
 
-	// Initialize configuration manager
-	configManager := config.NewManager()
-	
-	// Load configuration (don't fail if config doesn't exist)
-	if err := configManager.Load(projectRoot); err != nil {
-		// Log warning but continue - config is optional
-		fmt.Printf("Warning: failed to load configuration: %v\n", err)
-	}
-
 	return &AppState{
 		ProjectRoot:   projectRoot,
 		GitDir:        gitDir,
 		ShadowRepoDir: shadowRepoDir,
 		IsInitialized: isInitialized,
-		Config:        configManager.Get(),
-		ConfigManager: configManager,
 	}, nil
 }
 
+// LoadAppStateAt is NewAppState for the repository containing dir, for
+// programs embedding TimeMachine: a configuration that fails to load is an
+// error rather than a warning, and the CLI's output settings are left alone
+func LoadAppStateAt(dir string) (*AppState, error) {
+	state, err := NewLightAppStateAt(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	configManager := config.NewManager()
+	if err := configManager.Load(state.ProjectRoot); err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	state.Config = configManager.Get()
+	state.ConfigManager = configManager
+	state.IncludePaths = cleanIncludePaths(state.Config.Watcher.IncludePaths)
+	return state, nil
+}
+
 // NewAppStateWithConfig creates a new AppState with custom configuration
 // This is useful for testing or when configuration should be loaded differently
 func NewAppStateWithConfig(configManager *config.Manager) (*AppState, error) {
@@ -77,12 +158,16 @@ func NewAppStateWithConfig(configManager *config.Manager) (*AppState, error) {
 	// Override configuration
 	state.ConfigManager = configManager
 	state.Config = configManager.Get()
+	state.IncludePaths = cleanIncludePaths(state.Config.Watcher.IncludePaths)
+	applyUIConfig(state.Config)
+	applyTelemetryConfig(state)
 	
 	return state, nil
 }
 
-// findGitDir searches for a .git directory starting from the given directory
-// and walking up the directory tree until it finds one or reaches the filesystem root
+// findGitDir searches for a .git directory, or a .git file pointing to one,
+// starting from the given directory and walking up the directory tree until
+// it finds one or reaches the filesystem root
 func findGitDir(startDir string) string {
 	currentDir := startDir
 	
@@ -90,9 +175,14 @@ func findGitDir(startDir string) string {
 		// Check for .git directory in current directory
 		gitPath := filepath.Join(currentDir, ".git")
 		
-		// Check if .git exists and is a directory (not a file, which could be a submodule)
+		// Check if .git exists and is a directory, or a gitfile as in linked
+		// worktrees and submodules
 		if info, err := os.Stat(gitPath); err == nil && info.IsDir() {
 			return gitPath
+		} else if err == nil {
+			if _, err := resolveGitDir(gitPath); err == nil {
+				return gitPath
+			}
 		}
 		
 		// Move to parent directory
@@ -108,4 +198,32 @@ func findGitDir(startDir string) string {
 	
 	// Not found
 	return ""
-}
\ No newline at end of file
+}
+// resolveGitDir returns the git directory a .git entry stands for: the
+// directory itself, or the one a gitfile ("gitdir: <path>") points to
+func resolveGitDir(dotGit string) (string, error) {
+	info, err := os.Stat(dotGit)
+	if err != nil {
+		return "", err
+	}
+	if info.IsDir() {
+		return dotGit, nil
+	}
+
+	content, err := os.ReadFile(dotGit)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", dotGit, err)
+	}
+	target, ok := strings.CutPrefix(strings.TrimSpace(string(content)), "gitdir: ")
+	if !ok {
+		return "", fmt.Errorf("%s is neither a directory nor a gitfile", dotGit)
+	}
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(filepath.Dir(dotGit), target)
+	}
+	target = filepath.Clean(target)
+	if info, err := os.Stat(target); err != nil || !info.IsDir() {
+		return "", fmt.Errorf("%s points to %s, which is not a git directory", dotGit, target)
+	}
+	return target, nil
+}
@@ -179,4 +179,66 @@ func TestFindGitDirNestedStructure(t *testing.T) {
 	if result != gitDir {
 		t.Errorf("Expected to find .git at %s from deeply nested dir, got %s", gitDir, result)
 	}
-}
\ No newline at end of file
+}
+func TestNewLightAppStateLinkedWorktree(t *testing.T) {
+	tempDir, _, gitManager := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+	snapshotFile(t, tempDir, gitManager, "main worktree")
+	projectGit(t, tempDir, "commit", "--allow-empty", "-m", "init")
+
+	parent, err := os.MkdirTemp("", "timemachine-worktree")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(parent)
+	worktree := filepath.Join(parent, "wt")
+	projectGit(t, tempDir, "worktree", "add", "-q", "-b", "feature", worktree)
+	subDir := filepath.Join(worktree, "src")
+	if err := os.Mkdir(subDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	state, err := NewLightAppStateAt(subDir)
+	if err != nil {
+		t.Fatalf("Expected a linked worktree to be found, got %v", err)
+	}
+	samePath := func(a, b string) bool {
+		a, _ = filepath.EvalSymlinks(a)
+		b, _ = filepath.EvalSymlinks(b)
+		return a == b
+	}
+	if !samePath(state.ProjectRoot, worktree) {
+		t.Errorf("Expected ProjectRoot %s, got %s", worktree, state.ProjectRoot)
+	}
+	if expected := filepath.Join(tempDir, ".git", "worktrees", "wt"); !samePath(state.GitDir, expected) {
+		t.Errorf("Expected GitDir %s, got %s", expected, state.GitDir)
+	}
+	if !samePath(CommonDir(state.GitDir), filepath.Join(tempDir, ".git")) {
+		t.Errorf("Expected the common dir to be the main repository's, got %s", CommonDir(state.GitDir))
+	}
+	if state.ShadowRepoDir != filepath.Join(state.GitDir, "timemachine_snapshots") || state.IsInitialized {
+		t.Errorf("Expected an uninitialized shadow repository of its own, got %s", state.ShadowRepoDir)
+	}
+
+	// Branches are shared with the main repository, HEAD is not
+	ref, head, err := ReadHead(state.GitDir)
+	mainHead := projectGit(t, tempDir, "rev-parse", "HEAD")
+	if err != nil || ref != "refs/heads/feature" || head != mainHead {
+		t.Errorf("Expected refs/heads/feature at %s, got %s at %s (%v)", mainHead, ref, head, err)
+	}
+
+	worktreeManager := NewGitManager(state)
+	if err := worktreeManager.InitializeShadowRepo(); err != nil {
+		t.Fatalf("InitializeShadowRepo failed: %v", err)
+	}
+	snapshotFile(t, worktree, worktreeManager, "linked worktree")
+	for _, check := range []struct {
+		manager  *GitManager
+		expected string
+	}{{gitManager, "main worktree"}, {worktreeManager, "linked worktree"}} {
+		snapshots, err := check.manager.ListSnapshots(0, "")
+		if err != nil || len(snapshots) != 1 || snapshots[0].Message != check.expected {
+			t.Errorf("Expected only %q, got %+v (%v)", check.expected, snapshots, err)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package core
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// RepositoryStats describes the storage of the shadow repository. Object
+// sizes come from git count-objects, which counts allocated disk blocks, so
+// they can exceed DiskBytes for many small loose objects.
+type RepositoryStats struct {
+	DiskBytes     int64 `json:"disk_bytes"` // Every file under the shadow repository
+	LooseObjects  int   `json:"loose_objects"`
+	LooseBytes    int64 `json:"loose_bytes"`
+	PackedObjects int   `json:"packed_objects"`
+	Packs         int   `json:"packs"`
+	PackBytes     int64 `json:"pack_bytes"`
+	PrunePackable int   `json:"prune_packable"` // Loose objects that are also packed
+	Garbage       int   `json:"garbage"`        // Files git doesn't recognize in the object store
+	GarbageBytes  int64 `json:"garbage_bytes"`
+	Snapshots     int   `json:"snapshots"` // On every shadow branch
+}
+
+// Objects returns the number of stored objects, loose and packed
+func (s *RepositoryStats) Objects() int {
+	return s.LooseObjects + s.PackedObjects
+}
+
+// StatsCollector gathers storage statistics about the shadow repository
+// with git and a native directory walk, so it works wherever git does
+type StatsCollector struct {
+	git *GitManager
+}
+
+// NewStatsCollector creates a collector for the shadow repository
+func NewStatsCollector(gitManager *GitManager) *StatsCollector {
+	return &StatsCollector{git: gitManager}
+}
+
+// Collect gathers every statistic
+func (c *StatsCollector) Collect() (*RepositoryStats, error) {
+	stats := &RepositoryStats{}
+
+	size, err := c.DiskUsage()
+	if err != nil {
+		return nil, err
+	}
+	stats.DiskBytes = size
+
+	if err := c.collectObjects(stats); err != nil {
+		return nil, err
+	}
+
+	count, err := c.git.RunCommand("rev-list", "--count", "--branches")
+	if err != nil {
+		return nil, fmt.Errorf("failed to count snapshots: %w", err)
+	}
+	if stats.Snapshots, err = strconv.Atoi(count); err != nil {
+		return nil, fmt.Errorf("unexpected rev-list output %q: %w", count, err)
+	}
+
+	return stats, nil
+}
+
+// DiskUsage returns the size of every file under the shadow repository
+func (c *StatsCollector) DiskUsage() (int64, error) {
+	return DirectorySize(c.git.State.ShadowRepoDir)
+}
+
+// collectObjects fills in the object counts from git count-objects
+func (c *StatsCollector) collectObjects(stats *RepositoryStats) error {
+	output, err := c.git.RunCommand("count-objects", "-v")
+	if err != nil {
+		return fmt.Errorf("failed to count objects: %w", err)
+	}
+	return parseCountObjects(output, stats)
+}
+
+// parseCountObjects parses `git count-objects -v`, whose sizes are in KiB
+func parseCountObjects(output string, stats *RepositoryStats) error {
+	for _, line := range strings.Split(output, "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), ": ")
+		if !ok {
+			continue
+		}
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("unexpected count-objects line %q", line)
+		}
+
+		switch key {
+		case "count":
+			stats.LooseObjects = int(n)
+		case "size":
+			stats.LooseBytes = n * 1024
+		case "in-pack":
+			stats.PackedObjects = int(n)
+		case "packs":
+			stats.Packs = int(n)
+		case "size-pack":
+			stats.PackBytes = n * 1024
+		case "prune-packable":
+			stats.PrunePackable = int(n)
+		case "garbage":
+			stats.Garbage = int(n)
+		case "size-garbage":
+			stats.GarbageBytes = n * 1024
+		}
+	}
+	return nil
+}
+
+// DirectorySize returns the total size of the files under dir. Entries that
+// can't be read are skipped.
+func DirectorySize(dir string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if path == dir {
+				return err
+			}
+			return nil
+		}
+		if entry.Type().IsRegular() {
+			if info, err := entry.Info(); err == nil {
+				size += info.Size()
+			}
+		}
+		return nil
+	})
+	return size, err
+}
@@ -0,0 +1,96 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseCountObjects(t *testing.T) {
+	output := `count: 12
+size: 48
+in-pack: 300
+packs: 2
+size-pack: 1024
+prune-packable: 3
+garbage: 1
+size-garbage: 4`
+
+	var stats RepositoryStats
+	if err := parseCountObjects(output, &stats); err != nil {
+		t.Fatalf("parseCountObjects failed: %v", err)
+	}
+
+	expected := RepositoryStats{
+		LooseObjects:  12,
+		LooseBytes:    48 * 1024,
+		PackedObjects: 300,
+		Packs:         2,
+		PackBytes:     1024 * 1024,
+		PrunePackable: 3,
+		Garbage:       1,
+		GarbageBytes:  4 * 1024,
+	}
+	if stats != expected {
+		t.Errorf("Expected %+v, got %+v", expected, stats)
+	}
+	if stats.Objects() != 312 {
+		t.Errorf("Expected 312 objects, got %d", stats.Objects())
+	}
+
+	if err := parseCountObjects("count: lots", &stats); err == nil {
+		t.Errorf("Expected an error for a non-numeric count")
+	}
+}
+
+func TestDirectorySize(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "a", "b"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "one"), make([]byte, 100), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "a", "b", "two"), make([]byte, 23), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	size, err := DirectorySize(dir)
+	if err != nil {
+		t.Fatalf("DirectorySize failed: %v", err)
+	}
+	if size != 123 {
+		t.Errorf("Expected 123 bytes, got %d", size)
+	}
+
+	if _, err := DirectorySize(filepath.Join(dir, "missing")); err == nil {
+		t.Errorf("Expected an error for a missing directory")
+	}
+}
+
+func TestStatsCollectorCollect(t *testing.T) {
+	tempDir, _, gitManager := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	snapshotFile(t, tempDir, gitManager, "one")
+	snapshotFile(t, tempDir, gitManager, "two")
+
+	stats, err := NewStatsCollector(gitManager).Collect()
+	if err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+
+	snapshots, err := gitManager.ListSnapshots(0, "")
+	if err != nil {
+		t.Fatalf("ListSnapshots failed: %v", err)
+	}
+	if stats.Snapshots != len(snapshots) {
+		t.Errorf("Expected %d snapshots, got %d", len(snapshots), stats.Snapshots)
+	}
+	if stats.Objects() == 0 {
+		t.Errorf("Expected stored objects, got %+v", stats)
+	}
+	if stats.DiskBytes == 0 {
+		t.Errorf("Expected the repository to take up space")
+	}
+}
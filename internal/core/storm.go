@@ -0,0 +1,90 @@
+package core
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// stormWindow is the span changes are counted over to detect a change storm
+const stormWindow = time.Second
+
+// stormSettle is how long a change storm must stay quiet to be over
+const stormSettle = 3 * time.Second
+
+// Change storm phases reported by change_storm events
+const (
+	StormStarted = "started" // Snapshots wait for the storm to subside
+	StormEnded   = "ended"   // One snapshot is taken of everything the storm changed
+)
+
+// noteChange counts a change towards change storm detection. During a storm
+// it reports true: the change waits for the single snapshot taken once the
+// storm subsides, which stages the whole tree. Only called by the event loop.
+func (w *Watcher) noteChange() bool {
+	now := time.Now()
+	if now.Sub(w.rateStart) >= stormWindow {
+		w.rateStart = now
+		w.rateChanges = 0
+	}
+	w.rateChanges++
+
+	if !w.storming {
+		w.mu.Lock()
+		threshold := w.stormThreshold
+		w.mu.Unlock()
+		if threshold <= 0 || w.rateChanges <= threshold {
+			return false
+		}
+		w.startStorm(threshold)
+	}
+	w.stormChanges++
+
+	if w.stormTimer == nil {
+		w.stormTimer = time.AfterFunc(stormSettle, func() {
+			select {
+			case w.stormDue <- struct{}{}:
+			default: // The end is already due
+			}
+		})
+	} else {
+		w.stormTimer.Reset(stormSettle)
+	}
+	return true
+}
+
+// startStorm defers snapshots until the changes subside: snapshotting
+// every batch of a 100k-file npm install or codegen run would keep git busy
+// for minutes
+func (w *Watcher) startStorm(threshold int) {
+	w.storming = true
+	w.stormChanges = w.rateChanges - 1
+	w.debouncer.Cancel()
+	w.mu.Lock()
+	w.rescan = true
+	w.mu.Unlock()
+
+	color.Yellow("🌪️  %s Change storm: over %d changes a second; snapshots wait until it subsides",
+		time.Now().Format("2006-01-02 15:04:05"), threshold)
+	w.emit(WatcherEvent{Type: EventChangeStorm, Action: StormStarted})
+}
+
+// endStorm takes one snapshot of everything a change storm changed, once it
+// stayed quiet for stormSettle. Only called by the event loop.
+func (w *Watcher) endStorm() {
+	if !w.storming {
+		return
+	}
+	w.storming = false
+	changes := w.stormChanges
+	w.stormChanges = 0
+
+	fmt.Printf("🌤️  %s Change storm over after %d changes; taking one snapshot\n",
+		time.Now().Format("2006-01-02 15:04:05"), changes)
+	w.emit(WatcherEvent{Type: EventChangeStorm, Action: StormEnded, Count: changes})
+	w.mu.Lock()
+	w.rescan = true
+	w.mu.Unlock()
+	w.debouncer.Flush(w.queueSnapshot)
+}
@@ -0,0 +1,63 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+func TestChangeStorm(t *testing.T) {
+	tempDir, state, gitManager := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	watcher, err := NewWatcher(state, gitManager)
+	if err != nil {
+		t.Fatalf("NewWatcher failed: %v", err)
+	}
+	defer watcher.fsWatcher.Close()
+	watcher.stormThreshold = 5
+	var events []WatcherEvent
+	watcher.SetEventHandler(func(event WatcherEvent) {
+		events = append(events, event)
+	})
+
+	for i := 0; i < 20; i++ {
+		file := filepath.Join(tempDir, fmt.Sprintf("gen%d.go", i))
+		if err := os.WriteFile(file, []byte("package gen\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		watcher.handleEvent(fsnotify.Event{Name: file, Op: fsnotify.Create})
+	}
+	watcher.stormTimer.Stop()
+
+	if !watcher.storming || watcher.debouncer.IsActive() {
+		t.Fatalf("Expected snapshots to wait for the storm (storming %v, snapshot pending %v)", watcher.storming, watcher.debouncer.IsActive())
+	}
+	if len(events) != 1 || events[0].Type != EventChangeStorm || events[0].Action != StormStarted {
+		t.Fatalf("Expected a change_storm started event, got %+v", events)
+	}
+
+	watcher.endStorm()
+	watcher.debouncer.Cancel()
+	watcher.createSnapshot()
+	if watcher.storming || len(events) < 2 || events[1].Action != StormEnded || events[1].Count != 20 {
+		t.Fatalf("Expected the storm to end after 20 changes, got %+v", events)
+	}
+	files, err := gitManager.SnapshotFiles("HEAD")
+	if err != nil || len(files) != 20 {
+		t.Errorf("Expected one snapshot of all 20 files, got %d files, %v", len(files), err)
+	}
+
+	// Below the threshold, changes are snapshotted as usual
+	watcher.rateStart = watcher.rateStart.Add(-stormWindow)
+	file := filepath.Join(tempDir, "main.go")
+	os.WriteFile(file, []byte("package main\n"), 0644)
+	watcher.handleEvent(fsnotify.Event{Name: file, Op: fsnotify.Create})
+	if watcher.storming || !watcher.debouncer.IsActive() {
+		t.Errorf("Expected a single change to schedule a snapshot")
+	}
+	watcher.debouncer.Cancel()
+}
@@ -0,0 +1,177 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ChangeSummary describes everything snapshots changed over a period, for a
+// PR description or commit message
+type ChangeSummary struct {
+	Since      string           `json:"since,omitempty"`
+	Start      time.Time        `json:"start"`
+	End        time.Time        `json:"end"`
+	Snapshots  int              `json:"snapshots"`
+	Insertions int              `json:"insertions"` // Churn: lines added across all snapshots
+	Deletions  int              `json:"deletions"`  // Churn: lines removed across all snapshots
+	Files      []SummaryFile    `json:"files"`
+	Sessions   []SummarySession `json:"sessions"`
+}
+
+// SummaryFile is one file touched during the period. Churn counts every
+// line written along the way; net only what differs from the start.
+type SummaryFile struct {
+	Path          string `json:"path"`
+	Status        string `json:"status"` // added, modified, deleted, renamed, or unchanged when edits cancelled out
+	Edits         int    `json:"edits"`  // Snapshots that changed it
+	Insertions    int    `json:"insertions"`
+	Deletions     int    `json:"deletions"`
+	NetInsertions int    `json:"net_insertions"`
+	NetDeletions  int    `json:"net_deletions"`
+	Binary        bool   `json:"binary,omitempty"`
+}
+
+// SummarySession is one work session on the timeline
+type SummarySession struct {
+	Start      time.Time `json:"start"`
+	End        time.Time `json:"end"`
+	Snapshots  int       `json:"snapshots"`
+	Files      int       `json:"files"`
+	Insertions int       `json:"insertions"`
+	Deletions  int       `json:"deletions"`
+	Messages   []string  `json:"messages"` // Distinct snapshot messages, oldest first
+}
+
+// Statuses of a SummaryFile
+const (
+	SummaryAdded     = "added"
+	SummaryModified  = "modified"
+	SummaryDeleted   = "deleted"
+	SummaryRenamed   = "renamed"
+	SummaryUnchanged = "unchanged"
+)
+
+// Summarize summarizes the snapshots taken since a commit of the project, an
+// age such as "2h", a date, or a snapshot; all of them when since is empty.
+// Sessions are runs of snapshots no more than gap apart.
+func (g *GitManager) Summarize(since string, gap time.Duration) (*ChangeSummary, error) {
+	bound := since
+	if since != "" && !strings.HasPrefix(since, "-") {
+		if _, err := ParseTimeBound(since, false); err != nil {
+			// A commit of the project, such as the branch a PR targets
+			if t, err := g.SinceProjectCommit(since); err == nil {
+				bound = t.Format(time.RFC3339)
+			}
+		}
+	}
+	snapshots, err := g.LogChanges(bound)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &ChangeSummary{Since: since, Snapshots: len(snapshots), Files: []SummaryFile{}, Sessions: []SummarySession{}}
+	if len(snapshots) == 0 {
+		return summary, nil
+	}
+	summary.Start = snapshots[0].Time
+	summary.End = snapshots[len(snapshots)-1].Time
+
+	edits := make(map[string]int)
+	for _, snapshot := range snapshots {
+		insertions, deletions := snapshot.Totals()
+		summary.Insertions += insertions
+		summary.Deletions += deletions
+		for _, file := range snapshot.Files {
+			edits[file.Path]++
+		}
+	}
+
+	net, err := g.netChanges(snapshots[0].Hash, snapshots[len(snapshots)-1].Hash)
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range AggregateFiles(snapshots) {
+		entry := SummaryFile{
+			Path:       file.Path,
+			Status:     SummaryUnchanged,
+			Edits:      edits[file.Path],
+			Insertions: file.Insertions,
+			Deletions:  file.Deletions,
+			Binary:     file.Binary,
+		}
+		if change, ok := net[file.Path]; ok {
+			entry.Status = change.Status
+			entry.NetInsertions = change.Insertions
+			entry.NetDeletions = change.Deletions
+		}
+		summary.Files = append(summary.Files, entry)
+	}
+
+	for _, group := range GroupSessions(snapshots, gap) {
+		session := SummarySession{Start: group[0].Time, End: group[len(group)-1].Time, Snapshots: len(group)}
+		session.Files = len(AggregateFiles(group))
+		seen := make(map[string]bool)
+		for _, snapshot := range group {
+			insertions, deletions := snapshot.Totals()
+			session.Insertions += insertions
+			session.Deletions += deletions
+			if !seen[snapshot.Message] {
+				seen[snapshot.Message] = true
+				session.Messages = append(session.Messages, snapshot.Message)
+			}
+		}
+		summary.Sessions = append(summary.Sessions, session)
+	}
+	return summary, nil
+}
+
+// netChanges diffs the state before first against last, by path
+func (g *GitManager) netChanges(first, last string) (map[string]FileChange, error) {
+	base, err := g.RunCommand("rev-parse", "--verify", "--quiet", first+"^")
+	if err != nil {
+		// The first snapshot ever: everything in it was added
+		tree, err := g.runCommandInput("", "mktree")
+		if err != nil {
+			return nil, fmt.Errorf("failed to summarize changes: %w", err)
+		}
+		base = strings.TrimSpace(string(tree))
+	}
+	// Renames are detected as git log detects them, so paths line up
+	output, err := g.RunCommand("diff", "--numstat", "--summary", base, last, "--")
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize changes: %w", err)
+	}
+
+	changes := make(map[string]FileChange)
+	var created, deleted []string
+	for _, line := range strings.Split(output, "\n") {
+		if file, ok := parseNumstatLine(line); ok {
+			file.Status = SummaryModified
+			if strings.Contains(file.Path, " => ") {
+				file.Status = SummaryRenamed
+			}
+			changes[file.Path] = file
+			continue
+		}
+		// " create mode 100644 path" and " delete mode 100644 path"
+		fields := strings.SplitN(strings.TrimSpace(line), " ", 4)
+		if len(fields) == 4 && fields[1] == "mode" {
+			switch fields[0] {
+			case "create":
+				created = append(created, fields[3])
+			case "delete":
+				deleted = append(deleted, fields[3])
+			}
+		}
+	}
+	for status, paths := range map[string][]string{SummaryAdded: created, SummaryDeleted: deleted} {
+		for _, path := range paths {
+			if file, ok := changes[path]; ok {
+				file.Status = status
+				changes[path] = file
+			}
+		}
+	}
+	return changes, nil
+}
@@ -0,0 +1,71 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSummarize(t *testing.T) {
+	tempDir, _, gitManager := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(tempDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+	snapshot := func(message string) string {
+		t.Helper()
+		if err := gitManager.CreateSnapshot(message); err != nil {
+			t.Fatalf("CreateSnapshot failed: %v", err)
+		}
+		hash, _ := gitManager.RunCommand("rev-parse", "HEAD")
+		return hash
+	}
+
+	write("a.txt", "one\n")
+	first := snapshot("Start")
+	write("a.txt", "one\ntwo\n")
+	write("scratch.txt", "temporary\n")
+	snapshot("Edit")
+	os.Remove(filepath.Join(tempDir, "scratch.txt"))
+	write("a.txt", "one\nTWO\nthree\n")
+	snapshot("Edit")
+
+	summary, err := gitManager.Summarize(first, DefaultSessionGap)
+	if err != nil {
+		t.Fatalf("Summarize failed: %v", err)
+	}
+	if summary.Snapshots != 2 || len(summary.Sessions) != 1 {
+		t.Fatalf("Expected 2 snapshots in 1 session, got %+v", summary)
+	}
+	if messages := summary.Sessions[0].Messages; len(messages) != 1 || messages[0] != "Edit" {
+		t.Errorf("Expected repeated messages once, got %v", messages)
+	}
+
+	files := make(map[string]SummaryFile)
+	for _, file := range summary.Files {
+		files[file.Path] = file
+	}
+	a := files["a.txt"]
+	if a.Status != SummaryModified || a.Edits != 2 || a.Insertions != 3 || a.Deletions != 1 || a.NetInsertions != 2 || a.NetDeletions != 0 {
+		t.Errorf("Unexpected a.txt %+v", a)
+	}
+	// Written and removed again: churn, but nothing net
+	if scratch := files["scratch.txt"]; scratch.Status != SummaryUnchanged || scratch.Edits != 2 || scratch.NetInsertions != 0 {
+		t.Errorf("Unexpected scratch.txt %+v", scratch)
+	}
+
+	// From the first snapshot on, everything was added
+	summary, err = gitManager.Summarize("", DefaultSessionGap)
+	if err != nil {
+		t.Fatalf("Summarize failed: %v", err)
+	}
+	for _, file := range summary.Files {
+		if file.Path == "a.txt" && file.Status != SummaryAdded {
+			t.Errorf("Expected a.txt added, got %+v", file)
+		}
+	}
+}
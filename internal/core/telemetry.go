@@ -0,0 +1,318 @@
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/config"
+)
+
+// TelemetryFile is where usage events are appended, in the shadow
+// repository. When it outgrows telemetryMaxBytes it is moved to
+// TelemetryFile+".1", replacing the previous one.
+const TelemetryFile = "telemetry.jsonl"
+
+// telemetryMaxBytes bounds the size of the current telemetry file
+const telemetryMaxBytes = 1 << 20
+
+// Kinds of telemetry events
+const (
+	TelemetryCommand  = "command"
+	TelemetrySnapshot = "snapshot"
+)
+
+// TelemetryEvent is one line of the telemetry file. It never holds paths,
+// arguments or snapshot contents.
+type TelemetryEvent struct {
+	Time       time.Time `json:"time"`
+	Kind       string    `json:"kind"`
+	Name       string    `json:"name,omitempty"` // Command path, such as "timemachine list"
+	DurationMS int64     `json:"duration_ms"`
+	Files      int       `json:"files,omitempty"` // Changed paths in a snapshot
+	Error      string    `json:"error,omitempty"` // Category, see ErrorCategory
+}
+
+// errorCategories name the exit codes in telemetry
+var errorCategories = map[int]string{
+	ExitFailure:        "other",
+	ExitNotInitialized: "not_initialized",
+	ExitNotGitRepo:     "not_git_repository",
+	ExitValidation:     "validation",
+	ExitGit:            "git",
+	ExitNotFound:       "not_found",
+	ExitLowDiskSpace:   "low_disk_space",
+	ExitSecrets:        "secrets",
+	ExitBusy:           "busy",
+}
+
+// ErrorCategory names the kind of an error, "" for nil. Only the kind is
+// recorded, never the message, which may contain paths.
+func ErrorCategory(err error) string {
+	if err == nil {
+		return ""
+	}
+	return errorCategories[ExitCode(err)]
+}
+
+// telemetryDir is the shadow repository of the last AppState loaded with
+// telemetry enabled, where RecordCommand writes; empty when it is off
+var telemetryDir string
+
+// applyTelemetryConfig lets RecordCommand write for the repository of a
+// loaded configuration
+func applyTelemetryConfig(state *AppState) {
+	if state.Config != nil && state.Config.Telemetry.Enabled {
+		telemetryDir = state.ShadowRepoDir
+	} else {
+		telemetryDir = ""
+	}
+}
+
+// RecordCommand records a finished command when the configuration loaded
+// while it ran enables telemetry. Commands that never load it, such as
+// prompt, aren't recorded.
+func RecordCommand(name string, duration time.Duration, err error) {
+	if telemetryDir == "" {
+		return
+	}
+	AppendTelemetry(telemetryDir, TelemetryEvent{
+		Time:       time.Now(),
+		Kind:       TelemetryCommand,
+		Name:       name,
+		DurationMS: duration.Milliseconds(),
+		Error:      ErrorCategory(err),
+	})
+}
+
+// recordSnapshotTelemetry records a snapshot attempt of the watcher, when
+// the current configuration enables telemetry
+func (w *Watcher) recordSnapshotTelemetry(duration time.Duration, files int, err error) {
+	if !w.telemetryEnabled() {
+		return
+	}
+	AppendTelemetry(w.state.ShadowRepoDir, TelemetryEvent{
+		Time:       time.Now(),
+		Kind:       TelemetrySnapshot,
+		DurationMS: duration.Milliseconds(),
+		Files:      files,
+		Error:      ErrorCategory(err),
+	})
+}
+
+// telemetryEnabled follows reloads of the configuration
+func (w *Watcher) telemetryEnabled() bool {
+	return w.state.Config != nil && w.state.Config.Telemetry.Enabled
+}
+
+// AppendTelemetry appends an event to the telemetry file of a shadow
+// repository. Telemetry must never get in the way, so failures are ignored;
+// a missing shadow repository records nothing.
+func AppendTelemetry(shadowDir string, event TelemetryEvent) {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	path := filepath.Join(shadowDir, TelemetryFile)
+	if info, err := os.Stat(path); err == nil && info.Size() >= telemetryMaxBytes {
+		os.Rename(path, path+".1")
+	}
+	// Appends of a single short line don't interleave, so the watcher and
+	// commands can record at the same time
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return
+	}
+	file.Write(append(line, '\n'))
+	file.Close()
+}
+
+// CommandUsage sums the runs of one command
+type CommandUsage struct {
+	Name     string `json:"name"`
+	Count    int    `json:"count"`
+	Failures int    `json:"failures"`
+}
+
+// SnapshotUsage sums the watcher's snapshot attempts. Latencies cover
+// successful snapshots; Interval is the median time between consecutive
+// ones, gaps over an hour excluded as pauses between sessions.
+type SnapshotUsage struct {
+	Count        int     `json:"count"`
+	Failures     int     `json:"failures"`
+	LatencyP50MS int64   `json:"latency_p50_ms"`
+	LatencyP95MS int64   `json:"latency_p95_ms"`
+	LatencyMaxMS int64   `json:"latency_max_ms"`
+	AverageFiles float64 `json:"average_files"`
+	IntervalMS   int64   `json:"interval_p50_ms"`
+	PerDay       float64 `json:"per_day"`
+}
+
+// UsageStats summarizes the telemetry of a repository. It holds counts and
+// durations only, so it is what stats --send may post.
+type UsageStats struct {
+	Since     time.Time      `json:"since"`
+	Until     time.Time      `json:"until"`
+	Events    int            `json:"events"`
+	Commands  []CommandUsage `json:"commands"` // Most used first
+	Errors    map[string]int `json:"errors"`   // By category, commands and snapshots
+	Snapshots SnapshotUsage  `json:"snapshots"`
+}
+
+// sessionGapLimit is the longest gap still counted as a snapshot interval
+const sessionGapLimit = time.Hour
+
+// ReadUsageStats summarizes the telemetry files of a shadow repository,
+// the rotated one included. Lines that don't parse are skipped.
+func ReadUsageStats(shadowDir string) (*UsageStats, error) {
+	var events []TelemetryEvent
+	path := filepath.Join(shadowDir, TelemetryFile)
+	for _, name := range []string{path + ".1", path} {
+		file, err := os.Open(name)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read telemetry: %w", err)
+		}
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			var event TelemetryEvent
+			if json.Unmarshal(scanner.Bytes(), &event) == nil {
+				events = append(events, event)
+			}
+		}
+		file.Close()
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read telemetry: %w", err)
+		}
+	}
+	return SummarizeTelemetry(events), nil
+}
+
+// SummarizeTelemetry sums events into UsageStats
+func SummarizeTelemetry(events []TelemetryEvent) *UsageStats {
+	stats := &UsageStats{Events: len(events), Errors: make(map[string]int)}
+	commands := make(map[string]*CommandUsage)
+	var latencies []int64
+	var snapshotTimes []time.Time
+	files := 0
+
+	for _, event := range events {
+		if stats.Since.IsZero() || event.Time.Before(stats.Since) {
+			stats.Since = event.Time
+		}
+		if event.Time.After(stats.Until) {
+			stats.Until = event.Time
+		}
+		if event.Error != "" {
+			stats.Errors[event.Error]++
+		}
+
+		switch event.Kind {
+		case TelemetryCommand:
+			usage := commands[event.Name]
+			if usage == nil {
+				usage = &CommandUsage{Name: event.Name}
+				commands[event.Name] = usage
+			}
+			usage.Count++
+			if event.Error != "" {
+				usage.Failures++
+			}
+		case TelemetrySnapshot:
+			if event.Error != "" {
+				stats.Snapshots.Failures++
+				continue
+			}
+			stats.Snapshots.Count++
+			latencies = append(latencies, event.DurationMS)
+			snapshotTimes = append(snapshotTimes, event.Time)
+			files += event.Files
+		}
+	}
+
+	for _, usage := range commands {
+		stats.Commands = append(stats.Commands, *usage)
+	}
+	sort.Slice(stats.Commands, func(i, j int) bool {
+		if stats.Commands[i].Count != stats.Commands[j].Count {
+			return stats.Commands[i].Count > stats.Commands[j].Count
+		}
+		return stats.Commands[i].Name < stats.Commands[j].Name
+	})
+
+	if n := len(latencies); n > 0 {
+		sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+		stats.Snapshots.LatencyP50MS = percentile(latencies, 50)
+		stats.Snapshots.LatencyP95MS = percentile(latencies, 95)
+		stats.Snapshots.LatencyMaxMS = latencies[n-1]
+		stats.Snapshots.AverageFiles = float64(files) / float64(n)
+	}
+
+	sort.Slice(snapshotTimes, func(i, j int) bool { return snapshotTimes[i].Before(snapshotTimes[j]) })
+	var intervals []int64
+	for i := 1; i < len(snapshotTimes); i++ {
+		if gap := snapshotTimes[i].Sub(snapshotTimes[i-1]); gap <= sessionGapLimit {
+			intervals = append(intervals, gap.Milliseconds())
+		}
+	}
+	if len(intervals) > 0 {
+		sort.Slice(intervals, func(i, j int) bool { return intervals[i] < intervals[j] })
+		stats.Snapshots.IntervalMS = percentile(intervals, 50)
+	}
+	if days := stats.Until.Sub(stats.Since).Hours() / 24; days >= 1 {
+		stats.Snapshots.PerDay = float64(stats.Snapshots.Count) / days
+	} else if stats.Snapshots.Count > 0 {
+		stats.Snapshots.PerDay = float64(stats.Snapshots.Count)
+	}
+	return stats
+}
+
+// percentile returns the nearest-rank percentile p of sorted values
+func percentile(sorted []int64, p int) int64 {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// ResetTelemetry deletes the telemetry files of a shadow repository
+func ResetTelemetry(shadowDir string) error {
+	path := filepath.Join(shadowDir, TelemetryFile)
+	for _, name := range []string{path, path + ".1"} {
+		if err := os.Remove(name); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to reset telemetry: %w", err)
+		}
+	}
+	return nil
+}
+
+// SendUsageStats posts stats as JSON to the configured telemetry endpoint.
+// Nothing is sent without one.
+func SendUsageStats(cfg config.TelemetryConfig, stats *UsageStats) error {
+	if cfg.Endpoint == "" {
+		return NewValidationError("telemetry.endpoint is not set; usage statistics never leave this machine without one")
+	}
+	body, err := json.Marshal(stats)
+	if err != nil {
+		return err
+	}
+	client := &http.Client{Timeout: 10 * time.Second}
+	response, err := client.Post(cfg.Endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to send usage statistics: %w", err)
+	}
+	response.Body.Close()
+	if response.StatusCode >= 300 {
+		return fmt.Errorf("failed to send usage statistics: %s answered %s", cfg.Endpoint, response.Status)
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package core
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSummarizeTelemetry(t *testing.T) {
+	start := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) time.Time { return start.Add(d) }
+	events := []TelemetryEvent{
+		{Time: at(0), Kind: TelemetryCommand, Name: "timemachine list"},
+		{Time: at(time.Minute), Kind: TelemetryCommand, Name: "timemachine show", Error: "not_found"},
+		{Time: at(2 * time.Minute), Kind: TelemetryCommand, Name: "timemachine list"},
+		{Time: at(10 * time.Second), Kind: TelemetrySnapshot, DurationMS: 100, Files: 2},
+		{Time: at(20 * time.Second), Kind: TelemetrySnapshot, DurationMS: 300, Files: 4},
+		{Time: at(50 * time.Second), Kind: TelemetrySnapshot, DurationMS: 200, Files: 6},
+		{Time: at(3 * time.Hour), Kind: TelemetrySnapshot, DurationMS: 900, Files: 0},
+		{Time: at(3*time.Hour + time.Second), Kind: TelemetrySnapshot, DurationMS: 50, Error: "git"},
+	}
+
+	stats := SummarizeTelemetry(events)
+	if stats.Events != len(events) || !stats.Since.Equal(start) || !stats.Until.Equal(at(3*time.Hour+time.Second)) {
+		t.Errorf("unexpected range: %d events from %v to %v", stats.Events, stats.Since, stats.Until)
+	}
+	if len(stats.Commands) != 2 || stats.Commands[0] != (CommandUsage{Name: "timemachine list", Count: 2}) ||
+		stats.Commands[1] != (CommandUsage{Name: "timemachine show", Count: 1, Failures: 1}) {
+		t.Errorf("unexpected commands: %+v", stats.Commands)
+	}
+	if stats.Errors["not_found"] != 1 || stats.Errors["git"] != 1 || len(stats.Errors) != 2 {
+		t.Errorf("unexpected errors: %v", stats.Errors)
+	}
+
+	snapshots := stats.Snapshots
+	if snapshots.Count != 4 || snapshots.Failures != 1 {
+		t.Errorf("expected 4 snapshots and 1 failure, got %+v", snapshots)
+	}
+	if snapshots.LatencyP50MS != 200 || snapshots.LatencyP95MS != 900 || snapshots.LatencyMaxMS != 900 {
+		t.Errorf("unexpected latencies: %+v", snapshots)
+	}
+	if snapshots.AverageFiles != 3 {
+		t.Errorf("expected 3 files on average, got %v", snapshots.AverageFiles)
+	}
+	// Intervals of 10s and 30s; the gap of almost 3 hours is a pause
+	if snapshots.IntervalMS != 10000 {
+		t.Errorf("expected a median interval of 10s, got %dms", snapshots.IntervalMS)
+	}
+}
+
+func TestTelemetryFile(t *testing.T) {
+	dir := t.TempDir()
+
+	stats, err := ReadUsageStats(dir)
+	if err != nil || stats.Events != 0 {
+		t.Fatalf("expected no events without a telemetry file, got %+v, %v", stats, err)
+	}
+
+	AppendTelemetry(dir, TelemetryEvent{Time: time.Now(), Kind: TelemetryCommand, Name: "timemachine list"})
+	// A rotated file is read too, before the current one
+	path := filepath.Join(dir, TelemetryFile)
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	AppendTelemetry(dir, TelemetryEvent{Time: time.Now(), Kind: TelemetrySnapshot, DurationMS: 5})
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	f.WriteString("not json\n")
+	f.Close()
+
+	stats, err = ReadUsageStats(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Events != 2 || stats.Snapshots.Count != 1 || len(stats.Commands) != 1 {
+		t.Errorf("expected a command and a snapshot, got %+v", stats)
+	}
+
+	if err := ResetTelemetry(dir); err != nil {
+		t.Fatal(err)
+	}
+	if stats, _ := ReadUsageStats(dir); stats.Events != 0 {
+		t.Errorf("expected no events after a reset, got %d", stats.Events)
+	}
+
+	// A missing shadow repository records nothing and doesn't fail
+	AppendTelemetry(filepath.Join(dir, "missing"), TelemetryEvent{Kind: TelemetryCommand})
+}
+
+func TestErrorCategory(t *testing.T) {
+	if ErrorCategory(nil) != "" {
+		t.Error("nil should have no category")
+	}
+	err := NewValidationError("invalid snapshot hash %q", "/home/user/secret")
+	if category := ErrorCategory(err); category != "validation" || strings.Contains(category, "secret") {
+		t.Errorf("unexpected category %q", category)
+	}
+	if category := ErrorCategory(errors.New("boom")); category != "other" {
+		t.Errorf("expected other, got %q", category)
+	}
+}
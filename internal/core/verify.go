@@ -0,0 +1,278 @@
+package core
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// IntegrityFileName holds the latest integrity check result in the shadow repo
+const IntegrityFileName = "integrity.json"
+
+// Defaults for git.verify_interval and git.verify_sample
+const (
+	DefaultVerifyInterval = 6 * time.Hour
+	DefaultVerifySample   = 200
+)
+
+// IntegrityReport is the outcome of an integrity check. It is persisted so
+// that corruption found in the background stays visible in status until a
+// full check passes.
+type IntegrityReport struct {
+	CheckedAt      time.Time `json:"checked_at"`
+	Full           bool      `json:"full"`
+	VerifiedHead   string    `json:"verified_head,omitempty"` // Everything reachable from here has been hashed
+	ObjectsChecked int       `json:"objects_checked"`
+	Problems       []string  `json:"problems,omitempty"`
+	DetectedAt     time.Time `json:"detected_at,omitempty"` // When the current problems were first found
+}
+
+// Corrupt reports whether the check found problems
+func (r *IntegrityReport) Corrupt() bool {
+	return len(r.Problems) > 0
+}
+
+// verifySettings returns how often the watcher checks integrity (0 disables
+// it) and how many older objects each check samples
+func (g *GitManager) verifySettings() (time.Duration, int) {
+	if g.State.Config == nil {
+		return DefaultVerifyInterval, DefaultVerifySample
+	}
+	return g.State.Config.Git.VerifyInterval, g.State.Config.Git.VerifySample
+}
+
+// integrityFile returns the path of the persisted integrity report
+func (g *GitManager) integrityFile() string {
+	return filepath.Join(g.State.ShadowRepoDir, IntegrityFileName)
+}
+
+// ReadIntegrityReport loads the latest integrity report; it returns
+// os.ErrNotExist (wrapped) when no check has run yet
+func (g *GitManager) ReadIntegrityReport() (*IntegrityReport, error) {
+	content, err := os.ReadFile(g.integrityFile())
+	if err != nil {
+		return nil, err
+	}
+
+	var report IntegrityReport
+	if err := json.Unmarshal(content, &report); err != nil {
+		return nil, fmt.Errorf("invalid integrity report: %w", err)
+	}
+	return &report, nil
+}
+
+// writeIntegrityReport atomically replaces the persisted integrity report
+func (g *GitManager) writeIntegrityReport(report *IntegrityReport) error {
+	content, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode integrity report: %w", err)
+	}
+
+	tmpFile := g.integrityFile() + ".tmp"
+	if err := os.WriteFile(tmpFile, content, 0644); err != nil {
+		return fmt.Errorf("failed to write integrity report: %w", err)
+	}
+	if err := os.Rename(tmpFile, g.integrityFile()); err != nil {
+		os.Remove(tmpFile)
+		return fmt.Errorf("failed to replace integrity report: %w", err)
+	}
+	return nil
+}
+
+// VerifyIntegrity checks the shadow repository for corruption and persists
+// the result.
+//
+// A full check runs `git fsck --full`, which reads every object. Otherwise
+// the check is incremental and cheap enough for the watcher: it confirms
+// every snapshot's objects exist, re-hashes the objects added since the last
+// clean check, and re-hashes a random sample of older ones, so silent
+// corruption of old snapshots is found over time rather than during an
+// emergency restore. Problems found by an incremental check are kept until
+// a full check passes, since the next sample may well miss them.
+func (g *GitManager) VerifyIntegrity(full bool, sample int) (*IntegrityReport, error) {
+	previous, _ := g.ReadIntegrityReport()
+	report := &IntegrityReport{CheckedAt: time.Now().UTC(), Full: full}
+
+	head, err := g.RunCommand("rev-parse", "--verify", "--quiet", "HEAD")
+	if err != nil {
+		// No snapshots yet, nothing to check
+		return report, g.writeIntegrityReport(report)
+	}
+
+	if full {
+		err = g.verifyFull(report)
+	} else {
+		err = g.verifyIncremental(report, head, previous, sample)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if !full && previous != nil && previous.Corrupt() {
+		report.Problems = appendUnique(previous.Problems, report.Problems...)
+	}
+	switch {
+	case !report.Corrupt():
+		report.VerifiedHead = head
+	case previous != nil && previous.Corrupt():
+		report.DetectedAt = previous.DetectedAt
+	default:
+		report.DetectedAt = report.CheckedAt
+	}
+
+	if err := g.writeIntegrityReport(report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// verifyFull reads and checks every object with git fsck
+func (g *GitManager) verifyFull(report *IntegrityReport) error {
+	if objects, err := g.listObjects("--all"); err == nil {
+		report.ObjectsChecked = len(objects)
+	}
+	if _, err := g.RunCommand("fsck", "--full", "--strict", "--no-dangling", "--no-progress"); err != nil {
+		report.Problems = fsckProblems(err)
+	}
+	return nil
+}
+
+// verifyIncremental checks connectivity, then re-hashes new objects and a
+// random sample of the rest
+func (g *GitManager) verifyIncremental(report *IntegrityReport, head string, previous *IntegrityReport, sample int) error {
+	if _, err := g.RunCommand("fsck", "--connectivity-only", "--no-dangling", "--no-progress"); err != nil {
+		report.Problems = fsckProblems(err)
+	}
+
+	all, err := g.listObjects("--all")
+	if err != nil {
+		report.Problems = appendUnique(report.Problems, err.Error())
+		return nil
+	}
+
+	// Objects of snapshots taken since the last clean check; if that
+	// snapshot is gone (pruned, imported over), everything counts as new
+	newObjects := all
+	if previous != nil && previous.VerifiedHead != "" {
+		if _, err := g.RunCommand("cat-file", "-e", previous.VerifiedHead+"^{commit}"); err == nil {
+			if newObjects, err = g.listObjects("--all", "^"+previous.VerifiedHead); err != nil {
+				return err
+			}
+		}
+	}
+
+	isNew := make(map[string]bool, len(newObjects))
+	for _, object := range newObjects {
+		isNew[object] = true
+	}
+	var older []string
+	for _, object := range all {
+		if !isNew[object] {
+			older = append(older, object)
+		}
+	}
+	rand.Shuffle(len(older), func(i, j int) { older[i], older[j] = older[j], older[i] })
+	if sample < len(older) {
+		older = older[:sample]
+	}
+
+	toCheck := append(newObjects, older...)
+	report.ObjectsChecked = len(toCheck)
+	problems, err := g.hashObjects(toCheck)
+	if err != nil {
+		return err
+	}
+	report.Problems = appendUnique(report.Problems, problems...)
+	return nil
+}
+
+// listObjects lists the objects reachable from the given revisions
+func (g *GitManager) listObjects(revs ...string) ([]string, error) {
+	output, err := g.RunCommand(append([]string{"rev-list", "--objects", "--no-object-names"}, revs...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshot objects: %w", err)
+	}
+	return strings.Fields(output), nil
+}
+
+// hashObjects reads objects through `git cat-file --batch` and recomputes
+// their hashes, returning a problem for every missing or damaged object
+func (g *GitManager) hashObjects(objects []string) ([]string, error) {
+	if len(objects) == 0 {
+		return nil, nil
+	}
+
+	format, err := g.RunCommand("rev-parse", "--show-object-format")
+	if err != nil {
+		format = "sha1"
+	}
+	newHash := sha1.New
+	if format == "sha256" {
+		newHash = sha256.New
+	}
+
+	var problems []string
+	err = g.batchObjects(objects, func(i int, objectType string, content []byte, err error) error {
+		object := objects[i]
+		switch {
+		case err == errObjectMissing:
+			problems = append(problems, fmt.Sprintf("missing object %s", object))
+		case err != nil:
+			problems = append(problems, fmt.Sprintf("unreadable object %s: %v", object, err))
+		default:
+			if sum := objectHash(newHash(), objectType, content); sum != object {
+				problems = append(problems, fmt.Sprintf("hash mismatch for %s object %s (content hashes to %s)", objectType, object, sum))
+			}
+		}
+		return nil
+	})
+	return problems, err
+}
+
+// objectHash computes a git object id from its type and content
+func objectHash(h hash.Hash, objectType string, content []byte) string {
+	h.Write([]byte(objectType + " " + strconv.Itoa(len(content)) + "\x00"))
+	h.Write(content)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// fsckProblems turns the output of a failed git fsck into problem lines
+func fsckProblems(err error) []string {
+	var problems []string
+	_, output, _ := strings.Cut(err.Error(), "Output: ")
+	for _, line := range strings.Split(output, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			problems = append(problems, line)
+		}
+	}
+	if len(problems) == 0 {
+		problems = append(problems, err.Error())
+	}
+	return problems
+}
+
+// appendUnique appends the items not already in list
+func appendUnique(list []string, items ...string) []string {
+	for _, item := range items {
+		found := false
+		for _, existing := range list {
+			if existing == item {
+				found = true
+				break
+			}
+		}
+		if !found {
+			list = append(list, item)
+		}
+	}
+	return list
+}
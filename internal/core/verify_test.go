@@ -0,0 +1,149 @@
+package core
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// looseObjectPath returns where git stores a loose object
+func looseObjectPath(gitManager *GitManager, object string) string {
+	return filepath.Join(gitManager.State.ShadowRepoDir, "objects", object[:2], object[2:])
+}
+
+// replaceLooseObject overwrites a loose object with the given raw content
+func replaceLooseObject(t *testing.T, path string, raw []byte) {
+	var compressed bytes.Buffer
+	zw := zlib.NewWriter(&compressed)
+	zw.Write(raw)
+	zw.Close()
+
+	os.Chmod(path, 0644)
+	if err := os.WriteFile(path, compressed.Bytes(), 0644); err != nil {
+		t.Fatalf("Failed to replace object: %v", err)
+	}
+}
+
+func TestVerifyIntegrityIncremental(t *testing.T) {
+	tempDir, _, gitManager := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	snapshotFile(t, tempDir, gitManager, "v1")
+
+	report, err := gitManager.VerifyIntegrity(false, DefaultVerifySample)
+	if err != nil {
+		t.Fatalf("VerifyIntegrity failed: %v", err)
+	}
+	if report.Corrupt() {
+		t.Fatalf("Expected a clean repository, got %v", report.Problems)
+	}
+	head, _ := gitManager.RunCommand("rev-parse", "HEAD")
+	if report.VerifiedHead != head {
+		t.Errorf("Expected verified head %s, got %s", head, report.VerifiedHead)
+	}
+	// commit, tree and blob
+	if report.ObjectsChecked != 3 {
+		t.Errorf("Expected 3 objects checked, got %d", report.ObjectsChecked)
+	}
+
+	// Only the new snapshot's objects are hashed without a sample
+	snapshotFile(t, tempDir, gitManager, "v2")
+	report, err = gitManager.VerifyIntegrity(false, 0)
+	if err != nil {
+		t.Fatalf("VerifyIntegrity failed: %v", err)
+	}
+	if report.ObjectsChecked != 3 {
+		t.Errorf("Expected only the 3 new objects to be checked, got %d", report.ObjectsChecked)
+	}
+
+	// The report is persisted for status
+	saved, err := gitManager.ReadIntegrityReport()
+	if err != nil || saved.ObjectsChecked != report.ObjectsChecked {
+		t.Errorf("Expected persisted report %+v, got %+v (%v)", report, saved, err)
+	}
+}
+
+func TestVerifyIntegrityDetectsCorruption(t *testing.T) {
+	tempDir, _, gitManager := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	snapshotFile(t, tempDir, gitManager, "original")
+	blob, _ := gitManager.RunCommand("rev-parse", "HEAD:file.txt")
+	path := looseObjectPath(gitManager, blob)
+	original, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read object: %v", err)
+	}
+
+	// Bit rot: the object still inflates, but to different content
+	replaceLooseObject(t, path, []byte("blob 8\x00damaged!"))
+
+	report, err := gitManager.VerifyIntegrity(false, DefaultVerifySample)
+	if err != nil {
+		t.Fatalf("VerifyIntegrity failed: %v", err)
+	}
+	if !report.Corrupt() || !strings.Contains(strings.Join(report.Problems, "\n"), blob) {
+		t.Fatalf("Expected a problem naming %s, got %v", blob, report.Problems)
+	}
+	if report.DetectedAt.IsZero() || report.VerifiedHead != "" {
+		t.Errorf("Expected detection time and no verified head, got %+v", report)
+	}
+
+	// A later quick check that doesn't look at the object keeps the alert
+	os.Chmod(path, 0644)
+	os.WriteFile(path, original, 0444)
+	next, err := gitManager.VerifyIntegrity(false, 0)
+	if err != nil {
+		t.Fatalf("VerifyIntegrity failed: %v", err)
+	}
+	if !next.Corrupt() || !next.DetectedAt.Equal(report.DetectedAt) {
+		t.Errorf("Expected the alert to persist, got %+v", next)
+	}
+
+	// A passing full check clears it
+	full, err := gitManager.VerifyIntegrity(true, 0)
+	if err != nil {
+		t.Fatalf("VerifyIntegrity failed: %v", err)
+	}
+	if full.Corrupt() {
+		t.Errorf("Expected full check to pass after repair, got %v", full.Problems)
+	}
+}
+
+func TestVerifyIntegrityMissingObject(t *testing.T) {
+	tempDir, _, gitManager := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	snapshotFile(t, tempDir, gitManager, "v1")
+	blob, _ := gitManager.RunCommand("rev-parse", "HEAD:file.txt")
+	if err := os.Remove(looseObjectPath(gitManager, blob)); err != nil {
+		t.Fatalf("Failed to remove object: %v", err)
+	}
+
+	for _, full := range []bool{false, true} {
+		report, err := gitManager.VerifyIntegrity(full, DefaultVerifySample)
+		if err != nil {
+			t.Fatalf("VerifyIntegrity(full=%v) failed: %v", full, err)
+		}
+		if !report.Corrupt() {
+			t.Errorf("VerifyIntegrity(full=%v): expected missing object to be reported", full)
+		}
+	}
+}
+
+func TestFsckProblems(t *testing.T) {
+	err := errors.New("git command failed: exit status 2\nOutput: missing blob 1234\nbroken link from tree 5678\n")
+	problems := fsckProblems(err)
+	if len(problems) != 2 || problems[0] != "missing blob 1234" {
+		t.Errorf("Expected 2 problems from fsck output, got %q", problems)
+	}
+
+	problems = fsckProblems(errors.New("git command failed: exit status 2\nOutput: "))
+	if len(problems) != 1 || !strings.Contains(problems[0], "exit status 2") {
+		t.Errorf("Expected the error itself without output, got %q", problems)
+	}
+}
@@ -1,9 +1,13 @@
 package core
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -11,6 +15,26 @@ import (
 	"github.com/fsnotify/fsnotify"
 )
 
+// integrityWarmup is the minimum delay before the first background integrity
+// check, so it never competes with the initial snapshot
+const integrityWarmup = time.Minute
+
+// retentionPassInterval is how often the watcher applies the retention policy
+// after a snapshot
+const retentionPassInterval = time.Hour
+
+// fullScanInterval is how often a snapshot stages the whole tree instead of
+// only the changed paths, reconciling changes the file events missed
+const fullScanInterval = 10 * time.Minute
+
+// ignoreReloadDelay lets an editor finish saving an ignore file (often
+// several events) before the patterns are reloaded
+const ignoreReloadDelay = 250 * time.Millisecond
+
+// branchReconcileDelay lets a rebase or rename finish updating the project's
+// refs before the shadow branches are reconciled with them
+const branchReconcileDelay = time.Second
+
 // Watcher monitors file system changes and creates snapshots
 type Watcher struct {
 	fsWatcher     *fsnotify.Watcher
@@ -20,6 +44,83 @@ type Watcher struct {
 	wg            sync.WaitGroup
 	state         *AppState
 	ignoreManager *EnhancedIgnoreManager
+	onSnapshot    func(err error)
+	onEvent       func(WatcherEvent) // nil unless events are published (start --porcelain)
+	noCatchUp     bool               // Start leaves the changes made while stopped alone (start --no-catchup)
+	metrics       *Metrics           // nil unless the metrics endpoint is enabled
+
+	batchSize         int                // Snapshot as soon as this many paths changed
+	batchWindow       time.Duration      // Snapshot at least this long after a batch's first change (0 = no limit)
+	stormThreshold    int                // Changes within stormWindow that start a change storm (0 = never)
+	snapshotEvery     time.Duration      // Longest pending changes wait for a snapshot (0 = no limit), watcher.interval_snapshot
+	debounceOverrides []debounceOverride // Debounce delays of matching paths, watcher.debounce_overrides
+	snapshotMu        sync.Mutex         // Serializes snapshots
+	snapshotQueue     chan struct{}      // Snapshots waiting for snapshotWorker, at most snapshotQueueSize
+
+	mu         sync.Mutex
+	changed    map[string]string // Paths changed since the last snapshot attempt, by pathKey
+	batchStart time.Time         // First change of the current batch
+	rescan     bool              // Changes may be missing from changed; stage the whole tree next time
+	lowSpace   bool              // Snapshots paused, recording metadata only
+
+	lastRetention    time.Time                // Last retention pass, only touched by createSnapshot
+	lastQuotaCheck   time.Time                // Last git.max_commits/max_repo_size_mb check, only touched by createSnapshot
+	lastFullScan     time.Time                // Last snapshot staging the whole tree, only touched by createSnapshot and Start
+	ignoreReload     *time.Timer              // Pending reload of changed ignore files, only touched by the event loop
+	ignoreChanged    chan struct{}            // Fired by ignoreReload; the event loop reloads
+	configReloads    chan configReloadRequest // Reloaded configurations for the event loop to apply
+	branchReconcile  *time.Timer              // Pending reconciliation of the shadow branches, only touched by the event loop
+	branchesChanged  chan struct{}            // Fired by branchReconcile; the event loop reconciles
+	lastReload       *ConfigReload            // Outcome of the last configuration reload, under mu
+	snapshotsSinceGC int                      // Snapshots since the last gc, only touched by createSnapshot
+	gcRunning        bool                     // A gc is running or about to start, under mu
+	lastGC           *GCRun                   // Outcome of the last gc, under mu
+	failedSnapshots  int                      // Snapshots failed in a row, only touched by createSnapshot
+	healWarned       bool                     // The broken shadow repository warning was printed, only touched by createSnapshot
+	lastHeal         *ShadowHeal              // Last re-initialization of the shadow repository, under mu
+	maxWatchedFiles  int                      // Files to watch before polling the remaining directories (0 = no limit)
+	watchedFiles     int                      // Files in watched directories, under mu
+	dirFiles         map[string]int           // Files counted per watched directory, under mu
+	polled           map[string]bool          // Directories polled instead of watched, with everything below them, under mu
+	pollStamps       map[string]fileStamp     // Polled files as of the last pass, only touched by the event loop
+	pollInterval     time.Duration            // Delay until the next poll pass, only touched by the event loop
+	maxPollInterval  time.Duration            // Longest delay polling backs off to
+	pollTimer        *time.Timer              // Pending poll pass; fires pollDue
+	pollDue          chan struct{}            // Fired by pollTimer; the event loop polls
+	limitWarned      bool                     // The watch limit warning was printed
+	branch           string                   // Branch checked out in the project, as of the last reconciliation
+	ignored          map[string]bool          // Ignored paths changed since the last files_ignored event, only touched by the event loop
+	ignoredTimer     *time.Timer              // Pending files_ignored event; fires ignoredDue
+	ignoredDue       chan struct{}            // Fired by ignoredTimer; the event loop reports
+	storming         bool                     // Snapshots wait for a change storm to subside, only touched by the event loop
+	stormChanges     int                      // Changes during the current storm, only touched by the event loop
+	rateStart        time.Time                // Start of the span changes are counted over for storm detection, only touched by the event loop
+	rateChanges      int                      // Changes since rateStart, only touched by the event loop
+	stormTimer       *time.Timer              // Pending end of the change storm; fires stormDue
+	stormDue         chan struct{}            // Fired by stormTimer; the event loop ends the storm
+	atomicSaves      map[string]atomicSave    // Editor temporary files of saves in flight, by path, under mu
+	atomicTimer      *time.Timer              // Pending expiry of atomicSaves, under mu; fires atomicDue
+	atomicDue        chan struct{}            // Fired by atomicTimer; the event loop expires saves
+	startedAt        time.Time
+}
+
+// WatcherStats is a point-in-time view of a running watcher, published
+// through the watcher state file for 'timemachine status'
+type WatcherStats struct {
+	StartedAt            time.Time
+	WatchedDirs          int
+	WatchedFiles         int  // Files in watched directories, counted against watcher.max_watched_files
+	PolledDirs           int  // Directories polled because of watch limits
+	PendingChanges       int  // Changed paths waiting for the next snapshot
+	SnapshotPending      bool // A debounced snapshot is scheduled or queued
+	IgnoreCacheHits      int64
+	IgnoreCacheMisses    int64
+	IgnoreCacheHitRate   float64        // Percent of ignore checks answered from cache
+	IgnoreCacheEvictions int64          // Results dropped to keep the cache within cache.max_entries
+	LastConfigReload     *ConfigReload  // nil until the configuration was reloaded
+	LastGC               *GCRun         // nil until the shadow repository was garbage-collected
+	LastHeal             *ShadowHeal    // nil unless the shadow repository was re-initialized
+	IgnoreSources        []IgnoreSource // Where ignore patterns come from, in the order applied
 }
 
 // NewWatcher creates a new file system watcher
@@ -31,42 +132,128 @@ func NewWatcher(state *AppState, gitManager *GitManager) (*Watcher, error) {
 
 	// Create debouncer using configured delay (defaults to 2s, optimal for bulk operations)
 	debounceDelay := 2000 * time.Millisecond // fallback default
+	batchSize, batchWindow := 100, 30*time.Second
+	stormThreshold := 1000
+	snapshotEvery := time.Duration(0)
+	maxWatchedFiles := 0
+	var debounceOverrides []debounceOverride
 	if state.Config != nil {
+		maxWatchedFiles = state.Config.Watcher.MaxWatchedFiles
 		debounceDelay = state.Config.Watcher.DebounceDelay
+		batchSize, batchWindow = state.Config.Watcher.BatchSize, state.Config.Watcher.BatchWindow
+		stormThreshold = state.Config.Watcher.StormThreshold
+		snapshotEvery = state.Config.Watcher.IntervalSnapshot
+		debounceOverrides = parseDebounceOverrides(state.Config.Watcher.DebounceOverrides)
 	}
 	debouncer := NewDebouncer(debounceDelay)
 
 	// Create enhanced ignore manager with .timemachine-ignore support
-	ignoreManager := NewEnhancedIgnoreManager(state.ProjectRoot)
+	ignoreManager, err := NewProjectIgnoreManager(state)
+	if err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
 
 	return &Watcher{
-		fsWatcher:     fsWatcher,
-		gitManager:    gitManager,
-		debouncer:     debouncer,
-		stopChan:      make(chan bool),
-		state:         state,
-		ignoreManager: ignoreManager,
+		fsWatcher:         fsWatcher,
+		gitManager:        gitManager,
+		debouncer:         debouncer,
+		stopChan:          make(chan bool),
+		state:             state,
+		ignoreManager:     ignoreManager,
+		batchSize:         batchSize,
+		batchWindow:       batchWindow,
+		stormThreshold:    stormThreshold,
+		snapshotEvery:     snapshotEvery,
+		debounceOverrides: debounceOverrides,
+		changed:           make(map[string]string),
+		ignoreChanged:     make(chan struct{}, 1),
+		configReloads:     make(chan configReloadRequest),
+		branchesChanged:   make(chan struct{}, 1),
+		maxWatchedFiles:   maxWatchedFiles,
+		dirFiles:          make(map[string]int),
+		polled:            make(map[string]bool),
+		pollStamps:        make(map[string]fileStamp),
+		pollDue:           make(chan struct{}, 1),
+		maxPollInterval:   pollMaxInterval,
+		ignored:           make(map[string]bool),
+		ignoredDue:        make(chan struct{}, 1),
+		stormDue:          make(chan struct{}, 1),
+		atomicSaves:       make(map[string]atomicSave),
+		atomicDue:         make(chan struct{}, 1),
+		snapshotQueue:     make(chan struct{}, snapshotQueueSize),
+		lastFullScan:      time.Now(),
 	}, nil
 }
 
 // Start begins monitoring file changes
 func (w *Watcher) Start() error {
-	// Add project root and subdirectories to watch
-	if err := w.addDirectoryRecursive(w.state.ProjectRoot); err != nil {
-		return fmt.Errorf("failed to add directories to watch: %w", err)
+	w.startedAt = time.Now()
+
+	// Add project root and subdirectories to watch, unless the filesystem
+	// doesn't report changes (e.g. a Windows drive in WSL)
+	if len(w.state.IncludePaths) > 0 {
+		color.Cyan("📁 Only watching and snapshotting %s", strings.Join(w.state.IncludePaths, ", "))
+		// The root itself, for changes to the ignore file
+		if err := w.fsWatcher.Add(w.state.ProjectRoot); err != nil {
+			return fmt.Errorf("failed to watch %s: %w", w.state.ProjectRoot, err)
+		}
+	}
+	if env := w.state.Environment(); env.Poll {
+		w.maxPollInterval = envPollInterval
+		for _, root := range w.watchRoots() {
+			w.pollDirectory(root)
+		}
+		color.Cyan("🐢 The project is on %s, which doesn't report changes; polling for them every %s at most", env.Filesystem, envPollInterval)
+	} else {
+		for _, root := range w.watchRoots() {
+			if err := w.addDirectoryRecursive(root); err != nil {
+				return fmt.Errorf("failed to add directories to watch: %w", err)
+			}
+		}
 	}
+	// Branches renamed or deleted in the project are followed by the shadow
+	// branches, including while the watcher was not running
+	w.watchBranchRefs()
+	w.reconcileBranches()
 
-	// Create initial snapshot
-	fmt.Print("✅ Creating initial snapshot... ")
-	if err := w.gitManager.CreateSnapshot(""); err != nil {
-		color.Red("❌")
-		return fmt.Errorf("failed to create initial snapshot: %w", err)
+	// A previous run killed mid-commit may have left the shadow repo locked;
+	// the initial snapshot then captures whatever that run missed
+	previous, _ := w.gitManager.RunCommand("rev-parse", "--verify", "--quiet", "HEAD")
+	message := ""
+	if w.recoverInterruptedCommit() {
+		message = fmt.Sprintf("Snapshot at %s (recovered after interrupted commit)", time.Now().Format("15:04:05"))
+	} else if previous != "" {
+		message = fmt.Sprintf("Catch-up snapshot at %s (changes made while the watcher was stopped)", time.Now().Format("15:04:05"))
+	}
+
+	// Create the initial snapshot, or catch up on the changes made since
+	// the last one: they were made while nothing was watching
+	if previous != "" && w.noCatchUp {
+		fmt.Println("⏭️  Skipping the catch-up snapshot (--no-catchup); changes made while stopped are recorded once edited again, or by the next full rescan")
+	} else if err := w.initialSnapshot(message, previous); err != nil {
+		return err
 	}
-	color.Green("Done!")
 
-	// Start event loop
-	w.wg.Add(1)
+	// Start event loop, and the worker it hands snapshots to
+	w.wg.Add(2)
 	go w.eventLoop()
+	go w.snapshotWorker()
+
+	// Look for silent corruption while there is time to act on it
+	if interval, sample := w.gitManager.verifySettings(); interval > 0 {
+		w.wg.Add(1)
+		go w.integrityLoop(interval, sample)
+	}
+
+	// Back snapshots up to the remote, so losing the machine loses none
+	if interval := w.gitManager.pushInterval(); interval > 0 {
+		w.wg.Add(1)
+		go w.pushLoop(interval)
+	}
+	if interval := w.gitManager.backupInterval(); interval > 0 {
+		w.wg.Add(1)
+		go w.backupLoop(interval)
+	}
 
 	// Print status
 	color.Green("🚀 Time Machine is watching for changes...")
@@ -75,6 +262,81 @@ func (w *Watcher) Start() error {
 	return nil
 }
 
+// initialSnapshot stages the whole tree when the watcher starts. previous
+// is the last snapshot, if any; the changes since then are a catch-up.
+func (w *Watcher) initialSnapshot(message, previous string) error {
+	if previous == "" {
+		fmt.Print("✅ Creating initial snapshot... ")
+	} else {
+		fmt.Print("✅ Catching up on changes made while stopped... ")
+	}
+	w.lastFullScan = time.Now()
+	err := w.gitManager.CreateSnapshot(message)
+	switch {
+	case IsLowDiskSpace(err):
+		// Keep watching; snapshots resume once space is freed
+		w.pauseSnapshots(err)
+	case IsSecretsDetected(err):
+		// Keep watching; the next change that removes the secret snapshots
+		w.secretsBlocked(err)
+	case err != nil:
+		color.Red("❌")
+		return fmt.Errorf("failed to create initial snapshot: %w", err)
+	case previous == "":
+		color.Green("Done!")
+	default:
+		head, _ := w.gitManager.RunCommand("rev-parse", "--verify", "--quiet", "HEAD")
+		if head == previous {
+			color.Green("Nothing changed")
+			break
+		}
+		files, _ := w.gitManager.SnapshotFiles(head)
+		color.Green("Done! (%d file(s) changed)", len(files))
+	}
+	return nil
+}
+
+// SetCatchUp controls whether Start snapshots the changes made since the
+// last snapshot right away (the default) or leaves them until edited again
+func (w *Watcher) SetCatchUp(enabled bool) {
+	w.noCatchUp = !enabled
+}
+
+// Stats reports the watcher's current activity
+func (w *Watcher) Stats() WatcherStats {
+	w.mu.Lock()
+	pending := len(w.changed)
+	lastReload := w.lastReload
+	lastGC := w.lastGC
+	lastHeal := w.lastHeal
+	watchedFiles, polled := w.watchedFiles, len(w.polled)
+	w.mu.Unlock()
+
+	hits, misses, _, hitRate, evictions := w.ignoreManager.GetStats()
+	return WatcherStats{
+		StartedAt:            w.startedAt,
+		WatchedDirs:          len(w.fsWatcher.WatchList()),
+		WatchedFiles:         watchedFiles,
+		PolledDirs:           polled,
+		PendingChanges:       pending,
+		SnapshotPending:      w.debouncer.IsActive() || len(w.snapshotQueue) > 0,
+		IgnoreCacheHits:      hits,
+		IgnoreCacheMisses:    misses,
+		IgnoreCacheHitRate:   hitRate,
+		IgnoreCacheEvictions: evictions,
+		LastConfigReload:     lastReload,
+		LastGC:               lastGC,
+		LastHeal:             lastHeal,
+		IgnoreSources:        w.ignoreManager.IgnoreSources(),
+	}
+}
+
+// SetSnapshotHandler registers a callback invoked after every debounced
+// snapshot attempt with the resulting error (nil on success)
+func (w *Watcher) SetSnapshotHandler(fn func(err error)) {
+	w.onSnapshot = fn
+}
+
 // Stop stops the file watcher
 func (w *Watcher) Stop() {
 	close(w.stopChan)
@@ -83,15 +345,30 @@ func (w *Watcher) Stop() {
 	w.wg.Wait()
 }
 
-// addDirectoryRecursive adds a directory and all its subdirectories to the watcher
+// watchRoots returns the directories watched recursively: the include
+// paths, or the whole project
+func (w *Watcher) watchRoots() []string {
+	if len(w.state.IncludePaths) == 0 {
+		return []string{w.state.ProjectRoot}
+	}
+	roots := make([]string, len(w.state.IncludePaths))
+	for i, include := range w.state.IncludePaths {
+		roots[i] = filepath.Join(w.state.ProjectRoot, filepath.FromSlash(include))
+	}
+	return roots
+}
+
+// addDirectoryRecursive adds a directory and all its subdirectories to the
+// watcher, polling those beyond the watch limits
 func (w *Watcher) addDirectoryRecursive(root string) error {
-	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+	return filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
 		if err != nil {
 			// Skip directories we can't read
 			return nil
 		}
 
-		if !info.IsDir() {
+		if !entry.IsDir() {
+			w.countWatchedFile(path)
 			return nil
 		}
 
@@ -100,10 +377,9 @@ func (w *Watcher) addDirectoryRecursive(root string) error {
 			return filepath.SkipDir
 		}
 
-		// Add directory to watcher
-		if err := w.fsWatcher.Add(path); err != nil {
-			// Log but don't fail - some directories might not be accessible
-			fmt.Printf("Warning: couldn't watch directory %s: %v\n", path, err)
+		// Polled directories already cover everything below them
+		if w.isPolled(path) || !w.watchDirectory(path) {
+			return filepath.SkipDir
 		}
 
 		return nil
@@ -140,20 +416,238 @@ func (w *Watcher) eventLoop() {
 				return
 			}
 			fmt.Printf("File watcher error: %v\n", err)
+			w.emitError("watcher", err)
+
+			// Events may have been dropped (e.g. queue overflow): the next
+			// snapshot can't rely on the collected paths
+			w.mu.Lock()
+			w.rescan = true
+			w.mu.Unlock()
+			if !w.storming {
+				w.debouncer.Trigger(w.queueSnapshot)
+			}
+
+		case <-w.ignoreChanged:
+			w.reloadIgnorePatterns()
+
+		case request := <-w.configReloads:
+			request.done <- w.applyConfig(request.manager)
+
+		case <-w.branchesChanged:
+			w.reconcileBranches()
+
+		case <-w.pollDue:
+			w.pollDirectories()
+
+		case <-w.ignoredDue:
+			w.reportIgnored()
+
+		case <-w.stormDue:
+			w.endStorm()
+
+		case <-w.atomicDue:
+			w.expireAtomicSaves()
 
 		case <-w.stopChan:
+			if w.ignoreReload != nil {
+				w.ignoreReload.Stop()
+			}
+			if w.branchReconcile != nil {
+				w.branchReconcile.Stop()
+			}
+			if w.pollTimer != nil {
+				w.pollTimer.Stop()
+			}
+			if w.ignoredTimer != nil {
+				w.ignoredTimer.Stop()
+			}
+			if w.stormTimer != nil {
+				w.stormTimer.Stop()
+			}
+			w.mu.Lock()
+			if w.atomicTimer != nil {
+				w.atomicTimer.Stop()
+			}
+			w.mu.Unlock()
 			return
 		}
 	}
 }
 
+// scheduleIgnoreReload reloads the ignore patterns once the ignore files
+// stopped changing for ignoreReloadDelay
+func (w *Watcher) scheduleIgnoreReload() {
+	if w.ignoreReload != nil {
+		w.ignoreReload.Reset(ignoreReloadDelay)
+		return
+	}
+	w.ignoreReload = time.AfterFunc(ignoreReloadDelay, func() {
+		select {
+		case w.ignoreChanged <- struct{}{}:
+		default: // A reload is already due
+		}
+	})
+}
+
+// reloadIgnorePatterns applies edited ignore files without a restart. It runs
+// on the event loop, the only goroutine matching paths, so patterns never
+// change under a check.
+func (w *Watcher) reloadIgnorePatterns() {
+	if err := w.ignoreManager.ReloadIgnoreFile(); err != nil {
+		fmt.Printf("Warning: failed to reload ignore patterns: %v\n", err)
+		return
+	}
+	fmt.Printf("🔄 Reloaded ignore patterns (%d from %s)\n", w.ignoreManager.GetPatternsCount(), DefaultIgnoreFile)
+
+	// Stop watching directories that are ignored now, then pick up those
+	// that no longer are
+	for _, dir := range w.fsWatcher.WatchList() {
+		if _, ok := w.gitDirPath(dir); !ok && dir != w.state.ProjectRoot && w.ignoreManager.ShouldIgnoreDirectory(dir) {
+			w.fsWatcher.Remove(dir)
+			w.forgetWatchedDir(dir)
+		}
+	}
+	for _, root := range w.watchRoots() {
+		if err := w.addDirectoryRecursive(root); err != nil {
+			fmt.Printf("Warning: couldn't watch directories: %v\n", err)
+		}
+	}
+
+	// Pending changes to paths ignored now are dropped
+	w.mu.Lock()
+	for key, rel := range w.changed {
+		if w.ignoreManager.ShouldIgnoreFile(filepath.Join(w.state.ProjectRoot, filepath.FromSlash(rel))) {
+			delete(w.changed, key)
+		}
+	}
+	w.mu.Unlock()
+}
+
+// watchBranchRefs watches the project's branch refs: loose refs below
+// refs/heads and, through the git directory itself, packed-refs and HEAD.
+// Linked worktrees share the refs of the main repository but have a HEAD
+// of their own.
+func (w *Watcher) watchBranchRefs() {
+	commonDir := CommonDir(w.state.GitDir)
+	if err := w.fsWatcher.Add(commonDir); err != nil {
+		fmt.Printf("Warning: couldn't watch %s for branch changes: %v\n", commonDir, err)
+		return
+	}
+	if w.state.GitDir != commonDir {
+		w.fsWatcher.Add(w.state.GitDir)
+	}
+	w.watchRefsDir(filepath.Join(commonDir, "refs", "heads"))
+}
+
+// watchRefsDir watches a directory of branch refs and those below it, which
+// hold branches with slashes in their names
+func (w *Watcher) watchRefsDir(root string) {
+	filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err == nil && entry.IsDir() {
+			w.fsWatcher.Add(path)
+		}
+		return nil
+	})
+}
+
+// gitDirPath returns the slash-separated path of a file inside the
+// project's (common) git directory, and whether it is inside it
+func (w *Watcher) gitDirPath(path string) (string, bool) {
+	rel, err := filepath.Rel(CommonDir(w.state.GitDir), path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+// scheduleBranchReconcile reconciles the shadow branches once the project's
+// refs stopped changing for branchReconcileDelay
+func (w *Watcher) scheduleBranchReconcile() {
+	if w.branchReconcile != nil {
+		w.branchReconcile.Reset(branchReconcileDelay)
+		return
+	}
+	w.branchReconcile = time.AfterFunc(branchReconcileDelay, func() {
+		select {
+		case w.branchesChanged <- struct{}{}:
+		default: // A reconciliation is already due
+		}
+	})
+}
+
+// reconcileBranches renames and prunes shadow branches after branches were
+// renamed or deleted in the project, between snapshots so none lands on a
+// branch being renamed
+func (w *Watcher) reconcileBranches() {
+	w.snapshotMu.Lock()
+	defer w.snapshotMu.Unlock()
+
+	w.followCheckout()
+	changes, err := w.gitManager.ReconcileBranches()
+	timestamp := time.Now().Format("2006-01-02 15:04:05")
+	for _, change := range changes {
+		if change.RenamedTo != "" {
+			fmt.Printf("🔀 %s Renamed shadow branch %s to %s, following the project\n", timestamp, change.Branch, change.RenamedTo)
+			w.emit(WatcherEvent{Type: EventBranchChanged, Action: BranchRenamed, Branch: change.RenamedTo, From: change.Branch})
+		} else {
+			fmt.Printf("🧹 %s Pruned shadow branch %s (deleted from the project, %d snapshot(s) of its own)\n", timestamp, change.Branch, change.Unique)
+			w.emit(WatcherEvent{Type: EventBranchChanged, Action: BranchPruned, Branch: change.Branch})
+		}
+	}
+	if err != nil {
+		color.Yellow("⚠️  %s Could not reconcile shadow branches: %v", timestamp, err)
+		w.emitError("branches", err)
+	}
+}
+
 // handleEvent processes a single file system event
 func (w *Watcher) handleEvent(event fsnotify.Event) {
+	// The project's own repository is only watched for branch changes
+	if rel, ok := w.gitDirPath(event.Name); ok {
+		head := event.Name == filepath.Join(w.state.GitDir, "HEAD")
+		if head || rel == "packed-refs" || strings.HasPrefix(rel, "refs/heads/") {
+			if event.Op&fsnotify.Create == fsnotify.Create {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					w.watchRefsDir(event.Name)
+				}
+			}
+			w.scheduleBranchReconcile()
+		}
+		return
+	}
+
+	if w.ignoreManager.IsIgnoreSource(event.Name) {
+		w.scheduleIgnoreReload()
+	}
+
+	// Only the root itself is watched outside the include paths
+	if rel, err := filepath.Rel(w.state.ProjectRoot, event.Name); err == nil && !isIncluded(filepath.ToSlash(rel), w.state.IncludePaths) {
+		return
+	}
+
+	// An editor's atomic save is one change of the file saved, whether or
+	// not its temporary files are ignored
+	event, keep := w.coalesceAtomicSave(event)
+	if !keep {
+		return
+	}
+
 	// Ignore if file should be ignored
 	if w.shouldIgnoreFile(event.Name) {
+		w.noteIgnored(event.Name)
 		return
 	}
 
+	// Chaos mode: simulate lost events (no-op unless fault injection is enabled)
+	if faults().dropEvent(event.Name) {
+		return
+	}
+
+	// A removed directory's files no longer count against the watch limit
+	if event.Op&(fsnotify.Remove|fsnotify.Rename) != 0 {
+		w.forgetWatchedDir(event.Name)
+	}
+
 	// If a new directory was created, add it to watch list
 	if event.Op&fsnotify.Create == fsnotify.Create {
 		if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
@@ -165,19 +659,139 @@ func (w *Watcher) handleEvent(event fsnotify.Event) {
 		}
 	}
 
-	// Debounce snapshot creation
-	w.debouncer.Trigger(w.createSnapshot)
+	// During a change storm the snapshot taken once it subsides stages
+	// everything, so changes are only counted
+	if w.noteChange() {
+		return
+	}
+
+	// Collect what changed: the next snapshot stages only these paths
+	batchDone := false
+	delay := time.Duration(0) // The debouncer's own
+	if rel, err := filepath.Rel(w.state.ProjectRoot, event.Name); err == nil {
+		rel = filepath.ToSlash(rel)
+		w.mu.Lock()
+		if len(w.changed) == 0 {
+			w.batchStart = time.Now()
+		}
+		w.changed[pathKey(rel, w.state.CaseInsensitive())] = rel
+		batchDone = (w.batchSize > 0 && len(w.changed) >= w.batchSize) ||
+			(w.batchWindow > 0 && time.Since(w.batchStart) >= w.batchWindow)
+		delay = debounceDelayFor(w.debounceOverrides, rel)
+		w.mu.Unlock()
+	}
+
+	// Debounce snapshot creation, unless the batch is already complete
+	if batchDone {
+		w.debouncer.Flush(w.queueSnapshot)
+	} else if delay > 0 {
+		w.debouncer.TriggerAfter(delay, w.queueSnapshot)
+	} else {
+		w.debouncer.Trigger(w.queueSnapshot)
+	}
+
+	// Chaos mode: kill the pending snapshot mid-flight
+	if faults().killDebounce() {
+		w.debouncer.Cancel()
+	}
 }
 
-// createSnapshot creates a snapshot (called after debounce delay)
+// createSnapshot creates a snapshot (called by snapshotWorker after the
+// debounce delay)
 func (w *Watcher) createSnapshot() {
+	w.snapshotMu.Lock()
+	defer w.snapshotMu.Unlock()
+
+	w.metrics.debounceFired()
+	paths, rescan := w.takeChangedPaths()
+	if len(paths) == 0 && !rescan {
+		return // Already taken by a snapshot that was flushed meanwhile
+	}
+	if w.atomicSaveInFlight() {
+		// The file being saved may be missing for a moment; wait for the
+		// editor to finish
+		w.requeueChangedPaths(paths, rescan)
+		w.debouncer.Trigger(w.queueSnapshot)
+		return
+	}
+
+	if err := w.gitManager.RunHooks(HookPreSnapshot, HookContext{ChangedFiles: paths}); err != nil {
+		color.Yellow("⏭️  Snapshot skipped: %v", err)
+		w.emitError("snapshot", err)
+		w.requeueChangedPaths(paths, rescan)
+		return
+	}
+	// Hooks, metrics, telemetry and events need to know what the new
+	// snapshot contains
+	track := w.metrics != nil || w.telemetryEnabled() || w.onEvent != nil || w.gitManager.HasHooks(HookPostSnapshot)
+	previous := ""
+	if track {
+		previous, _ = w.gitManager.RunCommand("rev-parse", "--verify", "--quiet", "HEAD")
+	}
+
 	fmt.Print("📸 Creating snapshot... ")
-	
-	if err := w.gitManager.CreateSnapshot(""); err != nil {
+
+	message := ""
+	if w.snapshotsPaused() {
+		message = fmt.Sprintf("Snapshot at %s (resumed after low disk space)", time.Now().Format("15:04:05"))
+		// Paths changed while paused were only recorded as metadata
+		rescan = true
+	}
+	if time.Since(w.lastFullScan) >= fullScanInterval {
+		rescan = true
+	}
+	if rescan {
+		w.lastFullScan = time.Now()
+	}
+
+	started := time.Now()
+	var err error
+	if rescan {
+		err = w.gitManager.CreateSnapshot(message)
+	} else {
+		err = w.gitManager.CreateSnapshotForPaths(message, paths)
+	}
+	if isLockError(err) && w.recoverInterruptedCommit() {
+		// Retry once; the lock was left by a process that no longer exists
+		err = w.gitManager.CreateSnapshot(fmt.Sprintf("Snapshot at %s (recovered after interrupted commit)", time.Now().Format("15:04:05")))
+	}
+	if err != nil && !IsSecretsDetected(err) && !IsLowDiskSpace(err) && w.snapshotFailed() {
+		// The shadow repository was re-created; start its history right away
+		err = w.gitManager.CreateSnapshot(fmt.Sprintf("Snapshot at %s (shadow repository re-created)", time.Now().Format("15:04:05")))
+	}
+	if w.onSnapshot != nil {
+		defer w.onSnapshot(err)
+	}
+	if err != nil {
+		w.metrics.observeSnapshot(time.Since(started), 0, err)
+		w.recordSnapshotTelemetry(time.Since(started), 0, err)
+	}
+	if IsSecretsDetected(err) {
+		w.secretsBlocked(err)
+		w.requeueChangedPaths(paths, rescan)
+		return
+	}
+	if IsLowDiskSpace(err) {
+		w.pauseSnapshots(err)
+		if err := w.gitManager.RecordMetadataOnly(paths); err != nil {
+			fmt.Printf("Warning: %v\n", err)
+		}
+		return
+	}
+	if err != nil {
 		color.Red("❌ Error: %v", err)
+		w.emitError("snapshot", err)
+		// The collected paths are gone; catch up with a full scan next time
+		w.mu.Lock()
+		w.rescan = true
+		w.mu.Unlock()
 		return
 	}
-	
+	w.failedSnapshots = 0
+	defer w.resumeSnapshots()
+
+	duration := time.Since(started)
+
 	// Get latest snapshot for display
 	snapshots, err := w.gitManager.ListSnapshots(1, "")
 	if err == nil && len(snapshots) > 0 {
@@ -185,4 +799,216 @@ func (w *Watcher) createSnapshot() {
 	} else {
 		color.Green("✅ Done!")
 	}
-}
\ No newline at end of file
+
+	if track && err == nil && len(snapshots) > 0 && snapshots[0].Hash != previous {
+		w.snapshotCreated(snapshots[0], duration)
+	}
+
+	w.applyRetention()
+	w.enforceQuota()
+	w.scheduleGC()
+}
+
+// secretsBlocked reports a snapshot skipped under secrets.mode: block
+func (w *Watcher) secretsBlocked(err error) {
+	var detected *SecretsDetectedError
+	errors.As(err, &detected)
+	fmt.Println()
+	color.Yellow("⏭️  Snapshot skipped: possible secrets in the changes (secrets.mode: block)")
+	printSecretFindings(detected.Findings)
+	fmt.Println("   Remove them, or add an exception to secrets.allow, and the next change will be snapshotted.")
+	w.emitError("snapshot", err)
+}
+
+// snapshotCreated records a new snapshot in the metrics and telemetry,
+// publishes it and runs the post_snapshot hooks for it
+func (w *Watcher) snapshotCreated(snapshot Snapshot, duration time.Duration) {
+	files, err := w.gitManager.SnapshotFiles(snapshot.Hash)
+	if err != nil {
+		color.Yellow("⚠️  %v", err)
+	}
+	w.metrics.observeSnapshot(duration, len(files), nil)
+	w.recordSnapshotTelemetry(duration, len(files), nil)
+	w.emit(WatcherEvent{Type: EventSnapshotCreated, Hash: snapshot.Hash, Message: snapshot.Message, Files: files})
+
+	if err := w.gitManager.RunHooks(HookPostSnapshot, HookContext{Hash: snapshot.Hash, Message: snapshot.Message, ChangedFiles: files}); err != nil {
+		color.Yellow("⚠️  %v", err)
+	}
+}
+
+// applyRetention prunes snapshots according to the configured retention
+// policy, at most once per retentionPassInterval
+func (w *Watcher) applyRetention() {
+	if time.Since(w.lastRetention) < retentionPassInterval {
+		return
+	}
+	policy, err := w.gitManager.RetentionPolicy()
+	if err != nil || !policy.Enabled() {
+		return
+	}
+	w.lastRetention = time.Now()
+
+	timestamp := time.Now().Format("2006-01-02 15:04:05")
+	plan, err := w.gitManager.ApplyRetention(policy, time.Now())
+	if err != nil {
+		color.Yellow("⚠️  %s Retention pass failed: %v", timestamp, err)
+		return
+	}
+	if len(plan.Remove) > 0 {
+		fmt.Printf("🧹 %s Retention policy removed %d snapshot(s), %d kept\n", timestamp, len(plan.Remove), len(plan.Keep))
+	}
+}
+
+// enforceQuota prunes the oldest snapshots once the shadow repository
+// exceeds git.max_commits or git.max_repo_size_mb, at most once per
+// quotaCheckInterval
+func (w *Watcher) enforceQuota() {
+	if w.state.Config == nil || time.Since(w.lastQuotaCheck) < quotaCheckInterval {
+		return
+	}
+	w.lastQuotaCheck = time.Now()
+
+	timestamp := time.Now().Format("2006-01-02 15:04:05")
+	plan, err := w.gitManager.EnforceQuota(time.Now())
+	if err != nil {
+		color.Yellow("⚠️  %s Quota enforcement failed: %v", timestamp, err)
+		return
+	}
+	if plan != nil && len(plan.Remove) > 0 {
+		fmt.Printf("🧹 %s Over quota: removed %d snapshot(s), %d kept\n", timestamp, len(plan.Remove), len(plan.Keep))
+	}
+}
+
+// recoverInterruptedCommit repairs leftovers of an interrupted git process in
+// the shadow repo, logging each repair; it reports whether anything was fixed
+func (w *Watcher) recoverInterruptedCommit() bool {
+	repairs, err := w.gitManager.RecoverInterruptedCommit()
+	for _, repair := range repairs {
+		color.Yellow("🩹 %s Recovered interrupted snapshot: %s", time.Now().Format("2006-01-02 15:04:05"), repair)
+	}
+	if err != nil {
+		color.Yellow("⚠️  Could not recover interrupted snapshot: %v", err)
+	}
+	return len(repairs) > 0
+}
+
+// integrityLoop runs an incremental integrity check every interval, timed
+// from the last check so frequent restarts don't postpone it indefinitely
+func (w *Watcher) integrityLoop(interval time.Duration, sample int) {
+	defer w.wg.Done()
+
+	wait := time.Duration(0)
+	if last, err := w.gitManager.ReadIntegrityReport(); err == nil {
+		wait = time.Until(last.CheckedAt.Add(interval))
+	}
+	if wait < integrityWarmup {
+		wait = integrityWarmup
+	}
+
+	for {
+		select {
+		case <-w.stopChan:
+			return
+		case <-time.After(wait):
+		}
+		w.checkIntegrity(sample)
+		wait = interval
+	}
+}
+
+// checkIntegrity runs one background integrity check and raises an alert
+// when the shadow repository is damaged
+func (w *Watcher) checkIntegrity(sample int) {
+	timestamp := time.Now().Format("2006-01-02 15:04:05")
+	report, err := w.gitManager.VerifyIntegrity(false, sample)
+	if err != nil {
+		color.Yellow("⚠️  %s Integrity check failed: %v", timestamp, err)
+		w.emitError("integrity", err)
+		return
+	}
+	if !report.Corrupt() {
+		fmt.Printf("🔍 %s Integrity check passed (%d objects)\n", timestamp, report.ObjectsChecked)
+		return
+	}
+
+	color.Red("🚨 %s Snapshot corruption detected (%d problem(s)):", timestamp, len(report.Problems))
+	w.emitError("integrity", fmt.Errorf("snapshot corruption detected (%d problem(s)); run 'timemachine verify --full'", len(report.Problems)))
+	for i, problem := range report.Problems {
+		if i == 5 {
+			fmt.Printf("   ... and %d more\n", len(report.Problems)-i)
+			break
+		}
+		fmt.Printf("   • %s\n", problem)
+	}
+	fmt.Println("   Run 'timemachine verify --full' for a complete check")
+}
+
+// pauseSnapshots switches to metadata-only tracking, warning on the first
+// skipped snapshot and printing a short note on later ones
+func (w *Watcher) pauseSnapshots(err error) {
+	w.mu.Lock()
+	alreadyPaused := w.lowSpace
+	w.lowSpace = true
+	w.mu.Unlock()
+
+	w.emitError("snapshot", err)
+	if alreadyPaused {
+		color.Yellow("⏸️  Skipped (low disk space)")
+		return
+	}
+
+	color.Yellow("⏸️  Skipped")
+	color.Yellow("⚠️  %v", err)
+	fmt.Println("   Snapshots are paused until space is freed; changed paths are recorded in")
+	fmt.Printf("   %s\n", w.gitManager.MetadataLogPath())
+}
+
+// resumeSnapshots leaves metadata-only tracking after a successful snapshot
+func (w *Watcher) resumeSnapshots() {
+	w.mu.Lock()
+	wasPaused := w.lowSpace
+	w.lowSpace = false
+	w.mu.Unlock()
+
+	if wasPaused {
+		color.Green("✅ Disk space recovered, snapshots resumed")
+	}
+}
+
+// snapshotsPaused reports whether the watcher is in metadata-only mode
+func (w *Watcher) snapshotsPaused() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.lowSpace
+}
+
+// requeueChangedPaths puts taken paths back when a snapshot was skipped, so
+// the next snapshot includes them
+func (w *Watcher) requeueChangedPaths(paths []string, rescan bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, path := range paths {
+		w.changed[pathKey(path, w.state.CaseInsensitive())] = path
+	}
+	w.rescan = w.rescan || rescan
+}
+
+// takeChangedPaths returns and clears the paths changed since the last
+// snapshot attempt, sorted, and whether the whole tree must be staged
+// because changes may have been missed
+func (w *Watcher) takeChangedPaths() ([]string, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	paths := make([]string, 0, len(w.changed))
+	for _, path := range w.changed {
+		paths = append(paths, path)
+	}
+	w.changed = make(map[string]string)
+	rescan := w.rescan
+	w.rescan = false
+
+	sort.Strings(paths)
+	return paths, rescan
+}
@@ -0,0 +1,31 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// watchLimitFile holds inotify's per-user limit on watched directories
+const watchLimitFile = "/proc/sys/fs/inotify/max_user_watches"
+
+// watchLimit returns how many directories a user may watch, 0 if unknown
+func watchLimit() int {
+	data, err := os.ReadFile(watchLimitFile)
+	if err != nil {
+		return 0
+	}
+	limit, _ := strconv.Atoi(strings.TrimSpace(string(data)))
+	return limit
+}
+
+// watchLimitHint explains how to raise the inotify limit the watcher ran into
+func watchLimitHint() string {
+	limit := "a limited number of"
+	if n := watchLimit(); n > 0 {
+		limit = strconv.Itoa(n)
+	}
+	return fmt.Sprintf("inotify allows %s watched directories per user. Raise the limit with\n"+
+		"   'sudo sysctl fs.inotify.max_user_watches=524288' (persist it in /etc/sysctl.d/)", limit)
+}
@@ -0,0 +1,21 @@
+//go:build !linux
+
+package core
+
+import "runtime"
+
+// watchLimit returns how many directories a user may watch, 0 if unknown.
+// kqueue's limit is the open file limit, shared with everything else.
+func watchLimit() int {
+	return 0
+}
+
+// watchLimitHint explains how to raise the limit the watcher ran into
+func watchLimitHint() string {
+	if runtime.GOOS == "windows" {
+		return "Windows ran out of resources for directory notifications."
+	}
+	// kqueue needs an open file for every watched file and directory
+	return "kqueue needs an open file per watched file. Raise the limit with\n" +
+		"   'ulimit -n 65536' in the shell that starts the watcher"
+}
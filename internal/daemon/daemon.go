@@ -0,0 +1,280 @@
+package daemon
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/core"
+)
+
+// File names used inside the shadow repository for daemon bookkeeping
+const (
+	PIDFileName = "daemon.pid"
+	LogFileName = "daemon.log"
+
+	// ChildFlag is the hidden flag passed to the re-executed binary so it
+	// knows it is running as the background watcher process
+	ChildFlag = "--daemon-child"
+
+	startupTimeout  = 5 * time.Second
+	shutdownTimeout = 10 * time.Second
+	reloadTimeout   = 5 * time.Second
+	pollInterval    = 100 * time.Millisecond
+)
+
+// ErrNotRunning is returned when an operation requires a running daemon
+var ErrNotRunning = errors.New("time machine daemon is not running")
+
+// ErrNoWatcher is returned when an operation requires a running watcher,
+// in the background or in the foreground
+var ErrNoWatcher = errors.New("no running time machine watcher found")
+
+// Status describes the state of the background watcher process
+type Status struct {
+	Running   bool      // Whether a live daemon process was found
+	PID       int       // Process ID from the PID file (0 if none)
+	StartedAt time.Time // When the PID file was written
+	Stale     bool      // PID file existed but the process was gone
+	PIDFile   string    // Path to the PID file
+	LogFile   string    // Path to the daemon log file
+}
+
+// Uptime returns how long the daemon has been running
+func (s *Status) Uptime() time.Duration {
+	if !s.Running || s.StartedAt.IsZero() {
+		return 0
+	}
+	return time.Since(s.StartedAt).Round(time.Second)
+}
+
+// Manager handles the lifecycle of the background watcher process
+type Manager struct {
+	State *core.AppState
+
+	mu        sync.Mutex // Serializes state file writes from the watcher
+	lastError string     // Error of the last snapshot attempt, for RefreshActivity
+}
+
+// NewManager creates a new daemon Manager for the given state
+func NewManager(state *core.AppState) *Manager {
+	return &Manager{State: state}
+}
+
+// PIDFile returns the path of the daemon PID file
+func (m *Manager) PIDFile() string {
+	return filepath.Join(m.State.ShadowRepoDir, PIDFileName)
+}
+
+// LogFile returns the path of the daemon log file
+func (m *Manager) LogFile() string {
+	return filepath.Join(m.State.ShadowRepoDir, LogFileName)
+}
+
+// Status reports whether the daemon is running, cleaning up stale PID files
+func (m *Manager) Status() (*Status, error) {
+	status := &Status{
+		PIDFile: m.PIDFile(),
+		LogFile: m.LogFile(),
+	}
+
+	pid, startedAt, err := m.readPIDFile()
+	if os.IsNotExist(err) {
+		return status, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	status.PID = pid
+	status.StartedAt = startedAt
+
+	if processAlive(pid) {
+		status.Running = true
+		return status, nil
+	}
+
+	// Process is gone but left its PID file behind (crash, kill -9, reboot)
+	status.Stale = true
+	if err := os.Remove(status.PIDFile); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to remove stale PID file: %w", err)
+	}
+
+	return status, nil
+}
+
+// Start re-executes the current binary as a detached background watcher.
+// extraArgs are appended to the child's `start` invocation.
+func (m *Manager) Start(extraArgs ...string) (int, error) {
+	status, err := m.Status()
+	if err != nil {
+		return 0, err
+	}
+	if status.Running {
+		return status.PID, fmt.Errorf("daemon already running (PID %d)", status.PID)
+	}
+	if pid, running := m.WatcherLockHolder(); running {
+		return pid, fmt.Errorf("%w; stop it with Ctrl+C first", &LockedError{PID: pid})
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return 0, fmt.Errorf("failed to locate timemachine executable: %w", err)
+	}
+
+	logFile, err := os.OpenFile(m.LogFile(), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open daemon log file: %w", err)
+	}
+	defer logFile.Close()
+
+	args := append([]string{"start", ChildFlag}, extraArgs...)
+	cmd := exec.Command(executable, args...)
+	cmd.Dir = m.State.ProjectRoot
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	cmd.Stdin = nil
+	cmd.SysProcAttr = detachedProcAttr()
+
+	if err := cmd.Start(); err != nil {
+		return 0, fmt.Errorf("failed to start daemon process: %w", err)
+	}
+	pid := cmd.Process.Pid
+
+	// Reap the child if it exits, so an early failure is noticed at once
+	// and its exit status reported; a zombie would still look alive. Once
+	// it is running, this process exits and leaves it to init.
+	exited := make(chan error, 1)
+	go func() { exited <- cmd.Wait() }()
+
+	// Wait for the child to write its PID file, which signals a successful start
+	deadline := time.After(startupTimeout)
+	for {
+		if filePID, _, err := m.readPIDFile(); err == nil && filePID == pid {
+			return pid, nil
+		}
+		select {
+		case err := <-exited:
+			if err == nil {
+				err = fmt.Errorf("exit status 0")
+			}
+			return 0, fmt.Errorf("daemon exited during startup (%v), see %s", err, m.LogFile())
+		case <-deadline:
+			return 0, fmt.Errorf("daemon did not report startup within %s, see %s", startupTimeout, m.LogFile())
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// Stop signals the running daemon to shut down and waits for it to exit
+func (m *Manager) Stop() (int, error) {
+	status, err := m.Status()
+	if err != nil {
+		return 0, err
+	}
+	if !status.Running {
+		return 0, ErrNotRunning
+	}
+
+	if err := terminateProcess(status.PID); err != nil {
+		return status.PID, fmt.Errorf("failed to signal daemon (PID %d): %w", status.PID, err)
+	}
+
+	deadline := time.Now().Add(shutdownTimeout)
+	for time.Now().Before(deadline) {
+		if !processAlive(status.PID) {
+			// The daemon removes its own PID file on graceful exit; make sure
+			os.Remove(m.PIDFile())
+			return status.PID, nil
+		}
+		time.Sleep(pollInterval)
+	}
+
+	return status.PID, fmt.Errorf("daemon (PID %d) did not stop within %s", status.PID, shutdownTimeout)
+}
+
+// Reload asks the running watcher, in the background or in the foreground,
+// to reload its configuration and waits for it to report the outcome
+func (m *Manager) Reload() (int, *core.ConfigReload, error) {
+	pid := 0
+	if status, err := m.Status(); err != nil {
+		return 0, nil, err
+	} else if status.Running {
+		pid = status.PID
+	} else if state, err := m.ReadState(); err == nil && state.WatcherAlive() {
+		pid = state.PID
+	}
+	if pid == 0 {
+		return 0, nil, ErrNoWatcher
+	}
+
+	requested := time.Now()
+	if err := reloadProcess(pid); err != nil {
+		return pid, nil, fmt.Errorf("failed to signal watcher (PID %d): %w", pid, err)
+	}
+
+	// The watcher publishes the outcome in its state file
+	deadline := requested.Add(reloadTimeout)
+	for time.Now().Before(deadline) {
+		if state, err := m.ReadState(); err == nil && state.LastConfigReload != nil && !state.LastConfigReload.At.Before(requested) {
+			return pid, state.LastConfigReload, nil
+		}
+		time.Sleep(pollInterval)
+	}
+	return pid, nil, fmt.Errorf("watcher (PID %d) did not report a reload within %s", pid, reloadTimeout)
+}
+
+// WritePIDFile records the current process as the running daemon.
+// Called by the child process once the watcher has started.
+func (m *Manager) WritePIDFile() error {
+	content := fmt.Sprintf("%d\n%s\n", os.Getpid(), time.Now().UTC().Format(time.RFC3339))
+	if err := os.WriteFile(m.PIDFile(), []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write PID file: %w", err)
+	}
+	return nil
+}
+
+// RemovePIDFile removes the PID file if it belongs to the current process
+func (m *Manager) RemovePIDFile() error {
+	pid, _, err := m.readPIDFile()
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if pid != os.Getpid() {
+		return nil
+	}
+	if err := os.Remove(m.PIDFile()); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove PID file: %w", err)
+	}
+	return nil
+}
+
+// readPIDFile parses the PID file: first line is the PID, second the start time
+func (m *Manager) readPIDFile() (int, time.Time, error) {
+	content, err := os.ReadFile(m.PIDFile())
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	pid, err := strconv.Atoi(strings.TrimSpace(lines[0]))
+	if err != nil || pid <= 0 {
+		return 0, time.Time{}, fmt.Errorf("invalid PID file %s", m.PIDFile())
+	}
+
+	var startedAt time.Time
+	if len(lines) > 1 {
+		startedAt, _ = time.Parse(time.RFC3339, strings.TrimSpace(lines[1]))
+	}
+
+	return pid, startedAt, nil
+}
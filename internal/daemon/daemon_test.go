@@ -0,0 +1,197 @@
+package daemon
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/core"
+)
+
+func setupTestManager(t *testing.T) *Manager {
+	tempDir, err := os.MkdirTemp("", "timemachine-daemon-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(tempDir) })
+
+	shadowRepoDir := filepath.Join(tempDir, ".git", "timemachine_snapshots")
+	if err := os.MkdirAll(shadowRepoDir, 0755); err != nil {
+		t.Fatalf("Failed to create shadow repo dir: %v", err)
+	}
+
+	return NewManager(&core.AppState{
+		ProjectRoot:   tempDir,
+		GitDir:        filepath.Join(tempDir, ".git"),
+		ShadowRepoDir: shadowRepoDir,
+		IsInitialized: true,
+	})
+}
+
+func TestStatus_NoPIDFile(t *testing.T) {
+	manager := setupTestManager(t)
+
+	status, err := manager.Status()
+	if err != nil {
+		t.Fatalf("Status failed: %v", err)
+	}
+	if status.Running || status.Stale || status.PID != 0 {
+		t.Errorf("Expected not running with no PID file, got %+v", status)
+	}
+}
+
+func TestWriteAndRemovePIDFile(t *testing.T) {
+	manager := setupTestManager(t)
+
+	if err := manager.WritePIDFile(); err != nil {
+		t.Fatalf("WritePIDFile failed: %v", err)
+	}
+
+	// The test process itself is alive, so it should be reported as running
+	status, err := manager.Status()
+	if err != nil {
+		t.Fatalf("Status failed: %v", err)
+	}
+	if !status.Running {
+		t.Error("Expected daemon to be reported as running")
+	}
+	if status.PID != os.Getpid() {
+		t.Errorf("Expected PID %d, got %d", os.Getpid(), status.PID)
+	}
+	if status.StartedAt.IsZero() {
+		t.Error("Expected start time to be recorded")
+	}
+
+	if err := manager.RemovePIDFile(); err != nil {
+		t.Fatalf("RemovePIDFile failed: %v", err)
+	}
+	if _, err := os.Stat(manager.PIDFile()); !os.IsNotExist(err) {
+		t.Error("Expected PID file to be removed")
+	}
+}
+
+func TestRemovePIDFile_OtherProcess(t *testing.T) {
+	manager := setupTestManager(t)
+
+	// A PID file owned by another process must not be removed
+	content := fmt.Sprintf("%d\n", os.Getppid())
+	if err := os.WriteFile(manager.PIDFile(), []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write PID file: %v", err)
+	}
+
+	if err := manager.RemovePIDFile(); err != nil {
+		t.Fatalf("RemovePIDFile failed: %v", err)
+	}
+	if _, err := os.Stat(manager.PIDFile()); err != nil {
+		t.Error("PID file of another process should be left in place")
+	}
+}
+
+func TestStatus_StalePIDFile(t *testing.T) {
+	manager := setupTestManager(t)
+
+	// PIDs this large are not handed out on any supported platform
+	if err := os.WriteFile(manager.PIDFile(), []byte("99999999\n"), 0644); err != nil {
+		t.Fatalf("Failed to write PID file: %v", err)
+	}
+
+	status, err := manager.Status()
+	if err != nil {
+		t.Fatalf("Status failed: %v", err)
+	}
+	if status.Running {
+		t.Error("Expected stale daemon to be reported as not running")
+	}
+	if !status.Stale {
+		t.Error("Expected stale PID file to be detected")
+	}
+	if _, err := os.Stat(manager.PIDFile()); !os.IsNotExist(err) {
+		t.Error("Expected stale PID file to be cleaned up")
+	}
+}
+
+func TestStatus_InvalidPIDFile(t *testing.T) {
+	manager := setupTestManager(t)
+
+	if err := os.WriteFile(manager.PIDFile(), []byte("not-a-pid\n"), 0644); err != nil {
+		t.Fatalf("Failed to write PID file: %v", err)
+	}
+
+	if _, err := manager.Status(); err == nil {
+		t.Error("Expected error for invalid PID file")
+	}
+}
+
+func TestStop_NotRunning(t *testing.T) {
+	manager := setupTestManager(t)
+
+	if _, err := manager.Stop(); err != ErrNotRunning {
+		t.Errorf("Expected ErrNotRunning, got %v", err)
+	}
+}
+
+func TestWriteAndReadState(t *testing.T) {
+	manager := setupTestManager(t)
+
+	if _, err := manager.ReadState(); !os.IsNotExist(err) {
+		t.Errorf("Expected not-exist error before any state is written, got %v", err)
+	}
+
+	want := &WatcherState{
+		PID:                os.Getpid(),
+		LastSnapshotHash:   "abc123",
+		SnapshotsSinceHead: 4,
+		LastError:          "disk full",
+	}
+	if err := manager.WriteState(want); err != nil {
+		t.Fatalf("WriteState failed: %v", err)
+	}
+
+	got, err := manager.ReadState()
+	if err != nil {
+		t.Fatalf("ReadState failed: %v", err)
+	}
+	if got.LastSnapshotHash != want.LastSnapshotHash || got.SnapshotsSinceHead != want.SnapshotsSinceHead ||
+		got.LastError != want.LastError {
+		t.Errorf("State round trip mismatch: got %+v, want %+v", got, want)
+	}
+	if !got.WatcherAlive() {
+		t.Error("Expected state written by this process to report a live watcher")
+	}
+}
+
+func TestRefreshActivityKeepsLastError(t *testing.T) {
+	manager := setupTestManager(t)
+	gitManager := core.NewGitManager(manager.State)
+
+	stats := &core.WatcherStats{WatchedDirs: 7, PendingChanges: 2, IgnoreCacheHits: 3, IgnoreCacheMisses: 1, IgnoreCacheHitRate: 75}
+	if err := manager.RefreshState(gitManager, stats, errors.New("disk full")); err != nil {
+		t.Fatalf("RefreshState failed: %v", err)
+	}
+
+	// A periodic refresh publishes new activity but keeps the failure visible
+	stats.PendingChanges = 5
+	if err := manager.RefreshActivity(gitManager, stats); err != nil {
+		t.Fatalf("RefreshActivity failed: %v", err)
+	}
+	got, err := manager.ReadState()
+	if err != nil {
+		t.Fatalf("ReadState failed: %v", err)
+	}
+	if got.WatchedDirs != 7 || got.PendingChanges != 5 || got.IgnoreCacheHitRate != 75 {
+		t.Errorf("Expected published watcher activity, got %+v", got)
+	}
+	if got.LastError != "disk full" {
+		t.Errorf("Expected last error to be kept, got %q", got.LastError)
+	}
+
+	// The next successful snapshot clears it
+	if err := manager.RefreshState(gitManager, stats, nil); err != nil {
+		t.Fatalf("RefreshState failed: %v", err)
+	}
+	if got, _ := manager.ReadState(); got == nil || got.LastError != "" {
+		t.Errorf("Expected last error to be cleared, got %+v", got)
+	}
+}
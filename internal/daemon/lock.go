@@ -0,0 +1,92 @@
+package daemon
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/core"
+)
+
+// LockFileName is held by the running watcher, in the background or in the
+// foreground, so that a second one in the same repository fails fast
+// instead of interleaving snapshots and branch switches with it
+const LockFileName = "watcher.lock"
+
+// LockedError reports that another watcher holds the lock
+type LockedError struct {
+	PID int // 0 if the holder couldn't be identified
+}
+
+func (e *LockedError) Error() string {
+	if e.PID == 0 {
+		return "another watcher is already running in this repository"
+	}
+	return fmt.Sprintf("another watcher (PID %d) is already running in this repository", e.PID)
+}
+
+// Unwrap makes a locked repository exit with core.ExitBusy
+func (e *LockedError) Unwrap() error {
+	return core.ErrWatcherRunning
+}
+
+// WatcherLock is the lock a running watcher holds until Release
+type WatcherLock struct {
+	file *os.File
+	path string
+}
+
+// LockFile returns the path of the watcher lock file
+func (m *Manager) LockFile() string {
+	return filepath.Join(m.State.ShadowRepoDir, LockFileName)
+}
+
+// AcquireWatcherLock takes the watcher lock for the current process. It
+// fails with a *LockedError while another live process holds it; a lock
+// left by a process that died is taken over.
+func (m *Manager) AcquireWatcherLock() (*WatcherLock, error) {
+	file, err := lockFile(m.LockFile())
+	if err != nil {
+		return nil, err
+	}
+
+	// The PID is only informational, for the error other processes report
+	if err := file.Truncate(0); err == nil {
+		file.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0)
+	}
+	return &WatcherLock{file: file, path: m.LockFile()}, nil
+}
+
+// WatcherLockHolder returns the PID of the live process holding the watcher
+// lock, and whether there is one
+func (m *Manager) WatcherLockHolder() (int, bool) {
+	lock, err := m.AcquireWatcherLock()
+	if err == nil {
+		lock.Release()
+		return 0, false
+	}
+	if locked, ok := err.(*LockedError); ok {
+		return locked.PID, true
+	}
+	return 0, false
+}
+
+// Release gives up the lock
+func (l *WatcherLock) Release() error {
+	return unlockFile(l.file, l.path)
+}
+
+// lockHolderPID reads the PID a lock file records, 0 if it can't
+func lockHolderPID(path string) int {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return 0
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(content)))
+	if err != nil || pid <= 0 {
+		return 0
+	}
+	return pid
+}
@@ -0,0 +1,46 @@
+package daemon
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/core"
+)
+
+func TestWatcherLock(t *testing.T) {
+	manager := setupTestManager(t)
+
+	if _, running := manager.WatcherLockHolder(); running {
+		t.Fatal("Expected no lock holder before locking")
+	}
+	lock, err := manager.AcquireWatcherLock()
+	if err != nil {
+		t.Fatalf("AcquireWatcherLock failed: %v", err)
+	}
+
+	// A second watcher fails fast, naming the first
+	_, err = manager.AcquireWatcherLock()
+	var locked *LockedError
+	if !errors.As(err, &locked) || locked.PID != os.Getpid() {
+		t.Fatalf("Expected a LockedError naming PID %d, got %v", os.Getpid(), err)
+	}
+	if code := core.ExitCode(err); code != core.ExitBusy {
+		t.Errorf("Expected a locked repository to exit %d, got %d", core.ExitBusy, code)
+	}
+	if pid, running := manager.WatcherLockHolder(); !running || pid != os.Getpid() {
+		t.Errorf("Expected the lock holder to be PID %d, got %d (%v)", os.Getpid(), pid, running)
+	}
+	if _, err := manager.Start(); err == nil {
+		t.Error("Expected the daemon not to start while a watcher holds the lock")
+	}
+
+	if err := lock.Release(); err != nil {
+		t.Fatalf("Release failed: %v", err)
+	}
+	lock, err = manager.AcquireWatcherLock()
+	if err != nil {
+		t.Fatalf("Expected the lock to be free after Release, got %v", err)
+	}
+	lock.Release()
+}
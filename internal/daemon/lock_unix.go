@@ -0,0 +1,35 @@
+//go:build !windows
+
+package daemon
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// lockFile takes an exclusive flock on path without waiting. The kernel
+// drops it when the holder exits, however it exits, so there are no stale
+// locks to detect.
+func lockFile(path string) (*os.File, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open watcher lock: %w", err)
+	}
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		file.Close()
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return nil, &LockedError{PID: lockHolderPID(path)}
+		}
+		return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+	}
+	return file, nil
+}
+
+// unlockFile releases the lock. The file stays: removing it would let a
+// process that opened it before the removal lock a file nobody else sees.
+func unlockFile(file *os.File, path string) error {
+	file.Truncate(0)
+	return file.Close()
+}
@@ -0,0 +1,46 @@
+//go:build windows
+
+package daemon
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// lockFile creates path exclusively. A lock file whose process is gone is
+// stale (a crash or a reboot) and is replaced; one without a PID yet is
+// stale once it is too old to be still being written.
+func lockFile(path string) (*os.File, error) {
+	for attempt := 0; ; attempt++ {
+		file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0644)
+		if err == nil {
+			return file, nil
+		}
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("failed to create watcher lock: %w", err)
+		}
+		pid := lockHolderPID(path)
+		if attempt > 0 || pid != 0 && processAlive(pid) || pid == 0 && !lockOld(path) {
+			return nil, &LockedError{PID: pid}
+		}
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return nil, &LockedError{PID: pid}
+		}
+	}
+}
+
+// lockOld reports whether a lock file was written a while ago
+func lockOld(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && time.Since(info.ModTime()) > startupTimeout
+}
+
+// unlockFile releases the lock by removing the file
+func unlockFile(file *os.File, path string) error {
+	file.Close()
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove watcher lock: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+//go:build windows
+
+package daemon
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+const (
+	createNewProcessGroup = 0x00000200
+	detachedProcess       = 0x00000008
+)
+
+// detachedProcAttr starts the child without a console so closing the
+// launching terminal does not kill it
+func detachedProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{
+		CreationFlags: createNewProcessGroup | detachedProcess,
+		HideWindow:    true,
+	}
+}
+
+// processAlive reports whether a process with the given PID exists.
+// On Windows FindProcess opens a handle and fails if the process is gone.
+func processAlive(pid int) bool {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	process.Release()
+	return true
+}
+
+// reloadProcess would ask a running watcher to reload its configuration.
+// Windows cannot deliver SIGHUP, so the watcher has to be restarted instead.
+func reloadProcess(pid int) error {
+	return errors.New("reloading a running watcher is not supported on Windows; restart it with 'timemachine stop' and 'timemachine start'")
+}
+
+// terminateProcess stops the daemon. Windows has no SIGTERM delivery for
+// detached processes, so the process is killed directly.
+func terminateProcess(pid int) error {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	return process.Kill()
+}
@@ -0,0 +1,112 @@
+package daemon
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// RegistryFileName is the repository registry in the user configuration
+// directory
+const RegistryFileName = "repos.json"
+
+// RegisteredRepo is a repository managed with 'timemachine repos'
+type RegisteredRepo struct {
+	Path    string    `json:"path"` // Project root
+	AddedAt time.Time `json:"added_at"`
+}
+
+// Registry lists the repositories whose watchers are managed together. Each
+// repository keeps its own shadow repository, configuration and background
+// watcher; the registry only remembers where they are.
+type Registry struct {
+	Repos []RegisteredRepo `json:"repos"`
+
+	path string
+}
+
+// RegistryPath returns where the registry is stored
+func RegistryPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate configuration directory: %w", err)
+	}
+	return filepath.Join(dir, "timemachine", RegistryFileName), nil
+}
+
+// LoadRegistry reads the registry; a missing registry is empty
+func LoadRegistry() (*Registry, error) {
+	path, err := RegistryPath()
+	if err != nil {
+		return nil, err
+	}
+
+	registry := &Registry{path: path}
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return registry, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read repository registry: %w", err)
+	}
+	if err := json.Unmarshal(content, registry); err != nil {
+		return nil, fmt.Errorf("invalid repository registry %s: %w", path, err)
+	}
+	return registry, nil
+}
+
+// Add registers a project root, reporting false if it already was
+func (r *Registry) Add(root string) bool {
+	if r.Contains(root) {
+		return false
+	}
+	r.Repos = append(r.Repos, RegisteredRepo{Path: root, AddedAt: time.Now()})
+	sort.Slice(r.Repos, func(i, j int) bool { return r.Repos[i].Path < r.Repos[j].Path })
+	return true
+}
+
+// Remove unregisters a project root, reporting false if it wasn't registered
+func (r *Registry) Remove(root string) bool {
+	for i, repo := range r.Repos {
+		if repo.Path == root {
+			r.Repos = append(r.Repos[:i], r.Repos[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Contains reports whether a project root is registered
+func (r *Registry) Contains(root string) bool {
+	for _, repo := range r.Repos {
+		if repo.Path == root {
+			return true
+		}
+	}
+	return false
+}
+
+// Save writes the registry atomically
+func (r *Registry) Save() error {
+	if err := os.MkdirAll(filepath.Dir(r.path), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(r.path), err)
+	}
+
+	content, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode repository registry: %w", err)
+	}
+
+	tmp := r.path + ".tmp"
+	if err := os.WriteFile(tmp, append(content, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write repository registry: %w", err)
+	}
+	if err := os.Rename(tmp, r.path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write repository registry: %w", err)
+	}
+	return nil
+}
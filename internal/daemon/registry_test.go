@@ -0,0 +1,86 @@
+package daemon
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// setupTestRegistry points the user configuration directory at a temp dir
+func setupTestRegistry(t *testing.T) string {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+	t.Setenv("AppData", dir)
+	return dir
+}
+
+func TestRegistryAddRemoveSave(t *testing.T) {
+	setupTestRegistry(t)
+
+	registry, err := LoadRegistry()
+	if err != nil {
+		t.Fatalf("LoadRegistry failed: %v", err)
+	}
+	if len(registry.Repos) != 0 {
+		t.Fatalf("Expected an empty registry, got %d repos", len(registry.Repos))
+	}
+
+	if !registry.Add("/src/web") || !registry.Add("/src/api") {
+		t.Fatalf("Expected new repositories to be added")
+	}
+	if registry.Add("/src/web") {
+		t.Errorf("Expected a registered repository not to be added twice")
+	}
+	if err := registry.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	path, err := RegistryPath()
+	if err != nil {
+		t.Fatalf("RegistryPath failed: %v", err)
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("Expected no temporary file to be left behind")
+	}
+
+	reloaded, err := LoadRegistry()
+	if err != nil {
+		t.Fatalf("LoadRegistry failed: %v", err)
+	}
+	if len(reloaded.Repos) != 2 || reloaded.Repos[0].Path != "/src/api" || reloaded.Repos[1].Path != "/src/web" {
+		t.Fatalf("Expected [/src/api /src/web], got %+v", reloaded.Repos)
+	}
+	if reloaded.Repos[0].AddedAt.IsZero() {
+		t.Errorf("Expected the registration time to be recorded")
+	}
+
+	if !reloaded.Remove("/src/api") {
+		t.Errorf("Expected /src/api to be removed")
+	}
+	if reloaded.Remove("/src/api") || reloaded.Contains("/src/api") {
+		t.Errorf("Expected /src/api to be gone")
+	}
+	if !reloaded.Contains("/src/web") {
+		t.Errorf("Expected /src/web to stay registered")
+	}
+}
+
+func TestLoadRegistryInvalid(t *testing.T) {
+	setupTestRegistry(t)
+
+	path, err := RegistryPath()
+	if err != nil {
+		t.Fatalf("RegistryPath failed: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("{not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadRegistry(); err == nil {
+		t.Errorf("Expected an error for a corrupt registry")
+	}
+}
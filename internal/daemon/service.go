@@ -0,0 +1,169 @@
+package daemon
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Services run the watcher at login (a systemd user unit on Linux, a
+// LaunchAgent on macOS, a logon task on Windows) so snapshots resume after a
+// reboot. Each repository gets its own service, and its definition file
+// records the repository, so the installed definitions are the list of
+// auto-started repositories.
+
+// servicePrefix starts the name of every Time Machine service
+const servicePrefix = "timemachine-"
+
+// ErrServicesUnsupported is returned on platforms without a supported
+// service manager
+var ErrServicesUnsupported = errors.New("auto-start services are not supported on this platform")
+
+// Service is an installed auto-start service
+type Service struct {
+	Name string // Unit, agent or task name
+	Repo string // Project root the watcher runs in
+	File string // Definition file
+}
+
+// ServiceState is what the service manager reports about a service
+type ServiceState struct {
+	Active bool   // Loaded and running (or, on Windows, scheduled)
+	Detail string // The service manager's own word for the state
+}
+
+// runServiceCommand runs a service manager command; tests replace it
+var runServiceCommand = func(name string, args ...string) ([]byte, error) {
+	return exec.Command(name, args...).CombinedOutput()
+}
+
+// ServiceName derives a stable service name from a project root: the
+// directory name, for humans, and a hash of the full path, for uniqueness
+func ServiceName(repo string) string {
+	sum := sha256.Sum256([]byte(repo))
+	base := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + 'a' - 'A'
+		}
+		return '-'
+	}, filepath.Base(repo))
+	return servicePrefix + strings.Trim(base, "-") + "-" + hex.EncodeToString(sum[:4])
+}
+
+// InstallService registers and starts the watcher service for a project
+// root, replacing an existing definition. exe is the timemachine binary and
+// logFile receives the watcher's output where the service manager doesn't
+// keep it.
+func InstallService(repo, exe, logFile string) (*Service, error) {
+	dir, err := serviceDir()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+
+	name := ServiceName(repo)
+	svc := &Service{Name: name, Repo: repo, File: filepath.Join(dir, serviceFile(name))}
+	if _, err := os.Stat(svc.File); err == nil {
+		// Reload a changed definition (e.g. a new binary path)
+		deactivateService(*svc)
+	}
+
+	if err := os.WriteFile(svc.File, []byte(renderService(name, repo, exe, logFile)), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", svc.File, err)
+	}
+	if err := activateService(*svc); err != nil {
+		// Don't leave a service behind that was never registered
+		os.Remove(svc.File)
+		serviceRemoved(*svc)
+		return nil, err
+	}
+	return svc, nil
+}
+
+// UninstallService stops a service and removes its definition
+func UninstallService(svc Service) error {
+	deactivateErr := deactivateService(svc)
+	if err := os.Remove(svc.File); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove %s: %w", svc.File, err)
+	}
+	if err := serviceRemoved(svc); err != nil {
+		return err
+	}
+	return deactivateErr
+}
+
+// ListServices returns the installed services, sorted by repository
+func ListServices() ([]Service, error) {
+	dir, err := serviceDir()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list services: %w", err)
+	}
+
+	var services []Service
+	for _, entry := range entries {
+		name, ok := serviceNameOf(entry.Name())
+		if !ok || !strings.HasPrefix(name, servicePrefix) {
+			continue
+		}
+		file := filepath.Join(dir, entry.Name())
+		content, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		services = append(services, Service{Name: name, Repo: serviceRepo(string(content)), File: file})
+	}
+
+	sort.Slice(services, func(i, j int) bool { return services[i].Repo < services[j].Repo })
+	return services, nil
+}
+
+// FindService returns the installed service for a project root, or nil
+func FindService(repo string) (*Service, error) {
+	services, err := ListServices()
+	if err != nil {
+		return nil, err
+	}
+	name := ServiceName(repo)
+	for _, svc := range services {
+		if svc.Name == name {
+			return &svc, nil
+		}
+	}
+	return nil, nil
+}
+
+// State asks the service manager about the service
+func (s Service) State() ServiceState {
+	return serviceState(s)
+}
+
+// serviceCommand runs a service manager command, folding its output into
+// the error
+func serviceCommand(name string, args ...string) error {
+	output, err := runServiceCommand(name, args...)
+	if errors.Is(err, exec.ErrNotFound) {
+		return fmt.Errorf("%s not found on PATH: %w", name, ErrServicesUnsupported)
+	}
+	if err != nil {
+		return fmt.Errorf("%s %s failed: %w\n%s", name, strings.Join(args, " "), err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
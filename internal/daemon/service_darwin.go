@@ -0,0 +1,108 @@
+//go:build darwin
+
+package daemon
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ServiceManager names the service manager used on this platform
+const ServiceManager = "launchd (LaunchAgents)"
+
+// ServiceNote explains when services start on this platform
+const ServiceNote = "Services start when you log in."
+
+// serviceLabelPrefix namespaces the LaunchAgent labels
+const serviceLabelPrefix = "com.timemachine-cli."
+
+func serviceDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate home directory: %w", err)
+	}
+	return filepath.Join(home, "Library", "LaunchAgents"), nil
+}
+
+func serviceFile(name string) string {
+	return serviceLabelPrefix + name + ".plist"
+}
+
+func serviceNameOf(file string) (string, bool) {
+	name, ok := strings.CutPrefix(file, serviceLabelPrefix)
+	if !ok {
+		return "", false
+	}
+	return strings.CutSuffix(name, ".plist")
+}
+
+func renderService(name, repo, exe, logFile string) string {
+	escape := html.EscapeString
+	return fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Label</key>
+	<string>%s</string>
+	<key>ProgramArguments</key>
+	<array>
+		<string>%s</string>
+		<string>start</string>
+	</array>
+	<key>WorkingDirectory</key>
+	<string>%s</string>
+	<key>RunAtLoad</key>
+	<true/>
+	<key>KeepAlive</key>
+	<dict>
+		<key>SuccessfulExit</key>
+		<false/>
+	</dict>
+	<key>ProcessType</key>
+	<string>Background</string>
+	<key>StandardOutPath</key>
+	<string>%s</string>
+	<key>StandardErrorPath</key>
+	<string>%s</string>
+</dict>
+</plist>
+`, escape(serviceLabelPrefix+name), escape(exe), escape(repo), escape(logFile), escape(logFile))
+}
+
+var workingDirectoryPattern = regexp.MustCompile(`<key>WorkingDirectory</key>\s*<string>([^<]*)</string>`)
+
+func serviceRepo(content string) string {
+	if match := workingDirectoryPattern.FindStringSubmatch(content); match != nil {
+		return html.UnescapeString(match[1])
+	}
+	return ""
+}
+
+func activateService(svc Service) error {
+	return serviceCommand("launchctl", "load", "-w", svc.File)
+}
+
+func deactivateService(svc Service) error {
+	return serviceCommand("launchctl", "unload", "-w", svc.File)
+}
+
+func serviceRemoved(svc Service) error {
+	return nil
+}
+
+var launchdPIDPattern = regexp.MustCompile(`"PID" = (\d+);`)
+
+func serviceState(svc Service) ServiceState {
+	output, err := runServiceCommand("launchctl", "list", serviceLabelPrefix+svc.Name)
+	if err != nil {
+		return ServiceState{Detail: "not loaded"}
+	}
+	if launchdPIDPattern.Match(output) {
+		return ServiceState{Active: true, Detail: "running"}
+	}
+	return ServiceState{Detail: "loaded, not running"}
+}
@@ -0,0 +1,97 @@
+//go:build linux
+
+package daemon
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ServiceManager names the service manager used on this platform
+const ServiceManager = "systemd (user units)"
+
+// ServiceNote explains when services start on this platform
+const ServiceNote = "Services start when you log in. To start them at boot without logging in, run 'loginctl enable-linger'."
+
+// serviceDir is where systemd looks for user units
+func serviceDir() (string, error) {
+	config := os.Getenv("XDG_CONFIG_HOME")
+	if config == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to locate home directory: %w", err)
+		}
+		config = filepath.Join(home, ".config")
+	}
+	return filepath.Join(config, "systemd", "user"), nil
+}
+
+func serviceFile(name string) string {
+	return name + ".service"
+}
+
+func serviceNameOf(file string) (string, bool) {
+	return strings.CutSuffix(file, ".service")
+}
+
+// systemdEscape protects % from specifier expansion
+func systemdEscape(value string) string {
+	return strings.ReplaceAll(value, "%", "%%")
+}
+
+func renderService(name, repo, exe, logFile string) string {
+	// The watcher logs to stdout, which the journal keeps:
+	// journalctl --user -u <name>
+	return fmt.Sprintf(`[Unit]
+Description=Time Machine watcher for %s
+
+[Service]
+Type=simple
+WorkingDirectory=%s
+ExecStart="%s" start
+Restart=on-failure
+RestartSec=10
+
+[Install]
+WantedBy=default.target
+`, systemdEscape(repo), systemdEscape(repo), systemdEscape(exe))
+}
+
+func serviceRepo(content string) string {
+	for _, line := range strings.Split(content, "\n") {
+		if value, ok := strings.CutPrefix(line, "WorkingDirectory="); ok {
+			return strings.ReplaceAll(value, "%%", "%")
+		}
+	}
+	return ""
+}
+
+func systemctl(args ...string) error {
+	return serviceCommand("systemctl", append([]string{"--user"}, args...)...)
+}
+
+func activateService(svc Service) error {
+	if err := systemctl("daemon-reload"); err != nil {
+		return err
+	}
+	return systemctl("enable", "--now", serviceFile(svc.Name))
+}
+
+func deactivateService(svc Service) error {
+	return systemctl("disable", "--now", serviceFile(svc.Name))
+}
+
+func serviceRemoved(svc Service) error {
+	return systemctl("daemon-reload")
+}
+
+func serviceState(svc Service) ServiceState {
+	output, _ := runServiceCommand("systemctl", "--user", "is-active", serviceFile(svc.Name))
+	detail := strings.TrimSpace(string(output))
+	if detail == "" {
+		detail = "unknown"
+	}
+	return ServiceState{Active: detail == "active", Detail: detail}
+}
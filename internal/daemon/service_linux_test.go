@@ -0,0 +1,74 @@
+package daemon
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestServiceName(t *testing.T) {
+	name := ServiceName("/home/dev/My Project")
+	if !strings.HasPrefix(name, "timemachine-my-project-") || len(name) != len("timemachine-my-project-")+8 {
+		t.Errorf("Unexpected service name %q", name)
+	}
+	if ServiceName("/home/dev/My Project") != name {
+		t.Errorf("Expected the service name to be stable")
+	}
+	if ServiceName("/other/My Project") == name {
+		t.Errorf("Expected repositories with the same name to get different services")
+	}
+}
+
+func TestInstallAndUninstallService(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	var commands []string
+	original := runServiceCommand
+	defer func() { runServiceCommand = original }()
+	runServiceCommand = func(name string, args ...string) ([]byte, error) {
+		commands = append(commands, name+" "+strings.Join(args, " "))
+		return []byte("active\n"), nil
+	}
+
+	repo := "/home/dev/100% project"
+	svc, err := InstallService(repo, "/usr/local/bin/timemachine", "/tmp/daemon.log")
+	if err != nil {
+		t.Fatalf("InstallService failed: %v", err)
+	}
+
+	unit, err := os.ReadFile(svc.File)
+	if err != nil {
+		t.Fatalf("Expected a unit file: %v", err)
+	}
+	if !strings.Contains(string(unit), "WorkingDirectory=/home/dev/100%% project\n") {
+		t.Errorf("Expected an escaped working directory, got:\n%s", unit)
+	}
+	if !strings.Contains(string(unit), `ExecStart="/usr/local/bin/timemachine" start`) {
+		t.Errorf("Expected the unit to run the watcher, got:\n%s", unit)
+	}
+	if last := commands[len(commands)-1]; last != "systemctl --user enable --now "+svc.Name+".service" {
+		t.Errorf("Expected the service to be enabled, got %q", last)
+	}
+
+	services, err := ListServices()
+	if err != nil || len(services) != 1 || services[0].Repo != repo {
+		t.Fatalf("Expected the installed service for %q, got %+v (%v)", repo, services, err)
+	}
+	if state := services[0].State(); !state.Active {
+		t.Errorf("Expected the service to be active, got %+v", state)
+	}
+
+	if err := UninstallService(*svc); err != nil {
+		t.Fatalf("UninstallService failed: %v", err)
+	}
+	if _, err := os.Stat(svc.File); !os.IsNotExist(err) {
+		t.Errorf("Expected the unit file to be removed")
+	}
+	if found, _ := FindService(repo); found != nil {
+		t.Errorf("Expected no service after uninstalling, got %+v", found)
+	}
+	if entries, _ := os.ReadDir(filepath.Dir(svc.File)); len(entries) != 0 {
+		t.Errorf("Expected the unit directory to be empty, found %d entries", len(entries))
+	}
+}
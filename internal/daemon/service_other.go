@@ -0,0 +1,45 @@
+//go:build !linux && !darwin && !windows
+
+package daemon
+
+// ServiceManager names the service manager used on this platform
+const ServiceManager = "none"
+
+// ServiceNote explains when services start on this platform
+const ServiceNote = "Start the watcher with 'timemachine start --daemon' from your login scripts instead."
+
+func serviceDir() (string, error) {
+	return "", ErrServicesUnsupported
+}
+
+func serviceFile(name string) string {
+	return name
+}
+
+func serviceNameOf(file string) (string, bool) {
+	return file, false
+}
+
+func renderService(name, repo, exe, logFile string) string {
+	return ""
+}
+
+func serviceRepo(content string) string {
+	return ""
+}
+
+func activateService(svc Service) error {
+	return ErrServicesUnsupported
+}
+
+func deactivateService(svc Service) error {
+	return ErrServicesUnsupported
+}
+
+func serviceRemoved(svc Service) error {
+	return nil
+}
+
+func serviceState(svc Service) ServiceState {
+	return ServiceState{Detail: "unsupported"}
+}
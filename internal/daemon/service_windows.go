@@ -0,0 +1,88 @@
+//go:build windows
+
+package daemon
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ServiceManager names the service manager used on this platform
+const ServiceManager = "Task Scheduler (logon tasks)"
+
+// ServiceNote explains when services start on this platform
+const ServiceNote = "Tasks start the background watcher when you log on."
+
+// serviceTaskFolder groups the scheduled tasks
+const serviceTaskFolder = `TimeMachine\`
+
+// serviceDir holds the launcher scripts the scheduled tasks run
+func serviceDir() (string, error) {
+	config, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate configuration directory: %w", err)
+	}
+	return filepath.Join(config, "timemachine", "services"), nil
+}
+
+func serviceFile(name string) string {
+	return name + ".cmd"
+}
+
+func serviceNameOf(file string) (string, bool) {
+	return strings.CutSuffix(file, ".cmd")
+}
+
+// cmdEscape protects % from variable expansion in batch files
+func cmdEscape(value string) string {
+	return strings.ReplaceAll(value, "%", "%%")
+}
+
+func renderService(name, repo, exe, logFile string) string {
+	// A logon task has no console to keep a foreground watcher in, so it
+	// starts the background watcher, which logs to the daemon log
+	return fmt.Sprintf("@echo off\r\nrem Time Machine watcher, started at logon by the scheduled task %s%s\r\ncd /d \"%s\"\r\n\"%s\" start --daemon\r\n",
+		serviceTaskFolder, name, cmdEscape(repo), cmdEscape(exe))
+}
+
+func serviceRepo(content string) string {
+	for _, line := range strings.Split(content, "\r\n") {
+		if value, ok := strings.CutPrefix(line, "cd /d "); ok {
+			return strings.ReplaceAll(strings.Trim(value, `"`), "%%", "%")
+		}
+	}
+	return ""
+}
+
+func activateService(svc Service) error {
+	if err := serviceCommand("schtasks", "/Create", "/F", "/TN", serviceTaskFolder+svc.Name,
+		"/SC", "ONLOGON", "/RL", "LIMITED", "/TR", `"`+svc.File+`"`); err != nil {
+		return err
+	}
+	return serviceCommand("schtasks", "/Run", "/TN", serviceTaskFolder+svc.Name)
+}
+
+func deactivateService(svc Service) error {
+	return serviceCommand("schtasks", "/Delete", "/F", "/TN", serviceTaskFolder+svc.Name)
+}
+
+func serviceRemoved(svc Service) error {
+	return nil
+}
+
+func serviceState(svc Service) ServiceState {
+	output, err := runServiceCommand("schtasks", "/Query", "/TN", serviceTaskFolder+svc.Name, "/FO", "CSV", "/NH")
+	if err != nil {
+		return ServiceState{Detail: "not registered"}
+	}
+	// "TaskName","Next Run Time","Status"
+	records, err := csv.NewReader(strings.NewReader(string(output))).ReadAll()
+	if err != nil || len(records) == 0 || len(records[0]) < 3 {
+		return ServiceState{Active: true, Detail: "registered"}
+	}
+	status := strings.ToLower(records[0][2])
+	return ServiceState{Active: status != "disabled", Detail: status}
+}
@@ -0,0 +1,165 @@
+package daemon
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/core"
+)
+
+// StateFileName is the cached watcher state consumed by cheap readers
+// such as the shell prompt helper
+const StateFileName = "state.json"
+
+// WatcherState is a small snapshot of the running watcher's progress,
+// rewritten after every snapshot attempt
+type WatcherState struct {
+	PID                int       `json:"pid"`
+	UpdatedAt          time.Time `json:"updated_at"`
+	LastSnapshotAt     time.Time `json:"last_snapshot_at,omitempty"`
+	LastSnapshotHash   string    `json:"last_snapshot_hash,omitempty"`
+	MainHead           string    `json:"main_head,omitempty"`
+	SnapshotsSinceHead int       `json:"snapshots_since_head"`
+	LastError          string    `json:"last_error,omitempty"`
+
+	// Watcher activity, for 'timemachine status'
+	StartedAt            time.Time `json:"started_at,omitempty"`
+	WatchedDirs          int       `json:"watched_dirs"`
+	WatchedFiles         int       `json:"watched_files"`
+	PolledDirs           int       `json:"polled_dirs"`
+	PendingChanges       int       `json:"pending_changes"`
+	SnapshotPending      bool      `json:"snapshot_pending"`
+	IgnoreCacheHits      int64     `json:"ignore_cache_hits"`
+	IgnoreCacheMisses    int64     `json:"ignore_cache_misses"`
+	IgnoreCacheHitRate   float64   `json:"ignore_cache_hit_rate"`
+	IgnoreCacheEvictions int64     `json:"ignore_cache_evictions"`
+
+	// Outcome of the last 'timemachine config reload' or SIGHUP
+	LastConfigReload *core.ConfigReload `json:"last_config_reload,omitempty"`
+
+	// Last garbage collection of the shadow repository (git.auto_gc)
+	LastGC *core.GCRun `json:"last_gc,omitempty"`
+
+	// Last re-initialization of a broken shadow repository (git.auto_heal)
+	LastHeal *core.ShadowHeal `json:"last_heal,omitempty"`
+
+	// Where the watcher's ignore patterns come from, in the order applied
+	IgnoreSources []core.IgnoreSource `json:"ignore_sources,omitempty"`
+}
+
+// WatcherAlive reports whether the process that wrote this state is still running
+func (s *WatcherState) WatcherAlive() bool {
+	return s.PID > 0 && processAlive(s.PID)
+}
+
+// StateFile returns the path of the cached watcher state file
+func (m *Manager) StateFile() string {
+	return filepath.Join(m.State.ShadowRepoDir, StateFileName)
+}
+
+// ReadState loads the cached watcher state
+func (m *Manager) ReadState() (*WatcherState, error) {
+	content, err := os.ReadFile(m.StateFile())
+	if err != nil {
+		return nil, err
+	}
+
+	var state WatcherState
+	if err := json.Unmarshal(content, &state); err != nil {
+		return nil, fmt.Errorf("invalid watcher state file: %w", err)
+	}
+
+	return &state, nil
+}
+
+// WriteState atomically replaces the cached watcher state
+func (m *Manager) WriteState(state *WatcherState) error {
+	content, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode watcher state: %w", err)
+	}
+
+	// Write to a temp file and rename so readers never see a partial file
+	tmpFile := m.StateFile() + ".tmp"
+	if err := os.WriteFile(tmpFile, content, 0644); err != nil {
+		return fmt.Errorf("failed to write watcher state: %w", err)
+	}
+	if err := os.Rename(tmpFile, m.StateFile()); err != nil {
+		os.Remove(tmpFile)
+		return fmt.Errorf("failed to replace watcher state: %w", err)
+	}
+
+	return nil
+}
+
+// RefreshState recomputes the cached watcher state after a snapshot attempt.
+// This runs in the watcher so that readers only need to parse a JSON file.
+func (m *Manager) RefreshState(gitManager *core.GitManager, stats *core.WatcherStats, snapshotErr error) error {
+	m.mu.Lock()
+	m.lastError = ""
+	if snapshotErr != nil {
+		m.lastError = snapshotErr.Error()
+	}
+	m.mu.Unlock()
+
+	return m.RefreshActivity(gitManager, stats)
+}
+
+// RefreshActivity rewrites the cached watcher state between snapshots, so the
+// watcher's activity (stats, when not nil) stays current. The error of the
+// last snapshot attempt is kept.
+func (m *Manager) RefreshActivity(gitManager *core.GitManager, stats *core.WatcherStats) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	state := &WatcherState{
+		PID:       os.Getpid(),
+		UpdatedAt: time.Now().UTC(),
+		LastError: m.lastError,
+	}
+	if stats != nil {
+		state.StartedAt = stats.StartedAt.UTC()
+		state.WatchedDirs = stats.WatchedDirs
+		state.WatchedFiles = stats.WatchedFiles
+		state.PolledDirs = stats.PolledDirs
+		state.PendingChanges = stats.PendingChanges
+		state.SnapshotPending = stats.SnapshotPending
+		state.IgnoreCacheHits = stats.IgnoreCacheHits
+		state.IgnoreCacheMisses = stats.IgnoreCacheMisses
+		state.IgnoreCacheHitRate = stats.IgnoreCacheHitRate
+		state.IgnoreCacheEvictions = stats.IgnoreCacheEvictions
+		state.LastConfigReload = stats.LastConfigReload
+		state.LastGC = stats.LastGC
+		state.LastHeal = stats.LastHeal
+		state.IgnoreSources = stats.IgnoreSources
+	}
+
+	if output, err := gitManager.RunCommand("log", "-1", "--format=%H|%ct"); err == nil {
+		parts := strings.SplitN(output, "|", 2)
+		if len(parts) == 2 {
+			state.LastSnapshotHash = parts[0]
+			if seconds, err := strconv.ParseInt(parts[1], 10, 64); err == nil {
+				state.LastSnapshotAt = time.Unix(seconds, 0).UTC()
+			}
+		}
+	}
+
+	// Without a main repo commit yet, every snapshot counts as "since HEAD"
+	var since time.Time
+	if _, head, err := core.ReadHead(m.State.GitDir); err == nil && head != "" {
+		state.MainHead = head
+		if headTime, err := gitManager.MainHeadTime(); err == nil {
+			since = headTime
+		}
+	}
+	if count, err := gitManager.CountSnapshotsSince(since); err == nil {
+		state.SnapshotsSinceHead = count
+	}
+
+	return m.WriteState(state)
+}
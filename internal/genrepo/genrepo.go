@@ -0,0 +1,293 @@
+// Package genrepo fabricates synthetic repositories and change workloads for
+// benchmarks, CI performance gates and bug reproduction.
+package genrepo
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"math"
+	"math/rand"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ManifestFile records how a repository was generated
+const ManifestFile = ".genrepo.json"
+
+// filesPerDir is the target number of files in each generated directory
+const filesPerDir = 20
+
+// extensions is the mix of file types written to the tree
+var extensions = []string{".go", ".js", ".ts", ".py", ".md", ".json", ".txt", ".css"}
+
+// words fills generated files with plausible-looking source text
+var words = []string{
+	"func", "return", "const", "var", "if", "else", "for", "range", "import",
+	"user", "config", "handler", "request", "response", "error", "value",
+	"index", "count", "buffer", "snapshot", "watcher", "session", "cache",
+}
+
+// Options controls repository generation
+type Options struct {
+	Files        int     // Total number of files to create
+	Depth        int     // Maximum directory depth
+	IgnoredRatio float64 // Fraction of files placed in ignored directories
+	Seed         int64   // Random seed; the same seed produces the same tree
+	InitGit      bool    // Run `git init` in the generated repository
+}
+
+// Manifest describes a generated repository
+type Manifest struct {
+	Files       int       `json:"files"`
+	Directories int       `json:"directories"`
+	Ignored     int       `json:"ignored"`
+	Depth       int       `json:"depth"`
+	Seed        int64     `json:"seed"`
+	Bytes       int64     `json:"bytes"`
+	GeneratedAt time.Time `json:"generated_at"`
+}
+
+// ChurnStats summarizes one round of changes
+type ChurnStats struct {
+	Modified int
+	Created  int
+	Deleted  int
+}
+
+// Generate writes a synthetic repository to root, which must be empty or
+// not exist yet
+func Generate(root string, opts Options) (*Manifest, error) {
+	if opts.Files <= 0 {
+		return nil, fmt.Errorf("number of files must be positive")
+	}
+	if opts.Depth <= 0 {
+		return nil, fmt.Errorf("depth must be positive")
+	}
+	if opts.IgnoredRatio < 0 || opts.IgnoredRatio >= 1 {
+		return nil, fmt.Errorf("ignored ratio must be between 0 and 1")
+	}
+
+	if entries, err := os.ReadDir(root); err == nil && len(entries) > 0 {
+		return nil, fmt.Errorf("directory %s is not empty", root)
+	}
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", root, err)
+	}
+
+	rng := rand.New(rand.NewSource(opts.Seed))
+	manifest := &Manifest{Depth: opts.Depth, Seed: opts.Seed, GeneratedAt: time.Now()}
+
+	ignored := int(float64(opts.Files) * opts.IgnoredRatio)
+	tracked := opts.Files - ignored
+
+	dirs := buildDirs("", tracked, opts.Depth)
+	if err := writeFiles(root, dirs, tracked, rng, manifest); err != nil {
+		return nil, err
+	}
+
+	if ignored > 0 {
+		// node_modules itself counts towards the depth
+		ignoredDirs := buildDirs("node_modules", ignored, min(opts.Depth-1, 3))
+		before := manifest.Files
+		if err := writeFiles(root, ignoredDirs, ignored, rng, manifest); err != nil {
+			return nil, err
+		}
+		manifest.Ignored = manifest.Files - before
+	}
+
+	gitignore := "node_modules/\ndist/\n*.log\n" + ManifestFile + "\n"
+	if err := os.WriteFile(filepath.Join(root, ".gitignore"), []byte(gitignore), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write .gitignore: %w", err)
+	}
+
+	if opts.InitGit {
+		if output, err := exec.Command("git", "init", "--quiet", root).CombinedOutput(); err != nil {
+			return nil, fmt.Errorf("failed to initialize git repository: %s", strings.TrimSpace(string(output)))
+		}
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(root, ManifestFile), data, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write manifest: %w", err)
+	}
+
+	return manifest, nil
+}
+
+// buildDirs lays out a balanced directory tree deep enough to reach depth
+// and wide enough to hold files at roughly filesPerDir per directory
+func buildDirs(base string, files, depth int) []string {
+	if depth <= 0 {
+		return []string{base}
+	}
+
+	leaves := math.Max(1, float64(files)/filesPerDir)
+	fanout := int(math.Max(2, math.Round(math.Pow(leaves, 1/float64(depth)))))
+
+	dirs := []string{base}
+	level := []string{base}
+	for d := 0; d < depth && len(dirs) < files; d++ {
+		var next []string
+		for _, parent := range level {
+			for i := 0; i < fanout && len(dirs) < files; i++ {
+				dir := filepath.Join(parent, fmt.Sprintf("dir%02d", i))
+				next = append(next, dir)
+				dirs = append(dirs, dir)
+			}
+		}
+		level = next
+	}
+
+	return dirs
+}
+
+// writeFiles spreads files round-robin over dirs
+func writeFiles(root string, dirs []string, files int, rng *rand.Rand, manifest *Manifest) error {
+	for _, dir := range dirs {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			return fmt.Errorf("failed to create directory: %w", err)
+		}
+	}
+	manifest.Directories += len(dirs)
+
+	for i := 0; i < files; i++ {
+		dir := dirs[i%len(dirs)]
+		name := "file" + strconv.Itoa(i) + extensions[rng.Intn(len(extensions))]
+
+		content := randomContent(rng)
+		if err := os.WriteFile(filepath.Join(root, dir, name), content, 0644); err != nil {
+			return fmt.Errorf("failed to write file: %w", err)
+		}
+		manifest.Files++
+		manifest.Bytes += int64(len(content))
+	}
+
+	return nil
+}
+
+// randomContent returns between 5 and 100 lines of pseudo source text
+func randomContent(rng *rand.Rand) []byte {
+	var b strings.Builder
+	lines := 5 + rng.Intn(96)
+	for i := 0; i < lines; i++ {
+		for j, n := 0, 3+rng.Intn(8); j < n; j++ {
+			if j > 0 {
+				b.WriteByte(' ')
+			}
+			b.WriteString(words[rng.Intn(len(words))])
+		}
+		b.WriteByte('\n')
+	}
+	return []byte(b.String())
+}
+
+// Churn changes percent (0-1) of the tracked files under root: mostly edits,
+// with some files created and deleted, like a real editing session
+func Churn(root string, percent float64, rng *rand.Rand) (ChurnStats, error) {
+	var stats ChurnStats
+
+	if percent <= 0 || percent > 1 {
+		return stats, fmt.Errorf("churn must be between 0%% and 100%%")
+	}
+
+	var files []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if name := d.Name(); name == ".git" || name == "node_modules" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if name := d.Name(); name != ManifestFile && name != ".gitignore" {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return stats, fmt.Errorf("failed to scan %s: %w", root, err)
+	}
+	if len(files) == 0 {
+		return stats, fmt.Errorf("no files to churn in %s", root)
+	}
+
+	count := int(math.Ceil(float64(len(files)) * percent))
+	rng.Shuffle(len(files), func(i, j int) { files[i], files[j] = files[j], files[i] })
+
+	for _, path := range files[:count] {
+		switch roll := rng.Float64(); {
+		case roll < 0.1:
+			if err := os.Remove(path); err != nil {
+				return stats, fmt.Errorf("failed to delete file: %w", err)
+			}
+			stats.Deleted++
+		case roll < 0.3:
+			name := fmt.Sprintf("new%d%s", rng.Int63(), extensions[rng.Intn(len(extensions))])
+			if err := os.WriteFile(filepath.Join(filepath.Dir(path), name), randomContent(rng), 0644); err != nil {
+				return stats, fmt.Errorf("failed to create file: %w", err)
+			}
+			stats.Created++
+		default:
+			f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+			if err != nil {
+				return stats, fmt.Errorf("failed to modify file: %w", err)
+			}
+			_, err = f.Write(randomContent(rng))
+			f.Close()
+			if err != nil {
+				return stats, fmt.Errorf("failed to modify file: %w", err)
+			}
+			stats.Modified++
+		}
+	}
+
+	return stats, nil
+}
+
+// ParseCount parses counts such as "500", "20k" or "1.5m"
+func ParseCount(s string) (int, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	multiplier := 1.0
+	switch {
+	case strings.HasSuffix(s, "k"):
+		multiplier, s = 1e3, strings.TrimSuffix(s, "k")
+	case strings.HasSuffix(s, "m"):
+		multiplier, s = 1e6, strings.TrimSuffix(s, "m")
+	}
+
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid count %q (examples: 500, 20k, 1.5m)", s)
+	}
+
+	return int(n * multiplier), nil
+}
+
+// ParsePercent parses "5%" or "0.05" into a fraction between 0 and 1
+func ParsePercent(s string) (float64, error) {
+	s = strings.TrimSpace(s)
+	value := strings.TrimSuffix(s, "%")
+
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid percentage %q (examples: 5%%, 0.05)", s)
+	}
+	if strings.HasSuffix(s, "%") {
+		n /= 100
+	}
+	if n < 0 || n > 1 {
+		return 0, fmt.Errorf("percentage %q out of range", s)
+	}
+
+	return n, nil
+}
@@ -0,0 +1,135 @@
+package genrepo
+
+import (
+	"io/fs"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func countFiles(t *testing.T, root string) (files, maxDepth int) {
+	t.Helper()
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && d.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		rel, _ := filepath.Rel(root, path)
+		if depth := strings.Count(rel, string(filepath.Separator)); depth > maxDepth {
+			maxDepth = depth
+		}
+		if !d.IsDir() && d.Name() != ManifestFile && d.Name() != ".gitignore" {
+			files++
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to walk %s: %v", root, err)
+	}
+	return files, maxDepth
+}
+
+func TestGenerate(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "timemachine-genrepo")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	root := filepath.Join(tempDir, "repo")
+	manifest, err := Generate(root, Options{Files: 500, Depth: 3, IgnoredRatio: 0.1, Seed: 42})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	if manifest.Files != 500 {
+		t.Errorf("Expected 500 files in manifest, got %d", manifest.Files)
+	}
+	if manifest.Ignored != 50 {
+		t.Errorf("Expected 50 ignored files, got %d", manifest.Ignored)
+	}
+
+	files, maxDepth := countFiles(t, root)
+	if files != 500 {
+		t.Errorf("Expected 500 files on disk, got %d", files)
+	}
+	// Files live one level below the deepest directory
+	if maxDepth != 3 {
+		t.Errorf("Expected max depth 3, got %d", maxDepth)
+	}
+
+	if _, err := Generate(root, Options{Files: 10, Depth: 1}); err == nil {
+		t.Error("Expected error when generating into a non-empty directory")
+	}
+}
+
+func TestGenerateDeterministic(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "timemachine-genrepo")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	a, errA := Generate(filepath.Join(tempDir, "a"), Options{Files: 100, Depth: 2, Seed: 7})
+	b, errB := Generate(filepath.Join(tempDir, "b"), Options{Files: 100, Depth: 2, Seed: 7})
+	if errA != nil || errB != nil {
+		t.Fatalf("Generate failed: %v, %v", errA, errB)
+	}
+	if a.Bytes != b.Bytes {
+		t.Errorf("Expected identical output for the same seed, got %d and %d bytes", a.Bytes, b.Bytes)
+	}
+}
+
+func TestChurn(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "timemachine-genrepo")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	if _, err := Generate(tempDir, Options{Files: 200, Depth: 2, Seed: 1}); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	stats, err := Churn(tempDir, 0.1, rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatalf("Churn failed: %v", err)
+	}
+	if total := stats.Modified + stats.Created + stats.Deleted; total != 20 {
+		t.Errorf("Expected 20 changes for 10%% churn of 200 files, got %d", total)
+	}
+
+	if _, err := Churn(tempDir, 0, rand.New(rand.NewSource(1))); err == nil {
+		t.Error("Expected error for zero churn")
+	}
+}
+
+func TestParseCountAndPercent(t *testing.T) {
+	counts := map[string]int{"500": 500, "20k": 20000, "1.5m": 1500000, "200K": 200000}
+	for input, want := range counts {
+		if got, err := ParseCount(input); err != nil || got != want {
+			t.Errorf("ParseCount(%q) = %d, %v; want %d", input, got, err, want)
+		}
+	}
+	for _, input := range []string{"", "abc", "-5", "0"} {
+		if _, err := ParseCount(input); err == nil {
+			t.Errorf("ParseCount(%q) expected error", input)
+		}
+	}
+
+	percents := map[string]float64{"5%": 0.05, "0.05": 0.05, "100%": 1}
+	for input, want := range percents {
+		if got, err := ParsePercent(input); err != nil || got != want {
+			t.Errorf("ParsePercent(%q) = %f, %v; want %f", input, got, err, want)
+		}
+	}
+	for _, input := range []string{"abc", "150%", "-1%"} {
+		if _, err := ParsePercent(input); err == nil {
+			t.Errorf("ParsePercent(%q) expected error", input)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+// Package mcp serves Time Machine to AI coding agents over the Model Context
+// Protocol: JSON-RPC 2.0 messages, one per line, on stdin and stdout.
+package mcp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/core"
+)
+
+// ProtocolVersion is the newest MCP revision the server speaks
+const ProtocolVersion = "2025-06-18"
+
+// supportedVersions are the revisions a client may ask for; the server
+// answers with ProtocolVersion to anything else
+var supportedVersions = map[string]bool{
+	"2024-11-05": true,
+	"2025-03-26": true,
+	"2025-06-18": true,
+}
+
+// JSON-RPC error codes
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+)
+
+// maxMessageSize bounds a single request line
+const maxMessageSize = 16 * 1024 * 1024
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"` // Absent for notifications
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// Server answers MCP requests about one project's snapshots
+type Server struct {
+	git     *core.GitManager
+	version string
+}
+
+// NewServer creates a server for the project gitManager manages; version is
+// reported to clients as the server's
+func NewServer(gitManager *core.GitManager, version string) *Server {
+	return &Server{git: gitManager, version: version}
+}
+
+// Serve handles requests from in, one at a time, until in is closed. out
+// must carry nothing but responses.
+func (s *Server) Serve(in io.Reader, out io.Writer) error {
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64*1024), maxMessageSize)
+	encoder := json.NewEncoder(out)
+
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		if resp := s.handle(line); resp != nil {
+			if err := encoder.Encode(resp); err != nil {
+				return fmt.Errorf("failed to write response: %w", err)
+			}
+		}
+	}
+	return scanner.Err()
+}
+
+// handle answers one message; notifications get no response
+func (s *Server) handle(line []byte) *response {
+	var req request
+	if err := json.Unmarshal(line, &req); err != nil {
+		return errorResponse(json.RawMessage("null"), codeParseError, "parse error: "+err.Error())
+	}
+	if req.ID == nil {
+		return nil // Notifications (initialized, cancelled) need no answer
+	}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		return errorResponse(req.ID, codeInvalidRequest, "invalid request")
+	}
+
+	var result interface{}
+	var err *rpcError
+	switch req.Method {
+	case "initialize":
+		result, err = s.initialize(req.Params)
+	case "ping":
+		result = struct{}{}
+	case "tools/list":
+		result = map[string]interface{}{"tools": tools}
+	case "tools/call":
+		result, err = s.callTool(req.Params)
+	default:
+		err = &rpcError{Code: codeMethodNotFound, Message: "method not found: " + req.Method}
+	}
+	if err != nil {
+		return errorResponse(req.ID, err.Code, err.Message)
+	}
+	return &response{JSONRPC: "2.0", ID: req.ID, Result: result}
+}
+
+func errorResponse(id json.RawMessage, code int, message string) *response {
+	return &response{JSONRPC: "2.0", ID: id, Error: &rpcError{Code: code, Message: message}}
+}
+
+// initialize agrees on a protocol revision and announces the tools
+func (s *Server) initialize(params json.RawMessage) (interface{}, *rpcError) {
+	var p struct {
+		ProtocolVersion string `json:"protocolVersion"`
+		ClientInfo      struct {
+			Name string `json:"name"`
+		} `json:"clientInfo"`
+	}
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, &rpcError{Code: codeInvalidParams, Message: err.Error()}
+		}
+	}
+	if p.ClientInfo.Name != "" {
+		// Snapshots taken through the tools record the client with
+		// git.provenance
+		s.git.Tool = "mcp:" + p.ClientInfo.Name
+	}
+	version := ProtocolVersion
+	if supportedVersions[p.ProtocolVersion] {
+		version = p.ProtocolVersion
+	}
+
+	return map[string]interface{}{
+		"protocolVersion": version,
+		"capabilities":    map[string]interface{}{"tools": map[string]interface{}{}},
+		"serverInfo":      map[string]string{"name": "timemachine", "version": s.version},
+		"instructions": "Time Machine snapshots this project's working tree in a shadow Git repository " +
+			"without touching the project's own Git history. Take a snapshot before risky edits, " +
+			"then list, diff and restore files from snapshots to undo them.",
+	}, nil
+}
+
+// callTool runs a tool. Tool failures are results with isError set, so the
+// agent sees them; only unknown tools and malformed calls are protocol errors.
+func (s *Server) callTool(params json.RawMessage) (interface{}, *rpcError) {
+	var p struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, &rpcError{Code: codeInvalidParams, Message: err.Error()}
+	}
+	t := findTool(p.Name)
+	if t == nil {
+		return nil, &rpcError{Code: codeInvalidParams, Message: "unknown tool: " + p.Name}
+	}
+	if len(p.Arguments) == 0 || string(p.Arguments) == "null" {
+		p.Arguments = json.RawMessage("{}")
+	}
+
+	text, err := t.call(s, p.Arguments)
+	if err != nil {
+		text = err.Error()
+	}
+	return map[string]interface{}{
+		"content": []map[string]string{{"type": "text", "text": text}},
+		"isError": err != nil,
+	}, nil
+}
@@ -0,0 +1,143 @@
+package mcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/core"
+)
+
+func setupTestServer(t *testing.T) (string, *Server) {
+	tempDir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", tempDir},
+		{"-C", tempDir, "config", "user.name", "Test User"},
+		{"-C", tempDir, "config", "user.email", "test@example.com"},
+	} {
+		if err := exec.Command("git", args...).Run(); err != nil {
+			t.Fatalf("git %v failed: %v", args, err)
+		}
+	}
+
+	gitDir := filepath.Join(tempDir, ".git")
+	gitManager := core.NewGitManager(&core.AppState{
+		ProjectRoot:   tempDir,
+		GitDir:        gitDir,
+		ShadowRepoDir: filepath.Join(gitDir, "timemachine_snapshots"),
+	})
+	if err := gitManager.InitializeShadowRepo(); err != nil {
+		t.Fatalf("Failed to initialize shadow repo: %v", err)
+	}
+	return tempDir, NewServer(gitManager, "test")
+}
+
+// exchange sends requests through Serve and decodes the responses
+func exchange(t *testing.T, server *Server, requests ...string) []map[string]interface{} {
+	var out bytes.Buffer
+	if err := server.Serve(strings.NewReader(strings.Join(requests, "\n")+"\n"), &out); err != nil {
+		t.Fatalf("Serve failed: %v", err)
+	}
+	var responses []map[string]interface{}
+	decoder := json.NewDecoder(&out)
+	for decoder.More() {
+		var resp map[string]interface{}
+		if err := decoder.Decode(&resp); err != nil {
+			t.Fatalf("Invalid response: %v", err)
+		}
+		responses = append(responses, resp)
+	}
+	return responses
+}
+
+// callText returns the text and isError of a tools/call response
+func callText(t *testing.T, resp map[string]interface{}) (string, bool) {
+	result, ok := resp["result"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected a tool result, got %v", resp)
+	}
+	content := result["content"].([]interface{})[0].(map[string]interface{})
+	return content["text"].(string), result["isError"].(bool)
+}
+
+func toolCall(id int, name string, args interface{}) string {
+	data, _ := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0", "id": id, "method": "tools/call",
+		"params": map[string]interface{}{"name": name, "arguments": args},
+	})
+	return string(data)
+}
+
+func TestServerProtocol(t *testing.T) {
+	_, server := setupTestServer(t)
+
+	responses := exchange(t, server,
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05","capabilities":{}}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"resources/list"}`,
+		`not json`,
+	)
+	if len(responses) != 4 {
+		t.Fatalf("Expected 4 responses (none for the notification), got %d: %v", len(responses), responses)
+	}
+
+	result := responses[0]["result"].(map[string]interface{})
+	if result["protocolVersion"] != "2024-11-05" {
+		t.Errorf("Expected the client's protocol version to be accepted, got %v", result["protocolVersion"])
+	}
+	listed := responses[1]["result"].(map[string]interface{})["tools"].([]interface{})
+	if len(listed) != len(tools) {
+		t.Errorf("Expected %d tools, got %d", len(tools), len(listed))
+	}
+	for i, code := range map[int]float64{2: codeMethodNotFound, 3: codeParseError} {
+		if rpcErr, ok := responses[i]["error"].(map[string]interface{}); !ok || rpcErr["code"] != code {
+			t.Errorf("Expected error %v for response %d, got %v", code, i, responses[i])
+		}
+	}
+}
+
+func TestServerSnapshotAndRestore(t *testing.T) {
+	tempDir, server := setupTestServer(t)
+	file := filepath.Join(tempDir, "main.go")
+	if err := os.WriteFile(file, []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	responses := exchange(t, server, toolCall(1, "create_snapshot", map[string]string{"note": "before the agent's edit"}))
+	text, isError := callText(t, responses[0])
+	if isError || !strings.HasPrefix(text, "Created snapshot ") {
+		t.Fatalf("Expected a snapshot, got %q", text)
+	}
+	hash := strings.Fields(strings.TrimPrefix(text, "Created snapshot "))[0]
+
+	if err := os.WriteFile(file, []byte("package broken\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	responses = exchange(t, server,
+		toolCall(1, "list_snapshots", map[string]interface{}{}),
+		toolCall(2, "diff_snapshot", map[string]string{"hash": hash}),
+		toolCall(3, "restore_files", map[string]interface{}{"hash": hash, "paths": []string{"main.go"}}),
+		toolCall(4, "restore_files", map[string]interface{}{"hash": "nope", "paths": []string{"main.go"}}),
+	)
+
+	if text, _ := callText(t, responses[0]); !strings.Contains(text, hash) || !strings.Contains(text, "before the agent's edit") {
+		t.Errorf("Expected the snapshot and its note to be listed, got %s", text)
+	}
+	if text, _ := callText(t, responses[1]); !strings.Contains(text, "+package broken") {
+		t.Errorf("Expected a diff against the working tree, got %s", text)
+	}
+	if text, isError := callText(t, responses[2]); isError || !strings.Contains(text, "Restored 1 file(s)") {
+		t.Errorf("Expected main.go to be restored, got %q", text)
+	}
+	if content, _ := os.ReadFile(file); string(content) != "package main\n" {
+		t.Errorf("Expected main.go to be restored, got %q", content)
+	}
+	if text, isError := callText(t, responses[3]); !isError || !strings.Contains(text, "does not exist") {
+		t.Errorf("Expected an unknown snapshot to be a tool error, got %q", text)
+	}
+}
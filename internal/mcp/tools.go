@@ -0,0 +1,309 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/core"
+)
+
+// maxDiffBytes bounds diffs returned to the agent, whose context is limited
+const maxDiffBytes = 256 * 1024
+
+// tool is an MCP tool: its description and JSON Schema for the client, and
+// the function running it
+type tool struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	InputSchema map[string]interface{} `json:"inputSchema"`
+	call        func(s *Server, args json.RawMessage) (string, error)
+}
+
+// tools are listed in this order by tools/list
+var tools = []*tool{
+	{
+		Name: "create_snapshot",
+		Description: "Snapshot the project's working tree now, e.g. before a risky edit. " +
+			"Returns the snapshot's hash, or the latest snapshot's when nothing changed.",
+		InputSchema: schema(map[string]interface{}{
+			"message": stringProperty("What the snapshot captures; a summary of the changed files by default"),
+			"note":    stringProperty("Note to attach to the snapshot, shown by 'timemachine list'"),
+		}),
+		call: (*Server).createSnapshot,
+	},
+	{
+		Name:        "list_snapshots",
+		Description: "List snapshots, newest first, as JSON with hash, message, time and note.",
+		InputSchema: schema(map[string]interface{}{
+			"limit": map[string]interface{}{"type": "integer", "description": "Most snapshots to return (default 20, 0 for all)", "minimum": 0},
+			"file":  stringProperty("Only snapshots that changed this path, relative to the project root"),
+			"since": stringProperty("Only snapshots taken since this age (2h, 7d) or date (2024-03-09)"),
+			"grep":  stringProperty("Only snapshots whose message matches this case-insensitive regular expression"),
+		}),
+		call: (*Server).listSnapshots,
+	},
+	{
+		Name: "diff_snapshot",
+		Description: "Show a unified diff from a snapshot to another snapshot or, by default, " +
+			"to the current working tree.",
+		InputSchema: schema(map[string]interface{}{
+			"hash":  stringProperty("Snapshot to diff from: a hash or a revision such as HEAD~2"),
+			"to":    stringProperty("Snapshot to diff to; the working tree when omitted"),
+			"paths": pathsProperty("Limit the diff to these paths, relative to the project root"),
+			"stat":  map[string]interface{}{"type": "boolean", "description": "Only a diffstat"},
+		}, "hash"),
+		call: (*Server).diffSnapshot,
+	},
+	{
+		Name: "restore_files",
+		Description: "Restore files in the working tree to their content in a snapshot. A pre-restore " +
+			"snapshot is taken first, so a restore can itself be undone. Refuses to overwrite uncommitted " +
+			"Git changes unless force is set.",
+		InputSchema: schema(map[string]interface{}{
+			"hash":  stringProperty("Snapshot to restore from: a hash or a revision such as HEAD~2"),
+			"paths": pathsProperty("Files, directories or glob patterns to restore, relative to the project root; \".\" restores everything"),
+			"force": map[string]interface{}{"type": "boolean", "description": "Overwrite files with uncommitted Git changes"},
+		}, "hash", "paths"),
+		call: (*Server).restoreFiles,
+	},
+}
+
+// findTool returns the tool with the given name, or nil
+func findTool(name string) *tool {
+	for _, t := range tools {
+		if t.Name == name {
+			return t
+		}
+	}
+	return nil
+}
+
+func schema(properties map[string]interface{}, required ...string) map[string]interface{} {
+	s := map[string]interface{}{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		s["required"] = required
+	}
+	return s
+}
+
+func stringProperty(description string) map[string]interface{} {
+	return map[string]interface{}{"type": "string", "description": description}
+}
+
+func pathsProperty(description string) map[string]interface{} {
+	return map[string]interface{}{"type": "array", "items": map[string]string{"type": "string"}, "description": description}
+}
+
+// decodeArgs unmarshals tool arguments
+func decodeArgs(args json.RawMessage, v interface{}) error {
+	if err := json.Unmarshal(args, v); err != nil {
+		return fmt.Errorf("invalid arguments: %w", err)
+	}
+	return nil
+}
+
+// projectPaths resolves paths given relative to the project root, whatever
+// directory the server was started in
+func (s *Server) projectPaths(paths []string) []string {
+	resolved := make([]string, len(paths))
+	for i, path := range paths {
+		if filepath.IsAbs(path) {
+			resolved[i] = path
+		} else {
+			resolved[i] = filepath.Join(s.git.State.ProjectRoot, filepath.FromSlash(path))
+		}
+	}
+	return resolved
+}
+
+func (s *Server) createSnapshot(args json.RawMessage) (string, error) {
+	var a struct {
+		Message string `json:"message"`
+		Note    string `json:"note"`
+	}
+	if err := decodeArgs(args, &a); err != nil {
+		return "", err
+	}
+
+	before, _ := s.git.RunCommand("rev-parse", "--verify", "--quiet", "HEAD")
+	if err := s.git.CreateSnapshot(a.Message); err != nil {
+		return "", err
+	}
+	head, err := s.git.RunCommand("rev-parse", "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("no snapshot was created: the project has no files to snapshot")
+	}
+
+	text := "Created snapshot " + head
+	if head == before {
+		text = "Nothing changed since the latest snapshot " + head
+	}
+	if a.Note != "" {
+		if _, err := s.git.AddNote(head, a.Note, head == before); err != nil {
+			return "", err
+		}
+		text += " (note attached)"
+	}
+	return text, nil
+}
+
+func (s *Server) listSnapshots(args json.RawMessage) (string, error) {
+	a := struct {
+		Limit *int   `json:"limit"`
+		File  string `json:"file"`
+		Since string `json:"since"`
+		Grep  string `json:"grep"`
+	}{}
+	if err := decodeArgs(args, &a); err != nil {
+		return "", err
+	}
+
+	filter := core.SnapshotFilter{Limit: 20, Grep: a.Grep}
+	if a.Limit != nil {
+		filter.Limit = *a.Limit
+	}
+	if a.File != "" {
+		filter.File = s.projectPaths([]string{a.File})[0]
+	}
+	if a.Since != "" {
+		since, err := core.ParseTimeBound(a.Since, false)
+		if err != nil {
+			return "", fmt.Errorf("invalid since: %w", err)
+		}
+		filter.Since = since
+	}
+	snapshots, err := s.git.FilterSnapshots(filter)
+	if err != nil {
+		return "", err
+	}
+	notes, _ := s.git.Notes()
+
+	type entry struct {
+		Hash    string    `json:"hash"`
+		Message string    `json:"message"`
+		Time    time.Time `json:"time"`
+		Age     string    `json:"age"`
+		Note    string    `json:"note,omitempty"`
+	}
+	entries := make([]entry, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		entries = append(entries, entry{
+			Hash:    snapshot.Hash,
+			Message: snapshot.Message,
+			Time:    snapshot.Timestamp.UTC(),
+			Age:     snapshot.Time,
+			Note:    notes[snapshot.Hash],
+		})
+	}
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func (s *Server) diffSnapshot(args json.RawMessage) (string, error) {
+	var a struct {
+		Hash  string   `json:"hash"`
+		To    string   `json:"to"`
+		Paths []string `json:"paths"`
+		Stat  bool     `json:"stat"`
+	}
+	if err := decodeArgs(args, &a); err != nil {
+		return "", err
+	}
+	from, err := s.git.ResolveSnapshot(a.Hash)
+	if err != nil {
+		return "", err
+	}
+	to := ""
+	if a.To != "" {
+		if to, err = s.git.ResolveSnapshot(a.To); err != nil {
+			return "", err
+		}
+	}
+	var pathspecs []string
+	if len(a.Paths) > 0 {
+		matches, err := s.git.MatchSnapshotPaths(from, s.projectPaths(a.Paths))
+		if err != nil {
+			return "", err
+		}
+		pathspecs = core.MatchedPathspecs(matches)
+	}
+
+	diff, err := s.git.DiffSnapshots(from, to, core.DiffOptions{Stat: a.Stat, Pathspecs: pathspecs})
+	if err != nil {
+		return "", err
+	}
+	if diff == "" {
+		return "No differences", nil
+	}
+	if len(diff) > maxDiffBytes {
+		diff = diff[:maxDiffBytes] + fmt.Sprintf("\n… diff truncated at %d bytes; narrow it with paths or use stat", maxDiffBytes)
+	}
+	return diff, nil
+}
+
+func (s *Server) restoreFiles(args json.RawMessage) (string, error) {
+	var a struct {
+		Hash  string   `json:"hash"`
+		Paths []string `json:"paths"`
+		Force bool     `json:"force"`
+	}
+	if err := decodeArgs(args, &a); err != nil {
+		return "", err
+	}
+	if len(a.Paths) == 0 {
+		return "", fmt.Errorf("paths is required; use [\".\"] to restore everything")
+	}
+	commit, err := s.git.ResolveSnapshot(a.Hash)
+	if err != nil {
+		return "", err
+	}
+	matches, err := s.git.MatchSnapshotPaths(commit, s.projectPaths(a.Paths))
+	if err != nil {
+		return "", err
+	}
+	pathspecs := core.MatchedPathspecs(matches)
+
+	conflicts, err := s.git.RestoreConflicts(commit, pathspecs)
+	if err != nil {
+		return "", err
+	}
+	if len(conflicts) > 0 && !a.Force {
+		var b strings.Builder
+		b.WriteString("Restore would overwrite uncommitted Git changes:\n")
+		for _, conflict := range conflicts {
+			fmt.Fprintf(&b, "  %s (%s)\n", conflict.Path, conflict.Describe())
+		}
+		b.WriteString("Commit them first, or set force to overwrite them (the pre-restore snapshot keeps their working copies).")
+		return "", fmt.Errorf("%s", b.String())
+	}
+
+	journal, err := s.git.JournaledRestore(context.Background(), commit, pathspecs, core.DefaultRestoreBatchSize, nil)
+	if err != nil {
+		return "", err
+	}
+	if len(journal.Files) == 0 {
+		return "The working tree already matches snapshot " + core.ShortHash(commit), nil
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Restored %d file(s) from snapshot %s:\n", len(journal.Files), core.ShortHash(commit))
+	for _, file := range journal.Files {
+		fmt.Fprintf(&b, "  %s\n", file)
+	}
+	fmt.Fprintf(&b, "Pre-restore snapshot: %s (restore it to undo this restore)", journal.Backup)
+	if journal.BackupPatch != "" {
+		fmt.Fprintf(&b, "\nReverse patch: %s (timemachine restore --apply-backup %s)", journal.BackupPatch, journal.BackupPatch)
+	}
+	hc := core.HookContext{Hash: journal.Source, BackupHash: journal.Backup, ChangedFiles: journal.Files}
+	if err := s.git.RunHooks(core.HookPostRestore, hc); err != nil {
+		fmt.Fprintf(&b, "\nWarning: %v", err)
+	}
+	return b.String(), nil
+}
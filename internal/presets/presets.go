@@ -0,0 +1,112 @@
+// Package presets holds the curated per-ecosystem profiles that 'timemachine
+// init --profile' seeds a project's timemachine.yaml and .timemachine-ignore
+// with. The profiles are embedded into the binary.
+package presets
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+//go:embed profiles
+var profiles embed.FS
+
+// Preset is one ecosystem's profile
+type Preset struct {
+	Name        string   // e.g. "node", as given to --profile
+	Description string   // One line, from the first comment of Config
+	Config      []byte   // timemachine.yaml with the profile's watcher settings
+	Ignore      []string // Patterns added to .timemachine-ignore
+}
+
+// Names returns the names of all profiles, sorted
+func Names() []string {
+	entries, err := fs.ReadDir(profiles, "profiles")
+	if err != nil {
+		return nil // Embedded at build time, so never happens
+	}
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Get returns the named profile
+func Get(name string) (*Preset, error) {
+	dir := "profiles/" + name
+	if name == "" || strings.ContainsAny(name, `/\.`) {
+		return nil, unknownError(name)
+	}
+	config, err := profiles.ReadFile(dir + "/timemachine.yaml")
+	if err != nil {
+		return nil, unknownError(name)
+	}
+	ignore, err := profiles.ReadFile(dir + "/timemachine-ignore")
+	if err != nil {
+		return nil, fmt.Errorf("profile %s has no ignore patterns: %w", name, err)
+	}
+
+	preset := &Preset{Name: name, Config: config}
+	firstLine, _, _ := strings.Cut(string(config), "\n")
+	preset.Description = strings.TrimSpace(strings.TrimPrefix(firstLine, "#"))
+	for _, line := range strings.Split(string(ignore), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			preset.Ignore = append(preset.Ignore, line)
+		}
+	}
+	return preset, nil
+}
+
+// All returns every profile, sorted by name
+func All() ([]*Preset, error) {
+	var presets []*Preset
+	for _, name := range Names() {
+		preset, err := Get(name)
+		if err != nil {
+			return nil, err
+		}
+		presets = append(presets, preset)
+	}
+	return presets, nil
+}
+
+// Settings returns the profile's settings as dotted configuration keys
+// (e.g. "watcher.batch_size") mapped to their values
+func (p *Preset) Settings() (map[string]interface{}, error) {
+	v := viper.New()
+	v.SetConfigType("yaml")
+	if err := v.ReadConfig(bytes.NewReader(p.Config)); err != nil {
+		return nil, fmt.Errorf("profile %s has invalid settings: %w", p.Name, err)
+	}
+	settings := make(map[string]interface{})
+	for _, key := range v.AllKeys() {
+		settings[key] = v.Get(key)
+	}
+	return settings, nil
+}
+
+// IgnoreSection returns the block init appends to .timemachine-ignore,
+// headed by IgnoreMarker
+func (p *Preset) IgnoreSection() string {
+	return "\n" + p.IgnoreMarker() + "\n" + strings.Join(p.Ignore, "\n") + "\n"
+}
+
+// IgnoreMarker is the comment heading the profile's patterns in
+// .timemachine-ignore
+func (p *Preset) IgnoreMarker() string {
+	return "# Profile: " + p.Name
+}
+
+func unknownError(name string) error {
+	return fmt.Errorf("unknown profile %q (available: %s)", name, strings.Join(Names(), ", "))
+}
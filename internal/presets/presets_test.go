@@ -0,0 +1,62 @@
+package presets
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/config"
+)
+
+func TestNames(t *testing.T) {
+	want := []string{"go", "node", "python", "rust"}
+	if got := Names(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected profiles %v, got %v", want, got)
+	}
+}
+
+func TestProfilesAreValid(t *testing.T) {
+	presets, err := All()
+	if err != nil {
+		t.Fatalf("All failed: %v", err)
+	}
+	for _, preset := range presets {
+		if preset.Description == "" || len(preset.Ignore) == 0 {
+			t.Errorf("Profile %s needs a description and ignore patterns: %+v", preset.Name, preset)
+		}
+		for _, pattern := range preset.Ignore {
+			if strings.HasPrefix(pattern, "#") || strings.Contains(pattern, "..") {
+				t.Errorf("Profile %s has an unusable ignore pattern %q", preset.Name, pattern)
+			}
+		}
+
+		// The seeded timemachine.yaml must pass validation as is
+		path := filepath.Join(t.TempDir(), "timemachine.yaml")
+		if err := os.WriteFile(path, preset.Config, 0600); err != nil {
+			t.Fatal(err)
+		}
+		if err := config.ValidateFile(path); err != nil {
+			t.Errorf("Profile %s has invalid settings: %v", preset.Name, err)
+		}
+
+		settings, err := preset.Settings()
+		if err != nil {
+			t.Fatalf("Settings failed: %v", err)
+		}
+		for key := range settings {
+			if !strings.HasPrefix(key, "watcher.") {
+				t.Errorf("Profile %s sets %s; profiles only seed watcher settings", preset.Name, key)
+			}
+		}
+	}
+}
+
+func TestGetUnknown(t *testing.T) {
+	for _, name := range []string{"", "java", "../node", "node/"} {
+		if _, err := Get(name); err == nil || !strings.Contains(err.Error(), "unknown profile") {
+			t.Errorf("Expected %q to be an unknown profile, got %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package render
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+	"github.com/fatih/color"
+)
+
+// Syntax themes besides chroma's styles
+const (
+	SyntaxThemeDefault = "monokai"
+	SyntaxThemeNone    = "none" // Disables syntax highlighting
+)
+
+// syntaxTheme is the active ui.syntax_theme, set once at startup by
+// ConfigureSyntax
+var syntaxTheme = SyntaxThemeDefault
+
+// ConfigureSyntax activates a syntax theme. An empty theme is the default.
+func ConfigureSyntax(theme string) error {
+	if err := ValidateSyntaxTheme(theme); err != nil {
+		return err
+	}
+	if theme == "" {
+		theme = SyntaxThemeDefault
+	}
+	syntaxTheme = theme
+	return nil
+}
+
+// ValidateSyntaxTheme checks that a theme is one of chroma's styles, none or
+// empty
+func ValidateSyntaxTheme(theme string) error {
+	if theme == "" || theme == SyntaxThemeNone {
+		return nil
+	}
+	if _, ok := styles.Registry[theme]; !ok {
+		return fmt.Errorf("unknown syntax theme %q, must be none or one of: %s", theme, strings.Join(styles.Names(), ", "))
+	}
+	return nil
+}
+
+// Highlighter colors the source of one file by its language. A nil
+// Highlighter leaves text as it is, so callers needn't check whether
+// highlighting is on.
+type Highlighter struct {
+	lexer  chroma.Lexer
+	style  *chroma.Style
+	plain  chroma.Colour // The theme's default text color, left to the terminal
+	escape map[chroma.TokenType]string
+}
+
+// NewHighlighter returns a highlighter for the language of filename, or nil
+// when highlighting is off (color disabled, output not a terminal, or the
+// none theme) or the language is unknown
+func NewHighlighter(filename string) *Highlighter {
+	if color.NoColor || syntaxTheme == SyntaxThemeNone {
+		return nil
+	}
+	lexer := lexers.Match(filename)
+	if lexer == nil {
+		return nil
+	}
+	style := styles.Get(syntaxTheme)
+	return &Highlighter{
+		lexer:  chroma.Coalesce(lexer),
+		style:  style,
+		plain:  style.Get(chroma.Background).Colour,
+		escape: make(map[chroma.TokenType]string),
+	}
+}
+
+// Line highlights a single line. Constructs spanning lines, such as block
+// comments, are only recognized within the line.
+func (h *Highlighter) Line(line string) string {
+	if h == nil || line == "" {
+		return line
+	}
+	tokens, err := h.lexer.Tokenise(nil, line)
+	if err != nil {
+		return line
+	}
+	var b strings.Builder
+	for token := tokens(); token != chroma.EOF; token = tokens() {
+		value := strings.TrimSuffix(token.Value, "\n") // Lexers end input with a newline
+		if value == "" {
+			continue
+		}
+		if escape := h.escapeFor(token.Type); escape != "" {
+			b.WriteString(escape + value + "\x1b[0m")
+		} else {
+			b.WriteString(value)
+		}
+	}
+	return b.String()
+}
+
+// Text highlights whole text, line by line
+func (h *Highlighter) Text(text string) string {
+	if h == nil {
+		return text
+	}
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = h.Line(line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// escapeFor returns the escape sequence a token type starts with, without
+// backgrounds so the terminal's shows through
+func (h *Highlighter) escapeFor(ttype chroma.TokenType) string {
+	if escape, ok := h.escape[ttype]; ok {
+		return escape
+	}
+	entry := h.style.Get(ttype)
+	var codes []string
+	if entry.Bold == chroma.Yes {
+		codes = append(codes, "1")
+	}
+	if entry.Italic == chroma.Yes {
+		codes = append(codes, "3")
+	}
+	if entry.Underline == chroma.Yes {
+		codes = append(codes, "4")
+	}
+	if entry.Colour.IsSet() && entry.Colour != h.plain {
+		codes = append(codes, fmt.Sprintf("38;5;%d", xterm256(entry.Colour)))
+	}
+	escape := ""
+	if len(codes) > 0 {
+		escape = "\x1b[" + strings.Join(codes, ";") + "m"
+	}
+	h.escape[ttype] = escape
+	return escape
+}
+
+// xterm256 maps a color to the nearest of the xterm 256-color palette's
+// color cube or gray ramp
+func xterm256(c chroma.Colour) int {
+	r, g, b := int(c.Red()), int(c.Green()), int(c.Blue())
+
+	// Color cube: 6 levels per channel, at 0, 95, 135, 175, 215 and 255
+	level := func(v int) int {
+		if v < 48 {
+			return 0
+		}
+		if v < 115 {
+			return 1
+		}
+		return (v - 35) / 40
+	}
+	value := func(l int) int {
+		if l == 0 {
+			return 0
+		}
+		return 55 + l*40
+	}
+	lr, lg, lb := level(r), level(g), level(b)
+	cube := 16 + 36*lr + 6*lg + lb
+	cubeDistance := distance(r, g, b, value(lr), value(lg), value(lb))
+
+	// Gray ramp: 24 levels from 8 to 238
+	average := (r + g + b) / 3
+	grayLevel := 23
+	if average < 238 {
+		grayLevel = (average - 3) / 10
+		if grayLevel < 0 {
+			grayLevel = 0
+		}
+	}
+	grayValue := 8 + grayLevel*10
+	if distance(r, g, b, grayValue, grayValue, grayValue) < cubeDistance {
+		return 232 + grayLevel
+	}
+	return cube
+}
+
+// distance is the squared distance between two colors
+func distance(r1, g1, b1, r2, g2, b2 int) int {
+	dr, dg, db := r1-r2, g1-g2, b1-b2
+	return dr*dr + dg*dg + db*db
+}
@@ -0,0 +1,95 @@
+package render
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/fatih/color"
+)
+
+func TestHighlighter(t *testing.T) {
+	noColor := color.NoColor
+	defer func() {
+		color.NoColor = noColor
+		ConfigureSyntax("")
+	}()
+	color.NoColor = false
+
+	highlighter := NewHighlighter("main.go")
+	if highlighter == nil {
+		t.Fatal("expected a highlighter for Go")
+	}
+	line := `func main() { println("hi") }`
+	highlighted := highlighter.Line(line)
+	if !strings.Contains(highlighted, "\x1b[") {
+		t.Errorf("Line(%q) has no color: %q", line, highlighted)
+	}
+	if strings.Contains(highlighted, "48;") {
+		t.Errorf("Line(%q) sets a background: %q", line, highlighted)
+	}
+	if stripped := stripEscapes(highlighted); stripped != line {
+		t.Errorf("Line changed the text: %q", stripped)
+	}
+	if got := highlighter.Text("a\nb"); strings.Count(got, "\n") != 1 {
+		t.Errorf("Text changed the line count: %q", got)
+	}
+
+	if NewHighlighter("notes.unknown-extension") != nil {
+		t.Error("expected no highlighter for an unknown language")
+	}
+	var none *Highlighter
+	if none.Line("x") != "x" || none.Text("x\ny") != "x\ny" {
+		t.Error("a nil highlighter must leave text as it is")
+	}
+
+	if err := ConfigureSyntax(SyntaxThemeNone); err != nil {
+		t.Fatal(err)
+	}
+	if NewHighlighter("main.go") != nil {
+		t.Error("expected the none theme to disable highlighting")
+	}
+	if err := ConfigureSyntax("github"); err != nil {
+		t.Errorf("ConfigureSyntax(github) failed: %v", err)
+	}
+	if err := ConfigureSyntax("no-such-theme"); err == nil {
+		t.Error("expected an unknown theme to be rejected")
+	}
+
+	color.NoColor = true
+	if NewHighlighter("main.go") != nil {
+		t.Error("expected disabled color to disable highlighting")
+	}
+}
+
+func TestXterm256(t *testing.T) {
+	tests := []struct {
+		rgb  string
+		want int
+	}{
+		{"#000000", 16},
+		{"#ffffff", 231},
+		{"#ff0000", 196},
+		{"#808080", 244},
+	}
+	for _, tt := range tests {
+		if got := xterm256(chroma.MustParseColour(tt.rgb)); got != tt.want {
+			t.Errorf("xterm256(%s) = %d, want %d", tt.rgb, got, tt.want)
+		}
+	}
+}
+
+// stripEscapes removes ANSI color sequences
+func stripEscapes(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\x1b' {
+			for i < len(s) && s[i] != 'm' {
+				i++
+			}
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
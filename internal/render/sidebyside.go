@@ -0,0 +1,260 @@
+// Package render draws git output for the terminal in ways git itself
+// doesn't, such as side-by-side diffs. Colors come from the ui theme.
+package render
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/ui"
+)
+
+// MinWidth is the narrowest a side-by-side diff is drawn; narrower widths
+// are widened so each column keeps some room for code
+const MinWidth = 40
+
+// tabWidth is how many columns a tab expands to
+const tabWidth = 4
+
+// separator divides the old and new columns
+const separator = " │ "
+
+// SideBySide renders unified diffs as two columns, the old side on the left
+// and the new side on the right, with line numbers. Lines too long for their
+// column wrap onto continuation rows.
+type SideBySide struct {
+	Width int // Total width in columns, separator included
+}
+
+// row is one line of a hunk side, or nothing when the other side is longer
+type row struct {
+	number int // 0 for an empty cell
+	text   string
+	role   ui.Role
+}
+
+// Render writes diff, the output of git diff or git show, side by side.
+// Anything before the first file header, such as a commit message, is
+// skipped.
+func (s SideBySide) Render(out io.Writer, diff string) {
+	width := s.Width
+	if width < MinWidth {
+		width = MinWidth
+	}
+	r := &renderer{out: out, width: width, column: (width - utf8.RuneCountInString(separator)) / 2}
+
+	inFile := false
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git"):
+			r.flush()
+			inFile, r.inHunk = true, false
+			r.highlighter = nil
+			if i := strings.LastIndex(line, " b/"); i >= 0 {
+				r.highlighter = NewHighlighter(line[i+3:])
+			}
+			fmt.Fprintln(r.out)
+			r.full(ui.RoleDiffHeader, line)
+		case !inFile:
+			// Commit header of git show
+		case strings.HasPrefix(line, "@@"):
+			r.flush()
+			r.startHunk(line)
+			r.full(ui.RoleDiffHunk, line)
+		case !r.inHunk:
+			// File metadata: index, modes, renames, ---/+++, binary notices
+			if !strings.HasPrefix(line, "index ") && !strings.HasPrefix(line, "--- ") && !strings.HasPrefix(line, "+++ ") && line != "" {
+				r.full(ui.RoleMuted, line)
+			}
+		case strings.HasPrefix(line, "-"):
+			r.left = append(r.left, row{r.oldLine, line[1:], ui.RoleDeleted})
+			r.oldLine++
+		case strings.HasPrefix(line, "+"):
+			r.right = append(r.right, row{r.newLine, line[1:], ui.RoleAdded})
+			r.newLine++
+		case strings.HasPrefix(line, `\`), line == "":
+			// "\ No newline at end of file" belongs to the line before, and
+			// context lines always start with a space
+		default:
+			r.flush()
+			text := strings.TrimPrefix(line, " ")
+			r.pair(row{r.oldLine, text, ""}, row{r.newLine, text, ""})
+			r.oldLine++
+			r.newLine++
+		}
+	}
+	r.flush()
+}
+
+// renderer holds the state of one Render call
+type renderer struct {
+	out    io.Writer
+	width  int
+	column int // Width of each side
+
+	highlighter *Highlighter // For the current file; nil leaves code plain
+
+	inHunk           bool
+	oldLine, newLine int
+	left, right      []row // Pending removed and added lines, paired on flush
+}
+
+// startHunk reads the line numbers a hunk starts at from its header
+func (r *renderer) startHunk(header string) {
+	r.inHunk = true
+	r.oldLine, r.newLine = 1, 1
+	for _, field := range strings.Fields(header) {
+		if field == "@@" || len(field) < 2 {
+			continue
+		}
+		start, _, _ := strings.Cut(field[1:], ",")
+		n, err := strconv.Atoi(start)
+		if err != nil {
+			continue
+		}
+		switch field[0] {
+		case '-':
+			r.oldLine = n
+		case '+':
+			r.newLine = n
+		}
+	}
+}
+
+// flush pairs the pending removed lines with the pending added ones, so a
+// changed line shows next to what replaced it
+func (r *renderer) flush() {
+	for i := 0; i < len(r.left) || i < len(r.right); i++ {
+		var left, right row
+		if i < len(r.left) {
+			left = r.left[i]
+		}
+		if i < len(r.right) {
+			right = r.right[i]
+		}
+		r.pair(left, right)
+	}
+	r.left, r.right = r.left[:0], r.right[:0]
+}
+
+// full writes a line across both columns, wrapped to the full width
+func (r *renderer) full(role ui.Role, line string) {
+	for _, chunk := range wrap(expandTabs(line), r.width) {
+		ui.Color(role).Fprintln(r.out, chunk)
+	}
+}
+
+// pair writes one old line next to one new line, wrapping either onto as
+// many rows as the longer one needs
+func (r *renderer) pair(left, right row) {
+	gutter := numberWidth(left.number, right.number)
+	text := r.column - gutter - 1
+	if text < 1 {
+		text = 1
+	}
+	leftChunks := cellChunks(left, text)
+	rightChunks := cellChunks(right, text)
+
+	for i := 0; i < len(leftChunks) || i < len(rightChunks); i++ {
+		r.cell(left, leftChunks, i, gutter, text, true)
+		fmt.Fprint(r.out, ui.Sprint(ui.RoleMuted, separator))
+		r.cell(right, rightChunks, i, gutter, text, false)
+		fmt.Fprintln(r.out)
+	}
+}
+
+// cell writes row i of one side: the line number on its first row, then
+// the text padded to the column (the right column isn't padded). With
+// syntax highlighting the line number shows whether the line was removed or
+// added, since the text is colored by its language.
+func (r *renderer) cell(line row, chunks []string, i, gutter, text int, pad bool) {
+	number := ""
+	if line.number > 0 && i == 0 {
+		number = strconv.Itoa(line.number)
+	}
+	numberRole := ui.RoleMuted
+	if r.highlighter != nil && line.role != "" {
+		numberRole = line.role
+	}
+	fmt.Fprint(r.out, ui.Sprint(numberRole, fmt.Sprintf("%*s ", gutter, number)))
+
+	chunk := ""
+	if i < len(chunks) {
+		chunk = chunks[i]
+	}
+	switch {
+	case r.highlighter != nil:
+		fmt.Fprint(r.out, r.highlighter.Line(chunk))
+	case line.role != "" && chunk != "":
+		fmt.Fprint(r.out, ui.Sprint(line.role, chunk))
+	default:
+		fmt.Fprint(r.out, chunk)
+	}
+	if pad {
+		fmt.Fprint(r.out, strings.Repeat(" ", text-utf8.RuneCountInString(chunk)))
+	}
+}
+
+// cellChunks wraps a cell's text; an empty cell is one empty row
+func cellChunks(line row, width int) []string {
+	if line.number == 0 {
+		return nil
+	}
+	return wrap(expandTabs(line.text), width)
+}
+
+// numberWidth is the width of the line-number gutter, wide enough for both
+// numbers and at least 4 columns so rows line up within a hunk
+func numberWidth(a, b int) int {
+	width := 4
+	for _, n := range []int{a, b} {
+		if digits := len(strconv.Itoa(n)); digits > width {
+			width = digits
+		}
+	}
+	return width
+}
+
+// wrap splits text into chunks of at most width runes. Empty text is one
+// empty chunk.
+func wrap(text string, width int) []string {
+	runes := []rune(text)
+	if len(runes) <= width {
+		return []string{text}
+	}
+	var chunks []string
+	for len(runes) > width {
+		chunks = append(chunks, string(runes[:width]))
+		runes = runes[width:]
+	}
+	if len(runes) > 0 {
+		chunks = append(chunks, string(runes))
+	}
+	return chunks
+}
+
+// expandTabs replaces tabs with spaces up to the next tab stop, and drops
+// carriage returns, so the columns stay aligned
+func expandTabs(text string) string {
+	if !strings.ContainsAny(text, "\t\r") {
+		return text
+	}
+	var b strings.Builder
+	column := 0
+	for _, c := range text {
+		switch c {
+		case '\t':
+			n := tabWidth - column%tabWidth
+			b.WriteString(strings.Repeat(" ", n))
+			column += n
+		case '\r':
+		default:
+			b.WriteRune(c)
+			column++
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,90 @@
+package render
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/fatih/color"
+)
+
+func TestSideBySide(t *testing.T) {
+	color.NoColor = true
+
+	diff := `commit 1234567
+Author: A <a@b.c>
+
+    message
+
+diff --git a/main.go b/main.go
+index 1111111..2222222 100644
+--- a/main.go
++++ b/main.go
+@@ -9,4 +9,4 @@ func main() {
+ 	a := 1
+-	b := 2
++	b := 3
++	c := 4
+ }
+diff --git a/new.txt b/new.txt
+new file mode 100644
+index 0000000..3333333
+--- /dev/null
++++ b/new.txt
+@@ -0,0 +1 @@
++` + strings.Repeat("x", 50) + `
+\ No newline at end of file
+`
+
+	var out bytes.Buffer
+	SideBySide{Width: 60}.Render(&out, diff)
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+
+	want := []string{
+		"",
+		"diff --git a/main.go b/main.go",
+		"@@ -9,4 +9,4 @@ func main() {",
+		"   9     a := 1              │    9     a := 1",
+		"  10     b := 2              │   10     b := 3",
+		"                             │   11     c := 4",
+		"  11 }                       │   12 }",
+		"",
+		"diff --git a/new.txt b/new.txt",
+		"new file mode 100644",
+		"@@ -0,0 +1 @@",
+		"                             │    1 xxxxxxxxxxxxxxxxxxxxxxx",
+		"                             │      xxxxxxxxxxxxxxxxxxxxxxx",
+		"                             │      xxxx",
+	}
+	if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("Render =\n%s\nwant\n%s", strings.Join(lines, "\n"), strings.Join(want, "\n"))
+	}
+	for _, line := range lines {
+		if n := utf8.RuneCountInString(line); n > 60 {
+			t.Errorf("line wider than 60 columns (%d): %q", n, line)
+		}
+	}
+}
+
+func TestWrap(t *testing.T) {
+	tests := []struct {
+		text  string
+		width int
+		want  []string
+	}{
+		{"", 5, []string{""}},
+		{"abc", 5, []string{"abc"}},
+		{"abcdef", 3, []string{"abc", "def"}},
+		{"héllo wörld", 4, []string{"héll", "o wö", "rld"}},
+	}
+	for _, tt := range tests {
+		if got := wrap(tt.text, tt.width); strings.Join(got, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("wrap(%q, %d) = %q, want %q", tt.text, tt.width, got, tt.want)
+		}
+	}
+
+	if got := expandTabs("\ta\tb\r"); got != "    a   b" {
+		t.Errorf("expandTabs = %q", got)
+	}
+}
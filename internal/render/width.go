@@ -0,0 +1,22 @@
+package render
+
+import (
+	"os"
+	"strconv"
+)
+
+// DefaultWidth is used when the terminal width is unknown, such as when
+// output is redirected and COLUMNS isn't set
+const DefaultWidth = 120
+
+// TerminalWidth returns the width of the terminal f writes to, then the
+// COLUMNS environment variable, then DefaultWidth
+func TerminalWidth(f *os.File) int {
+	if width := terminalWidth(f); width > 0 {
+		return width
+	}
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 0 {
+		return columns
+	}
+	return DefaultWidth
+}
@@ -0,0 +1,138 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Languages accepted by ui.language
+const (
+	LanguageAuto     = "auto" // From LC_ALL, LC_MESSAGES or LANG
+	LanguageEnglish  = "en"
+	LanguageJapanese = "ja"
+	LanguageChinese  = "zh"
+)
+
+// Languages lists the accepted ui.language values
+var Languages = []string{LanguageAuto, LanguageEnglish, LanguageJapanese, LanguageChinese}
+
+// Message identifies a user-facing message in the catalogs. Its text is
+// looked up in the active language's catalog, falling back to English.
+type Message string
+
+// catalogs holds the messages of each language. English must have every
+// message; the others may lag behind.
+var catalogs = map[string]map[Message]string{
+	LanguageEnglish:  english,
+	LanguageJapanese: japanese,
+	LanguageChinese:  chinese,
+}
+
+// language is the active catalog, chosen from the environment until
+// SetLanguage applies ui.language
+var language = detectLanguage()
+
+// emojiEnabled is cleared by DisableEmoji
+var emojiEnabled = true
+
+// SetLanguage activates the catalog of a language; auto or empty detects it
+// from the environment
+func SetLanguage(name string) error {
+	switch name {
+	case "", LanguageAuto:
+		language = detectLanguage()
+		return nil
+	}
+	if _, ok := catalogs[name]; !ok {
+		return fmt.Errorf("unknown language %q, must be one of: %s", name, strings.Join(Languages, ", "))
+	}
+	language = name
+	return nil
+}
+
+// Language returns the active language
+func Language() string {
+	return language
+}
+
+// DisableEmoji drops emoji from messages for the rest of the run, for
+// terminals and logs that can't show them. It can't be turned back on, so
+// --no-emoji wins over ui.emoji.
+func DisableEmoji() {
+	emojiEnabled = false
+}
+
+// T formats a message in the active language, with its emoji unless they
+// are disabled
+func T(key Message, a ...interface{}) string {
+	text, ok := catalogs[language][key]
+	if !ok {
+		text, ok = english[key]
+	}
+	if !ok {
+		return string(key) // A missing message shows its key rather than nothing
+	}
+	if len(a) > 0 {
+		text = fmt.Sprintf(text, a...)
+	}
+	if emoji := emojis[key]; emoji != "" && emojiEnabled {
+		text = emoji + " " + text
+	}
+	return text
+}
+
+// detectLanguage picks the catalog matching the POSIX locale variables,
+// English when none matches
+func detectLanguage() string {
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		value := os.Getenv(name)
+		if value == "" {
+			continue
+		}
+		// "ja_JP.UTF-8" -> "ja"; the first set variable decides
+		code := strings.ToLower(strings.FieldsFunc(value, func(r rune) bool {
+			return r == '_' || r == '-' || r == '.' || r == '@'
+		})[0])
+		if _, ok := catalogs[code]; ok {
+			return code
+		}
+		return LanguageEnglish
+	}
+	return LanguageEnglish
+}
+
+// stripEmoji removes emoji, and the spaces that separated them from the
+// text, when emoji are disabled
+func stripEmoji(s string) string {
+	if emojiEnabled {
+		return s
+	}
+	var b strings.Builder
+	skipSpace := false
+	for _, r := range s {
+		if isEmoji(r) {
+			skipSpace = true
+			continue
+		}
+		if skipSpace && r == ' ' {
+			continue
+		}
+		skipSpace = false
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// isEmoji reports whether r is an emoji or joins or modifies one
+func isEmoji(r rune) bool {
+	switch {
+	case r >= 0x1F000 && r <= 0x1FAFF, // Pictographs, emoticons, transport, flags
+		r >= 0x2600 && r <= 0x27BF, // Miscellaneous symbols and dingbats
+		r >= 0x2B00 && r <= 0x2BFF, // Arrows and stars such as ⭐
+		r >= 0x2300 && r <= 0x23FF, // Technical symbols such as ⏰ and ⌛
+		r == 0xFE0F, r == 0x200D:   // Variation selector and zero-width joiner
+		return true
+	}
+	return false
+}
@@ -0,0 +1,91 @@
+package ui
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestT(t *testing.T) {
+	defer func() {
+		language = LanguageEnglish
+		emojiEnabled = true
+	}()
+
+	if err := SetLanguage(LanguageEnglish); err != nil {
+		t.Fatal(err)
+	}
+	if got := T(MsgTotalSnapshots, 3); got != "Total: 3 snapshots" {
+		t.Errorf("T(MsgTotalSnapshots) = %q", got)
+	}
+	if got := T(MsgNoSnapshots); got != "📸 No snapshots found." {
+		t.Errorf("T(MsgNoSnapshots) = %q", got)
+	}
+
+	if err := SetLanguage(LanguageJapanese); err != nil {
+		t.Fatal(err)
+	}
+	if got := T(MsgTotalSnapshotsForFile, 2, "main.go"); got != "合計: 'main.go' のスナップショット 2 件" {
+		t.Errorf("T(MsgTotalSnapshotsForFile) in Japanese = %q", got)
+	}
+	if got := T(Message("missing")); got != "missing" {
+		t.Errorf("T of an unknown message = %q, want its key", got)
+	}
+	if err := SetLanguage("fr"); err == nil {
+		t.Error("expected an unknown language to be rejected")
+	}
+
+	SetLanguage(LanguageEnglish)
+	DisableEmoji()
+	if got := T(MsgNoSnapshots); got != "No snapshots found." {
+		t.Errorf("T without emoji = %q", got)
+	}
+	if got := stripEmoji("⚠️  Warning: ✅ done → next"); got != "Warning: done → next" {
+		t.Errorf("stripEmoji = %q", got)
+	}
+}
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		lcAll, lang string
+		expected    string
+	}{
+		{"", "ja_JP.UTF-8", LanguageJapanese},
+		{"", "zh_CN.UTF-8", LanguageChinese},
+		{"", "de_DE.UTF-8", LanguageEnglish},
+		{"C", "ja_JP.UTF-8", LanguageEnglish}, // LC_ALL overrides LANG
+		{"", "", LanguageEnglish},
+	}
+	for _, tt := range tests {
+		t.Setenv("LC_ALL", tt.lcAll)
+		t.Setenv("LC_MESSAGES", "")
+		t.Setenv("LANG", tt.lang)
+		if got := detectLanguage(); got != tt.expected {
+			t.Errorf("detectLanguage(LC_ALL=%q LANG=%q) = %q, want %q", tt.lcAll, tt.lang, got, tt.expected)
+		}
+	}
+}
+
+// TestCatalogs checks that translations only use known messages and take
+// the same arguments as the English text
+func TestCatalogs(t *testing.T) {
+	verbs := regexp.MustCompile(`%(\[\d+\])?[a-z]`)
+	for name, catalog := range catalogs {
+		for key, text := range catalog {
+			source, ok := english[key]
+			if !ok {
+				t.Errorf("%s message %s has no English text", name, key)
+				continue
+			}
+			if got, want := len(verbs.FindAllString(text, -1)), len(verbs.FindAllString(source, -1)); got != want {
+				t.Errorf("%s message %s takes %d arguments, English takes %d", name, key, got, want)
+			}
+		}
+	}
+	for key := range english {
+		for _, name := range []string{LanguageJapanese, LanguageChinese} {
+			if _, ok := catalogs[name][key]; !ok {
+				t.Errorf("message %s is missing from the %s catalog", key, name)
+			}
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package ui
+
+// Messages shared by several commands. Keys name what a message says, so a
+// reworded English text keeps its translations until they are updated.
+const (
+	MsgNotInitialized         Message = "not_initialized"
+	MsgRunInit                Message = "run_init"
+	MsgRepoNotInitialized     Message = "repo_not_initialized"
+	MsgRunInitThere           Message = "run_init_there"
+	MsgNothingToClean         Message = "nothing_to_clean"
+	MsgRecentSnapshots        Message = "recent_snapshots"
+	MsgNoSnapshots            Message = "no_snapshots"
+	MsgTryWithoutFileFilter   Message = "try_without_file_filter"
+	MsgNoSnapshotsPastOffset  Message = "no_snapshots_past_offset"
+	MsgTryWideningFilters     Message = "try_widening_filters"
+	MsgCreateFirstSnapshot    Message = "create_first_snapshot"
+	MsgTotalSnapshotsForFile  Message = "total_snapshots_for_file"
+	MsgTotalMatchingSnapshots Message = "total_matching_snapshots"
+	MsgTotalSnapshots         Message = "total_snapshots"
+	MsgMoreSnapshots          Message = "more_snapshots"
+	MsgUseShow                Message = "use_show"
+	MsgUseRestore             Message = "use_restore"
+	MsgWarning                Message = "warning"
+	MsgOutsideRepository      Message = "outside_repository"
+	MsgGitRepository          Message = "git_repository"
+	MsgStatusInitialized      Message = "status_initialized"
+	MsgStatusNotInitialized   Message = "status_not_initialized"
+	MsgUseHelp                Message = "use_help"
+	MsgUseCommandHelp         Message = "use_command_help"
+)
+
+// emojis prefix messages in every language, unless disabled
+var emojis = map[Message]string{
+	MsgNotInitialized:       "❌",
+	MsgRepoNotInitialized:   "❌",
+	MsgRecentSnapshots:      "📸",
+	MsgNoSnapshots:          "📸",
+	MsgWarning:              "⚠️ ",
+	MsgGitRepository:        "📂",
+	MsgStatusInitialized:    "✅",
+	MsgStatusNotInitialized: "❌",
+}
+
+var english = map[Message]string{
+	MsgNotInitialized:         "Time Machine is not initialized!",
+	MsgRunInit:                "Run 'timemachine init' to get started.",
+	MsgRepoNotInitialized:     "%s: Time Machine is not initialized",
+	MsgRunInitThere:           "Run 'timemachine init' there first.",
+	MsgNothingToClean:         "Nothing to clean.",
+	MsgRecentSnapshots:        "Recent snapshots:",
+	MsgNoSnapshots:            "No snapshots found.",
+	MsgTryWithoutFileFilter:   "Try without the --file filter or check if '%s' exists.",
+	MsgNoSnapshotsPastOffset:  "There are no snapshots past offset %d.",
+	MsgTryWideningFilters:     "Try widening the --branch, --since, --since-commit, --until or --grep filters.",
+	MsgCreateFirstSnapshot:    "Create your first snapshot by making changes to files.",
+	MsgTotalSnapshotsForFile:  "Total: %d snapshots for '%s'",
+	MsgTotalMatchingSnapshots: "Total: %d matching snapshots",
+	MsgTotalSnapshots:         "Total: %d snapshots",
+	MsgMoreSnapshots:          "There may be more: use --offset %d for the next page or --limit 0 for all",
+	MsgUseShow:                "Use 'timemachine show <hash>' to see details",
+	MsgUseRestore:             "Use 'timemachine restore <hash>' to restore a snapshot",
+	MsgWarning:                "Warning: %v",
+	MsgOutsideRepository:      "Some commands may not work outside of a Git repository.",
+	MsgGitRepository:          "Git Repository: %s",
+	MsgStatusInitialized:      "Time Machine: Initialized and ready",
+	MsgStatusNotInitialized:   "Time Machine: Not initialized",
+	MsgUseHelp:                "Use 'timemachine --help' for detailed command information",
+	MsgUseCommandHelp:         "Use 'timemachine <command> --help' for specific command help",
+}
+
+var japanese = map[Message]string{
+	MsgNotInitialized:         "Time Machine が初期化されていません！",
+	MsgRunInit:                "'timemachine init' を実行して始めてください。",
+	MsgRepoNotInitialized:     "%s: Time Machine が初期化されていません",
+	MsgRunInitThere:           "まずそのディレクトリで 'timemachine init' を実行してください。",
+	MsgNothingToClean:         "クリーンアップするものはありません。",
+	MsgRecentSnapshots:        "最近のスナップショット:",
+	MsgNoSnapshots:            "スナップショットが見つかりません。",
+	MsgTryWithoutFileFilter:   "--file フィルターを外すか、'%s' が存在するか確認してください。",
+	MsgNoSnapshotsPastOffset:  "オフセット %d より後のスナップショットはありません。",
+	MsgTryWideningFilters:     "--branch、--since、--since-commit、--until、--grep の条件を広げてみてください。",
+	MsgCreateFirstSnapshot:    "ファイルを変更すると最初のスナップショットが作成されます。",
+	MsgTotalSnapshotsForFile:  "合計: '%[2]s' のスナップショット %[1]d 件",
+	MsgTotalMatchingSnapshots: "合計: 一致するスナップショット %d 件",
+	MsgTotalSnapshots:         "合計: スナップショット %d 件",
+	MsgMoreSnapshots:          "続きがある可能性があります: 次のページは --offset %d、すべて表示するには --limit 0 を使用してください",
+	MsgUseShow:                "詳細は 'timemachine show <hash>' で確認できます",
+	MsgUseRestore:             "スナップショットを復元するには 'timemachine restore <hash>' を使用してください",
+	MsgWarning:                "警告: %v",
+	MsgOutsideRepository:      "Git リポジトリの外では一部のコマンドが動作しない場合があります。",
+	MsgGitRepository:          "Git リポジトリ: %s",
+	MsgStatusInitialized:      "Time Machine: 初期化済み、利用可能です",
+	MsgStatusNotInitialized:   "Time Machine: 未初期化",
+	MsgUseHelp:                "コマンドの詳細は 'timemachine --help' を参照してください",
+	MsgUseCommandHelp:         "各コマンドのヘルプは 'timemachine <command> --help' を参照してください",
+}
+
+var chinese = map[Message]string{
+	MsgNotInitialized:         "Time Machine 尚未初始化！",
+	MsgRunInit:                "运行 'timemachine init' 开始使用。",
+	MsgRepoNotInitialized:     "%s: Time Machine 尚未初始化",
+	MsgRunInitThere:           "请先在该目录运行 'timemachine init'。",
+	MsgNothingToClean:         "没有需要清理的内容。",
+	MsgRecentSnapshots:        "最近的快照:",
+	MsgNoSnapshots:            "未找到快照。",
+	MsgTryWithoutFileFilter:   "请去掉 --file 过滤条件，或检查 '%s' 是否存在。",
+	MsgNoSnapshotsPastOffset:  "偏移量 %d 之后没有快照。",
+	MsgTryWideningFilters:     "请尝试放宽 --branch、--since、--since-commit、--until 或 --grep 过滤条件。",
+	MsgCreateFirstSnapshot:    "修改文件即可创建第一个快照。",
+	MsgTotalSnapshotsForFile:  "共计: '%[2]s' 的快照 %[1]d 个",
+	MsgTotalMatchingSnapshots: "共计: 匹配的快照 %d 个",
+	MsgTotalSnapshots:         "共计: 快照 %d 个",
+	MsgMoreSnapshots:          "可能还有更多: 使用 --offset %d 查看下一页，或使用 --limit 0 查看全部",
+	MsgUseShow:                "使用 'timemachine show <hash>' 查看详情",
+	MsgUseRestore:             "使用 'timemachine restore <hash>' 恢复快照",
+	MsgWarning:                "警告: %v",
+	MsgOutsideRepository:      "在 Git 仓库之外，部分命令可能无法使用。",
+	MsgGitRepository:          "Git 仓库: %s",
+	MsgStatusInitialized:      "Time Machine: 已初始化，可以使用",
+	MsgStatusNotInitialized:   "Time Machine: 未初始化",
+	MsgUseHelp:                "使用 'timemachine --help' 查看命令详情",
+	MsgUseCommandHelp:         "使用 'timemachine <command> --help' 查看具体命令的帮助",
+}
@@ -0,0 +1,70 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// current is the active theme, set once at startup by Configure
+var current, _ = NewTheme(ThemeDefault, nil)
+
+// Configure activates a theme and applies the color_output setting. On error
+// the previous theme stays active.
+func Configure(name string, custom map[string]string, colorOutput bool) error {
+	if !colorOutput {
+		color.NoColor = true
+	}
+
+	theme, err := NewTheme(name, custom)
+	if err != nil {
+		return err
+	}
+
+	current = theme
+	return nil
+}
+
+// Current returns the active theme
+func Current() *Theme {
+	return current
+}
+
+// Color returns the active theme's color for a role
+func Color(role Role) *color.Color {
+	return current.Color(role)
+}
+
+// Sprint formats values in the active theme's color for a role
+func Sprint(role Role, a ...interface{}) string {
+	return current.Color(role).Sprint(a...)
+}
+
+// printLine prints a line in the role's color, adding a trailing newline
+// like the fatih/color helpers (color.Green etc.) do. Emoji are dropped
+// when disabled.
+func printLine(role Role, format string, a ...interface{}) {
+	if !strings.HasSuffix(format, "\n") {
+		format += "\n"
+	}
+	current.Color(role).Print(stripEmoji(fmt.Sprintf(format, a...)))
+}
+
+// Success prints a line in the success color
+func Success(format string, a ...interface{}) { printLine(RoleSuccess, format, a...) }
+
+// Warning prints a line in the warning color
+func Warning(format string, a ...interface{}) { printLine(RoleWarning, format, a...) }
+
+// Error prints a line in the error color
+func Error(format string, a ...interface{}) { printLine(RoleError, format, a...) }
+
+// Info prints a line in the info color
+func Info(format string, a ...interface{}) { printLine(RoleInfo, format, a...) }
+
+// Heading prints a line in the heading color
+func Heading(format string, a ...interface{}) { printLine(RoleHeading, format, a...) }
+
+// Line prints a line in the color of any role
+func Line(role Role, format string, a ...interface{}) { printLine(role, format, a...) }
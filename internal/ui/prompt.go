@@ -0,0 +1,102 @@
+package ui
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/mattn/go-isatty"
+)
+
+// AssumeYesEnv answers every confirmation yes when set to a true value
+// (1, true, yes), like --yes
+const AssumeYesEnv = "TIMEMACHINE_ASSUME_YES"
+
+// ErrNoInput is returned by Confirm and RequireInput when a command needs an
+// answer but nobody can give one: --no-input was given, or standard input
+// is not a terminal
+var ErrNoInput = errors.New("no interactive input")
+
+var (
+	assumeYes bool // Set by SetAssumeYes
+	noInput   bool // Set by SetNoInput
+
+	// input is where answers are read from, replaced by tests
+	input = bufio.NewReader(os.Stdin)
+
+	// inputIsTerminal reports whether a person can answer; replaced by tests
+	inputIsTerminal = func() bool {
+		return isatty.IsTerminal(os.Stdin.Fd()) || isatty.IsCygwinTerminal(os.Stdin.Fd())
+	}
+)
+
+// SetAssumeYes answers every confirmation yes for the rest of the run, for
+// --yes
+func SetAssumeYes() {
+	assumeYes = true
+}
+
+// SetNoInput stops commands from reading answers for the rest of the run,
+// for --no-input
+func SetNoInput() {
+	noInput = true
+}
+
+// AssumeYes reports whether confirmations are answered yes, by --yes or
+// TIMEMACHINE_ASSUME_YES
+func AssumeYes() bool {
+	if assumeYes {
+		return true
+	}
+	value, err := strconv.ParseBool(strings.TrimSpace(os.Getenv(AssumeYesEnv)))
+	if err != nil {
+		value = strings.EqualFold(strings.TrimSpace(os.Getenv(AssumeYesEnv)), "yes")
+	}
+	return value
+}
+
+// CanPrompt reports whether questions may be asked: input is allowed and
+// standard input is a terminal
+func CanPrompt() bool {
+	return !noInput && inputIsTerminal()
+}
+
+// RequireInput fails with ErrNoInput when what, an interactive mode, can't
+// ask its questions, rather than leave it waiting on input that never comes
+func RequireInput(what string) error {
+	if CanPrompt() {
+		return nil
+	}
+	return fmt.Errorf("%w: %s needs a terminal to answer its questions", ErrNoInput, what)
+}
+
+// Confirm asks a yes/no question, defaulting to no. With --yes or
+// TIMEMACHINE_ASSUME_YES the answer is yes without asking; without a
+// terminal, or with --no-input, it fails with ErrNoInput instead of hanging.
+// End of input answers no.
+func Confirm(question string) (bool, error) {
+	return confirm(os.Stdout, question)
+}
+
+func confirm(out io.Writer, question string) (bool, error) {
+	if AssumeYes() {
+		fmt.Fprintf(out, "%s (y/N): y %s\n", question, Sprint(RoleMuted, "(assumed)"))
+		return true, nil
+	}
+	if !CanPrompt() {
+		return false, fmt.Errorf("%w: %q needs an answer; rerun with --yes (or set %s=1) to confirm", ErrNoInput, question, AssumeYesEnv)
+	}
+
+	fmt.Fprintf(out, "%s (y/N): ", question)
+	response, err := input.ReadString('\n')
+	if err != nil && response == "" {
+		fmt.Fprintln(out)
+		return false, nil
+	}
+	response = strings.TrimSpace(strings.ToLower(response))
+	return response == "y" || response == "yes", nil
+}
@@ -0,0 +1,66 @@
+package ui
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestConfirm(t *testing.T) {
+	defer func(terminal func() bool) {
+		inputIsTerminal, assumeYes, noInput = terminal, false, false
+	}(inputIsTerminal)
+	t.Setenv(AssumeYesEnv, "")
+
+	tests := []struct {
+		name      string
+		answer    string
+		terminal  bool
+		assumeYes bool
+		noInput   bool
+		env       string
+		expected  bool
+		noAnswer  bool
+	}{
+		{name: "yes", answer: "y\n", terminal: true, expected: true},
+		{name: "long yes", answer: " YES \n", terminal: true, expected: true},
+		{name: "default no", answer: "\n", terminal: true},
+		{name: "end of input", answer: "", terminal: true},
+		{name: "no terminal", answer: "y\n", noAnswer: true},
+		{name: "no input", answer: "y\n", terminal: true, noInput: true, noAnswer: true},
+		{name: "assume yes", assumeYes: true, expected: true},
+		{name: "assume yes over no input", assumeYes: true, noInput: true, expected: true},
+		{name: "environment", env: "1", expected: true},
+		{name: "environment yes", env: "yes", expected: true},
+		{name: "environment false", env: "false", answer: "y\n", terminal: true, expected: true},
+		{name: "environment false no terminal", env: "0", noAnswer: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input = bufio.NewReader(strings.NewReader(tt.answer))
+			inputIsTerminal = func() bool { return tt.terminal }
+			assumeYes, noInput = tt.assumeYes, tt.noInput
+			t.Setenv(AssumeYesEnv, tt.env)
+
+			confirmed, err := confirm(io.Discard, "Continue?")
+			if tt.noAnswer {
+				if !errors.Is(err, ErrNoInput) {
+					t.Errorf("Expected ErrNoInput, got %v", err)
+				}
+				return
+			}
+			if err != nil || confirmed != tt.expected {
+				t.Errorf("confirm() = %v, %v; want %v", confirmed, err, tt.expected)
+			}
+		})
+	}
+
+	inputIsTerminal = func() bool { return false }
+	assumeYes, noInput = false, false
+	if err := RequireInput("restore --patch"); !errors.Is(err, ErrNoInput) {
+		t.Errorf("Expected RequireInput to fail without a terminal, got %v", err)
+	}
+}
@@ -0,0 +1,241 @@
+// Package ui holds the output theme shared by all commands. Commands print
+// through semantic roles (success, warning, added, ...) instead of picking
+// colors themselves, so the palette can be switched with ui.theme.
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// Role is the meaning of a piece of output, mapped to a color by the theme
+type Role string
+
+// Output roles
+const (
+	RoleSuccess    Role = "success"     // Completed actions, healthy status
+	RoleWarning    Role = "warning"     // Recoverable problems, hints
+	RoleError      Role = "error"       // Failures
+	RoleInfo       Role = "info"        // Neutral highlights
+	RoleHeading    Role = "heading"     // Section titles
+	RoleHash       Role = "hash"        // Snapshot hashes
+	RoleMuted      Role = "muted"       // Secondary details such as line numbers
+	RoleAdded      Role = "added"       // Added files and lines
+	RoleModified   Role = "modified"    // Modified files
+	RoleDeleted    Role = "deleted"     // Deleted files and lines
+	RoleRenamed    Role = "renamed"     // Renamed or copied files
+	RoleDiffHeader Role = "diff_header" // "diff --git" lines
+	RoleDiffHunk   Role = "diff_hunk"   // "@@" hunk headers
+)
+
+// Roles lists every role a theme defines
+var Roles = []Role{
+	RoleSuccess, RoleWarning, RoleError, RoleInfo, RoleHeading, RoleHash, RoleMuted,
+	RoleAdded, RoleModified, RoleDeleted, RoleRenamed, RoleDiffHeader, RoleDiffHunk,
+}
+
+// Theme names accepted by ui.theme
+const (
+	ThemeDefault    = "default"
+	ThemeDark       = "dark"
+	ThemeLight      = "light"
+	ThemeMonochrome = "monochrome"
+	ThemeCustom     = "custom"
+)
+
+// ThemeNames lists the accepted ui.theme values
+var ThemeNames = []string{ThemeDefault, ThemeDark, ThemeLight, ThemeMonochrome, ThemeCustom}
+
+// Theme maps output roles to colors
+type Theme struct {
+	Name   string
+	colors map[Role][]color.Attribute
+}
+
+// Color returns the color for a role. Unknown roles print unstyled.
+func (t *Theme) Color(role Role) *color.Color {
+	return color.New(t.colors[role]...)
+}
+
+// builtinThemes holds the palettes for every theme except custom
+var builtinThemes = map[string]map[Role][]color.Attribute{
+	ThemeDefault: {
+		RoleSuccess:    {color.FgGreen},
+		RoleWarning:    {color.FgYellow},
+		RoleError:      {color.FgRed},
+		RoleInfo:       {color.FgCyan},
+		RoleHeading:    {color.FgCyan},
+		RoleHash:       {color.FgYellow},
+		RoleMuted:      {color.FgYellow},
+		RoleAdded:      {color.FgGreen},
+		RoleModified:   {color.FgYellow},
+		RoleDeleted:    {color.FgRed},
+		RoleRenamed:    {color.FgBlue},
+		RoleDiffHeader: {color.FgCyan, color.Bold},
+		RoleDiffHunk:   {color.FgBlue},
+	},
+	// Bright variants stay readable on dark backgrounds
+	ThemeDark: {
+		RoleSuccess:    {color.FgHiGreen},
+		RoleWarning:    {color.FgHiYellow},
+		RoleError:      {color.FgHiRed},
+		RoleInfo:       {color.FgHiCyan},
+		RoleHeading:    {color.FgHiCyan, color.Bold},
+		RoleHash:       {color.FgHiYellow},
+		RoleMuted:      {color.FgHiBlack},
+		RoleAdded:      {color.FgHiGreen},
+		RoleModified:   {color.FgHiYellow},
+		RoleDeleted:    {color.FgHiRed},
+		RoleRenamed:    {color.FgHiBlue},
+		RoleDiffHeader: {color.FgHiCyan, color.Bold},
+		RoleDiffHunk:   {color.FgHiMagenta},
+	},
+	// Avoids yellow and cyan, which wash out on white backgrounds
+	ThemeLight: {
+		RoleSuccess:    {color.FgGreen},
+		RoleWarning:    {color.FgMagenta},
+		RoleError:      {color.FgRed},
+		RoleInfo:       {color.FgBlue},
+		RoleHeading:    {color.FgBlue, color.Bold},
+		RoleHash:       {color.FgMagenta},
+		RoleMuted:      {color.FgBlack, color.Faint},
+		RoleAdded:      {color.FgGreen},
+		RoleModified:   {color.FgMagenta},
+		RoleDeleted:    {color.FgRed},
+		RoleRenamed:    {color.FgBlue},
+		RoleDiffHeader: {color.FgBlue, color.Bold},
+		RoleDiffHunk:   {color.FgMagenta},
+	},
+	// Text attributes only, for terminals and logs without color
+	ThemeMonochrome: {
+		RoleError:      {color.Bold},
+		RoleWarning:    {color.Bold},
+		RoleHeading:    {color.Bold},
+		RoleHash:       {color.Bold},
+		RoleMuted:      {color.Faint},
+		RoleDeleted:    {color.Faint},
+		RoleDiffHeader: {color.Bold},
+		RoleDiffHunk:   {color.Underline},
+	},
+}
+
+// NewTheme builds a theme by name. The custom theme starts from the default
+// palette and overrides roles from custom (role name -> color spec such as
+// "bold hi-green" or "red bg-white"); custom is ignored for other themes.
+func NewTheme(name string, custom map[string]string) (*Theme, error) {
+	if name == "" {
+		name = ThemeDefault
+	}
+
+	base := name
+	if name == ThemeCustom {
+		base = ThemeDefault
+	}
+	palette, ok := builtinThemes[base]
+	if !ok {
+		return nil, fmt.Errorf("unknown theme '%s', must be one of: %s", name, strings.Join(ThemeNames, ", "))
+	}
+
+	theme := &Theme{Name: name, colors: make(map[Role][]color.Attribute, len(Roles))}
+	for role, attrs := range palette {
+		theme.colors[role] = attrs
+	}
+
+	if name != ThemeCustom {
+		return theme, nil
+	}
+
+	// Sort for deterministic error messages
+	keys := make([]string, 0, len(custom))
+	for key := range custom {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		role := Role(strings.ToLower(strings.TrimSpace(key)))
+		if !isRole(role) {
+			return nil, fmt.Errorf("unknown theme role '%s'", key)
+		}
+		attrs, err := ParseColor(custom[key])
+		if err != nil {
+			return nil, fmt.Errorf("theme role '%s': %w", key, err)
+		}
+		theme.colors[role] = attrs
+	}
+
+	return theme, nil
+}
+
+func isRole(role Role) bool {
+	for _, known := range Roles {
+		if role == known {
+			return true
+		}
+	}
+	return false
+}
+
+// colorNames maps color names to their foreground attribute; background and
+// bright variants are derived from the fixed offsets fatih/color uses
+var colorNames = map[string]color.Attribute{
+	"black":   color.FgBlack,
+	"red":     color.FgRed,
+	"green":   color.FgGreen,
+	"yellow":  color.FgYellow,
+	"blue":    color.FgBlue,
+	"magenta": color.FgMagenta,
+	"cyan":    color.FgCyan,
+	"white":   color.FgWhite,
+}
+
+var styleNames = map[string]color.Attribute{
+	"bold":      color.Bold,
+	"faint":     color.Faint,
+	"italic":    color.Italic,
+	"underline": color.Underline,
+}
+
+// ParseColor parses a color spec: space or comma separated words from
+// black, red, green, yellow, blue, magenta, cyan and white, optionally
+// prefixed with hi- (bright) and/or bg- (background), plus the styles bold,
+// faint, italic and underline. "none" or an empty spec means unstyled.
+func ParseColor(spec string) ([]color.Attribute, error) {
+	words := strings.FieldsFunc(strings.ToLower(spec), func(r rune) bool {
+		return r == ' ' || r == ','
+	})
+
+	attrs := []color.Attribute{}
+	for _, word := range words {
+		if word == "none" || word == "default" {
+			continue
+		}
+		if style, ok := styleNames[word]; ok {
+			attrs = append(attrs, style)
+			continue
+		}
+
+		name := word
+		background := strings.HasPrefix(name, "bg-")
+		name = strings.TrimPrefix(name, "bg-")
+		bright := strings.HasPrefix(name, "hi-") || strings.HasPrefix(name, "bright-")
+		name = strings.TrimPrefix(strings.TrimPrefix(name, "hi-"), "bright-")
+
+		attr, ok := colorNames[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown color '%s'", word)
+		}
+		if background {
+			attr += color.BgBlack - color.FgBlack
+		}
+		if bright {
+			attr += color.FgHiBlack - color.FgBlack
+		}
+		attrs = append(attrs, attr)
+	}
+
+	return attrs, nil
+}
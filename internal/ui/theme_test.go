@@ -0,0 +1,141 @@
+package ui
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+)
+
+func TestParseColor(t *testing.T) {
+	tests := []struct {
+		spec     string
+		expected []color.Attribute
+		wantErr  bool
+	}{
+		{"green", []color.Attribute{color.FgGreen}, false},
+		{"bold red", []color.Attribute{color.Bold, color.FgRed}, false},
+		{"hi-cyan,underline", []color.Attribute{color.FgHiCyan, color.Underline}, false},
+		{"bright-magenta", []color.Attribute{color.FgHiMagenta}, false},
+		{"white bg-blue", []color.Attribute{color.FgWhite, color.BgBlue}, false},
+		{"bg-hi-yellow", []color.Attribute{color.BgHiYellow}, false},
+		{"BOLD Green", []color.Attribute{color.Bold, color.FgGreen}, false},
+		{"none", []color.Attribute{}, false},
+		{"", []color.Attribute{}, false},
+		{"purple", nil, true},
+		{"bold hi-", nil, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseColor(tt.spec)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseColor(%q): expected error %v, got %v", tt.spec, tt.wantErr, err)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("ParseColor(%q): expected %v, got %v", tt.spec, tt.expected, got)
+		}
+	}
+}
+
+func TestBuiltinThemesCoverRoles(t *testing.T) {
+	for _, name := range ThemeNames {
+		theme, err := NewTheme(name, nil)
+		if err != nil {
+			t.Fatalf("NewTheme(%q) failed: %v", name, err)
+		}
+		if theme.Name != name {
+			t.Errorf("Expected theme name %q, got %q", name, theme.Name)
+		}
+		// Monochrome intentionally leaves some roles unstyled
+		if name == ThemeMonochrome {
+			continue
+		}
+		for _, role := range Roles {
+			if len(theme.colors[role]) == 0 {
+				t.Errorf("Theme %q has no color for role %q", name, role)
+			}
+		}
+	}
+}
+
+func TestMonochromeUsesNoColors(t *testing.T) {
+	theme, err := NewTheme(ThemeMonochrome, nil)
+	if err != nil {
+		t.Fatalf("NewTheme failed: %v", err)
+	}
+	for role, attrs := range theme.colors {
+		for _, attr := range attrs {
+			if attr >= color.FgBlack {
+				t.Errorf("Monochrome role %q uses color attribute %d", role, attr)
+			}
+		}
+	}
+}
+
+func TestNewThemeCustom(t *testing.T) {
+	theme, err := NewTheme(ThemeCustom, map[string]string{
+		"error": "bold hi-red",
+		"Hash":  "magenta",
+	})
+	if err != nil {
+		t.Fatalf("NewTheme failed: %v", err)
+	}
+
+	if got := theme.colors[RoleError]; !reflect.DeepEqual(got, []color.Attribute{color.Bold, color.FgHiRed}) {
+		t.Errorf("Expected custom error color, got %v", got)
+	}
+	if got := theme.colors[RoleHash]; !reflect.DeepEqual(got, []color.Attribute{color.FgMagenta}) {
+		t.Errorf("Expected custom hash color, got %v", got)
+	}
+	// Roles not overridden fall back to the default palette
+	if got := theme.colors[RoleSuccess]; !reflect.DeepEqual(got, builtinThemes[ThemeDefault][RoleSuccess]) {
+		t.Errorf("Expected default success color, got %v", got)
+	}
+
+	// Overrides are ignored unless the theme is custom
+	theme, err = NewTheme(ThemeDark, map[string]string{"error": "green"})
+	if err != nil {
+		t.Fatalf("NewTheme failed: %v", err)
+	}
+	if got := theme.colors[RoleError]; !reflect.DeepEqual(got, builtinThemes[ThemeDark][RoleError]) {
+		t.Errorf("Expected dark error color, got %v", got)
+	}
+}
+
+func TestNewThemeErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		theme    string
+		custom   map[string]string
+		expected string
+	}{
+		{"unknown theme", "solarized", nil, "unknown theme 'solarized'"},
+		{"unknown role", ThemeCustom, map[string]string{"banner": "red"}, "unknown theme role 'banner'"},
+		{"unknown color", ThemeCustom, map[string]string{"error": "crimson"}, "unknown color 'crimson'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewTheme(tt.theme, tt.custom)
+			if err == nil || !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("Expected error containing %q, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestConfigureKeepsThemeOnError(t *testing.T) {
+	defer func(theme *Theme) { current = theme }(current)
+
+	if err := Configure(ThemeLight, nil, true); err != nil {
+		t.Fatalf("Configure failed: %v", err)
+	}
+	if err := Configure("bogus", nil, true); err == nil {
+		t.Fatal("Expected error for unknown theme")
+	}
+	if Current().Name != ThemeLight {
+		t.Errorf("Expected light theme to stay active, got %q", Current().Name)
+	}
+}
@@ -10,19 +10,7 @@ import (
 
 // CalculateDirectorySize calculates the total size of all files in a directory
 func CalculateDirectorySize(dirPath string) (int64, error) {
-	var size int64
-	
-	err := filepath.Walk(dirPath, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return nil // Skip files we can't access
-		}
-		if !info.IsDir() {
-			size += info.Size()
-		}
-		return nil
-	})
-	
-	return size, err
+	return core.DirectorySize(dirPath)
 }
 
 // FormatBytes formats bytes in human-readable format
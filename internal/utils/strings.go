@@ -1,14 +1,16 @@
 package utils
 
-// TruncateString truncates a string to the specified length with ellipsis
+// TruncateString truncates a string to at most maxLen runes with ellipsis,
+// never cutting a multi-byte character in half
 func TruncateString(s string, maxLen int) string {
-	if len(s) <= maxLen {
+	runes := []rune(s)
+	if len(runes) <= maxLen {
 		return s
 	}
 	if maxLen <= 3 {
-		return s[:maxLen]
+		return string(runes[:max(maxLen, 0)])
 	}
-	return s[:maxLen-3] + "..."
+	return string(runes[:maxLen-3]) + "..."
 }
 
 // Contains checks if string s contains substring
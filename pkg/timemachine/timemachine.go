@@ -0,0 +1,115 @@
+// Package timemachine embeds TimeMachine in other Go programs: it lists,
+// takes and restores the snapshots of a project initialized with
+// 'timemachine init', sharing the shadow repository with the CLI and a
+// running watcher.
+//
+//	project, err := timemachine.Open(".")
+//	if err != nil {
+//		return err
+//	}
+//	snapshots, err := project.ListSnapshots(timemachine.Filter{Limit: 10, Stats: true})
+//
+// The API is stable: fields and functions are only ever added, never renamed
+// or removed.
+package timemachine
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/deepakkumarnarayana/timemachine-cli/internal/core"
+)
+
+// Snapshot is one snapshot with its metadata. FilesChanged, Insertions and
+// Deletions are only set when listed with Filter.Stats.
+type Snapshot = core.Snapshot
+
+// Filter selects snapshots; zero fields match everything
+type Filter = core.SnapshotFilter
+
+// RestoreJournal records a restore: the snapshot restored, the snapshot of
+// the state before it, and the files written
+type RestoreJournal = core.RestoreJournal
+
+// Errors Open returns, to be checked with errors.Is
+var (
+	ErrNotGitRepository = core.ErrNotGitRepository
+	ErrNotInitialized   = core.ErrNotInitialized
+)
+
+// Project is a Git repository whose working tree TimeMachine snapshots
+type Project struct {
+	state *core.AppState
+	git   *core.GitManager
+}
+
+// Open opens the project containing dir, with its timemachine.yaml and
+// TIMEMACHINE_* settings. The project must have been initialized.
+func Open(dir string) (*Project, error) {
+	state, err := core.LoadAppStateAt(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !state.IsInitialized {
+		return nil, fmt.Errorf("%s: %w", state.ProjectRoot, ErrNotInitialized)
+	}
+	return &Project{state: state, git: core.NewGitManager(state)}, nil
+}
+
+// Init initializes the project containing dir, like 'timemachine init'
+// without writing an ignore file, and opens it. An initialized project is
+// opened as is.
+func Init(dir string) (*Project, error) {
+	state, err := core.LoadAppStateAt(dir)
+	if err != nil {
+		return nil, err
+	}
+	git := core.NewGitManager(state)
+	if !state.IsInitialized {
+		if err := git.InitializeShadowRepo(); err != nil {
+			return nil, err
+		}
+	}
+	return &Project{state: state, git: git}, nil
+}
+
+// Root returns the absolute path of the project's working tree
+func (p *Project) Root() string {
+	return p.state.ProjectRoot
+}
+
+// ListSnapshots returns the snapshots matching filter, newest first, read in
+// one pass over the history
+func (p *Project) ListSnapshots(filter Filter) ([]Snapshot, error) {
+	return p.git.FilterSnapshots(filter)
+}
+
+// WalkSnapshots calls fn for each snapshot matching filter, newest first, as
+// the history is read; an error from fn stops the walk and is returned
+func (p *Project) WalkSnapshots(filter Filter, fn func(Snapshot) error) error {
+	return p.git.WalkSnapshots(filter, fn)
+}
+
+// CreateSnapshot snapshots the working tree and returns the latest snapshot,
+// an earlier one when nothing changed since. An empty message uses
+// git.message_template.
+func (p *Project) CreateSnapshot(message string) (Snapshot, error) {
+	if err := p.git.CreateSnapshot(message); err != nil {
+		return Snapshot{}, err
+	}
+	snapshots, err := p.git.FilterSnapshots(Filter{Limit: 1})
+	if err != nil {
+		return Snapshot{}, err
+	}
+	if len(snapshots) == 0 {
+		return Snapshot{}, fmt.Errorf("no snapshot was taken: the working tree is empty")
+	}
+	return snapshots[0], nil
+}
+
+// Restore writes the files of snapshot hash into the working tree, limited
+// to pathspecs when given. The current state is snapshotted first, so the
+// restore can be undone by restoring the journal's Backup.
+func (p *Project) Restore(ctx context.Context, hash string, pathspecs ...string) (*RestoreJournal, error) {
+	return p.git.JournaledRestore(ctx, hash, pathspecs, core.DefaultRestoreBatchSize, nil)
+}
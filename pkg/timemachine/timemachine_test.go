@@ -0,0 +1,87 @@
+package timemachine
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// gitRepository creates a Git repository
+func gitRepository(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", dir},
+		{"-C", dir, "config", "user.name", "Test User"},
+		{"-C", dir, "config", "user.email", "test@example.com"},
+	} {
+		if output, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+	}
+	return dir
+}
+
+func TestProject(t *testing.T) {
+	if _, err := Open(t.TempDir()); !errors.Is(err, ErrNotGitRepository) {
+		t.Errorf("Expected a directory outside Git to be rejected, got %v", err)
+	}
+
+	dir := gitRepository(t)
+	if _, err := Open(dir); !errors.Is(err, ErrNotInitialized) {
+		t.Errorf("Expected an uninitialized project to be rejected, got %v", err)
+	}
+	if _, err := Init(dir); err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+	project, err := Open(filepath.Join(dir, ".git", ".."))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if root, _ := filepath.EvalSymlinks(project.Root()); root != mustEvalSymlinks(t, dir) {
+		t.Errorf("Expected the root %s, got %s", dir, project.Root())
+	}
+
+	file := filepath.Join(dir, "main.go")
+	os.WriteFile(file, []byte("package main\n"), 0644)
+	first, err := project.CreateSnapshot("First")
+	if err != nil {
+		t.Fatalf("CreateSnapshot failed: %v", err)
+	}
+	os.WriteFile(file, []byte("package main\n\nfunc main() {}\n"), 0644)
+	second, err := project.CreateSnapshot("Second")
+	if err != nil {
+		t.Fatalf("CreateSnapshot failed: %v", err)
+	}
+	if second.Parent != first.Hash || second.Message != "Second" {
+		t.Errorf("Unexpected snapshots %+v, %+v", first, second)
+	}
+
+	snapshots, err := project.ListSnapshots(Filter{Stats: true})
+	if err != nil || len(snapshots) != 2 {
+		t.Fatalf("Expected 2 snapshots, got %+v, %v", snapshots, err)
+	}
+	if snapshots[0].FilesChanged != 1 || snapshots[0].Insertions != 2 {
+		t.Errorf("Expected the stats of the latest snapshot, got %+v", snapshots[0])
+	}
+
+	journal, err := project.Restore(context.Background(), first.Hash)
+	if err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	if content, _ := os.ReadFile(file); string(content) != "package main\n" || journal.Source != first.Hash {
+		t.Errorf("Expected the first snapshot restored, got %q, %+v", content, journal)
+	}
+}
+
+func mustEvalSymlinks(t *testing.T, path string) string {
+	t.Helper()
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resolved
+}